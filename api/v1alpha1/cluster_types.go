@@ -15,6 +15,10 @@
 package v1alpha1
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -25,6 +29,55 @@ const (
 	KubeconfigKey                               = "kubeconfig"
 )
 
+// The probe annotations override the connectivity probe settings of the controller for a cluster,
+// the interval and the timeout are durations, e.g. 10s
+const (
+	ProbeIntervalAnnotation         = "cluster-registry.k8s.cisco.com/probe-interval"
+	ProbeTimeoutAnnotation          = "cluster-registry.k8s.cisco.com/probe-timeout"
+	ProbeFailureThresholdAnnotation = "cluster-registry.k8s.cisco.com/probe-failure-threshold"
+)
+
+// The client annotations override the client settings of the controller for a cluster, the QPS is a number,
+// the burst is an integer and the timeout is a duration, e.g. 30s
+const (
+	ClientQPSAnnotation     = "cluster-registry.k8s.cisco.com/client-qps"
+	ClientBurstAnnotation   = "cluster-registry.k8s.cisco.com/client-burst"
+	ClientTimeoutAnnotation = "cluster-registry.k8s.cisco.com/client-timeout"
+	// ClientForceJSONAnnotation disables the protobuf encoding of the built-in kinds for the cluster if set to true
+	ClientForceJSONAnnotation = "cluster-registry.k8s.cisco.com/client-force-json"
+)
+
+const (
+	// SourceRemovedPolicyAnnotation on a Cluster overrides the source removed policy of every rule for the objects
+	// synced from the cluster, its value is Orphan, Delete or DeleteAfter: <duration>, e.g. DeleteAfter: 1h
+	SourceRemovedPolicyAnnotation = "cluster-registry.k8s.cisco.com/source-removed-policy"
+	// SourceRemovedFinalizer holds the deletion of a Cluster until the source removed policies of the rules are applied
+	// to the objects synced from it
+	SourceRemovedFinalizer = "cluster-registry.k8s.cisco.com/source-removed"
+)
+
+// ParseSourceRemovedPolicy parses the value of the source removed policy annotation of a Cluster
+func ParseSourceRemovedPolicy(value string) (*SourceRemovedPolicy, error) {
+	policyType, after, hasAfter := strings.Cut(value, ":")
+
+	policy := &SourceRemovedPolicy{
+		Type: SourceRemovedPolicyType(strings.TrimSpace(policyType)),
+	}
+	if hasAfter {
+		d, err := time.ParseDuration(strings.TrimSpace(after))
+		if err != nil {
+			return nil, fmt.Errorf("after: %w", err)
+		}
+		policy.After = &metav1.Duration{Duration: d}
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
 // AuthInfo holds information that describes how a client can get
 // credentials to access the cluster.
 type AuthInfo struct {
@@ -61,6 +114,43 @@ type ClusterSpec struct {
 	// cluster.
 	// +optional
 	KubernetesAPIEndpoints []KubernetesAPIEndpoint `json:"kubernetesApiEndpoints,omitempty"`
+	// Connection holds the settings of the connections to the API server of the cluster which are not part of its
+	// kubeconfig.
+	// +optional
+	Connection *ClusterConnection `json:"connection,omitempty"`
+}
+
+// ClusterConnection holds the transport settings of the connections to the API server of a cluster
+type ClusterConnection struct {
+	// CABundleSecretRef references a secret key holding PEM encoded CA certificates, which are trusted on top of
+	// the ones of the kubeconfig.
+	// +optional
+	CABundleSecretRef *SecretKeyRef `json:"caBundleSecretRef,omitempty"`
+	// ProxyURL is the URL of the proxy the cluster is connected through, e.g. http://proxy.example.com:3128.
+	// The http, https and socks5 schemes are supported.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(http|https|socks5)://`
+	ProxyURL string `json:"proxyURL,omitempty"`
+}
+
+// SecretKeyRef references a key of a secret
+type SecretKeyRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Key of the secret data, defaults to ca.crt.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+const DefaultCABundleKey = "ca.crt"
+
+// GetKey returns the key of the secret data, or the default CA bundle key if it is not set
+func (r SecretKeyRef) GetKey() string {
+	if r.Key == "" {
+		return DefaultCABundleKey
+	}
+
+	return r.Key
 }
 
 // ClusterStatus defines the observed state of Cluster
@@ -71,18 +161,46 @@ type ClusterStatus struct {
 	Type   ClusterType `json:"type,omitempty"`
 	Leader bool        `json:"leader,omitempty"`
 
+	// APIEndpoint is the API server endpoint the controller currently connects to, it changes when the controller
+	// fails over between the endpoints of the cluster.
+	// +optional
+	APIEndpoint string `json:"apiEndpoint,omitempty"`
+
 	// Metadata
 	ClusterMetadata `json:",inline"`
 
+	// SyncState summarizes the objects synced from this cluster, it is written periodically by the sync controllers.
+	// +optional
+	SyncState *ClusterSyncState `json:"syncState,omitempty"`
+
 	// Conditions contains the different condition statuses for this cluster.
 	Conditions []ClusterCondition `json:"conditions,omitempty"`
 }
 
+// ClusterSyncState summarizes the objects synced from a cluster by the resource sync rules
+type ClusterSyncState struct {
+	// Rules is the number of resource sync rules syncing from the cluster.
+	Rules int `json:"rules"`
+	// Objects is the number of objects synced from the cluster, summed over the rules.
+	Objects int `json:"objects"`
+	// LastSyncTime is the time an object was last synced from the cluster successfully.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// LastError is the last error of syncing an object from the cluster.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// LastErrorTime is the time of the last error.
+	// +optional
+	LastErrorTime *metav1.Time `json:"lastErrorTime,omitempty"`
+}
+
+// Reset returns the status to be observed again, the sync state is kept as it is written by the sync controllers
 func (s ClusterStatus) Reset() ClusterStatus {
 	return ClusterStatus{
 		State:           ClusterStateReady,
 		Type:            ClusterTypePeer,
 		ClusterMetadata: ClusterMetadata{},
+		SyncState:       s.SyncState,
 		Conditions:      s.Conditions,
 	}
 }
@@ -113,6 +231,12 @@ const (
 	ClusterConditionTypeClusterMetadata ClusterConditionType = "ClusterMetadataSet"
 	ClusterConditionTypeReady           ClusterConditionType = "Ready"
 	ClusterConditionTypeClustersSynced  ClusterConditionType = "ClustersSynced"
+	ClusterConditionTypeReachable       ClusterConditionType = "ClusterReachable"
+	ClusterConditionTypeConnection      ClusterConditionType = "ConnectionConfigured"
+	// ClusterConditionTypeWatchDegraded is true while the list and watch requests of a resource keep failing
+	ClusterConditionTypeWatchDegraded ClusterConditionType = "WatchDegraded"
+	// ClusterConditionTypeBackoff is true while the reconciles are paused because the API server is erroring heavily
+	ClusterConditionTypeBackoff ClusterConditionType = "ClusterBackoff"
 )
 
 // ClusterCondition contains condition information for a cluster.
@@ -152,6 +276,8 @@ type ClusterCondition struct {
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state"
 // +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".status.type"
 // +kubebuilder:printcolumn:name="Synced",type="string",JSONPath=".status.conditions[?(@.type==\"ClustersSynced\")].status"
+// +kubebuilder:printcolumn:name="Objects",type="integer",JSONPath=".status.syncState.objects"
+// +kubebuilder:printcolumn:name="Last Sync",type="date",JSONPath=".status.syncState.lastSyncTime"
 // +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.version",priority=1
 // +kubebuilder:printcolumn:name="Provider",type="string",JSONPath=".status.provider",priority=1
 // +kubebuilder:printcolumn:name="Distribution",type="string",JSONPath=".status.distribution",priority=1
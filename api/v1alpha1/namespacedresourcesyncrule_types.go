@@ -0,0 +1,74 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+
+// NamespacedResourceSyncRule syncs objects the same way as a ResourceSyncRule, but its effects are confined to its
+// namespace: it only matches the source objects in its namespace, or in the source namespace mapped to it by the
+// controller, and only writes the synced objects into its own namespace. It can be authored by the teams owning the
+// namespace without a review of the cluster administrators.
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=namespacedresourcesyncrules,scope=Namespaced,shortName=nrsr
+type NamespacedResourceSyncRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResourceSyncRuleSpec   `json:"spec,omitempty"`
+	Status ResourceSyncRuleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespacedResourceSyncRuleList contains a list of NamespacedResourceSyncRule
+type NamespacedResourceSyncRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespacedResourceSyncRule `json:"items"`
+}
+
+// ValidateNamespaced checks the constraints of the spec of a namespaced rule on top of the ones of every rule, the
+// fields reaching out of the namespace of the rule can not be used
+func (r ResourceSyncRuleSpec) ValidateNamespaced() error {
+	if r.AnchorOwnership {
+		return fmt.Errorf("anchorOwnership: can not be used by namespaced rules")
+	}
+
+	if len(r.DependsOn) > 0 {
+		return fmt.Errorf("dependsOn: can not be used by namespaced rules")
+	}
+
+	if r.ServiceAccountName != "" {
+		return fmt.Errorf("serviceAccountName: can not be used by namespaced rules")
+	}
+
+	for i, rule := range r.Rules {
+		if rule.Mutations.NamespaceRouting != nil {
+			return fmt.Errorf("rules[%d].mutations.namespaceRouting: can not be used by namespaced rules, the objects are written into the namespace of the rule", i)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespacedResourceSyncRule{}, &NamespacedResourceSyncRuleList{})
+}
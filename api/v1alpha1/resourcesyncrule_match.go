@@ -16,8 +16,10 @@ package v1alpha1
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/tidwall/gjson"
+	corev1 "k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -129,7 +131,15 @@ type MatchedRules []SyncRule
 func (r ResourceSyncRuleSpec) Match(obj runtime.Object) (bool, MatchedRules, error) {
 	matchedRules := make(MatchedRules, 0)
 
-	if resources.ConvertGVK(obj.GetObjectKind().GroupVersionKind()) != r.GVK {
+	if !r.MatchGVK(obj.GetObjectKind().GroupVersionKind()) {
+		return false, matchedRules, nil
+	}
+
+	if !r.IncludeSystemSecrets && IsSystemSecret(obj) {
+		return false, matchedRules, nil
+	}
+
+	if r.RequireOptInAnnotation && !IsSyncOptedIn(obj) {
 		return false, matchedRules, nil
 	}
 
@@ -146,6 +156,45 @@ func (r ResourceSyncRuleSpec) Match(obj runtime.Object) (bool, MatchedRules, err
 	return len(matchedRules) > 0, matchedRules, nil
 }
 
+// MatchGVK returns whether objects of the GVK are synced by the rule
+func (r ResourceSyncRuleSpec) MatchGVK(gvk schema.GroupVersionKind) bool {
+	if gvk.Group != r.GVK.Group || gvk.Kind != r.GVK.Kind {
+		return false
+	}
+
+	if r.GVK.Version != AnyVersion {
+		return gvk.Version == r.GVK.Version
+	}
+
+	if len(r.Versions) == 0 {
+		return true
+	}
+
+	for _, version := range r.Versions {
+		if version == gvk.Version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetPreservedFinalizers returns the finalizers kept on the synced objects, in their original order
+func (r ResourceSyncRuleSpec) GetPreservedFinalizers(finalizers []string) []string {
+	var preserved []string
+	for _, finalizer := range finalizers {
+		for _, name := range r.PreserveFinalizers {
+			if name == AnyFinalizer || name == finalizer {
+				preserved = append(preserved, finalizer)
+
+				break
+			}
+		}
+	}
+
+	return preserved
+}
+
 func (s *ResourceSyncRule) Match(obj runtime.Object) (bool, MatchedRules, error) {
 	return s.Spec.Match(obj)
 }
@@ -190,8 +239,10 @@ func (r MatchedRules) GetMutatedGVK(gvk schema.GroupVersionKind) (bool, schema.G
 
 func (r MatchedRules) GetMutationLabels() LabelMutations {
 	m := LabelMutations{
-		Add:    make(map[string]string),
-		Remove: make([]string, 0),
+		Add:            make(map[string]string),
+		Remove:         make([]string, 0),
+		RemovePrefixes: make([]string, 0),
+		RemoveRegexes:  make([]string, 0),
 	}
 
 	for _, matchedRule := range r {
@@ -199,6 +250,8 @@ func (r MatchedRules) GetMutationLabels() LabelMutations {
 			m.Add[k] = v
 		}
 		m.Remove = append(m.Remove, matchedRule.Mutations.GetLabels().Remove...)
+		m.RemovePrefixes = append(m.RemovePrefixes, matchedRule.Mutations.GetLabels().RemovePrefixes...)
+		m.RemoveRegexes = append(m.RemoveRegexes, matchedRule.Mutations.GetLabels().RemoveRegexes...)
 	}
 
 	return m
@@ -206,8 +259,10 @@ func (r MatchedRules) GetMutationLabels() LabelMutations {
 
 func (r MatchedRules) GetMutationAnnotations() AnnotationMutations {
 	m := AnnotationMutations{
-		Add:    make(map[string]string),
-		Remove: make([]string, 0),
+		Add:            make(map[string]string),
+		Remove:         make([]string, 0),
+		RemovePrefixes: make([]string, 0),
+		RemoveRegexes:  make([]string, 0),
 	}
 
 	for _, matchedRule := range r {
@@ -215,6 +270,8 @@ func (r MatchedRules) GetMutationAnnotations() AnnotationMutations {
 			m.Add[k] = v
 		}
 		m.Remove = append(m.Remove, matchedRule.Mutations.GetAnnotations().Remove...)
+		m.RemovePrefixes = append(m.RemovePrefixes, matchedRule.Mutations.GetAnnotations().RemovePrefixes...)
+		m.RemoveRegexes = append(m.RemoveRegexes, matchedRule.Mutations.GetAnnotations().RemoveRegexes...)
 	}
 
 	return m
@@ -229,6 +286,93 @@ func (r MatchedRules) GetMutationOverrides() []resources.K8SResourceOverlayPatch
 	return overrides
 }
 
+func (r MatchedRules) GetMutationJSONPatches() []JSONPatchOperation {
+	operations := make([]JSONPatchOperation, 0)
+	for _, matchedRule := range r {
+		operations = append(operations, matchedRule.Mutations.JSONPatches...)
+	}
+
+	return operations
+}
+
+// GetMutationNamespaceRouting returns the namespace routing of the last matched rule which has one or nil if none is set
+func (r MatchedRules) GetMutationNamespaceRouting() *NamespaceRouting {
+	var routing *NamespaceRouting
+	for _, matchedRule := range r {
+		if matchedRule.Mutations.NamespaceRouting != nil {
+			routing = matchedRule.Mutations.NamespaceRouting
+		}
+	}
+
+	return routing
+}
+
+// GetMutationEndpoints returns the endpoints mutations of the last matched rule which has one or nil if none is set
+func (r MatchedRules) GetMutationEndpoints() *EndpointsMutations {
+	var mutations *EndpointsMutations
+	for _, matchedRule := range r {
+		if matchedRule.Mutations.Endpoints != nil {
+			mutations = matchedRule.Mutations.Endpoints
+		}
+	}
+
+	return mutations
+}
+
+// GetMutationWebhooks returns the webhook mutations of the last matched rule which has one or nil if none is set
+func (r MatchedRules) GetMutationWebhooks() *WebhookMutations {
+	var mutations *WebhookMutations
+	for _, matchedRule := range r {
+		if matchedRule.Mutations.Webhooks != nil {
+			mutations = matchedRule.Mutations.Webhooks
+		}
+	}
+
+	return mutations
+}
+
+// GetMutationPersistentVolumeClaims returns the persistent volume claim mutations of the last matched rule which has
+// one or nil if none is set
+func (r MatchedRules) GetMutationPersistentVolumeClaims() *PersistentVolumeClaimMutations {
+	var mutations *PersistentVolumeClaimMutations
+	for _, matchedRule := range r {
+		if matchedRule.Mutations.PersistentVolumeClaims != nil {
+			mutations = matchedRule.Mutations.PersistentVolumeClaims
+		}
+	}
+
+	return mutations
+}
+
+// GetMutationReplicas returns the replica mutations of the last matched rule which has one or nil if none is set
+func (r MatchedRules) GetMutationReplicas() *ReplicaMutations {
+	var mutations *ReplicaMutations
+	for _, matchedRule := range r {
+		if matchedRule.Mutations.Replicas != nil {
+			mutations = matchedRule.Mutations.Replicas
+		}
+	}
+
+	return mutations
+}
+
+// GetMutationSecretData returns the union of the secret data mutations of the matched rules or nil if none is set
+func (r MatchedRules) GetMutationSecretData() *SecretDataMutations {
+	var m *SecretDataMutations
+	for _, matchedRule := range r {
+		if matchedRule.Mutations.SecretData == nil {
+			continue
+		}
+		if m == nil {
+			m = &SecretDataMutations{}
+		}
+		m.IncludeKeys = append(m.IncludeKeys, matchedRule.Mutations.SecretData.IncludeKeys...)
+		m.ExcludeKeys = append(m.ExcludeKeys, matchedRule.Mutations.SecretData.ExcludeKeys...)
+	}
+
+	return m
+}
+
 func (r MatchedRules) GetMutationSyncStatus() bool {
 	for _, matchedRule := range r {
 		if matchedRule.Mutations.SyncStatus == true {
@@ -239,6 +383,18 @@ func (r MatchedRules) GetMutationSyncStatus() bool {
 	return false
 }
 
+// GetMutationStatusSync returns the status sync of the last matched rule which has one or nil if none is set
+func (r MatchedRules) GetMutationStatusSync() *StatusSync {
+	var statusSync *StatusSync
+	for _, matchedRule := range r {
+		if matchedRule.Mutations.StatusSync != nil {
+			statusSync = matchedRule.Mutations.StatusSync
+		}
+	}
+
+	return statusSync
+}
+
 func (s AnnotationSelector) convertMatchExpressions() []metav1.LabelSelectorRequirement {
 	reqs := make([]metav1.LabelSelectorRequirement, 0)
 	for _, r := range s.MatchExpressions {
@@ -255,3 +411,49 @@ func (s AnnotationSelector) convertMatchExpressions() []metav1.LabelSelectorRequ
 
 	return reqs
 }
+
+// IsSystemSecret returns whether the object is a service account token or a Helm release Secret,
+// which are meaningless or harmful on other clusters
+// IsSyncOptedIn returns whether the object is opted in to be synced by the SyncOptInAnnotation
+func IsSyncOptedIn(obj runtime.Object) bool {
+	metaObj, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+
+	return metaObj.GetAnnotations()[SyncOptInAnnotation] == "true"
+}
+
+func IsSystemSecret(obj runtime.Object) bool {
+	// the typed Secrets read directly from the API server have an empty TypeMeta, so the other objects are only
+	// recognized by their GVK
+	var secretType corev1.SecretType
+	switch o := obj.(type) {
+	case *corev1.Secret:
+		secretType = o.Type
+	default:
+		if obj.GetObjectKind().GroupVersionKind() != corev1.SchemeGroupVersion.WithKind("Secret") {
+			return false
+		}
+		if u, ok := obj.(runtime.Unstructured); ok {
+			if t, ok := u.UnstructuredContent()["type"].(string); ok {
+				secretType = corev1.SecretType(t)
+			}
+		}
+	}
+
+	if secretType == corev1.SecretTypeServiceAccountToken || secretType == HelmReleaseSecretType {
+		return true
+	}
+
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+
+	if _, ok := objMeta.GetAnnotations()[corev1.ServiceAccountNameKey]; ok {
+		return true
+	}
+
+	return strings.HasPrefix(objMeta.GetName(), helmReleaseSecretNamePrefix) && objMeta.GetLabels()["owner"] == "helm"
+}
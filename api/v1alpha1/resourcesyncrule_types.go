@@ -15,6 +15,10 @@
 package v1alpha1
 
 import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -23,16 +27,398 @@ import (
 )
 
 const (
-	OwnershipAnnotation       = "cluster-registry.k8s.cisco.com/resource-owner-cluster-id"
+	OwnershipAnnotation = "cluster-registry.k8s.cisco.com/resource-owner-cluster-id"
+	// OwnerRuleAnnotation is the name of the rule which wrote the synced object, objects written before it was
+	// introduced only carry the OwnershipAnnotation
+	OwnerRuleAnnotation       = "k8s.cisco.com/resource-owner-rule"
 	OriginalGVKAnnotation     = "cluster-registry.k8s.cisco.com/original-group-version-kind"
+	OriginalNameLabel         = "cluster-registry.k8s.cisco.com/original-name"
+	OriginalNamespaceLabel    = "cluster-registry.k8s.cisco.com/original-namespace"
 	ClusterDisabledAnnotation = "cluster-registry.k8s.cisco.com/cluster-disabled"
 	SyncDisabledAnnotation    = "cluster-registry.k8s.cisco.com/resource-sync-disabled"
+	FormatVersionAnnotation   = "cluster-registry.k8s.cisco.com/format-version"
+	SyncAnchorRuleLabel       = "cluster-registry.k8s.cisco.com/sync-anchor-rule"
+
+	SourceClusterAnnotation         = "cluster-registry.k8s.cisco.com/source-cluster"
+	SourceObjectAnnotation          = "cluster-registry.k8s.cisco.com/source-object"
+	SourceRuleAnnotation            = "cluster-registry.k8s.cisco.com/source-rule"
+	SourceResourceVersionAnnotation = "cluster-registry.k8s.cisco.com/source-resource-version"
+
+	// SourceUIDAnnotation is set on every synced object to the UID of the source object it was written from, so the
+	// deletion of a source object recreated with the same name does not delete the object synced from the new one
+	SourceUIDAnnotation = "cluster-registry.k8s.cisco.com/source-uid"
+
+	// SourceModifiedAtAnnotation can be set on the source objects by the side pushing them, to the time of their last
+	// modification in RFC 3339 format, so the sync lag is measured from it instead of the time the change was observed
+	SourceModifiedAtAnnotation = "cluster-registry.k8s.cisco.com/source-modified-at"
+
+	// SyncOriginAnnotation is set on every synced object to the ID of the cluster the object was first synced from and
+	// the number of syncs it took to reach the cluster, in <cluster ID>/<hops> format, so sync loops can be detected
+	SyncOriginAnnotation = "cluster-registry.k8s.cisco.com/sync-origin"
+
+	// ContentHashAnnotation is set on every synced object to the hash of its desired state, so objects whose desired
+	// state is unchanged are not reconciled again
+	ContentHashAnnotation = "cluster-registry.k8s.cisco.com/content-hash"
+
+	// AuditHashAnnotation is set on the synced objects when the audit trail is enabled, to the sha256 of their desired
+	// state, so audits can prove that an object is exactly what the rule syncs from its source object
+	AuditHashAnnotation = "cluster-registry.k8s.cisco.com/audit-hash"
+
+	// SyncedAtAnnotation is set with the audit hash to the time the desired state of the hash was written, in RFC 3339
+	// format
+	SyncedAtAnnotation = "cluster-registry.k8s.cisco.com/synced-at"
+
+	// VersionFallbackAnnotation records the GVK a synced object was meant to be synced as, when it is synced as the
+	// version the local cluster serves instead
+	VersionFallbackAnnotation = "cluster-registry.k8s.cisco.com/version-fallback"
+
+	// DeleteProtectedAnnotation set to "true" on a synced object keeps it from being deleted by the sync controller,
+	// unless the rule allows the deletion of protected objects
+	DeleteProtectedAnnotation = "k8s.cisco.com/sync-delete-protected"
+
+	// SyncOptInAnnotation set to "true" on a source object opts it in to be synced by the rules requiring the opt-in
+	// annotation, removing it deletes the synced object like the deletion of the source object
+	SyncOptInAnnotation = "k8s.cisco.com/sync"
+
+	// ResyncRequestedAnnotation on a rule requests syncing every object it covers again, whenever its value changes
+	ResyncRequestedAnnotation = "cluster-registry.k8s.cisco.com/resync-requested"
+
+	// LogLevelAnnotation on a rule sets the log level of its sync controllers to error, info, debug or trace, whatever
+	// the verbosity of the controller is
+	LogLevelAnnotation = "k8s.cisco.com/log-level"
+)
+
+// AnyVersion as the version of the GVK of a rule means whatever version a source cluster serves
+const AnyVersion = "*"
+
+// AnyFinalizer as a preserved finalizer of a rule keeps every finalizer of the synced objects
+const AnyFinalizer = "*"
+
+// MaxWriteCoalesceWindow is the longest time the write of a changed object can be held for
+const MaxWriteCoalesceWindow = time.Minute
+
+const (
+	HelmReleaseSecretType       corev1.SecretType = "helm.sh/release.v1"
+	helmReleaseSecretNamePrefix                   = "sh.helm.release.v1."
 )
 
 type ResourceSyncRuleSpec struct {
 	ClusterFeatureMatches []ClusterFeatureMatch      `json:"clusterFeatureMatch,omitempty"`
 	GVK                   resources.GroupVersionKind `json:"groupVersionKind"`
 	Rules                 []SyncRule                 `json:"rules"`
+	// AnchorOwnership sets a per rule anchor object as a non-controller owner on every synced object,
+	// deleting the anchor garbage collects every object synced by the rule
+	AnchorOwnership bool `json:"anchorOwnership,omitempty"`
+	// DisableFieldSanitization keeps cluster specific fields, like the allocated cluster IPs and node ports of Services,
+	// which are cleared by default before syncing
+	DisableFieldSanitization bool `json:"disableFieldSanitization,omitempty"`
+	// DisableSourceAnnotations omits the annotations referencing the source cluster, object, rule and resource version
+	// from the synced objects
+	DisableSourceAnnotations bool `json:"disableSourceAnnotations,omitempty"`
+	// RequireOptInAnnotation only syncs the source objects opted in by the k8s.cisco.com/sync: "true" annotation, in
+	// addition to the matches of the rules
+	RequireOptInAnnotation bool `json:"requireOptInAnnotation,omitempty"`
+	// IncludeSystemSecrets allows syncing service account token and Helm release Secrets, which are skipped by default
+	IncludeSystemSecrets bool `json:"includeSystemSecrets,omitempty"`
+	// Versions restricts and orders the versions considered when the version of the GVK is "*",
+	// the first one served by a source cluster is synced from it. Every served version is considered if empty.
+	Versions []string `json:"versions,omitempty"`
+	// NormalizeToVersion converts objects synced from any version to this version
+	NormalizeToVersion string `json:"normalizeToVersion,omitempty"`
+	// VersionConversions are used to convert objects between versions not convertible by the scheme
+	VersionConversions []VersionConversion `json:"versionConversions,omitempty"`
+	// VersionFallback syncs the objects as the preferred version of their kind served by the local cluster, if it does
+	// not serve the version they are synced as. The objects are converted by the scheme or the versionConversions, or
+	// moved to the served version as they are if it has every field of them.
+	VersionFallback bool `json:"versionFallback,omitempty"`
+	// RecreatePolicy controls which synced objects are deleted and created again when their immutable fields change
+	// +kubebuilder:validation:Enum=Workloads;Always;Never
+	RecreatePolicy RecreatePolicy `json:"recreatePolicy,omitempty"`
+	// PatchStrategy controls how the synced objects are compared with the live objects to decide whether they are
+	// updated, StrategicMerge is the default. Merge, JSON and Replace help with kinds whose lists are not merged as the
+	// strategic merge patch expects, which would otherwise be updated on every resync.
+	// +kubebuilder:validation:Enum=StrategicMerge;Merge;JSON;Replace
+	PatchStrategy PatchStrategy `json:"patchStrategy,omitempty"`
+	// RecreateImmutable deletes and creates again the synced ConfigMaps and Secrets marked immutable when their content
+	// changes, their updates are rejected by the API server. Otherwise they are left as is until their source changes.
+	RecreateImmutable bool `json:"recreateImmutable,omitempty"`
+	// ReadMode controls whether the source objects are read from the cache of the source cluster, or directly from its
+	// API server. Direct reads are strongly consistent, but cost an API request per reconcile.
+	// +kubebuilder:validation:Enum=Cached;Direct
+	ReadMode ReadMode `json:"readMode,omitempty"`
+	// SyncMode controls whether the synced objects are kept up to date with their sources, or only created once
+	// +kubebuilder:validation:Enum=EnsureUpToDate;EnsureExists
+	SyncMode SyncMode `json:"syncMode,omitempty"`
+	// ConflictPolicy controls what happens when an object not written by the sync controller already exists locally
+	// and differs from the synced one, identical objects are adopted regardless of it
+	// +kubebuilder:validation:Enum=Requeue;Skip;Overwrite
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+	// DriftPolicy controls what happens when a synced object is changed locally by someone else than the sync
+	// controller, Repair is the default
+	// +kubebuilder:validation:Enum=Report;Repair;Ignore
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+	// Priority decides which rule syncs an object matched by multiple rules from the same cluster, the one with the
+	// highest priority syncs it, ties are broken by the lowest rule name. The other rules skip the object.
+	Priority int `json:"priority,omitempty"`
+	// AllowProtectedDeletion lets the rule delete and take over the synced objects protected by the
+	// sync-delete-protected annotation
+	AllowProtectedDeletion bool `json:"allowProtectedDeletion,omitempty"`
+	// DeletionPropagation is the propagation policy of the deletes of the synced objects, the default of the kind is
+	// used if not set
+	// +kubebuilder:validation:Enum=Orphan;Background;Foreground
+	DeletionPropagation *metav1.DeletionPropagation `json:"deletionPropagation,omitempty"`
+	// DeleteAfter delays the deletion of the synced objects after their source disappeared, a source recreated in the
+	// meantime cancels the deletion
+	DeleteAfter *metav1.Duration `json:"deleteAfter,omitempty"`
+	// SourceRemovedPolicy is what happens to the objects synced by the rule from a cluster once the cluster is removed
+	// from the registry, they are orphaned if not set. The source-removed-policy annotation of a Cluster overrides it
+	// for the objects synced from the cluster.
+	// +optional
+	SourceRemovedPolicy *SourceRemovedPolicy `json:"sourceRemovedPolicy,omitempty"`
+	// PreserveFinalizers are the finalizers of the source objects kept on the synced objects, "*" keeps every
+	// finalizer. The finalizers are removed by default. The synced objects are only deleted after the kept finalizers
+	// are removed from them locally.
+	PreserveFinalizers []string `json:"preserveFinalizers,omitempty"`
+	// OverrideLocalOwners lets the rule adopt and overwrite existing local objects controlled by a local controller
+	// through their owner references, they are skipped by default
+	OverrideLocalOwners bool `json:"overrideLocalOwners,omitempty"`
+	// OwnerReferenceMode controls the owner references of the synced objects, they are cleared by default since the
+	// owners of the source cluster do not exist locally
+	// +kubebuilder:validation:Enum=Clear;Remap
+	OwnerReferenceMode OwnerReferenceMode `json:"ownerReferenceMode,omitempty"`
+	// Suspend pauses syncing without deleting the rule, already synced objects are left untouched while suspended
+	Suspend bool `json:"suspend,omitempty"`
+	// DependsOn lists the rules which must be ready before this rule starts syncing, e.g. the rule syncing the CRDs of
+	// the synced custom resources. The rule waits again if any of them is deleted.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// ServiceAccountName is the service account in the namespace of the controller the synced objects are written as on
+	// the local cluster, so the writes are limited to its RBAC permissions. The controller writes with its own service
+	// account if not set.
+	// +kubebuilder:validation:MaxLength=253
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Workers is the number of objects reconciled concurrently by the sync controller of each cluster, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	Workers int `json:"workers,omitempty"`
+	// ReconcileTimeout limits the time a reconcile of an object can take, including the API calls to the source and the
+	// local cluster, it overrides the default of the controller
+	ReconcileTimeout *metav1.Duration `json:"reconcileTimeout,omitempty"`
+	// WriteCoalesceWindow holds the write of a changed object for the window, e.g. 500ms to 5s, and writes only its
+	// latest desired state once the window closes, so the objects changing many times a second are written once per
+	// window. The objects are written right away if not set.
+	WriteCoalesceWindow *metav1.Duration `json:"writeCoalesceWindow,omitempty"`
+	// MaxObjects is the number of distinct local objects the rule syncs at most from every cluster, the new objects
+	// beyond it are not created, while the synced ones are still updated and deleted. 0 disables the limit.
+	// +kubebuilder:validation:Minimum=0
+	MaxObjects int `json:"maxObjects,omitempty"`
+	// MaxObjectSize is the largest size of the source objects serialized to JSON synced by the rule, the larger ones
+	// are skipped. It overrides the default of the controller, 0 disables the limit.
+	MaxObjectSize *resource.Quantity `json:"maxObjectSize,omitempty"`
+	// Backoff tunes the per object exponential backoff of the failed reconciles
+	Backoff *SyncBackoff `json:"backoff,omitempty"`
+	// Verification periodically compares a sample of the synced objects with the desired state rendered from their
+	// current source objects and reports the drifted ones
+	Verification *SyncVerification `json:"verification,omitempty"`
+	// SyncWindow limits the changes of the synced objects to recurring maintenance windows, the objects are synced at
+	// the start of the next window otherwise
+	SyncWindow *SyncWindow `json:"syncWindow,omitempty"`
+	// EventTarget controls whether the events of the synced objects are recorded on the rule, on the synced objects
+	// themselves, or on both. The events of objects which could not be created are recorded on the rule regardless.
+	// +kubebuilder:validation:Enum=Rule;Object;Both
+	EventTarget EventTarget `json:"eventTarget,omitempty"`
+}
+
+type SyncVerification struct {
+	// ObjectsPerMinute is the number of synced objects verified per minute by the sync controller of each cluster,
+	// defaults to 10
+	// +kubebuilder:validation:Minimum=1
+	ObjectsPerMinute int `json:"objectsPerMinute,omitempty"`
+	// AutoRepair syncs the drifted objects again, they are only reported by default
+	AutoRepair bool `json:"autoRepair,omitempty"`
+}
+
+const DefaultVerificationObjectsPerMinute = 10
+
+func (v SyncVerification) GetObjectsPerMinute() int {
+	if v.ObjectsPerMinute > 0 {
+		return v.ObjectsPerMinute
+	}
+
+	return DefaultVerificationObjectsPerMinute
+}
+
+type SyncBackoff struct {
+	// BaseDelay is the delay after the first failure, which is doubled after every subsequent one, defaults to 5ms
+	BaseDelay metav1.Duration `json:"baseDelay,omitempty"`
+	// MaxDelay is the maximum delay between the retries of an object, defaults to 1000s
+	MaxDelay metav1.Duration `json:"maxDelay,omitempty"`
+}
+
+type SyncWindow struct {
+	// Schedule is the start of the windows in cron format, e.g. "0 22 * * 6" opens a window every Saturday at 22:00
+	Schedule string `json:"schedule"`
+	// Duration is the length of the windows
+	Duration metav1.Duration `json:"duration"`
+	// TimeZone is the IANA name of the time zone of the schedule, e.g. "Europe/Berlin", defaults to UTC
+	TimeZone string `json:"timeZone,omitempty"`
+	// Deletions controls whether the deletions of the synced objects wait for the windows too, they do by default
+	// +kubebuilder:validation:Enum=Defer;Immediate
+	Deletions SyncWindowDeletionPolicy `json:"deletions,omitempty"`
+}
+
+type SyncWindowDeletionPolicy string
+
+const (
+	// SyncWindowDeletionPolicyDefer deletes the synced objects of the missing sources in the windows only, this is the
+	// default
+	SyncWindowDeletionPolicyDefer SyncWindowDeletionPolicy = "Defer"
+	// SyncWindowDeletionPolicyImmediate deletes the synced objects of the missing sources right away
+	SyncWindowDeletionPolicyImmediate SyncWindowDeletionPolicy = "Immediate"
+)
+
+type RecreatePolicy string
+
+const (
+	// RecreatePolicyWorkloads recreates Services, Deployments, StatefulSets and DaemonSets, this is the default
+	RecreatePolicyWorkloads RecreatePolicy = "Workloads"
+	// RecreatePolicyAlways recreates objects of any kind, including Jobs and PersistentVolumeClaims
+	RecreatePolicyAlways RecreatePolicy = "Always"
+	// RecreatePolicyNever never recreates objects
+	RecreatePolicyNever RecreatePolicy = "Never"
+)
+
+type PatchStrategy string
+
+const (
+	// PatchStrategyStrategicMerge compares the objects with a three-way strategic merge patch, this is the default
+	PatchStrategyStrategicMerge PatchStrategy = "StrategicMerge"
+	// PatchStrategyMerge compares the objects with a three-way JSON merge patch, lists are replaced as a whole
+	PatchStrategyMerge PatchStrategy = "Merge"
+	// PatchStrategyJSON compares the fields of the desired object with the live object one by one, lists as a whole
+	PatchStrategyJSON PatchStrategy = "JSON"
+	// PatchStrategyReplace updates the live object with the full desired object when any of their fields differ,
+	// including the fields added to the live object or removed from the desired one
+	PatchStrategyReplace PatchStrategy = "Replace"
+)
+
+type ReadMode string
+
+const (
+	// ReadModeCached reads the source objects from the informer cache of the source cluster, this is the default
+	ReadModeCached ReadMode = "Cached"
+	// ReadModeDirect reads the source objects from the API server of the source cluster on every reconcile
+	ReadModeDirect ReadMode = "Direct"
+)
+
+type SyncMode string
+
+const (
+	// SyncModeEnsureUpToDate creates, updates and deletes the synced objects following their sources, this is the
+	// default
+	SyncModeEnsureUpToDate SyncMode = "EnsureUpToDate"
+	// SyncModeEnsureExists creates the missing objects, but never updates or deletes them afterwards, they are owned by
+	// the local cluster once created
+	SyncModeEnsureExists SyncMode = "EnsureExists"
+)
+
+type OwnerReferenceMode string
+
+const (
+	// OwnerReferenceModeClear clears the owner references of the synced objects, this is the default
+	OwnerReferenceModeClear OwnerReferenceMode = "Clear"
+	// OwnerReferenceModeRemap points the owner references to the local copies of the owners synced from the same
+	// cluster, the references to owners which are not synced are cleared
+	OwnerReferenceModeRemap OwnerReferenceMode = "Remap"
+)
+
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyRequeue retries syncing the object with backoff until the existing object is removed or changed,
+	// this is the default
+	ConflictPolicyRequeue ConflictPolicy = "Requeue"
+	// ConflictPolicySkip leaves the existing object alone, the object is synced again when its source changes
+	ConflictPolicySkip ConflictPolicy = "Skip"
+	// ConflictPolicyOverwrite adopts the existing object and overwrites it with the synced one
+	ConflictPolicyOverwrite ConflictPolicy = "Overwrite"
+)
+
+type DriftPolicy string
+
+const (
+	// DriftPolicyReport reports the local changes of the synced objects, they are reverted by the next sync of their
+	// source object
+	DriftPolicyReport DriftPolicy = "Report"
+	// DriftPolicyRepair reports the local changes of the synced objects and reverts them right away, this is the default
+	DriftPolicyRepair DriftPolicy = "Repair"
+	// DriftPolicyIgnore neither reports nor reverts the local changes of the synced objects, they are reverted by the
+	// next sync of their source object
+	DriftPolicyIgnore DriftPolicy = "Ignore"
+)
+
+type SourceRemovedPolicyType string
+
+const (
+	// SourceRemovedPolicyOrphan leaves the objects synced from a removed cluster on the local cluster, this is the
+	// default
+	SourceRemovedPolicyOrphan SourceRemovedPolicyType = "Orphan"
+	// SourceRemovedPolicyDelete deletes the objects synced from a removed cluster before the Cluster resource is gone
+	SourceRemovedPolicyDelete SourceRemovedPolicyType = "Delete"
+	// SourceRemovedPolicyDeleteAfter deletes the objects synced from a removed cluster once the grace period of the
+	// policy passed, registering the cluster again in the meantime cancels the deletion
+	SourceRemovedPolicyDeleteAfter SourceRemovedPolicyType = "DeleteAfter"
+)
+
+// SourceRemovedPolicy is what happens to the synced objects once the cluster they are synced from is removed from the
+// registry
+type SourceRemovedPolicy struct {
+	// +kubebuilder:validation:Enum=Orphan;Delete;DeleteAfter
+	Type SourceRemovedPolicyType `json:"type"`
+	// After is the grace period of the DeleteAfter policy, measured from the removal of the cluster
+	// +optional
+	After *metav1.Duration `json:"after,omitempty"`
+}
+
+// GetType returns the type of the policy, the objects are orphaned without a policy
+func (p *SourceRemovedPolicy) GetType() SourceRemovedPolicyType {
+	if p == nil || p.Type == "" {
+		return SourceRemovedPolicyOrphan
+	}
+
+	return p.Type
+}
+
+// GetGracePeriod returns the time the deletion of the synced objects is delayed for after the removal of the cluster
+func (p *SourceRemovedPolicy) GetGracePeriod() time.Duration {
+	if p.GetType() != SourceRemovedPolicyDeleteAfter || p.After == nil {
+		return 0
+	}
+
+	return p.After.Duration
+}
+
+type EventTarget string
+
+const (
+	// EventTargetRule records the events of the synced objects on the rule, this is the default
+	EventTargetRule EventTarget = "Rule"
+	// EventTargetObject records the events of the synced objects on the synced objects
+	EventTargetObject EventTarget = "Object"
+	// EventTargetBoth records the events of the synced objects on both the rule and the synced objects
+	EventTargetBoth EventTarget = "Both"
+)
+
+type VersionConversion struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// FieldMaps move the values of fields renamed between the versions, every other field is kept as is
+	FieldMaps []FieldMap `json:"fieldMaps,omitempty"`
+}
+
+type FieldMap struct {
+	// From is the dot separated path of the field in the source version, e.g. spec.replicaCount
+	From string `json:"from"`
+	// To is the dot separated path of the field in the target version, e.g. spec.replicas
+	To string `json:"to"`
 }
 
 type ClusterFeatureMatch struct {
@@ -51,7 +437,205 @@ type Mutations struct {
 	GVK         *resources.GroupVersionKind         `json:"groupVersionKind,omitempty"`
 	Labels      *LabelMutations                     `json:"labels,omitempty"`
 	Overrides   []resources.K8SResourceOverlayPatch `json:"overrides,omitempty"`
-	SyncStatus  bool                                `json:"syncStatus,omitempty"`
+	// JSONPatches are RFC 6902 JSON Patch operations applied in order after the overrides
+	JSONPatches []JSONPatchOperation `json:"jsonPatches,omitempty"`
+	// SecretData prunes the data and stringData keys of Secrets, it can only be used for v1/Secret rules
+	SecretData *SecretDataMutations `json:"secretData,omitempty"`
+	// NamespaceRouting selects the namespace of the synced object based on the metadata of the source object
+	NamespaceRouting *NamespaceRouting `json:"namespaceRouting,omitempty"`
+	// Endpoints rewrites the addresses of EndpointSlices and Endpoints, it can only be used for
+	// discovery.k8s.io/EndpointSlice and v1/Endpoints rules
+	Endpoints *EndpointsMutations `json:"endpoints,omitempty"`
+	// Webhooks rewrites the client configs of admission webhook configurations, it can only be used for
+	// admissionregistration.k8s.io/ValidatingWebhookConfiguration and MutatingWebhookConfiguration rules
+	Webhooks *WebhookMutations `json:"webhooks,omitempty"`
+	// PersistentVolumeClaims maps the storage classes of PersistentVolumeClaims, it can only be used for
+	// v1/PersistentVolumeClaim rules
+	PersistentVolumeClaims *PersistentVolumeClaimMutations `json:"persistentVolumeClaims,omitempty"`
+	// Replicas overrides the number of replicas of the synced workloads, e.g. to keep them scaled down on a standby
+	// cluster
+	Replicas   *ReplicaMutations `json:"replicas,omitempty"`
+	SyncStatus bool              `json:"syncStatus,omitempty"`
+	// StatusSync controls how the status of the source object is written onto the synced object if syncStatus is set
+	StatusSync *StatusSync `json:"statusSync,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Replace;MergeConditions;Fields
+type StatusSyncStrategy string
+
+const (
+	// StatusSyncStrategyReplace overwrites the whole status of the synced object with the one of the source object
+	StatusSyncStrategyReplace StatusSyncStrategy = "Replace"
+	// StatusSyncStrategyMergeConditions overwrites the status, except its conditions, which are merged by their type.
+	// The condition with the newest lastTransitionTime wins, conditions only present locally are kept.
+	StatusSyncStrategyMergeConditions StatusSyncStrategy = "MergeConditions"
+	// StatusSyncStrategyFields only copies the listed fields of the status, the rest of the local status is kept
+	StatusSyncStrategyFields StatusSyncStrategy = "Fields"
+)
+
+type StatusSync struct {
+	// Strategy is the way the status is written, defaults to Replace
+	Strategy StatusSyncStrategy `json:"strategy,omitempty"`
+	// Fields are the JSONPath fields of the status copied by the Fields strategy, e.g. .status.phase
+	Fields []string `json:"fields,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Skip;Default;Fail
+type UnmappedNamespacePolicy string
+
+const (
+	// UnmappedNamespacePolicySkip does not sync the object and records an event
+	UnmappedNamespacePolicySkip UnmappedNamespacePolicy = "Skip"
+	// UnmappedNamespacePolicyDefault syncs the object into the default namespace
+	UnmappedNamespacePolicyDefault UnmappedNamespacePolicy = "Default"
+	// UnmappedNamespacePolicyFail fails the reconciliation of the object, so it is retried with backoff
+	UnmappedNamespacePolicyFail UnmappedNamespacePolicy = "Fail"
+)
+
+type NamespaceRouting struct {
+	// FromAnnotation is the annotation of the source object whose value selects the target namespace
+	FromAnnotation string `json:"fromAnnotation,omitempty"`
+	// FromLabel is the label of the source object whose value selects the target namespace
+	FromLabel string `json:"fromLabel,omitempty"`
+	// Map contains the target namespace for each value
+	Map map[string]string `json:"map,omitempty"`
+	// DefaultNamespace is used for the objects without a mapped value if the unmapped policy is Default
+	DefaultNamespace string `json:"defaultNamespace,omitempty"`
+	// UnmappedPolicy decides what happens with the objects without a mapped value
+	// +kubebuilder:default=Skip
+	UnmappedPolicy UnmappedNamespacePolicy `json:"unmappedPolicy,omitempty"`
+}
+
+// Route returns the target namespace for the source object, routed is false if the object has no mapped value and
+// the unmapped policy is not Default
+func (r NamespaceRouting) Route(source metav1.Object) (namespace string, routed bool) {
+	var value string
+	var ok bool
+	if r.FromAnnotation != "" {
+		value, ok = source.GetAnnotations()[r.FromAnnotation]
+	} else {
+		value, ok = source.GetLabels()[r.FromLabel]
+	}
+
+	if ok {
+		if namespace, ok := r.Map[value]; ok {
+			return namespace, true
+		}
+	}
+
+	if r.UnmappedPolicy == UnmappedNamespacePolicyDefault {
+		return r.DefaultNamespace, true
+	}
+
+	return "", false
+}
+
+// GetStrategy returns the status sync strategy, Replace if it is not set
+func (s StatusSync) GetStrategy() StatusSyncStrategy {
+	if s.Strategy == "" {
+		return StatusSyncStrategyReplace
+	}
+
+	return s.Strategy
+}
+
+// GetUnmappedPolicy returns the unmapped policy, Skip if it is not set
+func (r NamespaceRouting) GetUnmappedPolicy() UnmappedNamespacePolicy {
+	if r.UnmappedPolicy == "" {
+		return UnmappedNamespacePolicySkip
+	}
+
+	return r.UnmappedPolicy
+}
+
+type EndpointsMutations struct {
+	// AddressMap replaces the listed addresses of the endpoints with the mapped ones, it is looked up before the CIDR
+	// translations
+	AddressMap map[string]string `json:"addressMap,omitempty"`
+	// CIDRTranslations move the addresses within the source CIDRs into the target CIDRs, the first matching one is
+	// applied
+	CIDRTranslations []CIDRTranslation `json:"cidrTranslations,omitempty"`
+	// DropUnreachableNodes removes the endpoints on the nodes of the source cluster which are not ready, or which are
+	// not selected by the reachable node selector
+	DropUnreachableNodes bool `json:"dropUnreachableNodes,omitempty"`
+	// ReachableNodeSelector selects the nodes of the source cluster whose endpoints are routable from the local
+	// cluster, every ready node is reachable if it is not set
+	ReachableNodeSelector *metav1.LabelSelector `json:"reachableNodeSelector,omitempty"`
+}
+
+// CIDRTranslation keeps the host bits of the addresses within From and replaces their network bits with the ones of
+// To, e.g. 10.1.2.3 is translated to 172.16.2.3 from 10.1.0.0/16 to 172.16.0.0/16
+type CIDRTranslation struct {
+	// From is the CIDR of the translated source addresses
+	From string `json:"from"`
+	// To is the CIDR the addresses are translated into
+	To string `json:"to"`
+}
+
+type WebhookMutations struct {
+	// ServiceURLTemplate replaces the service references of the client configs with the URL executed from the
+	// template, the services of the source cluster are not reachable through the local service network. The template
+	// gets the data of the overrides, and the replaced service reference as .Service.
+	ServiceURLTemplate string `json:"serviceURLTemplate,omitempty"`
+	// CABundleFrom references the key of a local Secret or ConfigMap the CA bundle of the client configs is set from,
+	// the webhooks are synced again when it changes
+	CABundleFrom *CABundleSource `json:"caBundleFrom,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Secret;ConfigMap
+type CABundleSourceKind string
+
+const (
+	CABundleSourceKindSecret    CABundleSourceKind = "Secret"
+	CABundleSourceKindConfigMap CABundleSourceKind = "ConfigMap"
+)
+
+// CABundleSource references a key of a local Secret or ConfigMap holding PEM encoded CA certificates
+type CABundleSource struct {
+	Kind      CABundleSourceKind `json:"kind"`
+	Name      string             `json:"name"`
+	Namespace string             `json:"namespace"`
+	// Key of the data, defaults to ca.crt.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// GetKey returns the key of the data, or the default CA bundle key if it is not set
+func (r CABundleSource) GetKey() string {
+	if r.Key == "" {
+		return DefaultCABundleKey
+	}
+
+	return r.Key
+}
+
+type PersistentVolumeClaimMutations struct {
+	// StorageClassMap maps the storage class names of the source cluster to the local ones, e.g. fast-ssd to gp3. The
+	// claims are not synced while their storage class does not exist locally.
+	StorageClassMap map[string]string `json:"storageClassMap,omitempty"`
+	// ClearDataSource removes the data sources of the claims, they reference the snapshots and the claims of the
+	// source cluster
+	ClearDataSource bool `json:"clearDataSource,omitempty"`
+}
+
+type ReplicaMutations struct {
+	// Value is the number of replicas of the synced objects, or the percentage of the replicas of the source object
+	// rounded up, e.g. 0 or 50%
+	// +kubebuilder:validation:XIntOrString
+	Value intstr.IntOrString `json:"value"`
+	// Field is the JSONPath of the replica field, e.g. .spec.replicas, it must be set for the kinds other than
+	// Deployments, StatefulSets, ReplicaSets and ReplicationControllers
+	Field string `json:"field,omitempty"`
+	// IgnoreIfHPAManaged leaves the replicas of the objects targeted by a local HorizontalPodAutoscaler to the
+	// autoscaler, the synced objects keep their local number of replicas
+	IgnoreIfHPAManaged bool `json:"ignoreIfHPAManaged,omitempty"`
+}
+
+type SecretDataMutations struct {
+	// IncludeKeys are the only keys kept if specified
+	IncludeKeys []string `json:"includeKeys,omitempty"`
+	// ExcludeKeys are removed after the include keys are applied
+	ExcludeKeys []string `json:"excludeKeys,omitempty"`
 }
 
 func (m Mutations) GetGVK() resources.GroupVersionKind {
@@ -78,14 +662,46 @@ func (m Mutations) GetLabels() LabelMutations {
 	return LabelMutations{}
 }
 
+// +kubebuilder:validation:Enum=add;remove;replace;move;copy;test
+type JSONPatchOperationType string
+
+const (
+	JSONPatchOperationTypeAdd     JSONPatchOperationType = "add"
+	JSONPatchOperationTypeRemove  JSONPatchOperationType = "remove"
+	JSONPatchOperationTypeReplace JSONPatchOperationType = "replace"
+	JSONPatchOperationTypeMove    JSONPatchOperationType = "move"
+	JSONPatchOperationTypeCopy    JSONPatchOperationType = "copy"
+	JSONPatchOperationTypeTest    JSONPatchOperationType = "test"
+)
+
+// JSONPatchOperation is a single RFC 6902 JSON Patch operation.
+type JSONPatchOperation struct {
+	Op   JSONPatchOperationType `json:"op"`
+	Path string                 `json:"path"`
+	// From is the source location of move and copy operations.
+	From string `json:"from,omitempty"`
+	// Value is the JSON encoded value of the operation, templates can be used the same way as in overrides.
+	Value *string `json:"value,omitempty"`
+	// Optional operations are skipped instead of failing the mutation when they could not be applied.
+	Optional bool `json:"optional,omitempty"`
+}
+
 type AnnotationMutations struct {
 	Add    map[string]string `json:"add,omitempty"`
 	Remove []string          `json:"remove,omitempty"`
+	// RemovePrefixes removes the annotations with any of the key prefixes, e.g. kubectl.kubernetes.io/
+	RemovePrefixes []string `json:"removePrefixes,omitempty"`
+	// RemoveRegexes removes the annotations with keys matching any of the RE2 regular expressions
+	RemoveRegexes []string `json:"removeRegexes,omitempty"`
 }
 
 type LabelMutations struct {
 	Add    map[string]string `json:"add,omitempty"`
 	Remove []string          `json:"remove,omitempty"`
+	// RemovePrefixes removes the labels with any of the key prefixes, e.g. kubectl.kubernetes.io/
+	RemovePrefixes []string `json:"removePrefixes,omitempty"`
+	// RemoveRegexes removes the labels with keys matching any of the RE2 regular expressions
+	RemoveRegexes []string `json:"removeRegexes,omitempty"`
 }
 
 type SyncRuleMatch struct {
@@ -135,7 +751,161 @@ const (
 	AnnotationSelectorOpDoesNotExist AnnotationSelectorOperator = "DoesNotExist"
 )
 
-type ResourceSyncRuleStatus struct{}
+type ResourceSyncRuleStatus struct {
+	// Clusters contains the source versions resolved per cluster
+	Clusters   []ResourceSyncRuleClusterStatus `json:"clusters,omitempty"`
+	Conditions []metav1.Condition              `json:"conditions,omitempty"`
+	// LastHandledResync is the value of the resync-requested annotation the objects of the rule were last
+	// enqueued for
+	LastHandledResync string `json:"lastHandledResync,omitempty"`
+	// ObservedGeneration is the generation of the rule the objects of every cluster are synced by
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// ObjectCount is the approximate number of distinct local objects synced by the rule, it is counted against
+	// the maxObjects quota of the rule
+	ObjectCount int `json:"objectCount,omitempty"`
+}
+
+type ResourceSyncRuleClusterStatus struct {
+	Name            string             `json:"name"`
+	ResolvedVersion string             `json:"resolvedVersion,omitempty"`
+	Conditions      []metav1.Condition `json:"conditions,omitempty"`
+	// ObservedGeneration is the generation of the rule the objects of the cluster are synced by, it is recorded once
+	// the objects are reconciled after the rule changed
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// SourceRemoval is the scheduled deletion of the objects synced from the cluster after it was removed from the
+	// registry, by the DeleteAfter source removed policy
+	SourceRemoval *SourceRemoval `json:"sourceRemoval,omitempty"`
+	// TakenOverObjects are the objects updated from the cluster while the cluster owning them is not alive
+	TakenOverObjects []TakenOverObject `json:"takenOverObjects,omitempty"`
+	// DriftedObjectCount is the number of synced objects found to differ from their desired state by the verification
+	DriftedObjectCount int `json:"driftedObjectCount,omitempty"`
+	// DriftedObjects lists the first drifted objects with the paths differing from their desired state
+	DriftedObjects []DriftedObject `json:"driftedObjects,omitempty"`
+	// FailedObjectCount is the number of objects not retried anymore, because they failed to sync too many times
+	FailedObjectCount int `json:"failedObjectCount,omitempty"`
+	// FailedObjects lists the first objects not retried anymore with their last error, they are retried when their
+	// source changes or a resync is requested
+	FailedObjects []FailedObject `json:"failedObjects,omitempty"`
+	// BlockedDeletionCount is the number of synced objects not deleted, because they are protected by the
+	// sync-delete-protected annotation
+	BlockedDeletionCount int `json:"blockedDeletionCount,omitempty"`
+	// BlockedDeletions lists the first synced objects not deleted, because they are protected
+	BlockedDeletions []BlockedDeletion `json:"blockedDeletions,omitempty"`
+	// OversizedObjectCount is the number of source objects not synced, because they are larger than the maximum
+	// object size
+	OversizedObjectCount int `json:"oversizedObjectCount,omitempty"`
+	// OversizedObjects lists the first source objects not synced, because they are too large
+	OversizedObjects []OversizedObject `json:"oversizedObjects,omitempty"`
+}
+
+type SourceRemoval struct {
+	// ClusterID is the ID of the removed cluster the objects are synced from
+	ClusterID string `json:"clusterID"`
+	// RemovedAt is the time the cluster was removed
+	RemovedAt metav1.Time `json:"removedAt"`
+	// DeleteAt is the time the objects synced from the cluster are deleted, unless the cluster is registered again
+	DeleteAt metav1.Time `json:"deleteAt"`
+}
+
+type BlockedDeletion struct {
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Since     metav1.Time `json:"since"`
+}
+
+type OversizedObject struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Size is the size of the source object serialized to JSON in bytes
+	Size  int64       `json:"size"`
+	Since metav1.Time `json:"since"`
+}
+
+type DriftedObject struct {
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Paths     []string    `json:"paths"`
+	Since     metav1.Time `json:"since"`
+}
+
+type FailedObject struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Name       string      `json:"name"`
+	Namespace  string      `json:"namespace,omitempty"`
+	Error      string      `json:"error"`
+	Failures   int         `json:"failures"`
+	Since      metav1.Time `json:"since"`
+}
+
+type TakenOverObject struct {
+	Name           string      `json:"name"`
+	Namespace      string      `json:"namespace,omitempty"`
+	OwnerClusterID string      `json:"ownerClusterID"`
+	Since          metav1.Time `json:"since"`
+}
+
+const (
+	// ResourceSyncRuleConditionTypeAdoptionImmutableConflict is true while objects synced from the cluster are not
+	// updated, because their immutable fields changed and the recreate policy does not allow recreating them
+	ResourceSyncRuleConditionTypeAdoptionImmutableConflict = "AdoptionImmutableConflict"
+	// ResourceSyncRuleConditionTypeOwnershipTakenOver is true while objects synced from the cluster are updated,
+	// although the cluster owning them is not alive
+	ResourceSyncRuleConditionTypeOwnershipTakenOver = "OwnershipTakenOver"
+	// ResourceSyncRuleConditionTypeRuleBlocked is true while objects synced from the cluster are skipped, because
+	// their kind or namespace is denied or not allowed by the controller
+	ResourceSyncRuleConditionTypeRuleBlocked = "RuleBlocked"
+	// ResourceSyncRuleConditionTypeOverriddenByRule is true while objects matched by the rule are synced from the
+	// cluster by other rules with higher priority
+	ResourceSyncRuleConditionTypeOverriddenByRule = "OverriddenByRule"
+	// ResourceSyncRuleConditionTypeSuspended is true while syncing is paused by the suspend field of the rule
+	ResourceSyncRuleConditionTypeSuspended = "Suspended"
+	// ResourceSyncRuleConditionTypeVerificationDrift is true while the verification finds synced objects differing
+	// from the desired state rendered from their source objects
+	ResourceSyncRuleConditionTypeVerificationDrift = "VerificationDrift"
+	// ResourceSyncRuleConditionTypeWaitingForCRD is true while the synced kind is not served by the local cluster, e.g.
+	// because its CRD is not installed yet, the objects are synced once the CRD is established
+	ResourceSyncRuleConditionTypeWaitingForCRD = "WaitingForCRD"
+	// ResourceSyncRuleConditionTypeSyncFailed is true while objects synced from the cluster are not retried anymore,
+	// because they failed to sync too many times in a row
+	ResourceSyncRuleConditionTypeSyncFailed = "SyncFailed"
+	// ResourceSyncRuleConditionTypeDeletionBlocked is true while synced objects are not deleted, because they are
+	// protected by the sync-delete-protected annotation
+	ResourceSyncRuleConditionTypeDeletionBlocked = "DeletionBlocked"
+	// ResourceSyncRuleConditionTypeWatchDegraded is true while the watch of the synced kind on the cluster keeps
+	// failing, so the changes of the source objects are not received
+	ResourceSyncRuleConditionTypeWatchDegraded = "WatchDegraded"
+	// ResourceSyncRuleConditionTypeReady is true once the objects of the rule were synced from every cluster at least
+	// once, the rules depending on the rule start syncing then
+	ResourceSyncRuleConditionTypeReady = "Ready"
+	// ResourceSyncRuleConditionTypeSynced is true once the objects of the rule were synced from the cluster at least
+	// once, it is only set by controllers running in sharded mode to compute the Ready condition across the replicas
+	ResourceSyncRuleConditionTypeSynced = "Synced"
+	// ResourceSyncRuleConditionTypeWaitingForDependencies is true while the rule does not sync, because the rules it
+	// depends on are missing or not ready yet
+	ResourceSyncRuleConditionTypeWaitingForDependencies = "WaitingForDependencies"
+	// ResourceSyncRuleConditionTypeForbidden is true while the identity the objects are written with on the local
+	// cluster is not allowed to write them
+	ResourceSyncRuleConditionTypeForbidden = "Forbidden"
+	// ResourceSyncRuleConditionTypeAccessVerified is true while every permission needed by the rule on the source and
+	// the local cluster is granted, the missing ones are listed in its message otherwise
+	ResourceSyncRuleConditionTypeAccessVerified = "AccessVerified"
+	// ResourceSyncRuleConditionTypeSyncFresh is false while the oldest object waiting in the queue of the cluster waits
+	// for longer than the freshness threshold of the controller
+	ResourceSyncRuleConditionTypeSyncFresh = "SyncFresh"
+	// ResourceSyncRuleConditionTypeCacheSyncing is true while the cache of the source cluster lists the source objects,
+	// its message shows the number of objects listed so far
+	ResourceSyncRuleConditionTypeCacheSyncing = "CacheSyncing"
+	// ResourceSyncRuleConditionTypeWaitingForStorageClass is true while synced PersistentVolumeClaims are held, because
+	// the storage classes they request do not exist in the local cluster
+	ResourceSyncRuleConditionTypeWaitingForStorageClass = "WaitingForStorageClass"
+	// ResourceSyncRuleConditionTypeDeferredBySyncWindow is true while objects wait for the next sync window of the rule,
+	// its message shows their number and the start of the window
+	ResourceSyncRuleConditionTypeDeferredBySyncWindow = "DeferredBySyncWindow"
+	// ResourceSyncRuleConditionTypeQuotaExceeded is true while new objects are not created, because the rule syncs as
+	// many objects as its maxObjects quota
+	ResourceSyncRuleConditionTypeQuotaExceeded = "QuotaExceeded"
+)
 
 // +kubebuilder:object:root=true
 
@@ -0,0 +1,361 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Validate checks the semantic constraints of the spec which cannot be expressed in the CRD schema
+func (r ResourceSyncRuleSpec) Validate() error {
+	if r.GVK.Version != AnyVersion && len(r.Versions) > 0 {
+		return fmt.Errorf("versions: can only be used when the version of the groupVersionKind is %q", AnyVersion)
+	}
+
+	for i, conversion := range r.VersionConversions {
+		if conversion.To != r.NormalizeToVersion {
+			return fmt.Errorf("versionConversions[%d]: converts to %s instead of the normalizeToVersion %q", i, conversion.To, r.NormalizeToVersion)
+		}
+	}
+
+	if r.Backoff != nil {
+		if r.Backoff.BaseDelay.Duration < 0 || r.Backoff.MaxDelay.Duration < 0 {
+			return fmt.Errorf("backoff: delays can not be negative")
+		}
+		if r.Backoff.BaseDelay.Duration > 0 && r.Backoff.MaxDelay.Duration > 0 && r.Backoff.BaseDelay.Duration > r.Backoff.MaxDelay.Duration {
+			return fmt.Errorf("backoff: baseDelay %s is greater than maxDelay %s", r.Backoff.BaseDelay.Duration, r.Backoff.MaxDelay.Duration)
+		}
+	}
+
+	if r.SyncMode == SyncModeEnsureExists && r.Verification != nil {
+		return fmt.Errorf("verification: can not be used with the %s sync mode, the synced objects are not updated", SyncModeEnsureExists)
+	}
+
+	if r.ReconcileTimeout != nil && r.ReconcileTimeout.Duration < 0 {
+		return fmt.Errorf("reconcileTimeout: can not be negative")
+	}
+
+	if r.WriteCoalesceWindow != nil && (r.WriteCoalesceWindow.Duration < 0 || r.WriteCoalesceWindow.Duration > MaxWriteCoalesceWindow) {
+		return fmt.Errorf("writeCoalesceWindow: must be between 0 and %s", MaxWriteCoalesceWindow)
+	}
+
+	if r.MaxObjectSize != nil && r.MaxObjectSize.Sign() < 0 {
+		return fmt.Errorf("maxObjectSize: can not be negative")
+	}
+
+	if r.MaxObjects < 0 {
+		return fmt.Errorf("maxObjects: can not be negative")
+	}
+
+	if r.DeleteAfter != nil && r.DeleteAfter.Duration < 0 {
+		return fmt.Errorf("deleteAfter: can not be negative")
+	}
+
+	if r.SourceRemovedPolicy != nil {
+		if err := r.SourceRemovedPolicy.Validate(); err != nil {
+			return fmt.Errorf("sourceRemovedPolicy: %w", err)
+		}
+	}
+
+	dependencies := make(map[string]struct{}, len(r.DependsOn))
+	for i, name := range r.DependsOn {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("dependsOn[%d]: invalid rule name %q: %s", i, name, strings.Join(errs, ", "))
+		}
+		if _, ok := dependencies[name]; ok {
+			return fmt.Errorf("dependsOn[%d]: duplicate rule %s", i, name)
+		}
+		dependencies[name] = struct{}{}
+	}
+
+	if r.ServiceAccountName != "" {
+		if errs := validation.IsDNS1123Subdomain(r.ServiceAccountName); len(errs) > 0 {
+			return fmt.Errorf("serviceAccountName: invalid service account name %q: %s", r.ServiceAccountName, strings.Join(errs, ", "))
+		}
+	}
+
+	if r.Verification != nil && r.Verification.ObjectsPerMinute < 0 {
+		return fmt.Errorf("verification.objectsPerMinute: can not be negative")
+	}
+
+	if r.SyncWindow != nil {
+		if err := r.SyncWindow.Validate(); err != nil {
+			return fmt.Errorf("syncWindow: %w", err)
+		}
+	}
+
+	for i, rule := range r.Rules {
+		// the version is not compared, so the rules with any version are accepted too
+		if rule.Mutations.SecretData != nil && schema.GroupVersionKind(r.GVK).GroupKind() != corev1.SchemeGroupVersion.WithKind("Secret").GroupKind() {
+			return fmt.Errorf("rules[%d].mutations.secretData: only supported for Secrets, not for %s", i, schema.GroupVersionKind(r.GVK))
+		}
+
+		if rule.Mutations.PersistentVolumeClaims != nil && schema.GroupVersionKind(r.GVK).GroupKind() != corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim").GroupKind() {
+			return fmt.Errorf("rules[%d].mutations.persistentVolumeClaims: only supported for PersistentVolumeClaims, not for %s", i, schema.GroupVersionKind(r.GVK))
+		}
+
+		if replicas := rule.Mutations.Replicas; replicas != nil {
+			if replicas.Field == "" && !IsWorkloadKind(schema.GroupVersionKind(r.GVK).GroupKind()) {
+				return fmt.Errorf("rules[%d].mutations.replicas: field must be set for %s", i, schema.GroupVersionKind(r.GVK))
+			}
+			if err := replicas.Validate(); err != nil {
+				return fmt.Errorf("rules[%d].mutations.replicas: %w", i, err)
+			}
+		}
+
+		if err := validateKeyRemovals(rule.Mutations.GetAnnotations().RemovePrefixes, rule.Mutations.GetAnnotations().RemoveRegexes); err != nil {
+			return fmt.Errorf("rules[%d].mutations.annotations.%w", i, err)
+		}
+
+		if err := validateKeyRemovals(rule.Mutations.GetLabels().RemovePrefixes, rule.Mutations.GetLabels().RemoveRegexes); err != nil {
+			return fmt.Errorf("rules[%d].mutations.labels.%w", i, err)
+		}
+
+		if routing := rule.Mutations.NamespaceRouting; routing != nil {
+			if err := routing.Validate(); err != nil {
+				return fmt.Errorf("rules[%d].mutations.namespaceRouting: %w", i, err)
+			}
+		}
+
+		if endpoints := rule.Mutations.Endpoints; endpoints != nil {
+			if gk := schema.GroupVersionKind(r.GVK).GroupKind(); gk != corev1.SchemeGroupVersion.WithKind("Endpoints").GroupKind() && gk != (schema.GroupKind{Group: "discovery.k8s.io", Kind: "EndpointSlice"}) {
+				return fmt.Errorf("rules[%d].mutations.endpoints: only supported for EndpointSlices and Endpoints, not for %s", i, schema.GroupVersionKind(r.GVK))
+			}
+			if err := endpoints.Validate(); err != nil {
+				return fmt.Errorf("rules[%d].mutations.endpoints: %w", i, err)
+			}
+		}
+
+		if webhooks := rule.Mutations.Webhooks; webhooks != nil {
+			if gk := schema.GroupVersionKind(r.GVK).GroupKind(); gk.Group != admissionregistrationv1.GroupName || gk.Kind != "ValidatingWebhookConfiguration" && gk.Kind != "MutatingWebhookConfiguration" {
+				return fmt.Errorf("rules[%d].mutations.webhooks: only supported for webhook configurations, not for %s", i, schema.GroupVersionKind(r.GVK))
+			}
+			if err := webhooks.Validate(); err != nil {
+				return fmt.Errorf("rules[%d].mutations.webhooks: %w", i, err)
+			}
+		}
+
+		if statusSync := rule.Mutations.StatusSync; statusSync != nil {
+			if err := statusSync.Validate(); err != nil {
+				return fmt.Errorf("rules[%d].mutations.statusSync: %w", i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the duration and the time zone of the windows, the schedule is parsed by the controller
+func (w SyncWindow) Validate() error {
+	if strings.TrimSpace(w.Schedule) == "" {
+		return fmt.Errorf("schedule: must be set")
+	}
+
+	if w.Duration.Duration <= 0 {
+		return fmt.Errorf("duration: must be positive")
+	}
+
+	if _, err := time.LoadLocation(w.TimeZone); err != nil {
+		return fmt.Errorf("timeZone: unknown time zone %q", w.TimeZone)
+	}
+
+	switch w.Deletions {
+	case "", SyncWindowDeletionPolicyDefer, SyncWindowDeletionPolicyImmediate:
+	default:
+		return fmt.Errorf("deletions: unknown policy %q", w.Deletions)
+	}
+
+	return nil
+}
+
+// Validate checks that the grace period is set for the DeleteAfter policy only, and it is positive
+func (p SourceRemovedPolicy) Validate() error {
+	switch p.Type {
+	case SourceRemovedPolicyOrphan, SourceRemovedPolicyDelete:
+		if p.After != nil {
+			return fmt.Errorf("after: can only be used with the %s policy", SourceRemovedPolicyDeleteAfter)
+		}
+	case SourceRemovedPolicyDeleteAfter:
+		if p.After == nil || p.After.Duration <= 0 {
+			return fmt.Errorf("after: must be positive")
+		}
+	default:
+		return fmt.Errorf("type: unknown policy %q", p.Type)
+	}
+
+	return nil
+}
+
+// Validate checks that the fields are set for the Fields strategy only, and each of them is within the status
+func (s StatusSync) Validate() error {
+	if s.GetStrategy() != StatusSyncStrategyFields {
+		if len(s.Fields) > 0 {
+			return fmt.Errorf("fields: can only be used with the %s strategy", StatusSyncStrategyFields)
+		}
+
+		return nil
+	}
+
+	if len(s.Fields) == 0 {
+		return fmt.Errorf("fields: must be set for the %s strategy", StatusSyncStrategyFields)
+	}
+
+	for i, field := range s.Fields {
+		if !strings.HasPrefix(field, ".status.") || strings.HasSuffix(field, ".") || strings.Contains(field, "..") {
+			return fmt.Errorf("fields[%d]: %q is not a field of the status, e.g. .status.phase", i, field)
+		}
+	}
+
+	return nil
+}
+
+// workloadReplicaKinds are the kinds whose replica field is known, see ReplicaMutations
+var workloadReplicaKinds = map[schema.GroupKind]struct{}{
+	{Group: "apps", Kind: "Deployment"}:        {},
+	{Group: "apps", Kind: "StatefulSet"}:       {},
+	{Group: "apps", Kind: "ReplicaSet"}:        {},
+	{Group: "", Kind: "ReplicationController"}: {},
+}
+
+// IsWorkloadKind returns whether the replicas of the kind are in the .spec.replicas field without setting it
+func IsWorkloadKind(gk schema.GroupKind) bool {
+	_, ok := workloadReplicaKinds[gk]
+
+	return ok
+}
+
+// Validate checks that the value is a non-negative number or percentage, and that the field is within the spec
+func (m ReplicaMutations) Validate() error {
+	if m.Value.Type == intstr.Int && m.Value.IntVal < 0 {
+		return fmt.Errorf("value: can not be negative")
+	}
+	if m.Value.Type == intstr.String {
+		percentage, err := strconv.Atoi(strings.TrimSuffix(m.Value.StrVal, "%"))
+		if !strings.HasSuffix(m.Value.StrVal, "%") || err != nil || percentage < 0 {
+			return fmt.Errorf("value: %q is not a number or a percentage, e.g. 0 or 50%%", m.Value.StrVal)
+		}
+	}
+
+	if m.Field != "" && (!strings.HasPrefix(m.Field, ".spec.") || strings.HasSuffix(m.Field, ".") || strings.Contains(m.Field, "..")) {
+		return fmt.Errorf("field: %q is not a field of the spec, e.g. .spec.replicas", m.Field)
+	}
+
+	return nil
+}
+
+// validateKeyRemovals checks that the prefixes of the removed keys are not empty, which would remove every key, and
+// that the regular expressions are valid RE2 expressions
+func validateKeyRemovals(prefixes []string, regexes []string) error {
+	for i, prefix := range prefixes {
+		if prefix == "" {
+			return fmt.Errorf("removePrefixes[%d]: can not be empty", i)
+		}
+	}
+
+	for i, expr := range regexes {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("removeRegexes[%d]: invalid regular expression %q: %w", i, expr, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the routing reads exactly one key and every target namespace is valid
+func (r NamespaceRouting) Validate() error {
+	if (r.FromAnnotation == "") == (r.FromLabel == "") {
+		return fmt.Errorf("exactly one of fromAnnotation and fromLabel must be set")
+	}
+
+	for value, namespace := range r.Map {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("map[%s]: invalid namespace %q: %v", value, namespace, errs)
+		}
+	}
+
+	if r.GetUnmappedPolicy() == UnmappedNamespacePolicyDefault {
+		if r.DefaultNamespace == "" {
+			return fmt.Errorf("defaultNamespace must be set for the %s unmapped policy", UnmappedNamespacePolicyDefault)
+		}
+		if errs := validation.IsDNS1123Label(r.DefaultNamespace); len(errs) > 0 {
+			return fmt.Errorf("defaultNamespace: invalid namespace %q: %v", r.DefaultNamespace, errs)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the mapped addresses are IP addresses, and that the CIDRs of each translation are valid and of
+// the same size
+func (m EndpointsMutations) Validate() error {
+	for address, mapped := range m.AddressMap {
+		if net.ParseIP(mapped) == nil {
+			return fmt.Errorf("addressMap[%s]: invalid address %q", address, mapped)
+		}
+	}
+
+	for i, translation := range m.CIDRTranslations {
+		_, from, err := net.ParseCIDR(translation.From)
+		if err != nil {
+			return fmt.Errorf("cidrTranslations[%d].from: %w", i, err)
+		}
+		_, to, err := net.ParseCIDR(translation.To)
+		if err != nil {
+			return fmt.Errorf("cidrTranslations[%d].to: %w", i, err)
+		}
+		fromOnes, fromBits := from.Mask.Size()
+		toOnes, toBits := to.Mask.Size()
+		if fromOnes != toOnes || fromBits != toBits {
+			return fmt.Errorf("cidrTranslations[%d]: %s and %s are not of the same size", i, translation.From, translation.To)
+		}
+	}
+
+	if m.ReachableNodeSelector != nil {
+		if !m.DropUnreachableNodes {
+			return fmt.Errorf("reachableNodeSelector: can only be used with dropUnreachableNodes")
+		}
+		if _, err := metav1.LabelSelectorAsSelector(m.ReachableNodeSelector); err != nil {
+			return fmt.Errorf("reachableNodeSelector: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the CA bundle source references an object, the URL template is checked when it is executed
+func (m WebhookMutations) Validate() error {
+	if source := m.CABundleFrom; source != nil {
+		if source.Name == "" || source.Namespace == "" {
+			return fmt.Errorf("caBundleFrom: name and namespace must be set")
+		}
+		if source.Kind != CABundleSourceKindSecret && source.Kind != CABundleSourceKindConfigMap {
+			return fmt.Errorf("caBundleFrom.kind: must be %s or %s, not %q", CABundleSourceKindSecret, CABundleSourceKindConfigMap, source.Kind)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SyncAnchorSpec defines the desired state of SyncAnchor
+type SyncAnchorSpec struct {
+	// RuleName is the name of the ResourceSyncRule the anchor belongs to
+	RuleName string `json:"ruleName"`
+}
+
+// +kubebuilder:object:root=true
+
+// SyncAnchor is the owner of the cluster scoped resources synced by a ResourceSyncRule with anchor ownership enabled
+// +kubebuilder:resource:path=syncanchors,scope=Cluster
+// +kubebuilder:printcolumn:name="Rule",type="string",JSONPath=".spec.ruleName"
+type SyncAnchor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SyncAnchorSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// SyncAnchorList contains a list of SyncAnchor
+type SyncAnchorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SyncAnchor `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SyncAnchor{}, &SyncAnchorList{})
+}
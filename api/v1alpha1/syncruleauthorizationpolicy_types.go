@@ -0,0 +1,68 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AnyGroupKind matches every group or kind in a SyncRuleAllowance
+const AnyGroupKind = "*"
+
+// SyncRuleAuthorizationPolicySpec defines which users and groups may author ResourceSyncRules syncing which kinds
+type SyncRuleAuthorizationPolicySpec struct {
+	// Subjects are the users, groups and service accounts the policy applies to
+	Subjects []rbacv1.Subject `json:"subjects"`
+	// Allow lists the rules the subjects may create or update, a rule must be allowed as a whole by one of them
+	Allow []SyncRuleAllowance `json:"allow"`
+}
+
+type SyncRuleAllowance struct {
+	// SourceKinds are the kinds the rule may sync, "*" matches every group or kind
+	SourceKinds []metav1.GroupKind `json:"sourceKinds"`
+	// TargetKinds are the kinds the synced objects may be written as, they default to the source kinds
+	TargetKinds []metav1.GroupKind `json:"targetKinds,omitempty"`
+	// Namespaces are the namespaces the rule may match and write into, every namespace is allowed if empty
+	Namespaces []string `json:"namespaces,omitempty"`
+	// ClusterFeatures are the cluster features the rule may select its source clusters by,
+	// every cluster is allowed if empty
+	ClusterFeatures []string `json:"clusterFeatures,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SyncRuleAuthorizationPolicy allows its subjects to author ResourceSyncRules syncing the listed kinds, it is enforced
+// by the resource sync rule validator webhook when authorization is enabled
+// +kubebuilder:resource:path=syncruleauthorizationpolicies,scope=Cluster,shortName=srap
+type SyncRuleAuthorizationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SyncRuleAuthorizationPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// SyncRuleAuthorizationPolicyList contains a list of SyncRuleAuthorizationPolicy
+type SyncRuleAuthorizationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SyncRuleAuthorizationPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SyncRuleAuthorizationPolicy{}, &SyncRuleAuthorizationPolicyList{})
+}
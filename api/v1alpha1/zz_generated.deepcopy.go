@@ -23,6 +23,7 @@ package v1alpha1
 
 import (
 	"github.com/banzaicloud/operator-tools/pkg/resources"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -42,6 +43,16 @@ func (in *AnnotationMutations) DeepCopyInto(out *AnnotationMutations) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RemovePrefixes != nil {
+		in, out := &in.RemovePrefixes, &out.RemovePrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoveRegexes != nil {
+		in, out := &in.RemoveRegexes, &out.RemoveRegexes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnnotationMutations.
@@ -119,6 +130,52 @@ func (in *AuthInfo) DeepCopy() *AuthInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlockedDeletion) DeepCopyInto(out *BlockedDeletion) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlockedDeletion.
+func (in *BlockedDeletion) DeepCopy() *BlockedDeletion {
+	if in == nil {
+		return nil
+	}
+	out := new(BlockedDeletion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CABundleSource) DeepCopyInto(out *CABundleSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundleSource.
+func (in *CABundleSource) DeepCopy() *CABundleSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CABundleSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CIDRTranslation) DeepCopyInto(out *CIDRTranslation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CIDRTranslation.
+func (in *CIDRTranslation) DeepCopy() *CIDRTranslation {
+	if in == nil {
+		return nil
+	}
+	out := new(CIDRTranslation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Cluster) DeepCopyInto(out *Cluster) {
 	*out = *in
@@ -163,6 +220,26 @@ func (in *ClusterCondition) DeepCopy() *ClusterCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterConnection) DeepCopyInto(out *ClusterConnection) {
+	*out = *in
+	if in.CABundleSecretRef != nil {
+		in, out := &in.CABundleSecretRef, &out.CABundleSecretRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConnection.
+func (in *ClusterConnection) DeepCopy() *ClusterConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterFeature) DeepCopyInto(out *ClusterFeature) {
 	*out = *in
@@ -354,6 +431,11 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Connection != nil {
+		in, out := &in.Connection, &out.Connection
+		*out = new(ClusterConnection)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
@@ -370,6 +452,11 @@ func (in *ClusterSpec) DeepCopy() *ClusterSpec {
 func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 	*out = *in
 	in.ClusterMetadata.DeepCopyInto(&out.ClusterMetadata)
+	if in.SyncState != nil {
+		in, out := &in.SyncState, &out.SyncState
+		*out = new(ClusterSyncState)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]ClusterCondition, len(*in))
@@ -389,6 +476,29 @@ func (in *ClusterStatus) DeepCopy() *ClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSyncState) DeepCopyInto(out *ClusterSyncState) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastErrorTime != nil {
+		in, out := &in.LastErrorTime, &out.LastErrorTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSyncState.
+func (in *ClusterSyncState) DeepCopy() *ClusterSyncState {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSyncState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContentSelector) DeepCopyInto(out *ContentSelector) {
 	*out = *in
@@ -405,6 +515,110 @@ func (in *ContentSelector) DeepCopy() *ContentSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriftedObject) DeepCopyInto(out *DriftedObject) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftedObject.
+func (in *DriftedObject) DeepCopy() *DriftedObject {
+	if in == nil {
+		return nil
+	}
+	out := new(DriftedObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EndpointsMutations) DeepCopyInto(out *EndpointsMutations) {
+	*out = *in
+	if in.AddressMap != nil {
+		in, out := &in.AddressMap, &out.AddressMap
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CIDRTranslations != nil {
+		in, out := &in.CIDRTranslations, &out.CIDRTranslations
+		*out = make([]CIDRTranslation, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReachableNodeSelector != nil {
+		in, out := &in.ReachableNodeSelector, &out.ReachableNodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EndpointsMutations.
+func (in *EndpointsMutations) DeepCopy() *EndpointsMutations {
+	if in == nil {
+		return nil
+	}
+	out := new(EndpointsMutations)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailedObject) DeepCopyInto(out *FailedObject) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailedObject.
+func (in *FailedObject) DeepCopy() *FailedObject {
+	if in == nil {
+		return nil
+	}
+	out := new(FailedObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldMap) DeepCopyInto(out *FieldMap) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldMap.
+func (in *FieldMap) DeepCopy() *FieldMap {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldMap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JSONPatchOperation) DeepCopyInto(out *JSONPatchOperation) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JSONPatchOperation.
+func (in *JSONPatchOperation) DeepCopy() *JSONPatchOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(JSONPatchOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubernetesAPIEndpoint) DeepCopyInto(out *KubernetesAPIEndpoint) {
 	*out = *in
@@ -440,6 +654,16 @@ func (in *LabelMutations) DeepCopyInto(out *LabelMutations) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RemovePrefixes != nil {
+		in, out := &in.RemovePrefixes, &out.RemovePrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoveRegexes != nil {
+		in, out := &in.RemoveRegexes, &out.RemoveRegexes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LabelMutations.
@@ -523,6 +747,48 @@ func (in *Mutations) DeepCopyInto(out *Mutations) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.JSONPatches != nil {
+		in, out := &in.JSONPatches, &out.JSONPatches
+		*out = make([]JSONPatchOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecretData != nil {
+		in, out := &in.SecretData, &out.SecretData
+		*out = new(SecretDataMutations)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceRouting != nil {
+		in, out := &in.NamespaceRouting, &out.NamespaceRouting
+		*out = new(NamespaceRouting)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = new(EndpointsMutations)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = new(WebhookMutations)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PersistentVolumeClaims != nil {
+		in, out := &in.PersistentVolumeClaims, &out.PersistentVolumeClaims
+		*out = new(PersistentVolumeClaimMutations)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(ReplicaMutations)
+		**out = **in
+	}
+	if in.StatusSync != nil {
+		in, out := &in.StatusSync, &out.StatusSync
+		*out = new(StatusSync)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Mutations.
@@ -536,41 +802,48 @@ func (in *Mutations) DeepCopy() *Mutations {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NamespacedName) DeepCopyInto(out *NamespacedName) {
+func (in *NamespaceRouting) DeepCopyInto(out *NamespaceRouting) {
 	*out = *in
+	if in.Map != nil {
+		in, out := &in.Map, &out.Map
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedName.
-func (in *NamespacedName) DeepCopy() *NamespacedName {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceRouting.
+func (in *NamespaceRouting) DeepCopy() *NamespaceRouting {
 	if in == nil {
 		return nil
 	}
-	out := new(NamespacedName)
+	out := new(NamespaceRouting)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceSyncRule) DeepCopyInto(out *ResourceSyncRule) {
+func (in *NamespacedResourceSyncRule) DeepCopyInto(out *NamespacedResourceSyncRule) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSyncRule.
-func (in *ResourceSyncRule) DeepCopy() *ResourceSyncRule {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedResourceSyncRule.
+func (in *NamespacedResourceSyncRule) DeepCopy() *NamespacedResourceSyncRule {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceSyncRule)
+	out := new(NamespacedResourceSyncRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ResourceSyncRule) DeepCopyObject() runtime.Object {
+func (in *NamespacedResourceSyncRule) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -578,31 +851,31 @@ func (in *ResourceSyncRule) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceSyncRuleList) DeepCopyInto(out *ResourceSyncRuleList) {
+func (in *NamespacedResourceSyncRuleList) DeepCopyInto(out *NamespacedResourceSyncRuleList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ResourceSyncRule, len(*in))
+		*out = make([]NamespacedResourceSyncRule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSyncRuleList.
-func (in *ResourceSyncRuleList) DeepCopy() *ResourceSyncRuleList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedResourceSyncRuleList.
+func (in *NamespacedResourceSyncRuleList) DeepCopy() *NamespacedResourceSyncRuleList {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceSyncRuleList)
+	out := new(NamespacedResourceSyncRuleList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ResourceSyncRuleList) DeepCopyObject() runtime.Object {
+func (in *NamespacedResourceSyncRuleList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -610,109 +883,774 @@ func (in *ResourceSyncRuleList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceSyncRuleSpec) DeepCopyInto(out *ResourceSyncRuleSpec) {
+func (in *NamespacedName) DeepCopyInto(out *NamespacedName) {
 	*out = *in
-	if in.ClusterFeatureMatches != nil {
-		in, out := &in.ClusterFeatureMatches, &out.ClusterFeatureMatches
-		*out = make([]ClusterFeatureMatch, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	out.GVK = in.GVK
-	if in.Rules != nil {
-		in, out := &in.Rules, &out.Rules
-		*out = make([]SyncRule, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSyncRuleSpec.
-func (in *ResourceSyncRuleSpec) DeepCopy() *ResourceSyncRuleSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespacedName.
+func (in *NamespacedName) DeepCopy() *NamespacedName {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceSyncRuleSpec)
+	out := new(NamespacedName)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceSyncRuleStatus) DeepCopyInto(out *ResourceSyncRuleStatus) {
+func (in *OversizedObject) DeepCopyInto(out *OversizedObject) {
 	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSyncRuleStatus.
-func (in *ResourceSyncRuleStatus) DeepCopy() *ResourceSyncRuleStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OversizedObject.
+func (in *OversizedObject) DeepCopy() *OversizedObject {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceSyncRuleStatus)
+	out := new(OversizedObject)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SyncRule) DeepCopyInto(out *SyncRule) {
+func (in *PersistentVolumeClaimMutations) DeepCopyInto(out *PersistentVolumeClaimMutations) {
 	*out = *in
-	if in.Matches != nil {
-		in, out := &in.Matches, &out.Matches
-		*out = make([]SyncRuleMatch, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.StorageClassMap != nil {
+		in, out := &in.StorageClassMap, &out.StorageClassMap
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
-	in.Mutations.DeepCopyInto(&out.Mutations)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRule.
-func (in *SyncRule) DeepCopy() *SyncRule {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimMutations.
+func (in *PersistentVolumeClaimMutations) DeepCopy() *PersistentVolumeClaimMutations {
 	if in == nil {
 		return nil
 	}
-	out := new(SyncRule)
+	out := new(PersistentVolumeClaimMutations)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SyncRuleMatch) DeepCopyInto(out *SyncRuleMatch) {
+func (in *ReplicaMutations) DeepCopyInto(out *ReplicaMutations) {
 	*out = *in
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make([]AnnotationSelector, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Content != nil {
-		in, out := &in.Content, &out.Content
-		*out = make([]ContentSelector, len(*in))
-		copy(*out, *in)
-	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make([]v1.LabelSelector, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Namespaces != nil {
-		in, out := &in.Namespaces, &out.Namespaces
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	out.ObjectKey = in.ObjectKey
+	out.Value = in.Value
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRuleMatch.
-func (in *SyncRuleMatch) DeepCopy() *SyncRuleMatch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaMutations.
+func (in *ReplicaMutations) DeepCopy() *ReplicaMutations {
 	if in == nil {
 		return nil
 	}
-	out := new(SyncRuleMatch)
+	out := new(ReplicaMutations)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSyncRule) DeepCopyInto(out *ResourceSyncRule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSyncRule.
+func (in *ResourceSyncRule) DeepCopy() *ResourceSyncRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSyncRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceSyncRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSyncRuleClusterStatus) DeepCopyInto(out *ResourceSyncRuleClusterStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SourceRemoval != nil {
+		in, out := &in.SourceRemoval, &out.SourceRemoval
+		*out = new(SourceRemoval)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TakenOverObjects != nil {
+		in, out := &in.TakenOverObjects, &out.TakenOverObjects
+		*out = make([]TakenOverObject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DriftedObjects != nil {
+		in, out := &in.DriftedObjects, &out.DriftedObjects
+		*out = make([]DriftedObject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FailedObjects != nil {
+		in, out := &in.FailedObjects, &out.FailedObjects
+		*out = make([]FailedObject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.BlockedDeletions != nil {
+		in, out := &in.BlockedDeletions, &out.BlockedDeletions
+		*out = make([]BlockedDeletion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OversizedObjects != nil {
+		in, out := &in.OversizedObjects, &out.OversizedObjects
+		*out = make([]OversizedObject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSyncRuleClusterStatus.
+func (in *ResourceSyncRuleClusterStatus) DeepCopy() *ResourceSyncRuleClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSyncRuleClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSyncRuleList) DeepCopyInto(out *ResourceSyncRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResourceSyncRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSyncRuleList.
+func (in *ResourceSyncRuleList) DeepCopy() *ResourceSyncRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSyncRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResourceSyncRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSyncRuleSpec) DeepCopyInto(out *ResourceSyncRuleSpec) {
+	*out = *in
+	if in.ClusterFeatureMatches != nil {
+		in, out := &in.ClusterFeatureMatches, &out.ClusterFeatureMatches
+		*out = make([]ClusterFeatureMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.GVK = in.GVK
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]SyncRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VersionConversions != nil {
+		in, out := &in.VersionConversions, &out.VersionConversions
+		*out = make([]VersionConversion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeletionPropagation != nil {
+		in, out := &in.DeletionPropagation, &out.DeletionPropagation
+		*out = new(v1.DeletionPropagation)
+		**out = **in
+	}
+	if in.DeleteAfter != nil {
+		in, out := &in.DeleteAfter, &out.DeleteAfter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.SourceRemovedPolicy != nil {
+		in, out := &in.SourceRemovedPolicy, &out.SourceRemovedPolicy
+		*out = new(SourceRemovedPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreserveFinalizers != nil {
+		in, out := &in.PreserveFinalizers, &out.PreserveFinalizers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReconcileTimeout != nil {
+		in, out := &in.ReconcileTimeout, &out.ReconcileTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.WriteCoalesceWindow != nil {
+		in, out := &in.WriteCoalesceWindow, &out.WriteCoalesceWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxObjectSize != nil {
+		in, out := &in.MaxObjectSize, &out.MaxObjectSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Backoff != nil {
+		in, out := &in.Backoff, &out.Backoff
+		*out = new(SyncBackoff)
+		**out = **in
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(SyncVerification)
+		**out = **in
+	}
+	if in.SyncWindow != nil {
+		in, out := &in.SyncWindow, &out.SyncWindow
+		*out = new(SyncWindow)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSyncRuleSpec.
+func (in *ResourceSyncRuleSpec) DeepCopy() *ResourceSyncRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSyncRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceSyncRuleStatus) DeepCopyInto(out *ResourceSyncRuleStatus) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ResourceSyncRuleClusterStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceSyncRuleStatus.
+func (in *ResourceSyncRuleStatus) DeepCopy() *ResourceSyncRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceSyncRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretDataMutations) DeepCopyInto(out *SecretDataMutations) {
+	*out = *in
+	if in.IncludeKeys != nil {
+		in, out := &in.IncludeKeys, &out.IncludeKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeKeys != nil {
+		in, out := &in.ExcludeKeys, &out.ExcludeKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretDataMutations.
+func (in *SecretDataMutations) DeepCopy() *SecretDataMutations {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretDataMutations)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyRef) DeepCopyInto(out *SecretKeyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRef.
+func (in *SecretKeyRef) DeepCopy() *SecretKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceRemoval) DeepCopyInto(out *SourceRemoval) {
+	*out = *in
+	in.RemovedAt.DeepCopyInto(&out.RemovedAt)
+	in.DeleteAt.DeepCopyInto(&out.DeleteAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceRemoval.
+func (in *SourceRemoval) DeepCopy() *SourceRemoval {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceRemoval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceRemovedPolicy) DeepCopyInto(out *SourceRemovedPolicy) {
+	*out = *in
+	if in.After != nil {
+		in, out := &in.After, &out.After
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceRemovedPolicy.
+func (in *SourceRemovedPolicy) DeepCopy() *SourceRemovedPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceRemovedPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatusSync) DeepCopyInto(out *StatusSync) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusSync.
+func (in *StatusSync) DeepCopy() *StatusSync {
+	if in == nil {
+		return nil
+	}
+	out := new(StatusSync)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncAnchor) DeepCopyInto(out *SyncAnchor) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncAnchor.
+func (in *SyncAnchor) DeepCopy() *SyncAnchor {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncAnchor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SyncAnchor) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncAnchorList) DeepCopyInto(out *SyncAnchorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SyncAnchor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncAnchorList.
+func (in *SyncAnchorList) DeepCopy() *SyncAnchorList {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncAnchorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SyncAnchorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncAnchorSpec) DeepCopyInto(out *SyncAnchorSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncAnchorSpec.
+func (in *SyncAnchorSpec) DeepCopy() *SyncAnchorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncAnchorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncBackoff) DeepCopyInto(out *SyncBackoff) {
+	*out = *in
+	out.BaseDelay = in.BaseDelay
+	out.MaxDelay = in.MaxDelay
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncBackoff.
+func (in *SyncBackoff) DeepCopy() *SyncBackoff {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncBackoff)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncRule) DeepCopyInto(out *SyncRule) {
+	*out = *in
+	if in.Matches != nil {
+		in, out := &in.Matches, &out.Matches
+		*out = make([]SyncRuleMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Mutations.DeepCopyInto(&out.Mutations)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRule.
+func (in *SyncRule) DeepCopy() *SyncRule {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncRuleAllowance) DeepCopyInto(out *SyncRuleAllowance) {
+	*out = *in
+	if in.SourceKinds != nil {
+		in, out := &in.SourceKinds, &out.SourceKinds
+		*out = make([]v1.GroupKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.TargetKinds != nil {
+		in, out := &in.TargetKinds, &out.TargetKinds
+		*out = make([]v1.GroupKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterFeatures != nil {
+		in, out := &in.ClusterFeatures, &out.ClusterFeatures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRuleAllowance.
+func (in *SyncRuleAllowance) DeepCopy() *SyncRuleAllowance {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncRuleAllowance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncRuleAuthorizationPolicy) DeepCopyInto(out *SyncRuleAuthorizationPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRuleAuthorizationPolicy.
+func (in *SyncRuleAuthorizationPolicy) DeepCopy() *SyncRuleAuthorizationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncRuleAuthorizationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SyncRuleAuthorizationPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncRuleAuthorizationPolicyList) DeepCopyInto(out *SyncRuleAuthorizationPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SyncRuleAuthorizationPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRuleAuthorizationPolicyList.
+func (in *SyncRuleAuthorizationPolicyList) DeepCopy() *SyncRuleAuthorizationPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncRuleAuthorizationPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SyncRuleAuthorizationPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncRuleAuthorizationPolicySpec) DeepCopyInto(out *SyncRuleAuthorizationPolicySpec) {
+	*out = *in
+	if in.Subjects != nil {
+		in, out := &in.Subjects, &out.Subjects
+		*out = make([]rbacv1.Subject, len(*in))
+		copy(*out, *in)
+	}
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]SyncRuleAllowance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRuleAuthorizationPolicySpec.
+func (in *SyncRuleAuthorizationPolicySpec) DeepCopy() *SyncRuleAuthorizationPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncRuleAuthorizationPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncRuleMatch) DeepCopyInto(out *SyncRuleMatch) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]AnnotationSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Content != nil {
+		in, out := &in.Content, &out.Content
+		*out = make([]ContentSelector, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]v1.LabelSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.ObjectKey = in.ObjectKey
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncRuleMatch.
+func (in *SyncRuleMatch) DeepCopy() *SyncRuleMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncRuleMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncVerification) DeepCopyInto(out *SyncVerification) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncVerification.
+func (in *SyncVerification) DeepCopy() *SyncVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncWindow) DeepCopyInto(out *SyncWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncWindow.
+func (in *SyncWindow) DeepCopy() *SyncWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TakenOverObject) DeepCopyInto(out *TakenOverObject) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TakenOverObject.
+func (in *TakenOverObject) DeepCopy() *TakenOverObject {
+	if in == nil {
+		return nil
+	}
+	out := new(TakenOverObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionConversion) DeepCopyInto(out *VersionConversion) {
+	*out = *in
+	if in.FieldMaps != nil {
+		in, out := &in.FieldMaps, &out.FieldMaps
+		*out = make([]FieldMap, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionConversion.
+func (in *VersionConversion) DeepCopy() *VersionConversion {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionConversion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookMutations) DeepCopyInto(out *WebhookMutations) {
+	*out = *in
+	if in.CABundleFrom != nil {
+		in, out := &in.CABundleFrom, &out.CABundleFrom
+		*out = new(CABundleSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookMutations.
+func (in *WebhookMutations) DeepCopy() *WebhookMutations {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookMutations)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -25,7 +25,15 @@ import (
 	"github.com/spf13/viper"
 	"sigs.k8s.io/yaml"
 
+	"github.com/cisco-open/cluster-registry-controller/controllers"
 	"github.com/cisco-open/cluster-registry-controller/internal/config"
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+	"github.com/cisco-open/cluster-registry-controller/pkg/ratelimit"
+	"github.com/cisco-open/cluster-registry-controller/pkg/sharding"
+	"github.com/cisco-open/cluster-registry-controller/pkg/shutdown"
+	"github.com/cisco-open/cluster-registry-controller/pkg/syncstate"
+	"github.com/cisco-open/cluster-registry-controller/pkg/tracing"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
 )
 
 type Configuration config.Configuration
@@ -77,7 +85,20 @@ func initConfiguration(v *viper.Viper, p *flag.FlagSet) {
 
 	p.String("metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	p.String("health-addr", ":8090", "The address the health endpoint binds to.")
+	p.Duration("health-cluster-unreachable-threshold", clusters.DefaultUnreachableThreshold, "Time since the last contact with a remote cluster after which it is considered unreachable by the readiness check")
+	_ = viper.BindPFlag("health.cluster-unreachable-threshold", p.Lookup("health-cluster-unreachable-threshold"))
+	p.Bool("health-fail-on-unreachable-cluster", false, "Make the controller unready while any remote cluster is unreachable")
+	_ = viper.BindPFlag("health.fail-on-unreachable-cluster", p.Lookup("health-fail-on-unreachable-cluster"))
 	p.Bool("devel-mode", false, "Set development mode (mainly for logging).")
+
+	p.Bool("tracing-enabled", false, "Export the spans of the sync controllers through OTLP")
+	_ = viper.BindPFlag("tracing.enabled", p.Lookup("tracing-enabled"))
+	p.String("tracing-endpoint", "localhost:4317", "The host:port of the OTLP gRPC receiver the spans are exported to")
+	_ = viper.BindPFlag("tracing.endpoint", p.Lookup("tracing-endpoint"))
+	p.Bool("tracing-insecure", false, "Export the spans without TLS")
+	_ = viper.BindPFlag("tracing.insecure", p.Lookup("tracing-insecure"))
+	p.Float64("tracing-sample-ratio", tracing.DefaultSampleRatio, "Ratio of the reconciles traced, unless their parent spans are sampled already")
+	_ = viper.BindPFlag("tracing.sample-ratio", p.Lookup("tracing-sample-ratio"))
 	p.Bool("version", false, "Show version information")
 	p.Bool("dump-config", false, "Dump configuration to the console")
 
@@ -90,6 +111,10 @@ func initConfiguration(v *viper.Viper, p *flag.FlagSet) {
 	_ = viper.BindPFlag("leader-election.name", p.Lookup("leader-election-name"))
 	p.String("leader-election-namespace", "", "Determines the namespace in which the leader election configmap will be created.")
 	_ = viper.BindPFlag("leader-election.namespace", p.Lookup("leader-election-namespace"))
+	p.String("leader-election-mode", string(config.LeaderElectionModeLeader), "Either leader, to run every controller on the elected leader, or sharded, to distribute the clusters across the live replicas by their Leases named after the leader election name")
+	_ = viper.BindPFlag("leader-election.mode", p.Lookup("leader-election-mode"))
+	p.Duration("shard-lease-duration", sharding.DefaultLeaseDuration, "Time after which a replica which did not renew its Lease is dropped from the shard members in sharded mode")
+	_ = viper.BindPFlag("leader-election.shard-lease-duration", p.Lookup("shard-lease-duration"))
 
 	p.Int("log-verbosity", 0, "Log verbosity")
 	_ = viper.BindPFlag("log.verbosity", p.Lookup("log-verbosity"))
@@ -114,9 +139,33 @@ func initConfiguration(v *viper.Viper, p *flag.FlagSet) {
 	p.String("apiserver-endpoint-address", "", "Endpoint address of the API server of the cluster the controller is running on. It is used in the managed cluster secret and/or in the provisioned local cluster resource if one or both of those features are turned on.")
 	_ = viper.BindPFlag("apiserver-endpoint-address", p.Lookup("apiserver-endpoint-address"))
 
+	p.String("local-cluster-id", "", "ID of the cluster the controller is running on, it is the UID of the kube-system namespace of the cluster if not specified")
+	_ = viper.BindPFlag("local-cluster-id", p.Lookup("local-cluster-id"))
+
 	p.Bool("core-resources-source-enabled", true, "Whether to act as a source for core cluster api resources")
 	_ = viper.BindPFlag("core-resources-source-enabled", p.Lookup("core-resources-source-enabled"))
 
+	p.Bool("registry-replication-enabled", false, "Replicate the Cluster resources and their credential secrets from the other registries of the cluster group which enable it")
+	_ = viper.BindPFlag("registry-replication-enabled", p.Lookup("registry-replication-enabled"))
+
+	p.Bool("namespaced-resource-sync-rules-enabled", false, "Start the controller of the namespaced resource sync rules, which only sync objects within their own namespace")
+	_ = viper.BindPFlag("namespaced-resource-sync-rules.enabled", p.Lookup("namespaced-resource-sync-rules-enabled"))
+
+	p.StringToString("namespaced-resource-sync-rule-source-namespaces", nil, "Namespaces of the source clusters the namespaced resource sync rules of a namespace match objects in, e.g. team-a=team-a-prod, the rules match objects in their own namespace if it is not mapped")
+	_ = viper.BindPFlag("namespaced-resource-sync-rules.source-namespaces", p.Lookup("namespaced-resource-sync-rule-source-namespaces"))
+
+	p.Bool("cache-transform-disabled", false, "Keep the managed fields of the objects stored in the informer caches, e.g. for debugging")
+	_ = viper.BindPFlag("cacheTransform.disabled", p.Lookup("cache-transform-disabled"))
+
+	p.Bool("cache-strip-last-applied-configuration", false, "Remove the last applied configuration annotation of kubectl from the objects stored in the informer caches as well")
+	_ = viper.BindPFlag("cacheTransform.stripLastAppliedConfiguration", p.Lookup("cache-strip-last-applied-configuration"))
+
+	p.Int64("cache-list-page-size", clusters.DefaultListPageSize, "Number of objects the informer caches list in a page, so the caches of very large kinds are filled page by page, 0 lists every object of a kind in a single response")
+	_ = viper.BindPFlag("cache-list-page-size", p.Lookup("cache-list-page-size"))
+
+	p.Duration("shutdown-drain-timeout", shutdown.DefaultDrainTimeout, "Longest time the running reconciles are waited for on shutdown before the controllers are stopped, keep it below the termination grace period of the pod")
+	_ = viper.BindPFlag("shutdown-drain-timeout", p.Lookup("shutdown-drain-timeout"))
+
 	p.Bool("cluster-validator-webhook-enabled", true, "Switch to enable the cluster validator webhook functionality.")
 	_ = viper.BindPFlag("cluster-validator-webhook.enabled", p.Lookup("cluster-validator-webhook-enabled"))
 
@@ -129,8 +178,118 @@ func initConfiguration(v *viper.Viper, p *flag.FlagSet) {
 	p.String("cluster-validator-webhook-certificate-directory", "/tmp/webhooks/clusterValidator/certificates", "Path of the directory to store the certificates at.")
 	_ = viper.BindPFlag("cluster-validator-webhook.certificate-directory", p.Lookup("cluster-validator-webhook-certificate-directory"))
 
+	p.Bool("resource-sync-rule-validator-webhook-enabled", true, "Switch to enable the resource sync rule validator webhook functionality, it is served by the cluster validator webhook server.")
+	_ = viper.BindPFlag("resource-sync-rule-validator-webhook.enabled", p.Lookup("resource-sync-rule-validator-webhook-enabled"))
+
+	p.Bool("resource-sync-rule-authorization-enabled", false, "Deny resource sync rules which are not allowed to the requesting user by a sync rule authorization policy, it requires the resource sync rule validator webhook")
+	_ = viper.BindPFlag("resource-sync-rule-validator-webhook.authorization.enabled", p.Lookup("resource-sync-rule-authorization-enabled"))
+
+	p.StringSlice("resource-sync-rule-authorization-exempt-users", nil, "Users whose resource sync rules are not checked against the sync rule authorization policies, e.g. the service account of the controller")
+	_ = viper.BindPFlag("resource-sync-rule-validator-webhook.authorization.exempt-users", p.Lookup("resource-sync-rule-authorization-exempt-users"))
+
+	p.Int("sync-write-format-version", 0, "Format version of the annotations written on synced objects (defaults to the previous version, raise it after every controller replica is upgraded)")
+	_ = viper.BindPFlag("syncController.writeFormatVersion", p.Lookup("sync-write-format-version"))
+
+	p.StringSlice("protected-namespaces", nil, "Namespaces objects are never synced into, regardless of the resource sync rules")
+	_ = viper.BindPFlag("syncController.protectedNamespaces", p.Lookup("protected-namespaces"))
+
+	p.StringSlice("allowed-target-namespaces", nil, "Glob patterns of the only namespaces objects are synced into and deleted from regardless of the resource sync rules, e.g. team-a-*, every namespace is allowed if not set")
+	_ = viper.BindPFlag("syncController.allowedTargetNamespaces", p.Lookup("allowed-target-namespaces"))
+
+	p.StringSlice("denied-gvks", nil, "Kinds which are never synced regardless of the resource sync rules, in [group/]version/kind format where the version can be *")
+	_ = viper.BindPFlag("syncController.deniedGVKs", p.Lookup("denied-gvks"))
+
+	p.StringSlice("redacted-fields", nil, "Sensitive fields whose values are scrubbed from the logs, events and errors in addition to the data of Secrets, in Kind[.group]:.path format")
+	_ = viper.BindPFlag("syncController.redactedFields", p.Lookup("redacted-fields"))
+
+	p.Duration("cluster-probe-interval", clusters.DefaultProbeInterval, "Time between two connectivity probes of a remote cluster")
+	_ = viper.BindPFlag("clusterController.probe.interval", p.Lookup("cluster-probe-interval"))
+
+	p.Duration("cluster-probe-timeout", clusters.DefaultProbeTimeout, "Time a connectivity probe of a remote cluster can take before it fails")
+	_ = viper.BindPFlag("clusterController.probe.timeout", p.Lookup("cluster-probe-timeout"))
+
+	p.Int("cluster-probe-failure-threshold", clusters.DefaultProbeFailureThreshold, "Number of consecutive failed connectivity probes after which a remote cluster is considered dead")
+	_ = viper.BindPFlag("clusterController.probe.failureThreshold", p.Lookup("cluster-probe-failure-threshold"))
+
+	p.Float32("cluster-client-qps", clusters.DefaultClientQPS, "Requests per second the clients of a remote cluster can send, shared by every controller of the cluster")
+	_ = viper.BindPFlag("clusterController.client.qps", p.Lookup("cluster-client-qps"))
+
+	p.Int("cluster-client-burst", clusters.DefaultClientBurst, "Requests the clients of a remote cluster can send at once above the QPS limit")
+	_ = viper.BindPFlag("clusterController.client.burst", p.Lookup("cluster-client-burst"))
+
+	p.Duration("cluster-client-timeout", 0, "Time a request to a remote cluster can take before it fails, 0 means no timeout")
+	_ = viper.BindPFlag("clusterController.client.timeout", p.Lookup("cluster-client-timeout"))
+
+	p.Bool("cluster-client-force-json", false, "Read the built-in kinds from the remote clusters as JSON instead of protobuf, e.g. for clusters behind proxies mishandling protobuf")
+	_ = viper.BindPFlag("clusterController.client.forceJSON", p.Lookup("cluster-client-force-json"))
+
+	p.Int("cluster-endpoint-failure-threshold", clusters.DefaultEndpointFailureThreshold, "Number of consecutive connection errors after which a remote cluster fails over to its next API server endpoint, and of consecutive health checks after which it fails back")
+	_ = viper.BindPFlag("clusterController.endpointFailover.failureThreshold", p.Lookup("cluster-endpoint-failure-threshold"))
+
+	p.Duration("cluster-endpoint-health-check-interval", clusters.DefaultEndpointHealthCheckInterval, "Time between two health checks of the preferred API server endpoints of a remote cluster while it is failed over")
+	_ = viper.BindPFlag("clusterController.endpointFailover.healthCheckInterval", p.Lookup("cluster-endpoint-health-check-interval"))
+
+	p.Int("cluster-watch-failure-threshold", clusters.DefaultWatchFailureThreshold, "Number of consecutive failed list and watch requests of a resource after which the watch of a remote cluster is considered degraded")
+	_ = viper.BindPFlag("clusterController.watchFailureThreshold", p.Lookup("cluster-watch-failure-threshold"))
+
+	p.Float64("cluster-circuit-breaker-error-threshold", clusters.DefaultCircuitBreakerErrorThreshold, "Share of the failed requests to a remote cluster in a window which pauses its reconciles for the cool-down, 0 disables the circuit breaker")
+	_ = viper.BindPFlag("clusterController.circuitBreaker.errorThreshold", p.Lookup("cluster-circuit-breaker-error-threshold"))
+
+	p.Int("cluster-circuit-breaker-min-requests", clusters.DefaultCircuitBreakerMinRequests, "Number of requests to a remote cluster a window needs before its circuit breaker can trip")
+	_ = viper.BindPFlag("clusterController.circuitBreaker.minRequests", p.Lookup("cluster-circuit-breaker-min-requests"))
+
+	p.Duration("cluster-circuit-breaker-window", clusters.DefaultCircuitBreakerWindow, "Time the error rate of the requests to a remote cluster is measured over, and after which a half-open circuit breaker admits more reconciles")
+	_ = viper.BindPFlag("clusterController.circuitBreaker.window", p.Lookup("cluster-circuit-breaker-window"))
+
+	p.Duration("cluster-circuit-breaker-cool-down", clusters.DefaultCircuitBreakerCoolDown, "Time the reconciles of a remote cluster are paused after its circuit breaker tripped")
+	_ = viper.BindPFlag("clusterController.circuitBreaker.coolDown", p.Lookup("cluster-circuit-breaker-cool-down"))
+
+	p.Int("sync-rate-limit-max-keys", ratelimit.DefaultMaxKeys, "Maximum number of objects tracked by the rate limiter of a sync controller, the least recently reconciled ones are evicted first")
+	_ = viper.BindPFlag("syncController.rateLimit.maxKeys", p.Lookup("sync-rate-limit-max-keys"))
+
+	p.Int("sync-failure-threshold", util.DefaultFailureThreshold, "Number of consecutive failures after which an object is not retried until its source changes or a resync is requested, 0 retries the objects forever")
+	_ = viper.BindPFlag("syncController.failureThreshold", p.Lookup("sync-failure-threshold"))
+
+	p.Duration("sync-reconcile-timeout", controllers.DefaultSyncReconcileTimeout, "Maximum time a reconcile of a synced object can take, so an unresponsive API server can not stall the sync controller, 0 disables the limit")
+	_ = viper.BindPFlag("syncController.reconcileTimeout", p.Lookup("sync-reconcile-timeout"))
+
+	p.Duration("sync-full-reconcile-interval", controllers.DefaultFullReconcileInterval, "Longest time a synced object whose desired state is unchanged since its last write is not reconciled in full, 0 reconciles every object in full")
+	_ = viper.BindPFlag("syncController.fullReconcileInterval", p.Lookup("sync-full-reconcile-interval"))
+
+	p.Int64("sync-max-object-size", controllers.DefaultSyncMaxObjectSize, "Largest size in bytes of the source objects serialized to JSON which are synced, larger objects are skipped, 0 disables the limit")
+	_ = viper.BindPFlag("syncController.maxObjectSize", p.Lookup("sync-max-object-size"))
+
+	p.Int64("sync-last-applied-size-limit", controllers.DefaultLastAppliedSizeLimit, "Size in bytes of the synced objects above which they are written without the last applied annotation and compared field by field, 0 disables the limit")
+	_ = viper.BindPFlag("syncController.lastAppliedSizeLimit", p.Lookup("sync-last-applied-size-limit"))
+
+	p.Duration("sync-access-check-interval", controllers.DefaultAccessCheckInterval, "Time between two checks of the permissions needed by the running sync controllers, 0 disables the periodic checks")
+	_ = viper.BindPFlag("syncController.accessCheckInterval", p.Lookup("sync-access-check-interval"))
+
+	p.Duration("sync-freshness-threshold", controllers.DefaultSyncFreshnessThreshold, "Longest time the oldest queued object of a sync controller can wait before the SyncFresh condition of its rule turns false, 0 disables the condition")
+	_ = viper.BindPFlag("syncController.freshnessThreshold", p.Lookup("sync-freshness-threshold"))
+
+	p.Duration("sync-startup-window", controllers.DefaultSyncStartupWindow, "Time the objects listed when a sync controller starts are spread over with random delays, so a restart does not write every object at once, 0 adds them to the queue right away")
+	_ = viper.BindPFlag("syncController.startupWindow", p.Lookup("sync-startup-window"))
+
+	p.Int("sync-max-concurrent-writes", 0, "Number of local writes of the synced objects running at the same time across the sync controllers of every rule, 0 disables the limit")
+	_ = viper.BindPFlag("syncController.maxConcurrentWrites", p.Lookup("sync-max-concurrent-writes"))
+
+	p.Int("sync-max-hops", util.DefaultMaxSyncHops, "Number of clusters an object can be synced through from the cluster it originates from, 0 disables the limit")
+	_ = viper.BindPFlag("syncController.maxSyncHops", p.Lookup("sync-max-hops"))
+
+	p.Duration("sync-state-interval", syncstate.DefaultInterval, "Time between two writes of the summary of the synced objects onto a Cluster resource, raise it to lower the write load of busy installations")
+	_ = viper.BindPFlag("syncController.syncStateInterval", p.Lookup("sync-state-interval"))
+
+	p.Bool("sync-shared-source-watches", false, "Share the watch of a source kind on a cluster between the rules syncing it, every rule keeps its own queue and workers, the events are dispatched to the rules they match")
+	_ = viper.BindPFlag("syncController.sharedSourceWatches", p.Lookup("sync-shared-source-watches"))
+
+	p.Int("sync-log-sample-rate", controllers.DefaultSyncLogSampleRate, "Number of lines per second the high volume messages of the sync controllers, e.g. \"reconciling\", are logged with per rule, the suppressed lines are counted, 0 disables sampling")
+	_ = viper.BindPFlag("syncController.logSampleRate", p.Lookup("sync-log-sample-rate"))
+
+	p.String("sync-audit-log", "", "File the audit records of the synced objects are written to in JSON, \"-\" writes them to the standard output, the synced objects are stamped with the audit hash of their desired state if set")
+	_ = viper.BindPFlag("syncController.auditLog", p.Lookup("sync-audit-log"))
+
 	v.SetDefault("syncController.workerCount", 1)
-	v.SetDefault("syncController.rateLimit.maxKeys", 1024)
 	v.SetDefault("syncController.rateLimit.maxRatePerSecond", 5)
 	v.SetDefault("syncController.rateLimit.maxBurst", 10)
 	v.SetDefault("clusterController.workerCount", 2)
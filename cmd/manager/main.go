@@ -16,15 +16,22 @@ package main
 
 import (
 	"context"
+	"io"
 	"os"
 	"time"
+	// the time zones of the sync windows are resolved without the zoneinfo of the image
+	_ "time/tzdata"
 
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	"go.uber.org/zap/zapcore"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -38,7 +45,11 @@ import (
 	"github.com/cisco-open/cluster-registry-controller/internal/config"
 	"github.com/cisco-open/cluster-registry-controller/pkg/cert"
 	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+	"github.com/cisco-open/cluster-registry-controller/pkg/health"
+	"github.com/cisco-open/cluster-registry-controller/pkg/sharding"
+	"github.com/cisco-open/cluster-registry-controller/pkg/shutdown"
 	"github.com/cisco-open/cluster-registry-controller/pkg/signals"
+	"github.com/cisco-open/cluster-registry-controller/pkg/tracing"
 	"github.com/cisco-open/cluster-registry-controller/pkg/util"
 	"github.com/cisco-open/cluster-registry-controller/pkg/webhooks"
 )
@@ -50,6 +61,9 @@ var (
 
 const FriendlyServiceName = "cluster-registry"
 
+// tracingFlushTimeout is the longest time the pending spans are exported for on exit
+const tracingFlushTimeout = time.Second * 5
+
 func init() {
 	_ = clientgoscheme.AddToScheme(scheme)
 
@@ -88,13 +102,82 @@ func main() {
 		}
 	}
 
+	denyList, err := util.NewDenyList(configuration.SyncController.ProtectedNamespaces, configuration.SyncController.DeniedGVKs)
+	if err != nil {
+		setupLog.Error(err, "invalid deny list")
+		os.Exit(1)
+	}
+
+	if _, err := util.NewNamespaceAllowList(configuration.SyncController.AllowedTargetNamespaces); err != nil {
+		setupLog.Error(err, "invalid allowed target namespaces")
+		os.Exit(1)
+	}
+
+	if _, err := util.NewRedactor(configuration.SyncController.RedactedFields); err != nil {
+		setupLog.Error(err, "invalid redacted fields")
+		os.Exit(1)
+	}
+
+	var auditLog logr.Logger
+	if configuration.SyncController.AuditLog != "" {
+		if auditLog, err = newAuditLogger(configuration.SyncController.AuditLog); err != nil {
+			setupLog.Error(err, "could not open audit log")
+			os.Exit(1)
+		}
+	}
+
+	stopTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Enabled:     configuration.Tracing.Enabled,
+		Endpoint:    configuration.Tracing.Endpoint,
+		Insecure:    configuration.Tracing.Insecure,
+		SampleRatio: configuration.Tracing.SampleRatio,
+		ServiceName: FriendlyServiceName,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
+	sharded := false
+	switch configuration.LeaderElection.Mode {
+	case config.LeaderElectionModeLeader, "":
+	case config.LeaderElectionModeSharded:
+		sharded = true
+	default:
+		setupLog.Error(errors.New("unknown leader election mode"), "invalid leader election mode", "mode", configuration.LeaderElection.Mode)
+		os.Exit(1)
+	}
+
+	// on a signal the running reconciles are drained first, the controllers are only stopped after that
+	drainer := shutdown.NewDrainer(shutdown.WithLogger(ctrl.Log.WithName("shutdown")))
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-signals.NotifyContext(context.Background()).Done()
+		drainer.Drain(configuration.ShutdownDrainTimeout)
+		cancel()
+	}()
+
 	options := ctrl.Options{
 		Scheme:                  scheme,
 		MetricsBindAddress:      configuration.MetricsAddr,
-		LeaderElection:          configuration.LeaderElection.Enabled,
+		LeaderElection:          configuration.LeaderElection.Enabled && !sharded,
 		LeaderElectionID:        configuration.LeaderElection.Name,
 		LeaderElectionNamespace: configuration.LeaderElection.Namespace,
-		HealthProbeBindAddress:  configuration.HealthAddr,
+		// the running reconciles are drained before the manager is stopped, so the lease can be released right away
+		LeaderElectionReleaseOnCancel: true,
+		// the health probes are served by the health server, as the checks of the rules change at runtime
+		HealthProbeBindAddress: "0",
+		NewCache:               controllers.NewCacheFunc(config.Configuration(configuration)),
+		// the local objects are written with the same cached mapper as the remote ones are read with, so that
+		// reconciles never wait for discovery
+		MapperProvider: func(config *rest.Config) (meta.RESTMapper, error) {
+			mapper, err := clusters.NewRESTMapperForConfig(config, clusters.WithRESTMapperName("local"), clusters.WithRESTMapperLogger(ctrl.Log.WithName("rest-mapper")))
+			if err != nil {
+				return nil, err
+			}
+
+			return mapper, mapper.Start(ctx)
+		},
 	}
 
 	if configuration.ClusterValidatorWebhook.Enabled {
@@ -121,6 +204,29 @@ func main() {
 			},
 		)
 
+		if configuration.ResourceSyncRuleValidatorWebhook.Enabled {
+			validatorOpts := []webhooks.ResourceSyncRuleValidatorOption{}
+			if authz := configuration.ResourceSyncRuleValidatorWebhook.Authorization; authz.Enabled {
+				validatorOpts = append(validatorOpts, webhooks.WithAuthorization(authz.ExemptUsers...))
+			}
+
+			mgr.GetWebhookServer().Register(
+				"/validate-resourcesyncrule",
+				&webhook.Admission{
+					Handler: webhooks.NewResourceSyncRuleValidator(ctrl.Log.WithName("resource-sync-rule-validator"), mgr.GetClient(), mgr.GetScheme(), denyList, validatorOpts...),
+				},
+			)
+
+			if configuration.NamespacedResourceSyncRules.Enabled {
+				mgr.GetWebhookServer().Register(
+					"/validate-namespacedresourcesyncrule",
+					&webhook.Admission{
+						Handler: webhooks.NewNamespacedResourceSyncRuleValidator(ctrl.Log.WithName("namespaced-resource-sync-rule-validator"), mgr.GetClient(), mgr.GetScheme(), denyList),
+					},
+				)
+			}
+		}
+
 		clusterValidatorCertRenewer, err := cert.NewRenewer(
 			clusterValidatorLogger,
 			nil,
@@ -152,29 +258,121 @@ func main() {
 		readyzCheckSelector = clusterWebhookCertifier.WebhookCertBundleReadyzChecker()
 	}
 
-	ctx := signals.NotifyContext(context.Background())
+	clustersManagerOptions := []clusters.ManagerOption{clusters.WithReconcileGate(drainer)}
+	if sharded {
+		membership, err := newShardMembership(mgr, configuration)
+		if err != nil {
+			setupLog.Error(err, "unable to set up shard membership")
+			os.Exit(1)
+		}
+
+		if err := mgr.Add(membership); err != nil {
+			setupLog.Error(err, "adding shard membership to manager failed")
+			os.Exit(1)
+		}
+
+		clustersManagerOptions = append(clustersManagerOptions, clusters.WithSharder(membership))
+	}
+
+	clustersManager := clusters.NewManager(ctx, clustersManagerOptions...)
+
+	healthServer := health.NewServer(configuration.HealthAddr, health.WithLogger(ctrl.Log.WithName("health")))
+	if err := mgr.Add(healthServer); err != nil {
+		setupLog.Error(err, "adding health server to manager failed")
+		os.Exit(1)
+	}
 
-	clustersManager := clusters.NewManager(ctx)
+	// the local cluster id is resolved once for every controller, so its changes are reported once
+	localClusterID := controllers.NewLocalClusterIDResolver(configuration.LocalClusterID, ctrl.Log.WithName("local-cluster-id"))
 
-	if err = controllers.NewResourceSyncRuleReconciler("resource-sync-rules", ctrl.Log.WithName("controllers").WithName("resource-sync-rule"), clustersManager, config.Configuration(configuration)).SetupWithManager(ctx, mgr); err != nil {
+	resourceSyncRuleReconciler := controllers.NewResourceSyncRuleReconciler("resource-sync-rules", ctrl.Log.WithName("controllers").WithName("resource-sync-rule"), clustersManager, config.Configuration(configuration))
+	resourceSyncRuleReconciler.SetReadyzChecks(healthServer.Readyz())
+	resourceSyncRuleReconciler.SetLocalClusterIDResolver(localClusterID)
+	if auditLog != nil {
+		resourceSyncRuleReconciler.SetAuditLogger(auditLog)
+	}
+	if err = resourceSyncRuleReconciler.SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "resource-sync-rule")
 		os.Exit(1)
 	}
 
-	if err = controllers.NewClusterReconciler("clusters", ctrl.Log.WithName("controllers").WithName("cluster"), clustersManager, config.Configuration(configuration)).SetupWithManager(ctx, mgr); err != nil {
+	// the namespaced rules are synced by the same sync controllers as the cluster scoped ones
+	if configuration.NamespacedResourceSyncRules.Enabled {
+		namespacedResourceSyncRuleReconciler := controllers.NewNamespacedResourceSyncRuleReconciler("namespaced-resource-sync-rules", ctrl.Log.WithName("controllers").WithName("namespaced-resource-sync-rule"), clustersManager, config.Configuration(configuration), resourceSyncRuleReconciler)
+		if err = namespacedResourceSyncRuleReconciler.SetupWithManager(ctx, mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "namespaced-resource-sync-rule")
+			os.Exit(1)
+		}
+	}
+
+	clusterReconciler := controllers.NewClusterReconciler("clusters", ctrl.Log.WithName("controllers").WithName("cluster"), clustersManager, config.Configuration(configuration))
+	clusterReconciler.SetLocalClusterIDResolver(localClusterID)
+	if err = clusterReconciler.SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "cluster")
 		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
 
-	if err = mgr.AddReadyzCheck("readyz", readyzCheckSelector); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
-		os.Exit(1)
-	}
+	healthServer.Healthz().Add("ping", healthz.Ping)
+	healthServer.Healthz().Add("clusters", clustersManager.HealthzCheck)
+	healthServer.Readyz().Add("readyz", readyzCheckSelector)
+	// the pod turns unready as soon as draining starts
+	healthServer.Readyz().Add("draining", drainer.Check)
+	healthServer.Readyz().Add("clusters", clustersManager.ReadyzCheck(clusters.HealthConfig{
+		UnreachableThreshold: configuration.Health.ClusterUnreachableThreshold,
+		FailOnUnreachable:    configuration.Health.FailOnUnreachableCluster,
+	}))
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctx); err != nil {
+	err = mgr.Start(ctx)
+
+	// the pending spans are exported before exiting
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), tracingFlushTimeout)
+	if err := stopTracing(flushCtx); err != nil {
+		setupLog.Error(err, "could not flush spans")
+	}
+	cancelFlush()
+
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// newShardMembership creates the membership of the replica among the replicas sharing the clusters, the replicas are
+// identified by their host names, which are the names of their pods
+func newShardMembership(mgr ctrl.Manager, configuration Configuration) (*sharding.Membership, error) {
+	identity, err := os.Hostname()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not get host name")
+	}
+
+	namespace := configuration.LeaderElection.Namespace
+	if namespace == "" {
+		namespace = configuration.Namespace
+	}
+
+	return sharding.NewMembership(mgr.GetClient(), mgr.GetAPIReader(), namespace, configuration.LeaderElection.Name, identity,
+		sharding.WithLeaseDuration(configuration.LeaderElection.ShardLeaseDuration),
+		sharding.WithLogger(ctrl.Log.WithName("sharding")),
+	)
+}
+
+// newAuditLogger creates the logger of the audit records of the synced objects, which writes JSON to the file of the
+// audit log, or to the standard output if it is "-"
+func newAuditLogger(path string) (logr.Logger, error) {
+	var out io.Writer = os.Stdout
+	if path != "-" {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not open file", "path", path)
+		}
+		out = file
+	}
+
+	return zap.New(
+		zap.UseDevMode(false),
+		zap.JSONEncoder(),
+		zap.WriteTo(out),
+	).WithName("audit"), nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// rule-eval shows whether a ResourceSyncRule matches an object and the object the controller would write, without
+// deploying the rule:
+//
+//	rule-eval rule.yaml object.yaml
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/ruleeval"
+)
+
+func main() {
+	if len(os.Args) != 3 { // nolint:gomnd
+		fmt.Fprintf(os.Stderr, "usage: %s RULE_FILE OBJECT_FILE\n", os.Args[0])
+		os.Exit(2) // nolint:gomnd
+	}
+
+	if err := run(os.Args[1], os.Args[2]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ruleFile, objectFile string) error {
+	content, err := readYAML(ruleFile)
+	if err != nil {
+		return err
+	}
+	// the unknown fields of the rule are reported, the controller would ignore them silently
+	rule := &clusterregistryv1alpha1.ResourceSyncRule{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(rule); err != nil {
+		return errors.WrapIff(err, "could not decode %s", ruleFile)
+	}
+
+	content, err = readYAML(objectFile)
+	if err != nil {
+		return err
+	}
+	// the unstructured decoder keeps the integers integers, so they can be converted to the typed objects
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(content); err != nil {
+		return errors.WrapIff(err, "could not decode %s", objectFile)
+	}
+
+	result, err := ruleeval.Evaluate(rule, obj)
+	if err != nil {
+		return err
+	}
+
+	if !result.Matched {
+		fmt.Printf("matched: false\nreason: %s\n", result.Reason)
+
+		return nil
+	}
+
+	fmt.Println("matched: true\nmatchedRules:")
+	for _, matched := range result.MatchedRules {
+		if len(matched.Matches) == 0 {
+			fmt.Printf("- rules[%d] (matches every object)\n", matched.Index)
+
+			continue
+		}
+		for _, match := range matched.Matches {
+			fmt.Printf("- rules[%d].match[%d]\n", matched.Index, match)
+		}
+	}
+
+	out, err := yaml.Marshal(result.Object)
+	if err != nil {
+		return errors.WrapIf(err, "could not marshal mutated object")
+	}
+	fmt.Printf("---\n%s", out)
+
+	return nil
+}
+
+// readYAML returns the content of the YAML file converted to JSON
+func readYAML(filename string) ([]byte, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.WrapIff(err, "could not read %s", filename)
+	}
+
+	content, err = yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, errors.WrapIff(err, "could not parse %s", filename)
+	}
+
+	return content, nil
+}
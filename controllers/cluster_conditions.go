@@ -15,6 +15,8 @@
 package controllers
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -22,6 +24,7 @@ import (
 	"k8s.io/client-go/tools/record"
 
 	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
 )
 
 type ClusterConditionsMap map[clusterregistryv1alpha1.ClusterConditionType]clusterregistryv1alpha1.ClusterCondition
@@ -179,3 +182,108 @@ func ClustersSyncedCondition(err error) clusterregistryv1alpha1.ClusterCondition
 
 	return condition
 }
+
+// ClusterConnectionCondition reports whether the connection to the cluster could be built, err is the error of
+// building it
+func ClusterConnectionCondition(err error) clusterregistryv1alpha1.ClusterCondition {
+	condition := clusterregistryv1alpha1.ClusterCondition{
+		Type:   clusterregistryv1alpha1.ClusterConditionTypeConnection,
+		Status: corev1.ConditionUnknown,
+	}
+
+	if err == nil {
+		condition.Reason = "ConnectionConfigured"
+		condition.Message = "connection is configured"
+		condition.Status = corev1.ConditionTrue
+
+		return condition
+	}
+
+	condition.Reason = "ConnectionNotConfigured"
+	condition.Message = err.Error()
+	condition.Status = corev1.ConditionFalse
+
+	return condition
+}
+
+// ClusterWatchDegradedCondition reports the watches of the cluster whose list and watch requests keep failing
+func ClusterWatchDegradedCondition(degraded []clusters.WatchStatus) clusterregistryv1alpha1.ClusterCondition {
+	condition := clusterregistryv1alpha1.ClusterCondition{
+		Type:    clusterregistryv1alpha1.ClusterConditionTypeWatchDegraded,
+		Status:  corev1.ConditionFalse,
+		Reason:  "WatchesAreHealthy",
+		Message: "every watch is healthy",
+
+		TrueIsFailure: true,
+	}
+
+	if len(degraded) == 0 {
+		return condition
+	}
+
+	resources := make([]string, 0, len(degraded))
+	for _, status := range degraded {
+		resources = append(resources, fmt.Sprintf("%s (%d failures: %s)", status.Resource.GroupResource(), status.ConsecutiveFailures, status.LastError))
+	}
+
+	condition.Reason = "WatchesAreDegraded"
+	condition.Message = fmt.Sprintf("list and watch requests keep failing for %s", strings.Join(resources, ", "))
+	condition.Status = corev1.ConditionTrue
+
+	return condition
+}
+
+// ClusterBackoffCondition reports whether the circuit breaker of the cluster paused the reconciles of its controllers
+func ClusterBackoffCondition(status clusters.CircuitBreakerStatus) clusterregistryv1alpha1.ClusterCondition {
+	condition := clusterregistryv1alpha1.ClusterCondition{
+		Type:    clusterregistryv1alpha1.ClusterConditionTypeBackoff,
+		Status:  corev1.ConditionFalse,
+		Reason:  "CircuitBreakerIsClosed",
+		Message: "every reconcile is admitted",
+
+		TrueIsFailure: true,
+	}
+
+	switch status.State {
+	case clusters.CircuitBreakerStateOpen:
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = "CircuitBreakerIsOpen"
+		condition.Message = fmt.Sprintf("%.0f%% of the requests failed (last error: %s), reconciles are paused until %s",
+			status.ErrorRate*100, status.LastError, status.RetryTime.UTC().Format(time.RFC3339))
+	case clusters.CircuitBreakerStateHalfOpen:
+		condition.Status = corev1.ConditionTrue
+		condition.Reason = "CircuitBreakerIsHalfOpen"
+		condition.Message = fmt.Sprintf("%.1f%% of the reconciles are admitted while the cluster recovers", status.AdmittedRatio*100)
+	case clusters.CircuitBreakerStateClosed:
+	}
+
+	return condition
+}
+
+func ClusterReachableCondition(status clusters.ProbeStatus) clusterregistryv1alpha1.ClusterCondition {
+	condition := clusterregistryv1alpha1.ClusterCondition{
+		Type:   clusterregistryv1alpha1.ClusterConditionTypeReachable,
+		Status: corev1.ConditionUnknown,
+	}
+
+	if status.LastProbeTime.IsZero() {
+		condition.Reason = "ClusterIsNotProbed"
+		condition.Message = "cluster is not probed yet"
+
+		return condition
+	}
+
+	if status.Reachable {
+		condition.Reason = "ClusterIsReachable"
+		condition.Message = "cluster is reachable"
+		condition.Status = corev1.ConditionTrue
+
+		return condition
+	}
+
+	condition.Reason = "ClusterIsNotReachable"
+	condition.Message = fmt.Sprintf("%d consecutive probes failed: %s", status.ConsecutiveFailures, status.LastError)
+	condition.Status = corev1.ConditionFalse
+
+	return condition
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultLocalClusterIDRefreshInterval is how often the resolved ID of the local cluster is read again, so a
+	// changed ID is picked up without restarting the controller
+	DefaultLocalClusterIDRefreshInterval = time.Minute * 5
+
+	localClusterIDMinBackoff = time.Second
+	localClusterIDMaxBackoff = time.Minute * 2
+)
+
+// ErrLocalClusterIDUnresolved is returned while the ID of the local cluster could not be resolved
+var ErrLocalClusterIDUnresolved = errors.New("local cluster id is not resolved")
+
+// LocalClusterIDResolver resolves the ID of the local cluster, which is the UID of its kube-system namespace unless
+// it is overridden. The resolved ID is cached and refreshed periodically, failed resolutions are retried with backoff.
+type LocalClusterIDResolver struct {
+	override        types.UID
+	refreshInterval time.Duration
+	log             logr.Logger
+
+	mu         sync.Mutex
+	id         types.UID
+	resolvedAt time.Time
+	failures   int
+	retryAt    time.Time
+	lastErr    error
+}
+
+// NewLocalClusterIDResolver returns a resolver of the ID of the local cluster, a non-empty override is used as the ID
+// without reading the cluster
+func NewLocalClusterIDResolver(override string, log logr.Logger) *LocalClusterIDResolver {
+	return &LocalClusterIDResolver{
+		override:        types.UID(override),
+		refreshInterval: DefaultLocalClusterIDRefreshInterval,
+		log:             log,
+	}
+}
+
+// Resolve returns the ID of the local cluster, it is read from the cluster if it is not resolved yet or the previous
+// resolution is older than the refresh interval. An event is recorded on the kube-system namespace when the ID is
+// established or changes. The errors wrap ErrLocalClusterIDUnresolved, the resolution is not retried before Backoff
+// passes.
+func (r *LocalClusterIDResolver) Resolve(ctx context.Context, c client.Reader, recorder record.EventRecorder) (types.UID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.override != "" {
+		if r.id == "" {
+			r.setID(r.override, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem}}, recorder)
+		}
+
+		return r.id, nil
+	}
+
+	now := time.Now()
+	if r.id != "" && now.Sub(r.resolvedAt) < r.refreshInterval {
+		return r.id, nil
+	}
+	if r.id == "" && r.lastErr != nil && now.Before(r.retryAt) {
+		return "", r.lastErr
+	}
+
+	ns := &corev1.Namespace{}
+	err := c.Get(ctx, client.ObjectKey{Name: metav1.NamespaceSystem}, ns)
+	if err == nil && ns.GetUID() == "" {
+		err = errors.New("kube-system namespace has no uid")
+	}
+	if err != nil {
+		if r.id != "" {
+			// the previously resolved ID is kept until it can be read again
+			r.log.Error(err, "could not refresh local cluster id, keeping the resolved one", "id", r.id)
+			r.resolvedAt = now
+
+			return r.id, nil
+		}
+
+		r.retryAt = now.Add(r.nextBackoff())
+		r.failures++
+		r.lastErr = errors.WithDetails(errors.WrapIf(ErrLocalClusterIDUnresolved, err.Error()), "retryAt", r.retryAt)
+
+		return "", r.lastErr
+	}
+
+	r.failures = 0
+	r.lastErr = nil
+	r.resolvedAt = now
+	if ns.GetUID() != r.id {
+		r.setID(ns.GetUID(), ns, recorder)
+	}
+
+	return r.id, nil
+}
+
+// Get returns the last resolved ID of the local cluster without reading the cluster
+func (r *LocalClusterIDResolver) Get() types.UID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.id
+}
+
+// Backoff returns the time after which a failed resolution is retried
+func (r *LocalClusterIDResolver) Backoff() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lastErr == nil {
+		return 0
+	}
+
+	if backoff := time.Until(r.retryAt); backoff > 0 {
+		return backoff
+	}
+
+	return localClusterIDMinBackoff
+}
+
+// nextBackoff must be called with mu held
+func (r *LocalClusterIDResolver) nextBackoff() time.Duration {
+	backoff := localClusterIDMinBackoff
+	for i := 0; i < r.failures && backoff < localClusterIDMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > localClusterIDMaxBackoff {
+		backoff = localClusterIDMaxBackoff
+	}
+
+	return backoff
+}
+
+// setID must be called with mu held
+func (r *LocalClusterIDResolver) setID(id types.UID, ns *corev1.Namespace, recorder record.EventRecorder) {
+	previous := r.id
+	r.id = id
+
+	reason, msg := "LocalClusterIDEstablished", fmt.Sprintf("local cluster id is %s", id)
+	if previous != "" {
+		reason, msg = "LocalClusterIDChanged", fmt.Sprintf("local cluster id changed from %s to %s", previous, id)
+	}
+	if r.override != "" {
+		msg += " (overridden)"
+	}
+
+	r.log.Info(msg, "id", id, "previousID", previous)
+	if recorder != nil {
+		recorder.Event(ns, corev1.EventTypeNormal, reason, msg)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/cisco-open/cluster-registry-controller/controllers"
+)
+
+var _ = Describe("Local cluster ID resolver", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	It("uses the overridden id without reading the cluster", func() {
+		recorder := record.NewFakeRecorder(10)
+		resolver := controllers.NewLocalClusterIDResolver("overridden", logr.Discard())
+
+		// the client is not used, a nil one would panic if it was
+		id, err := resolver.Resolve(context.Background(), nil, recorder)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(id).Should(Equal(types.UID("overridden")))
+		Expect(recorder.Events).Should(Receive(ContainSubstring("LocalClusterIDEstablished")))
+	})
+
+	It("retries with backoff until the kube-system namespace can be read", func() {
+		ctx := context.Background()
+		c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+		recorder := record.NewFakeRecorder(10)
+		resolver := controllers.NewLocalClusterIDResolver("", logr.Discard())
+
+		_, err := resolver.Resolve(ctx, c, recorder)
+		Expect(errors.Is(err, controllers.ErrLocalClusterIDUnresolved)).Should(BeTrue())
+		Expect(resolver.Backoff()).Should(BeNumerically(">", 0))
+
+		Expect(c.Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: metav1.NamespaceSystem,
+				UID:  "kube-system-uid",
+			},
+		})).Should(Succeed())
+
+		Eventually(func() (types.UID, error) {
+			return resolver.Resolve(ctx, c, recorder)
+		}, timeout, interval).Should(Equal(types.UID("kube-system-uid")))
+		Expect(resolver.Get()).Should(Equal(types.UID("kube-system-uid")))
+		Expect(resolver.Backoff()).Should(BeZero())
+		Expect(recorder.Events).Should(Receive(ContainSubstring("LocalClusterIDEstablished")))
+	})
+})
@@ -0,0 +1,52 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+const (
+	// ClusterProviderName is the name of the default cluster provider, which provides the clusters described by
+	// the Cluster custom resources and their Secrets on the local cluster
+	ClusterProviderName = "cluster-custom-resources"
+
+	// ClusterMetadataSecretID is the metadata key of the namespace/name of the Secret of the cluster
+	ClusterMetadataSecretID = "secretID"
+	// ClusterMetadataAPIEndpoints is the metadata key of the API server endpoints the cluster is connected through
+	ClusterMetadataAPIEndpoints = "apiEndpoints"
+)
+
+// clusterProvider lets the cluster reconciler manage the remote clusters through the clusters manager
+type clusterProvider struct {
+	reconciler *ClusterReconciler
+}
+
+func (p *clusterProvider) GetName() string {
+	return ClusterProviderName
+}
+
+func (p *clusterProvider) Start(ctx context.Context, callbacks clusters.ClusterProviderCallbacks) error {
+	p.reconciler.setClusterProviderCallbacks(callbacks)
+
+	go func() {
+		<-ctx.Done()
+		p.reconciler.setClusterProviderCallbacks(nil)
+	}()
+
+	return nil
+}
@@ -17,8 +17,11 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
+	"sync"
 	"time"
 
 	"emperror.dev/errors"
@@ -27,6 +30,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -53,23 +57,52 @@ type ClusterReconciler struct {
 	clustersManager *clusters.Manager
 	config          config.Configuration
 
-	clusterID types.UID
-	queue     workqueue.RateLimitingInterface
+	// clusters manages the remote clusters through the callbacks of the default cluster provider
+	clusters   clusters.ClusterProviderCallbacks
+	clustersMu sync.RWMutex
+
+	// localClusterID resolves the ID of the cluster the controller runs on
+	localClusterID *LocalClusterIDResolver
+	queue          workqueue.RateLimitingInterface
+
+	// probeReports contains the time the probe status of each cluster was last reported
+	probeReports   map[string]time.Time
+	probeReportsMu sync.Mutex
+
+	// triggers enqueues the clusters, and the removed clusters with scheduled deletions, when the controller starts
+	triggers *InMemorySource
 }
 
+// clusterReachableHeartbeatInterval is how often the ClusterReachable condition is refreshed while it does not change
+const clusterReachableHeartbeatInterval = time.Minute
+
 func NewClusterReconciler(name string, log logr.Logger, clustersManager *clusters.Manager, config config.Configuration) *ClusterReconciler {
 	return &ClusterReconciler{
 		ManagedReconciler: clusters.NewManagedReconciler(name, log),
 
 		clustersManager: clustersManager,
 		config:          config,
+		localClusterID:  NewLocalClusterIDResolver(config.LocalClusterID, log.WithName("local-cluster-id")),
+		probeReports:    make(map[string]time.Time),
 	}
 }
 
+// SetLocalClusterIDResolver sets the resolver of the ID of the local cluster, so it can be shared with the sync
+// controllers, it must be called before the reconciler is started
+func (r *ClusterReconciler) SetLocalClusterIDResolver(resolver *LocalClusterIDResolver) {
+	r.localClusterID = resolver
+}
+
 func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.GetLogger().WithValues("cluster", req.NamespacedName)
 
-	err := r.setClusterID(ctx)
+	// the clusters are not reconciled until the local one can be told apart, e.g. while the API server is unreachable
+	clusterID, err := r.localClusterID.Resolve(ctx, r.GetClient(), r.GetRecorder())
+	if errors.Is(err, ErrLocalClusterIDUnresolved) {
+		log.Info("local cluster id is not resolved, retrying", append([]interface{}{"error", err.Error()}, errors.GetDetails(err)...)...)
+
+		return ctrl.Result{RequeueAfter: r.localClusterID.Backoff()}, nil
+	}
 	if err != nil {
 		return ctrl.Result{}, errors.WithStackIf(err)
 	}
@@ -89,16 +122,52 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			return ctrl.Result{}, errors.WithStackIf(err)
 		}
 
+		// the deletions of the objects synced from the removed cluster are applied once they are due
+		var result ctrl.Result
+		if r.clustersManager.Owns(req.NamespacedName.Name) {
+			if result, err = r.reconcileSourceRemovals(ctx, req.NamespacedName.Name, log); err != nil {
+				return ctrl.Result{}, errors.WithStackIf(err)
+			}
+		}
+
 		log.Info("reconciled successfully")
 
-		return ctrl.Result{}, nil
+		return result, nil
 	}
 	if err != nil {
 		return ctrl.Result{}, errors.WrapIf(err, "could not get object")
 	}
 
-	isClusterLocal := cluster.Spec.ClusterID == r.clusterID
+	// in sharded mode only the replica the cluster is assigned to connects to it and writes its status
+	if !r.clustersManager.Owns(cluster.Name) {
+		removeErr := r.removeRemoteCluster(cluster.Name)
+		if removeErr != nil && !errors.Is(errors.Cause(removeErr), clusters.ErrClusterNotFound) {
+			return ctrl.Result{}, errors.WithStackIf(removeErr)
+		}
 
+		log.Info("cluster is assigned to another replica")
+
+		return ctrl.Result{}, nil
+	}
+
+	isClusterLocal := cluster.Spec.ClusterID == clusterID
+
+	if !cluster.GetDeletionTimestamp().IsZero() {
+		return r.reconcileClusterDeletion(ctx, cluster, isClusterLocal, log)
+	}
+
+	if !isClusterLocal {
+		if err := r.ensureSourceRemovedFinalizer(ctx, cluster); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// the cluster is registered again, so the objects synced from it are kept
+		if err := r.cancelSourceRemovals(ctx, cluster, log); err != nil {
+			log.Error(err, "could not cancel scheduled deletions of synced objects")
+		}
+	}
+
+	previousAPIEndpoint := cluster.Status.APIEndpoint
 	cluster.Status = cluster.Status.Reset()
 	if isClusterLocal {
 		// try remove existing remote cluster instance, since a local cluster could have been a remote earlier
@@ -116,6 +185,7 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	currentConditions := GetCurrentConditions(cluster)
 
 	var reconcileError error
+	var conditionsChanged bool
 	if _, ok := cluster.GetAnnotations()[clusterregistryv1alpha1.ClusterDisabledAnnotation]; ok { //nolint:nestif
 		removeErr := r.removeRemoteCluster(cluster.Name)
 		if removeErr != nil && !errors.Is(errors.Cause(removeErr), clusters.ErrClusterNotFound) {
@@ -133,13 +203,20 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		if isClusterLocal {
 			reconcileError = r.reconcileLocalCluster(ctx, cluster, currentConditions)
 		} else {
+			previousConnection := currentConditions[clusterregistryv1alpha1.ClusterConditionTypeConnection]
 			reconcileError = r.reconcileRemoteCluster(ctx, cluster, currentConditions)
+			// a fixed connection is reported even if the cluster is not probed yet
+			conditionsChanged = r.setClusterReachableCondition(cluster, currentConditions) ||
+				r.setClusterWatchDegradedCondition(cluster, currentConditions) ||
+				r.setClusterBackoffCondition(cluster, currentConditions) ||
+				previousConnection.Status != currentConditions[clusterregistryv1alpha1.ClusterConditionTypeConnection].Status ||
+				previousAPIEndpoint != cluster.Status.APIEndpoint
 		}
 
 		SetCondition(cluster, currentConditions, ClusterReadyCondition(err), r.GetRecorder())
 	}
 
-	if isClusterLocal || reconcileError != nil {
+	if isClusterLocal || reconcileError != nil || conditionsChanged {
 		// status needs to be updated if the cluster is local or if there was any error setting up a remote cluster instance
 		err = UpdateCluster(ctx, reconcileError, r.GetClient(), cluster, currentConditions, log)
 		if err != nil {
@@ -178,8 +255,13 @@ func (r *ClusterReconciler) getK8SConfigForCluster(ctx context.Context, namespac
 func (r *ClusterReconciler) getRemoteCluster(ctx context.Context, cluster *clusterregistryv1alpha1.Cluster) (*clusters.Cluster, error) {
 	log := r.GetLogger().WithValues("cluster", cluster.Name)
 
+	clusterCallbacks, err := r.getClusterProviderCallbacks()
+	if err != nil {
+		return nil, err
+	}
+
 	secretID := fmt.Sprintf("%s/%s", cluster.Spec.AuthInfo.SecretRef.Namespace, cluster.Spec.AuthInfo.SecretRef.Name)
-	remoteCluster, _ := r.clustersManager.Get(cluster.Name)
+	remoteCluster, _ := clusterCallbacks.Get(cluster.Name)
 
 	k8sconfig, err := r.getK8SConfigForCluster(ctx, cluster.Spec.AuthInfo.SecretRef.Namespace, cluster.Spec.AuthInfo.SecretRef.Name)
 	if err != nil {
@@ -187,7 +269,7 @@ func (r *ClusterReconciler) getRemoteCluster(ctx context.Context, cluster *clust
 
 		if remoteCluster != nil {
 			log.Info("cluster secret is removed")
-			err := r.clustersManager.Remove(remoteCluster)
+			err := clusterCallbacks.Remove(remoteCluster.GetName())
 			if err != nil {
 				return nil, errors.WrapIf(err, "could not remove cluster from manager")
 			}
@@ -196,10 +278,31 @@ func (r *ClusterReconciler) getRemoteCluster(ctx context.Context, cluster *clust
 		return nil, WrapAsPermanentError(err)
 	}
 
+	probeConfig, err := r.getProbeConfig(cluster)
+	if err != nil {
+		return nil, WrapAsPermanentError(err)
+	}
+
+	clientConfig, err := r.getClientConfig(cluster)
+	if err != nil {
+		return nil, WrapAsPermanentError(err)
+	}
+
+	connectionConfig, err := r.getConnectionConfig(ctx, cluster)
+	if err != nil {
+		return nil, WrapAsPermanentError(err)
+	}
+
+	metadata, err := r.getClusterMetadata(cluster, secretID)
+	if err != nil {
+		return nil, WrapAsPermanentError(err)
+	}
+
 	if remoteCluster != nil { // nolint:nestif
-		if !remoteCluster.IsAlive() {
-			return nil, WrapAsPermanentError(errors.New("remote cluster is not alive"))
-		}
+		// a new probe or client config is applied even if the cluster is dead, since it might be dead because of the old one
+		connectionConfigChanged := remoteCluster.GetProbeConfig() != probeConfig || remoteCluster.GetClientConfig() != clientConfig ||
+			!remoteCluster.GetConnectionConfig().Equal(connectionConfig) ||
+			remoteCluster.GetMetadata()[ClusterMetadataAPIEndpoints] != metadata[ClusterMetadataAPIEndpoints]
 		credentialsChanged := false
 		if remoteCluster.GetSecretID() != nil && *remoteCluster.GetSecretID() != secretID {
 			log.Info("cluster secret reference changed")
@@ -209,83 +312,468 @@ func (r *ClusterReconciler) getRemoteCluster(ctx context.Context, cluster *clust
 			log.Info("cluster secret content changed")
 			credentialsChanged = true
 		}
-		if !credentialsChanged {
+
+		// rotated credentials are reloaded in place so the controllers of the cluster are kept, a dead cluster is
+		// reloaded as well since it might be dead because of the expired credentials
+		if credentialsChanged && !connectionConfigChanged {
+			return r.reloadRemoteClusterCredentials(cluster, clusterCallbacks, metadata, k8sconfig, connectionConfig)
+		}
+
+		if !remoteCluster.IsAlive() && !connectionConfigChanged {
+			return nil, WrapAsPermanentError(errors.New("remote cluster is not alive"))
+		}
+		if !connectionConfigChanged {
 			return remoteCluster, nil
 		}
-		err := r.clustersManager.Remove(remoteCluster)
+		log.Info("cluster probe, client, connection or endpoint config changed")
+		err := clusterCallbacks.Remove(remoteCluster.GetName())
 		if err != nil {
 			return nil, errors.WrapIf(err, "could not remove cluster from manager")
 		}
 	}
 
+	restConfig, failover, err := r.getRestConfig(cluster, k8sconfig, connectionConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	onDeadFunc := func(c *clusters.Cluster) error {
+		if r.queue != nil {
+			r.queue.Add(reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: c.GetName(),
+				},
+			})
+		}
+
+		return nil
+	}
+
+	options := []clusters.Option{
+		clusters.WithEndpointFailover(failover),
+		clusters.WithLogger(r.GetLogger()),
+		clusters.WithSecretID(secretID),
+		clusters.WithCtrlOption(ctrl.Options{
+			Scheme:             r.GetManager().GetScheme(),
+			MetricsBindAddress: "0",
+			Port:               0,
+			NewCache:           NewCacheFunc(r.config),
+		}),
+		clusters.WithOnDeadFunc(onDeadFunc),
+		clusters.WithKubeconfig(k8sconfig),
+		clusters.WithProbeConfig(probeConfig),
+		clusters.WithClientConfig(clientConfig),
+		clusters.WithConnectionConfig(connectionConfig),
+		clusters.WithOnProbeFunc(r.onClusterProbe),
+		clusters.WithWatchFailureThreshold(r.config.ClusterController.WatchFailureThreshold),
+		clusters.WithOnWatchStatusFunc(r.onClusterWatchStatus),
+	}
+	if breakerConfig := r.config.ClusterController.CircuitBreaker; breakerConfig.ErrorThreshold > 0 {
+		options = append(options,
+			clusters.WithCircuitBreakerConfig(clusters.CircuitBreakerConfig{
+				ErrorThreshold: breakerConfig.ErrorThreshold,
+				MinRequests:    breakerConfig.MinRequests,
+				Window:         breakerConfig.Window,
+				CoolDown:       breakerConfig.CoolDown,
+			}),
+			clusters.WithOnCircuitBreakerFunc(r.onClusterCircuitBreaker),
+		)
+	}
+
+	remoteCluster, err = clusterCallbacks.Add(clusters.ClusterConfig{
+		Name:       cluster.Name,
+		RestConfig: restConfig,
+		Metadata:   metadata,
+		Options:    options,
+		Controllers: []clusters.ManagedController{
+			clusters.NewManagedController("remote-cluster", NewRemoteClusterReconciler(cluster.Name, r.GetManager(), r.GetLogger()), r.GetLogger()),
+		},
+	})
+	if err != nil {
+		failover.Stop()
+
+		return nil, errors.WrapIf(err, "could not add cluster to manager")
+	}
+
+	// the feature reconciler needs the cluster instance, so it is added after the cluster is created
+	err = remoteCluster.AddController(clusters.NewManagedController("remote-cluster-feature", NewClusterFeatureReconciler(cluster.Name, remoteCluster, r.GetLogger()), r.GetLogger()))
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not add managed controller")
+	}
+
+	return remoteCluster, nil
+}
+
+// getProbeConfig returns the probe config of the controller overridden by the probe annotations of the cluster
+// getRestConfig builds the rest config of the cluster, if the cluster has more than one API server endpoint usable
+// from the network of the controller, the returned config fails over between them
+func (r *ClusterReconciler) getRestConfig(cluster *clusterregistryv1alpha1.Cluster, k8sconfig []byte, connectionConfig clusters.ConnectionConfig) (*rest.Config, *clusters.EndpointFailover, error) {
 	clusterConfig, err := clientcmd.Load(k8sconfig)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not load kubeconfig")
+		return nil, nil, errors.WrapIf(err, "could not load kubeconfig")
 	}
 
 	kubeConfigOverrides, err := util.GetKubeconfigOverridesForClusterByNetwork(cluster, r.config.NetworkName)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, nil, errors.WithStack(err)
+	}
+
+	configs := make([]*rest.Config, 0, len(kubeConfigOverrides))
+	for _, overrides := range kubeConfigOverrides {
+		config, err := clientcmd.NewDefaultClientConfig(*clusterConfig, overrides).ClientConfig()
+		if err != nil {
+			return nil, nil, errors.WrapIf(err, "could not create k8s rest config")
+		}
+
+		config, err = connectionConfig.Apply(config)
+		if err != nil {
+			return nil, nil, WrapAsPermanentError(errors.WithStack(fmt.Errorf("%w: %s", ErrInvalidConnection, err)))
+		}
+		configs = append(configs, config)
+	}
+
+	if len(configs) == 1 {
+		return configs[0], nil, nil
 	}
-	rest, err := clientcmd.NewDefaultClientConfig(*clusterConfig, kubeConfigOverrides).ClientConfig()
+
+	failover, config, err := clusters.NewEndpointFailover(configs,
+		clusters.WithEndpointFailoverLogger(r.GetLogger().WithValues("cluster", cluster.Name)),
+		clusters.WithEndpointFailureThreshold(r.config.ClusterController.EndpointFailover.FailureThreshold),
+		clusters.WithEndpointHealthCheckInterval(r.config.ClusterController.EndpointFailover.HealthCheckInterval),
+		clusters.WithOnEndpointChangeFunc(r.onEndpointChange(cluster.Name)),
+	)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not create k8s rest config")
+		return nil, nil, WrapAsPermanentError(errors.WithStack(fmt.Errorf("%w: %s", ErrInvalidConnection, err)))
 	}
 
-	onDeadFunc := func(c *clusters.Cluster) error {
+	return config, failover, nil
+}
+
+// onEndpointChange records the endpoint change on the cluster and reconciles it to update its status
+func (r *ClusterReconciler) onEndpointChange(name string) clusters.EndpointChangeFunc {
+	return func(previous, current, reason string) {
+		cluster := &clusterregistryv1alpha1.Cluster{}
+		if err := r.GetClient().Get(context.Background(), types.NamespacedName{Name: name}, cluster); err == nil {
+			eventType := corev1.EventTypeWarning
+			if reason == "EndpointRecovered" {
+				eventType = corev1.EventTypeNormal
+			}
+			r.GetRecorder().Event(cluster, eventType, "APIEndpointChanged", fmt.Sprintf("API server endpoint changed from %s to %s (%s)", previous, current, reason))
+		}
+
 		if r.queue != nil {
 			r.queue.Add(reconcile.Request{
 				NamespacedName: types.NamespacedName{
-					Name: c.GetName(),
+					Name: name,
 				},
 			})
 		}
+	}
+}
 
-		return nil
+// getClusterMetadata returns the metadata of the remote cluster, the endpoints are recorded to detect their changes
+func (r *ClusterReconciler) getClusterMetadata(cluster *clusterregistryv1alpha1.Cluster, secretID string) (map[string]string, error) {
+	endpoints, err := json.Marshal(util.GetEndpointsForClusterByNetwork(cluster, r.config.NetworkName))
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not marshal endpoints")
+	}
+
+	return map[string]string{
+		ClusterMetadataSecretID:     secretID,
+		ClusterMetadataAPIEndpoints: string(endpoints),
+	}, nil
+}
+
+// getConnectionConfig reads the connection settings of the cluster, the CA bundle is read from its secret
+func (r *ClusterReconciler) getConnectionConfig(ctx context.Context, cluster *clusterregistryv1alpha1.Cluster) (clusters.ConnectionConfig, error) {
+	config := clusters.ConnectionConfig{}
+	if cluster.Spec.Connection == nil {
+		return config, nil
 	}
 
-	remoteCluster, err = clusters.NewCluster(ctx, cluster.Name, rest, r.GetLogger(), clusters.WithSecretID(secretID), clusters.WithCtrlOption(ctrl.Options{
-		Scheme:             r.GetManager().GetScheme(),
-		MetricsBindAddress: "0",
-		Port:               0,
-	}), clusters.WithOnDeadFunc(onDeadFunc), clusters.WithKubeconfig(k8sconfig))
+	config.ProxyURL = cluster.Spec.Connection.ProxyURL
+
+	if ref := cluster.Spec.Connection.CABundleSecretRef; ref != nil {
+		var secret corev1.Secret
+		err := r.GetClient().Get(ctx, client.ObjectKey{
+			Namespace: ref.Namespace,
+			Name:      ref.Name,
+		}, &secret)
+		if err != nil {
+			return config, errors.WithStack(fmt.Errorf("%w: could not get CA bundle secret %s/%s: %s", ErrInvalidConnection, ref.Namespace, ref.Name, err))
+		}
+
+		bundle, ok := secret.Data[ref.GetKey()]
+		if !ok || len(bundle) == 0 {
+			return config, errors.WithStack(fmt.Errorf("%w: CA bundle secret %s/%s has no %s key", ErrInvalidConnection, ref.Namespace, ref.Name, ref.GetKey()))
+		}
+		config.CABundle = bundle
+	}
+
+	return config, nil
+}
+
+// reloadRemoteClusterCredentials validates the new credentials against the remote cluster and swaps them in, the old
+// credentials are kept if the new ones are invalid
+func (r *ClusterReconciler) reloadRemoteClusterCredentials(cluster *clusterregistryv1alpha1.Cluster, clusterCallbacks clusters.ClusterProviderCallbacks, metadata map[string]string, k8sconfig []byte, connectionConfig clusters.ConnectionConfig) (*clusters.Cluster, error) {
+	secretID := metadata[ClusterMetadataSecretID]
+	config, failover, err := r.getRestConfig(cluster, k8sconfig, connectionConfig)
+	if err == nil {
+		var remoteCluster *clusters.Cluster
+		remoteCluster, err = clusterCallbacks.SetCredentials(clusters.ClusterConfig{
+			Name:       cluster.Name,
+			RestConfig: config,
+			Metadata:   metadata,
+			Options: []clusters.Option{
+				clusters.WithSecretID(secretID),
+				clusters.WithKubeconfig(k8sconfig),
+				clusters.WithEndpointFailover(failover),
+			},
+		})
+		if err == nil {
+			r.GetRecorder().Event(cluster, corev1.EventTypeNormal, "CredentialsReloaded", fmt.Sprintf("credentials are reloaded from secret %s", secretID))
+
+			return remoteCluster, nil
+		}
+		failover.Stop()
+	}
+
+	r.GetRecorder().Event(cluster, corev1.EventTypeWarning, "CredentialsValidationFailed", fmt.Sprintf("credentials from secret %s are not applied: %s", secretID, err))
+
+	if errors.Is(err, clusters.ErrInvalidCredentials) {
+		return nil, WrapAsPermanentError(err)
+	}
+
+	return nil, errors.WrapIf(err, "could not reload cluster credentials")
+}
+
+func (r *ClusterReconciler) getProbeConfig(cluster *clusterregistryv1alpha1.Cluster) (clusters.ProbeConfig, error) {
+	config := clusters.ProbeConfig{
+		Interval:         r.config.ClusterController.Probe.Interval,
+		Timeout:          r.config.ClusterController.Probe.Timeout,
+		FailureThreshold: r.config.ClusterController.Probe.FailureThreshold,
+	}
+
+	annotations := cluster.GetAnnotations()
+	for annotation, duration := range map[string]*time.Duration{
+		clusterregistryv1alpha1.ProbeIntervalAnnotation: &config.Interval,
+		clusterregistryv1alpha1.ProbeTimeoutAnnotation:  &config.Timeout,
+	} {
+		value, ok := annotations[annotation]
+		if !ok {
+			continue
+		}
+
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return clusters.ProbeConfig{}, errors.WithDetails(ErrInvalidProbeAnnotation, "annotation", annotation, "value", value)
+		}
+		*duration = d
+	}
+
+	if value, ok := annotations[clusterregistryv1alpha1.ProbeFailureThresholdAnnotation]; ok {
+		threshold, err := strconv.Atoi(value)
+		if err != nil || threshold <= 0 {
+			return clusters.ProbeConfig{}, errors.WithDetails(ErrInvalidProbeAnnotation, "annotation", clusterregistryv1alpha1.ProbeFailureThresholdAnnotation, "value", value)
+		}
+		config.FailureThreshold = threshold
+	}
+
+	return config.WithDefaults(), nil
+}
+
+// onClusterProbe triggers the reconcile of the cluster to update its ClusterReachable condition
+// when the probe result changes or the condition is not refreshed for a while
+func (r *ClusterReconciler) getClientConfig(cluster *clusterregistryv1alpha1.Cluster) (clusters.ClientConfig, error) {
+	config := clusters.ClientConfig{
+		QPS:       r.config.ClusterController.Client.QPS,
+		Burst:     r.config.ClusterController.Client.Burst,
+		Timeout:   r.config.ClusterController.Client.Timeout,
+		ForceJSON: r.config.ClusterController.Client.ForceJSON,
+	}
+
+	annotations := cluster.GetAnnotations()
+	if value, ok := annotations[clusterregistryv1alpha1.ClientQPSAnnotation]; ok {
+		qps, err := strconv.ParseFloat(value, 32)
+		if err != nil || qps <= 0 {
+			return clusters.ClientConfig{}, errors.WithDetails(ErrInvalidClientAnnotation, "annotation", clusterregistryv1alpha1.ClientQPSAnnotation, "value", value)
+		}
+		config.QPS = float32(qps)
+	}
+
+	if value, ok := annotations[clusterregistryv1alpha1.ClientBurstAnnotation]; ok {
+		burst, err := strconv.Atoi(value)
+		if err != nil || burst <= 0 {
+			return clusters.ClientConfig{}, errors.WithDetails(ErrInvalidClientAnnotation, "annotation", clusterregistryv1alpha1.ClientBurstAnnotation, "value", value)
+		}
+		config.Burst = burst
+	}
+
+	if value, ok := annotations[clusterregistryv1alpha1.ClientTimeoutAnnotation]; ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return clusters.ClientConfig{}, errors.WithDetails(ErrInvalidClientAnnotation, "annotation", clusterregistryv1alpha1.ClientTimeoutAnnotation, "value", value)
+		}
+		config.Timeout = timeout
+	}
+
+	if value, ok := annotations[clusterregistryv1alpha1.ClientForceJSONAnnotation]; ok {
+		forceJSON, err := strconv.ParseBool(value)
+		if err != nil {
+			return clusters.ClientConfig{}, errors.WithDetails(ErrInvalidClientAnnotation, "annotation", clusterregistryv1alpha1.ClientForceJSONAnnotation, "value", value)
+		}
+		config.ForceJSON = forceJSON
+	}
+
+	return config.WithDefaults(), nil
+}
+
+func (r *ClusterReconciler) onClusterProbe(c *clusters.Cluster, previous clusters.ProbeStatus, current clusters.ProbeStatus) {
+	r.probeReportsMu.Lock()
+	lastReport := r.probeReports[c.GetName()]
+	report := current.Changed(previous) || current.LastProbeTime.Sub(lastReport) >= clusterReachableHeartbeatInterval
+	if report {
+		r.probeReports[c.GetName()] = current.LastProbeTime
+	}
+	r.probeReportsMu.Unlock()
+
+	if report && r.queue != nil {
+		r.queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: c.GetName(),
+			},
+		})
+	}
+}
+
+// onClusterWatchStatus reconciles the cluster when the watch of a resource becomes degraded or recovers
+func (r *ClusterReconciler) onClusterWatchStatus(c *clusters.Cluster, _ clusters.WatchStatus) {
+	if r.queue != nil {
+		r.queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: c.GetName(),
+			},
+		})
+	}
+}
+
+// setClusterWatchDegradedCondition sets the WatchDegraded condition from the watch statuses of the remote cluster,
+// it returns whether the condition needs to be written
+func (r *ClusterReconciler) setClusterWatchDegradedCondition(cluster *clusterregistryv1alpha1.Cluster, currentConditions ClusterConditionsMap) bool {
+	clusterCallbacks, err := r.getClusterProviderCallbacks()
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not create new cluster")
+		return false
 	}
 
-	err = remoteCluster.AddController(clusters.NewManagedController("remote-cluster", NewRemoteClusterReconciler(cluster.Name, r.GetManager(), r.GetLogger()), r.GetLogger()))
+	remoteCluster, err := clusterCallbacks.Get(cluster.Name)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not add managed controller")
+		return false
 	}
 
-	err = remoteCluster.AddController(clusters.NewManagedController("remote-cluster-feature", NewClusterFeatureReconciler(cluster.Name, remoteCluster, r.GetLogger()), r.GetLogger()))
+	stored := GetCurrentCondition(cluster, clusterregistryv1alpha1.ClusterConditionTypeWatchDegraded)
+	SetCondition(cluster, currentConditions, ClusterWatchDegradedCondition(remoteCluster.GetDegradedWatches()), r.GetRecorder())
+	condition := currentConditions[clusterregistryv1alpha1.ClusterConditionTypeWatchDegraded]
+
+	return stored.Status != condition.Status || stored.Message != condition.Message
+}
+
+func (r *ClusterReconciler) onClusterCircuitBreaker(c *clusters.Cluster, _ clusters.CircuitBreakerStatus) {
+	if r.queue != nil {
+		r.queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: c.GetName(),
+			},
+		})
+	}
+}
+
+// setClusterBackoffCondition sets the ClusterBackoff condition from the circuit breaker of the remote cluster,
+// it returns whether the condition needs to be written
+func (r *ClusterReconciler) setClusterBackoffCondition(cluster *clusterregistryv1alpha1.Cluster, currentConditions ClusterConditionsMap) bool {
+	clusterCallbacks, err := r.getClusterProviderCallbacks()
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not add managed controller")
+		return false
 	}
 
-	err = remoteCluster.Start()
+	remoteCluster, err := clusterCallbacks.Get(cluster.Name)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not start cluster")
+		return false
 	}
 
-	err = r.clustersManager.Add(remoteCluster)
+	stored := GetCurrentCondition(cluster, clusterregistryv1alpha1.ClusterConditionTypeBackoff)
+
+	status, ok := remoteCluster.GetCircuitBreakerStatus()
+	if !ok {
+		// the condition of a disabled circuit breaker is removed
+		if _, exists := currentConditions[clusterregistryv1alpha1.ClusterConditionTypeBackoff]; exists {
+			delete(currentConditions, clusterregistryv1alpha1.ClusterConditionTypeBackoff)
+
+			return true
+		}
+
+		return false
+	}
+
+	SetCondition(cluster, currentConditions, ClusterBackoffCondition(status), r.GetRecorder())
+	condition := currentConditions[clusterregistryv1alpha1.ClusterConditionTypeBackoff]
+
+	return stored.Status != condition.Status || stored.Message != condition.Message
+}
+
+// setClusterReachableCondition sets the ClusterReachable condition from the probe status of the remote cluster,
+// it returns whether the condition needs to be written
+func (r *ClusterReconciler) setClusterReachableCondition(cluster *clusterregistryv1alpha1.Cluster, currentConditions ClusterConditionsMap) bool {
+	clusterCallbacks, err := r.getClusterProviderCallbacks()
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not add cluster to manager")
+		return false
+	}
+
+	remoteCluster, err := clusterCallbacks.Get(cluster.Name)
+	if err != nil {
+		return false
+	}
+
+	status := remoteCluster.GetProbeStatus()
+	stored := GetCurrentCondition(cluster, clusterregistryv1alpha1.ClusterConditionTypeReachable)
+
+	SetCondition(cluster, currentConditions, ClusterReachableCondition(status), r.GetRecorder())
+
+	// the heartbeat shows the time of the last probe instead of the time of the reconcile
+	condition := currentConditions[clusterregistryv1alpha1.ClusterConditionTypeReachable]
+	if !status.LastProbeTime.IsZero() {
+		condition.LastHeartbeatTime = metav1.NewTime(status.LastProbeTime)
 	}
+	currentConditions[clusterregistryv1alpha1.ClusterConditionTypeReachable] = condition
 
-	return remoteCluster, err
+	return stored.Status != condition.Status ||
+		stored.Message != condition.Message ||
+		condition.LastHeartbeatTime.Sub(stored.LastHeartbeatTime.Time) >= clusterReachableHeartbeatInterval
+}
+
+func (r *ClusterReconciler) getExistingRemoteCluster(name string) (*clusters.Cluster, error) {
+	clusterCallbacks, err := r.getClusterProviderCallbacks()
+	if err != nil {
+		return nil, err
+	}
+
+	return clusterCallbacks.Get(name)
 }
 
 func (r *ClusterReconciler) removeRemoteCluster(name string) error {
-	cluster, err := r.clustersManager.Get(name)
-	if err == nil {
-		err = r.clustersManager.Remove(cluster)
-		if err != nil {
-			return errors.WrapIf(err, "could not remove cluster from manager")
-		}
+	clusterCallbacks, err := r.getClusterProviderCallbacks()
+	if err != nil {
+		return err
 	}
 
-	return err
+	r.probeReportsMu.Lock()
+	delete(r.probeReports, name)
+	r.probeReportsMu.Unlock()
+
+	if err := clusterCallbacks.Remove(name); err != nil {
+		return errors.WrapIf(err, "could not remove cluster from manager")
+	}
+
+	return nil
 }
 
 func (r *ClusterReconciler) reconcileRemoteCluster(ctx context.Context, cluster *clusterregistryv1alpha1.Cluster, currentConditions ClusterConditionsMap) error {
@@ -297,7 +785,21 @@ func (r *ClusterReconciler) reconcileRemoteCluster(ctx context.Context, cluster
 		return nil
 	}
 
-	_, err := r.getRemoteCluster(ctx, cluster)
+	remoteCluster, err := r.getRemoteCluster(ctx, cluster)
+	if remoteCluster == nil {
+		// the endpoint of a cluster which is not alive is still shown
+		remoteCluster, _ = r.getExistingRemoteCluster(cluster.Name)
+	}
+	if remoteCluster != nil {
+		cluster.Status.APIEndpoint = remoteCluster.GetAPIEndpoint()
+	}
+
+	switch {
+	case err == nil:
+		SetCondition(cluster, currentConditions, ClusterConnectionCondition(nil), r.GetRecorder())
+	case errors.Is(err, ErrInvalidConnection):
+		SetCondition(cluster, currentConditions, ClusterConnectionCondition(err), r.GetRecorder())
+	}
 	if err != nil {
 		err = errors.WithStackIf(err)
 		SetCondition(cluster, currentConditions, ClustersSyncedCondition(err), r.GetRecorder())
@@ -418,16 +920,22 @@ func (r *ClusterReconciler) triggerLocalClusterReconciles(ctx context.Context) e
 	return nil
 }
 
-func (r *ClusterReconciler) setClusterID(ctx context.Context) error {
-	if r.clusterID == "" {
-		clusterID, err := GetClusterID(ctx, r.GetClient())
-		if err != nil {
-			return errors.WrapIf(err, "could not get cluster id")
-		}
-		r.clusterID = clusterID
+func (r *ClusterReconciler) setClusterProviderCallbacks(callbacks clusters.ClusterProviderCallbacks) {
+	r.clustersMu.Lock()
+	defer r.clustersMu.Unlock()
+
+	r.clusters = callbacks
+}
+
+func (r *ClusterReconciler) getClusterProviderCallbacks() (clusters.ClusterProviderCallbacks, error) {
+	r.clustersMu.RLock()
+	defer r.clustersMu.RUnlock()
+
+	if r.clusters == nil {
+		return nil, errors.WithStack(clusters.ErrClusterProviderNotReady)
 	}
 
-	return nil
+	return r.clusters, nil
 }
 
 func (r *ClusterReconciler) setQueue(q workqueue.RateLimitingInterface) {
@@ -496,12 +1004,18 @@ func (r *ClusterReconciler) SetupWithController(ctx context.Context, ctrl contro
 		}
 	}
 
-	err = ctrl.Watch(&InMemorySource{
-		reconciler: r,
-	}, handler.Funcs{})
+	r.triggers = NewInMemorySource(r, DefaultInMemorySourceBufferSize)
+	err = ctrl.Watch(r.triggers, handler.Funcs{})
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	// the deletions scheduled before a restart are only applied if the removed clusters are reconciled
+	r.triggers.TriggerAll(true)
+
+	// the clusters newly assigned to the replica are added by their reconciles
+	r.clustersManager.AddOnShardChangeFunc(func() {
+		r.enqueueAllObjects(ctx, true)
+	}, "trigger-cluster-reconcile")
 
 	return nil
 }
@@ -530,6 +1044,9 @@ func (r *ClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manag
 			if !reflect.DeepEqual(e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()) {
 				return true
 			}
+			if e.ObjectOld.GetDeletionTimestamp().IsZero() && !e.ObjectNew.GetDeletionTimestamp().IsZero() {
+				return true
+			}
 
 			return false
 		},
@@ -537,7 +1054,7 @@ func (r *ClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manag
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: r.config.ClusterController.WorkerCount,
 		}).
-		Build(r)
+		Build(clusters.NewGatedReconciler(r, r.clustersManager.GetReconcileGate()))
 	if err != nil {
 		return err
 	}
@@ -549,5 +1066,5 @@ func (r *ClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manag
 
 	r.SetClient(mgr.GetClient())
 
-	return nil
+	return errors.WrapIf(r.clustersManager.AddProvider(&clusterProvider{reconciler: r}), "could not add cluster provider")
 }
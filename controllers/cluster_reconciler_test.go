@@ -20,9 +20,14 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"github.com/banzaicloud/operator-tools/pkg/resources"
 	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
 )
 
@@ -62,4 +67,114 @@ var _ = Describe("Cluster controller", func() {
 		}, timeout, interval).Should(BeTrue())
 		Expect(createdCluster.Spec.ClusterID).Should(Equal(cluster.Spec.ClusterID))
 	})
+
+	It("applies the source removed policy of the rules when a cluster is removed", func() {
+		ctx := context.Background()
+
+		deleteRule := &clusterregistryv1alpha1.ResourceSyncRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "source-removed-delete",
+			},
+			Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				GVK: resources.GroupVersionKind{
+					Version: "v1",
+					Kind:    "ConfigMap",
+				},
+				SourceRemovedPolicy: &clusterregistryv1alpha1.SourceRemovedPolicy{
+					Type: clusterregistryv1alpha1.SourceRemovedPolicyDelete,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, deleteRule)).Should(Succeed())
+
+		deleteAfterRule := deleteRule.DeepCopy()
+		deleteAfterRule.SetName("source-removed-delete-after")
+		deleteAfterRule.Spec.SourceRemovedPolicy = &clusterregistryv1alpha1.SourceRemovedPolicy{
+			Type:  clusterregistryv1alpha1.SourceRemovedPolicyDeleteAfter,
+			After: &metav1.Duration{Duration: time.Hour},
+		}
+		Expect(k8sClient.Create(ctx, deleteAfterRule)).Should(Succeed())
+
+		cluster := &clusterregistryv1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "source-removed",
+			},
+			Spec: clusterregistryv1alpha1.ClusterSpec{
+				ClusterID: "source-removed-id",
+			},
+		}
+		Expect(k8sClient.Create(ctx, cluster)).Should(Succeed())
+
+		By("holding the deletion of the cluster with a finalizer")
+		Eventually(func() bool {
+			current := &clusterregistryv1alpha1.Cluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), current); err != nil {
+				return false
+			}
+
+			return controllerutil.ContainsFinalizer(current, clusterregistryv1alpha1.SourceRemovedFinalizer)
+		}, timeout, interval).Should(BeTrue())
+
+		syncedObject := func(name string, rule string, protected bool) *corev1.ConfigMap {
+			obj := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+					Labels: map[string]string{
+						clusterregistryv1alpha1.OwnershipAnnotation: string(cluster.Spec.ClusterID),
+					},
+					Annotations: map[string]string{
+						clusterregistryv1alpha1.OwnershipAnnotation: string(cluster.Spec.ClusterID),
+						clusterregistryv1alpha1.OwnerRuleAnnotation: rule,
+					},
+				},
+			}
+			if protected {
+				obj.Annotations[clusterregistryv1alpha1.DeleteProtectedAnnotation] = "true"
+			}
+			Expect(k8sClient.Create(ctx, obj)).Should(Succeed())
+
+			return obj
+		}
+		deleted := syncedObject("source-removed-deleted", deleteRule.Name, false)
+		protected := syncedObject("source-removed-protected", deleteRule.Name, true)
+		scheduled := syncedObject("source-removed-scheduled", deleteAfterRule.Name, false)
+
+		By("deleting the cluster")
+		Expect(k8sClient.Delete(ctx, cluster)).Should(Succeed())
+		Eventually(func() bool {
+			return apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), &clusterregistryv1alpha1.Cluster{}))
+		}, timeout, interval).Should(BeTrue())
+
+		By("deleting the objects of the Delete policy, except the protected ones")
+		Expect(apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(deleted), &corev1.ConfigMap{}))).Should(BeTrue())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(protected), &corev1.ConfigMap{})).Should(Succeed())
+
+		By("scheduling the deletion of the objects of the DeleteAfter policy")
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(scheduled), &corev1.ConfigMap{})).Should(Succeed())
+		current := &clusterregistryv1alpha1.ResourceSyncRule{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(deleteAfterRule), current)).Should(Succeed())
+		Expect(current.Status.Clusters).Should(HaveLen(1))
+		Expect(current.Status.Clusters[0].SourceRemoval).ShouldNot(BeNil())
+		Expect(current.Status.Clusters[0].SourceRemoval.ClusterID).Should(Equal(string(cluster.Spec.ClusterID)))
+
+		By("canceling the scheduled deletion once the cluster is registered again")
+		cluster = &clusterregistryv1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "source-removed-again",
+			},
+			Spec: clusterregistryv1alpha1.ClusterSpec{
+				ClusterID: cluster.Spec.ClusterID,
+			},
+		}
+		Expect(k8sClient.Create(ctx, cluster)).Should(Succeed())
+		Eventually(func() bool {
+			current := &clusterregistryv1alpha1.ResourceSyncRule{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(deleteAfterRule), current); err != nil {
+				return false
+			}
+
+			return current.Status.Clusters[0].SourceRemoval == nil
+		}, timeout, interval).Should(BeTrue())
+	})
 })
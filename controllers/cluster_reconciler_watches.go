@@ -80,9 +80,6 @@ func (r *ClusterReconciler) watchClusterRegistrySecrets(ctx context.Context, b *
 		handler.EnqueueRequestsFromMapFunc(func(object client.Object) []ctrl.Request {
 			reqs := make([]reconcile.Request, 0)
 			if secret, ok := object.(*corev1.Secret); ok {
-				if secret.Type != clusterregistryv1alpha1.SecretTypeClusterRegistry {
-					return nil
-				}
 				clusters, err := GetClusters(ctx, r.GetClient())
 				if err != nil {
 					r.GetLogger().Error(err, "")
@@ -95,7 +92,9 @@ func (r *ClusterReconciler) watchClusterRegistrySecrets(ctx context.Context, b *
 						Name:      c.Name,
 						Namespace: c.Namespace,
 					}
-					if c.Spec.AuthInfo.SecretRef.Name == secret.Name && c.Spec.AuthInfo.SecretRef.Namespace == secret.Namespace {
+					isAuthInfo := secret.Type == clusterregistryv1alpha1.SecretTypeClusterRegistry &&
+						c.Spec.AuthInfo.SecretRef.Name == secret.Name && c.Spec.AuthInfo.SecretRef.Namespace == secret.Namespace
+					if isAuthInfo || isCABundleSecret(c, secret) {
 						reqs = append(reqs, ctrl.Request{
 							NamespacedName: nsn,
 						})
@@ -109,3 +108,13 @@ func (r *ClusterReconciler) watchClusterRegistrySecrets(ctx context.Context, b *
 		}),
 	)
 }
+
+func isCABundleSecret(cluster clusterregistryv1alpha1.Cluster, secret *corev1.Secret) bool {
+	if cluster.Spec.Connection == nil || cluster.Spec.Connection.CABundleSecretRef == nil {
+		return false
+	}
+
+	ref := cluster.Spec.Connection.CABundleSecretRef
+
+	return ref.Name == secret.Name && ref.Namespace == secret.Namespace
+}
@@ -0,0 +1,544 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+	"github.com/cisco-open/cluster-registry-controller/pkg/events"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// sourceRemovalRule is a rule or a namespaced rule the source removed policy is applied by to the objects synced from
+// a removed cluster
+type sourceRemovalRule struct {
+	// name is the name the objects are synced by, see NamespacedRuleName
+	name   string
+	object client.Object
+	spec   clusterregistryv1alpha1.ResourceSyncRuleSpec
+	// namespace is the namespace of a namespaced rule, its objects are only deleted from it
+	namespace string
+	status    clusterregistryv1alpha1.ResourceSyncRuleStatus
+}
+
+// getClusterStatus returns the status of the rule for the cluster, nil if there is none
+func (r sourceRemovalRule) getClusterStatus(clusterName string) *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus {
+	for i := range r.status.Clusters {
+		if r.status.Clusters[i].Name == clusterName {
+			return &r.status.Clusters[i]
+		}
+	}
+
+	return nil
+}
+
+// isDeleteProtected returns whether the synced object is protected from deletion by the rule
+func (r sourceRemovalRule) isDeleteProtected(obj client.Object) bool {
+	return !r.spec.AllowProtectedDeletion && obj.GetAnnotations()[clusterregistryv1alpha1.DeleteProtectedAnnotation] == "true"
+}
+
+// getDeleteOptions returns the options of the deletes of the synced objects
+func (r sourceRemovalRule) getDeleteOptions() []client.DeleteOption {
+	var opts []client.DeleteOption
+	if policy := r.spec.DeletionPropagation; policy != nil {
+		opts = append(opts, client.PropagationPolicy(*policy))
+	}
+
+	return opts
+}
+
+// listSourceRemovalRules lists the rules, and the namespaced rules if their controller is enabled
+func (r *ClusterReconciler) listSourceRemovalRules(ctx context.Context) ([]sourceRemovalRule, error) {
+	list := &clusterregistryv1alpha1.ResourceSyncRuleList{}
+	if err := r.GetClient().List(ctx, list); err != nil {
+		return nil, errors.WrapIf(err, "could not list resource sync rules")
+	}
+
+	rules := make([]sourceRemovalRule, 0, len(list.Items))
+	for i := range list.Items {
+		rule := &list.Items[i]
+		rules = append(rules, sourceRemovalRule{
+			name:   rule.GetName(),
+			object: rule,
+			spec:   rule.Spec,
+			status: rule.Status,
+		})
+	}
+
+	if !r.config.NamespacedResourceSyncRules.Enabled {
+		return rules, nil
+	}
+
+	namespacedList := &clusterregistryv1alpha1.NamespacedResourceSyncRuleList{}
+	if err := r.GetClient().List(ctx, namespacedList); err != nil {
+		return nil, errors.WrapIf(err, "could not list namespaced resource sync rules")
+	}
+
+	for i := range namespacedList.Items {
+		rule := &namespacedList.Items[i]
+		rules = append(rules, sourceRemovalRule{
+			name:      NamespacedRuleName(client.ObjectKeyFromObject(rule)),
+			object:    rule,
+			spec:      rule.Spec,
+			namespace: rule.GetNamespace(),
+			status:    rule.Status,
+		})
+	}
+
+	return rules, nil
+}
+
+// getSourceRemovedPolicyOverride returns the source removed policy set on the cluster for every rule, an invalid
+// policy is reported and the policies of the rules are used
+func (r *ClusterReconciler) getSourceRemovedPolicyOverride(cluster *clusterregistryv1alpha1.Cluster, log logr.Logger) *clusterregistryv1alpha1.SourceRemovedPolicy {
+	value, ok := cluster.GetAnnotations()[clusterregistryv1alpha1.SourceRemovedPolicyAnnotation]
+	if !ok {
+		return nil
+	}
+
+	policy, err := clusterregistryv1alpha1.ParseSourceRemovedPolicy(value)
+	if err != nil {
+		msg := fmt.Sprintf("invalid %s annotation, the policies of the rules are applied: %s", clusterregistryv1alpha1.SourceRemovedPolicyAnnotation, err)
+		r.GetRecorder().Event(cluster, corev1.EventTypeWarning, "InvalidSourceRemovedPolicy", msg)
+		log.Info(msg)
+
+		return nil
+	}
+
+	return policy
+}
+
+// ensureSourceRemovedFinalizer adds the finalizer holding the deletion of the cluster until the source removed
+// policies of the rules are applied
+func (r *ClusterReconciler) ensureSourceRemovedFinalizer(ctx context.Context, cluster *clusterregistryv1alpha1.Cluster) error {
+	if controllerutil.ContainsFinalizer(cluster, clusterregistryv1alpha1.SourceRemovedFinalizer) {
+		return nil
+	}
+
+	patch := client.MergeFromWithOptions(cluster.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	controllerutil.AddFinalizer(cluster, clusterregistryv1alpha1.SourceRemovedFinalizer)
+
+	return errors.WrapIf(r.GetClient().Patch(ctx, cluster, patch), "could not add finalizer")
+}
+
+// removeSourceRemovedFinalizer lets the deletion of the cluster proceed
+func (r *ClusterReconciler) removeSourceRemovedFinalizer(ctx context.Context, name string) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cluster := &clusterregistryv1alpha1.Cluster{}
+		if err := r.GetClient().Get(ctx, types.NamespacedName{Name: name}, cluster); err != nil {
+			return err
+		}
+
+		if !controllerutil.ContainsFinalizer(cluster, clusterregistryv1alpha1.SourceRemovedFinalizer) {
+			return nil
+		}
+
+		patch := client.MergeFromWithOptions(cluster.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		controllerutil.RemoveFinalizer(cluster, clusterregistryv1alpha1.SourceRemovedFinalizer)
+
+		return r.GetClient().Patch(ctx, cluster, patch)
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	return errors.WrapIfWithDetails(err, "could not remove finalizer", "cluster", name)
+}
+
+// reconcileClusterDeletion stops syncing from the deleted cluster and applies the source removed policies of the
+// rules to the objects synced from it, the finalizer of the cluster is kept until they are applied
+func (r *ClusterReconciler) reconcileClusterDeletion(ctx context.Context, cluster *clusterregistryv1alpha1.Cluster, isClusterLocal bool, log logr.Logger) (ctrl.Result, error) {
+	removeErr := r.removeRemoteCluster(cluster.Name)
+	if removeErr != nil && !errors.Is(errors.Cause(removeErr), clusters.ErrClusterNotFound) {
+		return ctrl.Result{}, errors.WithStackIf(removeErr)
+	}
+
+	if !controllerutil.ContainsFinalizer(cluster, clusterregistryv1alpha1.SourceRemovedFinalizer) {
+		log.Info("cluster is being deleted")
+
+		return ctrl.Result{}, nil
+	}
+
+	// nothing is synced from the local cluster
+	if !isClusterLocal {
+		if err := r.applySourceRemovedPolicies(ctx, cluster, log); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.removeSourceRemovedFinalizer(ctx, cluster.Name); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("source removed policies applied")
+
+	return ctrl.Result{}, nil
+}
+
+// applySourceRemovedPolicies deletes the objects synced from the removed cluster by the rules with the Delete policy,
+// and schedules their deletion for the rules with the DeleteAfter policy. The objects are kept while another cluster
+// is registered with the same ID, since they are synced from it as well.
+func (r *ClusterReconciler) applySourceRemovedPolicies(ctx context.Context, cluster *clusterregistryv1alpha1.Cluster, log logr.Logger) error {
+	clusterID := string(cluster.Spec.ClusterID)
+	if clusterID == "" {
+		return nil
+	}
+
+	registered, err := r.isClusterIDRegistered(ctx, clusterID, cluster.Name)
+	if err != nil {
+		return err
+	}
+	if registered {
+		log.Info("another cluster is registered with the same cluster id, synced objects are kept", "clusterID", clusterID)
+
+		return nil
+	}
+
+	rules, err := r.listSourceRemovalRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	override := r.getSourceRemovedPolicyOverride(cluster, log)
+	now := time.Now()
+
+	var errs error
+	for _, rule := range rules {
+		policy := rule.spec.SourceRemovedPolicy
+		if override != nil {
+			policy = override
+		}
+
+		switch policy.GetType() {
+		case clusterregistryv1alpha1.SourceRemovedPolicyDelete:
+			errs = errors.Append(errs, r.deleteSourceRemovedObjects(ctx, rule, cluster.Name, clusterID, log))
+		case clusterregistryv1alpha1.SourceRemovedPolicyDeleteAfter:
+			errs = errors.Append(errs, r.scheduleSourceRemoval(ctx, rule, cluster.Name, clusterID, now, policy.GetGracePeriod(), log))
+		case clusterregistryv1alpha1.SourceRemovedPolicyOrphan:
+		}
+	}
+
+	return errs
+}
+
+// isClusterIDRegistered returns whether a cluster other than the named one is registered with the cluster ID
+func (r *ClusterReconciler) isClusterIDRegistered(ctx context.Context, clusterID string, except string) (bool, error) {
+	list := &clusterregistryv1alpha1.ClusterList{}
+	if err := r.GetClient().List(ctx, list); err != nil {
+		return false, errors.WrapIf(err, "could not list clusters")
+	}
+
+	for _, c := range list.Items {
+		if c.Name != except && string(c.Spec.ClusterID) == clusterID && c.GetDeletionTimestamp().IsZero() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// scheduleSourceRemoval records the deletion of the objects synced from the removed cluster in the status of the
+// rule, a deletion already scheduled is kept
+func (r *ClusterReconciler) scheduleSourceRemoval(ctx context.Context, rule sourceRemovalRule, clusterName string, clusterID string, now time.Time, gracePeriod time.Duration, log logr.Logger) error {
+	// the API server stores the time with second precision
+	removal := &clusterregistryv1alpha1.SourceRemoval{
+		ClusterID: clusterID,
+		RemovedAt: metav1.NewTime(now.Truncate(time.Second)),
+		DeleteAt:  metav1.NewTime(now.Add(gracePeriod).Truncate(time.Second)),
+	}
+
+	scheduled := false
+	err := SetResourceSyncRuleClusterStatus(ctx, r.GetClient(), rule.name, clusterName, func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus) {
+		if status.SourceRemoval != nil && status.SourceRemoval.ClusterID == clusterID {
+			return
+		}
+		status.SourceRemoval = removal
+		scheduled = true
+	})
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not schedule deletion of synced objects", "rule", rule.name)
+	}
+
+	if scheduled {
+		msg := fmt.Sprintf("objects synced from removed cluster %s are deleted at %s, unless the cluster is registered again", clusterName, removal.DeleteAt.Format(time.RFC3339))
+		r.recordRuleEvent(rule, corev1.EventTypeNormal, "SourceRemovedDeletionScheduled", msg)
+		log.Info(msg, "rule", rule.name)
+	}
+
+	return nil
+}
+
+// reconcileSourceRemovals applies the deletions scheduled for the objects synced from the removed cluster once they
+// are due, and cancels them if a cluster with the same ID is registered again. It returns the result requeueing the
+// cluster until the next deletion is due.
+func (r *ClusterReconciler) reconcileSourceRemovals(ctx context.Context, clusterName string, log logr.Logger) (ctrl.Result, error) {
+	rules, err := r.listSourceRemovalRules(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var requeueAfter time.Duration
+	var errs error
+	for _, rule := range rules {
+		status := rule.getClusterStatus(clusterName)
+		if status == nil || status.SourceRemoval == nil {
+			continue
+		}
+		removal := status.SourceRemoval
+
+		registered, err := r.isClusterIDRegistered(ctx, removal.ClusterID, clusterName)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if registered {
+			errs = errors.Append(errs, r.cancelSourceRemoval(ctx, rule, clusterName, log))
+
+			continue
+		}
+
+		if remaining := time.Until(removal.DeleteAt.Time); remaining > 0 {
+			if requeueAfter == 0 || remaining < requeueAfter {
+				requeueAfter = remaining
+			}
+
+			continue
+		}
+
+		if err := r.deleteSourceRemovedObjects(ctx, rule, clusterName, removal.ClusterID, log); err != nil {
+			errs = errors.Append(errs, err)
+
+			continue
+		}
+
+		errs = errors.Append(errs, errors.WrapIfWithDetails(SetResourceSyncRuleClusterStatus(ctx, r.GetClient(), rule.name, clusterName, func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus) {
+			status.SourceRemoval = nil
+		}), "could not clear scheduled deletion of synced objects", "rule", rule.name))
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, errs
+}
+
+// cancelSourceRemovals cancels the deletions scheduled for the objects synced from the cluster ID of the cluster,
+// since it is registered again
+func (r *ClusterReconciler) cancelSourceRemovals(ctx context.Context, cluster *clusterregistryv1alpha1.Cluster, log logr.Logger) error {
+	if cluster.Spec.ClusterID == "" {
+		return nil
+	}
+
+	rules, err := r.listSourceRemovalRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for _, rule := range rules {
+		for _, status := range rule.status.Clusters {
+			if status.SourceRemoval != nil && status.SourceRemoval.ClusterID == string(cluster.Spec.ClusterID) {
+				errs = errors.Append(errs, r.cancelSourceRemoval(ctx, rule, status.Name, log))
+			}
+		}
+	}
+
+	return errs
+}
+
+// cancelSourceRemoval clears the deletion scheduled for the objects synced from the removed cluster by the rule
+func (r *ClusterReconciler) cancelSourceRemoval(ctx context.Context, rule sourceRemovalRule, clusterName string, log logr.Logger) error {
+	err := SetResourceSyncRuleClusterStatus(ctx, r.GetClient(), rule.name, clusterName, func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus) {
+		status.SourceRemoval = nil
+	})
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not cancel scheduled deletion of synced objects", "rule", rule.name)
+	}
+
+	msg := fmt.Sprintf("deletion of the objects synced from cluster %s is canceled, the cluster is registered again", clusterName)
+	r.recordRuleEvent(rule, corev1.EventTypeNormal, "SourceRemovedDeletionCanceled", msg)
+	log.Info(msg, "rule", rule.name)
+
+	return nil
+}
+
+// getSourceRemovalGVK returns the local kind the rule synced the objects of the cluster to, and false if the kind is
+// not served by the local cluster, so there is nothing to delete
+func (r *ClusterReconciler) getSourceRemovalGVK(rule sourceRemovalRule, clusterName string) (schema.GroupVersionKind, bool, error) {
+	gvk := schema.GroupVersionKind(rule.spec.GVK)
+	if status := rule.getClusterStatus(clusterName); gvk.Version == clusterregistryv1alpha1.AnyVersion && status != nil && status.ResolvedVersion != "" {
+		gvk.Version = status.ResolvedVersion
+	}
+	gvk = getLocalKind(rule.spec, gvk)
+
+	gvk, err := util.ResolveSourceGVK(r.GetManager().GetRESTMapper(), gvk, nil)
+	if errors.Is(err, util.ErrNoServedVersion) {
+		return gvk, false, nil
+	}
+	if err != nil {
+		return gvk, false, err
+	}
+
+	return gvk, true, nil
+}
+
+// deleteSourceRemovedObjects deletes the objects synced from the removed cluster by the rule, as the identity of the
+// rule. The protected objects are kept, unless the rule allows deleting them.
+func (r *ClusterReconciler) deleteSourceRemovedObjects(ctx context.Context, rule sourceRemovalRule, clusterName string, clusterID string, log logr.Logger) error {
+	log = log.WithValues("rule", rule.name)
+
+	gvk, served, err := r.getSourceRemovalGVK(rule, clusterName)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not resolve local kind", "rule", rule.name)
+	}
+	if !served {
+		log.V(1).Info("local kind is not served, there is nothing to delete", "gvk", gvk.String())
+
+		return nil
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	opts := []client.ListOption{
+		client.MatchingLabels(map[string]string{
+			clusterregistryv1alpha1.OwnershipAnnotation: clusterID,
+		}),
+	}
+	if rule.namespace != "" {
+		opts = append(opts, client.InNamespace(rule.namespace))
+	}
+	// the synced kinds are not cached by the manager
+	err = r.GetManager().GetAPIReader().List(ctx, list, opts...)
+	if meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not list synced objects", "rule", rule.name, "gvk", gvk.String())
+	}
+
+	var writeUser string
+	if rule.spec.ServiceAccountName != "" {
+		writeUser = util.ServiceAccountUsername(r.config.Namespace, rule.spec.ServiceAccountName)
+	}
+	writeClient, err := client.New(util.RuleRESTConfig(r.GetManager().GetConfig(), rule.name, writeUser), client.Options{
+		Scheme: r.GetManager().GetScheme(),
+		Mapper: r.GetManager().GetRESTMapper(),
+	})
+	if err != nil {
+		return errors.WrapIf(err, "could not create local client")
+	}
+
+	var deleted int
+	var errs error
+	for i := range list.Items {
+		obj := &list.Items[i]
+		if !util.IsOwnedByRule(obj, clusterID, rule.name) || !obj.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+
+		if rule.isDeleteProtected(obj) {
+			msg := fmt.Sprintf("object is protected by the %s annotation, it is not deleted (resource: %s)", clusterregistryv1alpha1.DeleteProtectedAnnotation, client.ObjectKeyFromObject(obj))
+			r.recordRuleEvent(rule, corev1.EventTypeWarning, "ObjectDeletionBlocked", msg)
+			log.Info(msg)
+
+			continue
+		}
+
+		err := writeClient.Delete(ctx, obj, rule.getDeleteOptions()...)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			errs = errors.Append(errs, errors.WrapIfWithDetails(err, "could not delete synced object", "rule", rule.name, "resource", client.ObjectKeyFromObject(obj)))
+
+			continue
+		}
+		deleted++
+		log.V(1).Info("synced object deleted", "resource", client.ObjectKeyFromObject(obj))
+	}
+
+	if deleted > 0 {
+		msg := fmt.Sprintf("%d objects synced from removed cluster %s are deleted", deleted, clusterName)
+		r.recordRuleEvent(rule, corev1.EventTypeNormal, "SourceRemovedObjectsDeleted", msg)
+		log.Info(msg)
+	}
+
+	return errs
+}
+
+// recordRuleEvent records the event on the rule
+func (r *ClusterReconciler) recordRuleEvent(rule sourceRemovalRule, eventtype, reason, message string) {
+	events.NewSafeRecorder(r.GetManager().GetEventRecorderFor(ruleEventsComponent), r.GetManager().GetScheme(), r.GetManager().GetRESTMapper(), r.GetLogger()).Event(rule.object, eventtype, reason, message)
+}
+
+// enqueueAllObjects reconciles every cluster again, and the removed clusters the objects synced from are scheduled
+// to be deleted, so the scheduled deletions are applied after a restart as well
+func (r *ClusterReconciler) enqueueAllObjects(ctx context.Context, listFromCache bool) {
+	if r.queue == nil {
+		return
+	}
+
+	var reader client.Reader = r.GetClient()
+	if !listFromCache {
+		reader = r.GetManager().GetAPIReader()
+	}
+
+	list := &clusterregistryv1alpha1.ClusterList{}
+	if err := reader.List(ctx, list); err != nil {
+		r.GetLogger().Error(err, "could not list clusters")
+
+		return
+	}
+
+	names := make(map[string]struct{}, len(list.Items))
+	for _, c := range list.Items {
+		names[c.GetName()] = struct{}{}
+	}
+
+	rules, err := r.listSourceRemovalRules(ctx)
+	if err != nil {
+		r.GetLogger().Error(err, "could not list rules")
+	}
+	for _, rule := range rules {
+		for _, status := range rule.status.Clusters {
+			if status.SourceRemoval != nil {
+				names[status.Name] = struct{}{}
+			}
+		}
+	}
+
+	for name := range names {
+		r.queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: name,
+			},
+		})
+	}
+}
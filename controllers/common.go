@@ -16,39 +16,205 @@ package controllers
 
 import (
 	"context"
+	"strings"
+	"sync"
 
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/internal/config"
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
 )
 
 type QueueAwareReconciler interface {
 	setQueue(q workqueue.RateLimitingInterface)
 }
 
+// FullResyncReconciler is implemented by the reconcilers which enqueue every object they reconcile when a full resync
+// is triggered through their in-memory source
+type FullResyncReconciler interface {
+	enqueueAllObjects(ctx context.Context, listFromCache bool)
+}
+
+// DefaultInMemorySourceBufferSize is the number of triggers buffered by an in-memory source until its consumers
+// catch up, the triggers beyond it are degraded to a full resync
+const DefaultInMemorySourceBufferSize = 1024
+
+// InMemorySource lets the components of the controller inject work into the queues of the controllers watching it.
+// Every controller started with the source consumes its triggers, the objects are enqueued by the source itself,
+// the event handler of the watch is not used. Triggering never blocks, the triggers beyond the buffer of the source
+// are degraded to a full resync of its reconciler.
 type InMemorySource struct {
 	reconciler QueueAwareReconciler
+	buffer     *util.TriggerBuffer
+
+	consumers map[*inMemoryConsumer]struct{}
+	// stop stops consuming the triggers, it is set while any controller consumes them
+	stop context.CancelFunc
+	mu   sync.Mutex
+}
+
+type inMemoryConsumer struct {
+	ctx        context.Context
+	queue      workqueue.RateLimitingInterface
+	predicates []predicate.Predicate
+}
+
+func NewInMemorySource(reconciler QueueAwareReconciler, bufferSize int) *InMemorySource {
+	return &InMemorySource{
+		reconciler: reconciler,
+		buffer:     util.NewTriggerBuffer(bufferSize),
+		consumers:  make(map[*inMemoryConsumer]struct{}),
+	}
 }
 
 func (s *InMemorySource) String() string {
 	return "in-memory"
 }
 
+// Start attaches the queue of a controller to the source until the context is done, the triggers are consumed while
+// any queue is attached and buffered otherwise
 func (s *InMemorySource) Start(ctx context.Context, h handler.EventHandler, q workqueue.RateLimitingInterface, p ...predicate.Predicate) error {
-	s.reconciler.setQueue(q)
+	if s.reconciler != nil {
+		s.reconciler.setQueue(q)
+	}
+
+	consumer := &inMemoryConsumer{
+		ctx:        ctx,
+		queue:      q,
+		predicates: p,
+	}
+
+	s.mu.Lock()
+	s.consumers[consumer] = struct{}{}
+	if s.stop == nil {
+		runCtx, cancel := context.WithCancel(context.Background())
+		s.stop = cancel
+		go s.run(runCtx)
+	}
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.consumers, consumer)
+		if len(s.consumers) == 0 && s.stop != nil {
+			s.stop()
+			s.stop = nil
+		}
+	}()
 
 	return nil
 }
 
+// TriggerObject enqueues the object of the kind in the queues of the controllers watching the source, whose
+// predicates accept it
+func (s *InMemorySource) TriggerObject(gvk schema.GroupVersionKind, key types.NamespacedName) {
+	s.buffer.Add(util.Trigger{
+		GVK: gvk,
+		Key: key,
+	})
+}
+
+// TriggerAll makes the reconciler of the source enqueue every object it reconciles, listing them from the informer
+// caches or the API servers. The full resyncs triggered while one is pending are coalesced into it.
+func (s *InMemorySource) TriggerAll(listFromCache bool) {
+	s.buffer.Add(util.Trigger{
+		All:           true,
+		ListFromCache: listFromCache,
+	})
+}
+
+func (s *InMemorySource) run(ctx context.Context) {
+	for {
+		trigger, ok := s.buffer.Next(ctx)
+		if !ok {
+			return
+		}
+
+		if !s.dispatch(trigger) {
+			// the last controller stopped meanwhile, the trigger is kept for the next one
+			s.buffer.Add(trigger)
+
+			return
+		}
+	}
+}
+
+// dispatch hands the trigger to the consumers of the source, it returns false if there is none
+func (s *InMemorySource) dispatch(trigger util.Trigger) bool {
+	s.mu.Lock()
+	consumers := make([]*inMemoryConsumer, 0, len(s.consumers))
+	for consumer := range s.consumers {
+		if consumer.ctx.Err() == nil {
+			consumers = append(consumers, consumer)
+		}
+	}
+	if len(consumers) == 0 {
+		// the next controller started consumes the triggers again
+		if s.stop != nil {
+			s.stop()
+			s.stop = nil
+		}
+		s.mu.Unlock()
+
+		return false
+	}
+	s.mu.Unlock()
+
+	if trigger.All {
+		if r, ok := s.reconciler.(FullResyncReconciler); ok {
+			r.enqueueAllObjects(consumers[0].ctx, trigger.ListFromCache)
+		}
+
+		return true
+	}
+
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(trigger.GVK)
+	obj.SetName(trigger.Key.Name)
+	obj.SetNamespace(trigger.Key.Namespace)
+	e := event.GenericEvent{Object: obj}
+
+	for _, consumer := range consumers {
+		if isGenericEventAccepted(e, consumer.predicates) {
+			consumer.queue.Add(reconcile.Request{NamespacedName: trigger.Key})
+		}
+	}
+
+	return true
+}
+
+func isGenericEventAccepted(e event.GenericEvent, predicates []predicate.Predicate) bool {
+	for _, p := range predicates {
+		if !p.Generic(e) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func GetClusters(ctx context.Context, c client.Client) (map[types.UID]clusterregistryv1alpha1.Cluster, error) {
 	clusterList := &clusterregistryv1alpha1.ClusterList{}
 	err := c.List(ctx, clusterList)
@@ -129,6 +295,8 @@ func UpdateClusterStatus(ctx context.Context, c client.Client, cluster *clusterr
 			return errors.WrapIf(err, "could not get cluster")
 		}
 		desired.SetResourceVersion(current.GetResourceVersion())
+		// the sync state is written by the sync controllers in the meantime
+		desired.Status.SyncState = current.Status.SyncState
 		err = c.Status().Update(ctx, desired)
 		if err != nil {
 			return errors.WrapIf(err, "could not update cluster status")
@@ -140,3 +308,166 @@ func UpdateClusterStatus(ctx context.Context, c client.Client, cluster *clusterr
 
 	return nil
 }
+
+// SetClusterSyncState patches the sync state of the cluster, leaving the rest of its status to the cluster controller
+func SetClusterSyncState(ctx context.Context, c client.Client, clusterName string, state clusterregistryv1alpha1.ClusterSyncState) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cluster := &clusterregistryv1alpha1.Cluster{}
+		err := c.Get(ctx, client.ObjectKey{
+			Name: clusterName,
+		}, cluster)
+		if err != nil {
+			return err
+		}
+
+		if equality.Semantic.DeepEqual(cluster.Status.SyncState, &state) {
+			return nil
+		}
+
+		patch := client.MergeFromWithOptions(cluster.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		cluster.Status.SyncState = &state
+
+		return c.Status().Patch(ctx, cluster, patch)
+	})
+	// the state of a deleted cluster is not written anymore
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+
+	return errors.WrapIfWithDetails(err, "could not update cluster sync state", "cluster", clusterName)
+}
+
+// NamespacedRuleName returns the name the namespaced rule is synced by, the names of the cluster scoped rules can not
+// contain a slash, so the rules of the two kinds never share a name
+func NamespacedRuleName(key types.NamespacedName) string {
+	return key.String()
+}
+
+// getRuleObject returns the empty rule the name refers to with its key, and the status of the rule which is filled
+// once it is read. The name refers to a namespaced rule if it contains its namespace, see NamespacedRuleName.
+func getRuleObject(ruleName string) (client.Object, client.ObjectKey, *clusterregistryv1alpha1.ResourceSyncRuleStatus) {
+	if namespace, name, ok := strings.Cut(ruleName, "/"); ok {
+		rule := &clusterregistryv1alpha1.NamespacedResourceSyncRule{}
+
+		return rule, client.ObjectKey{Namespace: namespace, Name: name}, &rule.Status
+	}
+
+	rule := &clusterregistryv1alpha1.ResourceSyncRule{}
+
+	return rule, client.ObjectKey{Name: ruleName}, &rule.Status
+}
+
+// updateRuleStatus updates the status of the rule, the status is only written if the update reports a change
+func updateRuleStatus(ctx context.Context, c client.Client, ruleName string, update func(status *clusterregistryv1alpha1.ResourceSyncRuleStatus) bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		rule, key, status := getRuleObject(ruleName)
+		err := c.Get(ctx, key, rule)
+		if err != nil {
+			return err
+		}
+
+		if !update(status) {
+			return nil
+		}
+
+		return c.Status().Update(ctx, rule)
+	})
+}
+
+// SetResourceSyncRuleClusterStatus updates the status of the rule for a single cluster, keeping the status of other clusters
+func SetResourceSyncRuleClusterStatus(ctx context.Context, c client.Client, ruleName string, clusterName string, update func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus)) error {
+	return updateRuleStatus(ctx, c, ruleName, func(status *clusterregistryv1alpha1.ResourceSyncRuleStatus) bool {
+		index := -1
+		for i, s := range status.Clusters {
+			if s.Name == clusterName {
+				index = i
+
+				break
+			}
+		}
+		if index < 0 {
+			status.Clusters = append(status.Clusters, clusterregistryv1alpha1.ResourceSyncRuleClusterStatus{
+				Name: clusterName,
+			})
+			index = len(status.Clusters) - 1
+		}
+
+		current := status.Clusters[index].DeepCopy()
+		update(&status.Clusters[index])
+
+		return !equality.Semantic.DeepEqual(current, &status.Clusters[index])
+	})
+}
+
+// SetResourceSyncRuleClusterCondition sets a condition of the rule for a single cluster
+func SetResourceSyncRuleClusterCondition(ctx context.Context, c client.Client, ruleName string, clusterName string, condition metav1.Condition) error {
+	return SetResourceSyncRuleClusterStatus(ctx, c, ruleName, clusterName, func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus) {
+		meta.SetStatusCondition(&status.Conditions, condition)
+	})
+}
+
+// SetResourceSyncRuleCondition sets a condition of the rule
+func SetResourceSyncRuleCondition(ctx context.Context, c client.Client, ruleName string, condition metav1.Condition) error {
+	return updateRuleStatus(ctx, c, ruleName, func(status *clusterregistryv1alpha1.ResourceSyncRuleStatus) bool {
+		current := status.DeepCopy()
+		meta.SetStatusCondition(&status.Conditions, condition)
+
+		return !equality.Semantic.DeepEqual(current, status)
+	})
+}
+
+// SetResourceSyncRuleObservedGeneration records the generation of the rule the objects of every cluster are synced by
+func SetResourceSyncRuleObservedGeneration(ctx context.Context, c client.Client, ruleName string, generation int64) error {
+	return updateRuleStatus(ctx, c, ruleName, func(status *clusterregistryv1alpha1.ResourceSyncRuleStatus) bool {
+		if status.ObservedGeneration == generation {
+			return false
+		}
+		status.ObservedGeneration = generation
+
+		return true
+	})
+}
+
+// warmRESTMapper makes sure that the kinds are known by the mapper if it is the cached mapper of a cluster,
+// other mappers look up the kinds on their own
+func warmRESTMapper(mapper meta.RESTMapper, gvks ...schema.GroupVersionKind) error {
+	if m, ok := mapper.(*clusters.RESTMapper); ok {
+		return m.Warm(gvks...)
+	}
+
+	return nil
+}
+
+// NewCacheFunc returns the function creating the informer caches of the local and remote objects, which strips the
+// managed fields of the cached objects unless the cache transform is disabled, and lists the objects in pages unless
+// the page size is 0
+func NewCacheFunc(configuration config.Configuration) cache.NewCacheFunc {
+	return newCacheFunc(configuration, newListPager(configuration))
+}
+
+// newListPager returns the pager of the lists of the informer caches, or nil if the objects are not listed in pages
+func newListPager(configuration config.Configuration) *clusters.ListPager {
+	if configuration.CacheListPageSize <= 0 {
+		return nil
+	}
+
+	return clusters.NewListPager(configuration.CacheListPageSize)
+}
+
+// newCacheFunc returns the function creating the informer caches whose lists are paged by the pager, if it is set
+func newCacheFunc(configuration config.Configuration, pager *clusters.ListPager) cache.NewCacheFunc {
+	newCache := cache.New
+	if !configuration.CacheTransform.Disabled {
+		opts := make([]clusters.CacheTransformOption, 0)
+		if configuration.CacheTransform.StripLastAppliedConfiguration {
+			opts = append(opts, clusters.WithLastAppliedConfigurationStripping())
+		}
+		newCache = clusters.NewCacheTransform(opts...).NewCache
+	}
+
+	if pager != nil {
+		newCache = pager.NewCacheFunc(newCache)
+	}
+
+	return newCache
+}
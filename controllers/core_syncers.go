@@ -15,6 +15,8 @@
 package controllers
 
 import (
+	"strconv"
+
 	"emperror.dev/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,6 +32,11 @@ import (
 const (
 	CoreResourcesSourceFeatureName = "cluster-registry-core-resources-source"
 	CoreResourceLabelName          = "cluster-registry-controller.k8s.cisco.com/core-sync-resource"
+
+	// RegistryReplicationSourceFeatureName is the feature of the registries whose Cluster resources are replicated
+	RegistryReplicationSourceFeatureName = "cluster-registry-registry-replication-source"
+	// registryReplicationPriority makes the registry replication rules win over the core resource rules
+	registryReplicationPriority = 100
 )
 
 func (r *ClusterReconciler) reconcileCoreSyncers(cluster *clusterregistryv1alpha1.Cluster) error {
@@ -47,11 +54,20 @@ func (r *ClusterReconciler) reconcileCoreSyncers(cluster *clusterregistryv1alpha
 		clusterFeatureDesiredState = reconciler.StatePresent
 	}
 
+	registryReplicationDesiredState := reconciler.StateAbsent
+	if r.config.RegistryReplicationEnabled {
+		registryReplicationDesiredState = reconciler.StatePresent
+	}
+
 	for o, ds := range map[client.Object]reconciler.DesiredState{
 		r.coreSyncersClusterFeature(): clusterFeatureDesiredState,
 		r.clustersSyncRule():          reconciler.StatePresent,
 		r.clusterSecretsSyncRule():    reconciler.StatePresent,
 		r.resourceSyncRuleSync():      reconciler.StatePresent,
+
+		r.registryReplicationClusterFeature():   registryReplicationDesiredState,
+		r.registryReplicationClustersSyncRule(): registryReplicationDesiredState,
+		r.registryReplicationSecretsSyncRule():  registryReplicationDesiredState,
 	} {
 		// set resource owner
 		if err := controllerutil.SetControllerReference(cluster, o, r.GetManager().GetScheme()); err != nil {
@@ -171,3 +187,109 @@ func (r *ClusterReconciler) resourceSyncRuleSync() *clusterregistryv1alpha1.Reso
 		},
 	}
 }
+
+func (r *ClusterReconciler) registryReplicationClusterFeature() *clusterregistryv1alpha1.ClusterFeature {
+	return &clusterregistryv1alpha1.ClusterFeature{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cluster-registry-registry-replication",
+			Labels: map[string]string{
+				CoreResourceLabelName: "true",
+			},
+		},
+		Spec: clusterregistryv1alpha1.ClusterFeatureSpec{
+			FeatureName: RegistryReplicationSourceFeatureName,
+		},
+	}
+}
+
+// registryReplicationClustersSyncRule replicates the Cluster resources of the other registries, their credential
+// Secret references are rewritten to the namespace of the local controller, where the Secrets are replicated to. The
+// Cluster resource of the local cluster is never overwritten, see syncReconciler.isSelfClusterObject.
+func (r *ClusterReconciler) registryReplicationClustersSyncRule() *clusterregistryv1alpha1.ResourceSyncRule {
+	namespace := strconv.Quote(r.config.Namespace)
+
+	return &clusterregistryv1alpha1.ResourceSyncRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cluster-registry-registry-replication-clusters-sink",
+			Labels: map[string]string{
+				CoreResourceLabelName: "true",
+			},
+			Annotations: map[string]string{
+				// the namespace of the controller differs between the registries
+				clusterregistryv1alpha1.SyncDisabledAnnotation: "true",
+			},
+		},
+		Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+			ClusterFeatureMatches: []clusterregistryv1alpha1.ClusterFeatureMatch{
+				{
+					FeatureName: RegistryReplicationSourceFeatureName,
+				},
+			},
+			GVK:      resources.GroupVersionKind(clusterregistryv1alpha1.SchemeBuilder.GroupVersion.WithKind("Cluster")),
+			Priority: registryReplicationPriority,
+			Rules: []clusterregistryv1alpha1.SyncRule{
+				{
+					Mutations: clusterregistryv1alpha1.Mutations{
+						JSONPatches: []clusterregistryv1alpha1.JSONPatchOperation{
+							{
+								Op:       clusterregistryv1alpha1.JSONPatchOperationTypeReplace,
+								Path:     "/spec/authInfo/secretRef/namespace",
+								Value:    &namespace,
+								Optional: true,
+							},
+						},
+						SyncStatus: false,
+					},
+				},
+			},
+		},
+	}
+}
+
+// registryReplicationSecretsSyncRule replicates the credential Secrets of the clusters from any namespace of the other
+// registries into the namespace of the local controller
+func (r *ClusterReconciler) registryReplicationSecretsSyncRule() *clusterregistryv1alpha1.ResourceSyncRule {
+	return &clusterregistryv1alpha1.ResourceSyncRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cluster-registry-registry-replication-cluster-secrets-sink",
+			Labels: map[string]string{
+				CoreResourceLabelName: "true",
+			},
+			Annotations: map[string]string{
+				clusterregistryv1alpha1.SyncDisabledAnnotation: "true",
+			},
+		},
+		Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+			ClusterFeatureMatches: []clusterregistryv1alpha1.ClusterFeatureMatch{
+				{
+					FeatureName: RegistryReplicationSourceFeatureName,
+				},
+			},
+			GVK:      resources.GroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret")),
+			Priority: registryReplicationPriority,
+			Rules: []clusterregistryv1alpha1.SyncRule{
+				{
+					Matches: []clusterregistryv1alpha1.SyncRuleMatch{
+						{
+							Content: []clusterregistryv1alpha1.ContentSelector{
+								{
+									Key:   "type",
+									Value: intstr.FromString(string(clusterregistryv1alpha1.SecretTypeClusterRegistry)),
+								},
+							},
+						},
+					},
+					Mutations: clusterregistryv1alpha1.Mutations{
+						// the Secrets carry no routing label, every one of them is synced into the default namespace
+						NamespaceRouting: &clusterregistryv1alpha1.NamespaceRouting{
+							FromLabel:        CoreResourceLabelName,
+							DefaultNamespace: r.config.Namespace,
+							UnmappedPolicy:   clusterregistryv1alpha1.UnmappedNamespacePolicyDefault,
+						},
+						SyncStatus: false,
+					},
+				},
+			},
+		},
+	}
+}
@@ -25,6 +25,17 @@ var (
 	ErrInvalidSecretContent = errors.New("could not found k8s config in secret")
 	ErrInvalidSecret        = errors.New("invalid secret type")
 	ErrLocalClusterConflict = errors.New("multiple local clusters are defined")
+	// ErrInvalidProbeAnnotation is returned for probe annotations which are not positive durations or numbers
+	ErrInvalidProbeAnnotation = errors.New("invalid probe annotation")
+	// ErrInvalidClientAnnotation is returned for client annotations which are not positive numbers or durations
+	ErrInvalidClientAnnotation = errors.New("invalid client annotation")
+	// ErrReconcileTimeout is returned if a reconcile of a synced object did not finish in time
+	ErrReconcileTimeout = errors.New("reconcile timed out")
+	// ErrInvalidConnection is returned if the connection to a cluster could not be built from its connection settings
+	ErrInvalidConnection = errors.New("invalid cluster connection")
+	// ErrDeletionPendingFinalizers is returned while a deleted synced object is kept by the finalizers preserved by its
+	// rule, the deletion is retried until they are removed locally
+	ErrDeletionPendingFinalizers = errors.New("deletion is pending on preserved finalizers")
 )
 
 func WrapAsPermanentError(err error) error {
@@ -0,0 +1,109 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/cisco-open/cluster-registry-controller/controllers"
+)
+
+var _ = Describe("In-memory source", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	configMapGVK := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	secretGVK := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+	key := types.NamespacedName{Namespace: "default", Name: "triggered"}
+
+	newQueue := func() workqueue.RateLimitingInterface {
+		return workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	}
+
+	It("enqueues the triggered objects in the queue of every attached controller accepting them", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		src := controllers.NewInMemorySource(nil, 10)
+
+		configMaps := newQueue()
+		defer configMaps.ShutDown()
+		Expect(src.Start(ctx, handler.Funcs{}, configMaps, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetObjectKind().GroupVersionKind() == configMapGVK
+		}))).Should(Succeed())
+
+		all := newQueue()
+		defer all.ShutDown()
+		Expect(src.Start(ctx, handler.Funcs{}, all)).Should(Succeed())
+
+		src.TriggerObject(configMapGVK, key)
+		src.TriggerObject(secretGVK, key)
+
+		Eventually(all.Len, timeout, interval).Should(Equal(1))
+		Eventually(configMaps.Len, timeout, interval).Should(Equal(1))
+
+		item, _ := configMaps.Get()
+		Expect(item).Should(Equal(reconcile.Request{NamespacedName: key}))
+		configMaps.Done(item)
+		Consistently(configMaps.Len, time.Second, interval).Should(Equal(0))
+	})
+
+	It("keeps the triggers until a controller is attached again", func() {
+		src := controllers.NewInMemorySource(nil, 10)
+
+		stoppedCtx, stop := context.WithCancel(context.Background())
+		stopped := newQueue()
+		defer stopped.ShutDown()
+		Expect(src.Start(stoppedCtx, handler.Funcs{}, stopped)).Should(Succeed())
+		stop()
+
+		// the triggers are buffered while no controller is attached
+		src.TriggerObject(configMapGVK, key)
+		Consistently(stopped.Len, time.Second, interval).Should(Equal(0))
+
+		// triggering never blocks, the triggers beyond the buffer are degraded to a full resync
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				src.TriggerObject(configMapGVK, types.NamespacedName{Namespace: "default", Name: "overflow"})
+			}
+		}()
+		Eventually(done, timeout, interval).Should(BeClosed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		restarted := newQueue()
+		defer restarted.ShutDown()
+		Expect(src.Start(ctx, handler.Funcs{}, restarted)).Should(Succeed())
+
+		Eventually(restarted.Len, timeout, interval).Should(Equal(2))
+		Expect(stopped.Len()).Should(Equal(0))
+	})
+})
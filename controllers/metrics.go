@@ -0,0 +1,225 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var sourceMappingViolationsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_source_mapping_violations_total",
+		Help: "Number of synced objects which did not map back to their source object",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncQueueDepth = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_queue_depth",
+		Help: "Number of objects waiting in the work queue of the sync controller of a rule",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncSharedWatchRules = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_shared_watch_rules",
+		Help: "Number of rules attached to the shared watch of a source kind on a cluster",
+	},
+	[]string{"cluster", "gvk"},
+)
+
+var syncQueueAddsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_queue_adds_total",
+		Help: "Number of objects added to the work queue of the sync controller of a rule",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncQueueRetriesTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_queue_retries_total",
+		Help: "Number of objects added back to the work queue of the sync controller of a rule, because they failed or were requeued",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncQueueOldestItemAge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_queue_oldest_item_age_seconds",
+		Help: "Time the oldest object in the work queue of the sync controller of a rule has been waiting for a worker",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncActiveWorkers = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_active_workers",
+		Help: "Number of workers of the sync controller of a rule currently reconciling an object",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncRateLimiterKeys = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_rate_limiter_keys",
+		Help: "Number of objects tracked by the rate limiter of the sync controller of a rule",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncVerifiedObjectsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_verified_objects_total",
+		Help: "Number of synced objects compared with the desired state rendered from their source objects",
+	},
+	[]string{"rule", "cluster", "result"},
+)
+
+var syncVerificationDriftedObjects = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_verification_drifted_objects",
+		Help: "Number of synced objects found to differ from the desired state rendered from their source objects",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncFailedObjects = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_failed_objects",
+		Help: "Number of objects not retried by the sync controller of a rule, because they failed to sync too many times in a row",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncForbiddenObjects = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_forbidden_objects",
+		Help: "Number of objects parked by the sync controller of a rule, because their writes are forbidden",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncForbiddenErrorsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_forbidden_errors_total",
+		Help: "Number of reconciles of the sync controller of a rule failing, because their requests are forbidden",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncBlockedDeletions = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_blocked_deletions",
+		Help: "Number of synced objects not deleted by the sync controller of a rule, because they are protected by the sync-delete-protected annotation",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncOversizedObjects = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_oversized_objects",
+		Help: "Number of source objects not synced by the sync controller of a rule, because they are larger than the maximum object size",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncUncachedReadsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_uncached_reads_total",
+		Help: "Number of objects read by the sync controller of a rule directly from the API server of the source or the local cluster, bypassing the cache",
+	},
+	[]string{"rule", "cluster", "target"},
+)
+
+var syncReconcileTimeoutsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_reconcile_timeouts_total",
+		Help: "Number of reconciles of synced objects which did not finish within the reconcile timeout",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncLoopsDetectedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_loops_detected_total",
+		Help: "Number of source objects not synced, because they originate from the local cluster or were synced through too many clusters",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncContentHashChecksTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_content_hash_checks_total",
+		Help: "Number of objects checked for an unchanged desired state by the sync controller of a rule, the ones with hit result were not reconciled again",
+	},
+	[]string{"rule", "cluster", "result"},
+)
+
+var syncCoalescedWritesTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_coalesced_writes_total",
+		Help: "Number of local writes saved by the write coalescing of a rule, because the desired state of a held object changed again before its window closed",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncHeldWrites = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_held_writes",
+		Help: "Number of objects whose local write is held by the write coalescing of a rule until their window closes",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncDriftDetectedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_drift_detected_total",
+		Help: "Number of local changes of synced objects made by others than the sync controller of a rule",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncQuotaRefusedObjects = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "cluster_registry_sync_quota_refused_objects",
+		Help: "Number of source objects whose local objects are not created, because the rule syncs as many objects as its maxObjects quota",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncSuppressedLogLinesTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_sync_suppressed_log_lines_total",
+		Help: "Number of high volume log lines of a rule, e.g. \"reconciling\", not logged because more lines of the same message were logged for the rule in the last second than the log sample rate",
+	},
+	[]string{"rule", "cluster"},
+)
+
+var syncLag = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "cluster_registry_sync_lag_seconds",
+		Help:    "Time from the modification of a source object to the completion of the local write of the object synced from it, measured from the time the change was observed without a modification time set by the push side",
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+	},
+	[]string{"rule", "cluster"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(sourceMappingViolationsTotal, syncQueueDepth, syncQueueAddsTotal, syncQueueRetriesTotal, syncQueueOldestItemAge, syncActiveWorkers, syncRateLimiterKeys, syncVerifiedObjectsTotal, syncVerificationDriftedObjects, syncFailedObjects, syncForbiddenObjects, syncForbiddenErrorsTotal, syncBlockedDeletions, syncOversizedObjects, syncUncachedReadsTotal, syncReconcileTimeoutsTotal, syncLoopsDetectedTotal, syncContentHashChecksTotal, syncCoalescedWritesTotal, syncHeldWrites, syncDriftDetectedTotal, syncQuotaRefusedObjects, syncSuppressedLogLinesTotal, syncLag, syncSharedWatchRules)
+}
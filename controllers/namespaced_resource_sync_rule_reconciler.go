@@ -0,0 +1,305 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/internal/config"
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+	"github.com/cisco-open/cluster-registry-controller/pkg/events"
+	"github.com/cisco-open/cluster-registry-controller/pkg/rulebuilder"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// namespacedRuleRoutingLabel is never set on the source objects, so every object routed by it is written into the
+// default namespace of the routing, which is the namespace of the namespaced rule
+const namespacedRuleRoutingLabel = "cluster-registry.k8s.cisco.com/namespaced-rule-routing"
+
+// NamespacedResourceSyncRuleReconciler starts the sync controllers of the namespaced rules. The namespaced rules are
+// converted into rules named after their namespace and name, which are synced by the same sync controllers as the
+// cluster scoped rules, confined to the namespace of the namespaced rule.
+type NamespacedResourceSyncRuleReconciler struct {
+	clusters.ManagedReconciler
+
+	clustersManager *clusters.Manager
+	config          config.Configuration
+	// rules is the reconciler of the cluster scoped rules, the sync controllers of the namespaced rules share its rule
+	// registry, limits and watches, and their status is maintained the same way
+	rules *ResourceSyncRuleReconciler
+
+	queue workqueue.RateLimitingInterface
+}
+
+func NewNamespacedResourceSyncRuleReconciler(name string, log logr.Logger, clustersManager *clusters.Manager, config config.Configuration, rules *ResourceSyncRuleReconciler) *NamespacedResourceSyncRuleReconciler {
+	return &NamespacedResourceSyncRuleReconciler{
+		ManagedReconciler: clusters.NewManagedReconciler(name, log),
+
+		clustersManager: clustersManager,
+		config:          config,
+		rules:           rules,
+	}
+}
+
+func (r *NamespacedResourceSyncRuleReconciler) setQueue(q workqueue.RateLimitingInterface) {
+	r.queue = q
+}
+
+func (r *NamespacedResourceSyncRuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.GetLogger().WithValues("rule", req.NamespacedName)
+
+	result, err := r.reconcile(ctx, req, log)
+	if err != nil {
+		//nolint:errorlint
+		if e, ok := err.(interface{ IsPermanent() bool }); ok && e.IsPermanent() {
+			log.Error(err, "", errors.GetDetails(err)...)
+			err = nil
+		}
+	}
+
+	return result, errors.WithStackIf(err)
+}
+
+func (r *NamespacedResourceSyncRuleReconciler) reconcile(ctx context.Context, req ctrl.Request, log logr.Logger) (ctrl.Result, error) {
+	log.Info("reconciling")
+
+	name := NamespacedRuleName(req.NamespacedName)
+
+	nsr := &clusterregistryv1alpha1.NamespacedResourceSyncRule{}
+	err := r.GetClient().Get(ctx, req.NamespacedName, nsr)
+	if apierrors.IsNotFound(err) {
+		for _, cluster := range r.clustersManager.GetAll() {
+			cluster.RemoveControllerByName(name)
+		}
+		r.rules.logSampler.Forget(name)
+
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// an invalid namespaced rule does not affect the readiness of the controller, since it is authored by a team
+	// owning the namespace instead of the cluster administrators
+	if err := r.validate(nsr); err != nil {
+		for _, cluster := range r.clustersManager.GetAll() {
+			cluster.RemoveControllerByName(name)
+		}
+		events.NewSafeRecorder(r.GetManager().GetEventRecorderFor(ruleEventsComponent), r.GetManager().GetScheme(), r.GetManager().GetRESTMapper(), r.GetLogger()).Event(nsr, corev1.EventTypeWarning, "InvalidRule", err.Error())
+
+		return ctrl.Result{}, WrapAsPermanentError(errors.WrapIf(err, "invalid namespaced resource sync rule"))
+	}
+
+	sr := r.toResourceSyncRule(nsr)
+
+	err = r.rules.setSuspendedCondition(ctx, sr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for _, cluster := range r.clustersManager.GetAll() {
+		log.Info("sync controller", "ctrl", name, "cluster", cluster.GetName())
+		err := r.rules.syncClusterController(ctx, cluster, sr, r.getSyncReconcilerOptions(nsr))
+		if err != nil {
+			log.Error(err, "could not sync controller", "cluster", cluster.GetName())
+		}
+	}
+
+	err = r.rules.setReadyCondition(ctx, sr, false)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if sr.Spec.GVK.Version == clusterregistryv1alpha1.AnyVersion {
+		return ctrl.Result{
+			RequeueAfter: versionResolutionInterval,
+		}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// validate returns an error if the namespaced rule could not be synced. The checks of the webhook are done again, as
+// the rule is the only thing confining the objects written on behalf of the team owning the namespace.
+func (r *NamespacedResourceSyncRuleReconciler) validate(nsr *clusterregistryv1alpha1.NamespacedResourceSyncRule) error {
+	err := rulebuilder.ValidateNamespacedSpec(nsr.Spec, rulebuilder.WithScheme(r.GetManager().GetScheme()), rulebuilder.WithRESTMapper(r.GetManager().GetRESTMapper()))
+	if err != nil {
+		return err
+	}
+
+	denyList, err := util.NewDenyList(r.config.SyncController.ProtectedNamespaces, r.config.SyncController.DeniedGVKs)
+	if err != nil {
+		return errors.WrapIf(err, "invalid deny list")
+	}
+	if denyList.IsNamespaceProtected(nsr.GetNamespace()) {
+		return errors.Errorf("namespace %s is protected by the controller, objects are never synced into it", nsr.GetNamespace())
+	}
+
+	allowList, err := util.NewNamespaceAllowList(r.config.SyncController.AllowedTargetNamespaces)
+	if err != nil {
+		return errors.WrapIf(err, "invalid allowed target namespaces")
+	}
+	if !allowList.IsNamespaceAllowed(nsr.GetNamespace()) {
+		return errors.Errorf("forbidden: namespace %s is not allowed by the controller, objects are never synced into it", nsr.GetNamespace())
+	}
+
+	return nil
+}
+
+// toResourceSyncRule converts the namespaced rule into the rule its sync controllers run with. The rule gets the UID
+// of the namespaced rule, and its name refers to the namespaced rule, so its status is written onto it.
+func (r *NamespacedResourceSyncRuleReconciler) toResourceSyncRule(nsr *clusterregistryv1alpha1.NamespacedResourceSyncRule) *clusterregistryv1alpha1.ResourceSyncRule {
+	sr := &clusterregistryv1alpha1.ResourceSyncRule{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ResourceSyncRule",
+			APIVersion: clusterregistryv1alpha1.SchemeBuilder.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        NamespacedRuleName(client.ObjectKeyFromObject(nsr)),
+			UID:         nsr.GetUID(),
+			Generation:  nsr.GetGeneration(),
+			Labels:      nsr.GetLabels(),
+			Annotations: nsr.GetAnnotations(),
+		},
+		Spec:   *nsr.Spec.DeepCopy(),
+		Status: *nsr.Status.DeepCopy(),
+	}
+
+	// the objects matched in a mapped source namespace are written into the namespace of the rule
+	if r.config.NamespacedResourceSyncRules.GetSourceNamespace(nsr.GetNamespace()) != nsr.GetNamespace() {
+		for i := range sr.Spec.Rules {
+			sr.Spec.Rules[i].Mutations.NamespaceRouting = &clusterregistryv1alpha1.NamespaceRouting{
+				FromLabel:        namespacedRuleRoutingLabel,
+				DefaultNamespace: nsr.GetNamespace(),
+				UnmappedPolicy:   clusterregistryv1alpha1.UnmappedNamespacePolicyDefault,
+			}
+		}
+	}
+
+	return sr
+}
+
+// getSyncReconcilerOptions returns the options of the sync reconcilers of the namespaced rule shared by every cluster
+func (r *NamespacedResourceSyncRuleReconciler) getSyncReconcilerOptions(nsr *clusterregistryv1alpha1.NamespacedResourceSyncRule) []SyncReconcilerOption {
+	key := client.ObjectKeyFromObject(nsr)
+
+	return append(r.rules.getSharedSyncReconcilerOptions(),
+		WithNamespacedRule(nsr.DeepCopy(), r.config.NamespacedResourceSyncRules.GetSourceNamespace(nsr.GetNamespace())),
+		// the readiness of the rule is updated once the objects of the cluster are synced
+		WithOnConvergedFunc(func() {
+			r.enqueueRule(key)
+		}),
+	)
+}
+
+// enqueueRule reconciles the namespaced rule again, e.g. to update its status once its sync controllers converged
+func (r *NamespacedResourceSyncRuleReconciler) enqueueRule(key types.NamespacedName) {
+	if r.queue == nil {
+		return
+	}
+
+	r.queue.Add(reconcile.Request{
+		NamespacedName: key,
+	})
+}
+
+// enqueueRules reconciles every namespaced rule again, e.g. to start their sync controllers on a new cluster
+func (r *NamespacedResourceSyncRuleReconciler) enqueueRules(ctx context.Context) {
+	if r.queue == nil {
+		return
+	}
+
+	rules := &clusterregistryv1alpha1.NamespacedResourceSyncRuleList{}
+	err := r.GetClient().List(ctx, rules)
+	if err != nil {
+		r.GetLogger().Error(err, "could not list namespaced resource sync rules")
+	}
+	for _, rule := range rules.Items {
+		r.enqueueRule(types.NamespacedName{
+			Namespace: rule.Namespace,
+			Name:      rule.Name,
+		})
+	}
+}
+
+func (r *NamespacedResourceSyncRuleReconciler) SetupWithController(ctx context.Context, ctrl controller.Controller) error {
+	err := r.ManagedReconciler.SetupWithController(ctx, ctrl)
+	if err != nil {
+		return err
+	}
+
+	err = ctrl.Watch(NewInMemorySource(r, DefaultInMemorySourceBufferSize), handler.Funcs{})
+	if err != nil {
+		return err
+	}
+
+	r.clustersManager.AddOnAfterAddFunc(func(c *clusters.Cluster) {
+		r.enqueueRules(ctx)
+	}, "trigger-namespaced-resource-sync-rule-reconcile")
+
+	r.clustersManager.AddOnShardChangeFunc(func() {
+		r.enqueueRules(ctx)
+	}, "trigger-namespaced-resource-sync-rule-reconcile")
+
+	return nil
+}
+
+func (r *NamespacedResourceSyncRuleReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	err := r.ManagedReconciler.SetupWithManager(ctx, mgr)
+	if err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr)
+
+	ctrl, err := b.For(&clusterregistryv1alpha1.NamespacedResourceSyncRule{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NamespacedResourceSyncRule",
+			APIVersion: clusterregistryv1alpha1.SchemeBuilder.GroupVersion.String(),
+		},
+	}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, logLevelChangedPredicate()))).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.config.SyncController.WorkerCount,
+		}).
+		Build(clusters.NewGatedReconciler(r, r.clustersManager.GetReconcileGate()))
+	if err != nil {
+		return err
+	}
+
+	err = r.SetupWithController(ctx, ctrl)
+	if err != nil {
+		return err
+	}
+
+	r.SetClient(mgr.GetClient())
+
+	return nil
+}
@@ -51,7 +51,7 @@ func NewRemoteClusterReconciler(name string, localMgr ctrl.Manager, log logr.Log
 		ManagedReconciler: clusters.NewManagedReconciler(name, log),
 
 		localMgr:      localMgr,
-		localRecorder: localMgr.GetEventRecorderFor("cluster-controller"),
+		localRecorder: localMgr.GetEventRecorderFor(ruleEventsComponent),
 	}
 }
 
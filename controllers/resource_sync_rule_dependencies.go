@@ -0,0 +1,235 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/events"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// checkDependencies sets whether the rule waits for the rules it depends on, and returns true while it does. A rule
+// which already started syncing only waits again if one of its dependencies is deleted, so it is not stopped whenever
+// a dependency is changed and syncs its objects again.
+func (r *ResourceSyncRuleReconciler) checkDependencies(ctx context.Context, sr *clusterregistryv1alpha1.ResourceSyncRule) (bool, error) {
+	previous := meta.FindStatusCondition(sr.Status.Conditions, clusterregistryv1alpha1.ResourceSyncRuleConditionTypeWaitingForDependencies)
+
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeWaitingForDependencies,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: sr.GetGeneration(),
+		Reason:             "NoDependencies",
+		Message:            "the rule does not depend on other rules",
+	}
+
+	if len(sr.Spec.DependsOn) > 0 {
+		rules := &clusterregistryv1alpha1.ResourceSyncRuleList{}
+		if err := r.GetClient().List(ctx, rules); err != nil {
+			return false, errors.WrapIf(err, "could not list resource sync rules")
+		}
+
+		started := previous != nil && previous.Status == metav1.ConditionFalse && previous.Reason == "DependenciesReady"
+		missing, notReady := getUnmetDependencies(sr, rules.Items)
+
+		condition.Reason = "DependenciesReady"
+		condition.Message = "the rules the rule depends on are ready"
+		switch cycle := util.FindDependencyCycle(util.GetRuleDependencies(rules.Items), sr.GetName()); {
+		case cycle != nil:
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "DependencyCycle"
+			condition.Message = fmt.Sprintf("the rule is part of the dependency cycle %s", strings.Join(cycle, " -> "))
+		case len(missing) > 0:
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "DependencyMissing"
+			condition.Message = fmt.Sprintf("the rules the rule depends on do not exist: %s", strings.Join(missing, ", "))
+		case len(notReady) > 0 && !started:
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = "DependencyNotReady"
+			condition.Message = fmt.Sprintf("the rules the rule depends on are not ready: %s", strings.Join(notReady, ", "))
+		}
+	}
+
+	waiting := condition.Status == metav1.ConditionTrue
+	recorder := events.NewSafeRecorder(r.GetManager().GetEventRecorderFor(ruleEventsComponent), r.GetManager().GetScheme(), r.GetManager().GetRESTMapper(), r.GetLogger())
+	switch {
+	case waiting && (previous == nil || previous.Status != metav1.ConditionTrue || previous.Reason != condition.Reason):
+		recorder.Event(sr, corev1.EventTypeWarning, "WaitingForDependencies", condition.Message)
+	case !waiting && previous != nil && previous.Status == metav1.ConditionTrue:
+		recorder.Event(sr, corev1.EventTypeNormal, "DependenciesReady", "the rules the rule depends on are ready, objects are synced")
+	}
+
+	return waiting, errors.WrapIf(SetResourceSyncRuleCondition(ctx, r.GetClient(), sr.GetName(), condition), "could not update rule status")
+}
+
+// getUnmetDependencies returns the names of the rules the rule depends on which do not exist or are not ready
+func getUnmetDependencies(sr *clusterregistryv1alpha1.ResourceSyncRule, rules []clusterregistryv1alpha1.ResourceSyncRule) ([]string, []string) {
+	existing := make(map[string]clusterregistryv1alpha1.ResourceSyncRule, len(rules))
+	for _, rule := range rules {
+		existing[rule.GetName()] = rule
+	}
+
+	missing := make([]string, 0)
+	notReady := make([]string, 0)
+	for _, name := range sr.Spec.DependsOn {
+		rule, ok := existing[name]
+		switch {
+		case !ok || !rule.GetDeletionTimestamp().IsZero():
+			missing = append(missing, name)
+		case !meta.IsStatusConditionTrue(rule.Status.Conditions, clusterregistryv1alpha1.ResourceSyncRuleConditionTypeReady):
+			notReady = append(notReady, name)
+		}
+	}
+
+	return missing, notReady
+}
+
+// setReadyCondition sets whether the objects of the rule were synced from every alive cluster at least once since
+// its sync controllers started
+func (r *ResourceSyncRuleReconciler) setReadyCondition(ctx context.Context, sr *clusterregistryv1alpha1.ResourceSyncRule, waiting bool) error {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: sr.GetGeneration(),
+		Reason:             "Synced",
+		Message:            "the objects are synced from every cluster",
+	}
+
+	syncing := r.getSyncingClusters(sr.GetName())
+	if r.clustersManager.IsSharded() {
+		var err error
+		if syncing, err = r.getShardedSyncingClusters(ctx, sr, syncing); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case sr.Spec.Suspend:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Suspended"
+		condition.Message = "syncing is suspended"
+	case waiting:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "WaitingForDependencies"
+		condition.Message = "the rules the rule depends on are not ready"
+	case len(syncing) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Syncing"
+		condition.Message = fmt.Sprintf("the objects are being synced from clusters %s", strings.Join(syncing, ", "))
+	}
+
+	err := SetResourceSyncRuleCondition(ctx, r.GetClient(), sr.GetName(), condition)
+	if err != nil || condition.Status != metav1.ConditionTrue {
+		return errors.WrapIf(err, "could not update rule status")
+	}
+
+	// the objects of every cluster are synced by the current spec of the rule
+	return errors.WrapIf(SetResourceSyncRuleObservedGeneration(ctx, r.GetClient(), sr.GetName(), sr.GetGeneration()), "could not update rule status")
+}
+
+// getSyncingClusters returns the alive clusters whose sync controllers of the rule did not reconcile the objects of the
+// cluster yet
+func (r *ResourceSyncRuleReconciler) getSyncingClusters(name string) []string {
+	syncing := make([]string, 0)
+	for _, cluster := range r.clustersManager.GetAll() {
+		if !cluster.IsAlive() {
+			continue
+		}
+
+		ctrl := cluster.GetController(name)
+		if ctrl == nil {
+			continue
+		}
+
+		if rec, ok := ctrl.GetReconciler().(SyncReconciler); ok && !rec.IsConverged() {
+			syncing = append(syncing, cluster.GetName())
+		}
+	}
+	sort.Strings(syncing)
+
+	return syncing
+}
+
+// enqueueRule reconciles the rule again, e.g. to update its status once its sync controllers converged
+func (r *ResourceSyncRuleReconciler) enqueueRule(name string) {
+	r.triggers.TriggerObject(clusterregistryv1alpha1.SchemeBuilder.GroupVersion.WithKind("ResourceSyncRule"), types.NamespacedName{
+		Name: name,
+	})
+}
+
+// getDependentRuleRequests returns the rules depending on the rule
+func (r *ResourceSyncRuleReconciler) getDependentRuleRequests(obj client.Object) []reconcile.Request {
+	rules := &clusterregistryv1alpha1.ResourceSyncRuleList{}
+	if err := r.GetClient().List(context.Background(), rules); err != nil {
+		r.GetLogger().Error(err, "could not list resource sync rules")
+
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for _, name := range util.GetDependentRules(rules.Items, obj.GetName()) {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: name,
+			},
+		})
+	}
+
+	return requests
+}
+
+// dependencyChangedPredicate passes the rules which were created, deleted, or became ready or not ready, so the rules
+// depending on them are reconciled
+func dependencyChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldRule, ok := e.ObjectOld.(*clusterregistryv1alpha1.ResourceSyncRule)
+			if !ok {
+				return false
+			}
+			newRule, ok := e.ObjectNew.(*clusterregistryv1alpha1.ResourceSyncRule)
+			if !ok {
+				return false
+			}
+
+			return meta.IsStatusConditionTrue(oldRule.Status.Conditions, clusterregistryv1alpha1.ResourceSyncRuleConditionTypeReady) !=
+				meta.IsStatusConditionTrue(newRule.Status.Conditions, clusterregistryv1alpha1.ResourceSyncRuleConditionTypeReady) ||
+				oldRule.GetDeletionTimestamp().IsZero() != newRule.GetDeletionTimestamp().IsZero()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"net/http"
+	"sort"
+
+	"emperror.dev/errors"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// ResourceSyncRuleHealthCheckPrefix prefixes the names of the readiness checks of the rules
+const ResourceSyncRuleHealthCheckPrefix = "resource-sync-rule/"
+
+// HealthChecks is a set of named health checks which can change while they are served
+type HealthChecks interface {
+	Add(name string, check healthz.Checker)
+	Remove(name string)
+}
+
+// ruleHealth contains the errors of the last reconcile of a rule
+type ruleHealth struct {
+	err      error
+	clusters map[string]error
+}
+
+// SetReadyzChecks makes the reconciler add a readiness check for every rule, which fails while the rule is invalid or
+// its sync controller could not be started on a cluster
+func (r *ResourceSyncRuleReconciler) SetReadyzChecks(checks HealthChecks) {
+	r.readyzChecks = checks
+}
+
+// setRuleHealth records the error of the rule, and adds the readiness check of the rule
+func (r *ResourceSyncRuleReconciler) setRuleHealth(name string, err error) {
+	if r.readyzChecks == nil {
+		return
+	}
+
+	r.ruleHealthMu.Lock()
+	health, ok := r.ruleHealth[name]
+	if !ok {
+		health = &ruleHealth{
+			clusters: make(map[string]error),
+		}
+		r.ruleHealth[name] = health
+	}
+	health.err = err
+	// the errors of the sync controllers are recorded again by the reconcile
+	health.clusters = make(map[string]error)
+	r.ruleHealthMu.Unlock()
+
+	if !ok {
+		r.readyzChecks.Add(ResourceSyncRuleHealthCheckPrefix+name, r.ruleReadyzCheck(name))
+	}
+}
+
+// setClusterRuleHealth records the error of syncing the controller of the rule on the cluster
+func (r *ResourceSyncRuleReconciler) setClusterRuleHealth(name string, cluster string, err error) {
+	r.ruleHealthMu.Lock()
+	defer r.ruleHealthMu.Unlock()
+
+	if health, ok := r.ruleHealth[name]; ok && err != nil {
+		health.clusters[cluster] = err
+	}
+}
+
+func (r *ResourceSyncRuleReconciler) removeRuleHealth(name string) {
+	if r.readyzChecks == nil {
+		return
+	}
+
+	r.ruleHealthMu.Lock()
+	delete(r.ruleHealth, name)
+	r.ruleHealthMu.Unlock()
+
+	r.readyzChecks.Remove(ResourceSyncRuleHealthCheckPrefix + name)
+}
+
+// ruleReadyzCheck returns the readiness check of the rule, it fails while the rule is invalid or its sync controller
+// could not be created or started on any of the clusters
+func (r *ResourceSyncRuleReconciler) ruleReadyzCheck(name string) healthz.Checker {
+	return func(_ *http.Request) error {
+		r.ruleHealthMu.Lock()
+		health, ok := r.ruleHealth[name]
+		if !ok {
+			r.ruleHealthMu.Unlock()
+
+			return nil
+		}
+		if health.err != nil {
+			r.ruleHealthMu.Unlock()
+
+			return health.err
+		}
+		errs := make(map[string]error, len(health.clusters))
+		for cluster, err := range health.clusters {
+			errs[cluster] = err
+		}
+		r.ruleHealthMu.Unlock()
+
+		for clusterName, cluster := range r.clustersManager.GetAll() {
+			if _, ok := errs[clusterName]; ok {
+				continue
+			}
+			if ctrl := cluster.GetController(name); ctrl != nil && ctrl.StartError() != nil {
+				errs[clusterName] = ctrl.StartError()
+			}
+		}
+
+		names := make([]string, 0, len(errs))
+		for cluster := range errs {
+			// the errors of the removed clusters are left behind until the next reconcile
+			if r.clustersManager.Exists(cluster) {
+				names = append(names, cluster)
+			}
+		}
+		sort.Strings(names)
+
+		combined := make([]error, 0, len(names))
+		for _, cluster := range names {
+			combined = append(combined, errors.WrapIff(errs[cluster], "sync controller is not running on cluster %s", cluster))
+		}
+
+		return errors.Combine(combined...)
+	}
+}
@@ -16,56 +16,168 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"sync"
+	"time"
 
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
 	"github.com/throttled/throttled"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
 	"github.com/cisco-open/cluster-registry-controller/internal/config"
 	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+	"github.com/cisco-open/cluster-registry-controller/pkg/events"
 	"github.com/cisco-open/cluster-registry-controller/pkg/ratelimit"
+	"github.com/cisco-open/cluster-registry-controller/pkg/syncstate"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
 )
 
 type SyncReconciler interface {
 	clusters.ManagedReconciler
 
 	GetRule() *clusterregistryv1alpha1.ResourceSyncRule
+	GetSourceGVK() schema.GroupVersionKind
+	IsSuspended() bool
+	IsConverged() bool
+	SetSuspended(ctx context.Context, suspended bool) error
+	UpdateRule(ctx context.Context, rule *clusterregistryv1alpha1.ResourceSyncRule) error
+	// SetLogLevel sets the log level of the logger of the reconciler
+	SetLogLevel(level util.RuleLogLevel)
+	Resync(ctx context.Context) error
+	// GetTriggerSource returns the source the other components of the controller enqueue the source objects through
+	GetTriggerSource() *InMemorySource
+	Teardown()
 }
 
+// versionResolutionInterval is the interval at which the versions served by the source clusters are checked
+// for rules syncing any version of a kind
+const versionResolutionInterval = time.Minute * 5
+
+// DefaultSyncReconcileTimeout is the default time a reconcile of a synced object can take
+const DefaultSyncReconcileTimeout = time.Minute * 2
+
+// DefaultFullReconcileInterval is the default longest time a synced object with an unchanged desired state is not
+// reconciled in full
+const DefaultFullReconcileInterval = time.Hour
+
+// DefaultSyncMaxObjectSize is the default largest size of the synced source objects serialized to JSON, the objects
+// stored by etcd are limited to 1.5MiB
+const DefaultSyncMaxObjectSize = 1024 * 1024
+
+// DefaultLastAppliedSizeLimit is the default size of the synced objects above which they are written without the last
+// applied annotation, which holds a compressed copy of the object
+const DefaultLastAppliedSizeLimit = 256 * 1024
+
+// the per object backoff delays and the overall limit of the default controller rate limiter
+const (
+	defaultBackoffBaseDelay = time.Millisecond * 5
+	defaultBackoffMaxDelay  = time.Second * 1000
+	defaultOverallQPS       = 10
+	defaultOverallBurst     = 100
+)
+
 type ResourceSyncRuleReconciler struct {
 	clusters.ManagedReconciler
 
 	clustersManager *clusters.Manager
 	config          config.Configuration
+	syncState       *syncstate.Aggregator
+	ruleRegistry    *util.RuleRegistry
+	// objectQuota counts the objects synced by the sync reconcilers of every rule against the quotas of the rules
+	objectQuota *util.ObjectQuota
+	// logSampler limits the high volume log lines of the sync reconcilers of every rule per rule
+	logSampler *util.LogSampler
+	// writeLimiter limits the local writes of the sync reconcilers of every rule running at the same time
+	writeLimiter *util.WriteLimiter
+	// syncHooks are passed to the sync reconcilers of every rule, see SetSyncHooks
+	syncHooks []SyncHook
+	// sharedWatches are the watches of the source kinds shared by the rules, nil if every rule watches its kind
+	sharedWatches *SharedSourceWatches
+	// localClusterID resolves the ID of the cluster the controller runs on for the sync reconcilers of every rule
+	localClusterID *LocalClusterIDResolver
+	// auditLog gets the audit records of the sync reconcilers of every rule, nil if the audit trail is disabled
+	auditLog logr.Logger
+
+	// handledResyncs contains the last resync value handled by the replica for each rule in sharded mode
+	handledResyncs   map[string]string
+	handledResyncsMu sync.Mutex
 
 	queue workqueue.RateLimitingInterface
+	// triggers enqueue the rules reconciled again by the components of the controller, e.g. the rules depending on a
+	// rule which became ready
+	triggers *InMemorySource
+
+	// readyzChecks get a readiness check for every rule, the checks report the errors recorded in ruleHealth
+	readyzChecks HealthChecks
+	ruleHealth   map[string]*ruleHealth
+	ruleHealthMu sync.Mutex
 }
 
 func NewResourceSyncRuleReconciler(name string, log logr.Logger, clustersManager *clusters.Manager, config config.Configuration) *ResourceSyncRuleReconciler {
-	return &ResourceSyncRuleReconciler{
+	var sharedWatches *SharedSourceWatches
+	if config.SyncController.SharedSourceWatches {
+		sharedWatches = NewSharedSourceWatches(config, log.WithName("shared-watches"))
+	}
+
+	r := &ResourceSyncRuleReconciler{
 		ManagedReconciler: clusters.NewManagedReconciler(name, log),
 
 		clustersManager: clustersManager,
 		config:          config,
+		ruleRegistry:    util.NewRuleRegistry(),
+		objectQuota:     util.NewObjectQuota(),
+		logSampler:      util.NewLogSampler(config.SyncController.LogSampleRate),
+		writeLimiter:    util.NewWriteLimiter(config.SyncController.MaxConcurrentWrites),
+		sharedWatches:   sharedWatches,
+		localClusterID:  NewLocalClusterIDResolver(config.LocalClusterID, log.WithName("local-cluster-id")),
+		handledResyncs:  make(map[string]string),
+		ruleHealth:      make(map[string]*ruleHealth),
 	}
+	r.triggers = NewInMemorySource(r, DefaultInMemorySourceBufferSize)
+
+	return r
 }
 
 func (r *ResourceSyncRuleReconciler) setQueue(q workqueue.RateLimitingInterface) {
 	r.queue = q
 }
 
+// SetLocalClusterIDResolver sets the resolver of the ID of the local cluster, so it can be shared with the cluster
+// controller, it must be called before the reconciler is started
+func (r *ResourceSyncRuleReconciler) SetLocalClusterIDResolver(resolver *LocalClusterIDResolver) {
+	r.localClusterID = resolver
+}
+
+// SetAuditLogger enables the audit trail of the sync reconcilers of every rule, the records of the synced objects are
+// written to the logger, it must be called before the reconciler is started
+func (r *ResourceSyncRuleReconciler) SetAuditLogger(log logr.Logger) {
+	r.auditLog = log
+}
+
+// SetSyncHooks sets the hooks of the sync reconcilers of every rule, it must be called before the reconciler is started
+func (r *ResourceSyncRuleReconciler) SetSyncHooks(hooks ...SyncHook) {
+	r.syncHooks = hooks
+}
+
 func (r *ResourceSyncRuleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.GetLogger().WithValues("rule", req.NamespacedName)
 
@@ -90,30 +202,88 @@ func (r *ResourceSyncRuleReconciler) reconcile(ctx context.Context, req ctrl.Req
 		for _, cluster := range r.clustersManager.GetAll() {
 			cluster.RemoveControllerByName(req.NamespacedName.Name)
 		}
+		r.removeRuleHealth(req.NamespacedName.Name)
+		r.logSampler.Forget(req.NamespacedName.Name)
 
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, DeleteSyncAnchors(ctx, r.GetManager().GetAPIReader(), r.GetClient(), req.NamespacedName.Name)
 	}
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	if err := sr.Spec.Validate(); err != nil {
+		for _, cluster := range r.clustersManager.GetAll() {
+			cluster.RemoveControllerByName(sr.Name)
+		}
+		r.setRuleHealth(sr.Name, errors.WrapIf(err, "invalid resource sync rule"))
+		events.NewSafeRecorder(r.GetManager().GetEventRecorderFor(ruleEventsComponent), r.GetManager().GetScheme(), r.GetManager().GetRESTMapper(), r.GetLogger()).Event(sr, corev1.EventTypeWarning, "InvalidRule", err.Error())
+
+		return ctrl.Result{}, WrapAsPermanentError(errors.WrapIf(err, "invalid resource sync rule"))
+	}
+
+	r.setRuleHealth(sr.Name, nil)
+
+	if !sr.Spec.AnchorOwnership {
+		err = DeleteSyncAnchors(ctx, r.GetManager().GetAPIReader(), r.GetClient(), sr.Name)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	err = r.setSuspendedCondition(ctx, sr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// the sync controllers are not started while the rule waits for its dependencies
+	waiting, err := r.checkDependencies(ctx, sr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if waiting {
+		for _, cluster := range r.clustersManager.GetAll() {
+			cluster.RemoveControllerByName(sr.Name)
+		}
+
+		return ctrl.Result{}, r.setReadyCondition(ctx, sr, waiting)
+	}
+
 	for _, cluster := range r.clustersManager.GetAll() {
 		log.Info("sync controller", "ctrl", sr.Name, "cluster", cluster.GetName())
-		err := r.syncClusterController(cluster, sr)
+		err := r.syncClusterController(ctx, cluster, sr, r.getSyncReconcilerOptions(sr))
 		if err != nil {
 			r.GetLogger().Error(err, "could not sync controller")
 		}
+		r.setClusterRuleHealth(sr.Name, cluster.GetName(), err)
+	}
+
+	err = r.handleResyncRequest(ctx, sr, log)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.setReadyCondition(ctx, sr, waiting)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if sr.Spec.GVK.Version == clusterregistryv1alpha1.AnyVersion {
+		return ctrl.Result{
+			RequeueAfter: versionResolutionInterval,
+		}, nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
-func (r *ResourceSyncRuleReconciler) syncClusterController(cluster *clusters.Cluster, sr *clusterregistryv1alpha1.ResourceSyncRule) error {
+// syncClusterController starts the sync controller of the rule on the cluster, or regenerates it with the options if
+// the rule changed
+func (r *ResourceSyncRuleReconciler) syncClusterController(ctx context.Context, cluster *clusters.Cluster, sr *clusterregistryv1alpha1.ResourceSyncRule, opts []SyncReconcilerOption) error {
 	var ctrl clusters.ManagedController
 	var err error
 
 	if !cluster.HasController(sr.Name) {
-		_, err = InitNewResourceSyncController(sr, cluster, r.clustersManager, r.GetManager(), r.GetLogger(), r.config)
+		_, err = InitNewResourceSyncController(sr, cluster, r.clustersManager, r.GetManager(), r.GetLogger(), r.config, opts...)
 		if err != nil {
 			return err
 		}
@@ -124,21 +294,212 @@ func (r *ResourceSyncRuleReconciler) syncClusterController(cluster *clusters.Clu
 	ctrl = cluster.GetController(sr.Name)
 
 	actualRule := &clusterregistryv1alpha1.ResourceSyncRule{}
-	if rec, ok := ctrl.GetReconciler().(SyncReconciler); ok {
+	rec, ok := ctrl.GetReconciler().(SyncReconciler)
+	if ok {
 		actualRule = rec.GetRule()
+		// the log level of the rule is applied without regenerating the controller
+		rec.SetLogLevel(getRuleLogLevel(sr, r.GetLogger()))
+	}
+
+	// the spec changes evaluated per object are applied without dropping the watches and the caches of the controller
+	if ok && specChanged(actualRule.Spec, sr.Spec) && IsRuleUpdatableInPlace(actualRule, sr) && !r.sourceVersionChanged(cluster, ctrl) {
+		err = rec.UpdateRule(ctx, sr)
+		if err == nil {
+			return r.setSuspended(ctx, rec, sr)
+		}
+		r.GetLogger().Error(err, "could not update rule in place, regenerating")
 	}
 
-	if actualRule != nil && !reflect.DeepEqual(actualRule.Spec, sr.Spec) {
+	if actualRule != nil && (specChanged(actualRule.Spec, sr.Spec) || r.sourceVersionChanged(cluster, ctrl)) {
 		r.GetLogger().Info("needs regenerate")
 		cluster.RemoveController(ctrl)
 		<-ctrl.Stopped()
-		_, err = InitNewResourceSyncController(sr, cluster, r.clustersManager, r.GetManager(), r.GetLogger(), r.config)
+		_, err = InitNewResourceSyncController(sr, cluster, r.clustersManager, r.GetManager(), r.GetLogger(), r.config, opts...)
 		if err != nil {
 			return err
 		}
+
+		return nil
 	}
 
-	return nil
+	if !ok {
+		return nil
+	}
+
+	return r.setSuspended(ctx, rec, sr)
+}
+
+// setSuspended suspends or resumes the sync controller of the rule, suspending keeps the controller and its informers
+// running, so it is not regenerated
+func (r *ResourceSyncRuleReconciler) setSuspended(ctx context.Context, rec SyncReconciler, sr *clusterregistryv1alpha1.ResourceSyncRule) error {
+	if rec.IsSuspended() == sr.Spec.Suspend {
+		return nil
+	}
+
+	return rec.SetSuspended(ctx, sr.Spec.Suspend)
+}
+
+// getSyncReconcilerOptions returns the options of the sync reconcilers of the rule shared by every cluster
+func (r *ResourceSyncRuleReconciler) getSyncReconcilerOptions(sr *clusterregistryv1alpha1.ResourceSyncRule) []SyncReconcilerOption {
+	name := sr.GetName()
+
+	return append(r.getSharedSyncReconcilerOptions(),
+		// the readiness of the rule is updated once the objects of the cluster are synced
+		WithOnConvergedFunc(func() {
+			r.enqueueRule(name)
+		}),
+	)
+}
+
+// getSharedSyncReconcilerOptions returns the options of the sync reconcilers of every rule, including the namespaced
+// ones, so the objects of all the rules are synced by the same registry and limits
+func (r *ResourceSyncRuleReconciler) getSharedSyncReconcilerOptions() []SyncReconcilerOption {
+	return []SyncReconcilerOption{
+		WithSyncStateAggregator(r.syncState),
+		WithRuleRegistry(r.ruleRegistry),
+		WithObjectQuota(r.objectQuota),
+		WithLogSampler(r.logSampler),
+		WithWriteLimiter(r.writeLimiter),
+		WithHooks(r.syncHooks...),
+		WithSharedSourceWatches(r.sharedWatches),
+		WithLocalClusterIDResolver(r.localClusterID),
+		WithAuditLogger(r.auditLog),
+	}
+}
+
+// handleResyncRequest enqueues every object of the rule on every cluster when the value of the resync-requested
+// annotation changes, and records the handled value in the rule status
+func (r *ResourceSyncRuleReconciler) handleResyncRequest(ctx context.Context, sr *clusterregistryv1alpha1.ResourceSyncRule, log logr.Logger) error {
+	requested := sr.GetAnnotations()[clusterregistryv1alpha1.ResyncRequestedAnnotation]
+	if requested == "" || r.isResyncHandled(sr, requested) {
+		return nil
+	}
+
+	log.Info("resync requested", "value", requested)
+
+	recorder := events.NewSafeRecorder(r.GetManager().GetEventRecorderFor(ruleEventsComponent), r.GetManager().GetScheme(), r.GetManager().GetRESTMapper(), r.GetLogger())
+	for _, cluster := range r.clustersManager.GetAll() {
+		ctrl := cluster.GetController(sr.Name)
+		if ctrl == nil {
+			continue
+		}
+
+		rec, ok := ctrl.GetReconciler().(SyncReconciler)
+		if !ok {
+			continue
+		}
+
+		// an unreachable cluster is synced entirely anyway when its controller starts again
+		if err := rec.Resync(ctx); err != nil {
+			log.Error(err, "could not resync", "cluster", cluster.GetName())
+			recorder.Event(sr, corev1.EventTypeWarning, "ResyncFailed", fmt.Sprintf("could not resync objects from cluster %s: %s", cluster.GetName(), err))
+		}
+	}
+
+	recorder.Event(sr, corev1.EventTypeNormal, "ResyncRequested", fmt.Sprintf("every object of the rule is synced again (resync-requested: %s)", requested))
+	r.setResyncHandled(sr, requested)
+
+	return errors.WrapIf(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		rule := &clusterregistryv1alpha1.ResourceSyncRule{}
+		if err := r.GetClient().Get(ctx, client.ObjectKeyFromObject(sr), rule); err != nil {
+			return err
+		}
+
+		rule.Status.LastHandledResync = requested
+
+		return r.GetClient().Status().Update(ctx, rule)
+	}), "could not record handled resync")
+}
+
+// resyncRequestedPredicate passes the rules whose resync-requested annotation changed
+func resyncRequestedPredicate() predicate.Predicate {
+	return annotationChangedPredicate(clusterregistryv1alpha1.ResyncRequestedAnnotation)
+}
+
+// logLevelChangedPredicate passes the rules whose log level annotation changed, the level is applied to the running
+// sync controllers
+func logLevelChangedPredicate() predicate.Predicate {
+	return annotationChangedPredicate(clusterregistryv1alpha1.LogLevelAnnotation)
+}
+
+// annotationChangedPredicate passes the updates changing the annotation
+func annotationChangedPredicate(annotation string) predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetAnnotations()[annotation] != e.ObjectNew.GetAnnotations()[annotation]
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// specChanged returns whether the sync controllers of the rule must be regenerated for the new spec
+func specChanged(actual, desired clusterregistryv1alpha1.ResourceSyncRuleSpec) bool {
+	actual.Suspend = false
+	desired.Suspend = false
+	actual.DependsOn = nil
+	desired.DependsOn = nil
+
+	return !reflect.DeepEqual(actual, desired)
+}
+
+func (r *ResourceSyncRuleReconciler) setSuspendedCondition(ctx context.Context, sr *clusterregistryv1alpha1.ResourceSyncRule) error {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeSuspended,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: sr.GetGeneration(),
+		Reason:             "Active",
+		Message:            "objects are synced",
+	}
+	if sr.Spec.Suspend {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Suspended"
+		condition.Message = "syncing is suspended, synced objects are left untouched"
+	}
+
+	return errors.WrapIf(SetResourceSyncRuleCondition(ctx, r.GetClient(), sr.GetName(), condition), "could not update rule status")
+}
+
+// sourceVersionChanged returns whether the cluster serves a different version than the one
+// the controller resolved for a rule syncing any version of a kind
+func (r *ResourceSyncRuleReconciler) sourceVersionChanged(cluster *clusters.Cluster, ctrl clusters.ManagedController) bool {
+	rec, ok := ctrl.GetReconciler().(SyncReconciler)
+	if !ok {
+		return false
+	}
+
+	gvk := rec.GetSourceGVK()
+	if gvk.Version == clusterregistryv1alpha1.AnyVersion || !cluster.IsManagerRunning() {
+		return false
+	}
+
+	// the mapper of the cluster is refreshed on CRD changes, so it knows the currently served versions
+	mapper := cluster.GetRESTMapper()
+	if mapper == nil {
+		return false
+	}
+
+	resolved, err := util.ResolveSourceGVK(mapper, schema.GroupVersionKind(rec.GetRule().Spec.GVK), rec.GetRule().Spec.Versions)
+	if err != nil {
+		r.GetLogger().Error(err, "could not resolve served version", "cluster", cluster.GetName())
+
+		return false
+	}
+
+	if resolved != gvk {
+		r.GetLogger().Info("served version changed", "cluster", cluster.GetName(), "old", gvk, "new", resolved)
+
+		return true
+	}
+
+	return false
 }
 
 func (r *ResourceSyncRuleReconciler) SetupWithController(ctx context.Context, ctrl controller.Controller) error {
@@ -147,33 +508,54 @@ func (r *ResourceSyncRuleReconciler) SetupWithController(ctx context.Context, ct
 		return err
 	}
 
-	err = ctrl.Watch(&InMemorySource{
-		reconciler: r,
-	}, handler.Funcs{})
+	err = ctrl.Watch(r.triggers, handler.Funcs{})
 	if err != nil {
 		return err
 	}
 
 	r.clustersManager.AddOnAfterAddFunc(func(c *clusters.Cluster) {
-		if r.queue != nil {
-			rules := &clusterregistryv1alpha1.ResourceSyncRuleList{}
-			err := r.GetClient().List(ctx, rules)
-			if err != nil {
-				r.GetLogger().Error(err, "could not list resource sync rules")
-			}
-			for _, rule := range rules.Items {
-				r.queue.Add(reconcile.Request{
-					NamespacedName: types.NamespacedName{
-						Name: rule.Name,
-					},
-				})
-			}
-		}
+		r.enqueueRules(ctx)
+	}, "trigger-resource-sync-rule-reconcile")
+
+	// the readiness of the rules changes with the clusters assigned to the replica
+	r.clustersManager.AddOnShardChangeFunc(func() {
+		r.enqueueRules(ctx)
 	}, "trigger-resource-sync-rule-reconcile")
 
 	return nil
 }
 
+// GetTriggerSource returns the source the components of the controller enqueue the rules reconciled again through
+func (r *ResourceSyncRuleReconciler) GetTriggerSource() *InMemorySource {
+	return r.triggers
+}
+
+// enqueueAllObjects reconciles every rule again on a full resync triggered through the source of the reconciler, the
+// rules are always listed from the cache
+func (r *ResourceSyncRuleReconciler) enqueueAllObjects(ctx context.Context, _ bool) {
+	r.enqueueRules(ctx)
+}
+
+// enqueueRules reconciles every rule again, e.g. to start their sync controllers on a new cluster
+func (r *ResourceSyncRuleReconciler) enqueueRules(ctx context.Context) {
+	if r.queue == nil {
+		return
+	}
+
+	rules := &clusterregistryv1alpha1.ResourceSyncRuleList{}
+	err := r.GetClient().List(ctx, rules)
+	if err != nil {
+		r.GetLogger().Error(err, "could not list resource sync rules")
+	}
+	for _, rule := range rules.Items {
+		r.queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name: rule.Name,
+			},
+		})
+	}
+}
+
 func (r *ResourceSyncRuleReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
 	err := r.ManagedReconciler.SetupWithManager(ctx, mgr)
 	if err != nil {
@@ -187,11 +569,13 @@ func (r *ResourceSyncRuleReconciler) SetupWithManager(ctx context.Context, mgr c
 			Kind:       "ResourceSyncRule",
 			APIVersion: clusterregistryv1alpha1.SchemeBuilder.GroupVersion.String(),
 		},
-	}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+	}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, resyncRequestedPredicate(), logLevelChangedPredicate()))).
+		// the rules waiting for a rule are reconciled when it becomes ready or is deleted
+		Watches(&source.Kind{Type: &clusterregistryv1alpha1.ResourceSyncRule{}}, handler.EnqueueRequestsFromMapFunc(r.getDependentRuleRequests), builder.WithPredicates(dependencyChangedPredicate())).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: r.config.SyncController.WorkerCount,
 		}).
-		Build(r)
+		Build(clusters.NewGatedReconciler(r, r.clustersManager.GetReconcileGate()))
 	if err != nil {
 		return err
 	}
@@ -203,10 +587,22 @@ func (r *ResourceSyncRuleReconciler) SetupWithManager(ctx context.Context, mgr c
 
 	r.SetClient(mgr.GetClient())
 
-	return nil
+	// the sync controllers of every rule report to the same aggregator, which summarizes them per cluster
+	r.syncState = syncstate.NewAggregator(func(ctx context.Context, cluster string, state clusterregistryv1alpha1.ClusterSyncState) error {
+		return SetClusterSyncState(ctx, mgr.GetClient(), cluster, state)
+	}, syncstate.WithInterval(r.config.SyncController.SyncStateInterval), syncstate.WithLogger(r.GetLogger().WithName("sync-state")))
+
+	// the pending sync states are written once the running reconciles returned on shutdown
+	if gate := r.clustersManager.GetReconcileGate(); gate != nil {
+		gate.AddOnDrainedFunc(func(ctx context.Context) {
+			r.syncState.Flush(ctx)
+		})
+	}
+
+	return errors.WrapIf(mgr.Add(r.syncState), "could not add sync state aggregator")
 }
 
-func InitNewResourceSyncController(rule *clusterregistryv1alpha1.ResourceSyncRule, cluster *clusters.Cluster, clustersManager *clusters.Manager, mgr ctrl.Manager, log logr.Logger, config config.Configuration) (clusters.ManagedController, error) {
+func InitNewResourceSyncController(rule *clusterregistryv1alpha1.ResourceSyncRule, cluster *clusters.Cluster, clustersManager clusters.ClusterLister, mgr ctrl.Manager, log logr.Logger, config config.Configuration, opts ...SyncReconcilerOption) (clusters.ManagedController, error) {
 	rl, err := ratelimit.NewRateLimiter(config.SyncController.RateLimit.MaxKeys, &throttled.RateQuota{
 		MaxRate:  throttled.PerSec(config.SyncController.RateLimit.MaxRatePerSecond),
 		MaxBurst: config.SyncController.RateLimit.MaxBurst,
@@ -215,6 +611,26 @@ func InitNewResourceSyncController(rule *clusterregistryv1alpha1.ResourceSyncRul
 		return nil, errors.WrapIf(err, "could not create rate limiter")
 	}
 
+	writeFormatVersion, err := util.ParseFormatVersion(config.SyncController.WriteFormatVersion)
+	if err != nil {
+		return nil, errors.WrapIf(err, "invalid write format version")
+	}
+
+	denyList, err := util.NewDenyList(config.SyncController.ProtectedNamespaces, config.SyncController.DeniedGVKs)
+	if err != nil {
+		return nil, errors.WrapIf(err, "invalid deny list")
+	}
+
+	namespaceAllowList, err := util.NewNamespaceAllowList(config.SyncController.AllowedTargetNamespaces)
+	if err != nil {
+		return nil, errors.WrapIf(err, "invalid allowed target namespaces")
+	}
+
+	redactor, err := util.NewRedactor(config.SyncController.RedactedFields)
+	if err != nil {
+		return nil, errors.WrapIf(err, "invalid redacted fields")
+	}
+
 	requiredClusterFeatures := make([]clusters.ClusterFeatureRequirement, 0)
 	for _, m := range rule.Spec.ClusterFeatureMatches {
 		requiredClusterFeatures = append(requiredClusterFeatures, clusters.ClusterFeatureRequirement{
@@ -224,12 +640,44 @@ func InitNewResourceSyncController(rule *clusterregistryv1alpha1.ResourceSyncRul
 		})
 	}
 
+	// the pager of the source cache of the controller reports the progress of its warm-up
+	listPager := newListPager(config)
+
 	log = log.WithName(rule.Name)
-	srec, err := NewSyncReconciler(rule.Name, mgr, rule, log, cluster.GetClusterID(), clustersManager, WithRateLimiter(rl))
+	opts = append([]SyncReconcilerOption{WithRateLimiter(rl), WithWriteFormatVersion(writeFormatVersion), WithClusterName(cluster.GetName()), WithDenyList(denyList), WithNamespaceAllowList(namespaceAllowList), WithRedactor(redactor), WithFailureThreshold(config.SyncController.FailureThreshold), WithReconcileTimeout(config.SyncController.ReconcileTimeout), WithNewCacheFunc(NewCacheFunc(config)), WithMaxSyncHops(config.SyncController.MaxSyncHops), WithFullReconcileInterval(config.SyncController.FullReconcileInterval), WithMaxObjectSize(config.SyncController.MaxObjectSize), WithLastAppliedSizeLimit(config.SyncController.LastAppliedSizeLimit), WithServiceAccountNamespace(config.Namespace), WithAccessCheckInterval(config.SyncController.AccessCheckInterval), WithFreshnessThreshold(config.SyncController.FreshnessThreshold), WithStartupWindow(config.SyncController.StartupWindow), WithListPager(listPager)}, opts...)
+	srec, err := NewSyncReconciler(rule.Name, mgr, rule, log, cluster.GetClusterID(), clustersManager, opts...)
 	if err != nil {
 		return nil, errors.WithStackIf(err)
 	}
-	ctrl := clusters.NewManagedController(rule.Name, srec, log, clusters.WithRequiredClusterFeatures(requiredClusterFeatures...))
+	ctrl := clusters.NewManagedController(rule.Name, srec, log,
+		clusters.WithRequiredClusterFeatures(requiredClusterFeatures...),
+		clusters.WithMaxConcurrentReconciles(rule.Spec.Workers),
+		clusters.WithWorkqueueRateLimiter(getWorkqueueRateLimiter(rule.Spec.Backoff)),
+		clusters.WithNewCacheFunc(newCacheFunc(config, listPager)),
+	)
 
 	return ctrl, cluster.AddController(ctrl)
 }
+
+// getWorkqueueRateLimiter returns the default rate limiter of the controllers with the backoff delays of the rule
+func getWorkqueueRateLimiter(backoff *clusterregistryv1alpha1.SyncBackoff) workqueue.RateLimiter {
+	if backoff == nil || backoff.BaseDelay.Duration <= 0 && backoff.MaxDelay.Duration <= 0 {
+		return workqueue.DefaultControllerRateLimiter()
+	}
+
+	baseDelay := defaultBackoffBaseDelay
+	if backoff.BaseDelay.Duration > 0 {
+		baseDelay = backoff.BaseDelay.Duration
+	}
+	maxDelay := defaultBackoffMaxDelay
+	if backoff.MaxDelay.Duration > 0 {
+		maxDelay = backoff.MaxDelay.Duration
+	}
+
+	// the overall limit of the default rate limiter can not be taken from it without its per object backoff, which
+	// would override the delays of the rule, so it is built the same way
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(defaultOverallQPS), defaultOverallBurst)},
+	)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	"emperror.dev/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// getShardedSyncingClusters returns the clusters still syncing the objects of the rule on any replica. The replica
+// records whether the rule converged on each of its clusters in the status of the rule, and reads back what the
+// other replicas recorded for their clusters.
+func (r *ResourceSyncRuleReconciler) getShardedSyncingClusters(ctx context.Context, sr *clusterregistryv1alpha1.ResourceSyncRule, syncing []string) ([]string, error) {
+	local := make(map[string]struct{}, len(syncing))
+	for _, name := range syncing {
+		local[name] = struct{}{}
+	}
+
+	for _, cluster := range r.clustersManager.GetAll() {
+		if cluster.GetController(sr.GetName()) == nil {
+			continue
+		}
+
+		condition := metav1.Condition{
+			Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeSynced,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: sr.GetGeneration(),
+			Reason:             "Synced",
+			Message:            "the objects are synced from the cluster",
+		}
+		if _, ok := local[cluster.GetName()]; ok {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "Syncing"
+			condition.Message = "the objects are being synced from the cluster"
+		} else if !cluster.IsAlive() {
+			condition.Status = metav1.ConditionUnknown
+			condition.Reason = "ClusterNotAlive"
+			condition.Message = "the cluster is not alive"
+		}
+
+		err := SetResourceSyncRuleClusterCondition(ctx, r.GetClient(), sr.GetName(), cluster.GetName(), condition)
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not update rule status")
+		}
+	}
+
+	// the status written by the other replicas may not be in the cache yet
+	rule, key, ruleStatus := getRuleObject(sr.GetName())
+	if err := r.GetManager().GetAPIReader().Get(ctx, key, rule); err != nil {
+		return nil, errors.WrapIf(err, "could not get rule")
+	}
+
+	for _, status := range ruleStatus.Clusters {
+		if _, ok := local[status.Name]; ok || r.clustersManager.Owns(status.Name) {
+			continue
+		}
+
+		condition := meta.FindStatusCondition(status.Conditions, clusterregistryv1alpha1.ResourceSyncRuleConditionTypeSynced)
+		if condition == nil || condition.Status != metav1.ConditionFalse {
+			continue
+		}
+
+		// the status of deleted clusters is left behind
+		err := r.GetClient().Get(ctx, client.ObjectKey{Name: status.Name}, &clusterregistryv1alpha1.Cluster{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not get cluster")
+		}
+
+		syncing = append(syncing, status.Name)
+	}
+	sort.Strings(syncing)
+
+	return syncing, nil
+}
+
+// isResyncHandled returns whether the requested resync was handled already. The value handled by the replicas is
+// kept in memory in sharded mode, since the status only records that one of the replicas handled it.
+func (r *ResourceSyncRuleReconciler) isResyncHandled(sr *clusterregistryv1alpha1.ResourceSyncRule, requested string) bool {
+	if !r.clustersManager.IsSharded() {
+		return requested == sr.Status.LastHandledResync
+	}
+
+	r.handledResyncsMu.Lock()
+	defer r.handledResyncsMu.Unlock()
+
+	return r.handledResyncs[sr.GetName()] == requested
+}
+
+func (r *ResourceSyncRuleReconciler) setResyncHandled(sr *clusterregistryv1alpha1.ResourceSyncRule, requested string) {
+	r.handledResyncsMu.Lock()
+	defer r.handledResyncsMu.Unlock()
+
+	r.handledResyncs[sr.GetName()] = requested
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+var _ = Describe("Resource sync rule validator webhook", func() {
+	newRule := func(name string, gvk resources.GroupVersionKind) *clusterregistryv1alpha1.ResourceSyncRule {
+		return &clusterregistryv1alpha1.ResourceSyncRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				GVK: gvk,
+				Rules: []clusterregistryv1alpha1.SyncRule{
+					{
+						Matches: []clusterregistryv1alpha1.SyncRuleMatch{
+							{
+								Namespaces: []string{"default"},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	It("admits valid rules", func() {
+		ctx := context.Background()
+
+		rule := newRule("webhook-valid", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+		Expect(k8sClient.Create(ctx, rule)).Should(Succeed())
+		Expect(k8sClient.Delete(ctx, rule)).Should(Succeed())
+	})
+
+	It("rejects rules with an unparsable GVK", func() {
+		rule := newRule("webhook-invalid-gvk", resources.GroupVersionKind{Group: "Invalid_Group", Version: "v1", Kind: "ConfigMap"})
+		Expect(k8sClient.Create(context.Background(), rule)).ShouldNot(Succeed())
+	})
+
+	It("rejects rules with an invalid label selector", func() {
+		rule := newRule("webhook-invalid-selector", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+		rule.Spec.Rules[0].Matches[0].Labels = []metav1.LabelSelector{
+			{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{
+						Key:      "app",
+						Operator: metav1.LabelSelectorOpIn,
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(context.Background(), rule)).ShouldNot(Succeed())
+	})
+
+	It("rejects rules mutating to an unregistered kind", func() {
+		rule := newRule("webhook-unregistered-kind", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+		rule.Spec.Rules[0].Mutations.GVK = &resources.GroupVersionKind{Group: "example.com", Kind: "Unknown"}
+		Expect(k8sClient.Create(context.Background(), rule)).ShouldNot(Succeed())
+	})
+
+	It("admits secret data mutations of Secrets of any version", func() {
+		ctx := context.Background()
+
+		rule := newRule("webhook-secret-data-any-version", resources.GroupVersionKind{Version: clusterregistryv1alpha1.AnyVersion, Kind: "Secret"})
+		rule.Spec.Rules[0].Mutations.SecretData = &clusterregistryv1alpha1.SecretDataMutations{
+			IncludeKeys: []string{"ca.crt"},
+		}
+		Expect(k8sClient.Create(ctx, rule)).Should(Succeed())
+		Expect(k8sClient.Delete(ctx, rule)).Should(Succeed())
+	})
+
+	It("rejects secret data mutations of other kinds", func() {
+		rule := newRule("webhook-secret-data-configmap", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+		rule.Spec.Rules[0].Mutations.SecretData = &clusterregistryv1alpha1.SecretDataMutations{
+			IncludeKeys: []string{"ca.crt"},
+		}
+		Expect(k8sClient.Create(context.Background(), rule)).ShouldNot(Succeed())
+	})
+})
@@ -19,31 +19,46 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/envtest/printer"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
 
 	// +kubebuilder:scaffold:imports
 	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
 	"github.com/cisco-open/cluster-registry-controller/controllers"
 	"github.com/cisco-open/cluster-registry-controller/internal/config"
 	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+	"github.com/cisco-open/cluster-registry-controller/pkg/webhooks"
 )
 
 // These tests use Ginkgo (BDD-style Go testing framework). Refer to
 // http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
 
+// syncTestWorkers is the number of workers of the sync reconcilers of the tests, more than one, so the races between
+// the workers are caught by the race detector
+const syncTestWorkers = 4
+
 var (
-	cfg       *rest.Config
-	k8sClient client.Client
-	testEnv   *envtest.Environment
+	cfg        *rest.Config
+	k8sClient  client.Client
+	k8sManager ctrl.Manager
+	testEnv    *envtest.Environment
 )
 
 func TestAPIs(t *testing.T) {
@@ -61,6 +76,9 @@ var _ = BeforeSuite(func(done Done) {
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths: []string{filepath.Join("..", "deploy", "charts", "cluster-registry", "crds")},
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{filepath.Join("..", "config", "webhook")},
+		},
 	}
 
 	var err error
@@ -75,11 +93,13 @@ var _ = BeforeSuite(func(done Done) {
 
 	stop := ctrl.SetupSignalHandler()
 
-	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{
+	k8sManager, err = ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:             scheme.Scheme,
 		MetricsBindAddress: "0",
 		LeaderElection:     false,
-		Port:               0,
+		Host:               testEnv.WebhookInstallOptions.LocalServingHost,
+		Port:               testEnv.WebhookInstallOptions.LocalServingPort,
+		CertDir:            testEnv.WebhookInstallOptions.LocalServingCertDir,
 	})
 	Expect(err).ToNot(HaveOccurred())
 	Expect(k8sManager).ToNot(BeNil())
@@ -89,6 +109,13 @@ var _ = BeforeSuite(func(done Done) {
 	err = controllers.NewClusterReconciler("clusters", ctrl.Log.WithName("controllers").WithName("cluster"), clusters.NewManager(ctx), config.Configuration{}).SetupWithManager(ctx, k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	k8sManager.GetWebhookServer().Register(
+		"/validate-resourcesyncrule",
+		&webhook.Admission{
+			Handler: webhooks.NewResourceSyncRuleValidator(ctrl.Log.WithName("resource-sync-rule-validator"), k8sManager.GetClient(), k8sManager.GetScheme(), nil),
+		},
+	)
+
 	go func() {
 		err = k8sManager.Start(stop)
 		Expect(err).ToNot(HaveOccurred())
@@ -105,3 +132,85 @@ var _ = AfterSuite(func() {
 	err := testEnv.Stop()
 	Expect(err).ToNot(HaveOccurred())
 })
+
+// newSyncTestRule returns a rule syncing the objects of the kind which have the name of the rule as a label. The source
+// and the local cluster are the same in the tests, so the synced object is renamed to <source name>-synced and its
+// matching label is removed to not be synced again.
+func newSyncTestRule(name string, gvk resources.GroupVersionKind) *clusterregistryv1alpha1.ResourceSyncRule {
+	return &clusterregistryv1alpha1.ResourceSyncRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+			GVK: gvk,
+			Rules: []clusterregistryv1alpha1.SyncRule{
+				{
+					Matches: []clusterregistryv1alpha1.SyncRuleMatch{
+						{
+							Labels: []metav1.LabelSelector{
+								{
+									MatchLabels: map[string]string{name: "true"},
+								},
+							},
+						},
+					},
+					Mutations: clusterregistryv1alpha1.Mutations{
+						Overrides: []resources.K8SResourceOverlayPatch{
+							{
+								Type:  resources.ReplaceOverlayPatchType,
+								Path:  pointer.String("/metadata/name"),
+								Value: pointer.String(name + "-synced"),
+							},
+							{
+								Type: resources.DeleteOverlayPatchType,
+								Path: pointer.String("/metadata/labels/" + name),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newSyncTestConfigMap returns a source ConfigMap in the default namespace matching the test rule of the name
+func newSyncTestConfigMap(name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			Labels:    map[string]string{name: "true"},
+		},
+		Data: map[string]string{"key": "source"},
+	}
+}
+
+// syncTestKey returns the key of the object synced from the source object by the test rule
+func syncTestKey(source client.Object) types.NamespacedName {
+	return types.NamespacedName{Name: source.GetName() + "-synced", Namespace: source.GetNamespace()}
+}
+
+// startSyncReconciler sets up a sync reconciler of the rule on the test manager and starts its controller, which runs
+// until the context is cancelled. The reconciler is torn down once its workers stopped.
+func startSyncReconciler(ctx context.Context, rule *clusterregistryv1alpha1.ResourceSyncRule, opts ...controllers.SyncReconcilerOption) controllers.SyncReconciler {
+	rec, err := controllers.NewSyncReconciler(rule.Name, k8sManager, rule, logr.Discard(), rule.Name+"-cluster", clusters.NewManager(ctx), opts...)
+	Expect(err).ToNot(HaveOccurred())
+	rec.SetManager(k8sManager)
+	rec.SetClient(k8sClient)
+	Expect(rec.PreCheck(ctx, k8sClient)).Should(Succeed())
+
+	c, err := controller.NewUnmanaged(rule.Name, k8sManager, controller.Options{
+		Reconciler:              rec,
+		MaxConcurrentReconciles: syncTestWorkers,
+	})
+	Expect(err).ToNot(HaveOccurred())
+	Expect(rec.SetupWithController(ctx, c)).Should(Succeed())
+
+	go func() {
+		defer GinkgoRecover()
+		defer rec.Teardown()
+		Expect(c.Start(ctx)).Should(Succeed())
+	}()
+
+	return rec
+}
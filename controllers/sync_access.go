@@ -0,0 +1,252 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	pluralize "github.com/gertd/go-pluralize"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// DefaultAccessCheckInterval is the time between two access checks of a running sync controller
+const DefaultAccessCheckInterval = 5 * time.Minute
+
+// errAccessMissing is returned by the access check if a permission needed by the rule is not granted
+var errAccessMissing = errors.NewPlain("missing permissions")
+
+// accessCheck is a set of permissions checked with the same client
+type accessCheck struct {
+	// cluster and identity describe where and as who the permissions are checked in the condition message
+	cluster  string
+	identity string
+	client   client.Client
+	attrs    []authorizationv1.ResourceAttributes
+	// write is set for the permissions of the identity the synced objects are written with
+	write bool
+}
+
+// getResourceAttributes returns the attributes of the verbs on the resource of the kind
+func getResourceAttributes(gvk schema.GroupVersionKind, subresource string, verbs ...string) []authorizationv1.ResourceAttributes {
+	attrs := make([]authorizationv1.ResourceAttributes, 0, len(verbs))
+	for _, verb := range verbs {
+		attrs = append(attrs, authorizationv1.ResourceAttributes{
+			Verb:        verb,
+			Group:       gvk.Group,
+			Version:     gvk.Version,
+			Resource:    strings.ToLower(pluralize.NewClient().Plural(gvk.Kind)),
+			Subresource: subresource,
+		})
+	}
+
+	return attrs
+}
+
+func formatResourceAttributes(attr authorizationv1.ResourceAttributes) string {
+	resource := attr.Resource
+	if attr.Group != "" {
+		resource += "." + attr.Group
+	}
+	if attr.Subresource != "" {
+		resource += "/" + attr.Subresource
+	}
+
+	return attr.Verb + " " + resource
+}
+
+// syncsStatus returns whether any rule of the rule syncs the status of the objects
+func (r *syncReconciler) syncsStatus() bool {
+	for _, rule := range r.getRule().Spec.Rules {
+		if rule.Mutations.SyncStatus {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getAccessChecks returns the permissions needed by the rule: reading the source objects on the source cluster,
+// caching the synced objects with the identity of the controller, and writing them with the identity of the rule
+func (r *syncReconciler) getAccessChecks(sourceClient client.Client) ([]accessCheck, error) {
+	writeClient, err := client.New(r.getLocalWriteConfig(), client.Options{
+		Scheme: r.localMgr.GetScheme(),
+		Mapper: r.localMgr.GetRESTMapper(),
+	})
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not create local client")
+	}
+
+	writeAttrs := getResourceAttributes(r.getLocalGVK(), "", "get", "create", "update", "patch", "delete")
+	if r.syncsStatus() {
+		writeAttrs = append(writeAttrs, getResourceAttributes(r.getLocalGVK(), "status", "update", "patch")...)
+	}
+
+	return []accessCheck{
+		{
+			cluster:  "source",
+			identity: "the identity of the controller",
+			client:   sourceClient,
+			attrs:    getResourceAttributes(r.GetSourceGVK(), "", "get", "list", "watch"),
+		},
+		{
+			cluster:  "local",
+			identity: "the identity of the controller",
+			client:   r.localMgr.GetClient(),
+			attrs:    getResourceAttributes(r.getLocalGVK(), "", "list", "watch"),
+		},
+		{
+			cluster:  "local",
+			identity: r.getWriteIdentity(),
+			client:   writeClient,
+			attrs:    writeAttrs,
+			write:    true,
+		},
+	}, nil
+}
+
+// verifyAccess checks the permissions needed by the rule with self subject access reviews and reports the missing
+// ones in the AccessVerified condition of the rule, errAccessMissing is returned if any of them is missing. The
+// Forbidden condition is set if a write permission is missing, and cleared if none is, which re-enqueues the objects
+// parked after forbidden requests right away.
+func (r *syncReconciler) verifyAccess(ctx context.Context, sourceClient client.Client, reset bool) error {
+	checks, err := r.getAccessChecks(sourceClient)
+	if err != nil {
+		return err
+	}
+
+	missing := make([]string, 0)
+	var writeErr error
+	for _, check := range checks {
+		denied := make([]string, 0)
+		for _, attr := range check.attrs {
+			attr := attr
+			review := &authorizationv1.SelfSubjectAccessReview{
+				Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+					ResourceAttributes: &attr,
+				},
+			}
+
+			err := check.client.Create(ctx, review)
+			// the review itself is forbidden if the controller is not allowed to impersonate the identity of the rule
+			if apierrors.IsForbidden(err) {
+				denied = append(denied, fmt.Sprintf("%s (%s)", formatResourceAttributes(attr), apierrors.ReasonForError(err)))
+
+				continue
+			}
+			if err != nil {
+				return errors.WrapIfWithDetails(err, "failed to create self subject access review", "cluster", check.cluster, "attributes", attr)
+			}
+
+			if !review.Status.Allowed {
+				denied = append(denied, formatResourceAttributes(attr))
+			}
+		}
+
+		if len(denied) == 0 {
+			continue
+		}
+
+		msg := fmt.Sprintf("on the %s cluster as %s: %s", check.cluster, check.identity, strings.Join(denied, ", "))
+		missing = append(missing, msg)
+		if check.write {
+			writeErr = errors.WrapIf(errWriteNotAllowed, msg)
+		}
+	}
+
+	if reset {
+		r.forbiddenMu.Lock()
+		r.forbidden = writeErr != nil
+		r.forbiddenMu.Unlock()
+		r.setForbiddenCondition(ctx, writeErr)
+		if writeErr == nil {
+			r.releaseForbiddenObjects()
+		}
+	} else {
+		r.onWriteResult(ctx, writeErr)
+	}
+
+	if err := r.setClusterCondition(ctx, r.getAccessVerifiedCondition(missing)); err != nil {
+		r.GetLogger().Error(err, "could not set access verified condition")
+	}
+
+	if len(missing) > 0 {
+		return errors.WithDetails(errors.WrapIf(errAccessMissing, strings.Join(missing, "; ")), "rule", r.getRule().GetName(), "cluster", r.clusterName)
+	}
+
+	return nil
+}
+
+func (r *syncReconciler) getAccessVerifiedCondition(missing []string) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeAccessVerified,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "PermissionsGranted",
+		Message:            "every permission needed by the rule is granted",
+	}
+
+	if len(missing) == 0 {
+		return condition
+	}
+
+	condition.Status = metav1.ConditionFalse
+	condition.Reason = "PermissionsMissing"
+	condition.Message = fmt.Sprintf("missing permissions %s", strings.Join(missing, "; "))
+
+	return condition
+}
+
+// requestAccessCheck makes the running controller check the permissions of the rule again, e.g. after a forbidden
+// write, so the conditions are updated once the RBAC rules are fixed
+func (r *syncReconciler) requestAccessCheck() {
+	select {
+	case r.accessCheckRequests <- struct{}{}:
+	default:
+	}
+}
+
+// checkAccessPeriodically checks the permissions of the rule every access check interval and whenever a check is
+// requested until the context is done
+func (r *syncReconciler) checkAccessPeriodically(ctx context.Context) {
+	var tick <-chan time.Time
+	if r.accessCheckInterval > 0 {
+		ticker := time.NewTicker(r.accessCheckInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-r.accessCheckRequests:
+		}
+
+		if err := r.verifyAccess(ctx, r.GetClient(), false); err != nil {
+			r.GetLogger().Info("access check failed", append([]interface{}{"error", err.Error()}, errors.GetDetails(err)...)...)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// SyncAnchorName returns the name of the anchor objects of a rule.
+func SyncAnchorName(ruleName string) string {
+	return ruleName + "-sync-anchor"
+}
+
+// EnsureSyncAnchor returns the anchor of the rule for the given namespace and creates it when it does not exist.
+// Namespaced objects are anchored to a ConfigMap in their own namespace, cluster scoped objects to a SyncAnchor.
+// The anchor is always read from the API server, a stale anchor UID would let the garbage collector delete
+// the synced objects.
+func EnsureSyncAnchor(ctx context.Context, reader client.Reader, writer client.Writer, ruleName string, namespace string) (client.Object, error) {
+	anchor := newSyncAnchor(ruleName, namespace)
+
+	err := reader.Get(ctx, client.ObjectKeyFromObject(anchor), anchor)
+	if apierrors.IsNotFound(err) {
+		anchor = newSyncAnchor(ruleName, namespace)
+		err = writer.Create(ctx, anchor)
+		if apierrors.IsAlreadyExists(err) {
+			err = reader.Get(ctx, client.ObjectKeyFromObject(anchor), anchor)
+		}
+	}
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not ensure sync anchor", "rule", ruleName, "namespace", namespace)
+	}
+
+	return anchor, nil
+}
+
+// SetSyncAnchorOwnerReference sets the anchor as a non-controller owner of the object.
+func SetSyncAnchorOwnerReference(obj client.Object, anchor client.Object, scheme *runtime.Scheme) error {
+	gvk, err := apiutil.GVKForObject(anchor, scheme)
+	if err != nil {
+		return errors.WrapIf(err, "could not get gvk for sync anchor")
+	}
+
+	ownerReferences := make([]metav1.OwnerReference, 0)
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID != anchor.GetUID() {
+			ownerReferences = append(ownerReferences, ref)
+		}
+	}
+	ownerReferences = append(ownerReferences, metav1.OwnerReference{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Name:       anchor.GetName(),
+		UID:        anchor.GetUID(),
+	})
+	obj.SetOwnerReferences(ownerReferences)
+
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[clusterregistryv1alpha1.SyncAnchorRuleLabel] = anchor.GetLabels()[clusterregistryv1alpha1.SyncAnchorRuleLabel]
+	obj.SetLabels(labels)
+
+	return nil
+}
+
+// DeleteSyncAnchors removes every anchor of the rule, the objects owned by the anchors are orphaned
+// instead of being garbage collected.
+func DeleteSyncAnchors(ctx context.Context, reader client.Reader, writer client.Writer, ruleName string) error {
+	anchors := make([]client.Object, 0)
+
+	configMaps := &corev1.ConfigMapList{}
+	err := reader.List(ctx, configMaps, client.MatchingLabels{
+		clusterregistryv1alpha1.SyncAnchorRuleLabel: ruleName,
+	})
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not list sync anchors", "rule", ruleName)
+	}
+	for i := range configMaps.Items {
+		anchors = append(anchors, &configMaps.Items[i])
+	}
+
+	syncAnchors := &clusterregistryv1alpha1.SyncAnchorList{}
+	err = reader.List(ctx, syncAnchors, client.MatchingLabels{
+		clusterregistryv1alpha1.SyncAnchorRuleLabel: ruleName,
+	})
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not list sync anchors", "rule", ruleName)
+	}
+	for i := range syncAnchors.Items {
+		anchors = append(anchors, &syncAnchors.Items[i])
+	}
+
+	for _, anchor := range anchors {
+		err = writer.Delete(ctx, anchor, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.WrapIfWithDetails(err, "could not delete sync anchor", "rule", ruleName, "namespace", anchor.GetNamespace(), "name", anchor.GetName())
+		}
+	}
+
+	return nil
+}
+
+func newSyncAnchor(ruleName string, namespace string) client.Object {
+	objectMeta := metav1.ObjectMeta{
+		Name:      SyncAnchorName(ruleName),
+		Namespace: namespace,
+		Labels: map[string]string{
+			clusterregistryv1alpha1.SyncAnchorRuleLabel: ruleName,
+		},
+	}
+
+	if namespace == "" {
+		return &clusterregistryv1alpha1.SyncAnchor{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "SyncAnchor",
+				APIVersion: clusterregistryv1alpha1.SchemeBuilder.GroupVersion.String(),
+			},
+			ObjectMeta: objectMeta,
+			Spec: clusterregistryv1alpha1.SyncAnchorSpec{
+				RuleName: ruleName,
+			},
+		}
+	}
+
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: objectMeta,
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/controllers"
+	"github.com/cisco-open/cluster-registry-controller/internal/config"
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+var _ = Describe("Sync anchors", func() {
+	const (
+		RuleName = "anchored-rule"
+
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	It("wires ownership to per namespace and cluster scoped anchors and cleans them up", func() {
+		ctx := context.Background()
+
+		By("By ensuring a namespaced anchor")
+		namespacedAnchor, err := controllers.EnsureSyncAnchor(ctx, k8sClient, k8sClient, RuleName, metav1.NamespaceDefault)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespacedAnchor).To(BeAssignableToTypeOf(&corev1.ConfigMap{}))
+		Expect(namespacedAnchor.GetUID()).ToNot(BeEmpty())
+
+		By("By ensuring the same namespaced anchor again")
+		anchor, err := controllers.EnsureSyncAnchor(ctx, k8sClient, k8sClient, RuleName, metav1.NamespaceDefault)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(anchor.GetUID()).To(Equal(namespacedAnchor.GetUID()))
+
+		By("By ensuring a cluster scoped anchor")
+		clusterAnchor, err := controllers.EnsureSyncAnchor(ctx, k8sClient, k8sClient, RuleName, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(clusterAnchor).To(BeAssignableToTypeOf(&clusterregistryv1alpha1.SyncAnchor{}))
+
+		By("By creating an object owned by the namespaced anchor")
+		synced := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "synced",
+				Namespace: metav1.NamespaceDefault,
+			},
+		}
+		Expect(controllers.SetSyncAnchorOwnerReference(synced, namespacedAnchor, scheme.Scheme)).Should(Succeed())
+		Expect(k8sClient.Create(ctx, synced)).Should(Succeed())
+		Expect(synced.GetOwnerReferences()).To(HaveLen(1))
+		Expect(synced.GetOwnerReferences()[0].UID).To(Equal(namespacedAnchor.GetUID()))
+		Expect(synced.GetOwnerReferences()[0].Controller).To(BeNil())
+		Expect(synced.GetLabels()).To(HaveKeyWithValue(clusterregistryv1alpha1.SyncAnchorRuleLabel, RuleName))
+
+		By("By deleting the anchors of the rule")
+		Expect(controllers.DeleteSyncAnchors(ctx, k8sClient, k8sClient, RuleName)).Should(Succeed())
+
+		// there is no garbage collector in the test environment, orphaned anchors keep their orphan finalizer
+		Eventually(func() bool {
+			current := &clusterregistryv1alpha1.SyncAnchor{}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(clusterAnchor), current)
+
+			return apierrors.IsNotFound(err) || !current.GetDeletionTimestamp().IsZero()
+		}, timeout, interval).Should(BeTrue())
+
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(synced), &corev1.ConfigMap{})).Should(Succeed())
+	})
+
+	It("anchors the objects synced by the reconciler and releases the anchors when the rule is deleted", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rule := newSyncTestRule("anchor-ownership-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+		rule.Spec.AnchorOwnership = true
+		Expect(k8sClient.Create(ctx, rule)).Should(Succeed())
+
+		syncCtx, stopSync := context.WithCancel(ctx)
+		defer stopSync()
+		startSyncReconciler(syncCtx, rule)
+
+		By("By syncing a matching source object")
+		source := newSyncTestConfigMap(rule.Name)
+		Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+		synced := &corev1.ConfigMap{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, syncTestKey(source), synced)
+		}, timeout, interval).Should(Succeed())
+
+		anchor := &corev1.ConfigMap{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: controllers.SyncAnchorName(rule.Name), Namespace: metav1.NamespaceDefault}, anchor)).Should(Succeed())
+		Expect(synced.GetLabels()).To(HaveKeyWithValue(clusterregistryv1alpha1.SyncAnchorRuleLabel, rule.Name))
+		Expect(synced.GetOwnerReferences()).To(HaveLen(1))
+		Expect(synced.GetOwnerReferences()[0].UID).To(Equal(anchor.GetUID()))
+		Expect(synced.GetOwnerReferences()[0].Controller).To(BeNil())
+
+		By("By deleting the rule")
+		// the controllers of a deleted rule are stopped before it is reconciled, otherwise they would recreate the anchors
+		stopSync()
+		Expect(k8sClient.Delete(ctx, rule)).Should(Succeed())
+		Eventually(func() bool {
+			return apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(rule), &clusterregistryv1alpha1.ResourceSyncRule{}))
+		}, timeout, interval).Should(BeTrue())
+
+		ruleReconciler := controllers.NewResourceSyncRuleReconciler(rule.Name, logr.Discard(), clusters.NewManager(ctx), config.Configuration{})
+		ruleReconciler.SetManager(k8sManager)
+		ruleReconciler.SetClient(k8sClient)
+		_, err := ruleReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(rule)})
+		Expect(err).ToNot(HaveOccurred())
+
+		// there is no garbage collector in the test environment, it would remove the owner references of the synced
+		// objects and the orphan finalizer of the anchors
+		Eventually(func() bool {
+			current := &corev1.ConfigMap{}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(anchor), current)
+
+			return apierrors.IsNotFound(err) || !current.GetDeletionTimestamp().IsZero() && controllerutil.ContainsFinalizer(current, metav1.FinalizerOrphanDependents)
+		}, timeout, interval).Should(BeTrue())
+
+		Expect(k8sClient.Get(ctx, syncTestKey(source), &corev1.ConfigMap{})).Should(Succeed())
+	})
+})
@@ -0,0 +1,63 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// setSyncedAt stamps the desired object with the time it is written at if the audit trail is enabled, the current
+// object is nil if it is created
+func setSyncedAt(current, desired runtime.Object) error {
+	desiredObj, ok := desired.(client.Object)
+	if !ok {
+		return errors.New("invalid object")
+	}
+
+	var currentObj client.Object
+	if current != nil {
+		if currentObj, ok = current.(client.Object); !ok {
+			return errors.New("invalid object")
+		}
+	}
+
+	util.SetSyncedAt(currentObj, desiredObj, time.Now())
+
+	return nil
+}
+
+// recordAudit writes the audit record of the desired state of the object written to the local cluster, the records
+// carry the same values as the audit annotations of the object, so they can be matched with each other
+func (r *syncReconciler) recordAudit(req ctrl.Request, obj client.Object, sourceResourceVersion string, syncedAt time.Time) {
+	r.auditLog.Info("object synced",
+		"rule", r.getRule().GetName(),
+		"sourceClusterID", r.clusterID,
+		"sourceCluster", r.clusterName,
+		"localClusterID", r.getLocalClusterID(),
+		"gvk", obj.GetObjectKind().GroupVersionKind().String(),
+		"source", req.NamespacedName.String(),
+		"object", client.ObjectKeyFromObject(obj).String(),
+		"auditHash", util.GetAuditHash(obj),
+		"sourceResourceVersion", sourceResourceVersion,
+		"syncedAt", syncedAt.UTC().Format(time.RFC3339),
+	)
+}
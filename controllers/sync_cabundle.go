@@ -0,0 +1,159 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	objectsync "github.com/cisco-open/cluster-registry-controller/pkg/sync"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// getCABundleSources returns the local Secrets and ConfigMaps the webhook mutations of the rule read CA bundles from
+func (r *syncReconciler) getCABundleSources() []clusterregistryv1alpha1.CABundleSource {
+	return getCABundleSources(r.getRule().Spec)
+}
+
+// getCABundleSources returns the local Secrets and ConfigMaps the webhook mutations of the spec read CA bundles from
+func getCABundleSources(spec clusterregistryv1alpha1.ResourceSyncRuleSpec) []clusterregistryv1alpha1.CABundleSource {
+	var sources []clusterregistryv1alpha1.CABundleSource
+	for _, rule := range spec.Rules {
+		if rule.Mutations.Webhooks != nil && rule.Mutations.Webhooks.CABundleFrom != nil {
+			sources = append(sources, *rule.Mutations.Webhooks.CABundleFrom)
+		}
+	}
+
+	return sources
+}
+
+// caBundleResolver returns the resolver of the CA bundles from the local cache, the objects read are watched by
+// initCABundleInformers
+func (r *syncReconciler) caBundleResolver(ctx context.Context) objectsync.CABundleResolver {
+	return func(src clusterregistryv1alpha1.CABundleSource) ([]byte, error) {
+		key := types.NamespacedName{Namespace: src.Namespace, Name: src.Name}
+		details := []interface{}{"kind", src.Kind, "name", key.String(), "key", src.GetKey()}
+
+		var bundle []byte
+		switch src.Kind {
+		case clusterregistryv1alpha1.CABundleSourceKindConfigMap:
+			configMap := &corev1.ConfigMap{}
+			err := r.localClient.Get(ctx, key, configMap)
+			if apierrors.IsNotFound(err) {
+				return nil, errors.WithDetails(util.ErrCABundleNotFound, details...)
+			}
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not get CA bundle", details...)
+			}
+			bundle = []byte(configMap.Data[src.GetKey()])
+		default:
+			secret := &corev1.Secret{}
+			err := r.localClient.Get(ctx, key, secret)
+			if apierrors.IsNotFound(err) {
+				return nil, errors.WithDetails(util.ErrCABundleNotFound, details...)
+			}
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not get CA bundle", details...)
+			}
+			bundle = secret.Data[src.GetKey()]
+		}
+
+		if len(bundle) == 0 {
+			return nil, errors.WithDetails(util.ErrCABundleNotFound, details...)
+		}
+
+		return bundle, nil
+	}
+}
+
+// initCABundleInformers watches the local Secrets and ConfigMaps the CA bundles are read from, the source objects of
+// the rule are enqueued when any of them changes
+func (r *syncReconciler) initCABundleInformers(ctx context.Context) error {
+	sources := r.getCABundleSources()
+	if len(sources) == 0 {
+		return nil
+	}
+
+	r.setupMu.Lock()
+	defer r.setupMu.Unlock()
+
+	for _, obj := range []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}} {
+		kind := clusterregistryv1alpha1.CABundleSourceKindSecret
+		if _, ok := obj.(*corev1.ConfigMap); ok {
+			kind = clusterregistryv1alpha1.CABundleSourceKindConfigMap
+		}
+
+		watched := false
+		for _, src := range sources {
+			watched = watched || src.Kind == kind
+		}
+		key := "local-ca-bundle/" + string(kind)
+		if _, ok := r.watches[key]; ok || !watched {
+			continue
+		}
+
+		informer, err := r.localCache.GetInformer(ctx, obj)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "could not create local informer for CA bundles", "kind", kind)
+		}
+
+		if err := r.watch(r.ctrl, key, &source.Informer{
+			Informer: informer,
+		}, handler.EnqueueRequestsFromMapFunc(r.getCABundleRequests(ctx, kind))); err != nil {
+			return errors.WrapIfWithDetails(err, "could not create watch for CA bundles", "kind", kind)
+		}
+	}
+
+	return nil
+}
+
+// getCABundleRequests returns the map func of the CA bundle watches, every matching source object is enqueued when a
+// CA bundle source of the rule changes
+func (r *syncReconciler) getCABundleRequests(ctx context.Context, kind clusterregistryv1alpha1.CABundleSourceKind) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		referenced := false
+		for _, src := range r.getCABundleSources() {
+			referenced = referenced || src.Kind == kind && src.Namespace == obj.GetNamespace() && src.Name == obj.GetName()
+		}
+		if !referenced {
+			return nil
+		}
+
+		sourceObjects, err := r.listObjects(ctx, r.getSourceClient(), r.GetSourceGVK())
+		if err != nil {
+			r.GetLogger().Error(err, "could not list source objects after the CA bundle changed")
+
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for _, sourceObj := range sourceObjects {
+			if ok, _, err := r.getRule().Match(sourceObj); ok && err == nil {
+				reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(sourceObj)})
+			}
+		}
+
+		return reqs
+	}
+}
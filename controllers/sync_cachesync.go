@@ -0,0 +1,93 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+// cacheSyncProgressInterval is the interval at which the number of source objects listed by the warming up cache is
+// reported
+const cacheSyncProgressInterval = time.Second * 10
+
+// reportCacheSyncing sets the CacheSyncing condition of the rule with the number of source objects listed so far,
+// while the cache of the source cluster lists them page by page, until the last page is received or the context is
+// done
+func (r *syncReconciler) reportCacheSyncing(ctx context.Context) {
+	if r.listPager == nil {
+		return
+	}
+
+	gvk := r.GetSourceGVK()
+	mapping, err := r.GetClient().RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		r.GetLogger().Error(err, "could not get the resource of the source kind, the warm-up of the cache is not reported")
+
+		return
+	}
+	resource := mapping.Resource.GroupResource()
+
+	ticker := time.NewTicker(cacheSyncProgressInterval)
+	defer ticker.Stop()
+
+	reported := int64(-1)
+	for {
+		progress := r.listPager.Progress(resource)
+		if progress.Done || progress.Listed != reported {
+			err := r.setClusterCondition(ctx, r.getCacheSyncingCondition(progress))
+			switch {
+			case err != nil:
+				r.GetLogger().Error(err, "could not update cache sync progress")
+			case progress.Done:
+				return
+			default:
+				reported = progress.Listed
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *syncReconciler) getCacheSyncingCondition(progress clusters.ListProgress) metav1.Condition {
+	if progress.Done {
+		return metav1.Condition{
+			Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeCacheSyncing,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: r.getRule().GetGeneration(),
+			Reason:             "CacheSynced",
+			Message:            fmt.Sprintf("%d source objects are listed", progress.Listed),
+		}
+	}
+
+	return metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeCacheSyncing,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "ListingObjects",
+		Message:            fmt.Sprintf("%d source objects are listed so far", progress.Listed),
+	}
+}
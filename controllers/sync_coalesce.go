@@ -0,0 +1,112 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// heldWrite is the local write of an object held until the write coalescing window of the rule closes
+type heldWrite struct {
+	windowEnd time.Time
+	// contentHash is the hash of the latest desired state of the object seen within the window
+	contentHash string
+}
+
+// getWriteCoalesceWindow returns the time the writes of the changed objects are held for, zero if they are written
+// right away
+func (r *syncReconciler) getWriteCoalesceWindow() time.Duration {
+	if window := r.getRule().Spec.WriteCoalesceWindow; window != nil && window.Duration > 0 {
+		return window.Duration
+	}
+
+	return 0
+}
+
+// holdWrite holds the write of the changed desired state of the source until the write coalescing window of the rule
+// closes, the changes arriving meanwhile only replace the held state. It returns the time after which the source is
+// reconciled again to write its latest desired state, and whether the write is held; it is not once the window of the
+// source closed. The reconciles of a source are not concurrent, so the held write is never written twice.
+func (r *syncReconciler) holdWrite(source types.NamespacedName, contentHash string, now time.Time) (time.Duration, bool) {
+	window := r.getWriteCoalesceWindow()
+	if window <= 0 {
+		return 0, false
+	}
+
+	r.heldWritesMu.Lock()
+	held, ok := r.heldWrites[source]
+	coalesced := false
+	switch {
+	case !ok:
+		held = heldWrite{
+			windowEnd:   now.Add(window),
+			contentHash: contentHash,
+		}
+		r.heldWrites[source] = held
+	case now.Before(held.windowEnd):
+		// the previous desired state is superseded before it was written
+		if held.contentHash != contentHash {
+			held.contentHash = contentHash
+			r.heldWrites[source] = held
+			coalesced = true
+		}
+	default:
+		delete(r.heldWrites, source)
+	}
+	count := len(r.heldWrites)
+	r.heldWritesMu.Unlock()
+
+	syncHeldWrites.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(count))
+	if coalesced {
+		syncCoalescedWritesTotal.WithLabelValues(r.getRule().GetName(), r.clusterID).Inc()
+	}
+
+	if ok && !now.Before(held.windowEnd) {
+		return 0, false
+	}
+
+	return held.windowEnd.Sub(now), true
+}
+
+// releaseHeldWrite forgets the write held for the source without writing it, e.g. because the desired state is back
+// to the synced one, it returns whether a write was held
+func (r *syncReconciler) releaseHeldWrite(source types.NamespacedName) bool {
+	r.heldWritesMu.Lock()
+	_, ok := r.heldWrites[source]
+	delete(r.heldWrites, source)
+	count := len(r.heldWrites)
+	r.heldWritesMu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	// the held write is saved entirely
+	syncHeldWrites.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(count))
+	syncCoalescedWritesTotal.WithLabelValues(r.getRule().GetName(), r.clusterID).Inc()
+
+	return true
+}
+
+// flushHeldWrite ends the write coalescing window of the source before the objects synced from it are deleted. The
+// held desired state is superseded by the deletion, so it is dropped instead of being written after it.
+func (r *syncReconciler) flushHeldWrite(source types.NamespacedName, log logr.Logger) {
+	if r.releaseHeldWrite(source) {
+		log.V(1).Info("held write is dropped, the object is not synced anymore")
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// convergenceCheckInterval is the interval at which a started reconciler checks whether it reconciled the objects
+// listed from the source cluster
+const convergenceCheckInterval = time.Second
+
+// IsConverged returns whether every object listed from the source cluster was reconciled at least once since the
+// reconciler started. The failed objects retried with backoff do not hold it back.
+func (r *syncReconciler) IsConverged() bool {
+	r.convergeMu.Lock()
+	defer r.convergeMu.Unlock()
+
+	return r.converged
+}
+
+// startReconcile counts the running reconciles, which are exported as the active workers, the reconciler is not
+// converged while any of them runs
+func (r *syncReconciler) startReconcile() func() {
+	r.convergeMu.Lock()
+	r.reconciling++
+	syncActiveWorkers.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(r.reconciling))
+	r.convergeMu.Unlock()
+
+	return func() {
+		r.convergeMu.Lock()
+		r.reconciling--
+		syncActiveWorkers.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(r.reconciling))
+		r.convergeMu.Unlock()
+	}
+}
+
+// waitForConvergence marks the reconciler converged once its queue is drained, and records the generation of the rule
+// the objects were synced by. The source objects are listed first, which waits for the informer of the source cluster
+// to sync, and the queue has to be found idle twice in a row, since the listed objects are added to the queue
+// asynchronously. The objects delayed by the startup window are not in the queue yet, so the reconciler is not
+// converged until the window passes.
+func (r *syncReconciler) waitForConvergence(ctx context.Context, generation int64) {
+
+	listed := false
+	idle := 0
+	err := wait.PollImmediateUntil(convergenceCheckInterval, func() (bool, error) {
+		if r.queue == nil {
+			return false, nil
+		}
+
+		if !listed {
+			if _, err := r.listObjects(ctx, r.getSourceClient(), r.GetSourceGVK()); err != nil {
+				r.GetLogger().V(1).Info("could not list source objects", "error", err.Error())
+
+				return false, nil
+			}
+			listed = true
+		}
+
+		r.convergeMu.Lock()
+		// the tracking is stopped when the rule is updated, it must not mark the reconciler converged afterwards
+		if ctx.Err() != nil {
+			r.convergeMu.Unlock()
+
+			return false, ctx.Err()
+		}
+		if r.queue.Len() == 0 && r.reconciling == 0 && !time.Now().Before(r.startupDeadline) {
+			idle++
+		} else {
+			idle = 0
+		}
+		r.converged = idle > 1
+		r.convergeMu.Unlock()
+
+		return idle > 1, nil
+	}, ctx.Done())
+	if err != nil {
+		return
+	}
+
+	r.GetLogger().Info("objects of the source cluster are synced", "generation", generation)
+
+	if err := r.setObservedGeneration(ctx, generation); err != nil {
+		r.GetLogger().Error(err, "could not record observed generation")
+	}
+
+	// every object synced before a restart is counted again by now
+	r.objectQuota.ClearRestored(r.getRule().GetName())
+
+	if r.onConverged != nil {
+		r.onConverged()
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// crdWaitInterval is the interval at which the objects are retried while the local kind is not served, the CRD watch
+// resumes them right away once the CRD is established
+const crdWaitInterval = time.Minute
+
+var customResourceDefinitionGVK = schema.GroupVersionKind{
+	Group:   "apiextensions.k8s.io",
+	Version: "v1",
+	Kind:    "CustomResourceDefinition",
+}
+
+// isMissingKindError returns whether the error is caused by a kind not served by the cluster
+func isMissingKindError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if meta.IsNoMatchError(errors.Cause(err)) {
+		return true
+	}
+
+	// the API server responds without a status to the requests of unknown resources
+	return apierrors.IsNotFound(err) && strings.Contains(err.Error(), "the server could not find the requested resource")
+}
+
+// refreshRESTMappers refreshes the mappers of the source and the local cluster if they do not know the synced kinds
+func (r *syncReconciler) refreshRESTMappers() error {
+	if err := warmRESTMapper(r.GetManager().GetRESTMapper(), r.GetSourceGVK()); err != nil {
+		return errors.WrapIf(err, "could not look up source kind")
+	}
+
+	localGVK := r.getLocalGVK()
+
+	return errors.WrapIf(warmRESTMapper(r.localMgr.GetRESTMapper(), localGVK), "could not look up local kind")
+}
+
+// isLocalKindKnown returns whether the local kind is known by the mapper of the local cluster
+func (r *syncReconciler) isLocalKindKnown() bool {
+	localGVK := r.getLocalGVK()
+
+	_, err := r.localMgr.GetRESTMapper().RESTMapping(localGVK.GroupKind(), localGVK.Version)
+
+	return err == nil
+}
+
+func (r *syncReconciler) isWaitingForCRD() bool {
+	r.crdMu.Lock()
+	defer r.crdMu.Unlock()
+
+	return r.waitingForCRD
+}
+
+// isLocalKindServed returns whether the objects can be synced, while the rule waits for the CRD of the local kind it
+// is checked at most once per crdWaitInterval
+func (r *syncReconciler) isLocalKindServed(ctx context.Context) bool {
+	r.crdMu.Lock()
+	if !r.waitingForCRD {
+		r.crdMu.Unlock()
+
+		return true
+	}
+	if time.Since(r.lastCRDCheck) < crdWaitInterval {
+		r.crdMu.Unlock()
+
+		return false
+	}
+	r.lastCRDCheck = time.Now()
+	r.crdMu.Unlock()
+
+	if err := r.resumeLocalKind(ctx); err != nil {
+		r.GetLogger().V(1).Info("local kind is still not served", "error", err.Error())
+
+		return false
+	}
+
+	return true
+}
+
+// waitForCRD parks the object until the local kind is served, the first parked object reports the rule waiting for
+// the CRD
+func (r *syncReconciler) waitForCRD(ctx context.Context, key types.NamespacedName) (ctrl.Result, error) {
+	r.crdMu.Lock()
+	waiting := r.waitingForCRD
+	if !waiting {
+		r.waitingForCRD = true
+		r.lastCRDCheck = time.Now()
+	}
+	r.crdWaitingObjects[key] = struct{}{}
+	r.crdMu.Unlock()
+
+	if !waiting {
+		r.reportWaitingForCRD(ctx)
+	}
+
+	return ctrl.Result{
+		RequeueAfter: crdWaitInterval,
+	}, nil
+}
+
+func (r *syncReconciler) reportWaitingForCRD(ctx context.Context) {
+	msg := fmt.Sprintf("local kind %s is not served, objects are synced once its CRD is established", r.getLocalGVK())
+	r.recordEvent(corev1.EventTypeWarning, "WaitingForCRD", msg)
+	r.GetLogger().Info(msg)
+
+	if err := r.setClusterCondition(ctx, r.getWaitingForCRDCondition(true)); err != nil {
+		r.GetLogger().Error(err, "could not update rule condition")
+	}
+
+	if err := r.watchCRDs(ctx); err != nil {
+		r.GetLogger().Error(err, "could not watch CRDs, objects are retried periodically")
+	}
+}
+
+// resumeLocalKind syncs the objects waiting for the CRD of the local kind, if the local kind is served already
+func (r *syncReconciler) resumeLocalKind(ctx context.Context) error {
+	if !r.isWaitingForCRD() {
+		return nil
+	}
+
+	// the established CRD could serve another version than the one the objects are synced as
+	if err := r.resolveLocalVersionFallback(ctx); err != nil {
+		return err
+	}
+
+	localGVK := r.getLocalGVK()
+
+	if err := warmRESTMapper(r.localMgr.GetRESTMapper(), localGVK); err != nil {
+		return errors.WrapIf(err, "could not look up local kind")
+	}
+
+	clusterScoped, err := util.IsClusterScoped(r.localMgr.GetRESTMapper(), localGVK)
+	if err != nil {
+		return errors.WrapIf(err, "could not look up scope of local kind")
+	}
+	r.setLocalClusterScoped(clusterScoped)
+
+	if err := r.initLocalInformer(ctx, r.initObjectFromGVK(localGVK)); err != nil {
+		return err
+	}
+
+	r.crdMu.Lock()
+	keys := r.crdWaitingObjects
+	r.crdWaitingObjects = make(map[types.NamespacedName]struct{})
+	r.waitingForCRD = false
+	r.crdMu.Unlock()
+
+	r.GetLogger().Info("local kind is served, resuming objects", "gvk", localGVK, "objects", len(keys))
+
+	if err := r.setClusterCondition(ctx, r.getWaitingForCRDCondition(false)); err != nil {
+		r.GetLogger().Error(err, "could not update rule condition")
+	}
+
+	if queue := r.queue; queue != nil {
+		for key := range keys {
+			queue.Add(reconcile.Request{NamespacedName: key})
+		}
+	}
+
+	return nil
+}
+
+// watchCRDs resumes the objects waiting for the local kind as soon as its CRD is established
+func (r *syncReconciler) watchCRDs(ctx context.Context) error {
+	r.setupMu.Lock()
+	defer r.setupMu.Unlock()
+
+	key := "local/" + customResourceDefinitionGVK.String()
+	if _, ok := r.watches[key]; ok || r.ctrl == nil || r.localCache == nil {
+		return nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(customResourceDefinitionGVK)
+
+	informer, err := r.localCache.GetInformer(ctx, obj)
+	if err != nil {
+		return errors.WrapIf(err, "could not create local informer for CRDs")
+	}
+
+	resume := func(obj client.Object) {
+		if !r.isLocalKindCRDEstablished(obj) {
+			return
+		}
+
+		if err := r.resumeLocalKind(ctx); err != nil {
+			r.GetLogger().Error(err, "could not resume objects waiting for CRD")
+		}
+	}
+
+	return errors.WrapIf(r.watch(r.ctrl, key, &source.Informer{
+		Informer: informer,
+	}, handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, _ workqueue.RateLimitingInterface) {
+			resume(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent, _ workqueue.RateLimitingInterface) {
+			resume(e.ObjectNew)
+		},
+	}), "could not create watch for CRDs")
+}
+
+// isLocalKindCRDEstablished returns whether the object is the established CRD of the local kind
+func (r *syncReconciler) isLocalKindCRDEstablished(obj client.Object) bool {
+	crd, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+
+	localGVK := r.getLocalGVK()
+
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	if group != localGVK.Group || kind != localGVK.Kind {
+		return false
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Established" && condition["status"] == string(metav1.ConditionTrue) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *syncReconciler) getWaitingForCRDCondition(waiting bool) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeWaitingForCRD,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "KindServed",
+		Message:            "the synced kind is served by the local cluster",
+	}
+
+	if waiting {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "KindNotServed"
+		condition.Message = fmt.Sprintf("local kind %s is not served, objects are synced once its CRD is established", r.getLocalGVK())
+	}
+
+	return condition
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// getDeleteOptions returns the options of the deletes of the synced objects
+func (r *syncReconciler) getDeleteOptions() []client.DeleteOption {
+	var opts []client.DeleteOption
+	if policy := r.getRule().Spec.DeletionPropagation; policy != nil {
+		opts = append(opts, client.PropagationPolicy(*policy))
+	}
+
+	return opts
+}
+
+// delayDeletion returns the result requeueing the source while the deletion of the objects synced from it is
+// delayed, and whether it is delayed. The delay starts when the source is first found missing.
+func (r *syncReconciler) delayDeletion(source types.NamespacedName) (ctrl.Result, bool) {
+	if r.getRule().Spec.DeleteAfter == nil || r.getRule().Spec.DeleteAfter.Duration <= 0 {
+		return ctrl.Result{}, false
+	}
+
+	r.deletionMu.Lock()
+	defer r.deletionMu.Unlock()
+
+	since, ok := r.pendingDeletions[source]
+	if !ok {
+		since = time.Now()
+		r.pendingDeletions[source] = since
+	}
+
+	remaining := r.getRule().Spec.DeleteAfter.Duration - time.Since(since)
+	if remaining <= 0 {
+		delete(r.pendingDeletions, source)
+
+		return ctrl.Result{}, false
+	}
+
+	return ctrl.Result{RequeueAfter: remaining}, true
+}
+
+// cancelDeletion forgets the delayed deletion of the objects synced from the source, e.g. because it is recreated
+func (r *syncReconciler) cancelDeletion(source types.NamespacedName) {
+	r.deletionMu.Lock()
+	defer r.deletionMu.Unlock()
+
+	delete(r.pendingDeletions, source)
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// getDriftPolicy returns what is done with the local changes of the synced objects
+func (r *syncReconciler) getDriftPolicy() clusterregistryv1alpha1.DriftPolicy {
+	if policy := r.getRule().Spec.DriftPolicy; policy != "" {
+		return policy
+	}
+
+	return clusterregistryv1alpha1.DriftPolicyRepair
+}
+
+// startLocalWrite marks the source as being written, the updates of the objects synced from it are not drift until
+// the write is recorded
+func (r *syncReconciler) startLocalWrite(source types.NamespacedName) {
+	r.syncedMu.Lock()
+	defer r.syncedMu.Unlock()
+
+	r.writingSources[source] = struct{}{}
+}
+
+func (r *syncReconciler) endLocalWrite(source types.NamespacedName) {
+	r.syncedMu.Lock()
+	defer r.syncedMu.Unlock()
+
+	delete(r.writingSources, source)
+}
+
+// onLocalUpdate checks whether the update of a synced object was made by a local actor instead of the sync
+// controller, and reports it as drift unless the drift policy ignores it. It returns whether the source is reconciled
+// to repair the object.
+func (r *syncReconciler) onLocalUpdate(oldObj, newObj client.Object) bool {
+	policy := r.getDriftPolicy()
+
+	managers, drifted := r.getLocalDrift(oldObj, newObj)
+	if !drifted {
+		return true
+	}
+	if policy == clusterregistryv1alpha1.DriftPolicyIgnore {
+		return false
+	}
+
+	r.recordDrift(newObj, managers)
+
+	return policy == clusterregistryv1alpha1.DriftPolicyRepair
+}
+
+// getLocalDrift returns the field managers which changed the synced object since it was last written by the sync
+// controller, and whether the update is drift. Only the changes of the generation, the metadata and, for the kinds
+// without a generation, the content are drift, so the status updates of the local controllers are not.
+func (r *syncReconciler) getLocalDrift(oldObj, newObj client.Object) ([]string, bool) {
+	if oldObj.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation] != r.clusterID || util.GetOwnerRule(oldObj) != r.getRule().GetName() {
+		return nil, false
+	}
+
+	source := util.GetSourceObjectKey(newObj)
+	r.syncedMu.Lock()
+	synced, ok := r.syncedVersions[source]
+	_, writing := r.writingSources[source]
+	r.syncedMu.Unlock()
+	if !ok || writing || synced.local != client.ObjectKeyFromObject(newObj) || newObj.GetResourceVersion() == synced.localResourceVersion {
+		return nil, false
+	}
+
+	metadataHash, err := util.MetadataHash(newObj)
+	changed := err != nil || metadataHash != synced.localMetadataHash || newObj.GetGeneration() != synced.localGeneration
+	if !changed && newObj.GetGeneration() == 0 {
+		changed = !hasEqualContent(oldObj, newObj)
+	}
+	if !changed {
+		return nil, false
+	}
+
+	// the writes of the sync controller which were read back from the cache before the update arrived are not drift
+	self := util.FieldManagerFromUserAgent(r.getLocalWriteConfig().UserAgent)
+	managers := make([]string, 0)
+	for _, manager := range util.ChangedFieldManagers(oldObj, newObj) {
+		if manager != self {
+			managers = append(managers, manager)
+		}
+	}
+
+	return managers, len(managers) > 0
+}
+
+func (r *syncReconciler) recordDrift(obj client.Object, managers []string) {
+	local := client.ObjectKeyFromObject(obj)
+	source := util.GetSourceObjectKey(obj)
+	msg := fmt.Sprintf("synced object was changed locally by %s", strings.Join(managers, ", "))
+
+	syncDriftDetectedTotal.WithLabelValues(r.getRule().GetName(), r.clusterID).Inc()
+	if r.recordsRuleEvents() {
+		r.recordEvent(corev1.EventTypeWarning, "SyncDriftDetected", fmt.Sprintf("%s (resource: %s, localResource: %s)", msg, source, local))
+	}
+	if r.recordsObjectEvents() {
+		r.objectRecorder.Event(obj, corev1.EventTypeWarning, "SyncDriftDetected", msg)
+	}
+	r.GetLogger().Info(msg, "resource", source.String(), "localResource", local.String(), "managers", managers, "driftPolicy", r.getDriftPolicy())
+}
+
+// hasEqualContent returns whether the objects are the same apart from their status and the fields set by the API
+// server
+func hasEqualContent(oldObj, newObj client.Object) bool {
+	oldHash, oldErr := contentHashWithoutStatus(oldObj)
+	newHash, newErr := contentHashWithoutStatus(newObj)
+
+	return oldErr == nil && newErr == nil && oldHash == newHash
+}
+
+func contentHashWithoutStatus(obj client.Object) (string, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", err
+	}
+	u := &unstructured.Unstructured{Object: runtime.DeepCopyJSON(content)}
+	unstructured.RemoveNestedField(u.Object, "status")
+
+	return util.ContentHash(u)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+const (
+	// ruleEventsComponent is the source component of the events recorded on the rules
+	ruleEventsComponent = "cluster-controller"
+	// objectEventsComponent is the source component of the events recorded on the synced objects. The recorders of
+	// both components share the event broadcaster of the manager, so their events are aggregated and rate limited the
+	// same way.
+	objectEventsComponent = "cluster-registry-sync"
+)
+
+// recordsRuleEvents returns whether the events of the synced objects are recorded on the rule
+func (r *syncReconciler) recordsRuleEvents() bool {
+	return r.getRule().Spec.EventTarget != clusterregistryv1alpha1.EventTargetObject
+}
+
+// recordsObjectEvents returns whether the events of the synced objects are recorded on the synced objects
+func (r *syncReconciler) recordsObjectEvents() bool {
+	return r.getRule().Spec.EventTarget == clusterregistryv1alpha1.EventTargetObject ||
+		r.getRule().Spec.EventTarget == clusterregistryv1alpha1.EventTargetBoth
+}
+
+// recordSyncedEvent records the successful sync of the object on the rule and on the synced object, as the rule
+// configures it
+func (r *syncReconciler) recordSyncedEvent(req ctrl.Request, obj client.Object) {
+	if r.recordsRuleEvents() {
+		r.recordEvent(corev1.EventTypeNormal, "ObjectReconciled", fmt.Sprintf("object reconciled (resource: %s)", req))
+	}
+	if r.recordsObjectEvents() {
+		r.objectRecorder.Event(obj, corev1.EventTypeNormal, "ObjectSynced", fmt.Sprintf("object synced from cluster %s by rule %s (resource: %s)", r.clusterName, r.getRule().GetName(), req))
+	}
+}
+
+// recordSyncFailedEvent records the failed sync of the object on the rule and on the objects synced from the source,
+// as the rule configures it. The event is recorded on the rule if there is no synced object yet, e.g. it could not be
+// created.
+func (r *syncReconciler) recordSyncFailedEvent(ctx context.Context, req ctrl.Request, reason, message string, err error) {
+	recorded := false
+	if r.recordsObjectEvents() {
+		objects, listErr := r.getSyncedObjects(ctx, req.NamespacedName, r.getLocalGVK())
+		if listErr != nil {
+			r.GetLogger().V(1).Info("could not get synced objects to record event on", "resource", req, "error", listErr.Error())
+		}
+		for _, obj := range objects {
+			r.objectRecorder.Event(obj, corev1.EventTypeWarning, "ObjectSyncFailed", fmt.Sprintf("could not sync object from cluster %s by rule %s (resource: %s): %s", r.clusterName, r.getRule().GetName(), req, err.Error()))
+			recorded = true
+		}
+	}
+
+	if r.recordsRuleEvents() || !recorded {
+		r.recordEvent(corev1.EventTypeWarning, reason, message)
+	}
+}
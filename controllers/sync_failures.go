@@ -0,0 +1,125 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// maxListedFailedObjects limits the failed objects listed in the status of the rule, the rest is only counted
+const maxListedFailedObjects = 20
+
+// parkFailedObject stops retrying an object which failed to sync too many times in a row, until its source changes.
+// It returns whether the object is parked.
+func (r *syncReconciler) parkFailedObject(ctx context.Context, req ctrl.Request, reconcileErr error) bool {
+	source := r.initObjectFromGVK(r.GetSourceGVK())
+	// deleted objects are not parked, the deletion is synced, e.g. retried until the preserved finalizers are removed
+	if err := r.getSourceReader().Get(ctx, req.NamespacedName, source); err != nil || !source.GetDeletionTimestamp().IsZero() {
+		return false
+	}
+
+	failed := r.failures.Park(req.NamespacedName, source.GetResourceVersion(), time.Now())
+	r.recordEvent(corev1.EventTypeWarning, "ObjectSyncFailed", fmt.Sprintf("object failed to sync %d times in a row, it is not retried until its source changes (resource: %s): %s", failed.Failures, req, reconcileErr))
+	r.GetLogger().Info("object failed to sync too many times, not retried until its source changes", "resource", req.NamespacedName, "failures", failed.Failures)
+
+	if err := r.setFailedObjectsStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not update failed objects")
+	}
+
+	return true
+}
+
+// isFailedObjectParked returns whether the object is parked and its source is unchanged since, parked objects with a
+// changed source are retried
+func (r *syncReconciler) isFailedObjectParked(ctx context.Context, key types.NamespacedName, source metav1.Object) bool {
+	if r.failures.IsParked(key, source.GetResourceVersion()) {
+		return true
+	}
+
+	r.unparkFailedObject(ctx, key)
+
+	return false
+}
+
+func (r *syncReconciler) unparkFailedObject(ctx context.Context, key types.NamespacedName) {
+	if !r.failures.Unpark(key) {
+		return
+	}
+
+	if err := r.setFailedObjectsStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not update failed objects")
+	}
+}
+
+func (r *syncReconciler) setFailedObjectsStatus(ctx context.Context) error {
+	failed := r.failures.List()
+	syncFailedObjects.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(len(failed)))
+
+	if r.getRule().GetUID() == "" {
+		return nil
+	}
+
+	apiVersion, kind := r.GetSourceGVK().ToAPIVersionAndKind()
+	objects := make([]clusterregistryv1alpha1.FailedObject, 0, maxListedFailedObjects)
+	for i, f := range failed {
+		if i == maxListedFailedObjects {
+			break
+		}
+		objects = append(objects, clusterregistryv1alpha1.FailedObject{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Name:       f.Key.Name,
+			Namespace:  f.Key.Namespace,
+			Error:      f.LastError,
+			Failures:   f.Failures,
+			// the API server stores the time with second precision
+			Since: metav1.NewTime(f.Since.Truncate(time.Second)),
+		})
+	}
+
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeSyncFailed,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "NoFailedObjects",
+		Message:            "every object is retried",
+	}
+	if len(failed) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ObjectsFailed"
+		condition.Message = fmt.Sprintf("%d objects failed to sync too many times in a row, they are not retried until their source changes or a resync is requested", len(failed))
+	}
+
+	return errors.WrapIf(SetResourceSyncRuleClusterStatus(ctx, r.localMgr.GetClient(), r.getRule().GetName(), r.clusterName, func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus) {
+		status.FailedObjectCount = len(failed)
+		if len(objects) == 0 {
+			status.FailedObjects = nil
+		} else {
+			status.FailedObjects = objects
+		}
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}), "could not update rule status")
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// syncedVersion is the last synced resource version of a source object and the resource version of the object
+// written from it
+type syncedVersion struct {
+	sourceResourceVersion string
+	gvk                   schema.GroupVersionKind
+	local                 types.NamespacedName
+	localResourceVersion  string
+	localGeneration       int64
+	// localMetadataHash is the hash of the labels, annotations and ownerReferences of the written object, they can
+	// change without changing its generation
+	localMetadataHash string
+	// contentHash is the hash of the desired state the object was written with, and reconciledAt is the time of the
+	// last full reconcile of the object
+	contentHash  string
+	reconciledAt time.Time
+}
+
+// isUnchangedSinceSync returns whether neither the source object nor the object synced from it changed since the
+// last sync, so syncing it again would not change anything. Objects mutated by templates are always synced, the
+// templates can depend on more than the source object, and so are the objects with remapped owner references, which
+// depend on the local owners.
+func (r *syncReconciler) isUnchangedSinceSync(ctx context.Context, source client.Object, matchedRules clusterregistryv1alpha1.MatchedRules) bool {
+	if r.getRule().Spec.DisableSourceAnnotations || len(matchedRules.GetMutationOverrides()) > 0 || len(matchedRules.GetMutationJSONPatches()) > 0 ||
+		r.getRule().Spec.OwnerReferenceMode == clusterregistryv1alpha1.OwnerReferenceModeRemap {
+		return false
+	}
+
+	r.syncedMu.Lock()
+	synced, ok := r.syncedVersions[client.ObjectKeyFromObject(source)]
+	r.syncedMu.Unlock()
+	if !ok || synced.sourceResourceVersion != source.GetResourceVersion() {
+		return false
+	}
+
+	local := r.initObjectFromGVK(synced.gvk)
+	if err := r.localClient.Get(ctx, synced.local, local); err != nil {
+		return false
+	}
+
+	return local.GetResourceVersion() == synced.localResourceVersion &&
+		local.GetDeletionTimestamp().IsZero() &&
+		local.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation] == r.clusterID &&
+		util.GetOwnerRule(local) == r.getRule().GetName() &&
+		util.GetSourceResourceVersion(local) == source.GetResourceVersion()
+}
+
+// setSyncedVersion records the resource versions of the source object and the object synced from it
+func (r *syncReconciler) setSyncedVersion(source types.NamespacedName, sourceResourceVersion string, gvk schema.GroupVersionKind, local client.Object, contentHash string) {
+	// an object without a metadata hash is never considered unchanged by its content hash
+	metadataHash, _ := util.MetadataHash(local)
+
+	r.syncedMu.Lock()
+	defer r.syncedMu.Unlock()
+
+	r.syncedVersions[source] = syncedVersion{
+		sourceResourceVersion: sourceResourceVersion,
+		gvk:                   gvk,
+		local:                 client.ObjectKeyFromObject(local),
+		localResourceVersion:  local.GetResourceVersion(),
+		localGeneration:       local.GetGeneration(),
+		localMetadataHash:     metadataHash,
+		contentHash:           contentHash,
+		reconciledAt:          time.Now(),
+	}
+}
+
+// isContentUnchanged returns whether the desired state of the object has the same hash as the one it was last
+// written with, and the local object was not changed since, so writing it again would not change anything. Unlike
+// isUnchangedSinceSync, it holds when only the source object changed without changing the desired state, e.g. the
+// status of the source was updated while the status is not synced. The objects are reconciled in full at least once
+// every full reconcile interval, so a tampered content hash annotation is corrected. Objects with remapped owner
+// references are always written, their owners can change locally.
+func (r *syncReconciler) isContentUnchanged(ctx context.Context, source types.NamespacedName, desired client.Object, hash string) bool {
+	if r.fullReconcileInterval <= 0 || r.getRule().Spec.AnchorOwnership || r.getRule().Spec.OwnerReferenceMode == clusterregistryv1alpha1.OwnerReferenceModeRemap {
+		return false
+	}
+
+	r.syncedMu.Lock()
+	synced, ok := r.syncedVersions[source]
+	r.syncedMu.Unlock()
+	// the objects written before a restart are compared with the content hash they were written with until the
+	// listed objects are reconciled, so the unchanged ones do not take write slots during the warm-up
+	if !ok && !r.IsConverged() {
+		unchanged := r.isWrittenWithContentHash(ctx, desired, hash)
+		r.observeContentHashCheck(unchanged)
+
+		return unchanged
+	}
+	if !ok || synced.contentHash != hash || synced.local != client.ObjectKeyFromObject(desired) || time.Since(synced.reconciledAt) > r.fullReconcileInterval {
+		r.observeContentHashCheck(false)
+
+		return false
+	}
+
+	local := r.initObjectFromGVK(synced.gvk)
+	if err := r.localClient.Get(ctx, synced.local, local); err != nil {
+		r.observeContentHashCheck(false)
+
+		return false
+	}
+
+	// the generation of the kinds without one, e.g. ConfigMaps, never changes, every change of them is detected by
+	// their resource version. The local changes of the metadata do not change the generation either, so they are
+	// detected by its hash.
+	metadataHash, err := util.MetadataHash(local)
+	unchanged := err == nil && synced.localMetadataHash != "" && metadataHash == synced.localMetadataHash &&
+		local.GetGeneration() == synced.localGeneration &&
+		(local.GetGeneration() != 0 || local.GetResourceVersion() == synced.localResourceVersion) &&
+		util.GetContentHash(local) == hash &&
+		local.GetDeletionTimestamp().IsZero() &&
+		local.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation] == r.clusterID &&
+		util.GetOwnerRule(local) == r.getRule().GetName()
+	r.observeContentHashCheck(unchanged)
+
+	return unchanged
+}
+
+// isWrittenWithContentHash returns whether the local object was written by the rule with the desired state of the
+// hash. Unlike isContentUnchanged, it can not detect the local changes which kept the content hash annotation.
+func (r *syncReconciler) isWrittenWithContentHash(ctx context.Context, desired client.Object, hash string) bool {
+	local := r.initObjectFromGVK(r.getLocalGVK())
+	if err := r.localClient.Get(ctx, client.ObjectKeyFromObject(desired), local); err != nil {
+		return false
+	}
+
+	return util.GetContentHash(local) == hash &&
+		local.GetDeletionTimestamp().IsZero() &&
+		local.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation] == r.clusterID &&
+		util.GetOwnerRule(local) == r.getRule().GetName()
+}
+
+func (r *syncReconciler) observeContentHashCheck(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	syncContentHashChecksTotal.WithLabelValues(r.getRule().GetName(), r.clusterID, result).Inc()
+}
+
+func (r *syncReconciler) forgetSyncedVersion(source types.NamespacedName) {
+	// the write held for an object which is not synced anymore is never written
+	r.releaseHeldWrite(source)
+
+	r.syncedMu.Lock()
+	defer r.syncedMu.Unlock()
+
+	delete(r.syncedVersions, source)
+}
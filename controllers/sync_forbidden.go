@@ -0,0 +1,137 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// forbiddenRequeueInterval is how often the objects parked after forbidden requests are retried, they are retried
+// right away once a write succeeds or the permissions of the rule are granted
+const forbiddenRequeueInterval = time.Minute * 10
+
+// forbiddenMessagePattern matches the verb and the resource in the message of the forbidden errors of the API server
+var forbiddenMessagePattern = regexp.MustCompile(`cannot (\S+) resource "([^"]+)"(?: in API group "([^"]*)")?`)
+
+// parkForbiddenObject parks the object, whose requests are forbidden, until the permissions of the rule are fixed
+// instead of retrying it with backoff, which would fail the same way. The missing permissions of the parked objects
+// are listed by the Forbidden condition of the rule.
+func (r *syncReconciler) parkForbiddenObject(ctx context.Context, req ctrl.Request, err error) ctrl.Result {
+	permission := getForbiddenPermission(err)
+
+	r.forbiddenMu.Lock()
+	added := true
+	for _, p := range r.forbiddenObjects {
+		if p == permission {
+			added = false
+
+			break
+		}
+	}
+	r.forbiddenObjects[req.NamespacedName] = permission
+	count := len(r.forbiddenObjects)
+	r.forbiddenMu.Unlock()
+
+	syncForbiddenErrorsTotal.WithLabelValues(r.getRule().GetName(), r.clusterID).Inc()
+	syncForbiddenObjects.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(count))
+
+	// the condition is only written again if the object is missing a permission it does not list yet
+	if !r.onWriteResult(ctx, err) && added {
+		r.setForbiddenCondition(ctx, err)
+	}
+
+	r.GetLogger().V(1).Info("request is forbidden, object is parked until the permissions are granted", "resource", req.NamespacedName, "permission", permission, "requeueAfter", forbiddenRequeueInterval)
+
+	return ctrl.Result{RequeueAfter: forbiddenRequeueInterval}
+}
+
+// releaseForbiddenObjects re-enqueues the objects parked after forbidden requests right away
+func (r *syncReconciler) releaseForbiddenObjects() {
+	r.forbiddenMu.Lock()
+	keys := make([]types.NamespacedName, 0, len(r.forbiddenObjects))
+	for key := range r.forbiddenObjects {
+		keys = append(keys, key)
+	}
+	r.forbiddenObjects = make(map[types.NamespacedName]string)
+	r.forbiddenMu.Unlock()
+
+	syncForbiddenObjects.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(0)
+
+	if len(keys) == 0 || r.queue == nil {
+		return
+	}
+
+	for _, key := range keys {
+		r.queue.Add(reconcile.Request{NamespacedName: key})
+	}
+
+	r.GetLogger().Info("objects parked after forbidden requests re-enqueued", "count", len(keys))
+}
+
+// getForbiddenPermissions returns the sorted missing permissions of the parked objects, it must be called with
+// forbiddenMu held
+func (r *syncReconciler) getForbiddenPermissions() []string {
+	permissions := make(map[string]struct{})
+	for _, permission := range r.forbiddenObjects {
+		permissions[permission] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(permissions))
+	for permission := range permissions {
+		sorted = append(sorted, permission)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// getForbiddenPermission returns the verb and the resource of the forbidden request from the error of the API server,
+// or the message of the error if they are not found in it
+func getForbiddenPermission(err error) string {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		return err.Error()
+	}
+
+	match := forbiddenMessagePattern.FindStringSubmatch(status.Status().Message)
+	if match == nil {
+		if details := status.Status().Details; details != nil && details.Kind != "" {
+			return fmt.Sprintf("access %s", strings.TrimSuffix(details.Kind+"."+details.Group, "."))
+		}
+
+		return status.Status().Message
+	}
+
+	resource, subresource, _ := strings.Cut(match[2], "/")
+
+	return formatResourceAttributes(authorizationv1.ResourceAttributes{
+		Verb:        match[1],
+		Group:       match[3],
+		Resource:    resource,
+		Subresource: subresource,
+	})
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// DefaultSyncFreshnessThreshold is the default longest time the oldest queued object of a sync controller can wait
+// before the SyncFresh condition of the rule turns false
+const DefaultSyncFreshnessThreshold = time.Minute
+
+// freshnessCheckInterval is the interval at which the age of the oldest queued object is compared with the freshness
+// threshold, the queue is checked without reconciles too, since a stalled controller does not reconcile
+const freshnessCheckInterval = time.Second * 10
+
+// observeSyncLag exports the time from the modification of the source object to the completion of the local write of
+// the object synced from it. The modification time set by the push side is used if the source has one, the time the
+// change was observed by the controller otherwise.
+func (r *syncReconciler) observeSyncLag(req ctrl.Request, source client.Object) {
+	modifiedAt, ok := util.GetSourceModifiedAt(source)
+	if !ok {
+		modifiedAt, ok = r.queueObserver.observedAt(req)
+	}
+	if !ok {
+		return
+	}
+
+	syncLag.WithLabelValues(r.getRule().GetName(), r.clusterID).Observe(time.Since(modifiedAt).Seconds())
+}
+
+// checkFreshness sets the SyncFresh condition of the rule whenever the oldest queued object starts or stops waiting
+// for longer than the freshness threshold, until the context is done
+func (r *syncReconciler) checkFreshness(ctx context.Context) {
+	if r.freshnessThreshold <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(freshnessCheckInterval)
+	defer ticker.Stop()
+
+	var reported *bool
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		age := r.queueObserver.oldestItemAge(time.Now())
+		fresh := age <= r.freshnessThreshold
+		if reported != nil && *reported == fresh {
+			continue
+		}
+
+		if err := r.setClusterCondition(ctx, r.getFreshnessCondition(fresh, age)); err != nil {
+			r.GetLogger().Error(err, "could not update sync freshness")
+
+			continue
+		}
+		reported = &fresh
+	}
+}
+
+func (r *syncReconciler) getFreshnessCondition(fresh bool, age time.Duration) metav1.Condition {
+	if fresh {
+		return metav1.Condition{
+			Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeSyncFresh,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: r.getRule().GetGeneration(),
+			Reason:             "QueueFresh",
+			Message:            fmt.Sprintf("queued objects wait for less than %s", r.freshnessThreshold),
+		}
+	}
+
+	return metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeSyncFresh,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "QueueStale",
+		Message:            fmt.Sprintf("the oldest queued object has been waiting for %s, longer than %s", age.Truncate(time.Second), r.freshnessThreshold),
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// ErrSyncHookFailed is returned if a hook of the sync reconciler failed, the object is retried like any failed object
+var ErrSyncHookFailed = errors.New("sync hook failed")
+
+// SyncHookInfo tells the hooks which object is synced by which rule from which cluster. The rule must not be modified.
+type SyncHookInfo struct {
+	Rule      *clusterregistryv1alpha1.ResourceSyncRule
+	ClusterID string
+	// Source is the key of the source object in the source cluster
+	Source types.NamespacedName
+}
+
+// SyncHook is a hook of the sync reconciler implementing one or more of PreMutateHook, PreWriteHook and PostSyncHook.
+//
+// The hooks are called by the workers of the sync controllers, concurrently for different objects, so they must be
+// safe for concurrent use. The hook interfaces are stable within a minor version of the module: the points they are
+// called at and their arguments are not changed, new points get new interfaces. The objects passed to the hooks are
+// the ones of the reconcile, they are typed objects for the kinds of the scheme of the manager and unstructured ones
+// otherwise.
+type SyncHook interface{}
+
+// PreMutateHook is called with the source object read from the source cluster before the mutations of the rule are
+// applied to it, changes of the object are synced as if they were made in the source cluster
+type PreMutateHook interface {
+	PreMutate(ctx context.Context, info SyncHookInfo, obj client.Object) error
+}
+
+// PreWriteHook is called with the mutated object right before it is written to the local cluster. Changes of the object
+// are written, but they are not part of the content hash of the object, so a hook producing a different object for
+// the same source object does not make the object written again while its source is unchanged.
+type PreWriteHook interface {
+	PreWrite(ctx context.Context, info SyncHookInfo, desired client.Object) error
+}
+
+// PostSyncHook is called after every reconcile of an object with its result, including the skipped and failed ones
+type PostSyncHook interface {
+	PostSync(ctx context.Context, info SyncHookInfo, result ctrl.Result, err error)
+}
+
+// WithHooks adds hooks to the sync reconciler, they are called in the order they are added
+func WithHooks(hooks ...SyncHook) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.hooks = append(r.hooks, hooks...)
+	}
+}
+
+func (r *syncReconciler) getHookInfo(source types.NamespacedName) SyncHookInfo {
+	return SyncHookInfo{
+		Rule:      r.getRule(),
+		ClusterID: r.clusterID,
+		Source:    source,
+	}
+}
+
+func (r *syncReconciler) runPreMutateHooks(ctx context.Context, source types.NamespacedName, obj client.Object) error {
+	for _, hook := range r.hooks {
+		if h, ok := hook.(PreMutateHook); ok {
+			if err := h.PreMutate(ctx, r.getHookInfo(source), obj); err != nil {
+				return errors.WithDetails(errors.WrapIf(ErrSyncHookFailed, err.Error()), "hook", fmt.Sprintf("%T", hook), "point", "pre-mutate")
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *syncReconciler) runPreWriteHooks(ctx context.Context, source types.NamespacedName, desired client.Object) error {
+	for _, hook := range r.hooks {
+		if h, ok := hook.(PreWriteHook); ok {
+			if err := h.PreWrite(ctx, r.getHookInfo(source), desired); err != nil {
+				return errors.WithDetails(errors.WrapIf(ErrSyncHookFailed, err.Error()), "hook", fmt.Sprintf("%T", hook), "point", "pre-write")
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *syncReconciler) runPostSyncHooks(ctx context.Context, source types.NamespacedName, result ctrl.Result, err error) {
+	for _, hook := range r.hooks {
+		if h, ok := hook.(PostSyncHook); ok {
+			h.PostSync(ctx, r.getHookInfo(source), result, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/tracing"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// getWriteUser returns the user the synced objects are written as on the local cluster, empty if the controller
+// writes them with its own identity
+func (r *syncReconciler) getWriteUser() string {
+	if r.getRule().Spec.ServiceAccountName == "" {
+		return ""
+	}
+
+	return util.ServiceAccountUsername(r.serviceAccountNamespace, r.getRule().Spec.ServiceAccountName)
+}
+
+// getWriteIdentity describes the identity the synced objects are written with for the events and conditions
+func (r *syncReconciler) getWriteIdentity() string {
+	if user := r.getWriteUser(); user != "" {
+		return user
+	}
+
+	return "the identity of the controller"
+}
+
+// getLocalWriteConfig returns the config of the local client the synced objects and their status are written with
+func (r *syncReconciler) getLocalWriteConfig() *rest.Config {
+	config := util.RuleRESTConfig(r.localMgr.GetConfig(), r.getRule().GetName(), r.getWriteUser())
+	// the requests of the reconciles are traced, so the latency of the local API server is attributable
+	config.Wrap(tracing.WrapTransport)
+
+	return config
+}
+
+// onWriteResult keeps the forbidden condition of the rule up to date with the result of a reconcile, the condition is
+// only written when it changes, which is returned. It is cleared by the first successful reconcile after a forbidden
+// write, which also re-enqueues the objects parked after forbidden requests.
+func (r *syncReconciler) onWriteResult(ctx context.Context, err error) bool {
+	forbidden := isForbiddenError(err)
+	if err != nil && !forbidden {
+		return false
+	}
+
+	r.forbiddenMu.Lock()
+	changed := r.forbidden != forbidden
+	r.forbidden = forbidden
+	r.forbiddenMu.Unlock()
+
+	if !changed {
+		return false
+	}
+
+	r.setForbiddenCondition(ctx, err)
+	// the permissions are checked again, so the AccessVerified condition lists the missing ones
+	if forbidden {
+		r.requestAccessCheck()
+	} else {
+		r.releaseForbiddenObjects()
+	}
+
+	return true
+}
+
+// setForbiddenCondition sets the forbidden condition of the rule, err is nil if the writes are allowed
+func (r *syncReconciler) setForbiddenCondition(ctx context.Context, err error) {
+	if err != nil {
+		r.recordEvent(corev1.EventTypeWarning, "WriteForbidden", fmt.Sprintf("%s is not allowed to write the synced objects: %s", r.getWriteIdentity(), err))
+	}
+
+	if err := r.setClusterCondition(ctx, r.getForbiddenCondition(err)); err != nil {
+		r.GetLogger().Error(err, "could not set forbidden condition")
+	}
+}
+
+func (r *syncReconciler) getForbiddenCondition(err error) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeForbidden,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "WritesAllowed",
+		Message:            fmt.Sprintf("objects are written as %s", r.getWriteIdentity()),
+	}
+
+	if err == nil {
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "WritesForbidden"
+	condition.Message = fmt.Sprintf("%s is not allowed to write the synced objects, check its RBAC permissions: %s", r.getWriteIdentity(), err)
+
+	r.forbiddenMu.Lock()
+	permissions := r.getForbiddenPermissions()
+	count := len(r.forbiddenObjects)
+	r.forbiddenMu.Unlock()
+	if count > 0 {
+		condition.Message = fmt.Sprintf("%s is not allowed to write the synced objects, check its RBAC permissions, %d objects are parked until it is granted: %s", r.getWriteIdentity(), count, strings.Join(permissions, ", "))
+	}
+
+	return condition
+}
+
+// errWriteNotAllowed is returned by the write pre-check if the access review denies a write
+var errWriteNotAllowed = errors.NewPlain("write not allowed")
+
+func isForbiddenError(err error) bool {
+	return err != nil && (apierrors.IsForbidden(err) || errors.Is(err, errWriteNotAllowed))
+}
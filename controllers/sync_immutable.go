@@ -0,0 +1,100 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// immutableDeletionTimeout limits the time the deletion of an immutable object is waited for before it is created
+	// again, the reconcile is retried after it
+	immutableDeletionTimeout = time.Second * 30
+	// immutableDeletionCheckInterval is the interval at which the deleted immutable object is checked to be gone
+	immutableDeletionCheckInterval = time.Millisecond * 250
+)
+
+// recreateImmutableObject deletes the local ConfigMap or Secret marked immutable, whose update was rejected, waits for
+// it to be gone and creates it with the desired content. Unless the rule allows recreating immutable objects, the
+// object is left as is and it is not retried until its source changes. It returns whether the object is recreated.
+func (r *syncReconciler) recreateImmutableObject(ctx context.Context, req ctrl.Request, rec reconciler.ResourceReconciler, desired client.Object, log logr.Logger) (bool, error) {
+	localResource := client.ObjectKeyFromObject(desired)
+
+	if !r.getRule().Spec.RecreateImmutable {
+		msg := fmt.Sprintf("local object is immutable, the changes of its source can not be synced until it is deleted or the rule allows recreating immutable objects (resource: %s, localResource: %s)", req, localResource)
+		r.recordEvent(corev1.EventTypeWarning, "ObjectImmutable", msg)
+		log.Info(msg)
+
+		return false, nil
+	}
+
+	existing := r.initObjectFromGVK(r.getLocalGVK())
+	err := r.localMgr.GetAPIReader().Get(ctx, localResource, existing)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, errors.WrapIf(err, "could not get immutable object")
+	}
+
+	// only the object which rejected the update is deleted, not one created by someone else since
+	if err == nil {
+		uid := existing.GetUID()
+		err = r.localClient.Delete(ctx, existing, client.Preconditions{UID: &uid}, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+			return false, errors.WrapIf(err, "could not delete immutable object")
+		}
+	}
+
+	// the object can not be created while the deleted one is terminating
+	waitCtx, cancel := context.WithTimeout(ctx, immutableDeletionTimeout)
+	defer cancel()
+	err = wait.PollImmediateUntil(immutableDeletionCheckInterval, func() (bool, error) {
+		err := r.localMgr.GetAPIReader().Get(waitCtx, localResource, r.initObjectFromGVK(r.getLocalGVK()))
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+
+		return false, err
+	}, waitCtx.Done())
+	if err != nil {
+		return false, errors.WrapIf(err, "could not wait for the deletion of the immutable object")
+	}
+
+	created, ok := desired.DeepCopyObject().(client.Object)
+	if !ok {
+		return false, errors.New("invalid object")
+	}
+	created.SetResourceVersion("")
+	created.SetUID("")
+	if _, err := rec.ReconcileResource(created, r.getObjectDesiredState(ctx, nil)); err != nil {
+		return false, errors.WrapIf(err, "could not create immutable object")
+	}
+
+	msg := fmt.Sprintf("immutable local object is recreated with the changes of its source (resource: %s, localResource: %s)", req, localResource)
+	r.recordEvent(corev1.EventTypeNormal, "ObjectRecreatedImmutable", msg)
+	log.Info(msg)
+
+	return true, nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/go-logr/logr"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// DefaultSyncLogSampleRate is the default number of lines per second the sampled messages of the sync controllers,
+// e.g. "reconciling", are logged with per rule
+const DefaultSyncLogSampleRate = 10
+
+// WithLogSampler sets the sampler limiting the high volume log lines of the reconciler, it is shared by the sync
+// reconcilers of the rule for every cluster
+func WithLogSampler(sampler *util.LogSampler) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.logSampler = sampler
+	}
+}
+
+// getRuleLogLevel returns the log level set on the rule, an invalid level is logged and the default level is used
+func getRuleLogLevel(rule *clusterregistryv1alpha1.ResourceSyncRule, log logr.Logger) util.RuleLogLevel {
+	level, err := util.ParseRuleLogLevel(rule.GetAnnotations()[clusterregistryv1alpha1.LogLevelAnnotation])
+	if err != nil {
+		log.Error(err, "invalid log level annotation, the default level is used", "rule", rule.GetName())
+	}
+
+	return level
+}
+
+// GetLogger returns the logger of the reconciler with the fields and the log level of the rule
+func (r *syncReconciler) GetLogger() logr.Logger {
+	r.logMu.RLock()
+	defer r.logMu.RUnlock()
+
+	return r.ruleLog
+}
+
+// SetLogger sets the logger of the reconciler, every line is logged with the rule, the source cluster and the source
+// kind, and at the log level of the rule
+func (r *syncReconciler) SetLogger(l logr.Logger) {
+	r.logMu.Lock()
+	r.baseLog = l
+	r.logMu.Unlock()
+
+	r.refreshLogger()
+}
+
+// SetLogLevel sets the log level of the rule, the lines of the rule are logged by it whatever the verbosity of the
+// controller is
+func (r *syncReconciler) SetLogLevel(level util.RuleLogLevel) {
+	r.logMu.Lock()
+	changed := r.logLevel != level
+	r.logLevel = level
+	r.logMu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	r.refreshLogger()
+
+	if level == util.RuleLogLevelDefault {
+		r.GetLogger().Info("log level set", "level", "default")
+
+		return
+	}
+	r.GetLogger().Info("log level set", "level", level)
+}
+
+func (r *syncReconciler) getLogLevel() util.RuleLogLevel {
+	r.logMu.RLock()
+	defer r.logMu.RUnlock()
+
+	return r.logLevel
+}
+
+// refreshLogger derives the logger of the reconciler from the base logger with the current fields and log level
+func (r *syncReconciler) refreshLogger() {
+	gvk := r.GetSourceGVK()
+
+	r.logMu.Lock()
+	defer r.logMu.Unlock()
+
+	if r.baseLog == nil {
+		return
+	}
+
+	log := r.baseLog.WithValues("rule", r.getRule().GetName(), "clusterID", r.clusterID, "gvk", gvk.String())
+	r.ruleLog = util.WithRuleLogLevel(log, r.logLevel)
+	r.ManagedReconciler.SetLogger(r.ruleLog)
+}
+
+// logSampled logs the high volume info line, unless the lines of the message logged for the rule in the last second
+// reached the sample rate. The lines are not sampled while the rule is logged at a verbose level.
+func (r *syncReconciler) logSampled(log logr.Logger, msg string, keysAndValues ...interface{}) {
+	if r.getLogLevel().IsVerbose() {
+		log.Info(msg, keysAndValues...)
+
+		return
+	}
+
+	if !r.logSampler.Info(log, r.getRule().GetName(), msg, keysAndValues...) {
+		syncSuppressedLogLinesTotal.WithLabelValues(r.getRule().GetName(), r.clusterID).Inc()
+	}
+}
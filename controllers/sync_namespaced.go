@@ -0,0 +1,52 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// WithNamespacedRule confines the reconciler to the namespaced rule its rule was converted from: only the source
+// objects in the source namespace are matched, the objects are only written into and deleted from the namespace of
+// the namespaced rule, and the events are recorded on the namespaced rule
+func WithNamespacedRule(rule *clusterregistryv1alpha1.NamespacedResourceSyncRule, sourceNamespace string) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.namespacedRule = rule
+		r.sourceNamespace = sourceNamespace
+	}
+}
+
+// matchRule matches the object against the rule, the objects outside of the source namespace of a namespaced rule
+// never match
+func (r *syncReconciler) matchRule(obj client.Object) (bool, clusterregistryv1alpha1.MatchedRules, error) {
+	if r.namespacedRule != nil && obj.GetNamespace() != r.sourceNamespace {
+		return false, nil, nil
+	}
+
+	return r.getRule().Match(obj)
+}
+
+// isNamespaceAllowed returns whether the object can be written or deleted. The namespace has to be allowed by the
+// controller, and a namespaced rule can only write into its own namespace, so it can never write cluster scoped
+// objects.
+func (r *syncReconciler) isNamespaceAllowed(obj client.Object) bool {
+	if r.namespacedRule != nil && obj.GetNamespace() != r.namespacedRule.GetNamespace() {
+		return false
+	}
+
+	return r.namespaceAllowList.IsAllowed(obj)
+}
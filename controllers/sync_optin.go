@@ -0,0 +1,33 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// isSyncOptedOut returns whether the rule requires the opt-in annotation and the source object is not opted in
+func (r *syncReconciler) isSyncOptedOut(obj client.Object) bool {
+	return r.getRule().Spec.RequireOptInAnnotation && !clusterregistryv1alpha1.IsSyncOptedIn(obj)
+}
+
+// isSyncOptInChanged returns whether the rule requires the opt-in annotation and the update of the source object
+// opted it in or out, even if nothing else changed on it
+func (r *syncReconciler) isSyncOptInChanged(oldObj client.Object, newObj client.Object) bool {
+	return r.getRule().Spec.RequireOptInAnnotation &&
+		clusterregistryv1alpha1.IsSyncOptedIn(oldObj) != clusterregistryv1alpha1.IsSyncOptedIn(newObj)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"math/rand"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// DefaultSyncStartupWindow is the default time the objects listed when a sync controller starts are spread over
+const DefaultSyncStartupWindow = time.Second * 30
+
+// getStartupDelay returns a random delay within the startup window while the controller is starting, so the objects
+// listed by the controllers of every rule after a restart are not written at once, 0 afterwards
+func (r *syncReconciler) getStartupDelay() time.Duration {
+	r.convergeMu.Lock()
+	deadline := r.startupDeadline
+	r.convergeMu.Unlock()
+
+	if r.startupWindow <= 0 || !time.Now().Before(deadline) {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(r.startupWindow))) // nolint:gosec
+}
+
+// pacedEventHandler adds the objects of the create events to the queue with the delay returned by getDelay, the
+// informers send a create event for every listed object when they start
+type pacedEventHandler struct {
+	handler.EventHandler
+
+	getDelay func() time.Duration
+}
+
+func (h *pacedEventHandler) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	if delay := h.getDelay(); delay > 0 {
+		q = &delayedQueue{
+			RateLimitingInterface: q,
+			delay:                 delay,
+		}
+	}
+
+	h.EventHandler.Create(e, q)
+}
+
+// delayedQueue adds the items to the queue after the delay
+type delayedQueue struct {
+	workqueue.RateLimitingInterface
+
+	delay time.Duration
+}
+
+func (q *delayedQueue) Add(item interface{}) {
+	q.RateLimitingInterface.AddAfter(item, q.delay)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"emperror.dev/errors"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// hasPatchStrategy returns whether the synced objects are compared with the patch strategy of the rule, instead of
+// the strategic merge patch of the generic reconciler
+func (r *syncReconciler) hasPatchStrategy() bool {
+	strategy := r.getRule().Spec.PatchStrategy
+
+	return strategy != "" && strategy != clusterregistryv1alpha1.PatchStrategyStrategicMerge
+}
+
+// hasPatchDiff returns whether the desired object differs from the current one with the patch strategy of the rule,
+// the desired object is prepared for the update as the generic reconciler would before comparing them
+func (r *syncReconciler) hasPatchDiff(current, desired runtime.Object, replicas *clusterregistryv1alpha1.ReplicaMutations) (bool, error) {
+	currentObj, ok := current.DeepCopyObject().(client.Object)
+	if !ok {
+		return false, errors.New("invalid object")
+	}
+	desiredObj, ok := desired.DeepCopyObject().(client.Object)
+	if !ok {
+		return false, errors.New("invalid object")
+	}
+
+	if err := beforeObjectUpdate(currentObj, desiredObj, replicas); err != nil {
+		return false, err
+	}
+
+	changed, err := util.HasPatchDiff(r.getRule().Spec.PatchStrategy, desiredObj, currentObj)
+	if err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not compare object", "patchStrategy", r.getRule().Spec.PatchStrategy)
+	}
+
+	return changed, nil
+}
+
+// forceObjectUpdate makes the generic reconciler update the current object once the patch strategy of the rule found
+// it changed. Its own strategic merge patch could miss the change, or fail on the object, so the current object is
+// recorded as the last applied configuration, and every field missing from the desired object counts as a change.
+func forceObjectUpdate(current runtime.Object) error {
+	return errors.WrapIf(patch.DefaultAnnotator.SetLastAppliedAnnotation(current), "could not set last applied annotation")
+}
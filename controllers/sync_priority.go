@@ -0,0 +1,130 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// isOverriddenByRule returns whether the object is synced by another rule matching it with a higher priority
+func (r *syncReconciler) isOverriddenByRule(ctx context.Context, key types.NamespacedName, obj client.Object, log logr.Logger) (bool, error) {
+	if r.ruleRegistry == nil {
+		return false, nil
+	}
+
+	winner, tied, err := r.ruleRegistry.Resolve(r.clusterName, obj)
+	if err != nil {
+		return false, errors.WrapIf(err, "could not resolve the rule syncing the object")
+	}
+	if winner == "" || winner == r.getRule().GetName() {
+		return false, r.releaseOverriddenObject(ctx, key)
+	}
+
+	r.overriddenMu.Lock()
+	previous, ok := r.overriddenObjects[key]
+	r.overriddenObjects[key] = winner
+	condition := r.getOverriddenCondition()
+	r.overriddenMu.Unlock()
+
+	if ok && previous == winner {
+		log.V(1).Info("object is synced by another rule, skipping", "rule", winner)
+
+		return true, nil
+	}
+
+	msg := fmt.Sprintf("object is not synced, it is synced by rule %s with higher priority (resource: %s)", winner, key)
+	if tied {
+		msg = fmt.Sprintf("object is not synced, it is synced by rule %s with the same priority and a lower name (resource: %s)", winner, key)
+	}
+	r.recordEvent(corev1.EventTypeNormal, "OverriddenByRule", msg)
+	log.Info(msg, "rule", winner, "tied", tied)
+
+	return true, r.setClusterCondition(ctx, condition)
+}
+
+// releaseOverriddenObject reports that the object is not synced by another rule anymore
+func (r *syncReconciler) releaseOverriddenObject(ctx context.Context, key types.NamespacedName) error {
+	r.overriddenMu.Lock()
+	if _, ok := r.overriddenObjects[key]; !ok {
+		r.overriddenMu.Unlock()
+
+		return nil
+	}
+	delete(r.overriddenObjects, key)
+	condition := r.getOverriddenCondition()
+	r.overriddenMu.Unlock()
+
+	return r.setClusterCondition(ctx, condition)
+}
+
+// enqueueOverriddenObjects enqueues the objects synced by other rules, since the rule syncing them could change
+// whenever a rule of the same kind is registered or unregistered
+func (r *syncReconciler) enqueueOverriddenObjects() {
+	queue := r.queue
+	if queue == nil {
+		return
+	}
+
+	r.overriddenMu.Lock()
+	defer r.overriddenMu.Unlock()
+
+	for key := range r.overriddenObjects {
+		queue.Add(reconcile.Request{NamespacedName: key})
+	}
+}
+
+// getOverriddenCondition must be called with overriddenMu held
+func (r *syncReconciler) getOverriddenCondition() metav1.Condition {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeOverriddenByRule,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "NoOverriddenObjects",
+		Message:            "no object is synced by another rule",
+	}
+
+	if len(r.overriddenObjects) == 0 {
+		return condition
+	}
+
+	winners := make(map[string]struct{})
+	for _, winner := range r.overriddenObjects {
+		winners[winner] = struct{}{}
+	}
+	rules := make([]string, 0, len(winners))
+	for winner := range winners {
+		rules = append(rules, winner)
+	}
+	sort.Strings(rules)
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "ObjectsOverridden"
+	condition.Message = fmt.Sprintf("%d objects are synced by rules with higher priority: %s", len(r.overriddenObjects), strings.Join(rules, ", "))
+
+	return condition
+}
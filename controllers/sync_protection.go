@@ -0,0 +1,164 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// maxListedBlockedDeletions limits the blocked deletions listed in the status of the rule, the rest is only counted
+const maxListedBlockedDeletions = 20
+
+// blockedDeletion is a synced object which is not deleted, because it is protected
+type blockedDeletion struct {
+	source types.NamespacedName
+	since  time.Time
+}
+
+// isDeleteProtected returns whether the synced object is protected from deletion and takeover by the rule
+func (r *syncReconciler) isDeleteProtected(obj metav1.Object) bool {
+	return !r.getRule().Spec.AllowProtectedDeletion && obj.GetAnnotations()[clusterregistryv1alpha1.DeleteProtectedAnnotation] == "true"
+}
+
+// blockDeletion records that the protected object is not deleted, until the annotation is removed or the rule allows
+// the deletion of protected objects
+func (r *syncReconciler) blockDeletion(ctx context.Context, obj client.Object, log logr.Logger) {
+	key := client.ObjectKeyFromObject(obj)
+
+	r.deletionMu.Lock()
+	if _, ok := r.blockedDeletions[key]; ok {
+		r.deletionMu.Unlock()
+		log.V(1).Info("deletion is skipped, object is protected")
+
+		return
+	}
+	r.blockedDeletions[key] = blockedDeletion{
+		source: util.GetSourceObjectKey(obj),
+		since:  time.Now(),
+	}
+	r.deletionMu.Unlock()
+
+	msg := fmt.Sprintf("object is protected by the %s annotation, it is not deleted (resource: %s)", clusterregistryv1alpha1.DeleteProtectedAnnotation, key)
+	r.recordEvent(corev1.EventTypeWarning, "ObjectDeletionBlocked", msg)
+	log.Info(msg)
+
+	if err := r.setBlockedDeletionsStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not update blocked deletions")
+	}
+}
+
+// unblockDeletions forgets the blocked deletions selected by the function, e.g. because the objects are deleted or
+// synced again
+func (r *syncReconciler) unblockDeletions(ctx context.Context, selected func(key types.NamespacedName, deletion blockedDeletion) bool) {
+	r.deletionMu.Lock()
+	var changed bool
+	for key, deletion := range r.blockedDeletions {
+		if selected(key, deletion) {
+			delete(r.blockedDeletions, key)
+			changed = true
+		}
+	}
+	r.deletionMu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if err := r.setBlockedDeletionsStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not update blocked deletions")
+	}
+}
+
+// unblockSourceDeletions forgets the blocked deletions of the objects synced from the source, except the given ones
+func (r *syncReconciler) unblockSourceDeletions(ctx context.Context, source types.NamespacedName, except ...client.Object) {
+	kept := make(map[types.NamespacedName]struct{}, len(except))
+	for _, obj := range except {
+		kept[client.ObjectKeyFromObject(obj)] = struct{}{}
+	}
+
+	r.unblockDeletions(ctx, func(key types.NamespacedName, deletion blockedDeletion) bool {
+		_, ok := kept[key]
+
+		return deletion.source == source && !ok
+	})
+}
+
+func (r *syncReconciler) setBlockedDeletionsStatus(ctx context.Context) error {
+	r.deletionMu.Lock()
+	keys := make([]types.NamespacedName, 0, len(r.blockedDeletions))
+	for key := range r.blockedDeletions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	deletions := make([]clusterregistryv1alpha1.BlockedDeletion, 0, maxListedBlockedDeletions)
+	for i, key := range keys {
+		if i == maxListedBlockedDeletions {
+			break
+		}
+		deletions = append(deletions, clusterregistryv1alpha1.BlockedDeletion{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			// the API server stores the time with second precision
+			Since: metav1.NewTime(r.blockedDeletions[key].since.Truncate(time.Second)),
+		})
+	}
+	r.deletionMu.Unlock()
+
+	syncBlockedDeletions.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(len(keys)))
+
+	if r.getRule().GetUID() == "" {
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeDeletionBlocked,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "NoBlockedDeletions",
+		Message:            "no protected object is waiting for deletion",
+	}
+	if len(keys) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ProtectedObjects"
+		condition.Message = fmt.Sprintf("%d synced objects are not deleted, because they are protected by the %s annotation", len(keys), clusterregistryv1alpha1.DeleteProtectedAnnotation)
+	}
+
+	return errors.WrapIf(SetResourceSyncRuleClusterStatus(ctx, r.localMgr.GetClient(), r.getRule().GetName(), r.clusterName, func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus) {
+		status.BlockedDeletionCount = len(keys)
+		if len(deletions) == 0 {
+			status.BlockedDeletions = nil
+		} else {
+			status.BlockedDeletions = deletions
+		}
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}), "could not update rule status")
+}
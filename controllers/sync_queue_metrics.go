@@ -0,0 +1,199 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// queueObserver exports the work queue metrics of the sync controller of a rule labeled with the rule and the source
+// cluster, the metrics of controller-runtime are labeled with the name of the controller only.
+// The age of the oldest item is measured from the time the item became ready, the items waiting for their retry
+// backoff are not counted until they are picked by a worker.
+type queueObserver struct {
+	rule    string
+	cluster string
+
+	mu      sync.Mutex
+	pending map[interface{}]time.Time
+	// observed are the times the changes of the items were first observed, until they are reconciled successfully,
+	// the sync lag of the objects without a modification time set by the push side is measured from them
+	observed map[interface{}]time.Time
+}
+
+func newQueueObserver(rule, cluster string) *queueObserver {
+	return &queueObserver{
+		rule:     rule,
+		cluster:  cluster,
+		pending:  make(map[interface{}]time.Time),
+		observed: make(map[interface{}]time.Time),
+	}
+}
+
+// changed records the time the change of the item was observed, the earliest one is kept until the item is reconciled
+func (o *queueObserver) changed(item interface{}, observedAt time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.observed[item]; !ok {
+		o.observed[item] = observedAt
+	}
+}
+
+// observedAt returns the time the pending change of the item was first observed
+func (o *queueObserver) observedAt(item interface{}) (time.Time, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	observedAt, ok := o.observed[item]
+
+	return observedAt, ok
+}
+
+// added records the item added to the queue, which is ready to be picked at readyAt
+func (o *queueObserver) added(item interface{}, readyAt time.Time) {
+	syncQueueAddsTotal.WithLabelValues(o.rule, o.cluster).Inc()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// the queue holds an item once, it is picked at the earliest time it was added for
+	if t, ok := o.pending[item]; !ok || readyAt.Before(t) {
+		o.pending[item] = readyAt
+	}
+}
+
+// picked records the item taken from the queue by a worker
+func (o *queueObserver) picked(item interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.pending, item)
+}
+
+// done records the result of the reconcile of the item, the controller adds the failed and the requeued items back
+func (o *queueObserver) done(item interface{}, result ctrl.Result, err error) {
+	if err == nil {
+		o.mu.Lock()
+		delete(o.observed, item)
+		o.mu.Unlock()
+	}
+
+	switch {
+	case err != nil || result.Requeue && result.RequeueAfter <= 0:
+		syncQueueRetriesTotal.WithLabelValues(o.rule, o.cluster).Inc()
+	case result.RequeueAfter > 0:
+		o.added(item, time.Now().Add(result.RequeueAfter))
+	}
+}
+
+// observe exports the age of the oldest item ready to be picked
+func (o *queueObserver) observe(now time.Time) {
+	syncQueueOldestItemAge.WithLabelValues(o.rule, o.cluster).Set(o.oldestItemAge(now).Seconds())
+}
+
+// oldestItemAge returns the time the oldest item ready to be picked has been waiting for
+func (o *queueObserver) oldestItemAge(now time.Time) time.Duration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	oldest := now
+	for _, readyAt := range o.pending {
+		if readyAt.Before(oldest) {
+			oldest = readyAt
+		}
+	}
+
+	return now.Sub(oldest)
+}
+
+// reset forgets the pending items, the queue is dropped with its controller
+func (o *queueObserver) reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.pending = make(map[interface{}]time.Time)
+	o.observed = make(map[interface{}]time.Time)
+}
+
+func (o *queueObserver) deleteMetrics() {
+	syncQueueAddsTotal.DeleteLabelValues(o.rule, o.cluster)
+	syncQueueRetriesTotal.DeleteLabelValues(o.rule, o.cluster)
+	syncQueueOldestItemAge.DeleteLabelValues(o.rule, o.cluster)
+	syncActiveWorkers.DeleteLabelValues(o.rule, o.cluster)
+	syncLag.DeleteLabelValues(o.rule, o.cluster)
+}
+
+// observedQueue records the items added to the queue with the observer
+type observedQueue struct {
+	workqueue.RateLimitingInterface
+
+	observer *queueObserver
+}
+
+func (q *observedQueue) Add(item interface{}) {
+	q.observer.changed(item, time.Now())
+	q.observer.added(item, time.Now())
+	q.RateLimitingInterface.Add(item)
+}
+
+func (q *observedQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.observer.changed(item, time.Now())
+	q.observer.added(item, time.Now().Add(duration))
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+// AddRateLimited records the item ready right away, the delay of the rate limiter is not known
+func (q *observedQueue) AddRateLimited(item interface{}) {
+	q.observer.changed(item, time.Now())
+	q.observer.added(item, time.Now())
+	q.RateLimitingInterface.AddRateLimited(item)
+}
+
+// observedEventHandler passes the queue of the controller to the handler with the items added to it recorded
+type observedEventHandler struct {
+	handler.EventHandler
+
+	observer *queueObserver
+}
+
+func (h *observedEventHandler) queue(q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+	return &observedQueue{
+		RateLimitingInterface: q,
+		observer:              h.observer,
+	}
+}
+
+func (h *observedEventHandler) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Create(e, h.queue(q))
+}
+
+func (h *observedEventHandler) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Update(e, h.queue(q))
+}
+
+func (h *observedEventHandler) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Delete(e, h.queue(q))
+}
+
+func (h *observedEventHandler) Generic(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Generic(e, h.queue(q))
+}
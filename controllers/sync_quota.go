@@ -0,0 +1,197 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// objectCountStatusInterval is the shortest time between the updates of the object count in the status of the rule,
+// the count is only approximate in between
+const objectCountStatusInterval = time.Minute
+
+// admitObject counts the local object synced from the source against the object quota of the rule, and returns
+// whether it can be synced. The objects existing already are always admitted, only the new ones beyond the quota are
+// refused.
+func (r *syncReconciler) admitObject(ctx context.Context, source types.NamespacedName, obj client.Object, log logr.Logger) (bool, error) {
+	limit := r.getRule().Spec.MaxObjects
+	local := client.ObjectKeyFromObject(obj)
+
+	exists := true
+	if limit > 0 {
+		err := r.localClient.Get(ctx, local, r.initObjectFromGVK(r.getLocalGVK()))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, errors.WrapIf(err, "could not get local object")
+		}
+		exists = err == nil
+	}
+
+	result := r.objectQuota.Admit(r.getRule().GetName(), r.clusterName, local, exists, limit)
+
+	r.quotaMu.Lock()
+	if result.Admitted {
+		delete(r.quotaRefusedObjects, source)
+		if previous, ok := r.quotaObjects[source]; ok && previous != local {
+			// the object is routed to another local object, the previous one is counted until it is deleted
+			r.quotaMovedObjects[previous] = struct{}{}
+		}
+		r.quotaObjects[source] = local
+	} else {
+		r.quotaRefusedObjects[source] = struct{}{}
+	}
+	refused := len(r.quotaRefusedObjects)
+	r.quotaMu.Unlock()
+
+	syncQuotaRefusedObjects.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(refused))
+
+	if !result.Admitted {
+		log.V(1).Info("object is not created, the rule syncs as many objects as its quota", "localResource", local.String(), "maxObjects", limit)
+	}
+	if result.ExceededChanged && result.Exceeded {
+		msg := fmt.Sprintf("new objects are not created, the rule syncs %d objects and its quota is %d (resource: %s)", result.Count, limit, source)
+		r.recordEvent(corev1.EventTypeWarning, "ObjectQuotaExceeded", msg)
+		log.Info(msg)
+	}
+
+	return result.Admitted, r.setObjectCountStatus(ctx, result.Count, result.ExceededChanged)
+}
+
+// releaseQuotaObject stops counting the object synced from the source against the object quota of the rule, e.g.
+// because it is deleted or the source is not synced by the rule anymore
+func (r *syncReconciler) releaseQuotaObject(ctx context.Context, source types.NamespacedName) error {
+	r.quotaMu.Lock()
+	local, ok := r.quotaObjects[source]
+	delete(r.quotaObjects, source)
+	_, refused := r.quotaRefusedObjects[source]
+	delete(r.quotaRefusedObjects, source)
+	count := len(r.quotaRefusedObjects)
+	r.quotaMu.Unlock()
+
+	if refused {
+		syncQuotaRefusedObjects.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(count))
+	}
+	if !ok {
+		return nil
+	}
+
+	return r.releaseLocalQuotaObject(ctx, local)
+}
+
+// releaseDeletedQuotaObject stops counting the deleted local object, either the current object of its source or one
+// left behind after the source was routed to another local object
+func (r *syncReconciler) releaseDeletedQuotaObject(ctx context.Context, obj client.Object) error {
+	local := client.ObjectKeyFromObject(obj)
+	source := util.GetSourceObjectKey(obj)
+
+	r.quotaMu.Lock()
+	_, moved := r.quotaMovedObjects[local]
+	delete(r.quotaMovedObjects, local)
+	current, ok := r.quotaObjects[source]
+	if ok && current == local {
+		delete(r.quotaObjects, source)
+	}
+	r.quotaMu.Unlock()
+
+	if !moved && (!ok || current != local) {
+		return nil
+	}
+
+	return r.releaseLocalQuotaObject(ctx, local)
+}
+
+func (r *syncReconciler) releaseLocalQuotaObject(ctx context.Context, local types.NamespacedName) error {
+	result := r.objectQuota.Release(r.getRule().GetName(), r.clusterName, local, r.getRule().Spec.MaxObjects)
+
+	return r.setObjectCountStatus(ctx, result.Count, result.ExceededChanged)
+}
+
+// enqueueQuotaRefusedObjects enqueues the objects refused by the object quota of the rule, since they can be created
+// once the rule syncs fewer objects than its quota
+func (r *syncReconciler) enqueueQuotaRefusedObjects() {
+	queue := r.queue
+	if queue == nil {
+		return
+	}
+
+	r.quotaMu.Lock()
+	defer r.quotaMu.Unlock()
+
+	for key := range r.quotaRefusedObjects {
+		queue.Add(reconcile.Request{NamespacedName: key})
+	}
+}
+
+// setObjectCountStatus records the number of objects synced by the rule in its status at most once per interval,
+// and the QuotaExceeded condition whenever it changes
+func (r *syncReconciler) setObjectCountStatus(ctx context.Context, count int, exceededChanged bool) error {
+	if r.getRule().GetUID() == "" {
+		return nil
+	}
+
+	limit := r.getRule().Spec.MaxObjects
+	r.quotaMu.Lock()
+	due := limit > 0 && time.Since(r.quotaStatusUpdated) >= objectCountStatusInterval
+	if !due && !exceededChanged {
+		r.quotaMu.Unlock()
+
+		return nil
+	}
+	r.quotaStatusUpdated = time.Now()
+	r.quotaMu.Unlock()
+
+	condition := r.getQuotaExceededCondition()
+
+	return errors.WrapIf(updateRuleStatus(ctx, r.localMgr.GetClient(), r.getRule().GetName(), func(status *clusterregistryv1alpha1.ResourceSyncRuleStatus) bool {
+		current := status.DeepCopy()
+		status.ObjectCount = count
+		meta.SetStatusCondition(&status.Conditions, condition)
+
+		return !equality.Semantic.DeepEqual(current, status)
+	}), "could not update rule status")
+}
+
+func (r *syncReconciler) getQuotaExceededCondition() metav1.Condition {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeQuotaExceeded,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "WithinQuota",
+		Message:            "the rule syncs fewer objects than its quota",
+	}
+
+	if r.objectQuota.IsExceeded(r.getRule().GetName()) {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ObjectQuotaExceeded"
+		condition.Message = fmt.Sprintf("new objects are not created, the rule syncs %d objects and its quota is %d", r.objectQuota.Count(r.getRule().GetName()), r.getRule().Spec.MaxObjects)
+	}
+
+	return condition
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+const (
+	uncachedReadTargetSource = "source"
+	uncachedReadTargetLocal  = "local"
+)
+
+// countingReader counts the reads bypassing the cache, to make their API load visible
+type countingReader struct {
+	client.Reader
+
+	reads prometheus.Counter
+}
+
+func (r countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	r.reads.Inc()
+
+	return r.Reader.Get(ctx, key, obj)
+}
+
+func (r countingReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	r.reads.Inc()
+
+	return r.Reader.List(ctx, list, opts...)
+}
+
+// getSourceClient returns the client reading the source objects from the cache of the source cluster, which is the
+// cache of the shared watch the rule is attached to, if any
+func (r *syncReconciler) getSourceClient() client.Client {
+	if r.sourceClient != nil {
+		return r.sourceClient
+	}
+
+	return r.GetClient()
+}
+
+// getSourceReader returns the reader of the source objects, which reads the API server of the source cluster directly
+// in the Direct read mode and its cache otherwise
+func (r *syncReconciler) getSourceReader() client.Reader {
+	if r.getRule().Spec.ReadMode != clusterregistryv1alpha1.ReadModeDirect || r.GetManager() == nil {
+		return r.getSourceClient()
+	}
+
+	return countingReader{
+		Reader: r.GetManager().GetAPIReader(),
+		reads:  syncUncachedReadsTotal.WithLabelValues(r.getRule().GetName(), r.clusterID, uncachedReadTargetSource),
+	}
+}
+
+// getUncachedSourceReader returns the reader of the source objects missing from the cache of the source cluster, it is
+// nil if the source objects are read directly anyway
+func (r *syncReconciler) getUncachedSourceReader() client.Reader {
+	if r.getRule().Spec.ReadMode == clusterregistryv1alpha1.ReadModeDirect || r.GetManager() == nil {
+		return nil
+	}
+
+	return countingReader{
+		Reader: r.GetManager().GetAPIReader(),
+		reads:  syncUncachedReadsTotal.WithLabelValues(r.getRule().GetName(), r.clusterID, uncachedReadTargetSource),
+	}
+}
+
+// getSyncedObject reads the synced object from the local cache, and directly from the local API server if it is not
+// in the cache yet, e.g. right after it is created
+func (r *syncReconciler) getSyncedObject(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	err := r.localClient.Get(ctx, key, obj)
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	syncUncachedReadsTotal.WithLabelValues(r.getRule().GetName(), r.clusterID, uncachedReadTargetLocal).Inc()
+
+	return r.localMgr.GetAPIReader().Get(ctx, key, obj)
+}
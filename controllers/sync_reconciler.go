@@ -17,14 +17,16 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"emperror.dev/errors"
-	pluralize "github.com/gertd/go-pluralize"
 	"github.com/go-logr/logr"
 	"github.com/throttled/throttled"
-	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -48,40 +50,233 @@ import (
 
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
-	"github.com/banzaicloud/operator-tools/pkg/resources"
-	operatortoolstypes "github.com/banzaicloud/operator-tools/pkg/types"
 	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
 	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+	"github.com/cisco-open/cluster-registry-controller/pkg/events"
+	objectsync "github.com/cisco-open/cluster-registry-controller/pkg/sync"
+	"github.com/cisco-open/cluster-registry-controller/pkg/syncstate"
+	"github.com/cisco-open/cluster-registry-controller/pkg/tracing"
 	"github.com/cisco-open/cluster-registry-controller/pkg/util"
 )
 
-const (
-	originalNameLabel      = "cluster-registry.k8s.cisco.com/original-name"
-	originalNamespaceLabel = "cluster-registry.k8s.cisco.com/original-namespace"
-)
+// takeoverCheckInterval is how often the taken over objects are checked for their owner to come back
+const takeoverCheckInterval = time.Second * 30
+
+// unresolvedOwnerInterval is how often the objects are synced again while some of their remapped owners are not synced
+// locally yet
+const unresolvedOwnerInterval = time.Second * 10
 
 type syncReconciler struct {
 	clusters.ManagedReconciler
 
-	gvk             schema.GroupVersionKind
-	localGVK        schema.GroupVersionKind
-	localClusterID  string
+	gvk      schema.GroupVersionKind
+	localGVK schema.GroupVersionKind
+	// fallbackSchema is the OpenAPI schema of the local version the objects fall back to, if the scheme does not know it
+	fallbackSchema  map[string]interface{}
+	localClusterID  *LocalClusterIDResolver
 	localMgr        ctrl.Manager
 	localRecorder   record.EventRecorder
-	clustersManager *clusters.Manager
+	objectRecorder  record.EventRecorder
+	clustersManager clusters.ClusterLister
 	rateLimiter     throttled.RateLimiter
 
-	clusterID      string
-	ctrl           controller.Controller
-	queue          workqueue.RateLimitingInterface
-	rule           *clusterregistryv1alpha1.ResourceSyncRule
-	localInformers map[string]struct{}
-
-	localClient client.Client
-	localCache  cache.Cache
+	writeFormatVersion util.FormatVersion
+
+	clusterID   string
+	clusterName string
+	ctrl        controller.Controller
+	queue       workqueue.RateLimitingInterface
+	// queueObserver exports the metrics of the queue labeled with the rule and the cluster
+	queueObserver *queueObserver
+	// rule is the rule the objects are synced by, it is swapped with the state derived from it when the rule is updated
+	// in place, see UpdateRule
+	rule   *clusterregistryv1alpha1.ResourceSyncRule
+	ruleMu sync.RWMutex
+	// watches are the keys of the watches registered on ctrl, so they are not registered twice
+	watches map[string]struct{}
+
+	localClient      client.Client
+	localCache       cache.Cache
+	localCacheCancel context.CancelFunc
 
 	resourceNameMutated      bool
 	resourceNamespaceMutated bool
+	// localClusterScoped is set when the local kind is cluster scoped, the synced objects never get a namespace then
+	localClusterScoped bool
+
+	// waitingForCRD is set while the local kind is not served, e.g. its CRD is not installed yet. The objects are parked
+	// in crdWaitingObjects until the CRD is established.
+	waitingForCRD     bool
+	crdWaitingObjects map[types.NamespacedName]struct{}
+	lastCRDCheck      time.Time
+
+	// parkedObjects are not synced until the values of their conflicting immutable fields change
+	parkedObjects map[types.NamespacedName]parkedObject
+	// blockedObjects are skipped, because their kind or namespace is in the deny list of the controller
+	blockedObjects map[types.NamespacedName]struct{}
+	denyList       *util.DenyList
+	// namespaceAllowList limits the namespaces objects are written into and deleted from, regardless of the rule
+	namespaceAllowList *util.NamespaceAllowList
+	// namespacedRule is the namespaced rule the rule was converted from, its events are recorded on it. The source
+	// objects outside of sourceNamespace are never matched, and the objects are only written into and deleted from
+	// the namespace of the namespaced rule.
+	namespacedRule  *clusterregistryv1alpha1.NamespacedResourceSyncRule
+	sourceNamespace string
+	// takeovers are the objects updated while the cluster owning them is not alive
+	takeovers *util.TakeoverTracker
+	// failures are the consecutive sync failures of the objects, objects failing too many times are parked
+	failures *util.FailureTracker
+
+	// reconcileTimeout limits the time a reconcile of an object can take, so a hung API call can not stall a worker
+	reconcileTimeout time.Duration
+
+	// newCache creates the cache of the synced local objects
+	newCache cache.NewCacheFunc
+
+	// fullReconcileInterval is the longest time an object with an unchanged content hash is not reconciled in full,
+	// 0 disables the content hash fast path
+	fullReconcileInterval time.Duration
+
+	// maxSyncHops is the number of clusters an object can be synced through, see util.CheckSyncLoop
+	maxSyncHops int
+
+	// maxObjectSize is the largest size of the synced source objects, unless the rule overrides it, 0 disables the limit
+	maxObjectSize int64
+	// lastAppliedSizeLimit is the size of the synced objects above which they are written without the last applied
+	// annotation, 0 disables the limit
+	lastAppliedSizeLimit int64
+	// oversizedObjects are the source objects not synced, because they are larger than the maximum object size
+	oversizedObjects map[types.NamespacedName]oversizedObject
+
+	// redactor knows the sensitive fields of the objects, whose values are scrubbed from the logs, events and errors
+	redactor *util.Redactor
+	// auditLog gets the audit records of the synced objects, which are stamped with their audit hash, nil if the audit
+	// trail is disabled
+	auditLog logr.Logger
+
+	// serviceAccountNamespace is the namespace of the service account of the rule the objects are written as
+	serviceAccountNamespace string
+	// accessCheckInterval is the time between two access checks of the running controller, 0 disables the checks
+	accessCheckInterval time.Duration
+	accessCheckRequests chan struct{}
+	// freshnessThreshold is the longest time the oldest queued object can wait while the SyncFresh condition holds, 0
+	// disables the condition
+	freshnessThreshold time.Duration
+	// listPager pages the lists of the source cache, the warm-up of the cache is not reported without it
+	listPager *clusters.ListPager
+	// sharedWatches share the watches of the source kinds between the rules, the source kind is watched by the
+	// controller of the rule if it is not set. sharedWatch is the watch the rule is attached to, and sourceClient reads
+	// the source objects from its cache.
+	sharedWatches *SharedSourceWatches
+	sharedWatch   *sharedSourceWatch
+	sourceClient  client.Client
+	// forbidden is set while the writes of the synced objects are forbidden for the identity they are written with
+	forbidden bool
+	// forbiddenObjects are the objects parked after forbidden requests with the permissions they are missing
+	forbiddenObjects map[types.NamespacedName]string
+
+	// suspended reconcilers drop every queued request, see the suspend field of the rule
+	suspended bool
+
+	// converged is set once the objects listed from the source cluster are reconciled, see IsConverged
+	converged   bool
+	reconciling int
+	onConverged func()
+	// startCtx is the context the reconciler was started with, the convergence is tracked again with it when the rule is
+	// updated in place, and convergeCancel stops the current tracking
+	startCtx       context.Context
+	convergeCancel context.CancelFunc
+	// startupWindow is the time the objects listed on start are spread over, they are added to the queue with a
+	// random delay within the window until startupDeadline
+	startupWindow   time.Duration
+	startupDeadline time.Time
+	// writeLimiter limits the local writes running at the same time across the controllers of every rule
+	writeLimiter *util.WriteLimiter
+
+	// syncedVersions are the resource versions of the last synced source objects and of the objects synced from them
+	syncedVersions map[types.NamespacedName]syncedVersion
+	// writingSources are the sources whose synced objects are being written, their updates are not local drift, it is
+	// guarded by syncedMu
+	writingSources map[types.NamespacedName]struct{}
+
+	// ruleRegistry resolves which one of the rules matching the same object syncs it
+	ruleRegistry *util.RuleRegistry
+	// triggers enqueue the source objects reconciled again by the components of the controller, the source is kept
+	// when the controller of the reconciler is restarted
+	triggers *InMemorySource
+	// objectQuota counts the local objects synced by the rule from every cluster against its object quota
+	objectQuota *util.ObjectQuota
+	// quotaObjects are the sources with the local objects synced from them counted against the object quota
+	quotaObjects map[types.NamespacedName]types.NamespacedName
+	// quotaMovedObjects are the local objects left behind after their sources were routed to other local objects, they
+	// are counted against the object quota until they are deleted
+	quotaMovedObjects map[types.NamespacedName]struct{}
+	// quotaRefusedObjects are the sources whose objects are not created, because of the object quota of the rule
+	quotaRefusedObjects map[types.NamespacedName]struct{}
+	// quotaStatusUpdated is the last time the object count was recorded in the status of the rule
+	quotaStatusUpdated time.Time
+	// logSampler limits the lines of the high volume log messages of the rule, it is shared by the reconcilers of the
+	// rule for every cluster
+	logSampler *util.LogSampler
+	// logLevel is the log level of the rule, the logger of the reconciler is derived from baseLog with it and with the
+	// fields of the rule as ruleLog, they are guarded by logMu
+	logLevel util.RuleLogLevel
+	baseLog  logr.Logger
+	ruleLog  logr.Logger
+	// overriddenObjects are matched by the rule, but synced by the rules in the values, which have higher priority
+	overriddenObjects map[types.NamespacedName]string
+	// waitingClaims are the PersistentVolumeClaims held until the storage classes in the values exist locally
+	waitingClaims map[types.NamespacedName]string
+	// blockedDeletions are the local synced objects not deleted, because they are protected
+	blockedDeletions map[types.NamespacedName]blockedDeletion
+	// locallyControlledObjects are the existing local objects skipped, because they are controlled by a local controller
+	locallyControlledObjects map[types.NamespacedName]struct{}
+	// pendingDeletions are the missing sources with the time they were first found missing, the objects synced from
+	// them are deleted once the deletion delay of the rule passes
+	pendingDeletions map[types.NamespacedName]time.Time
+	// syncWindow is the parsed sync window of the rule, the changes are deferred while it is closed, it is guarded by
+	// ruleMu
+	syncWindow *util.SyncWindow
+	// deferredObjects are the objects whose changes wait for the next sync window
+	deferredObjects map[types.NamespacedName]struct{}
+	// heldWrites are the objects whose local write is held until the write coalescing window of the rule closes
+	heldWrites map[types.NamespacedName]heldWrite
+	// keyRemovals are the compiled key prefixes and regular expressions of the annotations and labels removed by the
+	// mutations of the rule, they are guarded by ruleMu
+	keyRemovals *util.KeyRemovals
+
+	// syncState is reported the synced objects and errors of the rule, which are summarized on the Cluster resource
+	syncState *syncstate.Aggregator
+
+	// hooks are called at the points of the reconciles they implement the interfaces of, see SyncHook
+	hooks []SyncHook
+
+	gvkMu      sync.RWMutex
+	mutatedMu  sync.RWMutex
+	setupMu    sync.Mutex
+	parkedMu   sync.Mutex
+	blockedMu  sync.Mutex
+	suspendMu  sync.RWMutex
+	convergeMu sync.Mutex
+	syncedMu   sync.Mutex
+	logMu      sync.RWMutex
+
+	overriddenMu sync.Mutex
+	crdMu        sync.Mutex
+	deletionMu   sync.Mutex
+	forbiddenMu  sync.Mutex
+	oversizedMu  sync.Mutex
+	controlledMu sync.Mutex
+
+	storageClassMu sync.Mutex
+	syncWindowMu   sync.Mutex
+	heldWritesMu   sync.Mutex
+	quotaMu        sync.Mutex
+}
+
+type parkedObject struct {
+	fields []string
+	values map[string]interface{}
 }
 
 type SyncReconcilerOption func(r *syncReconciler)
@@ -92,20 +287,248 @@ func WithRateLimiter(rateLimiter throttled.RateLimiter) SyncReconcilerOption {
 	}
 }
 
-func NewSyncReconciler(name string, localMgr ctrl.Manager, rule *clusterregistryv1alpha1.ResourceSyncRule, log logr.Logger, clusterID string, clustersManager *clusters.Manager, opts ...SyncReconcilerOption) (SyncReconciler, error) {
+func WithClusterName(name string) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.clusterName = name
+	}
+}
+
+// WithNamespaceAllowList sets the namespaces the reconciler can only write into and delete from
+func WithNamespaceAllowList(allowList *util.NamespaceAllowList) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.namespaceAllowList = allowList
+	}
+}
+
+// WithDenyList sets the namespaces and kinds the reconciler never writes
+func WithDenyList(denyList *util.DenyList) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.denyList = denyList
+	}
+}
+
+// WithFailureThreshold sets the number of consecutive failures after which an object is not retried until its source
+// changes, 0 retries the objects forever
+func WithFailureThreshold(threshold int) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.failures = util.NewFailureTracker(threshold)
+	}
+}
+
+// WithReconcileTimeout sets the time a reconcile of an object can take, 0 disables the limit. The reconcile timeout of
+// the rule overrides it.
+func WithReconcileTimeout(timeout time.Duration) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.reconcileTimeout = timeout
+	}
+}
+
+// WithSyncStateAggregator sets the aggregator the reconciler reports the synced objects and errors to
+func WithSyncStateAggregator(aggregator *syncstate.Aggregator) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.syncState = aggregator
+	}
+}
+
+// WithRuleRegistry makes the reconciler skip the objects synced by other rules with higher priority
+func WithRuleRegistry(registry *util.RuleRegistry) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.ruleRegistry = registry
+	}
+}
+
+// WithObjectQuota sets the object quota shared by the sync reconcilers of the rule for every cluster
+func WithObjectQuota(quota *util.ObjectQuota) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.objectQuota = quota
+	}
+}
+
+// WithNewCacheFunc sets the function creating the cache of the synced local objects, e.g. to transform the cached
+// objects
+func WithNewCacheFunc(newCache cache.NewCacheFunc) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.newCache = newCache
+	}
+}
+
+// WithFullReconcileInterval sets the longest time an object whose desired state is unchanged since its last write is not
+// reconciled in full, 0 reconciles every object in full
+func WithFullReconcileInterval(interval time.Duration) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.fullReconcileInterval = interval
+	}
+}
+
+// WithMaxSyncHops sets the number of clusters an object can be synced through from the cluster it originates from,
+// 0 disables the limit
+func WithMaxSyncHops(hops int) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.maxSyncHops = hops
+	}
+}
+
+// WithMaxObjectSize sets the largest size of the source objects serialized to JSON which are synced, 0 disables the
+// limit. The maximum object size of the rule overrides it.
+func WithMaxObjectSize(size int64) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.maxObjectSize = size
+	}
+}
+
+// WithLastAppliedSizeLimit sets the size of the synced objects above which they are written without the last applied
+// annotation and compared field by field, 0 disables the limit
+func WithLastAppliedSizeLimit(size int64) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.lastAppliedSizeLimit = size
+	}
+}
+
+// WithRedactor sets the sensitive fields of the objects, whose values are scrubbed from the logs, events and errors,
+// only the data of Secrets is scrubbed by default
+func WithRedactor(redactor *util.Redactor) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.redactor = redactor
+	}
+}
+
+// WithAuditLogger enables the audit trail, the synced objects are stamped with the audit hash of their desired state
+// and the time it was written, and a record of every write is written to the logger
+func WithAuditLogger(log logr.Logger) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.auditLog = log
+	}
+}
+
+// WithAccessCheckInterval sets the time between two checks of the permissions of the rule, 0 disables the periodic
+// checks, the permissions are still checked before the controller starts and after forbidden writes
+func WithAccessCheckInterval(interval time.Duration) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.accessCheckInterval = interval
+	}
+}
+
+// WithFreshnessThreshold sets the longest time the oldest queued object can wait before the SyncFresh condition of
+// the rule turns false, 0 disables the condition
+func WithFreshnessThreshold(threshold time.Duration) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.freshnessThreshold = threshold
+	}
+}
+
+// WithStartupWindow sets the time the objects listed when the controller starts are spread over, 0 adds them to the
+// queue right away
+func WithStartupWindow(window time.Duration) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.startupWindow = window
+	}
+}
+
+// WithWriteLimiter sets the limiter of the local writes shared by the controllers of every rule
+func WithWriteLimiter(limiter *util.WriteLimiter) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.writeLimiter = limiter
+	}
+}
+
+// WithListPager sets the pager of the lists of the source cache, whose progress is reported by the CacheSyncing
+// condition of the rule during the warm-up of the cache
+func WithListPager(pager *clusters.ListPager) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.listPager = pager
+	}
+}
+
+// WithSharedSourceWatches shares the watch of the source kind with the rules watching the same kind on the cluster
+func WithSharedSourceWatches(watches *SharedSourceWatches) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.sharedWatches = watches
+	}
+}
+
+// WithServiceAccountNamespace sets the namespace of the service accounts the rules write the synced objects as
+func WithServiceAccountNamespace(namespace string) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.serviceAccountNamespace = namespace
+	}
+}
+
+// WithOnConvergedFunc sets the function called once the reconciler reconciled the objects listed from the source
+// cluster after it started
+func WithOnConvergedFunc(f func()) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.onConverged = f
+	}
+}
+
+func WithWriteFormatVersion(version util.FormatVersion) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.writeFormatVersion = version
+	}
+}
+
+// WithLocalClusterIDResolver sets the resolver of the ID of the local cluster, so it is shared by the controllers
+func WithLocalClusterIDResolver(resolver *LocalClusterIDResolver) SyncReconcilerOption {
+	return func(r *syncReconciler) {
+		r.localClusterID = resolver
+	}
+}
+
+func NewSyncReconciler(name string, localMgr ctrl.Manager, rule *clusterregistryv1alpha1.ResourceSyncRule, log logr.Logger, clusterID string, clustersManager clusters.ClusterLister, opts ...SyncReconcilerOption) (SyncReconciler, error) {
 	r := &syncReconciler{
 		ManagedReconciler: clusters.NewManagedReconciler(name, log),
-
-		gvk:             schema.GroupVersionKind(rule.Spec.GVK),
-		localMgr:        localMgr,
-		localRecorder:   localMgr.GetEventRecorderFor("cluster-controller"),
-		clustersManager: clustersManager,
-		rule:            rule,
-		clusterID:       clusterID,
-		localInformers:  make(map[string]struct{}),
+		baseLog:           log,
+		ruleLog:           log,
+
+		localMgr:                 localMgr,
+		localRecorder:            events.NewSafeRecorder(localMgr.GetEventRecorderFor(ruleEventsComponent), localMgr.GetScheme(), localMgr.GetRESTMapper(), log),
+		objectRecorder:           events.NewSafeRecorder(localMgr.GetEventRecorderFor(objectEventsComponent), localMgr.GetScheme(), localMgr.GetRESTMapper(), log),
+		clustersManager:          clustersManager,
+		clusterID:                clusterID,
+		localClusterID:           NewLocalClusterIDResolver("", log.WithName("local-cluster-id")),
+		queueObserver:            newQueueObserver(rule.GetName(), clusterID),
+		watches:                  make(map[string]struct{}),
+		parkedObjects:            make(map[types.NamespacedName]parkedObject),
+		blockedObjects:           make(map[types.NamespacedName]struct{}),
+		syncedVersions:           make(map[types.NamespacedName]syncedVersion),
+		writingSources:           make(map[types.NamespacedName]struct{}),
+		overriddenObjects:        make(map[types.NamespacedName]string),
+		crdWaitingObjects:        make(map[types.NamespacedName]struct{}),
+		waitingClaims:            make(map[types.NamespacedName]string),
+		blockedDeletions:         make(map[types.NamespacedName]blockedDeletion),
+		pendingDeletions:         make(map[types.NamespacedName]time.Time),
+		locallyControlledObjects: make(map[types.NamespacedName]struct{}),
+		forbiddenObjects:         make(map[types.NamespacedName]string),
+		oversizedObjects:         make(map[types.NamespacedName]oversizedObject),
+		deferredObjects:          make(map[types.NamespacedName]struct{}),
+		heldWrites:               make(map[types.NamespacedName]heldWrite),
+		objectQuota:              util.NewObjectQuota(),
+		quotaObjects:             make(map[types.NamespacedName]types.NamespacedName),
+		quotaMovedObjects:        make(map[types.NamespacedName]struct{}),
+		quotaRefusedObjects:      make(map[types.NamespacedName]struct{}),
+		takeovers:                util.NewTakeoverTracker(),
+		failures:                 util.NewFailureTracker(util.DefaultFailureThreshold),
+		reconcileTimeout:         DefaultSyncReconcileTimeout,
+		newCache:                 cache.New,
+		maxSyncHops:              util.DefaultMaxSyncHops,
+		maxObjectSize:            DefaultSyncMaxObjectSize,
+		lastAppliedSizeLimit:     DefaultLastAppliedSizeLimit,
+		fullReconcileInterval:    DefaultFullReconcileInterval,
+		accessCheckInterval:      DefaultAccessCheckInterval,
+		freshnessThreshold:       DefaultSyncFreshnessThreshold,
+		accessCheckRequests:      make(chan struct{}, 1),
+		suspended:                rule.Spec.Suspend,
+
+		writeFormatVersion: util.PreviousFormatVersion,
+	}
+
+	r.triggers = NewInMemorySource(r, DefaultInMemorySourceBufferSize)
+
+	if err := r.setRule(rule); err != nil {
+		return nil, err
 	}
 
-	_, r.localGVK = clusterregistryv1alpha1.MatchedRules(rule.Spec.Rules).GetMutatedGVK(r.gvk)
+	r.setSourceGVK(schema.GroupVersionKind(rule.Spec.GVK))
 
 	for _, opt := range opts {
 		opt(r)
@@ -114,126 +537,762 @@ func NewSyncReconciler(name string, localMgr ctrl.Manager, rule *clusterregistry
 	return r, nil
 }
 
-func (r *syncReconciler) PreCheck(ctx context.Context, client client.Client) error {
-	for _, verb := range []string{"get", "list", "watch"} {
-		attr := &authorizationv1.ResourceAttributes{
-			Verb:     verb,
-			Group:    r.gvk.Group,
-			Version:  r.gvk.Version,
-			Resource: strings.ToLower(pluralize.NewClient().Plural(r.gvk.Kind)),
-		}
-		selfSubjectAccessReview := authorizationv1.SelfSubjectAccessReview{
-			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
-				ResourceAttributes: attr,
-			},
+// getLocalClusterID returns the last resolved ID of the local cluster
+func (r *syncReconciler) getLocalClusterID() string {
+	return string(r.localClusterID.Get())
+}
+
+// setSourceGVK sets the GVK synced from the cluster and the local GVK it is synced to, the lines of the reconciler are
+// logged with the GVK
+func (r *syncReconciler) setSourceGVK(gvk schema.GroupVersionKind) {
+	r.gvkMu.Lock()
+	r.gvk = gvk
+	r.localGVK = getLocalKind(r.getRule().Spec, gvk)
+	r.gvkMu.Unlock()
+
+	r.refreshLogger()
+}
+
+func (r *syncReconciler) IsSuspended() bool {
+	r.suspendMu.RLock()
+	defer r.suspendMu.RUnlock()
+
+	return r.suspended
+}
+
+// SetSuspended suspends or resumes syncing, every matching source object and every object synced from the
+// cluster is re-enqueued on resume so that the changes missed while suspended converge
+func (r *syncReconciler) SetSuspended(ctx context.Context, suspended bool) error {
+	r.suspendMu.Lock()
+	resumed := r.suspended && !suspended
+	r.suspended = suspended
+	r.suspendMu.Unlock()
+
+	r.GetLogger().Info("set suspended", "suspended", suspended)
+
+	if !resumed {
+		return nil
+	}
+
+	return r.enqueueAll(ctx, false)
+}
+
+// Resync syncs every object covered by the rule again, even the ones unchanged since their last sync. The objects
+// are added to the queue through its rate limiter, so they are synced at the pace of the regular reconciles.
+func (r *syncReconciler) Resync(ctx context.Context) error {
+	r.syncedMu.Lock()
+	r.syncedVersions = make(map[types.NamespacedName]syncedVersion)
+	r.syncedMu.Unlock()
+
+	r.overriddenMu.Lock()
+	r.overriddenObjects = make(map[types.NamespacedName]string)
+	r.overriddenMu.Unlock()
+
+	r.crdMu.Lock()
+	r.crdWaitingObjects = make(map[types.NamespacedName]struct{})
+	r.crdMu.Unlock()
+
+	if r.failures.Reset() {
+		if err := r.setFailedObjectsStatus(ctx); err != nil {
+			r.GetLogger().Error(err, "could not reset failed objects")
 		}
+	}
 
-		err := client.Create(ctx, &selfSubjectAccessReview)
+	return r.enqueueAll(ctx, true)
+}
+
+// GetTriggerSource returns the source the components of the controller enqueue the source objects reconciled again
+// through
+func (r *syncReconciler) GetTriggerSource() *InMemorySource {
+	return r.triggers
+}
+
+// enqueueAllObjects enqueues every object of the rule on a full resync triggered through the source of the reconciler
+func (r *syncReconciler) enqueueAllObjects(ctx context.Context, listFromCache bool) {
+	if err := r.enqueueListed(ctx, true, listFromCache); err != nil {
+		r.GetLogger().Error(err, "could not enqueue objects")
+	}
+}
+
+// enqueueAll adds the matching source objects and the objects synced from the cluster to the queue
+func (r *syncReconciler) enqueueAll(ctx context.Context, rateLimited bool) error {
+	return r.enqueueListed(ctx, rateLimited, true)
+}
+
+// enqueueListed adds the matching source objects and the objects synced from the cluster to the queue, they are
+// listed from the informer caches or the API servers
+func (r *syncReconciler) enqueueListed(ctx context.Context, rateLimited bool, listFromCache bool) error {
+	if r.queue == nil {
+		return nil
+	}
+
+	keys := make(map[types.NamespacedName]struct{})
+
+	sourceReader, localReader := client.Reader(r.getSourceClient()), client.Reader(r.localClient)
+	if !listFromCache {
+		if r.GetManager() != nil {
+			sourceReader = r.GetManager().GetAPIReader()
+		}
+		localReader = r.localMgr.GetAPIReader()
+	}
+
+	sourceObjects, err := r.listObjectsFrom(ctx, sourceReader, r.getSourceClient().Scheme(), r.GetSourceGVK())
+	if err != nil {
+		return errors.WrapIf(err, "could not list source objects")
+	}
+	for _, obj := range sourceObjects {
+		if ok, _, err := r.matchRule(obj); ok && err == nil {
+			keys[client.ObjectKeyFromObject(obj)] = struct{}{}
+		}
+	}
+
+	// the local objects can not be listed while the local kind is not served
+	if !r.isWaitingForCRD() {
+		localGVK := r.getLocalGVK()
+
+		localObjects, err := r.listObjectsFrom(ctx, localReader, r.localClient.Scheme(), localGVK)
 		if err != nil {
-			return errors.WrapIfWithDetails(err, "failed to create self subject access review", "attributes", attr)
+			return errors.WrapIf(err, "could not list local objects")
+		}
+		for _, obj := range localObjects {
+			if util.IsOwnedByRule(obj, r.clusterID, r.getRule().GetName()) {
+				keys[util.GetSourceObjectKey(obj)] = struct{}{}
+			}
+		}
+	}
+
+	for key := range keys {
+		req := reconcile.Request{
+			NamespacedName: key,
+		}
+		if rateLimited {
+			r.queue.AddRateLimited(req)
+		} else {
+			r.queue.Add(req)
+		}
+	}
+
+	r.observeQueueDepth()
+
+	r.GetLogger().Info("objects re-enqueued", "count", len(keys))
+
+	return nil
+}
+
+func (r *syncReconciler) listObjects(ctx context.Context, c client.Client, gvk schema.GroupVersionKind) ([]client.Object, error) {
+	return r.listObjectsFrom(ctx, c, c.Scheme(), gvk)
+}
+
+func (r *syncReconciler) listObjectsFrom(ctx context.Context, c client.Reader, scheme *runtime.Scheme, gvk schema.GroupVersionKind) ([]client.Object, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+	var objectList client.ObjectList = list
+	if o, err := scheme.New(list.GroupVersionKind()); err == nil {
+		if typed, ok := o.(client.ObjectList); ok {
+			objectList = typed
 		}
+	}
+
+	err := c.List(ctx, objectList)
+	if err != nil {
+		return nil, err
+	}
 
-		if !selfSubjectAccessReview.Status.Allowed {
-			return errors.Errorf("do not have access to %s gvk: %s", attr.Verb, r.gvk)
+	objects := make([]client.Object, 0)
+	err = meta.EachListItem(objectList, func(o runtime.Object) error {
+		if obj, ok := o.(client.Object); ok {
+			obj.GetObjectKind().SetGroupVersionKind(gvk)
+			objects = append(objects, obj)
 		}
+
+		return nil
+	})
+
+	return objects, errors.WithStackIf(err)
+}
+
+func (r *syncReconciler) GetSourceGVK() schema.GroupVersionKind {
+	r.gvkMu.RLock()
+	defer r.gvkMu.RUnlock()
+
+	return r.gvk
+}
+
+func (r *syncReconciler) getLocalGVK() schema.GroupVersionKind {
+	r.gvkMu.RLock()
+	defer r.gvkMu.RUnlock()
+
+	return r.localGVK
+}
+
+func (r *syncReconciler) setLocalClusterScoped(clusterScoped bool) {
+	r.gvkMu.Lock()
+	defer r.gvkMu.Unlock()
+
+	r.localClusterScoped = clusterScoped
+}
+
+func (r *syncReconciler) isLocalClusterScoped() bool {
+	r.gvkMu.RLock()
+	defer r.gvkMu.RUnlock()
+
+	return r.localClusterScoped
+}
+
+// setKeyMutated records whether the name or the namespace of any synced object differs from its source object, the
+// synced objects are looked up by their source key from then on
+func (r *syncReconciler) setKeyMutated(nameMutated, namespaceMutated bool) {
+	if !nameMutated && !namespaceMutated {
+		return
 	}
 
-	if err := r.writePreCheck(ctx); err != nil {
+	r.mutatedMu.Lock()
+	defer r.mutatedMu.Unlock()
+
+	r.resourceNameMutated = r.resourceNameMutated || nameMutated
+	r.resourceNamespaceMutated = r.resourceNamespaceMutated || namespaceMutated
+}
+
+func (r *syncReconciler) isKeyMutated() (nameMutated, namespaceMutated bool) {
+	r.mutatedMu.RLock()
+	defer r.mutatedMu.RUnlock()
+
+	return r.resourceNameMutated, r.resourceNamespaceMutated
+}
+
+// resolveSourceGVK resolves the version of the rule GVK to the one served by the cluster
+// and records it in the status of the rule
+func (r *syncReconciler) resolveSourceGVK(ctx context.Context) error {
+	if r.getRule().Spec.GVK.Version != clusterregistryv1alpha1.AnyVersion {
+		return nil
+	}
+
+	gvk, err := util.ResolveSourceGVK(r.GetManager().GetRESTMapper(), schema.GroupVersionKind(r.getRule().Spec.GVK), r.getRule().Spec.Versions)
+	if err != nil {
 		return err
 	}
 
-	return nil
-}
+	r.setSourceGVK(gvk)
+	r.GetLogger().Info("source version resolved", "gvk", gvk)
+
+	if r.getRule().GetUID() == "" {
+		return nil
+	}
+
+	return errors.WrapIf(SetResourceSyncRuleClusterStatus(ctx, r.localMgr.GetClient(), r.getRule().GetName(), r.clusterName, func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus) {
+		status.ResolvedVersion = gvk.Version
+	}), "could not update rule status")
+}
+
+func (r *syncReconciler) PreCheck(ctx context.Context, client client.Client) error {
+	// the kinds are looked up on activation, so that the reconciles never wait for discovery
+	if err := warmRESTMapper(r.GetManager().GetRESTMapper(), schema.GroupVersionKind(r.getRule().Spec.GVK)); err != nil {
+		return errors.WrapIf(err, "could not look up source kind")
+	}
+
+	if err := r.resolveSourceGVK(ctx); err != nil {
+		return err
+	}
+
+	if err := r.resolveLocalVersionFallback(ctx); err != nil {
+		return err
+	}
+
+	// the controller is started without a served local kind, the objects wait for its CRD
+	err := warmRESTMapper(r.localMgr.GetRESTMapper(), r.getLocalGVK())
+	if err != nil && !isMissingKindError(err) {
+		return errors.WrapIf(err, "could not look up local kind")
+	}
+	r.crdMu.Lock()
+	r.waitingForCRD = err != nil
+	r.lastCRDCheck = time.Now()
+	r.crdMu.Unlock()
+
+	if err == nil {
+		clusterScoped, err := util.IsClusterScoped(r.localMgr.GetRESTMapper(), r.getLocalGVK())
+		if err != nil {
+			return errors.WrapIf(err, "could not look up scope of local kind")
+		}
+		r.setLocalClusterScoped(clusterScoped)
+	}
+
+	return r.verifyAccess(ctx, client, true)
+}
+
+func (r *syncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	r.queueObserver.picked(req)
+	r.observeQueueDepth()
+	r.observeRateLimiterKeys()
+
+	defer r.startReconcile()()
+	defer func() {
+		r.queueObserver.done(req, result, err)
+		r.runPostSyncHooks(ctx, req.NamespacedName, result, err)
+	}()
+
+	ctx, span := r.startSpan(ctx, spanReconcile, req.NamespacedName)
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	result, err = r.reconcileWithTimeout(ctx, req)
+	// the mappers could miss a kind right after its CRD is installed, the object is retried once after refreshing them
+	if isMissingKindError(err) && r.refreshRESTMappers() == nil {
+		result, err = r.reconcileWithTimeout(ctx, req)
+	}
+	// the CRD of the local kind could be removed after the controller is started
+	if isMissingKindError(err) && !r.isLocalKindKnown() {
+		return r.waitForCRD(ctx, req.NamespacedName)
+	}
+	if err != nil && r.syncState != nil {
+		r.syncState.SyncFailed(r.clusterName, r.getRule().GetName(), req.NamespacedName, err)
+	}
+	// forbidden requests fail the same way until the permissions are fixed, they are not counted as failures
+	if isForbiddenError(err) {
+		return r.parkForbiddenObject(ctx, req, err), nil
+	}
+	r.onWriteResult(ctx, err)
+	if err == nil {
+		r.failures.Succeeded(req.NamespacedName)
+
+		return result, nil
+	}
+
+	if errors.Is(err, ErrReconcileTimeout) {
+		r.recordSyncFailedEvent(ctx, req, "ObjectReconcileTimeout", fmt.Sprintf("could not reconcile in time (resource: %s): %s", req, err.Error()), err)
+	} else if errors.Is(err, ErrSyncHookFailed) {
+		r.recordSyncFailedEvent(ctx, req, "ObjectSyncHookFailed", fmt.Sprintf("sync hook failed (resource: %s): %s", req, err.Error()), err)
+	} else if errors.Is(err, ErrDeletionPendingFinalizers) {
+		r.recordSyncFailedEvent(ctx, req, "ObjectDeletionPendingFinalizers", fmt.Sprintf("synced object is not deleted until its preserved finalizers are removed (resource: %s): %s", req, err.Error()), err)
+	} else if errors.Is(err, util.ErrVersionFallbackFailed) {
+		r.recordSyncFailedEvent(ctx, req, "VersionFallbackFailed", fmt.Sprintf("could not convert object to the version served by the local cluster (resource: %s): %s", req, err.Error()), err)
+	} else if errors.Is(err, util.ErrJSONPatchFailed) {
+		r.recordSyncFailedEvent(ctx, req, "ObjectJSONPatchFailed", fmt.Sprintf("could not apply json patch (resource: %s): %s", req, err.Error()), err)
+	} else {
+		r.recordSyncFailedEvent(ctx, req, "ObjectNotReconciled", fmt.Sprintf("could not reconcile (resource: %s): %s", req, err.Error()), err)
+	}
+
+	if r.failures.Failed(req.NamespacedName, err) && r.parkFailedObject(ctx, req, err) {
+		return ctrl.Result{}, nil
+	}
+
+	return result, err
+}
+
+// reconcileWithTimeout reconciles the object within the reconcile timeout of the rule
+func (r *syncReconciler) reconcileWithTimeout(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	timeout := r.getReconcileTimeout()
+	if timeout <= 0 {
+		return r.reconcile(ctx, req)
+	}
+
+	reconcileCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := r.reconcile(reconcileCtx, req)
+	if err != nil && errors.Is(reconcileCtx.Err(), context.DeadlineExceeded) {
+		syncReconcileTimeoutsTotal.WithLabelValues(r.getRule().GetName(), r.clusterID).Inc()
+
+		return result, errors.WithDetails(errors.WrapIf(ErrReconcileTimeout, err.Error()), "timeout", timeout.String())
+	}
+
+	return result, err
+}
+
+func (r *syncReconciler) getReconcileTimeout() time.Duration {
+	if r.getRule().Spec.ReconcileTimeout != nil {
+		return r.getRule().Spec.ReconcileTimeout.Duration
+	}
+
+	return r.reconcileTimeout
+}
+
+// isCreateOnly returns whether the synced objects are only created and left to the local cluster afterwards
+func (r *syncReconciler) isCreateOnly() bool {
+	return r.getRule().Spec.SyncMode == clusterregistryv1alpha1.SyncModeEnsureExists
+}
+
+func (r *syncReconciler) DoCleanup() {
+	if r.syncState != nil {
+		r.syncState.RemoveRule(r.clusterName, r.getRule().GetName())
+	}
+	if r.ruleRegistry != nil {
+		r.ruleRegistry.Unregister(r.clusterName, r.getRule().GetName())
+	}
+	r.objectQuota.ForgetCluster(r.getRule().GetName(), r.clusterName)
+	r.unwatchSourceStatus()
+	syncQueueDepth.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncRateLimiterKeys.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	r.queueObserver.deleteMetrics()
+	syncVerificationDriftedObjects.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncFailedObjects.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncForbiddenObjects.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncForbiddenErrorsTotal.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncBlockedDeletions.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncOversizedObjects.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncReconcileTimeoutsTotal.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncLoopsDetectedTotal.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncContentHashChecksTotal.DeleteLabelValues(r.getRule().GetName(), r.clusterID, "hit")
+	syncContentHashChecksTotal.DeleteLabelValues(r.getRule().GetName(), r.clusterID, "miss")
+	syncCoalescedWritesTotal.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncHeldWrites.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncDriftDetectedTotal.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncQuotaRefusedObjects.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	syncSuppressedLogLinesTotal.DeleteLabelValues(r.getRule().GetName(), r.clusterID)
+	for _, target := range []string{uncachedReadTargetSource, uncachedReadTargetLocal} {
+		syncUncachedReadsTotal.DeleteLabelValues(r.getRule().GetName(), r.clusterID, target)
+	}
+	for _, result := range []string{"in_sync", "drifted"} {
+		syncVerifiedObjectsTotal.DeleteLabelValues(r.getRule().GetName(), r.clusterID, result)
+	}
+	r.Teardown()
+
+	r.ManagedReconciler.DoCleanup()
+}
+
+// observeQueueDepth exports the number of the queued objects, which shows whether the rule needs more workers
+func (r *syncReconciler) observeQueueDepth() {
+	if r.queue == nil {
+		return
+	}
+
+	syncQueueDepth.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(r.queue.Len()))
+	r.queueObserver.observe(time.Now())
+}
+
+// keyCounter is implemented by the rate limiters which report the number of tracked keys
+type keyCounter interface {
+	KeyCount() int
+}
+
+// observeRateLimiterKeys exports the number of the objects tracked by the rate limiter, which shows whether it grows
+func (r *syncReconciler) observeRateLimiterKeys() {
+	counter, ok := r.rateLimiter.(keyCounter)
+	if !ok {
+		return
+	}
+
+	syncRateLimiterKeys.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(counter.KeyCount()))
+}
+
+// recordEvent records an event on the rule, events which could not be recorded are logged instead
+func (r *syncReconciler) recordEvent(eventtype, reason, message string) {
+	if r.namespacedRule != nil {
+		r.localRecorder.Event(r.namespacedRule, eventtype, reason, message)
+
+		return
+	}
+
+	r.localRecorder.Event(r.getRule(), eventtype, reason, message)
+}
+
+func (r *syncReconciler) initObjectFromGVK(gvk schema.GroupVersionKind) client.Object {
+	return objectsync.NewObject(r.localClient.Scheme(), gvk)
+}
+
+func (r *syncReconciler) reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := r.GetLogger().WithValues("resource", req.NamespacedName)
+
+	// already synced objects are left untouched while the rule is suspended, the requests are re-enqueued on resume
+	if r.IsSuspended() {
+		log.V(1).Info("rule is suspended, skipping")
+
+		return ctrl.Result{}, nil
+	}
+
+	if !r.isLocalKindServed(ctx) {
+		log.V(1).Info("local kind is not served, waiting for its CRD")
+
+		return r.waitForCRD(ctx, req.NamespacedName)
+	}
+
+	// the local cluster id is refreshed periodically, it tells the objects synced back to this cluster apart
+	if _, err := r.localClusterID.Resolve(ctx, r.localMgr.GetAPIReader(), r.localRecorder); errors.Is(err, ErrLocalClusterIDUnresolved) {
+		log.Info("local cluster id is not resolved, retrying", append([]interface{}{"error", err.Error()}, errors.GetDetails(err)...)...)
+
+		return ctrl.Result{RequeueAfter: r.localClusterID.Backoff()}, nil
+	} else if err != nil {
+		return ctrl.Result{}, errors.WithStackIf(err)
+	}
+
+	obj := r.initObjectFromGVK(r.GetSourceGVK())
+	obj.SetName(req.Name)
+	obj.SetNamespace(req.Namespace)
+
+	// Mutate prior to check target namespace
+	getCtx, span := r.startSpan(ctx, spanRemoteGet, req.NamespacedName)
+	err = util.GetSourceObject(getCtx, r.getSourceReader(), r.getUncachedSourceReader(), req.NamespacedName, obj)
+	tracing.End(span, client.IgnoreNotFound(err))
+
+	// the values of the sensitive fields, e.g. the data of Secrets, are scrubbed from the errors, so they do not end up
+	// in the logs, events and statuses, and from the logs of the reconciler of the synced object
+	scrubber := r.redactor.NewScrubber(obj)
+	defer func() {
+		err = scrubber.ScrubError(err)
+	}()
+	log = scrubber.WrapLogger(log)
+
+	// the source objects opted out of the sync are handled like the deleted ones
+	optedOut := err == nil && r.isSyncOptedOut(obj)
+	if optedOut {
+		log.V(1).Info("source object is not opted in to be synced anymore")
+	}
+
+	if apierrors.IsNotFound(err) || err == nil && !obj.GetDeletionTimestamp().IsZero() || optedOut {
+		if result, deferred, err := r.deferToSyncWindow(ctx, req.NamespacedName, true); err != nil || deferred {
+			return result, err
+		}
+		if err := r.unparkObject(ctx, req.NamespacedName); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.unblockObject(ctx, req.NamespacedName); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.unparkFailedObject(ctx, req.NamespacedName)
+		r.forgetOversizedObject(ctx, req.NamespacedName)
+		if err := r.releaseOverriddenObject(ctx, req.NamespacedName); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.releaseStorageClassWaitingObject(ctx, req.NamespacedName); err != nil {
+			return ctrl.Result{}, err
+		}
+		// a write held for the object must not land after its deletion
+		r.flushHeldWrite(req.NamespacedName, log)
+		if r.isCreateOnly() {
+			log.V(1).Info("source object is gone, the synced object is kept in the create-only sync mode")
+		} else if result, delayed := r.delayDeletion(req.NamespacedName); delayed {
+			log.V(1).Info("source object is gone, the deletion of the synced object is delayed", "requeueAfter", result.RequeueAfter)
+
+			return result, nil
+		} else if err := r.deleteResource(ctx, obj, log); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.forgetSyncedVersion(req.NamespacedName)
+		r.reportObjectRemoved(req.NamespacedName)
+
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, errors.WrapIf(err, "could not get object")
+	}
+	r.cancelDeletion(req.NamespacedName)
+
+	if r.isFailedObjectParked(ctx, req.NamespacedName, obj) {
+		log.V(1).Info("object failed to sync too many times, it is not retried until its source changes")
+
+		return ctrl.Result{}, nil
+	}
+
+	_, span = r.startSpan(ctx, spanMatch, req.NamespacedName)
+	ok, matchedRules, err := r.matchRule(obj)
+	tracing.End(span, err)
+	if !ok {
+		r.forgetSyncedVersion(req.NamespacedName)
+		r.reportObjectRemoved(req.NamespacedName)
+		if err := r.releaseStorageClassWaitingObject(ctx, req.NamespacedName); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.releaseSyncWindowDeferredObject(ctx, req.NamespacedName); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.releaseQuotaObject(ctx, req.NamespacedName); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{}, r.releaseOverriddenObject(ctx, req.NamespacedName)
+	}
+	if err != nil {
+		return ctrl.Result{}, errors.WrapIf(err, "could not match object")
+	}
+
+	// an object coming back to the cluster it originates from, e.g. through a pair of rules syncing in both directions,
+	// is never synced, otherwise it would bounce between the clusters forever
+	if err := util.CheckSyncLoop(obj, r.getLocalClusterID(), r.maxSyncHops); err != nil {
+		r.recordEvent(corev1.EventTypeWarning, "SyncLoopDetected", fmt.Sprintf("object is not synced, check the rules syncing it between the clusters (resource: %s): %s", req, err))
+		syncLoopsDetectedTotal.WithLabelValues(r.getRule().GetName(), r.clusterID).Inc()
+		log.Info("sync loop detected, skipping", append([]interface{}{"error", err.Error()}, errors.GetDetails(err)...)...)
+		r.forgetSyncedVersion(req.NamespacedName)
+		r.reportObjectRemoved(req.NamespacedName)
+
+		return ctrl.Result{}, nil
+	}
+
+	if overridden, err := r.isOverriddenByRule(ctx, req.NamespacedName, obj, log); err != nil || overridden {
+		if overridden {
+			r.forgetSyncedVersion(req.NamespacedName)
+			r.reportObjectRemoved(req.NamespacedName)
+			if err := r.releaseQuotaObject(ctx, req.NamespacedName); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+
+		return ctrl.Result{}, err
+	}
+
+	sourceResourceVersion := obj.GetResourceVersion()
+	if r.isUnchangedSinceSync(ctx, obj, matchedRules) {
+		log.V(1).Info("object is unchanged since the last sync, skipping")
+		if r.syncState != nil {
+			r.syncState.ObjectSynced(r.clusterName, r.getRule().GetName(), req.NamespacedName)
+		}
+
+		return ctrl.Result{}, nil
+	}
+
+	// oversized objects are skipped before they are mutated, their writes would fail anyway
+	if oversized, err := r.isOversized(ctx, req.NamespacedName, obj, log); err != nil || oversized {
+		return ctrl.Result{}, err
+	}
+
+	if result, deferred, err := r.deferToSyncWindow(ctx, req.NamespacedName, false); err != nil || deferred {
+		return result, err
+	}
+
+	r.logSampled(log, "reconciling")
+
+	if err := r.runPreMutateHooks(ctx, req.NamespacedName, obj); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	sourceObj := obj
+	_, span = r.startSpan(ctx, spanMutate, req.NamespacedName)
+	obj, err = r.mutateObject(ctx, obj, matchedRules)
+	tracing.End(span, err)
+	if errors.Is(err, util.ErrEmptySecretData) {
+		// the previously synced copy would keep the keys which are pruned by now, so it is removed
+		msg := "secret data is empty after pruning, removing the synced object"
+		r.recordEvent(corev1.EventTypeWarning, "ObjectSkippedEmptySecretData", fmt.Sprintf("%s (resource: %s)", msg, req))
+		log.Info(msg)
+		if !r.isCreateOnly() {
+			r.flushHeldWrite(req.NamespacedName, log)
+			if err := r.deleteResource(ctx, sourceObj, log); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		r.forgetSyncedVersion(req.NamespacedName)
+		r.reportObjectRemoved(req.NamespacedName)
 
-// WritePreCheck Check for write permissions on local cluster
-func (r *syncReconciler) writePreCheck(ctx context.Context) error {
-	localClient, err := client.New(r.localMgr.GetConfig(), client.Options{
-		Scheme: r.localMgr.GetScheme(),
-		Mapper: r.localMgr.GetRESTMapper(),
-	})
-	if err != nil {
-		return errors.WrapIfWithDetails(err, "error creating local client")
+		return ctrl.Result{}, nil
 	}
+	if errors.Is(err, util.ErrNamespaceNotRouted) {
+		if matchedRules.GetMutationNamespaceRouting().GetUnmappedPolicy() == clusterregistryv1alpha1.UnmappedNamespacePolicyFail {
+			r.recordEvent(corev1.EventTypeWarning, "ObjectNotReconciledUnmappedNamespace", fmt.Sprintf("could not reconcile (resource: %s): %s", req, err))
 
-	for _, verb := range []string{"create", "patch", "update", "delete"} {
-		attr := &authorizationv1.ResourceAttributes{
-			Verb:     verb,
-			Group:    r.localGVK.Group,
-			Version:  r.localGVK.Version,
-			Resource: strings.ToLower(pluralize.NewClient().Plural(r.localGVK.Kind)),
-		}
-		selfSubjectAccessReview := authorizationv1.SelfSubjectAccessReview{
-			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
-				ResourceAttributes: attr,
-			},
+			return ctrl.Result{}, err
 		}
 
-		err = localClient.Create(ctx, &selfSubjectAccessReview)
-		if err != nil {
-			return errors.WrapIfWithDetails(err, "failed to create self subject access review", "attributes", attr)
-		}
+		msg := "no target namespace is mapped to the object, skipping"
+		r.recordEvent(corev1.EventTypeWarning, "ObjectSkippedUnmappedNamespace", fmt.Sprintf("%s (resource: %s)", msg, req))
+		log.Info(msg)
 
-		if !selfSubjectAccessReview.Status.Allowed {
-			return errors.Errorf("do not have local access to %s gvk: %s", attr.Verb, r.gvk)
-		}
+		return ctrl.Result{}, nil
 	}
+	if errors.Is(err, util.ErrEndpointsServiceNotSynced) {
+		// the endpoints are synced once the service is, their source object is not changed by then
+		r.recordEvent(corev1.EventTypeNormal, "ObjectNotReconciledMissingService", fmt.Sprintf("could not reconcile (resource: %s): %s", req, err))
+		log.Info("service of the endpoints is not synced yet, retrying")
 
-	return nil
-}
+		return ctrl.Result{RequeueAfter: time.Second * 30}, nil //nolint:gomnd
+	}
+	if errors.Is(err, util.ErrCABundleNotFound) {
+		// the webhooks are synced again by the watch of the CA bundle once it is created
+		r.recordEvent(corev1.EventTypeWarning, "ObjectNotReconciledMissingCABundle", fmt.Sprintf("could not reconcile (resource: %s): %s", req, err))
+		log.Info("CA bundle of the webhooks is not found, waiting for it", errors.GetDetails(err)...)
+
+		return ctrl.Result{}, nil
+	}
+	if errors.Is(err, util.ErrStorageClassNotFound) {
+		return r.waitForStorageClass(ctx, req.NamespacedName, getMissingStorageClass(err))
+	}
+	if errors.Is(err, util.ErrEndpointsServiceSelected) {
+		msg := "endpoints of the service are managed by the local endpoint controllers, skipping"
+		r.recordEvent(corev1.EventTypeWarning, "ObjectSkippedSelectedService", fmt.Sprintf("%s (resource: %s)", msg, req))
+		log.Info(msg)
 
-func (r *syncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	result, err := r.reconcile(ctx, req)
+		return ctrl.Result{}, nil
+	}
 	if err != nil {
-		r.localRecorder.Event(r.rule, corev1.EventTypeWarning, "ObjectNotReconciled", fmt.Sprintf("could not reconcile (resource: %s): %s", req, err.Error()))
+		return ctrl.Result{}, errors.WrapIf(err, "could not mutate object")
+	}
+	if err := r.releaseStorageClassWaitingObject(ctx, req.NamespacedName); err != nil {
+		return ctrl.Result{}, err
+	}
 
-		return result, err
+	// the Cluster resource of the local cluster and its credentials are never overwritten by the copies of other
+	// registries, even if the rule matches them
+	if self, err := r.isSelfClusterObject(ctx, obj); err != nil {
+		return ctrl.Result{}, errors.WrapIf(err, "could not check local cluster object")
+	} else if self {
+		msg := "object describes the local cluster, it is not overwritten by the synced one"
+		r.recordEvent(corev1.EventTypeWarning, "ObjectSkippedLocalCluster", fmt.Sprintf("%s (resource: %s)", msg, req))
+		log.Info(msg)
+		r.forgetSyncedVersion(req.NamespacedName)
+		r.reportObjectRemoved(req.NamespacedName)
+
+		return ctrl.Result{}, nil
 	}
 
-	return result, nil
-}
+	if rand.Float64() < sourceMappingValidationSampleRate { // nolint:gosec
+		r.validateSourceMapping(obj, req, log)
+	}
 
-func (r *syncReconciler) initObjectFromGVK(gvk schema.GroupVersionKind) client.Object {
-	var object client.Object
-	obj, err := r.localClient.Scheme().New(gvk)
+	parked, err := r.isObjectParked(ctx, req.NamespacedName, obj)
 	if err != nil {
-		object = &unstructured.Unstructured{}
-		object.GetObjectKind().SetGroupVersionKind(gvk)
-	} else {
-		object = obj.(client.Object) // nolint:forcetypeassert
+		return ctrl.Result{}, err
 	}
+	if parked {
+		log.V(1).Info("object is not synced until its conflicting immutable fields change")
 
-	return object
-}
+		return ctrl.Result{}, nil
+	}
 
-func (r *syncReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := r.GetLogger().WithValues("resource", req.NamespacedName)
+	if r.denyList.IsDenied(obj) {
+		msg := fmt.Sprintf("object is not synced, its kind or namespace is denied by the controller (resource: %s, gvk: %s, namespace: %s)", req.NamespacedName, obj.GetObjectKind().GroupVersionKind(), obj.GetNamespace())
 
-	obj := r.initObjectFromGVK(r.gvk)
-	obj.SetName(req.Name)
-	obj.SetNamespace(req.Namespace)
+		return ctrl.Result{}, r.blockObject(ctx, req.NamespacedName, "ObjectBlocked", msg, log)
+	}
+	// the namespace is checked after the mutations, so a namespace mapping can not route the object out of the
+	// allowed namespaces
+	if !r.isNamespaceAllowed(obj) {
+		msg := fmt.Sprintf("forbidden: object is not synced, its namespace is not allowed by the controller or the rule (resource: %s, gvk: %s, namespace: %s)", req.NamespacedName, obj.GetObjectKind().GroupVersionKind(), obj.GetNamespace())
 
-	// Mutate prior to check target namespace
-	err := r.GetClient().Get(ctx, req.NamespacedName, obj)
-	if apierrors.IsNotFound(err) || err == nil && !obj.GetDeletionTimestamp().IsZero() {
-		return ctrl.Result{}, r.deleteResource(ctx, obj, log)
+		return ctrl.Result{}, r.blockObject(ctx, req.NamespacedName, "ObjectForbiddenNamespace", msg, log)
 	}
-	if err != nil {
-		return ctrl.Result{}, errors.WrapIf(err, "could not get object")
+	if err := r.unblockObject(ctx, req.NamespacedName); err != nil {
+		return ctrl.Result{}, err
 	}
 
-	ok, matchedRules, err := r.rule.Match(obj)
-	if !ok {
-		return ctrl.Result{}, nil
+	// the objects beyond the quota of the rule are synced once the rule syncs fewer objects, they are enqueued then
+	if admitted, err := r.admitObject(ctx, req.NamespacedName, obj, log); err != nil || !admitted {
+		return ctrl.Result{}, err
 	}
+
+	contentHash, err := util.ContentHash(obj)
 	if err != nil {
-		return ctrl.Result{}, errors.WrapIf(err, "could not match object")
+		return ctrl.Result{}, errors.WrapIf(err, "could not compute content hash")
 	}
+	if r.isContentUnchanged(ctx, req.NamespacedName, obj, contentHash) {
+		log.V(1).Info("desired state is unchanged since the last sync, skipping")
+		r.releaseHeldWrite(req.NamespacedName)
+		if r.syncState != nil {
+			r.syncState.ObjectSynced(r.clusterName, r.getRule().GetName(), req.NamespacedName)
+		}
 
-	log.Info("reconciling", "gvk", r.gvk)
+		return ctrl.Result{}, nil
+	}
+	if delay, held := r.holdWrite(req.NamespacedName, contentHash, time.Now()); held {
+		log.V(1).Info("write is held to coalesce the changes of the object", "requeueAfter", delay)
 
-	obj, err = r.mutateObject(obj, matchedRules)
-	if err != nil {
-		return ctrl.Result{}, errors.WrapIf(err, "could not mutate object")
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+	util.SetContentHash(obj, contentHash)
+	if r.auditLog != nil {
+		auditHash, err := util.AuditHash(obj)
+		if err != nil {
+			return ctrl.Result{}, errors.WrapIf(err, "could not compute audit hash")
+		}
+		util.SetAuditHash(obj, auditHash)
 	}
 
 	// check namespace existence
@@ -248,7 +1307,7 @@ func (r *syncReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		if apierrors.IsNotFound(err) {
 			msg := "namespace does not exists locally"
 			localResource := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
-			r.localRecorder.Event(r.rule, corev1.EventTypeWarning, "ObjectNotReconciledMissingNamespace", fmt.Sprintf("could not reconcile (resource: %s, localResource: %s): %s", req, localResource, msg))
+			r.recordEvent(corev1.EventTypeWarning, "ObjectNotReconciledMissingNamespace", fmt.Sprintf("could not reconcile (resource: %s, localResource: %s): %s", req, localResource, msg))
 			log.Info(msg, "localResource", localResource.String())
 
 			return ctrl.Result{
@@ -264,7 +1323,7 @@ func (r *syncReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		}
 		if limited {
 			msg := "ratelimited, too frequent reconciles were happening for this object"
-			r.localRecorder.Event(r.rule, corev1.EventTypeWarning, "ObjectReconcileRateLimited", fmt.Sprintf("%s (resource: %s)", msg, req))
+			r.recordEvent(corev1.EventTypeWarning, "ObjectReconcileRateLimited", fmt.Sprintf("%s (resource: %s)", msg, req))
 			log.Info(msg)
 
 			return ctrl.Result{
@@ -273,34 +1332,84 @@ func (r *syncReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		}
 	}
 
-	rec := reconciler.NewGenericReconciler(
-		r.localClient,
-		log,
-		reconciler.ReconcilerOpts{
-			EnableRecreateWorkloadOnImmutableFieldChange: true,
-			Scheme: r.localClient.Scheme(),
-		},
-	)
+	if r.getRule().Spec.AnchorOwnership {
+		anchor, err := EnsureSyncAnchor(ctx, r.localMgr.GetAPIReader(), r.localClient, r.getRule().GetName(), obj.GetNamespace())
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		err = SetSyncAnchorOwnerReference(obj, anchor, r.localClient.Scheme())
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.runPreWriteHooks(ctx, req.NamespacedName, obj); err != nil {
+		return ctrl.Result{}, err
+	}
 
 	var desiredObject client.Object
 	if desiredObject, ok = obj.DeepCopyObject().(client.Object); !ok {
 		return ctrl.Result{}, errors.New("invalid object")
 	}
 
-	_, err = rec.ReconcileResource(obj, r.getObjectDesiredState())
-	if apierrors.IsAlreadyExists(errors.Cause(err)) {
-		log.Info("object already exists, requeue")
+	// the generic reconciler does not get the context, the span is carried into its requests by the client
+	reconcileCtx, span := r.startSpan(ctx, spanReconcileResource, req.NamespacedName)
+	rec := reconciler.NewGenericReconciler(withSpan(reconcileCtx, r.withLastAppliedSizeLimit(r.localClient)), log, r.getReconcilerOpts())
+	if err := r.writeLimiter.Acquire(reconcileCtx); err != nil {
+		tracing.End(span, err)
 
-		return ctrl.Result{
-			Requeue: true,
-		}, nil
+		return ctrl.Result{}, err
 	}
-	if err != nil {
+	writeStarted := time.Now()
+	r.startLocalWrite(req.NamespacedName)
+	defer r.endLocalWrite(req.NamespacedName)
+	_, err = rec.ReconcileResource(obj, r.getObjectDesiredState(ctx, matchedRules.GetMutationReplicas()))
+	syncedAt, _ := util.GetSyncedAt(obj)
+	immutable, recreated := util.IsImmutableObjectError(err), false
+	if immutable {
+		recreated, err = r.recreateImmutableObject(reconcileCtx, req, rec, desiredObject, log)
+	}
+	r.writeLimiter.Release()
+	if immutable && !recreated {
+		tracing.End(span, err)
+
+		return ctrl.Result{}, err
+	}
+	tracing.End(span, err)
+	if fields := util.ImmutableFieldCauses(err); len(fields) > 0 {
+		return ctrl.Result{}, r.parkObject(ctx, req.NamespacedName, desiredObject, fields, log)
+	}
+	if apierrors.IsAlreadyExists(errors.Cause(err)) && r.isCreateOnly() {
+		log.V(1).Info("object already exists, it is not adopted in the create-only sync mode")
+
+		return ctrl.Result{}, nil
+	}
+	if apierrors.IsAlreadyExists(errors.Cause(err)) {
+		adopted, result, err := r.adoptExistingObject(ctx, req, desiredObject, log)
+		if !adopted {
+			return result, err
+		}
+	} else if err != nil {
 		return ctrl.Result{}, errors.WrapIf(err, "could not reconcile object")
 	}
-	log.Info("object reconciled")
+	r.logSampled(log, "object reconciled")
+	// the synced at time is only stamped when the desired state is written
+	if r.auditLog != nil && !syncedAt.Before(writeStarted.Truncate(time.Second)) {
+		r.recordAudit(req, obj, sourceResourceVersion, syncedAt)
+	}
+
+	// the objects synced from the source are in use again
+	r.unblockSourceDeletions(ctx, req.NamespacedName)
+
+	// the object is already written into its current namespace, so the copies in the previous ones can be removed
+	if matchedRules.GetMutationNamespaceRouting() != nil && !r.isCreateOnly() {
+		if err := r.deleteStaleSyncedObjects(ctx, req.NamespacedName, obj, log); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 
-	err = r.localClient.Get(ctx, client.ObjectKey{
+	err = r.getSyncedObject(ctx, client.ObjectKey{
 		Name:      obj.GetName(),
 		Namespace: obj.GetNamespace(),
 	}, obj)
@@ -311,64 +1420,259 @@ func (r *syncReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{}, errors.WrapIf(err, "could not get object")
 	}
 
-	if matchedRules.GetMutationSyncStatus() {
+	if matchedRules.GetMutationSyncStatus() && !r.isCreateOnly() {
+		// the status is not written after the deadline, the object is synced in full again on the retry
+		if err := ctx.Err(); err != nil {
+			return ctrl.Result{}, errors.WrapIf(err, "status sync skipped")
+		}
+
+		if err := util.SetSyncedStatus(desiredObject, obj, matchedRules.GetMutationStatusSync()); err != nil {
+			return ctrl.Result{}, errors.WrapIf(err, "could not merge object status")
+		}
 		desiredObject.SetResourceVersion(obj.GetResourceVersion())
-		err = r.localClient.Status().Update(ctx, desiredObject)
+		statusCtx, span := r.startSpan(ctx, spanStatusSync, req.NamespacedName)
+		err = r.localClient.Status().Update(statusCtx, desiredObject)
+		tracing.End(span, err)
 		if err != nil {
 			return ctrl.Result{}, errors.WrapIf(err, "could not update object status")
 		}
+		obj.SetResourceVersion(desiredObject.GetResourceVersion())
+	}
+	r.setSyncedVersion(req.NamespacedName, sourceResourceVersion, desiredObject.GetObjectKind().GroupVersionKind(), obj, contentHash)
+	r.observeSyncLag(req, sourceObj)
+
+	r.recordSyncedEvent(req, obj)
+	if r.syncState != nil {
+		r.syncState.ObjectSynced(r.clusterName, r.getRule().GetName(), req.NamespacedName)
 	}
 
-	if r.rule.UID != "" {
-		r.localRecorder.Event(r.rule, corev1.EventTypeNormal, "ObjectReconciled", fmt.Sprintf("object reconciled (resource: %s)", req))
+	// the owners synced after the object are only referenced once it is synced again
+	if r.hasUnresolvedOwners(sourceObj, desiredObject) {
+		log.V(1).Info("owner references are not resolved yet, requeue", "requeueAfter", unresolvedOwnerInterval)
+
+		return ctrl.Result{RequeueAfter: unresolvedOwnerInterval}, nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
-func (r *syncReconciler) Start(ctx context.Context) error {
-	// set local cluster id
-	if r.localClusterID == "" {
-		localClusterID, err := GetClusterID(ctx, r.localClient)
-		if err != nil {
-			return errors.WrapIf(err, "could not get local cluster id")
-		}
-		r.localClusterID = string(localClusterID)
-		r.GetLogger().Info("set local cluster id", "id", r.localClusterID)
+// adoptExistingObject handles an object which exists locally without being written by the sync controller, e.g. after
+// the controller is migrated. An object identical to the desired one is adopted and treated as synced, otherwise the
+// conflict policy of the rule applies.
+func (r *syncReconciler) adoptExistingObject(ctx context.Context, req ctrl.Request, desired client.Object, log logr.Logger) (bool, ctrl.Result, error) {
+	existing := r.initObjectFromGVK(desired.GetObjectKind().GroupVersionKind())
+	// the cache has not seen the object yet, otherwise it would not have been created
+	err := r.localMgr.GetAPIReader().Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if apierrors.IsNotFound(err) {
+		log.Info("object already exists, requeue")
+
+		return false, ctrl.Result{Requeue: true}, nil
+	}
+	if err != nil {
+		return false, ctrl.Result{}, errors.WrapIf(err, "could not get existing object")
 	}
 
-	// init local informer
-	_, gvk := clusterregistryv1alpha1.MatchedRules(r.rule.Spec.Rules).GetMutatedGVK(schema.GroupVersionKind(r.rule.Spec.GVK))
-	obj := r.initObjectFromGVK(gvk)
-	err := r.initLocalInformer(ctx, obj)
+	// objects written by the sync controller are updated once the cache catches up
+	if existing.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation] != "" {
+		log.Info("object already exists, requeue")
+
+		return false, ctrl.Result{Requeue: true}, nil
+	}
+
+	// objects controlled by a local controller are left to it
+	if r.isLocallyControlled(existing) {
+		r.skipLocallyControlled(existing, log)
+
+		return false, ctrl.Result{}, nil
+	}
+	r.forgetLocallyControlled(client.ObjectKeyFromObject(existing))
+
+	driftedPaths, err := util.AdoptionDriftedPaths(desired, existing)
 	if err != nil {
+		return false, ctrl.Result{}, errors.WrapIf(err, "could not compare existing object")
+	}
+
+	localResource := client.ObjectKeyFromObject(existing)
+	if len(driftedPaths) == 0 {
+		if err := r.adoptObject(ctx, existing, desired); err != nil {
+			return false, ctrl.Result{}, err
+		}
+		r.recordEvent(corev1.EventTypeNormal, "ObjectAdopted", fmt.Sprintf("identical existing object adopted (resource: %s, localResource: %s)", req, localResource))
+		log.Info("identical existing object adopted")
+
+		return true, ctrl.Result{}, nil
+	}
+
+	msg := fmt.Sprintf("existing object differs (resource: %s, localResource: %s, paths: %s)", req, localResource, strings.Join(driftedPaths, ", "))
+	switch r.getRule().Spec.ConflictPolicy {
+	case clusterregistryv1alpha1.ConflictPolicySkip:
+		r.recordEvent(corev1.EventTypeWarning, "ObjectConflictSkipped", msg)
+		log.Info("existing object differs, skipping", "paths", driftedPaths)
+
+		return false, ctrl.Result{}, nil
+	case clusterregistryv1alpha1.ConflictPolicyOverwrite:
+		if err := r.adoptObject(ctx, existing, desired); err != nil {
+			return false, ctrl.Result{}, err
+		}
+		r.recordEvent(corev1.EventTypeNormal, "ObjectConflictOverwritten", msg)
+		log.Info("existing object differs, adopted to be overwritten", "paths", driftedPaths)
+
+		// the adopted object is updated like any synced object
+		return false, ctrl.Result{Requeue: true}, nil
+	case clusterregistryv1alpha1.ConflictPolicyRequeue:
+		fallthrough
+	default:
+		r.recordEvent(corev1.EventTypeWarning, "ObjectConflict", msg)
+		log.Info("existing object differs, requeue", "paths", driftedPaths)
+
+		return false, ctrl.Result{Requeue: true}, nil
+	}
+}
+
+// adoptObject writes the metadata of the synced objects onto the existing object
+func (r *syncReconciler) adoptObject(ctx context.Context, existing client.Object, desired client.Object) error {
+	base, ok := existing.DeepCopyObject().(client.Object)
+	if !ok {
+		return errors.New("invalid object")
+	}
+
+	util.AdoptObject(existing, desired)
+
+	err := r.localClient.Patch(ctx, existing, client.MergeFromWithOptions(base, client.MergeFromWithOptimisticLock{}))
+
+	return errors.WrapIf(err, "could not adopt existing object")
+}
+
+func (r *syncReconciler) reportObjectRemoved(key types.NamespacedName) {
+	if r.syncState != nil {
+		r.syncState.ObjectRemoved(r.clusterName, r.getRule().GetName(), key)
+	}
+}
+
+func (r *syncReconciler) Start(ctx context.Context) error {
+	// the objects are not reconciled until the local cluster id is resolved, the controller is started anyway
+	if _, err := r.localClusterID.Resolve(ctx, r.localMgr.GetAPIReader(), r.localRecorder); err != nil {
+		r.GetLogger().Error(err, "could not resolve local cluster id, retrying on reconcile")
+	}
+
+	// init local informer, or wait for the CRD of the local kind
+	if r.isWaitingForCRD() {
+		r.reportWaitingForCRD(ctx)
+	} else {
+		obj := r.initObjectFromGVK(r.getLocalGVK())
+		if err := r.initLocalInformer(ctx, obj); err != nil {
+			return errors.WithStackIf(err)
+		}
+	}
+	if err := r.initCABundleInformers(ctx); err != nil {
 		return errors.WithStackIf(err)
 	}
 
+	// the conditions reported by the previous controller of the rule are reset, the objects are reported again
+	// when they are reconciled
+	r.parkedMu.Lock()
+	conditions := []metav1.Condition{r.getImmutableConflictCondition()}
+	r.parkedMu.Unlock()
+	r.blockedMu.Lock()
+	conditions = append(conditions, r.getBlockedCondition())
+	r.blockedMu.Unlock()
+	r.overriddenMu.Lock()
+	conditions = append(conditions, r.getOverriddenCondition())
+	r.overriddenMu.Unlock()
+	r.storageClassMu.Lock()
+	conditions = append(conditions, r.getWaitingForStorageClassCondition())
+	r.storageClassMu.Unlock()
+	r.syncWindowMu.Lock()
+	conditions = append(conditions, r.getDeferredBySyncWindowCondition())
+	r.syncWindowMu.Unlock()
+	if !r.isWaitingForCRD() {
+		conditions = append(conditions, r.getWaitingForCRDCondition(false))
+	}
+
+	for _, condition := range conditions {
+		if err := r.setClusterCondition(ctx, condition); err != nil {
+			r.GetLogger().Error(err, "could not reset rule condition", "type", condition.Type)
+		}
+	}
+	if err := r.setTakeoverStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not reset taken over objects")
+	}
+	if err := r.setFailedObjectsStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not reset failed objects")
+	}
+	if err := r.setBlockedDeletionsStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not reset blocked deletions")
+	}
+	if err := r.setOversizedObjectsStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not reset oversized objects")
+	}
+
+	// the objects are counted again as they are reconciled by the new controller
+	if r.syncState != nil {
+		r.syncState.AddRule(r.clusterName, r.getRule().GetName())
+	}
+
+	if r.ruleRegistry != nil {
+		r.ruleRegistry.Register(r.clusterName, r.getRule(), r.enqueueOverriddenObjects)
+	}
+
+	// the objects synced before the restart are counted against the quota until they are counted again
+	r.objectQuota.Restore(r.getRule().GetName(), r.getRule().Status.ObjectCount)
+	r.objectQuota.OnRelease(r.getRule().GetName(), r.clusterName, r.enqueueQuotaRefusedObjects)
+
+	r.convergeMu.Lock()
+	r.startupDeadline = time.Now().Add(r.startupWindow)
+	r.convergeMu.Unlock()
+
+	r.watchSourceStatus(ctx)
+
+	go r.checkTakeovers(ctx)
+
+	r.startConvergence(ctx)
+
+	go r.checkAccessPeriodically(ctx)
+
+	go r.checkFreshness(ctx)
+
+	go r.reportCacheSyncing(ctx)
+
+	r.startVerification(ctx)
+
 	return nil
 }
 
+// SetupWithController registers the watches of the reconciler on the controller. Setting up the same controller again
+// is a no-op, while setting up a new one, e.g. after the previous one is restarted, starts from a clean state.
 func (r *syncReconciler) SetupWithController(ctx context.Context, ctrl controller.Controller) error {
-	err := r.ManagedReconciler.SetupWithController(ctx, ctrl)
-	if err != nil {
-		return err
+	r.setupMu.Lock()
+	defer r.setupMu.Unlock()
+
+	if r.ctrl != nil && r.ctrl != ctrl {
+		r.teardown()
 	}
 
-	localCache, err := r.createAndStartCache()
+	err := r.ManagedReconciler.SetupWithController(ctx, ctrl)
 	if err != nil {
 		return err
 	}
-	r.localCache = localCache
 
-	localClient, err := r.createClient(r.localMgr.GetConfig(), localCache)
-	if err != nil {
-		return err
+	if r.localCache == nil {
+		localCache, err := r.createAndStartCache()
+		if err != nil {
+			return err
+		}
+		r.localCache = localCache
+
+		localClient, err := r.createClient(r.getLocalWriteConfig(), localCache)
+		if err != nil {
+			return err
+		}
+		r.localClient = localClient
 	}
-	r.localClient = localClient
 
 	isObjectMatch := func(obj client.Object, gvk schema.GroupVersionKind) bool {
 		obj.GetObjectKind().SetGroupVersionKind(gvk)
-		ok, _, err := r.rule.Match(obj)
+		ok, _, err := r.matchRule(obj)
 		if err != nil {
 			r.GetLogger().Error(err, "could not match object")
 
@@ -378,24 +1682,29 @@ func (r *syncReconciler) SetupWithController(ctx context.Context, ctrl controlle
 		return ok
 	}
 
-	gvk := schema.GroupVersionKind(r.rule.Spec.GVK)
+	gvk := r.GetSourceGVK()
 	obj := r.initObjectFromGVK(gvk)
 
+	src, err := r.getSourceWatch(gvk, obj)
+	if err != nil {
+		return err
+	}
+
 	// set watcher for gvk
-	err = ctrl.Watch(
-		&source.Kind{
-			Type: obj,
-		},
-		handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
-			return []reconcile.Request{
-				{
-					NamespacedName: types.NamespacedName{
-						Name:      obj.GetName(),
-						Namespace: obj.GetNamespace(),
+	err = r.watch(ctrl, "remote/"+gvk.String(), src,
+		&pacedEventHandler{
+			EventHandler: handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+				return []reconcile.Request{
+					{
+						NamespacedName: types.NamespacedName{
+							Name:      obj.GetName(),
+							Namespace: obj.GetNamespace(),
+						},
 					},
-				},
-			}
-		}),
+				}
+			}),
+			getDelay: r.getStartupDelay,
+		},
 		predicate.Funcs{
 			CreateFunc: func(e event.CreateEvent) bool {
 				if r.isOwnedByUs(e.Object) {
@@ -424,6 +1733,11 @@ func (r *syncReconciler) SetupWithController(ctx context.Context, ctrl controlle
 					return false
 				}
 
+				// the objects opted out of the sync do not match anymore, but their synced objects are deleted
+				if r.isSyncOptInChanged(e.ObjectOld, e.ObjectNew) {
+					return true
+				}
+
 				return isObjectMatch(e.ObjectNew, gvk)
 			},
 			DeleteFunc: func(e event.DeleteEvent) bool {
@@ -446,9 +1760,12 @@ func (r *syncReconciler) SetupWithController(ctx context.Context, ctrl controlle
 		return err
 	}
 
-	err = ctrl.Watch(&InMemorySource{
-		reconciler: r,
-	}, handler.Funcs{})
+	// the objects of other kinds are not reconciled by the rule
+	err = r.watch(ctrl, "in-memory", r.triggers, handler.Funcs{}, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+
+		return gvk.Empty() || gvk.GroupKind() == r.GetSourceGVK().GroupKind()
+	}))
 	if err != nil {
 		return err
 	}
@@ -458,276 +1775,570 @@ func (r *syncReconciler) SetupWithController(ctx context.Context, ctrl controlle
 	return nil
 }
 
-func (r *syncReconciler) GetRule() *clusterregistryv1alpha1.ResourceSyncRule {
-	return r.rule
-}
+// watch registers the watch on the controller unless it is already registered with the same key,
+// it must be called with setupMu held
+func (r *syncReconciler) watch(ctrl controller.Controller, key string, src source.Source, h handler.EventHandler, predicates ...predicate.Predicate) error {
+	if _, ok := r.watches[key]; ok {
+		return nil
+	}
 
-func (r *syncReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
-	return errors.New("not implemented")
+	// the objects added to the queue by the handlers are recorded for the queue metrics
+	h = &observedEventHandler{
+		EventHandler: h,
+		observer:     r.queueObserver,
+	}
+	if err := ctrl.Watch(src, h, predicates...); err != nil {
+		return err
+	}
+
+	r.watches[key] = struct{}{}
+
+	return nil
 }
 
-func (r *syncReconciler) mutateObject(current client.Object, matchedRules clusterregistryv1alpha1.MatchedRules) (client.Object, error) {
-	var ok bool
+// Teardown stops the local cache and forgets the registered watches and the per object state, so the reconciler can
+// be set up again with a new controller. Watches can not be removed from a controller, they stop with the controller.
+func (r *syncReconciler) Teardown() {
+	r.setupMu.Lock()
+	defer r.setupMu.Unlock()
 
-	var obj client.Object
-	if obj, ok = current.DeepCopyObject().(client.Object); !ok {
-		return nil, errors.New("invalid object")
-	}
+	r.teardown()
+}
 
-	objAnnotations := obj.GetAnnotations()
-	if objAnnotations == nil {
-		objAnnotations = make(map[string]string)
+func (r *syncReconciler) teardown() {
+	if r.localCacheCancel != nil {
+		r.localCacheCancel()
 	}
+	r.localCacheCancel = nil
+	r.localCache = nil
+	r.localClient = nil
 
-	for k, v := range matchedRules.GetMutationAnnotations().Add {
-		objAnnotations[k] = v
-	}
+	r.ctrl = nil
+	r.queue = nil
+	r.queueObserver.reset()
+	r.watches = make(map[string]struct{})
 
-	for _, k := range matchedRules.GetMutationAnnotations().Remove {
-		delete(objAnnotations, k)
+	if r.sharedWatch != nil {
+		r.sharedWatches.release(r.sharedWatch, r)
 	}
+	r.sharedWatch = nil
+	r.sourceClient = nil
 
-	objLabels := obj.GetLabels()
-	if objLabels == nil {
-		objLabels = make(map[string]string)
-	}
+	r.parkedMu.Lock()
+	r.parkedObjects = make(map[types.NamespacedName]parkedObject)
+	r.parkedMu.Unlock()
 
-	for k, v := range matchedRules.GetMutationLabels().Add {
-		objLabels[k] = v
-	}
+	r.blockedMu.Lock()
+	r.blockedObjects = make(map[types.NamespacedName]struct{})
+	r.blockedMu.Unlock()
 
-	for _, k := range matchedRules.GetMutationLabels().Remove {
-		delete(objLabels, k)
-	}
+	r.syncedMu.Lock()
+	r.syncedVersions = make(map[types.NamespacedName]syncedVersion)
+	r.writingSources = make(map[types.NamespacedName]struct{})
+	r.syncedMu.Unlock()
 
-	if objAnnotations[clusterregistryv1alpha1.OwnershipAnnotation] == "" {
-		objAnnotations[clusterregistryv1alpha1.OwnershipAnnotation] = r.clusterID
-	}
+	r.deletionMu.Lock()
+	r.blockedDeletions = make(map[types.NamespacedName]blockedDeletion)
+	r.pendingDeletions = make(map[types.NamespacedName]time.Time)
+	r.deletionMu.Unlock()
 
-	if objLabels[clusterregistryv1alpha1.OwnershipAnnotation] == "" {
-		objLabels[clusterregistryv1alpha1.OwnershipAnnotation] = r.clusterID
-	}
+	r.oversizedMu.Lock()
+	r.oversizedObjects = make(map[types.NamespacedName]oversizedObject)
+	r.oversizedMu.Unlock()
 
-	if mutated, gvk := matchedRules.GetMutatedGVK(obj.GetObjectKind().GroupVersionKind()); mutated {
-		objAnnotations[clusterregistryv1alpha1.OriginalGVKAnnotation] = util.GVKToString(obj.GetObjectKind().GroupVersionKind())
-		obj.GetObjectKind().SetGroupVersionKind(gvk)
+	r.controlledMu.Lock()
+	r.locallyControlledObjects = make(map[types.NamespacedName]struct{})
+	r.controlledMu.Unlock()
+
+	r.forbiddenMu.Lock()
+	r.forbiddenObjects = make(map[types.NamespacedName]string)
+	r.forbiddenMu.Unlock()
+
+	r.storageClassMu.Lock()
+	r.waitingClaims = make(map[types.NamespacedName]string)
+	r.storageClassMu.Unlock()
+
+	r.syncWindowMu.Lock()
+	r.deferredObjects = make(map[types.NamespacedName]struct{})
+	r.syncWindowMu.Unlock()
+
+	r.heldWritesMu.Lock()
+	r.heldWrites = make(map[types.NamespacedName]heldWrite)
+	r.heldWritesMu.Unlock()
+
+	r.quotaMu.Lock()
+	r.quotaObjects = make(map[types.NamespacedName]types.NamespacedName)
+	r.quotaMovedObjects = make(map[types.NamespacedName]struct{})
+	r.quotaRefusedObjects = make(map[types.NamespacedName]struct{})
+	r.quotaMu.Unlock()
+
+	r.takeovers.Reset()
+	r.failures.Reset()
+}
+
+func (r *syncReconciler) GetRule() *clusterregistryv1alpha1.ResourceSyncRule {
+	return r.getRule()
+}
+
+func (r *syncReconciler) getRule() *clusterregistryv1alpha1.ResourceSyncRule {
+	r.ruleMu.RLock()
+	defer r.ruleMu.RUnlock()
+
+	return r.rule
+}
+
+func (r *syncReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	return errors.New("not implemented")
+}
+
+func (r *syncReconciler) mutateObject(ctx context.Context, current client.Object, matchedRules clusterregistryv1alpha1.MatchedRules) (client.Object, error) {
+	// the mutator is cheap to create, and the scope of the local kind is only known after the kind is served
+	obj, err := objectsync.NewMutator(r.getRule(), r.localClient.Scheme(),
+		objectsync.WithClusterName(r.clusterName),
+		objectsync.WithWriteFormatVersion(r.writeFormatVersion),
+		objectsync.WithLocalClusterScoped(r.isLocalClusterScoped()),
+		objectsync.WithTemplateData(func(current client.Object, obj client.Object) (map[string]interface{}, error) {
+			return r.getMutationTemplateData(ctx, current, obj)
+		}),
+		objectsync.WithOwnerReferenceResolver(r.ownerReferenceResolver(ctx)),
+		objectsync.WithServiceResolver(r.serviceResolver(ctx)),
+		objectsync.WithSourceNodeGetter(r.sourceNodeGetter(ctx)),
+		objectsync.WithCABundleResolver(r.caBundleResolver(ctx)),
+		objectsync.WithStorageClassChecker(r.storageClassChecker(ctx)),
+		objectsync.WithAutoscalerChecker(r.autoscalerChecker(ctx)),
+		objectsync.WithKeyRemovals(r.getKeyRemovals()),
+		objectsync.WithVersionFallback(r.getLocalVersionFallback()),
+		objectsync.WithLogger(r.GetLogger()),
+	).Mutate(current, matchedRules, r.clusterID)
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO: make these annotations as parameters, which can be specified
-	// by users, that way other annotations can be used as well and we can
-	// get rid of banzai specific annotations from the code
-	delete(objAnnotations, operatortoolstypes.BanzaiCloudManagedComponent)
-	delete(objAnnotations, operatortoolstypes.BanzaiCloudRelatedTo)
-	delete(objAnnotations, patch.LastAppliedConfig)
-	delete(objAnnotations, corev1.LastAppliedConfigAnnotation)
-	obj.SetAnnotations(objAnnotations)
-	obj.SetLabels(objLabels)
-
-	obj.SetGeneration(0)
-	obj.SetResourceVersion("")
-	obj.SetUID("")
-	obj.SetSelfLink("")
-	obj.SetCreationTimestamp(metav1.Time{})
-	obj.SetFinalizers(nil)
-	obj.SetOwnerReferences(nil)
-	obj.SetManagedFields(nil)
-
-	if patches := matchedRules.GetMutationOverrides(); len(patches) > 0 { // nolint:nestif
-		clusters, err := GetClusters(r.GetContext(), r.localClient)
+	r.setKeyMutated(obj.GetName() != current.GetName(), obj.GetNamespace() != current.GetNamespace())
+
+	return obj, nil
+}
+
+// hasUnresolvedOwners returns whether the rule remaps the owner references and some of the owners of the source object
+// are not synced locally yet, so their references were dropped
+func (r *syncReconciler) hasUnresolvedOwners(source client.Object, obj client.Object) bool {
+	return r.getRule().Spec.OwnerReferenceMode == clusterregistryv1alpha1.OwnerReferenceModeRemap &&
+		len(obj.GetOwnerReferences()) < len(source.GetOwnerReferences())
+}
+
+// ownerReferenceResolver returns the resolver of the owner references to the local copies of the owners synced from
+// the source cluster by any rule, they are looked up by the GVK and the key of the source owner
+func (r *syncReconciler) ownerReferenceResolver(ctx context.Context) objectsync.OwnerReferenceResolver {
+	return func(ref metav1.OwnerReference, sourceNamespace string, obj client.Object) (*metav1.OwnerReference, error) {
+		gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
+		clusterScoped, err := util.IsClusterScoped(r.localMgr.GetRESTMapper(), gvk)
+		if meta.IsNoMatchError(errors.Cause(err)) {
+			return nil, nil
+		}
 		if err != nil {
-			return nil, errors.WrapIf(err, "could not get clusters")
+			return nil, err
 		}
 
-		var localCluster clusterregistryv1alpha1.Cluster
-		if localCluster, ok = clusters[types.UID(r.localClusterID)]; !ok {
-			return nil, errors.NewWithDetails("could not find local cluster by id", "id", r.localClusterID)
+		source := types.NamespacedName{Name: ref.Name, Namespace: sourceNamespace}
+		opts := []client.ListOption{
+			client.MatchingLabels(map[string]string{
+				clusterregistryv1alpha1.OwnershipAnnotation: r.clusterID,
+			}),
+		}
+		if clusterScoped {
+			source.Namespace = ""
+		} else {
+			opts = append(opts, client.InNamespace(obj.GetNamespace()))
 		}
 
-		syncedClusterID := types.UID(r.clusterID)
-		if clusterID, ok := current.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation]; ok {
-			syncedClusterID = types.UID(clusterID)
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		// the owners can be of any kind, they are not cached
+		if err := r.localMgr.GetAPIReader().List(ctx, list, opts...); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not list local owners", "gvk", gvk.String())
 		}
-		var syncedCluster clusterregistryv1alpha1.Cluster
-		if syncedCluster, ok = clusters[syncedClusterID]; !ok {
-			return nil, errors.NewWithDetails("could not find synced cluster by id", "id", r.localClusterID)
+
+		for i := range list.Items {
+			owner := &list.Items[i]
+			if util.GetSourceObjectKey(owner) != source {
+				continue
+			}
+
+			resolved := ref.DeepCopy()
+			resolved.Name = owner.GetName()
+			resolved.UID = owner.GetUID()
+
+			return resolved, nil
 		}
 
-		modifiedPatches, err := util.K8SResourceOverlayPatchExecuteTemplates(patches, map[string]interface{}{
-			"Object":       obj,
-			"Cluster":      syncedCluster.DeepCopy(),
-			"LocalCluster": localCluster.DeepCopy(),
-		})
-		if err != nil {
-			return nil, errors.WrapIf(err, "could not execute templates on patches")
+		return nil, nil
+	}
+}
+
+// serviceResolver returns the resolver of the local copies of the Services synced from the source cluster by any rule,
+// the EndpointSlices and the Endpoints are attached to them
+func (r *syncReconciler) serviceResolver(ctx context.Context) objectsync.ServiceResolver {
+	return func(key types.NamespacedName, obj client.Object) (*corev1.Service, error) {
+		services := &corev1.ServiceList{}
+		if err := r.localMgr.GetAPIReader().List(ctx, services, client.InNamespace(obj.GetNamespace()), client.MatchingLabels(map[string]string{
+			clusterregistryv1alpha1.OwnershipAnnotation: r.clusterID,
+		})); err != nil {
+			return nil, errors.WrapIf(err, "could not list local services")
 		}
-		patches = modifiedPatches
 
-		gvk := resources.ConvertGVK(obj.GetObjectKind().GroupVersionKind())
-		patchFunc, err := resources.PatchYAMLModifier(resources.K8SResourceOverlay{
-			GVK:     &gvk,
-			Patches: patches,
-		}, resources.NewObjectParser(r.localClient.Scheme()))
-		if err != nil {
-			return nil, errors.WrapIf(err, "could not get patch func for object")
+		for i := range services.Items {
+			if util.GetSourceObjectKey(&services.Items[i]) == key {
+				return &services.Items[i], nil
+			}
 		}
 
-		patchedObject, err := patchFunc(obj)
+		return nil, nil
+	}
+}
+
+// sourceNodeGetter returns the getter of the nodes of the source cluster the endpoints are on
+func (r *syncReconciler) sourceNodeGetter(ctx context.Context) util.NodeGetter {
+	return func(name string) (*corev1.Node, error) {
+		node := &corev1.Node{}
+		err := r.GetClient().Get(ctx, types.NamespacedName{Name: name}, node)
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
 		if err != nil {
-			return nil, errors.WrapIf(err, "could not patch object")
+			return nil, errors.WrapIf(err, "could not get source node")
 		}
 
-		if obj, ok = patchedObject.(client.Object); !ok {
-			return nil, errors.New("invalid object")
-		}
+		return node, nil
 	}
+}
 
-	if current.GetName() != obj.GetName() {
-		objLabels := obj.GetLabels()
-		if objLabels == nil {
-			objLabels = make(map[string]string)
-		}
-		objLabels[originalNameLabel] = current.GetName()
-		obj.SetLabels(objLabels)
-		r.resourceNameMutated = true
-	}
-	if current.GetNamespace() != obj.GetNamespace() {
-		objLabels := obj.GetLabels()
-		if objLabels == nil {
-			objLabels = make(map[string]string)
-		}
-		objLabels[originalNamespaceLabel] = current.GetNamespace()
-		obj.SetLabels(objLabels)
-		r.resourceNamespaceMutated = true
+// validateSourceMapping checks whether the local informers would map the mutated object back to the reconciled one
+func (r *syncReconciler) validateSourceMapping(obj client.Object, req ctrl.Request, log logr.Logger) {
+	err := util.ValidateSourceMapping(obj, r.GetSourceGVK(), req.NamespacedName)
+	if err == nil {
+		return
 	}
 
-	return obj, nil
+	sourceMappingViolationsTotal.WithLabelValues(r.getRule().GetName(), r.clusterID).Inc()
+	log.Error(err, "source mapping violation", errors.GetDetails(err)...)
 }
 
-func (r *syncReconciler) getObjectByOriginalNamespaceAndName(ctx context.Context, obj client.Object, gvk schema.GroupVersionKind) (bool, client.Object, error) {
-	var err error
+func (r *syncReconciler) getMutationTemplateData(ctx context.Context, current client.Object, obj client.Object) (map[string]interface{}, error) {
+	clusters, err := GetClusters(ctx, r.localClient)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not get clusters")
+	}
 
-	objects := &unstructured.UnstructuredList{}
-	objects.SetGroupVersionKind(schema.GroupVersionKind{
-		Group:   gvk.Group,
-		Kind:    fmt.Sprintf("%sList", gvk.Kind),
-		Version: gvk.Version,
-	})
-	name := obj.GetName()
-	namespace := obj.GetNamespace()
+	var ok bool
+	var localCluster clusterregistryv1alpha1.Cluster
+	if localCluster, ok = clusters[r.localClusterID.Get()]; !ok {
+		return nil, errors.NewWithDetails("could not find local cluster by id", "id", r.getLocalClusterID())
+	}
 
-	if originalName, ok := obj.GetLabels()[originalNameLabel]; ok {
-		name = originalName
+	syncedClusterID := types.UID(r.clusterID)
+	if clusterID, ok := current.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation]; ok {
+		syncedClusterID = types.UID(clusterID)
 	}
-	if originalNamespace, ok := obj.GetLabels()[originalNamespaceLabel]; ok {
-		namespace = originalNamespace
+	var syncedCluster clusterregistryv1alpha1.Cluster
+	if syncedCluster, ok = clusters[syncedClusterID]; !ok {
+		return nil, errors.NewWithDetails("could not find synced cluster by id", "id", r.getLocalClusterID())
 	}
 
-	err = r.localClient.List(ctx, objects, client.InNamespace(namespace), client.MatchingLabels(map[string]string{
-		originalNameLabel: name,
-		clusterregistryv1alpha1.OwnershipAnnotation: r.clusterID,
-	}))
-	if err != nil {
-		return false, nil, err
+	return objectsync.NewTemplateData(obj, syncedCluster.DeepCopy(), localCluster.DeepCopy()), nil
+}
+
+// getSyncedObjects returns the objects synced from the source object, which might be renamed or moved into another namespace
+func (r *syncReconciler) getSyncedObjects(ctx context.Context, source types.NamespacedName, gvk schema.GroupVersionKind) ([]client.Object, error) {
+	listOptions := [][]client.ListOption{
+		{
+			client.InNamespace(source.Namespace),
+			client.MatchingLabels(map[string]string{
+				clusterregistryv1alpha1.OwnershipAnnotation: r.clusterID,
+			}),
+		},
+	}
+	if _, namespaceMutated := r.isKeyMutated(); namespaceMutated && source.Namespace != "" {
+		listOptions = append(listOptions, []client.ListOption{
+			client.MatchingLabels(map[string]string{
+				clusterregistryv1alpha1.OriginalNamespaceLabel: source.Namespace,
+				clusterregistryv1alpha1.OwnershipAnnotation:    r.clusterID,
+			}),
+		})
+	}
+
+	objects := make([]client.Object, 0)
+	seen := make(map[types.NamespacedName]struct{})
+	for _, opts := range listOptions {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   gvk.Group,
+			Kind:    fmt.Sprintf("%sList", gvk.Kind),
+			Version: gvk.Version,
+		})
+
+		if err := r.localClient.List(ctx, list, opts...); err != nil {
+			return nil, err
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			key := client.ObjectKeyFromObject(obj)
+			if _, ok := seen[key]; ok || util.GetSourceObjectKey(obj) != source {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			objects = append(objects, obj)
+		}
 	}
 
-	if len(objects.Items) > 1 {
-		return false, nil, errors.New("multiple renamed objects were found")
+	return objects, nil
+}
+
+// deleteStaleSyncedObjects deletes the objects synced from the source object other than the current one,
+// e.g. the ones left in the previous namespace after the namespace routing of the rule changed
+func (r *syncReconciler) deleteStaleSyncedObjects(ctx context.Context, source types.NamespacedName, current client.Object, log logr.Logger) error {
+	objects, err := r.getSyncedObjects(ctx, source, current.GetObjectKind().GroupVersionKind())
+	if err != nil {
+		return errors.WrapIf(err, "could not list synced objects")
 	}
 
-	if len(objects.Items) == 1 {
-		return true, objects.Items[0].DeepCopy(), nil
+	for _, obj := range util.GetStaleSyncedObjects(objects, source, client.ObjectKeyFromObject(current)) {
+		deleted, err := r.deleteSyncedObject(ctx, obj, log)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			r.recordEvent(corev1.EventTypeNormal, "ObjectMigrated", fmt.Sprintf("object is moved from namespace %s to %s (resource: %s)", obj.GetNamespace(), current.GetNamespace(), source))
+		}
 	}
 
-	return false, nil, nil
+	return nil
 }
 
 func (r *syncReconciler) deleteResource(ctx context.Context, obj client.Object, log logr.Logger) error {
-	var object, current client.Object
+	var object client.Object
 	var ok bool
 	if object, ok = obj.DeepCopyObject().(client.Object); !ok {
 		return errors.New("invalid object")
 	}
 
-	isGVKMutated := r.gvk != r.localGVK
-	if isGVKMutated {
-		object.GetObjectKind().SetGroupVersionKind(r.localGVK)
-	}
+	r.gvkMu.RLock()
+	gvk, localGVK := r.gvk, r.localGVK
+	r.gvkMu.RUnlock()
 
-	if current, ok = object.DeepCopyObject().(client.Object); !ok {
-		return errors.New("invalid object")
+	if gvk != localGVK {
+		object.GetObjectKind().SetGroupVersionKind(localGVK)
 	}
 
-	if r.resourceNamespaceMutated || r.resourceNameMutated { // nolint:nestif
-		if ok, obj, err := r.getObjectByOriginalNamespaceAndName(ctx, obj, current.GetObjectKind().GroupVersionKind()); err != nil {
+	var objects []client.Object
+	if nameMutated, namespaceMutated := r.isKeyMutated(); nameMutated || namespaceMutated {
+		var err error
+		objects, err = r.getSyncedObjects(ctx, client.ObjectKeyFromObject(obj), object.GetObjectKind().GroupVersionKind())
+		if err != nil {
 			return err
-		} else if ok {
-			current = obj
-		} else {
-			return nil
 		}
 	} else {
-		err := r.localClient.Get(ctx, types.NamespacedName{
+		key := types.NamespacedName{
 			Name:      obj.GetName(),
 			Namespace: obj.GetNamespace(),
-		}, current)
+		}
+		if r.isLocalClusterScoped() {
+			key.Namespace = ""
+		}
+
+		err := r.localClient.Get(ctx, key, object)
 		if apierrors.IsNotFound(err) {
+			r.unblockSourceDeletions(ctx, client.ObjectKeyFromObject(obj))
+
 			return nil
 		} else if err != nil {
 			return err
 		}
+
+		objects = []client.Object{object}
+	}
+
+	// the objects deleted in the meantime are not blocked anymore
+	r.unblockSourceDeletions(ctx, client.ObjectKeyFromObject(obj), objects...)
+
+	for _, current := range objects {
+		// the object is synced from the instance recreated after the deleted one
+		if !util.IsSyncedFrom(current, obj.GetUID()) {
+			log.V(1).Info("deletion is skipped, object is synced from another instance of the source object", "sourceUID", util.GetSourceUID(current))
+
+			continue
+		}
+
+		if _, err := r.deleteSyncedObject(ctx, current, log); err != nil {
+			return err
+		}
 	}
 
+	return nil
+}
+
+// deleteSyncedObject deletes the object unless it is protected, owned by this or another live cluster,
+// or written in an unsupported format
+func (r *syncReconciler) deleteSyncedObject(ctx context.Context, current client.Object, log logr.Logger) (bool, error) {
 	log = log.WithValues("resource", types.NamespacedName{
 		Name:      current.GetName(),
 		Namespace: current.GetNamespace(),
 	})
 
+	if r.denyList.IsGVKDenied(current.GetObjectKind().GroupVersionKind()) || r.denyList.IsNamespaceProtected(current.GetNamespace()) {
+		log.Info("deletion is skipped, the kind or the namespace of the object is denied")
+
+		return false, nil
+	}
+
+	if !r.isNamespaceAllowed(current) {
+		msg := fmt.Sprintf("forbidden: synced object is not deleted, its namespace is not allowed by the controller or the rule (resource: %s, namespace: %s)", client.ObjectKeyFromObject(current), current.GetNamespace())
+		r.recordEvent(corev1.EventTypeWarning, "ObjectDeletionForbiddenNamespace", msg)
+		log.Info(msg)
+
+		return false, nil
+	}
+
 	ownerClusterID := current.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation]
 
 	if ownerClusterID == "" {
 		log.V(1).Info("deletion is skipped, object is owned by this cluster")
 
-		return nil
+		return false, nil
 	}
 
 	if r.isOwnedByAnotherAliveCluster(ownerClusterID) {
 		log.V(1).Info("deletion is skipped, owned by another live cluster")
 
-		return nil
+		return false, nil
+	}
+
+	if r.isOwnedByAnotherRule(ctx, current) {
+		log.V(1).Info("deletion is skipped, owned by another rule", "rule", util.GetOwnerRule(current))
+
+		return false, nil
+	}
+
+	if _, err := util.GetFormatVersion(current.GetAnnotations()); err != nil {
+		log.V(1).Info("deletion is skipped, object is written in an unsupported format", errors.GetDetails(err)...)
+
+		return false, nil
+	}
+
+	if r.isDeleteProtected(current) {
+		r.blockDeletion(ctx, current, log)
+
+		return false, nil
 	}
 
-	err := r.localClient.Delete(ctx, current)
+	err := r.localClient.Delete(ctx, current, r.getDeleteOptions()...)
 	if err != nil && !apierrors.IsNotFound(err) {
-		return err
+		return false, err
+	}
+
+	// the object is only gone once the preserved finalizers are removed, until then its deletion is retried
+	if finalizers := r.getRule().Spec.GetPreservedFinalizers(current.GetFinalizers()); err == nil && len(finalizers) > 0 {
+		return false, errors.WithDetails(ErrDeletionPendingFinalizers, "resource", client.ObjectKeyFromObject(current), "finalizers", finalizers)
 	}
 
+	key := client.ObjectKeyFromObject(current)
+	r.unblockDeletions(ctx, func(blocked types.NamespacedName, _ blockedDeletion) bool {
+		return blocked == key
+	})
+
 	log.Info("object deleted")
 
-	return nil
+	if err := r.releaseDeletedQuotaObject(ctx, current); err != nil {
+		log.Error(err, "could not release object quota")
+	}
+
+	return true, nil
 }
 
 func (r *syncReconciler) isOwnedByAnotherAliveCluster(ownerClusterID string) bool {
 	return ownerClusterID != "" && r.clustersManager.GetAliveClustersByID()[ownerClusterID] != nil && ownerClusterID != r.clusterID
 }
 
+// isOwnedByAnotherRule returns whether the object was written by another existing rule, the objects of deleted rules
+// are taken over by the rules still syncing them
+func (r *syncReconciler) isOwnedByAnotherRule(ctx context.Context, obj metav1.Object) bool {
+	owner := util.GetOwnerRule(obj)
+	if owner == "" || owner == r.getRule().GetName() {
+		return false
+	}
+
+	rule, key, _ := getRuleObject(owner)
+	err := r.localMgr.GetClient().Get(ctx, key, rule)
+
+	// the object is left alone if the owner rule could not be checked
+	return !apierrors.IsNotFound(err)
+}
+
+// isLocallyControlled returns whether the object was not written by the sync controller and is controlled by a local
+// controller through its owner references. The rule can override the local owners.
+func (r *syncReconciler) isLocallyControlled(obj metav1.Object) bool {
+	if r.getRule().Spec.OverrideLocalOwners || obj.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation] != "" {
+		return false
+	}
+
+	return metav1.GetControllerOf(obj) != nil
+}
+
+// skipLocallyControlled records an OwnedByOtherController event the first time the locally controlled object is
+// skipped, until the object is synced again
+func (r *syncReconciler) skipLocallyControlled(obj metav1.Object, log logr.Logger) {
+	key := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+
+	r.controlledMu.Lock()
+	if _, ok := r.locallyControlledObjects[key]; ok {
+		r.controlledMu.Unlock()
+		log.V(1).Info("existing object is controlled by a local controller, skipping", "localResource", key)
+
+		return
+	}
+	r.locallyControlledObjects[key] = struct{}{}
+	r.controlledMu.Unlock()
+
+	var controllerRef string
+	if controller := metav1.GetControllerOf(obj); controller != nil {
+		controllerRef = fmt.Sprintf("%s %s", controller.Kind, controller.Name)
+	}
+
+	msg := fmt.Sprintf("existing object is controlled by a local controller, it is not synced (localResource: %s, controller: %s)", key, controllerRef)
+	r.recordEvent(corev1.EventTypeWarning, "OwnedByOtherController", msg)
+	log.Info(msg)
+}
+
+// forgetLocallyControlled forgets the skipped object once it is no longer controlled by a local controller
+func (r *syncReconciler) forgetLocallyControlled(key types.NamespacedName) {
+	r.controlledMu.Lock()
+	delete(r.locallyControlledObjects, key)
+	r.controlledMu.Unlock()
+}
+
 func (r *syncReconciler) isOwnedByUs(object client.Object) bool {
-	return object.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation] == r.localClusterID
+	return object.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation] == r.getLocalClusterID()
 }
 
-func (r *syncReconciler) getObjectDesiredState() *reconciler.DynamicDesiredState {
+// getObjectDesiredState returns the desired state of the synced objects, the replicas removed by the replica mutations
+// are kept on update
+func (r *syncReconciler) getObjectDesiredState(ctx context.Context, replicas *clusterregistryv1alpha1.ReplicaMutations) *reconciler.DynamicDesiredState {
 	return &reconciler.DynamicDesiredState{
 		BeforeUpdateFunc: func(current, desired runtime.Object) error {
-			for _, f := range []func(current, desired runtime.Object) error{
-				reconciler.ServiceIPModifier,
-			} {
-				err := f(current, desired)
-				if err != nil {
-					return err
-				}
+			if err := beforeObjectUpdate(current, desired, replicas); err != nil {
+				return err
+			}
+
+			if r.hasPatchStrategy() {
+				return forceObjectUpdate(current)
 			}
 
 			return nil
 		},
+		BeforeCreateFunc: func(desired runtime.Object) error {
+			return setSyncedAt(nil, desired)
+		},
 		ShouldCreateFunc: func(desired runtime.Object) (bool, error) {
 			metaObj, err := meta.Accessor(desired)
 			if err != nil {
@@ -741,7 +2352,7 @@ func (r *syncReconciler) getObjectDesiredState() *reconciler.DynamicDesiredState
 
 			ownerClusterID := metaObj.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation]
 			// the resource is coming from through an intermediary but marked as owned by this cluster
-			if ownerClusterID != "" && r.localClusterID == ownerClusterID {
+			if ownerClusterID != "" && r.getLocalClusterID() == ownerClusterID {
 				return false, nil
 			}
 
@@ -753,6 +2364,11 @@ func (r *syncReconciler) getObjectDesiredState() *reconciler.DynamicDesiredState
 			return true, nil
 		},
 		ShouldUpdateFunc: func(current, desired runtime.Object) (bool, error) {
+			// the object is owned by the local cluster once created
+			if r.isCreateOnly() {
+				return false, nil
+			}
+
 			metaObj, err := meta.Accessor(current)
 			if err != nil {
 				return false, err
@@ -763,58 +2379,391 @@ func (r *syncReconciler) getObjectDesiredState() *reconciler.DynamicDesiredState
 				return false, nil
 			}
 
+			// this resource is controlled by a local controller, which would fight back
+			if r.isLocallyControlled(metaObj) {
+				r.skipLocallyControlled(metaObj, r.GetLogger())
+
+				return false, nil
+			}
+			r.forgetLocallyControlled(types.NamespacedName{Name: metaObj.GetName(), Namespace: metaObj.GetNamespace()})
+
 			// this resources is owned by this cluster
 			ownerClusterID := metaObj.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation]
 			if ownerClusterID == "" {
 				return false, nil
 			}
 
+			key := types.NamespacedName{
+				Name:      metaObj.GetName(),
+				Namespace: metaObj.GetNamespace(),
+			}
+
 			// this resource is owned by another live cluster - sync is only allowed from that cluster
 			if r.isOwnedByAnotherAliveCluster(ownerClusterID) {
+				r.handBackObject(ctx, key)
+
+				return false, nil
+			}
+
+			// this resource was written by a newer controller in a format we do not understand
+			if _, err := util.GetFormatVersion(metaObj.GetAnnotations()); err != nil {
+				return false, nil
+			}
+
+			// this resource is written by another rule - objects written before the owner rule was recorded are
+			// upgraded in place by the update
+			if r.isOwnedByAnotherRule(ctx, metaObj) {
 				return false, nil
 			}
 
+			// the cluster owning this resource is not alive, it is updated from this cluster until the owner is back
+			if ownerClusterID != r.clusterID && ownerClusterID != r.getLocalClusterID() {
+				if r.isDeleteProtected(metaObj) {
+					r.recordEvent(corev1.EventTypeWarning, "ObjectTakeoverBlocked", fmt.Sprintf("owner cluster is not alive, but the object is protected by the %s annotation, it is not taken over (resource: %s, owner: %s)", clusterregistryv1alpha1.DeleteProtectedAnnotation, key, ownerClusterID))
+
+					return false, nil
+				}
+				r.takeOverObject(ctx, key, ownerClusterID)
+			}
+
+			// the objects written without the last applied annotation can not be compared with it
+			if unchanged, err := r.isLargeObjectUnchanged(current, desired); err != nil || unchanged {
+				return false, err
+			}
+
+			if r.hasPatchStrategy() {
+				return r.hasPatchDiff(current, desired, replicas)
+			}
+
 			return true, nil
 		},
 	}
 }
 
+// beforeObjectUpdate prepares the desired object for the update of the current one, the replicas removed by the
+// replica mutations and the synced at time of an unchanged desired state are kept
+func beforeObjectUpdate(current, desired runtime.Object, replicas *clusterregistryv1alpha1.ReplicaMutations) error {
+	for _, f := range []func(current, desired runtime.Object) error{
+		reconciler.ServiceIPModifier,
+		keepReplicas(replicas),
+		setSyncedAt,
+	} {
+		err := f(current, desired)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *syncReconciler) getReconcilerOpts() reconciler.ReconcilerOpts {
+	opts := reconciler.ReconcilerOpts{
+		EnableRecreateWorkloadOnImmutableFieldChange: true,
+		Scheme: r.localClient.Scheme(),
+	}
+
+	switch r.getRule().Spec.RecreatePolicy {
+	case clusterregistryv1alpha1.RecreatePolicyAlways:
+		opts.RecreateEnabledResourceCondition = func(_ schema.GroupVersionKind, _ metav1.Status) bool {
+			return true
+		}
+	case clusterregistryv1alpha1.RecreatePolicyNever:
+		opts.EnableRecreateWorkloadOnImmutableFieldChange = false
+	case clusterregistryv1alpha1.RecreatePolicyWorkloads:
+	}
+
+	return opts
+}
+
+// parkObject stops syncing the object until the values of the immutable fields rejected by the API server change
+func (r *syncReconciler) parkObject(ctx context.Context, key types.NamespacedName, obj client.Object, fields []string, log logr.Logger) error {
+	values, err := util.GetFieldValues(obj, fields)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("immutable fields changed, the object is not synced until they are restored or the recreate policy allows recreating it (resource: %s, fields: %s)", key, strings.Join(fields, ", "))
+	r.recordEvent(corev1.EventTypeWarning, "ObjectImmutableFieldConflict", msg)
+	log.Info(msg)
+
+	r.parkedMu.Lock()
+	r.parkedObjects[key] = parkedObject{
+		fields: fields,
+		values: values,
+	}
+	condition := r.getImmutableConflictCondition()
+	r.parkedMu.Unlock()
+
+	return r.setClusterCondition(ctx, condition)
+}
+
+// isObjectParked returns whether the object is parked and the conflicting immutable fields of its desired state
+// are unchanged. Objects with changed fields are unparked, so they are synced again.
+func (r *syncReconciler) isObjectParked(ctx context.Context, key types.NamespacedName, obj client.Object) (bool, error) {
+	r.parkedMu.Lock()
+	parked, ok := r.parkedObjects[key]
+	r.parkedMu.Unlock()
+	if !ok {
+		return false, nil
+	}
+
+	values, err := util.GetFieldValues(obj, parked.fields)
+	if err != nil {
+		return false, err
+	}
+	if reflect.DeepEqual(values, parked.values) {
+		return true, nil
+	}
+
+	return false, r.unparkObject(ctx, key)
+}
+
+func (r *syncReconciler) unparkObject(ctx context.Context, key types.NamespacedName) error {
+	r.parkedMu.Lock()
+	if _, ok := r.parkedObjects[key]; !ok {
+		r.parkedMu.Unlock()
+
+		return nil
+	}
+	delete(r.parkedObjects, key)
+	condition := r.getImmutableConflictCondition()
+	r.parkedMu.Unlock()
+
+	return r.setClusterCondition(ctx, condition)
+}
+
+// getImmutableConflictCondition must be called with parkedMu held
+func (r *syncReconciler) getImmutableConflictCondition() metav1.Condition {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeAdoptionImmutableConflict,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "NoImmutableFieldConflicts",
+		Message:            "every object is synced",
+	}
+
+	if len(r.parkedObjects) == 0 {
+		return condition
+	}
+
+	objects := make([]string, 0, len(r.parkedObjects))
+	for key, parked := range r.parkedObjects {
+		objects = append(objects, fmt.Sprintf("%s (%s)", key, strings.Join(parked.fields, ", ")))
+	}
+	sort.Strings(objects)
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "ImmutableFieldsChanged"
+	condition.Message = fmt.Sprintf("immutable fields changed, objects are not synced until the fields are restored or the recreate policy allows recreating them: %s", strings.Join(objects, "; "))
+
+	return condition
+}
+
+// blockObject skips the object, since it would be written into a protected namespace or a namespace not allowed by the
+// controller, or it is of a denied kind
+func (r *syncReconciler) blockObject(ctx context.Context, key types.NamespacedName, reason string, msg string, log logr.Logger) error {
+	r.recordEvent(corev1.EventTypeWarning, reason, msg)
+	log.Info(msg)
+
+	r.blockedMu.Lock()
+	r.blockedObjects[key] = struct{}{}
+	condition := r.getBlockedCondition()
+	r.blockedMu.Unlock()
+
+	return r.setClusterCondition(ctx, condition)
+}
+
+func (r *syncReconciler) unblockObject(ctx context.Context, key types.NamespacedName) error {
+	r.blockedMu.Lock()
+	if _, ok := r.blockedObjects[key]; !ok {
+		r.blockedMu.Unlock()
+
+		return nil
+	}
+	delete(r.blockedObjects, key)
+	condition := r.getBlockedCondition()
+	r.blockedMu.Unlock()
+
+	return r.setClusterCondition(ctx, condition)
+}
+
+// getBlockedCondition must be called with blockedMu held
+func (r *syncReconciler) getBlockedCondition() metav1.Condition {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeRuleBlocked,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "NoBlockedObjects",
+		Message:            "no object is blocked",
+	}
+
+	if len(r.blockedObjects) == 0 {
+		return condition
+	}
+
+	objects := make([]string, 0, len(r.blockedObjects))
+	for key := range r.blockedObjects {
+		objects = append(objects, key.String())
+	}
+	sort.Strings(objects)
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "ObjectsBlocked"
+	condition.Message = fmt.Sprintf("objects are not synced, their kind or namespace is denied or not allowed by the controller: %s", strings.Join(objects, "; "))
+
+	return condition
+}
+
+// takeOverObject reports that the object is updated from this cluster, although it is owned by another one
+func (r *syncReconciler) takeOverObject(ctx context.Context, key types.NamespacedName, ownerClusterID string) {
+	if !r.takeovers.TakeOver(key, ownerClusterID, time.Now()) {
+		return
+	}
+
+	msg := fmt.Sprintf("owner cluster is not alive, the object is updated from this cluster until the owner is back (resource: %s, owner: %s)", key, ownerClusterID)
+	if status, ok := r.clustersManager.GetProbeStatusByID(ownerClusterID); ok && !status.Reachable {
+		msg = fmt.Sprintf("%s, owner is unreachable since %s: %s", msg, status.LastTransitionTime.Format(time.RFC3339), status.LastError)
+	}
+	r.recordEvent(corev1.EventTypeWarning, "OwnershipTakenOver", msg)
+	r.GetLogger().Info(msg)
+
+	if err := r.setTakeoverStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not update taken over objects")
+	}
+}
+
+// handBackObject reports that the owner of a taken over object is alive again, so it is not updated from this cluster
+func (r *syncReconciler) handBackObject(ctx context.Context, key types.NamespacedName) {
+	takeover, ok := r.takeovers.HandBack(key)
+	if !ok {
+		return
+	}
+
+	msg := fmt.Sprintf("owner cluster is alive again, the object is handed back to it (resource: %s, owner: %s, taken over at: %s)", key, takeover.OwnerClusterID, takeover.Since.Format(time.RFC3339))
+	r.recordEvent(corev1.EventTypeNormal, "OwnershipReturned", msg)
+	r.GetLogger().Info(msg)
+
+	if err := r.setTakeoverStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not update taken over objects")
+	}
+}
+
+// checkTakeovers periodically hands back the taken over objects whose owner is alive again,
+// and forgets the ones which are deleted
+func (r *syncReconciler) checkTakeovers(ctx context.Context) {
+	ticker := time.NewTicker(takeoverCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		alive := r.clustersManager.GetAliveClustersByID()
+		for _, takeover := range r.takeovers.List() {
+			if alive[takeover.OwnerClusterID] != nil {
+				r.handBackObject(ctx, takeover.Key)
+
+				continue
+			}
+
+			obj := r.initObjectFromGVK(r.getLocalGVK())
+
+			err := r.localMgr.GetAPIReader().Get(ctx, takeover.Key, obj)
+			if apierrors.IsNotFound(err) {
+				r.takeovers.HandBack(takeover.Key)
+				if err := r.setTakeoverStatus(ctx); err != nil {
+					r.GetLogger().Error(err, "could not update taken over objects")
+				}
+			}
+		}
+	}
+}
+
+func (r *syncReconciler) setTakeoverStatus(ctx context.Context) error {
+	if r.getRule().GetUID() == "" {
+		return nil
+	}
+
+	takeovers := r.takeovers.List()
+
+	objects := make([]clusterregistryv1alpha1.TakenOverObject, 0, len(takeovers))
+	for _, takeover := range takeovers {
+		objects = append(objects, clusterregistryv1alpha1.TakenOverObject{
+			Name:           takeover.Key.Name,
+			Namespace:      takeover.Key.Namespace,
+			OwnerClusterID: takeover.OwnerClusterID,
+			// the API server stores the time with second precision
+			Since: metav1.NewTime(takeover.Since.Truncate(time.Second)),
+		})
+	}
+
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeOwnershipTakenOver,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "NoTakenOverObjects",
+		Message:            "no object is taken over",
+	}
+	if len(objects) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ObjectsTakenOver"
+		condition.Message = fmt.Sprintf("%d objects are updated from this cluster while their owner cluster is not alive", len(objects))
+	}
+
+	return errors.WrapIf(SetResourceSyncRuleClusterStatus(ctx, r.localMgr.GetClient(), r.getRule().GetName(), r.clusterName, func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus) {
+		if len(objects) == 0 {
+			status.TakenOverObjects = nil
+		} else {
+			status.TakenOverObjects = objects
+		}
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}), "could not update rule status")
+}
+
+func (r *syncReconciler) setClusterCondition(ctx context.Context, condition metav1.Condition) error {
+	if r.getRule().GetUID() == "" {
+		return nil
+	}
+
+	return errors.WrapIf(SetResourceSyncRuleClusterCondition(ctx, r.localMgr.GetClient(), r.getRule().GetName(), r.clusterName, condition), "could not update rule status")
+}
+
 func (r *syncReconciler) setQueue(q workqueue.RateLimitingInterface) {
-	r.queue = q
+	r.queue = &observedQueue{
+		RateLimitingInterface: q,
+		observer:              r.queueObserver,
+	}
 }
 
 func (r *syncReconciler) initLocalInformer(ctx context.Context, obj client.Object) error {
-	key := obj.GetObjectKind().GroupVersionKind().String()
+	r.setupMu.Lock()
+	defer r.setupMu.Unlock()
 
-	if _, ok := r.localInformers[key]; ok {
+	key := "local/" + obj.GetObjectKind().GroupVersionKind().String()
+
+	if _, ok := r.watches[key]; ok {
 		return nil
 	}
 
-	r.GetLogger().Info("init local informer", "gvk", key)
+	r.GetLogger().Info("init local informer", "gvk", obj.GetObjectKind().GroupVersionKind().String())
 
+	ctx, span := r.startSpan(ctx, spanInformerInit, types.NamespacedName{})
 	localInformer, err := r.localCache.GetInformer(ctx, obj)
+	tracing.End(span, err)
 	if err != nil {
 		return errors.WrapIf(err, "could not create local informer for clusters")
 	}
 
-	err = r.ctrl.Watch(&source.Informer{
+	err = r.watch(r.ctrl, key, &source.Informer{
 		Informer: localInformer,
 	}, handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
-		name := obj.GetName()
-		namespace := obj.GetNamespace()
-		if originalName, ok := obj.GetLabels()[originalNameLabel]; ok {
-			name = originalName
-		}
-		if originalNamespace, ok := obj.GetLabels()[originalNamespaceLabel]; ok {
-			namespace = originalNamespace
-		}
-
 		return []reconcile.Request{
 			{
-				NamespacedName: types.NamespacedName{
-					Name:      name,
-					Namespace: namespace,
-				},
+				NamespacedName: util.GetSourceObjectKey(obj),
 			},
 		}
 	}), r.localPredicate())
@@ -822,8 +2771,6 @@ func (r *syncReconciler) initLocalInformer(ctx context.Context, obj client.Objec
 		return errors.WrapIf(err, "could not create watch for local informer")
 	}
 
-	r.localInformers[key] = struct{}{}
-
 	return nil
 }
 
@@ -855,7 +2802,7 @@ func (r *syncReconciler) localPredicate() predicate.Funcs {
 				return false
 			}
 
-			return true
+			return r.onLocalUpdate(e.ObjectOld, e.ObjectNew)
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
 			_, ok := e.Object.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation]
@@ -890,7 +2837,7 @@ func (r *syncReconciler) createClient(config *rest.Config, cache cache.Cache) (c
 }
 
 func (r *syncReconciler) createAndStartCache() (cache.Cache, error) {
-	cche, err := cache.New(r.localMgr.GetConfig(), cache.Options{
+	cche, err := r.newCache(r.localMgr.GetConfig(), cache.Options{
 		Scheme: r.localMgr.GetScheme(),
 		Mapper: r.localMgr.GetRESTMapper(),
 	})
@@ -898,15 +2845,21 @@ func (r *syncReconciler) createAndStartCache() (cache.Cache, error) {
 		return nil, err
 	}
 
+	ctx, cancel := context.WithCancel(r.GetContext())
+	r.localCacheCancel = cancel
+
 	go func() {
-		err = cche.Start(r.GetContext())
+		err = cche.Start(ctx)
 		if err != nil {
 			r.GetLogger().Error(err, "could not start cache")
 		}
 		r.GetLogger().Info("cache stopped")
 	}()
 
-	if !cche.WaitForCacheSync(r.GetContext()) {
+	if !cche.WaitForCacheSync(ctx) {
+		cancel()
+		r.localCacheCancel = nil
+
 		return nil, errors.New("could not sync cache")
 	}
 
@@ -0,0 +1,87 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/controllers"
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+var _ = Describe("Sync reconciler access check", func() {
+	newRule := func(serviceAccountName string) *clusterregistryv1alpha1.ResourceSyncRule {
+		return &clusterregistryv1alpha1.ResourceSyncRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "access-test",
+			},
+			Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				GVK: resources.GroupVersionKind{
+					Version: "v1",
+					Kind:    "ConfigMap",
+				},
+				ServiceAccountName: serviceAccountName,
+				Rules: []clusterregistryv1alpha1.SyncRule{
+					{
+						Mutations: clusterregistryv1alpha1.Mutations{
+							SyncStatus: true,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	It("passes with the identity of the controller", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rule := newRule("")
+		rec, err := controllers.NewSyncReconciler(rule.Name, k8sManager, rule, logr.Discard(), "access-test-cluster", clusters.NewManager(ctx))
+		Expect(err).ToNot(HaveOccurred())
+		rec.SetManager(k8sManager)
+		rec.SetClient(k8sClient)
+
+		Expect(rec.PreCheck(ctx, k8sClient)).Should(Succeed())
+	})
+
+	It("lists the permissions missing for the service account of the rule", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		rule := newRule("access-test")
+		rec, err := controllers.NewSyncReconciler(rule.Name, k8sManager, rule, logr.Discard(), "access-test-cluster", clusters.NewManager(ctx),
+			controllers.WithServiceAccountNamespace("default"),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		rec.SetManager(k8sManager)
+		rec.SetClient(k8sClient)
+
+		err = rec.PreCheck(ctx, k8sClient)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("system:serviceaccount:default:access-test"))
+		Expect(err.Error()).To(ContainSubstring("create configmaps"))
+		Expect(err.Error()).To(ContainSubstring("update configmaps/status"))
+		Expect(err.Error()).ToNot(ContainSubstring("on the source cluster"))
+	})
+})
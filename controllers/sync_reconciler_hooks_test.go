@@ -0,0 +1,145 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	"github.com/cisco-open/cluster-registry-controller/controllers"
+)
+
+// signingHook is an example hook, it signs the data of the synced ConfigMaps with the ID of the source cluster
+type signingHook struct {
+	fail bool
+
+	mu      sync.Mutex
+	sources []string
+	errors  []error
+}
+
+func (h *signingHook) PreMutate(ctx context.Context, info controllers.SyncHookInfo, obj client.Object) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sources = append(h.sources, info.Source.String())
+
+	return nil
+}
+
+func (h *signingHook) PreWrite(ctx context.Context, info controllers.SyncHookInfo, desired client.Object) error {
+	if h.fail {
+		return errors.New("signing key is not available")
+	}
+
+	cm, ok := desired.(*corev1.ConfigMap)
+	if !ok {
+		return errors.New("unexpected object")
+	}
+	cm.Data["signature"] = info.Rule.GetName() + "/" + info.ClusterID + "/" + cm.Data["key"]
+
+	return nil
+}
+
+func (h *signingHook) PostSync(ctx context.Context, info controllers.SyncHookInfo, result ctrl.Result, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.errors = append(h.errors, err)
+}
+
+func (h *signingHook) getSources() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]string{}, h.sources...)
+}
+
+func (h *signingHook) hasFailedWith(target error) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, err := range h.errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var _ = Describe("Sync reconciler with hooks", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	It("calls the hooks and writes the objects changed by them", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hook := &signingHook{}
+		rule := newSyncTestRule("hooks-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+		startSyncReconciler(ctx, rule, controllers.WithHooks(hook))
+
+		By("creating a matching source object")
+		source := newSyncTestConfigMap(rule.Name)
+		Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+		synced := &corev1.ConfigMap{}
+		Eventually(func() string {
+			if err := k8sClient.Get(ctx, syncTestKey(source), synced); err != nil {
+				return ""
+			}
+
+			return synced.Data["signature"]
+		}, timeout, interval).Should(Equal(rule.Name + "/" + rule.Name + "-cluster/source"))
+		Expect(hook.getSources()).To(ContainElement(client.ObjectKeyFromObject(source).String()))
+	})
+
+	It("fails the reconcile if a hook fails", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		hook := &signingHook{
+			fail: true,
+		}
+		rule := newSyncTestRule("failing-hooks-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+		startSyncReconciler(ctx, rule, controllers.WithHooks(hook))
+
+		By("creating a matching source object")
+		source := newSyncTestConfigMap(rule.Name)
+		Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+		Eventually(func() bool {
+			return hook.hasFailedWith(controllers.ErrSyncHookFailed)
+		}, timeout, interval).Should(BeTrue())
+
+		Consistently(func() bool {
+			return apierrors.IsNotFound(k8sClient.Get(ctx, syncTestKey(source), &corev1.ConfigMap{}))
+		}, time.Second*2, interval).Should(BeTrue())
+	})
+})
@@ -0,0 +1,280 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/controllers"
+	"github.com/cisco-open/cluster-registry-controller/internal/config"
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+// countingReconciler counts the reconciles of each object instead of syncing it
+type countingReconciler struct {
+	counts map[types.NamespacedName]int
+	mu     sync.Mutex
+}
+
+func (r *countingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.counts[req.NamespacedName]++
+
+	return ctrl.Result{}, nil
+}
+
+func (r *countingReconciler) count(key types.NamespacedName) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.counts[key]
+}
+
+// watchCountingController counts the watches registered on the controller
+type watchCountingController struct {
+	controller.Controller
+
+	watches int32
+}
+
+func (c *watchCountingController) Watch(src source.Source, h handler.EventHandler, predicates ...predicate.Predicate) error {
+	atomic.AddInt32(&c.watches, 1)
+
+	return c.Controller.Watch(src, h, predicates...)
+}
+
+var _ = Describe("Sync reconciler setup", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	It("registers the watches once and starts clean after a teardown", func() {
+		rule := &clusterregistryv1alpha1.ResourceSyncRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "setup-test",
+			},
+			Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				GVK: resources.GroupVersionKind{
+					Version: "v1",
+					Kind:    "ConfigMap",
+				},
+				Rules: []clusterregistryv1alpha1.SyncRule{
+					{
+						Matches: []clusterregistryv1alpha1.SyncRuleMatch{
+							{
+								Labels: []metav1.LabelSelector{
+									{
+										MatchLabels: map[string]string{"setup-test": "true"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		rec, err := controllers.NewSyncReconciler(rule.Name, k8sManager, rule, logr.Discard(), "setup-test-cluster", clusters.NewManager(context.Background()))
+		Expect(err).ToNot(HaveOccurred())
+		rec.SetManager(k8sManager)
+
+		counter := &countingReconciler{
+			counts: make(map[types.NamespacedName]int),
+		}
+		newController := func(name string) *watchCountingController {
+			c, err := controller.NewUnmanaged(name, k8sManager, controller.Options{
+				Reconciler: counter,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			return &watchCountingController{Controller: c}
+		}
+
+		By("setting up the same controller twice")
+		ctx1, cancel1 := context.WithCancel(context.Background())
+		first := newController("setup-test-first")
+		Expect(rec.SetupWithController(ctx1, first)).Should(Succeed())
+		Expect(rec.SetupWithController(ctx1, first)).Should(Succeed())
+		Expect(atomic.LoadInt32(&first.watches)).Should(Equal(int32(2)))
+
+		By("tearing down and setting up a new controller")
+		rec.Teardown()
+		cancel1()
+
+		ctx2, cancel2 := context.WithCancel(context.Background())
+		defer cancel2()
+		second := newController("setup-test-second")
+		Expect(rec.SetupWithController(ctx2, second)).Should(Succeed())
+		Expect(atomic.LoadInt32(&second.watches)).Should(Equal(int32(2)))
+
+		go func() {
+			defer GinkgoRecover()
+			Expect(second.Start(ctx2)).Should(Succeed())
+		}()
+
+		By("creating a matching source object")
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "setup-test",
+				Namespace: "default",
+				Labels:    map[string]string{"setup-test": "true"},
+			},
+		}
+		Expect(k8sClient.Create(ctx2, cm)).Should(Succeed())
+		key := types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}
+
+		Eventually(func() int { return counter.count(key) }, timeout, interval).Should(Equal(1))
+		Consistently(func() int { return counter.count(key) }, time.Second*2, interval).Should(Equal(1))
+
+		By("updating the source object")
+		cm.Data = map[string]string{"key": "value"}
+		Expect(k8sClient.Update(ctx2, cm)).Should(Succeed())
+
+		Eventually(func() int { return counter.count(key) }, timeout, interval).Should(Equal(2))
+		Consistently(func() int { return counter.count(key) }, time.Second*2, interval).Should(Equal(2))
+
+		Expect(k8sClient.Delete(ctx2, cm)).Should(Succeed())
+	})
+
+	It("shares the watch of the source kind between the rules", func() {
+		newRule := func(name string) *clusterregistryv1alpha1.ResourceSyncRule {
+			return &clusterregistryv1alpha1.ResourceSyncRule{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+				},
+				Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+					GVK: resources.GroupVersionKind{
+						Version: "v1",
+						Kind:    "ConfigMap",
+					},
+					Rules: []clusterregistryv1alpha1.SyncRule{
+						{
+							Matches: []clusterregistryv1alpha1.SyncRuleMatch{
+								{
+									Labels: []metav1.LabelSelector{
+										{
+											MatchLabels: map[string]string{name: "true"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+		}
+
+		watches := controllers.NewSharedSourceWatches(config.Configuration{}, logr.Discard())
+
+		// startRule sets up the reconciler of the rule on a controller counting the reconciles
+		startRule := func(ctx context.Context, name string) *countingReconciler {
+			rule := newRule(name)
+			rec, err := controllers.NewSyncReconciler(rule.Name, k8sManager, rule, logr.Discard(), "shared-test-cluster", clusters.NewManager(context.Background()), controllers.WithSharedSourceWatches(watches))
+			Expect(err).ToNot(HaveOccurred())
+			rec.SetManager(k8sManager)
+			rec.SetClient(k8sClient)
+
+			counter := &countingReconciler{
+				counts: make(map[types.NamespacedName]int),
+			}
+			c, err := controller.NewUnmanaged(name, k8sManager, controller.Options{
+				Reconciler: counter,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.SetupWithController(ctx, c)).Should(Succeed())
+
+			go func() {
+				defer GinkgoRecover()
+				defer rec.Teardown()
+				Expect(c.Start(ctx)).Should(Succeed())
+			}()
+
+			return counter
+		}
+
+		newConfigMap := func(name string) *corev1.ConfigMap {
+			return &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+					Labels:    map[string]string{name: "true"},
+				},
+			}
+		}
+
+		first := newConfigMap("shared-test-first")
+		second := newConfigMap("shared-test-second")
+		firstKey := types.NamespacedName{Name: first.Name, Namespace: first.Namespace}
+		secondKey := types.NamespacedName{Name: second.Name, Namespace: second.Namespace}
+
+		ctx := context.Background()
+		Expect(k8sClient.Create(ctx, first)).Should(Succeed())
+		Expect(k8sClient.Create(ctx, second)).Should(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, first)).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, second)).Should(Succeed())
+		}()
+
+		By("starting the rule of the first object")
+		ctx1, cancel1 := context.WithCancel(ctx)
+		defer cancel1()
+		firstCounter := startRule(ctx1, first.Name)
+
+		Eventually(func() int { return firstCounter.count(firstKey) }, timeout, interval).Should(Equal(1))
+		Expect(firstCounter.count(secondKey)).Should(Equal(0))
+
+		By("attaching the rule of the second object to the running watch")
+		ctx2, cancel2 := context.WithCancel(ctx)
+		defer cancel2()
+		secondCounter := startRule(ctx2, second.Name)
+
+		// the objects listed by the shared watch before the rule is attached are sent to it
+		Eventually(func() int { return secondCounter.count(secondKey) }, timeout, interval).Should(Equal(1))
+		Expect(secondCounter.count(firstKey)).Should(Equal(0))
+		Consistently(func() int { return firstCounter.count(firstKey) }, time.Second*2, interval).Should(Equal(1))
+
+		By("detaching the rule of the first object")
+		cancel1()
+
+		second.Data = map[string]string{"key": "value"}
+		Expect(k8sClient.Update(ctx, second)).Should(Succeed())
+		Eventually(func() int { return secondCounter.count(secondKey) }, timeout, interval).Should(Equal(2))
+
+		first.Data = map[string]string{"key": "value"}
+		Expect(k8sClient.Update(ctx, first)).Should(Succeed())
+		Consistently(func() int { return firstCounter.count(firstKey) }, time.Second*2, interval).Should(Equal(1))
+	})
+})
@@ -0,0 +1,1244 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/controllers"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+var _ = Describe("Sync reconciler", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("upgrades between format versions", func() {
+		// syncedObjects returns the objects synced from the source by the rule, whatever format they are written in
+		syncedObjects := func(ctx context.Context, rule *clusterregistryv1alpha1.ResourceSyncRule) []corev1.ConfigMap {
+			list := &corev1.ConfigMapList{}
+			Expect(k8sClient.List(ctx, list, client.InNamespace("default"), client.MatchingLabels{
+				clusterregistryv1alpha1.OwnershipAnnotation: rule.Name + "-cluster",
+			})).Should(Succeed())
+
+			return list.Items
+		}
+
+		for name, versions := range map[string][2]util.FormatVersion{
+			"upgrade":   {util.PreviousFormatVersion, util.CurrentFormatVersion},
+			"downgrade": {util.CurrentFormatVersion, util.PreviousFormatVersion},
+		} {
+			name, versions := name, versions
+			It("hands the objects over without lost or duplicated work on "+name, func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				rule := newSyncTestRule("format-version-"+name+"-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+				source := newSyncTestConfigMap(rule.Name)
+				syncedKey := syncTestKey(source)
+
+				By("syncing the source object with the controller writing the first format")
+				firstCtx, stopFirst := context.WithCancel(ctx)
+				startSyncReconciler(firstCtx, rule, controllers.WithWriteFormatVersion(versions[0]))
+				Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+				synced := &corev1.ConfigMap{}
+				Eventually(func() error {
+					return k8sClient.Get(ctx, syncedKey, synced)
+				}, timeout, interval).Should(Succeed())
+				version, err := util.GetFormatVersion(synced.GetAnnotations())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(version).To(Equal(versions[0]))
+
+				By("changing the source object while the controllers are handed over")
+				stopFirst()
+				source.Data["key"] = "handover"
+				Expect(k8sClient.Update(ctx, source)).Should(Succeed())
+
+				By("syncing the queued change with the controller writing the second format")
+				startSyncReconciler(ctx, rule, controllers.WithWriteFormatVersion(versions[1]))
+
+				Eventually(func() string {
+					if err := k8sClient.Get(ctx, syncedKey, synced); err != nil {
+						return ""
+					}
+
+					return synced.Data["key"]
+				}, timeout, interval).Should(Equal("handover"))
+				version, err = util.GetFormatVersion(synced.GetAnnotations())
+				Expect(err).ToNot(HaveOccurred())
+				Expect(version).To(Equal(versions[1]))
+				Expect(syncedObjects(ctx, rule)).To(HaveLen(1))
+
+				By("deleting the source object")
+				Expect(k8sClient.Delete(ctx, source)).Should(Succeed())
+
+				Eventually(func() bool {
+					return apierrors.IsNotFound(k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{}))
+				}, timeout, interval).Should(BeTrue())
+				Expect(syncedObjects(ctx, rule)).To(BeEmpty())
+			})
+		}
+	})
+
+	Context("for cluster scoped resources", func() {
+		It("syncs, updates and deletes cluster scoped objects", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("cluster-scoped-test", resources.GroupVersionKind{Group: rbacv1.GroupName, Version: "v1", Kind: "ClusterRole"})
+			// a namespace set by the overrides is dropped for cluster scoped kinds
+			rule.Spec.Rules[0].Mutations.Overrides = append(rule.Spec.Rules[0].Mutations.Overrides, resources.K8SResourceOverlayPatch{
+				Type:  resources.ReplaceOverlayPatchType,
+				Path:  pointer.String("/metadata/namespace"),
+				Value: pointer.String(metav1.NamespaceDefault),
+			})
+			startSyncReconciler(ctx, rule)
+
+			By("creating a matching cluster scoped source object")
+			source := &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   rule.Name,
+					Labels: map[string]string{rule.Name: "true"},
+				},
+				Rules: []rbacv1.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"configmaps"},
+						Verbs:     []string{"get"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			syncedKey := syncTestKey(source)
+			synced := &rbacv1.ClusterRole{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncedKey, synced)
+			}, timeout, interval).Should(Succeed())
+			Expect(synced.GetNamespace()).To(BeEmpty())
+			Expect(synced.GetLabels()).ToNot(HaveKey("cluster-scoped-test"))
+			Expect(synced.GetAnnotations()).To(HaveKeyWithValue(clusterregistryv1alpha1.SourceObjectAnnotation, source.GetName()))
+			Expect(synced.Rules).To(Equal(source.Rules))
+
+			By("updating the source object")
+			source.Rules[0].Verbs = []string{"get", "list"}
+			Expect(k8sClient.Update(ctx, source)).Should(Succeed())
+
+			Eventually(func() []string {
+				if err := k8sClient.Get(ctx, syncedKey, synced); err != nil {
+					return nil
+				}
+
+				return synced.Rules[0].Verbs
+			}, timeout, interval).Should(Equal([]string{"get", "list"}))
+
+			By("deleting the source object")
+			Expect(k8sClient.Delete(ctx, source)).Should(Succeed())
+
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, syncedKey, &rbacv1.ClusterRole{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("in the create-only sync mode", func() {
+		It("creates missing objects but keeps the local edits and the objects of deleted sources", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("create-only-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rule.Spec.SyncMode = clusterregistryv1alpha1.SyncModeEnsureExists
+			startSyncReconciler(ctx, rule)
+
+			By("creating a matching source object")
+			source := newSyncTestConfigMap(rule.Name)
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			syncedKey := syncTestKey(source)
+			synced := &corev1.ConfigMap{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncedKey, synced)
+			}, timeout, interval).Should(Succeed())
+			Expect(synced.Data).To(HaveKeyWithValue("key", "source"))
+			Expect(synced.GetAnnotations()).To(HaveKey(clusterregistryv1alpha1.OwnershipAnnotation))
+
+			By("editing the synced object locally")
+			synced.Data["key"] = "local"
+			Expect(k8sClient.Update(ctx, synced)).Should(Succeed())
+
+			By("updating the source object")
+			source.Data["key"] = "updated"
+			Expect(k8sClient.Update(ctx, source)).Should(Succeed())
+
+			Consistently(func() string {
+				if err := k8sClient.Get(ctx, syncedKey, synced); err != nil {
+					return ""
+				}
+
+				return synced.Data["key"]
+			}, time.Second*3, interval).Should(Equal("local"))
+
+			By("deleting the source object")
+			Expect(k8sClient.Delete(ctx, source)).Should(Succeed())
+
+			Consistently(func() error {
+				return k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{})
+			}, time.Second*3, interval).Should(Succeed())
+		})
+	})
+
+	Context("with delete protected objects", func() {
+		It("keeps protected objects until the protection is removed", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("delete-protection-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			startSyncReconciler(ctx, rule)
+
+			By("creating a matching source object")
+			source := newSyncTestConfigMap(rule.Name)
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			syncedKey := syncTestKey(source)
+			synced := &corev1.ConfigMap{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncedKey, synced)
+			}, timeout, interval).Should(Succeed())
+
+			By("protecting the synced object")
+			patch := client.MergeFrom(synced.DeepCopy())
+			synced.Annotations[clusterregistryv1alpha1.DeleteProtectedAnnotation] = "true"
+			Expect(k8sClient.Patch(ctx, synced, patch)).Should(Succeed())
+
+			By("deleting the source object")
+			Expect(k8sClient.Delete(ctx, source)).Should(Succeed())
+
+			Consistently(func() error {
+				return k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{})
+			}, time.Second*3, interval).Should(Succeed())
+
+			By("removing the protection")
+			Expect(k8sClient.Get(ctx, syncedKey, synced)).Should(Succeed())
+			annotations := synced.GetAnnotations()
+			delete(annotations, clusterregistryv1alpha1.DeleteProtectedAnnotation)
+			synced.SetAnnotations(annotations)
+			Expect(k8sClient.Update(ctx, synced)).Should(Succeed())
+
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("with delayed deletion", func() {
+		It("deletes the synced objects only after the source is missing for the deletion delay", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			const deleteAfter = time.Second * 3
+			propagation := metav1.DeletePropagationBackground
+
+			rule := newSyncTestRule("delayed-deletion-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rule.Spec.DeletionPropagation = &propagation
+			rule.Spec.DeleteAfter = &metav1.Duration{Duration: deleteAfter}
+			startSyncReconciler(ctx, rule)
+
+			By("creating a matching source object")
+			source := newSyncTestConfigMap(rule.Name)
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			syncedKey := syncTestKey(source)
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+
+			By("deleting and recreating the source object within the deletion delay")
+			Expect(k8sClient.Delete(ctx, source)).Should(Succeed())
+			time.Sleep(deleteAfter / 3)
+			source.SetResourceVersion("")
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			Consistently(func() error {
+				return k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{})
+			}, deleteAfter*2, interval).Should(Succeed())
+
+			By("deleting the source object")
+			Expect(k8sClient.Delete(ctx, source)).Should(Succeed())
+
+			Consistently(func() error {
+				return k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{})
+			}, deleteAfter/2, interval).Should(Succeed())
+
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("with the opt-in annotation required", func() {
+		It("syncs the opted in objects only and deletes the synced objects once opted out", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("opt-in-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rule.Spec.RequireOptInAnnotation = true
+			startSyncReconciler(ctx, rule)
+
+			By("creating a matching source object without the opt-in annotation")
+			source := newSyncTestConfigMap(rule.Name)
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			syncedKey := syncTestKey(source)
+			Consistently(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{}))
+			}, time.Second*2, interval).Should(BeTrue())
+
+			By("opting the source object in")
+			source.SetAnnotations(map[string]string{clusterregistryv1alpha1.SyncOptInAnnotation: "true"})
+			Expect(k8sClient.Update(ctx, source)).Should(Succeed())
+
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+
+			By("removing the opt-in annotation only")
+			source.SetAnnotations(nil)
+			Expect(k8sClient.Update(ctx, source)).Should(Succeed())
+
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("in the direct read mode", func() {
+		It("syncs objects read directly from the API server", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("direct-read-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rule.Spec.ReadMode = clusterregistryv1alpha1.ReadModeDirect
+			startSyncReconciler(ctx, rule)
+
+			By("creating a matching source object")
+			source := newSyncTestConfigMap(rule.Name)
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			syncedKey := syncTestKey(source)
+			synced := &corev1.ConfigMap{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncedKey, synced)
+			}, timeout, interval).Should(Succeed())
+			Expect(synced.Data).To(HaveKeyWithValue("key", "source"))
+
+			By("updating the source object")
+			source.Data["key"] = "updated"
+			Expect(k8sClient.Update(ctx, source)).Should(Succeed())
+
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, syncedKey, synced); err != nil {
+					return ""
+				}
+
+				return synced.Data["key"]
+			}, timeout, interval).Should(Equal("updated"))
+
+			By("deleting the source object")
+			Expect(k8sClient.Delete(ctx, source)).Should(Succeed())
+
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("convergence", func() {
+		It("reports convergence once the listed source objects are synced", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			By("creating a matching source object before the reconciler starts")
+			source := newSyncTestConfigMap("convergence-test")
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			converged := make(chan struct{})
+			rule := newSyncTestRule("convergence-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rec := startSyncReconciler(ctx, rule, controllers.WithOnConvergedFunc(func() {
+				close(converged)
+			}))
+			Expect(rec.IsConverged()).To(BeFalse())
+
+			Expect(rec.Start(ctx)).Should(Succeed())
+			Eventually(converged, timeout, interval).Should(BeClosed())
+			Expect(rec.IsConverged()).To(BeTrue())
+
+			By("finding the object synced by the time of the convergence")
+			synced := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, syncTestKey(source), synced)).Should(Succeed())
+			Expect(synced.Data).To(HaveKeyWithValue("key", "source"))
+		})
+	})
+
+	Context("in place rule updates", func() {
+		It("syncs the objects by the updated rule without regenerating the controller", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("in-place-update-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rec := startSyncReconciler(ctx, rule)
+			Expect(rec.Start(ctx)).Should(Succeed())
+
+			By("creating a matching source object")
+			source := newSyncTestConfigMap(rule.Name)
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			synced := &corev1.ConfigMap{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncTestKey(source), synced)
+			}, timeout, interval).Should(Succeed())
+			Expect(synced.GetLabels()).NotTo(HaveKey("updated"))
+
+			By("updating the mutations of the rule in place")
+			updated := rule.DeepCopy()
+			updated.Generation = 2
+			updated.Spec.Rules[0].Mutations.Labels = &clusterregistryv1alpha1.LabelMutations{
+				Add: map[string]string{"updated": "true"},
+			}
+			Expect(controllers.IsRuleUpdatableInPlace(rule, updated)).To(BeTrue())
+			Expect(rec.UpdateRule(ctx, updated)).Should(Succeed())
+			Expect(rec.GetRule()).To(BeIdenticalTo(updated))
+
+			Eventually(func() map[string]string {
+				if err := k8sClient.Get(ctx, syncTestKey(source), synced); err != nil {
+					return nil
+				}
+
+				return synced.GetLabels()
+			}, timeout, interval).Should(HaveKeyWithValue("updated", "true"))
+			Eventually(rec.IsConverged, timeout, interval).Should(BeTrue())
+
+			By("refusing the changes of the watched kind")
+			changed := updated.DeepCopy()
+			changed.Spec.GVK = resources.GroupVersionKind{Version: "v1", Kind: "Secret"}
+			Expect(controllers.IsRuleUpdatableInPlace(updated, changed)).To(BeFalse())
+			Expect(rec.UpdateRule(ctx, changed)).ShouldNot(Succeed())
+			Expect(rec.GetRule()).To(BeIdenticalTo(updated))
+
+			changed = updated.DeepCopy()
+			changed.Spec.Workers = 4
+			Expect(controllers.IsRuleUpdatableInPlace(updated, changed)).To(BeFalse())
+		})
+	})
+
+	Context("with preserved finalizers", func() {
+		const (
+			timeout  = time.Second * 10
+			interval = time.Millisecond * 250
+
+			finalizer = "example.com/keep"
+		)
+
+		It("keeps the preserved finalizers and deletes the synced objects once they are removed", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("preserve-finalizers-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rule.Spec.PreserveFinalizers = []string{finalizer}
+			startSyncReconciler(ctx, rule)
+
+			By("creating a matching source object with finalizers")
+			source := newSyncTestConfigMap(rule.Name)
+			source.SetFinalizers([]string{finalizer, "example.com/drop"})
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+			defer func() {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(source), source)).Should(Succeed())
+				source.SetFinalizers(nil)
+				Expect(k8sClient.Update(ctx, source)).Should(Succeed())
+			}()
+
+			syncedKey := syncTestKey(source)
+			synced := &corev1.ConfigMap{}
+			Eventually(func() ([]string, error) {
+				err := k8sClient.Get(ctx, syncedKey, synced)
+
+				return synced.GetFinalizers(), err
+			}, timeout, interval).Should(Equal([]string{finalizer}))
+
+			By("deleting the source object")
+			Expect(k8sClient.Delete(ctx, source)).Should(Succeed())
+
+			Eventually(func() bool {
+				return k8sClient.Get(ctx, syncedKey, synced) == nil && !synced.GetDeletionTimestamp().IsZero()
+			}, timeout, interval).Should(BeTrue())
+
+			Consistently(func() error {
+				return k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{})
+			}, time.Second*2, interval).Should(Succeed())
+
+			By("removing the preserved finalizer from the synced object")
+			synced.SetFinalizers(nil)
+			Expect(k8sClient.Update(ctx, synced)).Should(Succeed())
+
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("with objects controlled by local controllers", func() {
+		// createLocallyControlledObject creates the object at the synced key of the source, controlled by a local owner
+		createLocallyControlledObject := func(ctx context.Context, source *corev1.ConfigMap) {
+			owner := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: source.GetName() + "-owner", Namespace: source.GetNamespace()},
+			}
+			Expect(k8sClient.Create(ctx, owner)).Should(Succeed())
+
+			key := syncTestKey(source)
+			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "v1",
+						Kind:       "ConfigMap",
+						Name:       owner.GetName(),
+						UID:        owner.GetUID(),
+						Controller: pointer.BoolPtr(true),
+					}},
+				},
+				Data: map[string]string{"key": "local"},
+			})).Should(Succeed())
+		}
+
+		getSyncedData := func(ctx context.Context, source *corev1.ConfigMap) func() (string, error) {
+			return func() (string, error) {
+				synced := &corev1.ConfigMap{}
+				err := k8sClient.Get(ctx, syncTestKey(source), synced)
+
+				return synced.Data["key"], err
+			}
+		}
+
+		It("skips the objects controlled by local controllers", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("local-owners-skip-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rule.Spec.ConflictPolicy = clusterregistryv1alpha1.ConflictPolicyOverwrite
+			source := newSyncTestConfigMap(rule.Name)
+			createLocallyControlledObject(ctx, source)
+			startSyncReconciler(ctx, rule)
+
+			By("creating a matching source object")
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			Consistently(getSyncedData(ctx, source), time.Second*3, interval).Should(Equal("local"))
+
+			By("updating the source object")
+			source.Data = map[string]string{"key": "updated"}
+			Expect(k8sClient.Update(ctx, source)).Should(Succeed())
+
+			Consistently(getSyncedData(ctx, source), time.Second*3, interval).Should(Equal("local"))
+
+			By("checking that the skipped object is reported once")
+			Eventually(func() (int32, error) {
+				events := &corev1.EventList{}
+				if err := k8sClient.List(ctx, events); err != nil {
+					return 0, err
+				}
+
+				var count int32
+				for _, event := range events.Items {
+					if event.InvolvedObject.Name == rule.GetName() && event.Reason == "OwnedByOtherController" {
+						count += event.Count
+					}
+				}
+
+				return count, nil
+			}, timeout, interval).Should(Equal(int32(1)))
+		})
+
+		It("overwrites the objects controlled by local controllers if the rule overrides the local owners", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("local-owners-override-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rule.Spec.ConflictPolicy = clusterregistryv1alpha1.ConflictPolicyOverwrite
+			rule.Spec.OverrideLocalOwners = true
+			source := newSyncTestConfigMap(rule.Name)
+			createLocallyControlledObject(ctx, source)
+			startSyncReconciler(ctx, rule)
+
+			By("creating a matching source object")
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			Eventually(getSyncedData(ctx, source), timeout, interval).Should(Equal("source"))
+		})
+	})
+
+	Context("owner references", func() {
+		// newOwnerReferencesTestRule returns the test rule syncing every matched object to its own name, so the owners and
+		// the owned objects do not collide
+		newOwnerReferencesTestRule := func(name string) *clusterregistryv1alpha1.ResourceSyncRule {
+			rule := newSyncTestRule(name, resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rule.Spec.Rules[0].Mutations.Overrides[0].Value = pointer.String("{{ .Object.GetName }}-synced")
+
+			return rule
+		}
+
+		// createOwnedSource creates the owner and the owned source objects, the owner is synced first
+		createOwnedSource := func(ctx context.Context, rule *clusterregistryv1alpha1.ResourceSyncRule) (*corev1.ConfigMap, *corev1.ConfigMap) {
+			owner := newSyncTestConfigMap(rule.Name + "-owner")
+			owner.Labels = map[string]string{rule.Name: "true"}
+			Expect(k8sClient.Create(ctx, owner)).Should(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncTestKey(owner), &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+
+			owned := newSyncTestConfigMap(rule.Name)
+			owned.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Name:       owner.GetName(),
+				UID:        owner.GetUID(),
+			}}
+			Expect(k8sClient.Create(ctx, owned)).Should(Succeed())
+
+			return owner, owned
+		}
+
+		getOwnerUIDs := func(ctx context.Context, key types.NamespacedName) func() ([]types.UID, error) {
+			return func() ([]types.UID, error) {
+				synced := &corev1.ConfigMap{}
+				if err := k8sClient.Get(ctx, key, synced); err != nil {
+					return nil, err
+				}
+
+				uids := make([]types.UID, 0)
+				for _, ref := range synced.GetOwnerReferences() {
+					uids = append(uids, ref.UID)
+				}
+
+				return uids, nil
+			}
+		}
+
+		// the local garbage collector deletes the objects whose owners are missing, the owner references to the objects of
+		// the source cluster must not be synced
+		It("clears the owner references by default", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newOwnerReferencesTestRule("owner-references-clear-test")
+			startSyncReconciler(ctx, rule)
+
+			_, owned := createOwnedSource(ctx, rule)
+
+			Eventually(getOwnerUIDs(ctx, syncTestKey(owned)), timeout, interval).Should(BeEmpty())
+		})
+
+		It("remaps the owner references to the local copies of the owners", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newOwnerReferencesTestRule("owner-references-remap-test")
+			rule.Spec.OwnerReferenceMode = clusterregistryv1alpha1.OwnerReferenceModeRemap
+			startSyncReconciler(ctx, rule)
+
+			owner, owned := createOwnedSource(ctx, rule)
+
+			syncedOwner := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, syncTestKey(owner), syncedOwner)).Should(Succeed())
+			Expect(syncedOwner.GetUID()).ShouldNot(Equal(owner.GetUID()))
+
+			Eventually(getOwnerUIDs(ctx, syncTestKey(owned)), timeout, interval).Should(Equal([]types.UID{syncedOwner.GetUID()}))
+		})
+
+		// the owner references are remapped once the owner is synced, even if the owned object is synced before it
+		It("remaps the owner references of the objects synced before their owners", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newOwnerReferencesTestRule("owner-references-reverse-test")
+			rule.Spec.OwnerReferenceMode = clusterregistryv1alpha1.OwnerReferenceModeRemap
+			startSyncReconciler(ctx, rule)
+
+			By("creating the owner, which is not matched by the rule yet")
+			owner := newSyncTestConfigMap(rule.Name + "-owner")
+			owner.Labels = nil
+			Expect(k8sClient.Create(ctx, owner)).Should(Succeed())
+
+			By("creating the owned object")
+			owned := newSyncTestConfigMap(rule.Name)
+			owned.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Name:       owner.GetName(),
+				UID:        owner.GetUID(),
+			}}
+			Expect(k8sClient.Create(ctx, owned)).Should(Succeed())
+
+			Eventually(getOwnerUIDs(ctx, syncTestKey(owned)), timeout, interval).Should(BeEmpty())
+
+			By("matching the owner by the rule")
+			owner.Labels = map[string]string{rule.Name: "true"}
+			Expect(k8sClient.Update(ctx, owner)).Should(Succeed())
+
+			syncedOwner := &corev1.ConfigMap{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncTestKey(owner), syncedOwner)
+			}, timeout, interval).Should(Succeed())
+
+			// the owned object is synced again at the unresolved owner interval
+			Eventually(getOwnerUIDs(ctx, syncTestKey(owned)), timeout*3, interval).Should(Equal([]types.UID{syncedOwner.GetUID()}))
+			Consistently(getOwnerUIDs(ctx, syncTestKey(owned)), time.Second*3, interval).Should(Equal([]types.UID{syncedOwner.GetUID()}))
+		})
+	})
+
+	Context("object size limits", func() {
+		It("skips the source objects larger than the maximum object size", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("max-object-size-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			maxObjectSize := resource.MustParse("1Ki")
+			rule.Spec.MaxObjectSize = &maxObjectSize
+			startSyncReconciler(ctx, rule)
+
+			By("creating an oversized source object")
+			source := newSyncTestConfigMap(rule.Name)
+			source.Data["key"] = strings.Repeat("x", 2048)
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			syncedKey := syncTestKey(source)
+			Consistently(func() error {
+				return k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{})
+			}, time.Second*2, interval).ShouldNot(Succeed())
+
+			By("shrinking the source object")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(source), source)).Should(Succeed())
+			source.Data["key"] = "source"
+			Expect(k8sClient.Update(ctx, source)).Should(Succeed())
+
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncedKey, &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("writes the objects above the size limit of the last applied annotation without it", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("last-applied-size-limit-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			startSyncReconciler(ctx, rule, controllers.WithLastAppliedSizeLimit(1024))
+
+			By("creating a large source object")
+			source := newSyncTestConfigMap(rule.Name)
+			source.Data["key"] = strings.Repeat("x", 2048)
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			syncedKey := syncTestKey(source)
+			synced := &corev1.ConfigMap{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncedKey, synced)
+			}, timeout, interval).Should(Succeed())
+			Expect(synced.GetAnnotations()).ShouldNot(HaveKey(patch.LastAppliedConfig))
+
+			By("updating the source object")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(source), source)).Should(Succeed())
+			source.Data["key"] = strings.Repeat("y", 2048)
+			Expect(k8sClient.Update(ctx, source)).Should(Succeed())
+
+			Eventually(func() (string, error) {
+				err := k8sClient.Get(ctx, syncedKey, synced)
+
+				return synced.Data["key"], err
+			}, timeout, interval).Should(Equal(source.Data["key"]))
+			Expect(synced.GetAnnotations()).ShouldNot(HaveKey(patch.LastAppliedConfig))
+		})
+
+		It("reverts the local changes of the objects above the size limit of the last applied annotation", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("last-applied-size-limit-revert-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			startSyncReconciler(ctx, rule, controllers.WithLastAppliedSizeLimit(1024))
+
+			By("creating a large source object")
+			source := newSyncTestConfigMap(rule.Name)
+			source.Data["key"] = strings.Repeat("x", 2048)
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			syncedKey := syncTestKey(source)
+			synced := &corev1.ConfigMap{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncedKey, synced)
+			}, timeout, interval).Should(Succeed())
+
+			By("modifying the synced object locally")
+			synced.Data["key"] = "local"
+			synced.Data["extra"] = "local"
+			Expect(k8sClient.Update(ctx, synced)).Should(Succeed())
+
+			Eventually(func() (map[string]string, error) {
+				err := k8sClient.Get(ctx, syncedKey, synced)
+
+				return synced.Data, err
+			}, timeout, interval).Should(Equal(source.Data))
+		})
+	})
+
+	Context("with an object quota", func() {
+		It("creates new objects only while the rule syncs fewer objects than its quota", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("object-quota-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rule.Spec.MaxObjects = 1
+			rec := startSyncReconciler(ctx, rule)
+
+			By("creating a matching source object")
+			first := newSyncTestConfigMap(rule.Name)
+			Expect(k8sClient.Create(ctx, first)).Should(Succeed())
+
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncTestKey(first), &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+
+			By("creating another source object beyond the quota")
+			second := newSyncTestConfigMap(rule.Name)
+			second.Name = rule.Name + "-second"
+			Expect(k8sClient.Create(ctx, second)).Should(Succeed())
+
+			Consistently(func() error {
+				return k8sClient.Get(ctx, syncTestKey(second), &corev1.ConfigMap{})
+			}, time.Second*2, interval).ShouldNot(Succeed())
+
+			By("updating the synced source object")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(first), first)).Should(Succeed())
+			first.Data["key"] = "updated"
+			Expect(k8sClient.Update(ctx, first)).Should(Succeed())
+
+			Eventually(func() string {
+				synced := &corev1.ConfigMap{}
+				if err := k8sClient.Get(ctx, syncTestKey(first), synced); err != nil {
+					return ""
+				}
+
+				return synced.Data["key"]
+			}, timeout, interval).Should(Equal("updated"))
+
+			By("deleting the synced source object")
+			Expect(k8sClient.Delete(ctx, first)).Should(Succeed())
+
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncTestKey(second), &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+
+			By("raising the quota in place")
+			third := newSyncTestConfigMap(rule.Name)
+			third.Name = rule.Name + "-third"
+			Expect(k8sClient.Create(ctx, third)).Should(Succeed())
+
+			Consistently(func() error {
+				return k8sClient.Get(ctx, syncTestKey(third), &corev1.ConfigMap{})
+			}, time.Second*2, interval).ShouldNot(Succeed())
+
+			updated := rule.DeepCopy()
+			updated.Generation = 2
+			updated.Spec.MaxObjects = 2
+			Expect(controllers.IsRuleUpdatableInPlace(rule, updated)).To(BeTrue())
+			Expect(rec.UpdateRule(ctx, updated)).Should(Succeed())
+
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncTestKey(third), &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	Context("with immutable objects", func() {
+		// syncImmutableSource creates an immutable source object, waits for it to be synced and then replaces it with
+		// another immutable object with different data, like a push side recreating it
+		syncImmutableSource := func(ctx context.Context, rule *clusterregistryv1alpha1.ResourceSyncRule) *corev1.ConfigMap {
+			source := newSyncTestConfigMap(rule.Name)
+			source.Immutable = pointer.BoolPtr(true)
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncTestKey(source), &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+
+			By("recreating the source object with different data")
+			Expect(k8sClient.Delete(ctx, source)).Should(Succeed())
+			source.SetResourceVersion("")
+			source.Data = map[string]string{"key": "recreated"}
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			return source
+		}
+
+		getSyncedData := func(ctx context.Context, source *corev1.ConfigMap) func() (string, error) {
+			return func() (string, error) {
+				synced := &corev1.ConfigMap{}
+				err := k8sClient.Get(ctx, syncTestKey(source), synced)
+
+				return synced.Data["key"], err
+			}
+		}
+
+		newImmutableTestRule := func(name string) *clusterregistryv1alpha1.ResourceSyncRule {
+			rule := newSyncTestRule(name, resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			// the synced object is kept while the source is recreated
+			rule.Spec.DeleteAfter = &metav1.Duration{Duration: time.Minute}
+
+			return rule
+		}
+
+		It("leaves the immutable objects as is by default", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newImmutableTestRule("immutable-default-test")
+			startSyncReconciler(ctx, rule)
+
+			source := syncImmutableSource(ctx, rule)
+
+			Consistently(getSyncedData(ctx, source), time.Second*3, interval).Should(Equal("source"))
+
+			Eventually(func() ([]string, error) {
+				events := &corev1.EventList{}
+				if err := k8sClient.List(ctx, events); err != nil {
+					return nil, err
+				}
+
+				reasons := make([]string, 0)
+				for _, event := range events.Items {
+					if event.InvolvedObject.Name == rule.GetName() {
+						reasons = append(reasons, event.Reason)
+					}
+				}
+
+				return reasons, nil
+			}, timeout, interval).Should(ContainElement("ObjectImmutable"))
+		})
+
+		It("recreates the immutable objects if the rule allows it", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newImmutableTestRule("immutable-recreate-test")
+			rule.Spec.RecreateImmutable = true
+			startSyncReconciler(ctx, rule)
+
+			source := syncImmutableSource(ctx, rule)
+
+			Eventually(getSyncedData(ctx, source), timeout, interval).Should(Equal("recreated"))
+
+			synced := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, syncTestKey(source), synced)).Should(Succeed())
+			Expect(synced.Immutable).Should(Equal(pointer.BoolPtr(true)))
+		})
+	})
+
+	Context("with immutable workload fields", func() {
+		newSelectorTestDeployment := func(name string, app string) *appsv1.Deployment {
+			labels := map[string]string{"app": app}
+
+			return &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: metav1.NamespaceDefault,
+					Labels:    map[string]string{name: "true"},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "app", Image: "nginx"}},
+						},
+					},
+				},
+			}
+		}
+
+		// syncSelectorChange syncs a source Deployment and recreates it with another selector, like a push side would,
+		// the selector of a Deployment can not be updated. It returns the synced Deployment before the change.
+		syncSelectorChange := func(ctx context.Context, rule *clusterregistryv1alpha1.ResourceSyncRule) *appsv1.Deployment {
+			source := newSelectorTestDeployment(rule.GetName(), "before")
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			synced := &appsv1.Deployment{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncTestKey(source), synced)
+			}, timeout, interval).Should(Succeed())
+
+			By("recreating the source Deployment with a different selector")
+			Expect(k8sClient.Delete(ctx, source)).Should(Succeed())
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(source), &appsv1.Deployment{}))
+			}, timeout, interval).Should(BeTrue())
+			Expect(k8sClient.Create(ctx, newSelectorTestDeployment(rule.GetName(), "after"))).Should(Succeed())
+
+			return synced
+		}
+
+		getRuleEventReasons := func(ctx context.Context, rule *clusterregistryv1alpha1.ResourceSyncRule) func() ([]string, error) {
+			return func() ([]string, error) {
+				events := &corev1.EventList{}
+				if err := k8sClient.List(ctx, events); err != nil {
+					return nil, err
+				}
+
+				reasons := make([]string, 0)
+				for _, event := range events.Items {
+					if event.InvolvedObject.Name == rule.GetName() {
+						reasons = append(reasons, event.Reason)
+					}
+				}
+
+				return reasons, nil
+			}
+		}
+
+		newSelectorTestRule := func(name string) *clusterregistryv1alpha1.ResourceSyncRule {
+			rule := newSyncTestRule(name, resources.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+			// the synced object is kept while the source is recreated
+			rule.Spec.DeleteAfter = &metav1.Duration{Duration: time.Minute}
+
+			return rule
+		}
+
+		It("recreates the Deployments with a changed selector by default", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSelectorTestRule("selector-recreate-test")
+			startSyncReconciler(ctx, rule)
+
+			synced := syncSelectorChange(ctx, rule)
+
+			By("waiting for the deletion of the synced Deployment")
+			current := &appsv1.Deployment{}
+			Eventually(func() (bool, error) {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(synced), current)
+
+				return current.GetDeletionTimestamp() != nil, err
+			}, timeout, interval).Should(BeTrue())
+			Expect(current.GetUID()).Should(Equal(synced.GetUID()))
+
+			// the test environment runs no garbage collector to finish the foreground deletion
+			current.SetFinalizers(nil)
+			Expect(k8sClient.Update(ctx, current)).Should(Succeed())
+
+			Eventually(func() (map[string]string, error) {
+				current := &appsv1.Deployment{}
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(synced), current)
+				if err != nil || current.GetUID() == synced.GetUID() || current.Spec.Selector == nil {
+					return nil, err
+				}
+
+				return current.Spec.Selector.MatchLabels, nil
+			}, timeout*3, interval).Should(Equal(map[string]string{"app": "after"}))
+		})
+
+		It("reports the changed selector instead of recreating the Deployments if the rule never recreates", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSelectorTestRule("selector-never-recreate-test")
+			rule.Spec.RecreatePolicy = clusterregistryv1alpha1.RecreatePolicyNever
+			startSyncReconciler(ctx, rule)
+
+			synced := syncSelectorChange(ctx, rule)
+
+			Eventually(getRuleEventReasons(ctx, rule), timeout, interval).Should(ContainElement("ObjectImmutableFieldConflict"))
+
+			Consistently(func() (types.UID, error) {
+				current := &appsv1.Deployment{}
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(synced), current)
+				if err != nil || current.GetDeletionTimestamp() != nil || current.Spec.Selector == nil ||
+					current.Spec.Selector.MatchLabels["app"] != "before" {
+					return "", err
+				}
+
+				return current.GetUID(), nil
+			}, time.Second*3, interval).Should(Equal(synced.GetUID()))
+		})
+	})
+
+	Context("with object events", func() {
+		// getEventReasons returns the reasons of the events recorded on the object
+		getEventReasons := func(ctx context.Context, obj client.Object) func() ([]string, error) {
+			return func() ([]string, error) {
+				events := &corev1.EventList{}
+				if err := k8sClient.List(ctx, events, client.InNamespace(obj.GetNamespace())); err != nil {
+					return nil, err
+				}
+
+				reasons := make([]string, 0)
+				for _, event := range events.Items {
+					if event.InvolvedObject.UID == obj.GetUID() {
+						reasons = append(reasons, event.Reason)
+					}
+				}
+
+				return reasons, nil
+			}
+		}
+
+		It("records the sync events on the synced objects if the rule enables it", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("object-events-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			rule.Spec.EventTarget = clusterregistryv1alpha1.EventTargetObject
+			// the rule exists, so the events could be recorded on it
+			Expect(k8sClient.Create(ctx, rule)).Should(Succeed())
+			startSyncReconciler(ctx, rule)
+
+			source := newSyncTestConfigMap(rule.Name)
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			synced := &corev1.ConfigMap{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncTestKey(source), synced)
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(getEventReasons(ctx, synced), timeout, interval).Should(ContainElement("ObjectSynced"))
+			Consistently(getEventReasons(ctx, rule), time.Second*2, interval).ShouldNot(ContainElement("ObjectReconciled"))
+		})
+	})
+
+	Context("sync lag", func() {
+		// getSyncLag returns the number and the sum of the sync lag samples of the rule
+		getSyncLag := func(rule *clusterregistryv1alpha1.ResourceSyncRule) (uint64, float64) {
+			families, err := metrics.Registry.Gather()
+			Expect(err).ToNot(HaveOccurred())
+
+			for _, family := range families {
+				if family.GetName() != "cluster_registry_sync_lag_seconds" {
+					continue
+				}
+				for _, metric := range family.GetMetric() {
+					for _, label := range metric.GetLabel() {
+						if label.GetName() == "rule" && label.GetValue() == rule.GetName() {
+							return metric.GetHistogram().GetSampleCount(), metric.GetHistogram().GetSampleSum()
+						}
+					}
+				}
+			}
+
+			return 0, 0
+		}
+
+		It("measures the sync lag from the modification time set by the push side", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("sync-lag-test", resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+			startSyncReconciler(ctx, rule)
+
+			By("creating a source object modified a minute ago")
+			source := newSyncTestConfigMap(rule.Name)
+			source.Annotations = map[string]string{
+				clusterregistryv1alpha1.SourceModifiedAtAnnotation: time.Now().Add(-time.Minute).Format(time.RFC3339),
+			}
+			Expect(k8sClient.Create(ctx, source)).Should(Succeed())
+
+			Eventually(func() error {
+				return k8sClient.Get(ctx, syncTestKey(source), &corev1.ConfigMap{})
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func() uint64 {
+				count, _ := getSyncLag(rule)
+
+				return count
+			}, timeout, interval).Should(BeNumerically(">=", 1))
+
+			count, sum := getSyncLag(rule)
+			Expect(sum / float64(count)).Should(BeNumerically(">=", time.Minute.Seconds()))
+		})
+	})
+
+	Context("for the Cluster resources", func() {
+		newCluster := func(name string, clusterID types.UID, labels map[string]string) *clusterregistryv1alpha1.Cluster {
+			return &clusterregistryv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: labels,
+				},
+				Spec: clusterregistryv1alpha1.ClusterSpec{
+					ClusterID: clusterID,
+				},
+			}
+		}
+
+		It("never overwrites the Cluster resource of the local cluster", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			rule := newSyncTestRule("self-cluster-test", resources.GroupVersionKind(clusterregistryv1alpha1.GroupVersion.WithKind("Cluster")))
+			startSyncReconciler(ctx, rule, controllers.WithLocalClusterIDResolver(controllers.NewLocalClusterIDResolver("self-cluster-test-id", logr.Discard())))
+			matching := map[string]string{rule.Name: "true"}
+
+			By("creating the local Cluster resource and a copy of another cluster with its name")
+			self := newCluster(rule.Name+"-peer-synced", "self-cluster-test-id", nil)
+			Expect(k8sClient.Create(ctx, self)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, newCluster(rule.Name+"-peer", "peer-cluster-test-id", matching))).Should(Succeed())
+
+			By("creating a copy of the local Cluster resource and the one of another cluster")
+			Expect(k8sClient.Create(ctx, newCluster(rule.Name+"-self", "self-cluster-test-id", matching))).Should(Succeed())
+			other := newCluster(rule.Name+"-other", "other-cluster-test-id", matching)
+			Expect(k8sClient.Create(ctx, other)).Should(Succeed())
+
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: other.Name + "-synced"}, &clusterregistryv1alpha1.Cluster{})
+			}, timeout, interval).Should(Succeed())
+
+			Consistently(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: rule.Name + "-self-synced"}, &clusterregistryv1alpha1.Cluster{}))
+			}, time.Second*2, interval).Should(BeTrue())
+
+			current := &clusterregistryv1alpha1.Cluster{}
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(self), current)).Should(Succeed())
+			Expect(current.Spec.ClusterID).Should(Equal(self.Spec.ClusterID))
+			Expect(current.GetAnnotations()).ShouldNot(HaveKey(clusterregistryv1alpha1.OwnershipAnnotation))
+		})
+	})
+})
@@ -0,0 +1,107 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// isSelfClusterObject returns whether the desired object would overwrite the Cluster resource describing the local
+// cluster or its credential Secret, they are managed by the local controller and never taken from another registry
+func (r *syncReconciler) isSelfClusterObject(ctx context.Context, desired client.Object) (bool, error) {
+	localClusterID := types.UID(r.getLocalClusterID())
+	if localClusterID == "" {
+		return false, nil
+	}
+
+	gvk := r.getLocalGVK()
+	switch gvk.GroupKind() {
+	case clusterregistryv1alpha1.GroupVersion.WithKind("Cluster").GroupKind():
+		cluster, err := toCluster(desired)
+		if err != nil {
+			return false, err
+		}
+		if cluster.Spec.ClusterID == localClusterID {
+			return true, nil
+		}
+
+		// a copy of another cluster with the name of the local one is not synced onto it either
+		current := &clusterregistryv1alpha1.Cluster{}
+		err = r.localClient.Get(ctx, client.ObjectKeyFromObject(desired), current)
+		if client.IgnoreNotFound(err) != nil {
+			return false, errors.WrapIf(err, "could not get local cluster")
+		}
+
+		return err == nil && current.Spec.ClusterID == localClusterID, nil
+	case corev1.SchemeGroupVersion.WithKind("Secret").GroupKind():
+		if getSecretType(desired) != clusterregistryv1alpha1.SecretTypeClusterRegistry {
+			return false, nil
+		}
+
+		clusters, err := GetClusters(ctx, r.localClient)
+		if err != nil {
+			return false, errors.WrapIf(err, "could not get clusters")
+		}
+		self, ok := clusters[localClusterID]
+		if !ok {
+			return false, nil
+		}
+		ref := self.Spec.AuthInfo.SecretRef
+
+		return ref.Name == desired.GetName() && ref.Namespace == desired.GetNamespace(), nil
+	default:
+		return false, nil
+	}
+}
+
+// toCluster returns the desired object as a Cluster, it is unstructured if the Cluster kind is not in the scheme
+func toCluster(obj client.Object) (*clusterregistryv1alpha1.Cluster, error) {
+	if cluster, ok := obj.(*clusterregistryv1alpha1.Cluster); ok {
+		return cluster, nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not convert object")
+	}
+	cluster := &clusterregistryv1alpha1.Cluster{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, cluster); err != nil {
+		return nil, errors.WrapIf(err, "could not convert object to cluster")
+	}
+
+	return cluster, nil
+}
+
+func getSecretType(obj client.Object) corev1.SecretType {
+	if secret, ok := obj.(*corev1.Secret); ok {
+		return secret.Type
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return ""
+	}
+	secretType, _ := content["type"].(string)
+
+	return corev1.SecretType(secretType)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// IsRuleUpdatableInPlace returns whether the sync controllers of the actual rule can sync the objects by the desired
+// rule without being regenerated. The controllers are regenerated if the kinds they watch, the clusters they run for,
+// their queues or the identity and caches they read and write the objects with change, any other field, e.g. the
+// selectors and the mutations of the rule, is evaluated per object.
+func IsRuleUpdatableInPlace(actual, desired *clusterregistryv1alpha1.ResourceSyncRule) bool {
+	a, d := actual.Spec, desired.Spec
+
+	return reflect.DeepEqual(a.GVK, d.GVK) &&
+		reflect.DeepEqual(a.Versions, d.Versions) &&
+		getLocalKind(a, schema.GroupVersionKind(a.GVK)) == getLocalKind(d, schema.GroupVersionKind(d.GVK)) &&
+		a.VersionFallback == d.VersionFallback &&
+		reflect.DeepEqual(a.ClusterFeatureMatches, d.ClusterFeatureMatches) &&
+		a.Workers == d.Workers &&
+		reflect.DeepEqual(a.Backoff, d.Backoff) &&
+		a.ServiceAccountName == d.ServiceAccountName &&
+		a.ReadMode == d.ReadMode &&
+		a.AnchorOwnership == d.AnchorOwnership &&
+		reflect.DeepEqual(a.Verification, d.Verification) &&
+		reflect.DeepEqual(getCABundleSources(a), getCABundleSources(d))
+}
+
+// getLocalKind returns the local kind the objects of the kind are synced to by the spec
+func getLocalKind(spec clusterregistryv1alpha1.ResourceSyncRuleSpec, gvk schema.GroupVersionKind) schema.GroupVersionKind {
+	if spec.NormalizeToVersion != "" {
+		gvk.Version = spec.NormalizeToVersion
+	}
+	_, gvk = clusterregistryv1alpha1.MatchedRules(spec.Rules).GetMutatedGVK(gvk)
+
+	return gvk
+}
+
+// setRule sets the rule with the state derived from it, the reconciles running meanwhile finish with the previous rule
+func (r *syncReconciler) setRule(rule *clusterregistryv1alpha1.ResourceSyncRule) error {
+	var syncWindow *util.SyncWindow
+	if rule.Spec.SyncWindow != nil {
+		var err error
+		if syncWindow, err = util.NewSyncWindow(*rule.Spec.SyncWindow); err != nil {
+			return errors.WrapIf(err, "invalid sync window")
+		}
+	}
+
+	keyRemovals, err := util.NewKeyRemovals(rule.Spec.Rules)
+	if err != nil {
+		return errors.WrapIf(err, "invalid annotation or label removals")
+	}
+
+	r.ruleMu.Lock()
+	r.rule = rule
+	r.syncWindow = syncWindow
+	r.keyRemovals = keyRemovals
+	r.ruleMu.Unlock()
+
+	r.SetLogLevel(getRuleLogLevel(rule, r.GetLogger()))
+
+	// routed objects are looked up by their source key even before any of them is reconciled, e.g. to be deleted
+	for _, syncRule := range rule.Spec.Rules {
+		if syncRule.Mutations.NamespaceRouting != nil {
+			r.setKeyMutated(false, true)
+		}
+	}
+
+	return nil
+}
+
+func (r *syncReconciler) getSyncWindow() *util.SyncWindow {
+	r.ruleMu.RLock()
+	defer r.ruleMu.RUnlock()
+
+	return r.syncWindow
+}
+
+func (r *syncReconciler) getKeyRemovals() *util.KeyRemovals {
+	r.ruleMu.RLock()
+	defer r.ruleMu.RUnlock()
+
+	return r.keyRemovals
+}
+
+// UpdateRule syncs the objects by the updated rule without regenerating the controller, so its watches and the
+// informer caches of the source cluster are kept. Every object of the rule is reconciled again through the queue of the
+// controller, and the generation of the rule is recorded in its status for the cluster once they are.
+func (r *syncReconciler) UpdateRule(ctx context.Context, rule *clusterregistryv1alpha1.ResourceSyncRule) error {
+	if !IsRuleUpdatableInPlace(r.getRule(), rule) {
+		return errors.NewWithDetails("rule can not be updated in place", "rule", rule.GetName())
+	}
+
+	if err := r.setRule(rule); err != nil {
+		return err
+	}
+
+	r.GetLogger().Info("rule updated in place", "generation", rule.GetGeneration())
+
+	if r.ruleRegistry != nil {
+		r.ruleRegistry.Register(r.clusterName, rule, r.enqueueOverriddenObjects)
+	}
+
+	// syncing the status of the objects needs more permissions
+	r.requestAccessCheck()
+
+	r.convergeMu.Lock()
+	startCtx := r.startCtx
+	r.convergeMu.Unlock()
+	if startCtx != nil {
+		r.startConvergence(startCtx)
+	}
+
+	// the objects unchanged since their last sync are synced again, their mutations could have changed
+	return r.Resync(ctx)
+}
+
+// startConvergence tracks whether the objects of the rule are synced, the tracking started earlier is stopped
+func (r *syncReconciler) startConvergence(ctx context.Context) {
+	r.convergeMu.Lock()
+	defer r.convergeMu.Unlock()
+
+	if r.convergeCancel != nil {
+		r.convergeCancel()
+	}
+
+	r.startCtx = ctx
+	r.converged = false
+
+	convergeCtx, cancel := context.WithCancel(ctx)
+	r.convergeCancel = cancel
+
+	go r.waitForConvergence(convergeCtx, r.getRule().GetGeneration())
+}
+
+// setObservedGeneration records the generation of the rule the objects of the cluster are synced by
+func (r *syncReconciler) setObservedGeneration(ctx context.Context, generation int64) error {
+	if r.getRule().GetUID() == "" {
+		return nil
+	}
+
+	return errors.WrapIf(SetResourceSyncRuleClusterStatus(ctx, r.localMgr.GetClient(), r.getRule().GetName(), r.clusterName, func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus) {
+		status.ObservedGeneration = generation
+	}), "could not update rule status")
+}
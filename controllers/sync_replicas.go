@@ -0,0 +1,79 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	objectsync "github.com/cisco-open/cluster-registry-controller/pkg/sync"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// autoscalerChecker returns the checker of the local HorizontalPodAutoscalers, they are read from the API server, so the
+// objects are not synced with the replicas of an autoscaler created right before
+func (r *syncReconciler) autoscalerChecker(ctx context.Context) objectsync.AutoscalerChecker {
+	return func(obj client.Object) (bool, error) {
+		if obj.GetNamespace() == "" {
+			return false, nil
+		}
+
+		autoscalers := &autoscalingv1.HorizontalPodAutoscalerList{}
+		if err := r.localMgr.GetAPIReader().List(ctx, autoscalers, client.InNamespace(obj.GetNamespace())); err != nil {
+			return false, errors.WrapIf(err, "could not list local autoscalers")
+		}
+
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		for _, autoscaler := range autoscalers.Items {
+			target := autoscaler.Spec.ScaleTargetRef
+			gv, err := schema.ParseGroupVersion(target.APIVersion)
+			if err != nil {
+				continue
+			}
+
+			if gv.Group == gvk.Group && target.Kind == gvk.Kind && target.Name == obj.GetName() {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+}
+
+// keepReplicas keeps the local replicas of the objects whose replica field is left to the local autoscalers
+func keepReplicas(replicas *clusterregistryv1alpha1.ReplicaMutations) func(current, desired runtime.Object) error {
+	return func(current, desired runtime.Object) error {
+		if replicas == nil || !replicas.IgnoreIfHPAManaged {
+			return nil
+		}
+
+		currentObj, ok := current.(client.Object)
+		if !ok {
+			return errors.New("invalid current object")
+		}
+		desiredObj, ok := desired.(client.Object)
+		if !ok {
+			return errors.New("invalid desired object")
+		}
+
+		return errors.WrapIf(util.KeepReplicas(currentObj, desiredObj, *replicas), "could not keep local replicas")
+	}
+}
@@ -0,0 +1,428 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/cisco-open/cluster-registry-controller/internal/config"
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+// SharedSourceWatches are the watches of the source kinds shared by the sync controllers of every rule watching the
+// same kind on the same cluster, so rules which differ only in their matchers do not list and watch the kind each.
+// Every rule keeps its own queue and workers, the shared watch dispatches the events to the rules they match.
+type SharedSourceWatches struct {
+	configuration config.Configuration
+	log           logr.Logger
+
+	watches map[string]*sharedSourceWatch
+	mu      sync.Mutex
+}
+
+func NewSharedSourceWatches(configuration config.Configuration, log logr.Logger) *SharedSourceWatches {
+	return &SharedSourceWatches{
+		configuration: configuration,
+		log:           log,
+
+		watches: make(map[string]*sharedSourceWatch),
+	}
+}
+
+// sharedSourceWatch is the informer of a source kind on a cluster with the rules attached to it
+type sharedSourceWatch struct {
+	key     string
+	cluster string
+	gvk     schema.GroupVersionKind
+	mgr     ctrl.Manager
+	log     logr.Logger
+
+	cache     cache.Cache
+	cancel    context.CancelFunc
+	listPager *clusters.ListPager
+
+	informer   cache.Informer
+	informerMu sync.Mutex
+
+	// users are the sync reconcilers of the rules using the watch, the watch is stopped once the last one releases it.
+	// The reconciler of a regenerated rule can acquire the watch before the previous one releases it.
+	users map[*syncReconciler]struct{}
+	// subscribers are the event handlers of the running sync controllers of the rules
+	subscribers   map[*sharedSource]sharedWatchSubscriber
+	subscribersMu sync.RWMutex
+}
+
+type sharedWatchSubscriber struct {
+	handler    handler.EventHandler
+	queue      workqueue.RateLimitingInterface
+	predicates []predicate.Predicate
+}
+
+// acquire returns the shared watch of the kind on the cluster served by the manager, the watch is created on its first
+// use and has to be released by every rule acquiring it
+func (s *SharedSourceWatches) acquire(mgr ctrl.Manager, cluster string, gvk schema.GroupVersionKind, user *syncReconciler) (*sharedSourceWatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := cluster + "/" + gvk.String()
+
+	// a watch created with the previous manager of the cluster is left to the rules still using it
+	if w, ok := s.watches[key]; ok && w.mgr == mgr {
+		w.users[user] = struct{}{}
+		syncSharedWatchRules.WithLabelValues(cluster, gvk.String()).Set(float64(len(w.users)))
+
+		return w, nil
+	}
+
+	listPager := newListPager(s.configuration)
+	c, err := newCacheFunc(s.configuration, listPager)(mgr.GetConfig(), cache.Options{
+		Scheme: mgr.GetScheme(),
+		Mapper: mgr.GetRESTMapper(),
+	})
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not create shared cache", "cluster", cluster, "gvk", gvk)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &sharedSourceWatch{
+		key:     key,
+		cluster: cluster,
+		gvk:     gvk,
+		mgr:     mgr,
+		log:     s.log.WithValues("cluster", cluster, "gvk", gvk),
+
+		cache:     c,
+		cancel:    cancel,
+		listPager: listPager,
+
+		users:       map[*syncReconciler]struct{}{user: {}},
+		subscribers: make(map[*sharedSource]sharedWatchSubscriber),
+	}
+
+	go func() {
+		if err := c.Start(ctx); err != nil {
+			w.log.Error(err, "could not start shared cache")
+		}
+		w.log.Info("shared cache stopped")
+	}()
+
+	s.watches[key] = w
+	syncSharedWatchRules.WithLabelValues(cluster, gvk.String()).Set(1)
+
+	w.log.Info("shared watch started", "rule", user.rule.GetName())
+
+	return w, nil
+}
+
+// release stops the watch once no rule uses it, the sources of the stopped controllers are detached already
+func (s *SharedSourceWatches) release(w *sharedSourceWatch, user *syncReconciler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(w.users, user)
+	current := s.watches[w.key] == w
+	if current {
+		syncSharedWatchRules.WithLabelValues(w.cluster, w.gvk.String()).Set(float64(len(w.users)))
+	}
+	if len(w.users) > 0 {
+		return
+	}
+
+	w.cancel()
+	if current {
+		delete(s.watches, w.key)
+		syncSharedWatchRules.DeleteLabelValues(w.cluster, w.gvk.String())
+	}
+
+	w.log.Info("shared watch stopped", "rule", user.rule.GetName())
+}
+
+// newSourceClient returns the client of the rule reading the source objects from the shared cache, the other objects
+// are read with the client of the sync controller of the rule
+func (w *sharedSourceWatch) newSourceClient(c client.Client) (client.Client, error) {
+	return client.NewDelegatingClient(client.NewDelegatingClientInput{
+		CacheReader: w.cache,
+		Client:      c,
+	})
+}
+
+// getInformer returns the informer of the kind, the events of the informer are dispatched to the attached rules
+func (w *sharedSourceWatch) getInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	w.informerMu.Lock()
+	defer w.informerMu.Unlock()
+
+	if w.informer != nil {
+		return w.informer, nil
+	}
+
+	informer, err := w.cache.GetInformer(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    w.onAdd,
+		UpdateFunc: w.onUpdate,
+		DeleteFunc: w.onDelete,
+	})
+	w.informer = informer
+
+	return informer, nil
+}
+
+// attach subscribes the handler of the rule to the events of the watch, the objects already in the cache are sent to it
+// as created, as the informer sent them to the rules attached before
+func (w *sharedSourceWatch) attach(ctx context.Context, src *sharedSource, subscriber sharedWatchSubscriber) error {
+	w.subscribersMu.Lock()
+	w.subscribers[src] = subscriber
+	w.subscribersMu.Unlock()
+
+	objects, err := w.listObjects(ctx)
+	if err != nil {
+		return errors.WrapIf(err, "could not list cached objects")
+	}
+	for _, obj := range objects {
+		subscriber.create(event.CreateEvent{Object: obj})
+	}
+
+	w.log.V(1).Info("rule attached", "rule", src.rule, "objects", len(objects))
+
+	return nil
+}
+
+func (w *sharedSourceWatch) detach(src *sharedSource) {
+	w.subscribersMu.Lock()
+	defer w.subscribersMu.Unlock()
+
+	delete(w.subscribers, src)
+}
+
+func (w *sharedSourceWatch) listObjects(ctx context.Context) ([]client.Object, error) {
+	var list client.ObjectList = &unstructured.UnstructuredList{}
+	list.GetObjectKind().SetGroupVersionKind(w.gvk.GroupVersion().WithKind(w.gvk.Kind + "List"))
+	if o, err := w.mgr.GetScheme().New(list.GetObjectKind().GroupVersionKind()); err == nil {
+		if typed, ok := o.(client.ObjectList); ok {
+			list = typed
+		}
+	}
+
+	if err := w.cache.List(ctx, list); err != nil {
+		return nil, err
+	}
+
+	objects := make([]client.Object, 0)
+	err := meta.EachListItem(list, func(o runtime.Object) error {
+		if obj, ok := o.(client.Object); ok {
+			objects = append(objects, obj)
+		}
+
+		return nil
+	})
+
+	return objects, errors.WithStackIf(err)
+}
+
+func (w *sharedSourceWatch) getSubscribers() []sharedWatchSubscriber {
+	w.subscribersMu.RLock()
+	defer w.subscribersMu.RUnlock()
+
+	subscribers := make([]sharedWatchSubscriber, 0, len(w.subscribers))
+	for _, subscriber := range w.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+
+	return subscribers
+}
+
+func (w *sharedSourceWatch) onAdd(obj interface{}) {
+	o, ok := obj.(client.Object)
+	if !ok {
+		return
+	}
+
+	for _, subscriber := range w.getSubscribers() {
+		subscriber.create(event.CreateEvent{Object: o})
+	}
+}
+
+func (w *sharedSourceWatch) onUpdate(oldObj, newObj interface{}) {
+	oldO, ok := oldObj.(client.Object)
+	if !ok {
+		return
+	}
+	newO, ok := newObj.(client.Object)
+	if !ok {
+		return
+	}
+
+	for _, subscriber := range w.getSubscribers() {
+		subscriber.update(event.UpdateEvent{ObjectOld: oldO, ObjectNew: newO})
+	}
+}
+
+func (w *sharedSourceWatch) onDelete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	o, ok := obj.(client.Object)
+	if !ok {
+		return
+	}
+
+	for _, subscriber := range w.getSubscribers() {
+		subscriber.delete(event.DeleteEvent{Object: o})
+	}
+}
+
+func (s sharedWatchSubscriber) create(e event.CreateEvent) {
+	for _, p := range s.predicates {
+		if !p.Create(e) {
+			return
+		}
+	}
+
+	s.handler.Create(e, s.queue)
+}
+
+func (s sharedWatchSubscriber) update(e event.UpdateEvent) {
+	for _, p := range s.predicates {
+		if !p.Update(e) {
+			return
+		}
+	}
+
+	s.handler.Update(e, s.queue)
+}
+
+func (s sharedWatchSubscriber) delete(e event.DeleteEvent) {
+	for _, p := range s.predicates {
+		if !p.Delete(e) {
+			return
+		}
+	}
+
+	s.handler.Delete(e, s.queue)
+}
+
+// sharedSource is the source of the sync controller of a rule attached to a shared watch, the rule is detached when
+// its controller stops
+type sharedSource struct {
+	watch *sharedSourceWatch
+	rule  string
+	obj   client.Object
+
+	started chan error
+}
+
+func (s *sharedSource) Start(ctx context.Context, h handler.EventHandler, queue workqueue.RateLimitingInterface, predicates ...predicate.Predicate) error {
+	s.started = make(chan error, 1)
+
+	go func() {
+		if _, err := s.watch.getInformer(ctx, s.obj); err != nil {
+			s.started <- err
+
+			return
+		}
+		if !s.watch.cache.WaitForCacheSync(ctx) {
+			s.started <- errors.New("shared cache did not sync")
+
+			return
+		}
+
+		err := s.watch.attach(ctx, s, sharedWatchSubscriber{
+			handler:    h,
+			queue:      queue,
+			predicates: predicates,
+		})
+		if err != nil {
+			s.watch.detach(s)
+			s.started <- err
+
+			return
+		}
+		close(s.started)
+
+		<-ctx.Done()
+		s.watch.detach(s)
+	}()
+
+	return nil
+}
+
+// WaitForSync implements source.SyncingSource, so the workers of the controller are started once the rule is attached
+func (s *sharedSource) WaitForSync(ctx context.Context) error {
+	select {
+	case err := <-s.started:
+		return err
+	case <-ctx.Done():
+		return errors.WrapIf(ctx.Err(), "timed out waiting for shared cache to sync")
+	}
+}
+
+func (s *sharedSource) String() string {
+	return fmt.Sprintf("shared source: %s", s.watch.key)
+}
+
+// getSourceWatch returns the source of the events of the source objects, which is the shared watch of the kind on the
+// cluster if the watches are shared, it must be called with setupMu held
+func (r *syncReconciler) getSourceWatch(gvk schema.GroupVersionKind, obj client.Object) (source.Source, error) {
+	if r.sharedWatches == nil {
+		return &source.Kind{
+			Type: obj,
+		}, nil
+	}
+
+	if r.sharedWatch == nil {
+		w, err := r.sharedWatches.acquire(r.GetManager(), r.clusterID, gvk, r)
+		if err != nil {
+			return nil, err
+		}
+
+		sourceClient, err := w.newSourceClient(r.GetClient())
+		if err != nil {
+			r.sharedWatches.release(w, r)
+
+			return nil, errors.WrapIf(err, "could not create source client")
+		}
+
+		r.sharedWatch = w
+		r.sourceClient = sourceClient
+		// the warm-up of the shared cache is reported instead of the one of the cache of the controller
+		r.listPager = w.listPager
+	}
+
+	return &sharedSource{
+		watch: r.sharedWatch,
+		rule:  r.getRule().GetName(),
+		obj:   obj,
+	}, nil
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// maxListedOversizedObjects limits the oversized objects listed in the status of the rule, the rest is only counted
+const maxListedOversizedObjects = 20
+
+// oversizedObject is a source object which is not synced, because it is larger than the maximum object size
+type oversizedObject struct {
+	size  int64
+	since time.Time
+}
+
+// getMaxObjectSize returns the largest size of the synced source objects, 0 disables the limit
+func (r *syncReconciler) getMaxObjectSize() int64 {
+	if r.getRule().Spec.MaxObjectSize != nil {
+		return r.getRule().Spec.MaxObjectSize.Value()
+	}
+
+	return r.maxObjectSize
+}
+
+// isOversized returns whether the source object is larger than the maximum object size. Oversized objects are skipped
+// instead of retried, they are synced again once their source shrinks.
+func (r *syncReconciler) isOversized(ctx context.Context, key types.NamespacedName, obj client.Object, log logr.Logger) (bool, error) {
+	limit := r.getMaxObjectSize()
+	if limit <= 0 {
+		r.forgetOversizedObject(ctx, key)
+
+		return false, nil
+	}
+
+	size, err := util.ObjectSize(obj)
+	if err != nil {
+		return false, errors.WrapIf(err, "could not get object size")
+	}
+	if size <= limit {
+		r.forgetOversizedObject(ctx, key)
+
+		return false, nil
+	}
+
+	r.oversizedMu.Lock()
+	previous, known := r.oversizedObjects[key]
+	since := time.Now()
+	if known {
+		since = previous.since
+	}
+	r.oversizedObjects[key] = oversizedObject{
+		size:  size,
+		since: since,
+	}
+	r.oversizedMu.Unlock()
+
+	log.V(1).Info("object is larger than the maximum object size, skipping", "size", size, "maxSize", limit)
+	if known && previous.size == size {
+		return true, nil
+	}
+
+	if !known {
+		r.recordEvent(corev1.EventTypeWarning, "ObjectSkippedOversized", fmt.Sprintf("object is larger than the maximum object size, it is not synced (resource: %s, size: %d, maxSize: %d)", key, size, limit))
+	}
+	if err := r.setOversizedObjectsStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not update oversized objects")
+	}
+
+	return true, nil
+}
+
+// forgetOversizedObject forgets the source object, e.g. because it is deleted or it is within the limit again
+func (r *syncReconciler) forgetOversizedObject(ctx context.Context, key types.NamespacedName) {
+	r.oversizedMu.Lock()
+	_, ok := r.oversizedObjects[key]
+	delete(r.oversizedObjects, key)
+	r.oversizedMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if err := r.setOversizedObjectsStatus(ctx); err != nil {
+		r.GetLogger().Error(err, "could not update oversized objects")
+	}
+}
+
+func (r *syncReconciler) setOversizedObjectsStatus(ctx context.Context) error {
+	r.oversizedMu.Lock()
+	keys := make([]types.NamespacedName, 0, len(r.oversizedObjects))
+	for key := range r.oversizedObjects {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+
+	objects := make([]clusterregistryv1alpha1.OversizedObject, 0, maxListedOversizedObjects)
+	for i, key := range keys {
+		if i == maxListedOversizedObjects {
+			break
+		}
+		objects = append(objects, clusterregistryv1alpha1.OversizedObject{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Size:      r.oversizedObjects[key].size,
+			// the API server stores the time with second precision
+			Since: metav1.NewTime(r.oversizedObjects[key].since.Truncate(time.Second)),
+		})
+	}
+	r.oversizedMu.Unlock()
+
+	syncOversizedObjects.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(len(keys)))
+
+	if r.getRule().GetUID() == "" {
+		return nil
+	}
+
+	return errors.WrapIf(SetResourceSyncRuleClusterStatus(ctx, r.localMgr.GetClient(), r.getRule().GetName(), r.clusterName, func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus) {
+		status.OversizedObjectCount = len(keys)
+		if len(objects) == 0 {
+			status.OversizedObjects = nil
+		} else {
+			status.OversizedObjects = objects
+		}
+	}), "could not update rule status")
+}
+
+// isLargeObjectUnchanged returns whether the desired object is larger than the size limit of the last applied
+// annotation and the current object still matches it. The objects above the limit are written without the annotation,
+// so the three-way patch of the object matcher would find them changed on every reconcile. The live object is compared
+// field by field, since its content hash annotation does not change when the object is modified locally.
+func (r *syncReconciler) isLargeObjectUnchanged(current, desired runtime.Object) (bool, error) {
+	if r.lastAppliedSizeLimit <= 0 {
+		return false, nil
+	}
+
+	currentObj, ok := current.(client.Object)
+	if !ok {
+		return false, errors.New("invalid object")
+	}
+	desiredObj, ok := desired.(client.Object)
+	if !ok {
+		return false, errors.New("invalid object")
+	}
+
+	size, err := util.ObjectSize(desiredObj)
+	if err != nil || size <= r.lastAppliedSizeLimit {
+		return false, errors.WrapIf(err, "could not get object size")
+	}
+
+	if hash := util.GetContentHash(desiredObj); hash == "" || util.GetContentHash(currentObj) != hash {
+		return false, nil
+	}
+
+	// the fields of the desired object changed or removed locally
+	paths, err := util.DriftedPaths(desiredObj, currentObj)
+	if err != nil || len(paths) > 0 {
+		return false, errors.WrapIf(err, "could not compare object")
+	}
+
+	// the fields added locally, the metadata and the status written by others are not compared
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(currentObj)
+	if err != nil {
+		return false, errors.WrapIf(err, "could not convert object to unstructured")
+	}
+	delete(content, "metadata")
+	delete(content, "status")
+
+	paths, err = util.DriftedPaths(&unstructured.Unstructured{Object: content}, desiredObj)
+	if err != nil {
+		return false, errors.WrapIf(err, "could not compare object")
+	}
+
+	return len(paths) == 0, nil
+}
+
+// withLastAppliedSizeLimit returns the client writing the objects above the size limit without the last applied
+// annotation, it holds a compressed copy of the object, which could push it over the size limit of the API server
+func (r *syncReconciler) withLastAppliedSizeLimit(c client.Client) client.Client {
+	if r.lastAppliedSizeLimit <= 0 {
+		return c
+	}
+
+	return &lastAppliedLimitClient{
+		Client: c,
+		limit:  r.lastAppliedSizeLimit,
+	}
+}
+
+type lastAppliedLimitClient struct {
+	client.Client
+	limit int64
+}
+
+func (c *lastAppliedLimitClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.removeLastApplied(obj); err != nil {
+		return err
+	}
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *lastAppliedLimitClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.removeLastApplied(obj); err != nil {
+		return err
+	}
+
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *lastAppliedLimitClient) removeLastApplied(obj client.Object) error {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[patch.LastAppliedConfig]; !ok {
+		return nil
+	}
+
+	size, err := util.ObjectSize(obj)
+	if err != nil {
+		return errors.WrapIf(err, "could not get object size")
+	}
+	if size > c.limit {
+		delete(annotations, patch.LastAppliedConfig)
+		obj.SetAnnotations(annotations)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	objectsync "github.com/cisco-open/cluster-registry-controller/pkg/sync"
+)
+
+// storageClassWaitInterval is the interval at which the claims are retried while their storage class does not exist
+// locally, the storage class watch resumes them right away once it is created
+const storageClassWaitInterval = time.Minute
+
+// storageClassChecker returns the checker of the local storage classes, they are read from the local cache, which
+// is watched by watchStorageClasses
+func (r *syncReconciler) storageClassChecker(ctx context.Context) objectsync.StorageClassChecker {
+	return func(name string) (bool, error) {
+		err := r.localClient.Get(ctx, types.NamespacedName{Name: name}, &storagev1.StorageClass{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.WrapIf(err, "could not get storage class")
+		}
+
+		return true, nil
+	}
+}
+
+// getMissingStorageClass returns the name of the storage class in the details of util.ErrStorageClassNotFound
+func getMissingStorageClass(err error) string {
+	details := errors.GetDetails(err)
+	for i := 0; i+1 < len(details); i += 2 {
+		if details[i] == "storageClass" {
+			name, _ := details[i+1].(string)
+
+			return name
+		}
+	}
+
+	return ""
+}
+
+// waitForStorageClass holds the claim until its storage class is created locally
+func (r *syncReconciler) waitForStorageClass(ctx context.Context, key types.NamespacedName, storageClass string) (ctrl.Result, error) {
+	r.storageClassMu.Lock()
+	previous, ok := r.waitingClaims[key]
+	r.waitingClaims[key] = storageClass
+	condition := r.getWaitingForStorageClassCondition()
+	r.storageClassMu.Unlock()
+
+	if err := r.watchStorageClasses(ctx); err != nil {
+		r.GetLogger().Error(err, "could not watch storage classes, claims are retried periodically")
+	}
+
+	result := ctrl.Result{
+		RequeueAfter: storageClassWaitInterval,
+	}
+
+	if ok && previous == storageClass {
+		return result, nil
+	}
+
+	msg := fmt.Sprintf("claim is not synced until storage class %s exists locally (resource: %s)", storageClass, key)
+	r.recordEvent(corev1.EventTypeWarning, "WaitingForStorageClass", msg)
+	r.GetLogger().Info(msg, "storageClass", storageClass)
+
+	return result, r.setClusterCondition(ctx, condition)
+}
+
+// releaseStorageClassWaitingObject reports that the object does not wait for its storage class anymore
+func (r *syncReconciler) releaseStorageClassWaitingObject(ctx context.Context, key types.NamespacedName) error {
+	r.storageClassMu.Lock()
+	if _, ok := r.waitingClaims[key]; !ok {
+		r.storageClassMu.Unlock()
+
+		return nil
+	}
+	delete(r.waitingClaims, key)
+	condition := r.getWaitingForStorageClassCondition()
+	r.storageClassMu.Unlock()
+
+	return r.setClusterCondition(ctx, condition)
+}
+
+// watchStorageClasses resumes the claims waiting for a storage class as soon as it is created
+func (r *syncReconciler) watchStorageClasses(ctx context.Context) error {
+	r.setupMu.Lock()
+	defer r.setupMu.Unlock()
+
+	key := "local/" + storagev1.SchemeGroupVersion.WithKind("StorageClass").String()
+	if _, ok := r.watches[key]; ok || r.ctrl == nil || r.localCache == nil {
+		return nil
+	}
+
+	informer, err := r.localCache.GetInformer(ctx, &storagev1.StorageClass{})
+	if err != nil {
+		return errors.WrapIf(err, "could not create local informer for storage classes")
+	}
+
+	return errors.WrapIf(r.watch(r.ctrl, key, &source.Informer{
+		Informer: informer,
+	}, handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			r.storageClassMu.Lock()
+			defer r.storageClassMu.Unlock()
+
+			for key, storageClass := range r.waitingClaims {
+				if storageClass == e.Object.GetName() {
+					q.Add(reconcile.Request{NamespacedName: key})
+				}
+			}
+		},
+	}), "could not create watch for storage classes")
+}
+
+// getWaitingForStorageClassCondition must be called with storageClassMu held
+func (r *syncReconciler) getWaitingForStorageClassCondition() metav1.Condition {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeWaitingForStorageClass,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "StorageClassesExist",
+		Message:            "no claim waits for its storage class",
+	}
+
+	if len(r.waitingClaims) == 0 {
+		return condition
+	}
+
+	missing := make(map[string]struct{})
+	for _, storageClass := range r.waitingClaims {
+		missing[storageClass] = struct{}{}
+	}
+	storageClasses := make([]string, 0, len(missing))
+	for storageClass := range missing {
+		storageClasses = append(storageClasses, storageClass)
+	}
+	sort.Strings(storageClasses)
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "StorageClassesMissing"
+	condition.Message = fmt.Sprintf("%d claims wait for the storage classes missing locally: %s", len(r.waitingClaims), strings.Join(storageClasses, ", "))
+
+	return condition
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/tracing"
+)
+
+// the names of the spans of the sync pipeline
+const (
+	spanReconcile         = "sync.reconcile"
+	spanRemoteGet         = "sync.remote_get"
+	spanMatch             = "sync.match"
+	spanMutate            = "sync.mutate"
+	spanReconcileResource = "sync.reconcile_resource"
+	spanStatusSync        = "sync.status_sync"
+	spanInformerInit      = "sync.informer_init"
+)
+
+// startSpan starts a span of the sync pipeline with the rule, the source cluster and the kind as attributes, the
+// attributes are only built while the span is recorded
+func (r *syncReconciler) startSpan(ctx context.Context, name string, key types.NamespacedName) (context.Context, trace.Span) {
+	ctx, span := tracing.Start(ctx, name)
+	if span.IsRecording() {
+		span.SetAttributes(
+			tracing.RuleKey.String(r.getRule().GetName()),
+			tracing.ClusterKey.String(r.clusterName),
+			tracing.GVKKey.String(r.GetSourceGVK().String()),
+		)
+		if key.Name != "" {
+			span.SetAttributes(tracing.ObjectKey.String(key.String()))
+		}
+	}
+
+	return ctx, span
+}
+
+// spanClient carries the span of the context it was created with into the calls of callers which do not pass the
+// context of the reconcile, e.g. the generic reconciler of the operator tools
+type spanClient struct {
+	client.Client
+
+	span trace.Span
+}
+
+// withSpan returns the client as it is unless the span of the context is recorded
+func withSpan(ctx context.Context, c client.Client) client.Client {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return c
+	}
+
+	return &spanClient{
+		Client: c,
+		span:   span,
+	}
+}
+
+func (c *spanClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return c.Client.Get(trace.ContextWithSpan(ctx, c.span), key, obj)
+}
+
+func (c *spanClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.Client.List(trace.ContextWithSpan(ctx, c.span), list, opts...)
+}
+
+func (c *spanClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return c.Client.Create(trace.ContextWithSpan(ctx, c.span), obj, opts...)
+}
+
+func (c *spanClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return c.Client.Delete(trace.ContextWithSpan(ctx, c.span), obj, opts...)
+}
+
+func (c *spanClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return c.Client.Update(trace.ContextWithSpan(ctx, c.span), obj, opts...)
+}
+
+func (c *spanClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.Client.Patch(trace.ContextWithSpan(ctx, c.span), obj, patch, opts...)
+}
+
+func (c *spanClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return c.Client.DeleteAllOf(trace.ContextWithSpan(ctx, c.span), obj, opts...)
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+	"github.com/cisco-open/cluster-registry-controller/pkg/verifier"
+)
+
+// maxListedDriftedObjects limits the drifted objects listed in the status of the rule, the rest is only counted
+const maxListedDriftedObjects = 20
+
+// startVerification starts the verifier of the rule, which runs until the controller is stopped
+func (r *syncReconciler) startVerification(ctx context.Context) {
+	if err := r.setVerificationStatus(ctx, nil); err != nil {
+		r.GetLogger().Error(err, "could not reset drifted objects")
+	}
+
+	verification := r.getRule().Spec.Verification
+	if verification == nil {
+		return
+	}
+
+	opts := []verifier.Option{
+		verifier.WithObjectsPerMinute(verification.GetObjectsPerMinute()),
+		verifier.WithGate(r.isVerificationAllowed),
+		verifier.WithReportFunc(r.reportVerification),
+		verifier.WithChangeFunc(func(ctx context.Context, findings []verifier.Finding) {
+			if err := r.setVerificationStatus(ctx, findings); err != nil {
+				r.GetLogger().Error(err, "could not update drifted objects")
+			}
+		}),
+		verifier.WithLogger(r.GetLogger().WithName("verifier")),
+	}
+	if verification.AutoRepair {
+		opts = append(opts, verifier.WithRepairFunc(r.repairDriftedObject))
+	}
+
+	v := verifier.New(r.listVerifiedObjects, r.renderDesiredState, opts...)
+
+	go func() {
+		_ = v.Start(ctx)
+	}()
+}
+
+// isVerificationAllowed pauses the verification while the rule is suspended, the source cluster is not alive
+// or the sync controller has pending work
+func (r *syncReconciler) isVerificationAllowed() bool {
+	if r.IsSuspended() || r.queue == nil || r.queue.Len() > 0 {
+		return false
+	}
+
+	return r.clustersManager.GetAliveClustersByID()[r.clusterID] != nil
+}
+
+// listVerifiedObjects returns the local objects synced from the cluster
+func (r *syncReconciler) listVerifiedObjects(ctx context.Context) ([]client.Object, error) {
+	if r.localClient == nil || r.isWaitingForCRD() {
+		return nil, nil
+	}
+
+	localGVK := r.getLocalGVK()
+
+	objects, err := r.listObjects(ctx, r.localClient, localGVK)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list local objects")
+	}
+
+	synced := make([]client.Object, 0, len(objects))
+	for _, obj := range objects {
+		if util.IsOwnedByRule(obj, r.clusterID, r.getRule().GetName()) && obj.GetDeletionTimestamp().IsZero() {
+			synced = append(synced, obj)
+		}
+	}
+
+	return synced, nil
+}
+
+// renderDesiredState renders the desired state of the synced object from its current source object the same way
+// as the object is reconciled. Objects not synced anymore, e.g. parked ones, have no desired state to compare with.
+func (r *syncReconciler) renderDesiredState(ctx context.Context, obj client.Object) (client.Object, bool, error) {
+	key := util.GetSourceObjectKey(obj)
+
+	r.parkedMu.Lock()
+	_, parked := r.parkedObjects[key]
+	r.parkedMu.Unlock()
+	if parked {
+		return nil, false, nil
+	}
+
+	source := r.initObjectFromGVK(r.GetSourceGVK())
+	err := r.getSourceReader().Get(ctx, key, source)
+	if apierrors.IsNotFound(err) || err == nil && !source.GetDeletionTimestamp().IsZero() {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.WrapIf(err, "could not get source object")
+	}
+
+	ok, matchedRules, err := r.getRule().Match(source)
+	if !ok || err != nil {
+		return nil, false, nil
+	}
+
+	desired, err := r.mutateObject(ctx, source, matchedRules)
+	if errors.Is(err, util.ErrEmptySecretData) || errors.Is(err, util.ErrNamespaceNotRouted) ||
+		errors.Is(err, util.ErrEndpointsServiceNotSynced) || errors.Is(err, util.ErrEndpointsServiceSelected) ||
+		errors.Is(err, util.ErrCABundleNotFound) || errors.Is(err, util.ErrStorageClassNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.WrapIf(err, "could not mutate object")
+	}
+
+	// stale copies left behind by the namespace routing are deleted by the next reconcile of the source object
+	if client.ObjectKeyFromObject(desired) != client.ObjectKeyFromObject(obj) {
+		return nil, false, nil
+	}
+
+	return desired, true, nil
+}
+
+func (r *syncReconciler) reportVerification(ctx context.Context, finding verifier.Finding, changed bool) {
+	result := "in_sync"
+	if finding.Drifted() {
+		result = "drifted"
+	}
+	syncVerifiedObjectsTotal.WithLabelValues(r.getRule().GetName(), r.clusterID, result).Inc()
+
+	if !changed || !finding.Drifted() {
+		return
+	}
+
+	msg := fmt.Sprintf("synced object differs from the desired state rendered from its source object (resource: %s, paths: %s)", finding.Key, strings.Join(finding.Paths, ", "))
+	r.recordEvent(corev1.EventTypeWarning, "VerificationDrift", msg)
+	r.GetLogger().Info(msg)
+}
+
+// repairDriftedObject enqueues the source object of the drifted object, so it is synced again
+func (r *syncReconciler) repairDriftedObject(ctx context.Context, obj client.Object) {
+	if r.queue == nil {
+		return
+	}
+
+	key := util.GetSourceObjectKey(obj)
+	r.recordEvent(corev1.EventTypeNormal, "VerificationDriftRepaired", fmt.Sprintf("drifted object is synced again (resource: %s)", key))
+
+	r.queue.Add(reconcile.Request{
+		NamespacedName: key,
+	})
+	r.observeQueueDepth()
+}
+
+func (r *syncReconciler) setVerificationStatus(ctx context.Context, findings []verifier.Finding) error {
+	if r.getRule().Spec.Verification != nil {
+		syncVerificationDriftedObjects.WithLabelValues(r.getRule().GetName(), r.clusterID).Set(float64(len(findings)))
+	}
+
+	if r.getRule().GetUID() == "" {
+		return nil
+	}
+
+	objects := make([]clusterregistryv1alpha1.DriftedObject, 0, maxListedDriftedObjects)
+	for i, finding := range findings {
+		if i == maxListedDriftedObjects {
+			break
+		}
+
+		objects = append(objects, clusterregistryv1alpha1.DriftedObject{
+			Name:      finding.Key.Name,
+			Namespace: finding.Key.Namespace,
+			Paths:     finding.Paths,
+			// the API server stores the time with second precision
+			Since: metav1.NewTime(finding.Since.Truncate(time.Second)),
+		})
+	}
+
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeVerificationDrift,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "NoDriftedObjects",
+		Message:            "no synced object differs from its desired state",
+	}
+	if len(findings) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ObjectsDrifted"
+		condition.Message = fmt.Sprintf("%d synced objects differ from the desired state rendered from their source objects", len(findings))
+	}
+
+	return errors.WrapIf(SetResourceSyncRuleClusterStatus(ctx, r.localMgr.GetClient(), r.getRule().GetName(), r.clusterName, func(status *clusterregistryv1alpha1.ResourceSyncRuleClusterStatus) {
+		status.DriftedObjectCount = len(findings)
+		status.DriftedObjects = nil
+		if len(objects) > 0 {
+			status.DriftedObjects = objects
+		}
+
+		if r.getRule().Spec.Verification == nil {
+			meta.RemoveStatusCondition(&status.Conditions, condition.Type)
+
+			return
+		}
+		meta.SetStatusCondition(&status.Conditions, condition)
+	}), "could not update rule status")
+}
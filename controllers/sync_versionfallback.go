@@ -0,0 +1,127 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolveLocalVersionFallback syncs the objects as the preferred version of the local kind, if the rule allows it and
+// the local cluster does not serve the version they are synced as. The objects keep waiting for the CRD if no version
+// of the local kind is served.
+func (r *syncReconciler) resolveLocalVersionFallback(ctx context.Context) error {
+	if !r.getRule().Spec.VersionFallback {
+		return nil
+	}
+
+	desired := getLocalKind(r.getRule().Spec, r.GetSourceGVK())
+	mapper := r.localMgr.GetRESTMapper()
+
+	err := warmRESTMapper(mapper, desired)
+	if err == nil {
+		r.setLocalVersionFallback(desired, nil)
+
+		return nil
+	}
+	if !isMissingKindError(err) {
+		return errors.WrapIf(err, "could not look up local kind")
+	}
+
+	mapping, err := mapper.RESTMapping(desired.GroupKind())
+	if isMissingKindError(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not look up served versions of local kind", "groupKind", desired.GroupKind().String())
+	}
+
+	served := mapping.GroupVersionKind
+	var openAPISchema map[string]interface{}
+	if !r.localClient.Scheme().Recognizes(served) {
+		openAPISchema, err = r.getCRDSchema(ctx, mapping.Resource.GroupResource(), served.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.setLocalVersionFallback(served, openAPISchema) {
+		msg := fmt.Sprintf("local cluster does not serve %s, objects are synced as %s", desired, served.Version)
+		r.recordEvent(corev1.EventTypeNormal, "VersionFallback", msg)
+		r.GetLogger().Info(msg)
+	}
+
+	return nil
+}
+
+// setLocalVersionFallback sets the local GVK the objects are synced as, it returns whether it changed
+func (r *syncReconciler) setLocalVersionFallback(gvk schema.GroupVersionKind, openAPISchema map[string]interface{}) bool {
+	r.gvkMu.Lock()
+	defer r.gvkMu.Unlock()
+
+	changed := r.localGVK != gvk
+	r.localGVK = gvk
+	r.fallbackSchema = openAPISchema
+
+	return changed
+}
+
+// getLocalVersionFallback returns the version the objects are converted to, and the schema of the version if the
+// scheme does not know it, the version is empty if the objects are synced as the rule says
+func (r *syncReconciler) getLocalVersionFallback() (string, map[string]interface{}) {
+	r.gvkMu.RLock()
+	defer r.gvkMu.RUnlock()
+
+	if !r.getRule().Spec.VersionFallback || r.localGVK == getLocalKind(r.getRule().Spec, r.gvk) {
+		return "", nil
+	}
+
+	return r.localGVK.Version, r.fallbackSchema
+}
+
+// getCRDSchema returns the OpenAPI schema of the version of the CRD of the resource
+func (r *syncReconciler) getCRDSchema(ctx context.Context, resource schema.GroupResource, version string) (map[string]interface{}, error) {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(customResourceDefinitionGVK)
+	if err := r.localMgr.GetAPIReader().Get(ctx, client.ObjectKey{Name: resource.String()}, crd); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not get CRD of local kind", "name", resource.String())
+	}
+
+	versions, _, err := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "invalid CRD", "name", resource.String())
+	}
+	for _, v := range versions {
+		crdVersion, ok := v.(map[string]interface{})
+		if !ok || crdVersion["name"] != version {
+			continue
+		}
+
+		openAPISchema, _, err := unstructured.NestedMap(crdVersion, "schema", "openAPIV3Schema")
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "invalid CRD schema", "name", resource.String(), "version", version)
+		}
+
+		return openAPISchema, nil
+	}
+
+	return nil, errors.NewWithDetails("version not found in CRD", "name", resource.String(), "version", version)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+// watchStatusFuncName is the name the watch status function of the rule is registered with on the source cluster
+func (r *syncReconciler) watchStatusFuncName() string {
+	return "resource-sync-rule/" + r.getRule().GetName()
+}
+
+// watchSourceStatus subscribes to the watch statuses of the source cluster and reports the current one
+func (r *syncReconciler) watchSourceStatus(ctx context.Context) {
+	cluster, err := r.clustersManager.Get(r.clusterName)
+	if err != nil {
+		r.GetLogger().V(1).Info("watch status of the source cluster is not available", "error", err.Error())
+
+		return
+	}
+	cluster.AddWatchStatusFunc(r.watchStatusFuncName(), r.onSourceWatchStatus)
+
+	status := clusters.WatchStatus{}
+	for _, degraded := range cluster.GetDegradedWatches() {
+		if r.isSourceResource(degraded.Resource) {
+			status = degraded
+		}
+	}
+	if err := r.setClusterCondition(ctx, r.getWatchDegradedCondition(status)); err != nil {
+		r.GetLogger().Error(err, "could not reset rule condition", "type", clusterregistryv1alpha1.ResourceSyncRuleConditionTypeWatchDegraded)
+	}
+}
+
+// unwatchSourceStatus unsubscribes from the watch statuses of the source cluster
+func (r *syncReconciler) unwatchSourceStatus() {
+	if cluster, err := r.clustersManager.Get(r.clusterName); err == nil {
+		cluster.RemoveWatchStatusFunc(r.watchStatusFuncName())
+	}
+}
+
+// onSourceWatchStatus reports the degraded and recovered watches of the synced kind, the objects of the rule are
+// enqueued on recovery, so the changes missed during the outage are synced
+func (r *syncReconciler) onSourceWatchStatus(_ *clusters.Cluster, status clusters.WatchStatus) {
+	if !r.isSourceResource(status.Resource) {
+		return
+	}
+
+	ctx := r.GetContext()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if status.Degraded {
+		r.recordEvent(corev1.EventTypeWarning, "SourceWatchDegraded", fmt.Sprintf("list and watch requests of %s keep failing on cluster %s, changes are not received: %s", status.Resource.GroupResource(), r.clusterName, status.LastError))
+	} else {
+		r.recordEvent(corev1.EventTypeNormal, "SourceWatchRecovered", fmt.Sprintf("watch of %s recovered on cluster %s, the objects are synced again", status.Resource.GroupResource(), r.clusterName))
+	}
+
+	if err := r.setClusterCondition(ctx, r.getWatchDegradedCondition(status)); err != nil {
+		r.GetLogger().Error(err, "could not update rule condition", "type", clusterregistryv1alpha1.ResourceSyncRuleConditionTypeWatchDegraded)
+	}
+
+	if !status.Degraded {
+		if err := r.enqueueAll(ctx, true); err != nil {
+			r.GetLogger().Error(err, "could not enqueue objects after the watch recovered")
+		}
+	}
+}
+
+// isSourceResource returns whether the resource is the one the source objects of the rule are watched through
+func (r *syncReconciler) isSourceResource(resource schema.GroupVersionResource) bool {
+	mgr := r.GetManager()
+	if mgr == nil {
+		return false
+	}
+
+	gvk := r.GetSourceGVK()
+	mapping, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false
+	}
+
+	return mapping.Resource.GroupResource() == resource.GroupResource()
+}
+
+func (r *syncReconciler) getWatchDegradedCondition(status clusters.WatchStatus) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeWatchDegraded,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "WatchIsHealthy",
+		Message:            "the source objects are watched",
+	}
+
+	if !status.Degraded {
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "WatchIsDegraded"
+	condition.Message = fmt.Sprintf("%d consecutive list and watch requests of %s failed, changes are not received: %s", status.ConsecutiveFailures, status.Resource.GroupResource(), status.LastError)
+
+	return condition
+}
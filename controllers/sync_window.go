@@ -0,0 +1,99 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// deferToSyncWindow holds the change of the object until the next sync window of the rule opens, the object is
+// requeued for the start of the window. Deletions are only held if the rule defers them too.
+func (r *syncReconciler) deferToSyncWindow(ctx context.Context, key types.NamespacedName, deletion bool) (ctrl.Result, bool, error) {
+	syncWindow := r.getSyncWindow()
+	if syncWindow == nil || deletion && !syncWindow.DefersDeletions() {
+		return ctrl.Result{}, false, r.releaseSyncWindowDeferredObject(ctx, key)
+	}
+
+	now := time.Now()
+	open, next := syncWindow.IsOpen(now)
+	if open {
+		return ctrl.Result{}, false, r.releaseSyncWindowDeferredObject(ctx, key)
+	}
+
+	r.syncWindowMu.Lock()
+	_, ok := r.deferredObjects[key]
+	r.deferredObjects[key] = struct{}{}
+	condition := r.getDeferredBySyncWindowCondition()
+	r.syncWindowMu.Unlock()
+
+	result := ctrl.Result{
+		RequeueAfter: next.Sub(now),
+	}
+
+	r.GetLogger().V(1).Info("sync window is closed, change is deferred", "resource", key, "deletion", deletion, "nextWindow", next)
+
+	if ok {
+		return result, true, nil
+	}
+
+	return result, true, r.setClusterCondition(ctx, condition)
+}
+
+// releaseSyncWindowDeferredObject reports that the change of the object does not wait for the sync window anymore
+func (r *syncReconciler) releaseSyncWindowDeferredObject(ctx context.Context, key types.NamespacedName) error {
+	r.syncWindowMu.Lock()
+	if _, ok := r.deferredObjects[key]; !ok {
+		r.syncWindowMu.Unlock()
+
+		return nil
+	}
+	delete(r.deferredObjects, key)
+	condition := r.getDeferredBySyncWindowCondition()
+	r.syncWindowMu.Unlock()
+
+	return r.setClusterCondition(ctx, condition)
+}
+
+// getDeferredBySyncWindowCondition must be called with syncWindowMu held
+func (r *syncReconciler) getDeferredBySyncWindowCondition() metav1.Condition {
+	condition := metav1.Condition{
+		Type:               clusterregistryv1alpha1.ResourceSyncRuleConditionTypeDeferredBySyncWindow,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: r.getRule().GetGeneration(),
+		Reason:             "NoChangesDeferred",
+		Message:            "no change waits for the sync window",
+	}
+
+	if len(r.deferredObjects) == 0 {
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "OutsideSyncWindow"
+	condition.Message = fmt.Sprintf("%d objects wait for the next sync window", len(r.deferredObjects))
+	if _, next := r.getSyncWindow().IsOpen(time.Now()); !next.IsZero() {
+		condition.Message = fmt.Sprintf("%d objects wait for the sync window opening at %s", len(r.deferredObjects), next.UTC().Format(time.RFC3339))
+	}
+
+	return condition
+}
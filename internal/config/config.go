@@ -14,9 +14,13 @@
 
 package config
 
+import "time"
+
 type Configuration struct {
 	MetricsAddr                string            `mapstructure:"metrics-addr" json:"metricsAddr,omitempty"`
 	HealthAddr                 string            `mapstructure:"health-addr" json:"healthAddr,omitempty"`
+	Health                     Health            `mapstructure:"health" json:"health,omitempty"`
+	Tracing                    Tracing           `mapstructure:"tracing" json:"tracing,omitempty"`
 	LeaderElection             LeaderElection    `mapstructure:"leader-election" json:"leaderElection,omitempty"`
 	Logging                    Logging           `mapstructure:"log" json:"logging,omitempty"`
 	ClusterController          ClusterController `mapstructure:"clusterController" json:"clusterController,omitempty"`
@@ -28,10 +32,29 @@ type Configuration struct {
 	NetworkName                string            `mapstructure:"network-name" json:"networkName,omitempty"`
 	APIServerEndpointAddress   string            `mapstructure:"apiserver-endpoint-address" json:"apiServerEndpointAddress,omitempty"`
 	CoreResourcesSourceEnabled bool              `mapstructure:"core-resources-source-enabled" json:"coreResourcesSourceEnabled,omitempty"`
+	CacheTransform             CacheTransform    `mapstructure:"cacheTransform" json:"cacheTransform,omitempty"`
+	// CacheListPageSize is the number of objects the informer caches list in a page, 0 lists every object of a kind
+	// in a single response.
+	CacheListPageSize int64 `mapstructure:"cache-list-page-size" json:"cacheListPageSize,omitempty"`
+	// ShutdownDrainTimeout is the longest time the running reconciles are waited for on shutdown, it should be
+	// shorter than the termination grace period of the pod.
+	ShutdownDrainTimeout time.Duration `mapstructure:"shutdown-drain-timeout" json:"shutdownDrainTimeout,omitempty"`
+	// LocalClusterID overrides the ID of the local cluster, which is the UID of its kube-system namespace by default.
+	LocalClusterID string `mapstructure:"local-cluster-id" json:"localClusterID,omitempty"`
+	// RegistryReplicationEnabled replicates the Cluster resources and their credential Secrets between the registries
+	// of the cluster group which enable it, so any of them can take over managing the clusters.
+	RegistryReplicationEnabled bool `mapstructure:"registry-replication-enabled" json:"registryReplicationEnabled,omitempty"`
+	// NamespacedResourceSyncRules configures the controller of the namespaced resource sync rules, which are confined
+	// to their namespaces, so they can be authored by the teams owning the namespaces.
+	NamespacedResourceSyncRules NamespacedResourceSyncRules `mapstructure:"namespaced-resource-sync-rules" json:"namespacedResourceSyncRules,omitempty"`
 
 	// ClusterValidatorWebhook configures the cluster CR validator webhook for
 	// the operator.
 	ClusterValidatorWebhook ClusterValidatorWebhook `mapstructure:"cluster-validator-webhook" json:"clusterValidatorWebhook"`
+
+	// ResourceSyncRuleValidatorWebhook configures the resource sync rule CR
+	// validator webhook for the operator.
+	ResourceSyncRuleValidatorWebhook ResourceSyncRuleValidatorWebhook `mapstructure:"resource-sync-rule-validator-webhook" json:"resourceSyncRuleValidatorWebhook"`
 }
 
 // ClusterValidatorWebhook describes the configuration options for the cluster
@@ -50,14 +73,193 @@ type ClusterValidatorWebhook struct {
 	Port uint `mapstructure:"port" json:"port,omitempty"`
 }
 
+// ResourceSyncRuleValidatorWebhook describes the configuration options for the
+// resource sync rule CR validator webhook. The webhook is served by the server
+// of the cluster CR validator webhook, using its port and certificates.
+type ResourceSyncRuleValidatorWebhook struct {
+	// Enabled is the indicator to determine whether the webhook is enabled.
+	Enabled bool `mapstructure:"enabled" json:"enabled,omitempty"`
+
+	// Authorization configures checking the created and updated rules against
+	// the sync rule authorization policies of the requesting users.
+	Authorization ResourceSyncRuleAuthorization `mapstructure:"authorization" json:"authorization,omitempty"`
+}
+
+// ResourceSyncRuleAuthorization describes the configuration options of the
+// sync rule authorization policy enforcement.
+type ResourceSyncRuleAuthorization struct {
+	// Enabled denies every rule not allowed by a policy when turned on.
+	Enabled bool `mapstructure:"enabled" json:"enabled,omitempty"`
+
+	// ExemptUsers are the users whose rules are not checked, e.g. the
+	// service account of the controller.
+	ExemptUsers []string `mapstructure:"exempt-users" json:"exemptUsers,omitempty"`
+}
+
+// NamespacedResourceSyncRules describes the configuration options of the
+// controller of the namespaced resource sync rules.
+type NamespacedResourceSyncRules struct {
+	// Enabled starts the controller of the namespaced resource sync rules.
+	Enabled bool `mapstructure:"enabled" json:"enabled,omitempty"`
+
+	// SourceNamespaces maps the namespaces of the rules to the namespaces
+	// of the source clusters the rules match objects in, the rules of the
+	// namespaces which are not mapped match objects in their own namespace.
+	SourceNamespaces map[string]string `mapstructure:"source-namespaces" json:"sourceNamespaces,omitempty"`
+}
+
+// GetSourceNamespace returns the namespace of the source clusters the rules
+// of the namespace match objects in.
+func (c NamespacedResourceSyncRules) GetSourceNamespace(namespace string) string {
+	if source, ok := c.SourceNamespaces[namespace]; ok && source != "" {
+		return source
+	}
+
+	return namespace
+}
+
+// Health describes how the state of the remote clusters is reflected in the
+// readiness of the controller.
+type Health struct {
+	// ClusterUnreachableThreshold is the time since the last contact with a
+	// remote cluster after which it is considered unreachable.
+	ClusterUnreachableThreshold time.Duration `mapstructure:"cluster-unreachable-threshold" json:"clusterUnreachableThreshold,omitempty"`
+
+	// FailOnUnreachableCluster makes the controller unready while any remote
+	// cluster is unreachable, otherwise only the clusters whose caches are not
+	// synced do.
+	FailOnUnreachableCluster bool `mapstructure:"fail-on-unreachable-cluster" json:"failOnUnreachableCluster,omitempty"`
+}
+
+// Tracing describes the export of the spans of the sync controllers through
+// OTLP.
+type Tracing struct {
+	// Enabled turns tracing on, it is off by default.
+	Enabled bool `mapstructure:"enabled" json:"enabled,omitempty"`
+
+	// Endpoint is the host:port of the OTLP gRPC receiver.
+	Endpoint string `mapstructure:"endpoint" json:"endpoint,omitempty"`
+
+	// Insecure exports the spans without TLS.
+	Insecure bool `mapstructure:"insecure" json:"insecure,omitempty"`
+
+	// SampleRatio is the ratio of the reconciles traced.
+	SampleRatio float64 `mapstructure:"sample-ratio" json:"sampleRatio,omitempty"`
+}
+
+// CacheTransform describes the fields removed from the objects before they are
+// stored in the informer caches.
+type CacheTransform struct {
+	// Disabled keeps every field of the cached objects, e.g. for debugging.
+	Disabled bool `mapstructure:"disabled" json:"disabled,omitempty"`
+
+	// StripLastAppliedConfiguration removes the last applied configuration
+	// annotation of kubectl along with the managed fields.
+	StripLastAppliedConfiguration bool `mapstructure:"stripLastAppliedConfiguration" json:"stripLastAppliedConfiguration,omitempty"`
+}
+
 type ClusterController struct {
 	WorkerCount            int `mapstructure:"workerCount" json:"workerCount,omitempty"`
 	RefreshIntervalSeconds int `mapstructure:"refreshIntervalSeconds" json:"refreshIntervalSeconds,omitempty"`
+	// Probe configures the connectivity probes of the remote clusters, it can be overridden per cluster by annotations.
+	Probe ClusterProbe `mapstructure:"probe" json:"probe,omitempty"`
+	// Client configures the clients of the remote clusters, it can be overridden per cluster by annotations.
+	Client ClusterClient `mapstructure:"client" json:"client,omitempty"`
+	// EndpointFailover configures the failover between the API server endpoints of the remote clusters.
+	EndpointFailover ClusterEndpointFailover `mapstructure:"endpointFailover" json:"endpointFailover,omitempty"`
+	// WatchFailureThreshold is the number of consecutive failed list and watch requests of a resource after which
+	// the watch of a remote cluster is considered degraded.
+	WatchFailureThreshold int `mapstructure:"watchFailureThreshold" json:"watchFailureThreshold,omitempty"`
+	// CircuitBreaker configures the circuit breakers pausing the reconciles of the remote clusters whose API servers
+	// are erroring heavily.
+	CircuitBreaker ClusterCircuitBreaker `mapstructure:"circuitBreaker" json:"circuitBreaker,omitempty"`
+}
+
+type ClusterProbe struct {
+	Interval         time.Duration `mapstructure:"interval" json:"interval,omitempty"`
+	Timeout          time.Duration `mapstructure:"timeout" json:"timeout,omitempty"`
+	FailureThreshold int           `mapstructure:"failureThreshold" json:"failureThreshold,omitempty"`
+}
+
+type ClusterClient struct {
+	QPS     float32       `mapstructure:"qps" json:"qps,omitempty"`
+	Burst   int           `mapstructure:"burst" json:"burst,omitempty"`
+	Timeout time.Duration `mapstructure:"timeout" json:"timeout,omitempty"`
+	// ForceJSON disables the protobuf encoding of the built-in kinds
+	ForceJSON bool `mapstructure:"forceJSON" json:"forceJSON,omitempty"`
+}
+
+type ClusterEndpointFailover struct {
+	FailureThreshold    int           `mapstructure:"failureThreshold" json:"failureThreshold,omitempty"`
+	HealthCheckInterval time.Duration `mapstructure:"healthCheckInterval" json:"healthCheckInterval,omitempty"`
+}
+
+// ClusterCircuitBreaker is disabled by an error threshold of 0
+type ClusterCircuitBreaker struct {
+	ErrorThreshold float64       `mapstructure:"errorThreshold" json:"errorThreshold,omitempty"`
+	MinRequests    int           `mapstructure:"minRequests" json:"minRequests,omitempty"`
+	Window         time.Duration `mapstructure:"window" json:"window,omitempty"`
+	CoolDown       time.Duration `mapstructure:"coolDown" json:"coolDown,omitempty"`
 }
 
 type SyncController struct {
 	WorkerCount int                     `mapstructure:"workerCount" json:"workerCount,omitempty"`
 	RateLimit   SyncControllerRateLimit `mapstructure:"rateLimit" json:"rateLimit,omitempty"`
+	// WriteFormatVersion is the format version of the annotations written on synced objects.
+	// It should only be raised once every controller replica runs a version which is able to read it.
+	WriteFormatVersion int `mapstructure:"writeFormatVersion" json:"writeFormatVersion,omitempty"`
+	// ProtectedNamespaces are namespaces objects are never synced into, regardless of the rules.
+	ProtectedNamespaces []string `mapstructure:"protectedNamespaces" json:"protectedNamespaces,omitempty"`
+	// AllowedTargetNamespaces are glob patterns of the only namespaces objects are synced into and deleted from,
+	// regardless of the rules. Every namespace is allowed if it is empty.
+	AllowedTargetNamespaces []string `mapstructure:"allowedTargetNamespaces" json:"allowedTargetNamespaces,omitempty"`
+	// DeniedGVKs are kinds which are never synced, regardless of the rules, in [group/]version/kind format.
+	DeniedGVKs []string `mapstructure:"deniedGVKs" json:"deniedGVKs,omitempty"`
+	// RedactedFields are sensitive fields in Kind[.group]:.path format, whose values are scrubbed from the logs, events
+	// and errors in addition to the data of Secrets.
+	RedactedFields []string `mapstructure:"redactedFields" json:"redactedFields,omitempty"`
+	// FailureThreshold is the number of consecutive failures after which an object is not retried until its source
+	// changes, 0 retries the objects forever.
+	FailureThreshold int `mapstructure:"failureThreshold" json:"failureThreshold,omitempty"`
+	// ReconcileTimeout limits the time a reconcile of an object can take, 0 disables the limit. It can be overridden
+	// per rule.
+	ReconcileTimeout time.Duration `mapstructure:"reconcileTimeout" json:"reconcileTimeout,omitempty"`
+	// FullReconcileInterval is the longest time a synced object whose desired state has the same content hash as the
+	// one it was last written with is not reconciled in full, 0 reconciles every object in full.
+	FullReconcileInterval time.Duration `mapstructure:"fullReconcileInterval" json:"fullReconcileInterval,omitempty"`
+	// MaxObjectSize is the largest size of the source objects serialized to JSON which are synced, 0 disables the
+	// limit. It can be overridden per rule.
+	MaxObjectSize int64 `mapstructure:"maxObjectSize" json:"maxObjectSize,omitempty"`
+	// LastAppliedSizeLimit is the size of the synced objects above which they are written without the last applied
+	// annotation and compared field by field instead, 0 disables the limit.
+	LastAppliedSizeLimit int64 `mapstructure:"lastAppliedSizeLimit" json:"lastAppliedSizeLimit,omitempty"`
+	// AccessCheckInterval is the time between two checks of the permissions needed by the running sync controllers,
+	// 0 disables the periodic checks.
+	AccessCheckInterval time.Duration `mapstructure:"accessCheckInterval" json:"accessCheckInterval,omitempty"`
+	// FreshnessThreshold is the longest time the oldest queued object of a sync controller can wait before the
+	// SyncFresh condition of its rule turns false, 0 disables the condition.
+	FreshnessThreshold time.Duration `mapstructure:"freshnessThreshold" json:"freshnessThreshold,omitempty"`
+	// StartupWindow is the time the objects listed when a sync controller starts are spread over, 0 adds them to the
+	// queue right away.
+	StartupWindow time.Duration `mapstructure:"startupWindow" json:"startupWindow,omitempty"`
+	// MaxConcurrentWrites is the number of local writes of the synced objects running at the same time across the sync
+	// controllers of every rule, 0 disables the limit.
+	MaxConcurrentWrites int `mapstructure:"maxConcurrentWrites" json:"maxConcurrentWrites,omitempty"`
+	// MaxSyncHops is the number of clusters an object can be synced through from the cluster it originates from, 0
+	// disables the limit. Objects coming back to the cluster they originate from are never synced.
+	MaxSyncHops int `mapstructure:"maxSyncHops" json:"maxSyncHops,omitempty"`
+	// SyncStateInterval is how often the summary of the synced objects is written onto the Cluster resources.
+	SyncStateInterval time.Duration `mapstructure:"syncStateInterval" json:"syncStateInterval,omitempty"`
+	// SharedSourceWatches shares the watch of a source kind on a cluster between the rules syncing it, instead of every
+	// rule watching it with its own controller.
+	SharedSourceWatches bool `mapstructure:"sharedSourceWatches" json:"sharedSourceWatches,omitempty"`
+	// LogSampleRate is the number of lines per second the high volume messages of the sync controllers, e.g.
+	// "reconciling", are logged with per rule, the suppressed lines are counted, 0 disables sampling. The lines of the
+	// rules logged at the debug or trace level are not sampled.
+	LogSampleRate int `mapstructure:"logSampleRate" json:"logSampleRate,omitempty"`
+	// AuditLog enables the audit trail of the synced objects, their audit records are written in JSON to this file, or
+	// to the standard output if it is "-". The audit trail is disabled if it is empty.
+	AuditLog string `mapstructure:"auditLog" json:"auditLog,omitempty"`
 }
 
 type SyncControllerRateLimit struct {
@@ -66,10 +268,24 @@ type SyncControllerRateLimit struct {
 	MaxBurst         int `mapstructure:"maxBurst" json:"maxBurst,omitempty"`
 }
 
+type LeaderElectionMode string
+
+const (
+	// LeaderElectionModeLeader runs every controller on the elected leader replica only
+	LeaderElectionModeLeader LeaderElectionMode = "leader"
+	// LeaderElectionModeSharded runs the controllers on every replica, each replica handles the clusters assigned to
+	// it by consistent hashing across the live replicas
+	LeaderElectionModeSharded LeaderElectionMode = "sharded"
+)
+
 type LeaderElection struct {
-	Enabled   bool   `mapstructure:"enabled" json:"enabled,omitempty"`
-	Name      string `mapstructure:"name" json:"name,omitempty"`
-	Namespace string `mapstructure:"namespace" json:"namespace,omitempty"`
+	Enabled   bool               `mapstructure:"enabled" json:"enabled,omitempty"`
+	Name      string             `mapstructure:"name" json:"name,omitempty"`
+	Namespace string             `mapstructure:"namespace" json:"namespace,omitempty"`
+	Mode      LeaderElectionMode `mapstructure:"mode" json:"mode,omitempty"`
+	// ShardLeaseDuration is the time after which a replica which did not renew its Lease is dropped from the shard
+	// members in sharded mode.
+	ShardLeaseDuration time.Duration `mapstructure:"shard-lease-duration" json:"shardLeaseDuration,omitempty"`
 }
 
 type (
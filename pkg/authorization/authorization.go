@@ -0,0 +1,294 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authorization
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+var ErrNotAuthorized = errors.New("not authorized")
+
+// Request is a sync the author of a rule asks for
+type Request struct {
+	// SourceKind is the kind of the synced objects
+	SourceKind schema.GroupKind
+	// TargetKind is the kind the synced objects are written as
+	TargetKind schema.GroupKind
+	// AllNamespaces is true if the sync is not restricted to namespaces, e.g. it syncs cluster scoped objects
+	AllNamespaces bool
+	// Namespaces are the namespaces the sync matches objects in and writes them into
+	Namespaces []string
+	// AllClusters is true if the source clusters are not restricted by their features
+	AllClusters bool
+	// ClusterFeatures are the features the source clusters are selected by
+	ClusterFeatures []string
+}
+
+func (r Request) String() string {
+	namespaces := "every namespace"
+	if !r.AllNamespaces {
+		namespaces = "namespaces " + strings.Join(r.Namespaces, ", ")
+	}
+
+	clusters := "every cluster"
+	if !r.AllClusters {
+		clusters = "clusters with features " + strings.Join(r.ClusterFeatures, ", ")
+	}
+
+	return fmt.Sprintf("sync %s as %s in %s from %s", formatGroupKind(r.SourceKind), formatGroupKind(r.TargetKind), namespaces, clusters)
+}
+
+// RequestsFromRuleSpec returns a request for every sync rule of the spec
+func RequestsFromRuleSpec(spec clusterregistryv1alpha1.ResourceSyncRuleSpec) []Request {
+	sourceKind := schema.GroupKind{Group: spec.GVK.Group, Kind: spec.GVK.Kind}
+
+	clusterFeatures := make([]string, 0, len(spec.ClusterFeatureMatches))
+	allClusters := len(spec.ClusterFeatureMatches) == 0
+	for _, match := range spec.ClusterFeatureMatches {
+		// clusters selected by labels only may have any feature
+		if match.FeatureName == "" {
+			allClusters = true
+
+			break
+		}
+		clusterFeatures = append(clusterFeatures, match.FeatureName)
+	}
+	if allClusters {
+		clusterFeatures = nil
+	}
+
+	if len(spec.Rules) == 0 {
+		return []Request{{
+			SourceKind:      sourceKind,
+			TargetKind:      sourceKind,
+			AllNamespaces:   true,
+			AllClusters:     allClusters,
+			ClusterFeatures: clusterFeatures,
+		}}
+	}
+
+	requests := make([]Request, 0, len(spec.Rules))
+	for _, rule := range spec.Rules {
+		targetKind := sourceKind
+		if gvk := rule.Mutations.GVK; gvk != nil {
+			if gvk.Group != "" {
+				targetKind.Group = gvk.Group
+			}
+			if gvk.Kind != "" {
+				targetKind.Kind = gvk.Kind
+			}
+		}
+
+		allNamespaces, namespaces := getRuleNamespaces(rule)
+
+		requests = append(requests, Request{
+			SourceKind:      sourceKind,
+			TargetKind:      targetKind,
+			AllNamespaces:   allNamespaces,
+			Namespaces:      namespaces,
+			AllClusters:     allClusters,
+			ClusterFeatures: append([]string(nil), clusterFeatures...),
+		})
+	}
+
+	return requests
+}
+
+// getRuleNamespaces returns the namespaces the rule matches objects in and routes them into
+func getRuleNamespaces(rule clusterregistryv1alpha1.SyncRule) (bool, []string) {
+	if len(rule.Matches) == 0 {
+		return true, nil
+	}
+
+	namespaces := make(map[string]struct{})
+	for _, match := range rule.Matches {
+		switch {
+		case match.ObjectKey.Namespace != "":
+			namespaces[match.ObjectKey.Namespace] = struct{}{}
+		case len(match.Namespaces) > 0:
+			for _, namespace := range match.Namespaces {
+				namespaces[namespace] = struct{}{}
+			}
+		default:
+			return true, nil
+		}
+	}
+
+	if routing := rule.Mutations.NamespaceRouting; routing != nil {
+		for _, namespace := range routing.Map {
+			namespaces[namespace] = struct{}{}
+		}
+		if routing.GetUnmappedPolicy() == clusterregistryv1alpha1.UnmappedNamespacePolicyDefault {
+			namespaces[routing.DefaultNamespace] = struct{}{}
+		}
+	}
+
+	return false, sortedKeys(namespaces)
+}
+
+// Authorize returns an error unless every request is allowed to the user by a single allowance of a policy
+// applying to the user. Users no policy applies to are denied.
+func Authorize(user authenticationv1.UserInfo, policies []clusterregistryv1alpha1.SyncRuleAuthorizationPolicy, requests []Request) error {
+	applicable := make([]clusterregistryv1alpha1.SyncRuleAuthorizationPolicy, 0)
+	for _, policy := range policies {
+		if AppliesTo(policy, user) {
+			applicable = append(applicable, policy)
+		}
+	}
+
+	if len(applicable) == 0 {
+		return errors.WithMessagef(ErrNotAuthorized, "no sync rule authorization policy applies to user %s (groups: %s)", user.Username, strings.Join(user.Groups, ", "))
+	}
+
+	for _, request := range requests {
+		if !isAllowed(applicable, request) {
+			names := make([]string, 0, len(applicable))
+			for _, policy := range applicable {
+				names = append(names, policy.GetName())
+			}
+			sort.Strings(names)
+
+			return errors.WithMessagef(ErrNotAuthorized, "user %s may not %s, it is not allowed by the policies %s", user.Username, request, strings.Join(names, ", "))
+		}
+	}
+
+	return nil
+}
+
+// AppliesTo returns whether the user is one of the subjects of the policy
+func AppliesTo(policy clusterregistryv1alpha1.SyncRuleAuthorizationPolicy, user authenticationv1.UserInfo) bool {
+	for _, subject := range policy.Spec.Subjects {
+		if subjectMatches(subject, user) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func subjectMatches(subject rbacv1.Subject, user authenticationv1.UserInfo) bool {
+	switch subject.Kind {
+	case rbacv1.UserKind:
+		return subject.Name == user.Username
+	case rbacv1.GroupKind:
+		for _, group := range user.Groups {
+			if group == subject.Name {
+				return true
+			}
+		}
+
+		return false
+	case rbacv1.ServiceAccountKind:
+		return user.Username == fmt.Sprintf("system:serviceaccount:%s:%s", subject.Namespace, subject.Name)
+	default:
+		return false
+	}
+}
+
+func isAllowed(policies []clusterregistryv1alpha1.SyncRuleAuthorizationPolicy, request Request) bool {
+	for _, policy := range policies {
+		for _, allowance := range policy.Spec.Allow {
+			if Covers(allowance, request) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Covers returns whether the allowance allows the whole request
+func Covers(allowance clusterregistryv1alpha1.SyncRuleAllowance, request Request) bool {
+	if !matchesAnyKind(allowance.SourceKinds, request.SourceKind) {
+		return false
+	}
+
+	targetKinds := allowance.TargetKinds
+	if len(targetKinds) == 0 {
+		targetKinds = allowance.SourceKinds
+	}
+	if !matchesAnyKind(targetKinds, request.TargetKind) {
+		return false
+	}
+
+	return containsAll(allowance.Namespaces, request.AllNamespaces, request.Namespaces) &&
+		containsAll(allowance.ClusterFeatures, request.AllClusters, request.ClusterFeatures)
+}
+
+func matchesAnyKind(patterns []metav1.GroupKind, kind schema.GroupKind) bool {
+	for _, pattern := range patterns {
+		if (pattern.Group == clusterregistryv1alpha1.AnyGroupKind || pattern.Group == kind.Group) &&
+			(pattern.Kind == clusterregistryv1alpha1.AnyGroupKind || pattern.Kind == kind.Kind) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsAll returns whether every requested value is allowed, an empty allowed list allows everything
+func containsAll(allowed []string, all bool, requested []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	set := make(map[string]struct{}, len(allowed))
+	for _, value := range allowed {
+		if value == clusterregistryv1alpha1.AnyGroupKind {
+			return true
+		}
+		set[value] = struct{}{}
+	}
+
+	if all {
+		return false
+	}
+
+	for _, value := range requested {
+		if _, ok := set[value]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func formatGroupKind(kind schema.GroupKind) string {
+	if kind.Group == "" {
+		return kind.Kind
+	}
+
+	return kind.String()
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authorization_test
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/authorization"
+)
+
+func newPolicy(name string, subjects []rbacv1.Subject, allow ...clusterregistryv1alpha1.SyncRuleAllowance) clusterregistryv1alpha1.SyncRuleAuthorizationPolicy {
+	return clusterregistryv1alpha1.SyncRuleAuthorizationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: clusterregistryv1alpha1.SyncRuleAuthorizationPolicySpec{
+			Subjects: subjects,
+			Allow:    allow,
+		},
+	}
+}
+
+func newSpec(group, kind string, mutate func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec)) clusterregistryv1alpha1.ResourceSyncRuleSpec {
+	spec := clusterregistryv1alpha1.ResourceSyncRuleSpec{
+		GVK: resources.GroupVersionKind{
+			Group:   group,
+			Version: "v1",
+			Kind:    kind,
+		},
+		Rules: []clusterregistryv1alpha1.SyncRule{
+			{
+				Matches: []clusterregistryv1alpha1.SyncRuleMatch{
+					{
+						Namespaces: []string{"team-a"},
+					},
+				},
+			},
+		},
+	}
+	if mutate != nil {
+		mutate(&spec)
+	}
+
+	return spec
+}
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+
+	policies := []clusterregistryv1alpha1.SyncRuleAuthorizationPolicy{
+		newPolicy("team-a",
+			[]rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "team-a"}},
+			clusterregistryv1alpha1.SyncRuleAllowance{
+				SourceKinds: []metav1.GroupKind{
+					{Group: "", Kind: "ConfigMap"},
+					{Group: "team-a.example.com", Kind: clusterregistryv1alpha1.AnyGroupKind},
+				},
+				Namespaces: []string{"team-a", "team-a-shared"},
+			},
+		),
+		newPolicy("platform-admins",
+			[]rbacv1.Subject{
+				{Kind: rbacv1.UserKind, Name: "admin"},
+				{Kind: rbacv1.ServiceAccountKind, Namespace: "platform", Name: "gitops"},
+			},
+			clusterregistryv1alpha1.SyncRuleAllowance{
+				SourceKinds: []metav1.GroupKind{
+					{Group: clusterregistryv1alpha1.AnyGroupKind, Kind: clusterregistryv1alpha1.AnyGroupKind},
+				},
+			},
+		),
+		newPolicy("edge-features",
+			[]rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "edge"}},
+			clusterregistryv1alpha1.SyncRuleAllowance{
+				SourceKinds:     []metav1.GroupKind{{Group: "", Kind: "Secret"}},
+				ClusterFeatures: []string{"edge"},
+			},
+		),
+	}
+
+	teamA := authenticationv1.UserInfo{Username: "alice", Groups: []string{"system:authenticated", "team-a"}}
+
+	tests := map[string]struct {
+		user       authenticationv1.UserInfo
+		spec       clusterregistryv1alpha1.ResourceSyncRuleSpec
+		authorized bool
+	}{
+		"group member syncs allowed kind": {
+			user:       teamA,
+			spec:       newSpec("", "ConfigMap", nil),
+			authorized: true,
+		},
+		"group member syncs kind of own group by wildcard": {
+			user:       teamA,
+			spec:       newSpec("team-a.example.com", "Widget", nil),
+			authorized: true,
+		},
+		"group member syncs kind not allowed": {
+			user:       teamA,
+			spec:       newSpec("rbac.authorization.k8s.io", "ClusterRole", nil),
+			authorized: false,
+		},
+		"group member mutates into kind not allowed": {
+			user: teamA,
+			spec: newSpec("", "ConfigMap", func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.Rules[0].Mutations.GVK = &resources.GroupVersionKind{Kind: "Secret"}
+			}),
+			authorized: false,
+		},
+		"group member syncs every namespace": {
+			user: teamA,
+			spec: newSpec("", "ConfigMap", func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.Rules[0].Matches = nil
+			}),
+			authorized: false,
+		},
+		"group member routes into namespace not allowed": {
+			user: teamA,
+			spec: newSpec("", "ConfigMap", func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.Rules[0].Mutations.NamespaceRouting = &clusterregistryv1alpha1.NamespaceRouting{
+					FromLabel: "team",
+					Map:       map[string]string{"a": "team-a-shared", "b": "team-b"},
+				}
+			}),
+			authorized: false,
+		},
+		"user not in group": {
+			user:       authenticationv1.UserInfo{Username: "bob", Groups: []string{"system:authenticated", "team-b"}},
+			spec:       newSpec("", "ConfigMap", nil),
+			authorized: false,
+		},
+		"admin user syncs any kind": {
+			user:       authenticationv1.UserInfo{Username: "admin"},
+			spec:       newSpec("rbac.authorization.k8s.io", "ClusterRole", nil),
+			authorized: true,
+		},
+		"service account subject": {
+			user:       authenticationv1.UserInfo{Username: "system:serviceaccount:platform:gitops"},
+			spec:       newSpec("rbac.authorization.k8s.io", "Role", nil),
+			authorized: true,
+		},
+		"service account in other namespace": {
+			user:       authenticationv1.UserInfo{Username: "system:serviceaccount:team-a:gitops"},
+			spec:       newSpec("", "ConfigMap", nil),
+			authorized: false,
+		},
+		"selected cluster feature": {
+			user: authenticationv1.UserInfo{Username: "carol", Groups: []string{"edge"}},
+			spec: newSpec("", "Secret", func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.ClusterFeatureMatches = []clusterregistryv1alpha1.ClusterFeatureMatch{{FeatureName: "edge"}}
+			}),
+			authorized: true,
+		},
+		"every cluster without allowed feature": {
+			user:       authenticationv1.UserInfo{Username: "carol", Groups: []string{"edge"}},
+			spec:       newSpec("", "Secret", nil),
+			authorized: false,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := authorization.Authorize(test.user, policies, authorization.RequestsFromRuleSpec(test.spec))
+			if test.authorized && err != nil {
+				t.Fatalf("expected to be authorized, got %s", err)
+			}
+			if !test.authorized && !errors.Is(err, authorization.ErrNotAuthorized) {
+				t.Fatalf("expected not authorized error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthorizeDeniesByDefault(t *testing.T) {
+	t.Parallel()
+
+	user := authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a"}}
+
+	err := authorization.Authorize(user, nil, authorization.RequestsFromRuleSpec(newSpec("", "ConfigMap", nil)))
+	if !errors.Is(err, authorization.ErrNotAuthorized) {
+		t.Fatalf("expected not authorized error, got %v", err)
+	}
+
+	expected := "no sync rule authorization policy applies to user alice (groups: team-a): not authorized"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestAuthorizeMessage(t *testing.T) {
+	t.Parallel()
+
+	policies := []clusterregistryv1alpha1.SyncRuleAuthorizationPolicy{
+		newPolicy("team-a",
+			[]rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "team-a"}},
+			clusterregistryv1alpha1.SyncRuleAllowance{
+				SourceKinds: []metav1.GroupKind{{Kind: "ConfigMap"}},
+			},
+		),
+	}
+	user := authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a"}}
+
+	err := authorization.Authorize(user, policies, authorization.RequestsFromRuleSpec(newSpec("apps", "Deployment", nil)))
+
+	expected := "user alice may not sync Deployment.apps as Deployment.apps in namespaces team-a from every cluster, it is not allowed by the policies team-a: not authorized"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
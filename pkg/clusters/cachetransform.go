@@ -0,0 +1,235 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// CacheTransform removes the fields the controllers never read from the objects before they are stored in the
+// informer caches. The managed fields of an object are often larger than its spec, so caching them multiplies the
+// memory usage of the caches. Only the fields of the entries are removed, the managers, the operations and the times
+// are kept to tell who changed an object.
+//
+// The informers of this controller-runtime version can not transform the objects they store, so the list and watch
+// responses are transformed by the transport of the caches instead. The clients writing the objects are not affected.
+type CacheTransform struct {
+	stripLastAppliedConfiguration bool
+}
+
+type CacheTransformOption func(t *CacheTransform)
+
+// WithLastAppliedConfigurationStripping removes the kubectl.kubernetes.io/last-applied-configuration annotation from
+// the cached objects as well, which holds a full copy of the objects applied by kubectl
+func WithLastAppliedConfigurationStripping() CacheTransformOption {
+	return func(t *CacheTransform) {
+		t.stripLastAppliedConfiguration = true
+	}
+}
+
+func NewCacheTransform(opts ...CacheTransformOption) *CacheTransform {
+	t := &CacheTransform{}
+
+	for _, o := range opts {
+		o(t)
+	}
+
+	return t
+}
+
+// NewCache creates an informer cache storing the transformed objects, it can be used as the NewCache option of
+// managers
+func (t *CacheTransform) NewCache(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+	config = rest.CopyConfig(config)
+	config.Wrap(t.WrapTransport)
+
+	return cache.New(config, opts)
+}
+
+// WrapTransport transforms the objects of the list and watch responses received through the round tripper
+func (t *CacheTransform) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &cacheTransformRoundTripper{
+		transform: t,
+		next:      rt,
+	}
+}
+
+// TransformObject removes the fields from the object and from the items of a list, it returns whether anything was
+// removed
+func (t *CacheTransform) TransformObject(obj map[string]interface{}) bool {
+	changed := t.transformMetadata(obj)
+
+	if items, ok := obj["items"].([]interface{}); ok {
+		for _, item := range items {
+			if item, ok := item.(map[string]interface{}); ok && t.transformMetadata(item) {
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+func (t *CacheTransform) transformMetadata(obj map[string]interface{}) bool {
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	changed := false
+	if entries, ok := metadata["managedFields"].([]interface{}); ok {
+		for _, entry := range entries {
+			if entry, ok := entry.(map[string]interface{}); ok {
+				if _, ok := entry["fieldsV1"]; ok {
+					delete(entry, "fieldsV1")
+					changed = true
+				}
+			}
+		}
+	}
+
+	if annotations, ok := metadata["annotations"].(map[string]interface{}); ok && t.stripLastAppliedConfiguration {
+		if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+			delete(annotations, corev1.LastAppliedConfigAnnotation)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// transform returns the transformed JSON encoded object, or the original one if nothing was removed from it
+func (t *CacheTransform) transform(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	obj := make(map[string]interface{})
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, errors.WrapIf(err, "could not decode object")
+	}
+
+	if !t.TransformObject(obj) {
+		return data, nil
+	}
+
+	data, err := json.Marshal(obj)
+
+	return data, errors.WrapIf(err, "could not encode object")
+}
+
+// transformEvent returns the JSON encoded watch event with its object transformed
+func (t *CacheTransform) transformEvent(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	event := make(map[string]interface{})
+	if err := decoder.Decode(&event); err != nil {
+		return nil, errors.WrapIf(err, "could not decode watch event")
+	}
+
+	obj, ok := event["object"].(map[string]interface{})
+	if !ok || !t.TransformObject(obj) {
+		return data, nil
+	}
+
+	data, err := json.Marshal(event)
+
+	return data, errors.WrapIf(err, "could not encode watch event")
+}
+
+type cacheTransformRoundTripper struct {
+	transform *CacheTransform
+	next      http.RoundTripper
+}
+
+func (rt *cacheTransformRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil || req.Method != http.MethodGet || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	// only the JSON responses are transformed, e.g. protobuf encoded ones are passed through
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return resp, nil
+	}
+
+	query := req.URL.Query()
+	if query.Get("watch") == "true" || query.Get("watch") == "1" {
+		resp.Body = &watchTransformReader{
+			transform: rt.transform,
+			body:      resp.Body,
+			decoder:   json.NewDecoder(resp.Body),
+		}
+
+		return resp, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not read response")
+	}
+
+	data, err = rt.transform.transform(data)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = int64(len(data))
+	resp.Header.Del("Content-Length")
+
+	return resp, nil
+}
+
+// watchTransformReader transforms the JSON encoded events of a watch stream one by one as they are read
+type watchTransformReader struct {
+	transform *CacheTransform
+	body      io.ReadCloser
+	decoder   *json.Decoder
+	buffer    bytes.Buffer
+}
+
+func (r *watchTransformReader) Read(p []byte) (int, error) {
+	if r.buffer.Len() == 0 {
+		var event json.RawMessage
+		if err := r.decoder.Decode(&event); err != nil {
+			return 0, err
+		}
+
+		data, err := r.transform.transformEvent(event)
+		if err != nil {
+			return 0, err
+		}
+
+		r.buffer.Write(data)
+		r.buffer.WriteByte('\n')
+	}
+
+	return r.buffer.Read(p)
+}
+
+func (r *watchTransformReader) Close() error {
+	return r.body.Close()
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+const cacheTransformTestObject = `{"metadata":{"name":"test","resourceVersion":"12345678901234567890","annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}","banzaicloud.com/last-applied":"{}"},"managedFields":[{"manager":"kubectl","operation":"Update","fieldsType":"FieldsV1","fieldsV1":{"f:data":{}}}]},"data":{"key":"value"}}`
+
+func TestCacheTransformTransport(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		url              string
+		contentType      string
+		body             string
+		opts             []clusters.CacheTransformOption
+		expectedObjects  int
+		expectedStripped bool
+		lastApplied      bool
+	}{
+		{
+			name:             "list",
+			url:              "https://cluster/api/v1/configmaps?limit=500",
+			contentType:      "application/json",
+			body:             `{"kind":"ConfigMapList","metadata":{"resourceVersion":"1"},"items":[` + cacheTransformTestObject + `,` + cacheTransformTestObject + `]}`,
+			expectedObjects:  2,
+			expectedStripped: true,
+			lastApplied:      true,
+		},
+		{
+			name:             "watch",
+			url:              "https://cluster/api/v1/configmaps?watch=true",
+			contentType:      "application/json",
+			body:             `{"type":"ADDED","object":` + cacheTransformTestObject + "}\n" + `{"type":"MODIFIED","object":` + cacheTransformTestObject + "}\n",
+			expectedObjects:  2,
+			expectedStripped: true,
+			lastApplied:      true,
+		},
+		{
+			name:             "last applied configuration",
+			url:              "https://cluster/api/v1/namespaces/default/configmaps/test",
+			contentType:      "application/json; charset=utf-8",
+			body:             cacheTransformTestObject,
+			opts:             []clusters.CacheTransformOption{clusters.WithLastAppliedConfigurationStripping()},
+			expectedObjects:  1,
+			expectedStripped: true,
+		},
+		{
+			name:            "not json",
+			url:             "https://cluster/api/v1/configmaps?limit=500",
+			contentType:     "application/vnd.kubernetes.protobuf",
+			body:            cacheTransformTestObject,
+			expectedObjects: 1,
+			lastApplied:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				rec := httptest.NewRecorder()
+				rec.Header().Set("Content-Type", tc.contentType)
+				rec.WriteHeader(http.StatusOK)
+				_, _ = rec.WriteString(tc.body)
+
+				return rec.Result(), nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			resp, err := clusters.NewCacheTransform(tc.opts...).WrapTransport(transport).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			objects := make([]map[string]interface{}, 0)
+			decoder := json.NewDecoder(resp.Body)
+			decoder.UseNumber()
+			for {
+				value := make(map[string]interface{})
+				if err := decoder.Decode(&value); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatal(err)
+				}
+
+				switch {
+				case value["items"] != nil:
+					for _, item := range value["items"].([]interface{}) {
+						objects = append(objects, item.(map[string]interface{}))
+					}
+				case value["object"] != nil:
+					objects = append(objects, value["object"].(map[string]interface{}))
+				default:
+					objects = append(objects, value)
+				}
+			}
+
+			if len(objects) != tc.expectedObjects {
+				t.Fatalf("expected %d objects, got %d", tc.expectedObjects, len(objects))
+			}
+			for _, obj := range objects {
+				metadata := obj["metadata"].(map[string]interface{})
+				annotations := metadata["annotations"].(map[string]interface{})
+
+				entry := metadata["managedFields"].([]interface{})[0].(map[string]interface{})
+				if _, ok := entry["fieldsV1"]; ok == tc.expectedStripped {
+					t.Fatalf("expected managed fields to be stripped: %t, got %v", tc.expectedStripped, metadata)
+				}
+				if entry["manager"] != "kubectl" || entry["operation"] != "Update" {
+					t.Fatalf("expected the managers of the managed fields to be kept, got %v", metadata)
+				}
+				if _, ok := annotations["kubectl.kubernetes.io/last-applied-configuration"]; ok != tc.lastApplied {
+					t.Fatalf("expected last applied configuration: %t, got %v", tc.lastApplied, annotations)
+				}
+				if _, ok := annotations["banzaicloud.com/last-applied"]; !ok {
+					t.Fatalf("expected the last applied annotation of the controller to be kept, got %v", annotations)
+				}
+				if metadata["resourceVersion"] != "12345678901234567890" || obj["data"].(map[string]interface{})["key"] != "value" {
+					t.Fatalf("expected the other fields to be kept, got %v", obj)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheTransformObject(t *testing.T) {
+	t.Parallel()
+
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "test",
+		},
+	}
+	if clusters.NewCacheTransform().TransformObject(obj) {
+		t.Fatal("expected an object without managed fields not to change")
+	}
+
+	obj["metadata"].(map[string]interface{})["managedFields"] = []interface{}{
+		map[string]interface{}{"manager": "kubectl", "fieldsV1": map[string]interface{}{}},
+	}
+	if !clusters.NewCacheTransform().TransformObject(obj) {
+		t.Fatal("expected the managed fields to be removed")
+	}
+	entry := obj["metadata"].(map[string]interface{})["managedFields"].([]interface{})[0].(map[string]interface{})
+	if _, ok := entry["fieldsV1"]; ok || entry["manager"] != "kubectl" {
+		t.Fatalf("expected only the fields of the managed fields to be removed, got %v", entry)
+	}
+}
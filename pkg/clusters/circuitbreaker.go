@@ -0,0 +1,348 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	DefaultCircuitBreakerErrorThreshold = 0.5
+	DefaultCircuitBreakerMinRequests    = 20
+	DefaultCircuitBreakerWindow         = time.Second * 30
+	DefaultCircuitBreakerCoolDown       = time.Minute
+
+	// circuitBreakerHalfOpenRatio is the share of the reconciles admitted in the first window after the cool-down,
+	// it is doubled by every window without too many errors until every reconcile is admitted again
+	circuitBreakerHalfOpenRatio = 0.125
+)
+
+// CircuitBreakerState is the state of the circuit breaker of a cluster
+type CircuitBreakerState string
+
+const (
+	// CircuitBreakerStateClosed admits every reconcile
+	CircuitBreakerStateClosed CircuitBreakerState = "Closed"
+	// CircuitBreakerStateOpen refuses every reconcile until the cool-down is over
+	CircuitBreakerStateOpen CircuitBreakerState = "Open"
+	// CircuitBreakerStateHalfOpen admits a growing share of the reconciles
+	CircuitBreakerStateHalfOpen CircuitBreakerState = "HalfOpen"
+)
+
+// CircuitBreakerConfig controls when the circuit breaker of a cluster trips and how long it stays open
+type CircuitBreakerConfig struct {
+	// ErrorThreshold is the share of the failed requests in a window which trips the breaker
+	ErrorThreshold float64
+	// MinRequests is the number of requests a window needs before the breaker can trip
+	MinRequests int
+	// Window is the time the error rate is measured over
+	Window time.Duration
+	// CoolDown is the time the breaker refuses every reconcile after it tripped
+	CoolDown time.Duration
+}
+
+// WithDefaults returns the config with the unset fields set to their default values
+func (c CircuitBreakerConfig) WithDefaults() CircuitBreakerConfig {
+	if c.ErrorThreshold <= 0 || c.ErrorThreshold > 1 {
+		c.ErrorThreshold = DefaultCircuitBreakerErrorThreshold
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = DefaultCircuitBreakerMinRequests
+	}
+	if c.Window <= 0 {
+		c.Window = DefaultCircuitBreakerWindow
+	}
+	if c.CoolDown <= 0 {
+		c.CoolDown = DefaultCircuitBreakerCoolDown
+	}
+
+	return c
+}
+
+// CircuitBreakerStatus is the state of the circuit breaker of a cluster
+type CircuitBreakerStatus struct {
+	State CircuitBreakerState
+	// ErrorRate is the share of the failed requests of the window which tripped the breaker
+	ErrorRate float64
+	// LastError is the last error which was counted before the breaker tripped
+	LastError string
+	// RetryTime is the time the open breaker starts admitting reconciles again
+	RetryTime time.Time
+	// AdmittedRatio is the share of the reconciles the half-open breaker admits
+	AdmittedRatio float64
+	// LastTransitionTime is the time the breaker changed its state
+	LastTransitionTime time.Time
+}
+
+// CircuitBreakerFunc is called when the circuit breaker of a cluster changes its state
+type CircuitBreakerFunc func(c *Cluster, status CircuitBreakerStatus)
+
+// CircuitBreaker measures the error rate of the requests sent to a cluster and stops the reconciles of its
+// controllers for a cool-down period once it is too high, so an API server which is erroring heavily is not hammered
+// by the retries of every object and the controllers do not log thousands of errors.
+type CircuitBreaker struct {
+	clusterName string
+	config      CircuitBreakerConfig
+	onChange    func(status CircuitBreakerStatus)
+
+	status      CircuitBreakerStatus
+	windowStart time.Time
+	requests    int
+	failures    int
+	lastError   string
+	// seen and admitted count the reconciles of the half-open breaker
+	seen     int
+	admitted int
+	mu       sync.Mutex
+}
+
+// NewCircuitBreaker returns a closed circuit breaker calling the function when it changes its state
+func NewCircuitBreaker(clusterName string, config CircuitBreakerConfig, onChange func(status CircuitBreakerStatus)) *CircuitBreaker {
+	b := &CircuitBreaker{
+		clusterName: clusterName,
+		config:      config.WithDefaults(),
+		onChange:    onChange,
+		status: CircuitBreakerStatus{
+			State: CircuitBreakerStateClosed,
+		},
+	}
+	setCircuitBreakerStateMetric(clusterName, CircuitBreakerStateClosed)
+
+	return b
+}
+
+// GetStatus returns the current state of the breaker
+func (b *CircuitBreaker) GetStatus() CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.status
+}
+
+// WrapTransport counts the failed requests sent through the round tripper, it can be used as the WrapTransport of a
+// rest config
+func (b *CircuitBreaker) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &circuitBreakerRoundTripper{
+		breaker: b,
+		next:    rt,
+	}
+}
+
+// Admit returns whether a reconcile can run, otherwise the time after which it should be retried
+func (b *CircuitBreaker) Admit(now time.Time) (time.Duration, bool) {
+	b.mu.Lock()
+	changed := b.roll(now)
+	status := b.status
+
+	var delay time.Duration
+	admit := true
+	switch status.State {
+	case CircuitBreakerStateOpen:
+		delay, admit = status.RetryTime.Sub(now), false
+	case CircuitBreakerStateHalfOpen:
+		b.seen++
+		if float64(b.admitted) < status.AdmittedRatio*float64(b.seen) {
+			b.admitted++
+		} else {
+			// the refused reconciles are retried once more of them are admitted
+			delay, admit = b.windowStart.Add(b.config.Window).Sub(now), false
+		}
+	case CircuitBreakerStateClosed:
+	}
+	b.mu.Unlock()
+
+	if changed {
+		b.changed(status)
+	}
+
+	return delay, admit
+}
+
+// observe records the result of a request, err is nil for a successful one
+func (b *CircuitBreaker) observe(err error, now time.Time) {
+	b.mu.Lock()
+	changed := b.roll(now)
+	// the requests still running when the breaker tripped do not count
+	if b.status.State == CircuitBreakerStateOpen {
+		b.mu.Unlock()
+
+		return
+	}
+
+	b.requests++
+	if err != nil {
+		b.failures++
+		b.lastError = err.Error()
+	}
+
+	minRequests := b.config.MinRequests
+	if b.status.State == CircuitBreakerStateHalfOpen {
+		// the half-open breaker sees only a share of the traffic
+		minRequests = int(math.Ceil(float64(minRequests) * b.status.AdmittedRatio))
+	}
+	errorRate := float64(b.failures) / float64(b.requests)
+	if b.requests >= minRequests && errorRate >= b.config.ErrorThreshold {
+		b.status = CircuitBreakerStatus{
+			State:              CircuitBreakerStateOpen,
+			ErrorRate:          errorRate,
+			LastError:          b.lastError,
+			RetryTime:          now.Add(b.config.CoolDown),
+			LastTransitionTime: now,
+		}
+		b.resetWindow(now)
+		circuitBreakerTrips.WithLabelValues(b.clusterName).Inc()
+		changed = true
+	}
+	status := b.status
+	b.mu.Unlock()
+
+	if changed {
+		b.changed(status)
+	}
+}
+
+// roll moves the breaker to its next state once the cool-down or the window is over, it returns whether the state
+// changed, the lock must be held
+func (b *CircuitBreaker) roll(now time.Time) bool {
+	switch b.status.State {
+	case CircuitBreakerStateOpen:
+		if now.Before(b.status.RetryTime) {
+			return false
+		}
+		b.status = CircuitBreakerStatus{
+			State:              CircuitBreakerStateHalfOpen,
+			ErrorRate:          b.status.ErrorRate,
+			LastError:          b.status.LastError,
+			AdmittedRatio:      circuitBreakerHalfOpenRatio,
+			LastTransitionTime: now,
+		}
+		b.resetWindow(now)
+
+		return true
+	case CircuitBreakerStateHalfOpen:
+		if now.Before(b.windowStart.Add(b.config.Window)) {
+			return false
+		}
+		b.resetWindow(now)
+		// the window did not trip the breaker, so more traffic is let through
+		if ratio := b.status.AdmittedRatio * 2; ratio < 1 {
+			b.status.AdmittedRatio = ratio
+
+			return true
+		}
+		b.status = CircuitBreakerStatus{
+			State:              CircuitBreakerStateClosed,
+			LastTransitionTime: now,
+		}
+
+		return true
+	case CircuitBreakerStateClosed:
+		if now.Sub(b.windowStart) >= b.config.Window {
+			b.resetWindow(now)
+		}
+	}
+
+	return false
+}
+
+func (b *CircuitBreaker) resetWindow(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+	b.lastError = ""
+	b.seen = 0
+	b.admitted = 0
+}
+
+func (b *CircuitBreaker) changed(status CircuitBreakerStatus) {
+	setCircuitBreakerStateMetric(b.clusterName, status.State)
+	if b.onChange != nil {
+		b.onChange(status)
+	}
+}
+
+func setCircuitBreakerStateMetric(clusterName string, state CircuitBreakerState) {
+	for _, s := range []CircuitBreakerState{CircuitBreakerStateClosed, CircuitBreakerStateOpen, CircuitBreakerStateHalfOpen} {
+		value := 0.0
+		if s == state {
+			value = 1
+		}
+		circuitBreakerState.WithLabelValues(clusterName, string(s)).Set(value)
+	}
+}
+
+func deleteCircuitBreakerMetrics(clusterName string) {
+	for _, s := range []CircuitBreakerState{CircuitBreakerStateClosed, CircuitBreakerStateOpen, CircuitBreakerStateHalfOpen} {
+		circuitBreakerState.DeleteLabelValues(clusterName, string(s))
+	}
+	circuitBreakerTrips.DeleteLabelValues(clusterName)
+}
+
+type circuitBreakerRoundTripper struct {
+	breaker *CircuitBreaker
+	next    http.RoundTripper
+}
+
+func (rt *circuitBreakerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+
+	switch {
+	case err != nil:
+		// the requests of stopped controllers and informers are cancelled
+		if req.Context().Err() == nil {
+			rt.breaker.observe(errors.WrapIff(err, "%s %s failed", req.Method, req.URL.Path), time.Now())
+		}
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		rt.breaker.observe(errors.Errorf("%s %s failed: %s", req.Method, req.URL.Path, resp.Status), time.Now())
+	default:
+		rt.breaker.observe(nil, time.Now())
+	}
+
+	return resp, err
+}
+
+type circuitBreakerReconciler struct {
+	reconcile.Reconciler
+
+	breaker *CircuitBreaker
+}
+
+// NewCircuitBreakerReconciler runs the reconciles of the reconciler admitted by the circuit breaker, the refused ones
+// are requeued once the breaker may admit them. The reconciler is returned as it is without a breaker.
+func NewCircuitBreakerReconciler(r reconcile.Reconciler, breaker *CircuitBreaker) reconcile.Reconciler {
+	if breaker == nil {
+		return r
+	}
+
+	return &circuitBreakerReconciler{
+		Reconciler: r,
+		breaker:    breaker,
+	}
+}
+
+func (r *circuitBreakerReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	if delay, ok := r.breaker.Admit(time.Now()); !ok {
+		return reconcile.Result{RequeueAfter: delay}, nil
+	}
+
+	return r.Reconciler.Reconcile(ctx, req)
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	status := http.StatusInternalServerError
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(status)
+
+		return rec.Result(), nil
+	})
+
+	changes := make([]clusters.CircuitBreakerState, 0)
+	// the window and the cool-down are only passed by the times given to Admit
+	breaker := clusters.NewCircuitBreaker("circuit-breaker-test", clusters.CircuitBreakerConfig{
+		ErrorThreshold: 0.5,
+		MinRequests:    20,
+		Window:         time.Hour,
+		CoolDown:       time.Hour,
+	}, func(status clusters.CircuitBreakerStatus) {
+		changes = append(changes, status.State)
+	})
+	rt := breaker.WrapTransport(transport)
+
+	send := func(n int) {
+		for i := 0; i < n; i++ {
+			resp, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "https://cluster/api/v1/namespaces/default/configmaps", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+		}
+	}
+	admitted := func(now time.Time, n int) int {
+		count := 0
+		for i := 0; i < n; i++ {
+			if _, ok := breaker.Admit(now); ok {
+				count++
+			}
+		}
+
+		return count
+	}
+
+	// too few requests do not trip the breaker
+	send(19)
+	if state := breaker.GetStatus().State; state != clusters.CircuitBreakerStateClosed {
+		t.Fatalf("expected the breaker to be closed, got %s", state)
+	}
+
+	send(1)
+	tripped := breaker.GetStatus()
+	if tripped.State != clusters.CircuitBreakerStateOpen || tripped.ErrorRate != 1 || tripped.LastError == "" {
+		t.Fatalf("expected the breaker to be open, got %+v", tripped)
+	}
+	delay, ok := breaker.Admit(tripped.RetryTime.Add(-time.Minute))
+	if ok || delay != time.Minute {
+		t.Fatalf("expected the reconcile to be refused for a minute, got %v, %t", delay, ok)
+	}
+
+	// the requests of the open breaker are ignored
+	status = http.StatusOK
+	send(100)
+	if state := breaker.GetStatus().State; state != clusters.CircuitBreakerStateOpen {
+		t.Fatalf("expected the breaker to stay open, got %s", state)
+	}
+
+	halfOpen := tripped.RetryTime
+	if count := admitted(halfOpen, 16); count != 2 {
+		t.Fatalf("expected the half-open breaker to admit 2 of 16 reconciles, got %d", count)
+	}
+
+	// fewer failures trip the half-open breaker again
+	status = http.StatusTooManyRequests
+	send(3)
+	reopened := breaker.GetStatus()
+	if reopened.State != clusters.CircuitBreakerStateOpen {
+		t.Fatalf("expected the half-open breaker to open again, got %s", reopened.State)
+	}
+
+	status = http.StatusOK
+	halfOpen = reopened.RetryTime
+	for i, expected := range []int{2, 4, 8} {
+		now := halfOpen.Add(time.Duration(i) * time.Hour)
+		send(10)
+		if count := admitted(now, 16); count != expected {
+			t.Fatalf("expected the half-open breaker to admit %d of 16 reconciles, got %d", expected, count)
+		}
+	}
+	if count := admitted(halfOpen.Add(3*time.Hour), 16); count != 16 {
+		t.Fatalf("expected the closed breaker to admit every reconcile, got %d", count)
+	}
+
+	expected := []clusters.CircuitBreakerState{
+		clusters.CircuitBreakerStateOpen,
+		clusters.CircuitBreakerStateHalfOpen,
+		clusters.CircuitBreakerStateOpen,
+		clusters.CircuitBreakerStateHalfOpen,
+		clusters.CircuitBreakerStateHalfOpen,
+		clusters.CircuitBreakerStateHalfOpen,
+		clusters.CircuitBreakerStateClosed,
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected the state changes %v, got %v", expected, changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Fatalf("expected the state changes %v, got %v", expected, changes)
+		}
+	}
+}
+
+func TestCircuitBreakerReconciler(t *testing.T) {
+	t.Parallel()
+
+	reconciled := 0
+	r := reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		reconciled++
+
+		return reconcile.Result{}, nil
+	})
+
+	if clusters.NewCircuitBreakerReconciler(r, nil) == nil {
+		t.Fatal("expected the reconciler without a breaker")
+	}
+
+	breaker := clusters.NewCircuitBreaker("circuit-breaker-reconciler-test", clusters.CircuitBreakerConfig{
+		MinRequests: 1,
+		CoolDown:    time.Hour,
+	}, nil)
+	rt := breaker.WrapTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(http.StatusServiceUnavailable)
+
+		return rec.Result(), nil
+	}))
+	gated := clusters.NewCircuitBreakerReconciler(r, breaker)
+
+	result, err := gated.Reconcile(context.Background(), reconcile.Request{})
+	if err != nil || result.RequeueAfter != 0 || reconciled != 1 {
+		t.Fatalf("expected the closed breaker to run the reconcile, got %v, %v", result, err)
+	}
+
+	resp, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "https://cluster/api/v1/namespaces", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	result, err = gated.Reconcile(context.Background(), reconcile.Request{})
+	if err != nil || result.RequeueAfter <= 0 || result.RequeueAfter > time.Hour || reconciled != 1 {
+		t.Fatalf("expected the open breaker to requeue the reconcile after the cool-down, got %v, %v", result, err)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// DefaultClientQPS and DefaultClientBurst are the client-go defaults
+	DefaultClientQPS   = 5
+	DefaultClientBurst = 10
+)
+
+// ClientConfig controls the clients built for a cluster, every controller of the cluster shares its client
+type ClientConfig struct {
+	// QPS is the number of requests per second the clients can send to the cluster
+	QPS float32
+	// Burst is the number of requests the clients can send at once above QPS
+	Burst int
+	// Timeout is the time a request can take before it fails, zero means no timeout
+	Timeout time.Duration
+	// ForceJSON disables the protobuf encoding of the built-in kinds, e.g. for clusters behind proxies mishandling it
+	ForceJSON bool
+}
+
+// WithDefaults returns the config with the unset fields set to their default values
+func (c ClientConfig) WithDefaults() ClientConfig {
+	if c.QPS <= 0 {
+		c.QPS = DefaultClientQPS
+	}
+	if c.Burst <= 0 {
+		c.Burst = DefaultClientBurst
+	}
+	if c.Timeout < 0 {
+		c.Timeout = 0
+	}
+
+	return c
+}
+
+// Apply returns a copy of the rest config with the client settings applied
+func (c ClientConfig) Apply(config *rest.Config) *rest.Config {
+	if config == nil {
+		return nil
+	}
+
+	config = rest.CopyConfig(config)
+	config.QPS = c.QPS
+	config.Burst = c.Burst
+	config.Timeout = c.Timeout
+	// a custom rate limiter would ignore the QPS and burst settings
+	config.RateLimiter = nil
+	c.applyContentType(config)
+
+	return config
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/protobuf"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+func TestClientConfigApply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		config  clusters.ClientConfig
+		qps     float32
+		burst   int
+		timeout time.Duration
+	}{
+		{
+			name:   "defaults",
+			config: clusters.ClientConfig{},
+			qps:    clusters.DefaultClientQPS,
+			burst:  clusters.DefaultClientBurst,
+		},
+		{
+			name:    "custom",
+			config:  clusters.ClientConfig{QPS: 50, Burst: 100, Timeout: time.Second * 30},
+			qps:     50,
+			burst:   100,
+			timeout: time.Second * 30,
+		},
+		{
+			name:   "negative values",
+			config: clusters.ClientConfig{QPS: -1, Burst: -1, Timeout: -time.Second},
+			qps:    clusters.DefaultClientQPS,
+			burst:  clusters.DefaultClientBurst,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			original := &rest.Config{
+				Host:        "https://127.0.0.1:6443",
+				RateLimiter: flowcontrol.NewFakeAlwaysRateLimiter(),
+			}
+			config := test.config.WithDefaults().Apply(original)
+
+			if config.QPS != test.qps || config.Burst != test.burst || config.Timeout != test.timeout {
+				t.Fatalf("unexpected client settings: qps=%v burst=%d timeout=%s", config.QPS, config.Burst, config.Timeout)
+			}
+			if config.RateLimiter != nil {
+				t.Fatal("the rate limiter of the original config must be dropped")
+			}
+			if config.Host != original.Host {
+				t.Fatalf("unexpected host: %s", config.Host)
+			}
+			if original.QPS != 0 || original.RateLimiter == nil {
+				t.Fatal("the original config must not be modified")
+			}
+		})
+	}
+}
+
+func TestClientConfigContentType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		config            clusters.ClientConfig
+		contentType       string
+		clientsetType     string
+		clientsetAccepted string
+	}{
+		{
+			name:              "protobuf",
+			config:            clusters.ClientConfig{},
+			contentType:       "",
+			clientsetType:     runtime.ContentTypeProtobuf,
+			clientsetAccepted: runtime.ContentTypeProtobuf + "," + runtime.ContentTypeJSON,
+		},
+		{
+			name:              "json forced",
+			config:            clusters.ClientConfig{ForceJSON: true},
+			contentType:       runtime.ContentTypeJSON,
+			clientsetType:     runtime.ContentTypeJSON,
+			clientsetAccepted: runtime.ContentTypeJSON,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			original := &rest.Config{
+				Host: "https://127.0.0.1:6443",
+				ContentConfig: rest.ContentConfig{
+					ContentType: "application/yaml",
+				},
+			}
+
+			// the controller-runtime clients choose protobuf for the built-in kinds only if the content type is not set
+			config := test.config.WithDefaults().Apply(original)
+			if config.ContentType != test.contentType {
+				t.Fatalf("unexpected content type: %q", config.ContentType)
+			}
+
+			config = test.config.WithDefaults().ClientsetConfig(original)
+			if config.ContentType != test.clientsetType || config.AcceptContentTypes != test.clientsetAccepted {
+				t.Fatalf("unexpected clientset content types: %q, %q", config.ContentType, config.AcceptContentTypes)
+			}
+
+			if original.ContentType != "application/yaml" {
+				t.Fatal("the original config must not be modified")
+			}
+		})
+	}
+}
+
+// TestProtobufEncodingSize compares the sizes of the lists of the built-in kinds read from the remote clusters
+func TestProtobufEncodingSize(t *testing.T) {
+	t.Parallel()
+
+	const count = 1000
+
+	configMaps := &corev1.ConfigMapList{}
+	pods := &corev1.PodList{}
+	for i := 0; i < count; i++ {
+		meta := metav1.ObjectMeta{
+			Name:              fmt.Sprintf("object-%d", i),
+			Namespace:         "default",
+			UID:               "0a3f1b9c-7e4d-4b8a-9c2e-5d6f7a8b9c0d",
+			ResourceVersion:   "123456",
+			CreationTimestamp: metav1.Now(),
+			Labels:            map[string]string{"app": "demo", "tier": "backend"},
+		}
+		configMaps.Items = append(configMaps.Items, corev1.ConfigMap{
+			ObjectMeta: meta,
+			Data:       map[string]string{"config.yaml": "key: value\nother: 42\n"},
+		})
+		pods.Items = append(pods.Items, corev1.Pod{
+			ObjectMeta: meta,
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  "demo",
+						Image: "ghcr.io/example/demo:v1.0.0",
+						Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: corev1.ProtocolTCP}},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("100m"),
+								corev1.ResourceMemory: resource.MustParse("128Mi"),
+							},
+						},
+					},
+				},
+				NodeName: "node-1",
+			},
+			Status: corev1.PodStatus{
+				Phase:  corev1.PodRunning,
+				PodIP:  "10.0.0.1",
+				HostIP: "192.168.0.1",
+			},
+		})
+	}
+
+	jsonInfo, _ := runtime.SerializerInfoForMediaType(scheme.Codecs.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	encoders := map[string]runtime.Encoder{
+		"json":     jsonInfo.Serializer,
+		"protobuf": protobuf.NewSerializer(scheme.Scheme, scheme.Scheme),
+	}
+
+	for name, list := range map[string]runtime.Object{"ConfigMaps": configMaps, "Pods": pods} {
+		sizes := make(map[string]int)
+		for encoding, encoder := range encoders {
+			buf := &bytes.Buffer{}
+			if err := encoder.Encode(list, buf); err != nil {
+				t.Fatal(err)
+			}
+			sizes[encoding] = buf.Len()
+		}
+
+		t.Logf("%d %s: json %d bytes, protobuf %d bytes", count, name, sizes["json"], sizes["protobuf"])
+		if sizes["protobuf"] >= sizes["json"] {
+			t.Fatalf("the protobuf encoding of the %s is expected to be smaller", name)
+		}
+	}
+}
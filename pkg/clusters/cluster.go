@@ -16,11 +16,13 @@ package clusters
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
 	"emperror.dev/errors"
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -29,9 +31,9 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/banzaicloud/operator-tools/pkg/resources"
-)
 
-const defaultLivenessCheckInterval = time.Second * 5
+	"github.com/cisco-open/cluster-registry-controller/pkg/tracing"
+)
 
 type Cluster struct {
 	name      string
@@ -43,29 +45,57 @@ type Cluster struct {
 	ctxCancel    context.CancelFunc
 	mgrCtx       context.Context
 	mgrCtxCancel context.CancelFunc
+	// mgrDone is closed once the last started manager is stopped
+	mgrDone chan struct{}
 
 	log logr.Logger
 	mgr ctrl.Manager
-
-	alive                 bool
-	started               bool
-	mgrStopped            bool
-	secretID              *string
-	clusterID             string
-	livenessCheckInterval time.Duration
-	onAliveFuncs          []ClusterFunc
-	onDeadFuncs           []ClusterFunc
-	features              map[string]ClusterFeature
-	kubeconfig            []byte
+	// restMapper is the REST mapper of the running manager
+	restMapper *RESTMapper
+
+	alive             bool
+	started           bool
+	mgrStopped        bool
+	cacheSynced       bool
+	secretID          *string
+	clusterID         string
+	onAliveFuncs      []ClusterFunc
+	onDeadFuncs       []ClusterFunc
+	features          map[string]ClusterFeature
+	kubeconfig        []byte
+	metadata          map[string]string
+	livenessCheckFunc LivenessCheckFunc
+	probeConfig       ProbeConfig
+	clientConfig      ClientConfig
+	connectionConfig  ConnectionConfig
+	endpointFailover  *EndpointFailover
+	probeStatus       ProbeStatus
+	onProbeFuncs      []ProbeFunc
+	// watchMonitor observes the list and watch requests of the informers of the manager
+	watchMonitor          *WatchMonitor
+	watchFailureThreshold int
+	onWatchStatusFuncs    []WatchStatusFunc
+	watchStatusFuncs      map[string]WatchStatusFunc
+	// circuitBreaker pauses the reconciles of the controllers while the API server is erroring heavily
+	circuitBreaker        *CircuitBreaker
+	circuitBreakerConfig  *CircuitBreakerConfig
+	onCircuitBreakerFuncs []CircuitBreakerFunc
 
 	controllers        ManagedControllers
 	pendingControllers ManagedControllers
 	mu                 *sync.RWMutex
+	// probeMu serializes the liveness checks with the credential changes
+	probeMu sync.Mutex
+
+	// reconcileGate admits the reconciles of every controller of the cluster
+	reconcileGate ReconcileGate
 }
 
 type (
 	ClusterFunc func(c *Cluster) error
 	Option      func(*Cluster)
+	// LivenessCheckFunc returns the ID of the cluster if it is alive
+	LivenessCheckFunc func(ctx context.Context, c *Cluster) (string, error)
 )
 
 func WithSecretID(secretID string) Option {
@@ -80,9 +110,102 @@ func WithKubeconfig(kubeconfig []byte) Option {
 	}
 }
 
+func WithMetadata(metadata map[string]string) Option {
+	return func(c *Cluster) {
+		c.SetMetadata(metadata)
+	}
+}
+
+func WithLogger(log logr.Logger) Option {
+	return func(c *Cluster) {
+		c.log = log.WithName(c.name)
+	}
+}
+
+// WithLivenessCheckFunc replaces the default liveness check, which reads the kube-system namespace of the cluster
+func WithLivenessCheckFunc(f LivenessCheckFunc) Option {
+	return func(c *Cluster) {
+		c.livenessCheckFunc = f
+	}
+}
+
 func WithLivenessCheckInterval(interval time.Duration) Option {
 	return func(c *Cluster) {
-		c.livenessCheckInterval = interval
+		c.probeConfig.Interval = interval
+	}
+}
+
+// WithProbeConfig sets the interval, the timeout and the failure threshold of the liveness check,
+// unset fields keep their default values
+func WithProbeConfig(config ProbeConfig) Option {
+	return func(c *Cluster) {
+		c.probeConfig = config.WithDefaults()
+	}
+}
+
+// WithClientConfig sets the QPS, the burst and the request timeout of the clients of the cluster,
+// unset fields keep their default values
+func WithClientConfig(config ClientConfig) Option {
+	return func(c *Cluster) {
+		c.clientConfig = config.WithDefaults()
+	}
+}
+
+// WithConnectionConfig records the connection settings the rest config of the cluster is built with,
+// they are already applied to the rest config
+func WithConnectionConfig(config ConnectionConfig) Option {
+	return func(c *Cluster) {
+		c.connectionConfig = config
+	}
+}
+
+// WithEndpointFailover sets the failover the rest config of the cluster sends its requests through,
+// the previous failover of the cluster is stopped
+func WithEndpointFailover(failover *EndpointFailover) Option {
+	return func(c *Cluster) {
+		if c.endpointFailover != failover {
+			c.endpointFailover.Stop()
+		}
+		c.endpointFailover = failover
+	}
+}
+
+// WithOnProbeFunc adds a function called after every liveness check of the cluster
+func WithOnProbeFunc(f ProbeFunc) Option {
+	return func(c *Cluster) {
+		c.onProbeFuncs = append(c.onProbeFuncs, f)
+	}
+}
+
+// WithWatchFailureThreshold sets the number of consecutive failed list and watch requests of a resource after which
+// its watch is reported degraded, 0 disables the reporting
+func WithWatchFailureThreshold(threshold int) Option {
+	return func(c *Cluster) {
+		c.watchFailureThreshold = threshold
+	}
+}
+
+// WithOnWatchStatusFunc adds a function called when the watch of a resource of the cluster becomes degraded or
+// recovers
+func WithOnWatchStatusFunc(f WatchStatusFunc) Option {
+	return func(c *Cluster) {
+		c.onWatchStatusFuncs = append(c.onWatchStatusFuncs, f)
+	}
+}
+
+// WithCircuitBreakerConfig enables the circuit breaker of the cluster, which pauses the reconciles of its controllers
+// while the error rate of the requests is too high, unset fields keep their default values
+func WithCircuitBreakerConfig(config CircuitBreakerConfig) Option {
+	return func(c *Cluster) {
+		config = config.WithDefaults()
+		c.circuitBreakerConfig = &config
+	}
+}
+
+// WithOnCircuitBreakerFunc adds a function called when the circuit breaker of the cluster changes its state
+func WithOnCircuitBreakerFunc(f CircuitBreakerFunc) Option {
+	return func(c *Cluster) {
+		c.onCircuitBreakerFuncs = append(c.onCircuitBreakerFuncs, f)
 	}
 }
 
@@ -120,10 +243,15 @@ func NewCluster(ctx context.Context, name string, k8sConfig *rest.Config, log lo
 			MetricsBindAddress: "0",
 			Port:               0,
 		},
-		livenessCheckInterval: defaultLivenessCheckInterval,
-		onAliveFuncs:          make([]ClusterFunc, 0),
-		onDeadFuncs:           make([]ClusterFunc, 0),
-		features:              make(map[string]ClusterFeature),
+		livenessCheckFunc: kubeSystemNamespaceLivenessCheck,
+		probeConfig:       ProbeConfig{}.WithDefaults(),
+		clientConfig:      ClientConfig{}.WithDefaults(),
+		onAliveFuncs:      make([]ClusterFunc, 0),
+		onDeadFuncs:       make([]ClusterFunc, 0),
+		features:          make(map[string]ClusterFeature),
+
+		watchFailureThreshold: DefaultWatchFailureThreshold,
+		watchStatusFuncs:      make(map[string]WatchStatusFunc),
 
 		controllers:        make(ManagedControllers),
 		pendingControllers: make(ManagedControllers),
@@ -137,6 +265,11 @@ func NewCluster(ctx context.Context, name string, k8sConfig *rest.Config, log lo
 		opt(c)
 	}
 
+	c.watchMonitor = NewWatchMonitor(name, c.watchFailureThreshold, c.onWatchStatus)
+	if c.circuitBreakerConfig != nil {
+		c.circuitBreaker = NewCircuitBreaker(name, *c.circuitBreakerConfig, c.onCircuitBreaker)
+	}
+
 	return c, nil
 }
 
@@ -148,7 +281,7 @@ func (c *Cluster) Start() error {
 	c.started = true
 
 	go func(ctx context.Context, cluster *Cluster, log logr.Logger) {
-		ticker := time.NewTicker(cluster.livenessCheckInterval)
+		ticker := time.NewTicker(cluster.probeConfig.Interval)
 		for {
 			select {
 			case <-ctx.Done():
@@ -218,6 +351,143 @@ func (c *Cluster) IsAlive() bool {
 	return c.alive
 }
 
+func (c *Cluster) GetProbeConfig() ProbeConfig {
+	return c.probeConfig
+}
+
+// GetRESTMapper returns the REST mapper of the running manager, it is nil if the manager is not running
+func (c *Cluster) GetRESTMapper() *RESTMapper {
+	if !c.IsManagerRunning() {
+		return nil
+	}
+
+	return c.restMapper
+}
+
+func (c *Cluster) GetClientConfig() ClientConfig {
+	return c.clientConfig
+}
+
+func (c *Cluster) GetConnectionConfig() ConnectionConfig {
+	return c.connectionConfig
+}
+
+// GetEndpoints returns the API server endpoints the cluster fails over between, it is empty without failover
+func (c *Cluster) GetEndpoints() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.endpointFailover == nil {
+		return nil
+	}
+
+	return c.endpointFailover.Endpoints()
+}
+
+// GetAPIEndpoint returns the API server endpoint the requests to the cluster are sent to
+func (c *Cluster) GetAPIEndpoint() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.endpointFailover != nil {
+		return c.endpointFailover.Active()
+	}
+	if c.k8sConfig == nil {
+		return ""
+	}
+
+	return c.k8sConfig.Host
+}
+
+// GetProbeStatus returns the result of the last liveness checks of the cluster
+func (c *Cluster) GetProbeStatus() ProbeStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.probeStatus
+}
+
+// GetDegradedWatches returns the statuses of the watches of the cluster whose list and watch requests keep failing
+func (c *Cluster) GetDegradedWatches() []WatchStatus {
+	return c.watchMonitor.GetDegraded()
+}
+
+// GetCircuitBreakerStatus returns the state of the circuit breaker of the cluster, it returns false if the cluster
+// has no circuit breaker
+func (c *Cluster) GetCircuitBreakerStatus() (CircuitBreakerStatus, bool) {
+	if c.circuitBreaker == nil {
+		return CircuitBreakerStatus{}, false
+	}
+
+	return c.circuitBreaker.GetStatus(), true
+}
+
+func (c *Cluster) onCircuitBreaker(status CircuitBreakerStatus) {
+	switch status.State {
+	case CircuitBreakerStateOpen:
+		c.log.Info("circuit breaker tripped, reconciles are paused", "errorRate", status.ErrorRate, "error", status.LastError, "retryTime", status.RetryTime)
+	case CircuitBreakerStateHalfOpen:
+		c.log.Info("circuit breaker is half-open", "admittedRatio", status.AdmittedRatio)
+	case CircuitBreakerStateClosed:
+		c.log.Info("circuit breaker closed, reconciles are resumed")
+	}
+
+	for _, f := range c.onCircuitBreakerFuncs {
+		f(c, status)
+	}
+}
+
+// AddWatchStatusFunc adds a function called when the watch of a resource of the cluster becomes degraded or recovers,
+// a function added with the same name is replaced
+func (c *Cluster) AddWatchStatusFunc(name string, f WatchStatusFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.watchStatusFuncs[name] = f
+}
+
+func (c *Cluster) RemoveWatchStatusFunc(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.watchStatusFuncs, name)
+}
+
+func (c *Cluster) onWatchStatus(status WatchStatus) {
+	if status.Degraded {
+		c.log.Info("watch is degraded", "resource", status.Resource.String(), "failures", status.ConsecutiveFailures, "error", status.LastError)
+	} else {
+		c.log.Info("watch recovered", "resource", status.Resource.String())
+	}
+
+	c.mu.RLock()
+	funcs := make([]WatchStatusFunc, 0, len(c.onWatchStatusFuncs)+len(c.watchStatusFuncs))
+	funcs = append(funcs, c.onWatchStatusFuncs...)
+	for _, f := range c.watchStatusFuncs {
+		funcs = append(funcs, f)
+	}
+	c.mu.RUnlock()
+
+	for _, f := range funcs {
+		f(c, status)
+	}
+}
+
+// IsCacheSynced returns whether the cache of the running manager of the cluster is synced
+func (c *Cluster) IsCacheSynced() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.cacheSynced
+}
+
+func (c *Cluster) setCacheSynced(synced bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cacheSynced = synced
+}
+
 func (c *Cluster) IsManagerRunning() bool {
 	return !c.mgrStopped && c.mgr != nil
 }
@@ -228,6 +498,14 @@ func (c *Cluster) Stop() {
 	}
 	c.log.V(2).Info("shutdown cluster")
 	c.ctxCancel()
+	deleteReceivedBytesMetrics(c.GetName())
+	if c.circuitBreaker != nil {
+		deleteCircuitBreakerMetrics(c.GetName())
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	c.endpointFailover.Stop()
 }
 
 func (c *Cluster) StopManager() {
@@ -266,6 +544,13 @@ func (c *Cluster) GetManager() ctrl.Manager {
 	return c.mgr
 }
 
+func (c *Cluster) GetRestConfig() *rest.Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.k8sConfig
+}
+
 func (c *Cluster) GetSecretID() *string {
 	return c.secretID
 }
@@ -274,6 +559,20 @@ func (c *Cluster) GetKubeconfig() []byte {
 	return c.kubeconfig
 }
 
+func (c *Cluster) GetMetadata() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.metadata
+}
+
+func (c *Cluster) SetMetadata(metadata map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.metadata = metadata
+}
+
 func (c *Cluster) AddController(controller ManagedController) error {
 	if c.checkRequiredClusterFeatures(controller) {
 		return c.addController(controller)
@@ -304,6 +603,12 @@ func (c *Cluster) addController(controller ManagedController) error {
 	defer c.mu.Unlock()
 
 	controller.SetLogger(c.log.WithName(name))
+	if gated, ok := controller.(interface{ setReconcileGate(gate ReconcileGate) }); ok && c.reconcileGate != nil {
+		gated.setReconcileGate(c.reconcileGate)
+	}
+	if broken, ok := controller.(interface{ setCircuitBreaker(breaker *CircuitBreaker) }); ok && c.circuitBreaker != nil {
+		broken.setCircuitBreaker(c.circuitBreaker)
+	}
 	c.controllers[name] = controller
 
 	if c.IsManagerRunning() {
@@ -405,29 +710,136 @@ func (c *Cluster) setDead() {
 	c.alive = false
 }
 
-func (c *Cluster) livenessCheck() error {
-	clientset, err := kubernetes.NewForConfig(c.k8sConfig)
+// SetCredentials replaces the rest config of the cluster once it is validated against the cluster, the options can
+// update the related fields, e.g. WithKubeconfig. The manager of the cluster is restarted with the new rest config,
+// which restarts the managed controllers while keeping their reconcilers.
+func (c *Cluster) SetCredentials(k8sConfig *rest.Config, opts ...Option) error {
+	c.probeMu.Lock()
+	defer c.probeMu.Unlock()
+
+	if err := c.validateCredentials(k8sConfig); err != nil {
+		return err
+	}
+
+	c.setDead()
+	if err := c.waitForManagerStop(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.k8sConfig = k8sConfig
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.mu.Unlock()
+
+	c.log.Info("cluster credentials changed")
+
+	if !c.started {
+		return nil
+	}
+
+	return c.probe()
+}
+
+// validateCredentials returns an error unless the credentials can be used to reach the same cluster
+func (c *Cluster) validateCredentials(k8sConfig *rest.Config) error {
+	ctx, cancel := context.WithTimeout(c.ctx, c.probeConfig.Timeout)
+	defer cancel()
+
+	clusterID, err := c.livenessCheckFunc(ctx, &Cluster{
+		name:      c.name,
+		k8sConfig: k8sConfig,
+		log:       c.log,
+		mu:        &sync.RWMutex{},
+	})
 	if err != nil {
-		c.setDead()
+		return errors.WithDetails(errors.WithStack(fmt.Errorf("%w: %s", ErrInvalidCredentials, err)), "cluster", c.name)
+	}
+
+	if current := c.GetClusterID(); current != "" && current != clusterID {
+		return errors.WithDetails(errors.WithStack(fmt.Errorf("%w: credentials are for another cluster", ErrInvalidCredentials)), "cluster", c.name, "expectedClusterID", current, "clusterID", clusterID)
+	}
+
+	return nil
+}
+
+// waitForManagerStop waits for the stopped manager and every managed controller to finish their cleanup,
+// so they can be started again
+func (c *Cluster) waitForManagerStop() error {
+	c.mu.RLock()
+	waitFor := make([]<-chan struct{}, 0, len(c.controllers)+1)
+	if c.mgrDone != nil {
+		waitFor = append(waitFor, c.mgrDone)
+	}
+	for _, mctrl := range c.controllers {
+		waitFor = append(waitFor, mctrl.Done())
+	}
+	c.mu.RUnlock()
 
-		return errors.WrapIf(err, "could not get cluster ID")
+	for _, done := range waitFor {
+		select {
+		case <-done:
+		case <-c.ctx.Done():
+			return errors.WrapIf(c.ctx.Err(), "cluster is stopped")
+		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	return nil
+}
+
+func (c *Cluster) livenessCheck() error {
+	c.probeMu.Lock()
+	defer c.probeMu.Unlock()
+
+	return c.probe()
+}
+
+func (c *Cluster) probe() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.probeConfig.Timeout)
 	defer cancel()
-	ns, err := clientset.CoreV1().Namespaces().Get(ctx, metav1.NamespaceSystem, metav1.GetOptions{})
+
+	clusterID, err := c.livenessCheckFunc(ctx, c)
+
+	c.mu.Lock()
+	previous := c.probeStatus
+	c.probeStatus = previous.next(err, time.Now())
+	current := c.probeStatus
+	c.mu.Unlock()
+
+	for _, f := range c.onProbeFuncs {
+		f(c, previous, current)
+	}
+
 	if err != nil {
-		c.setDead()
+		// a cluster is only considered dead after the configured number of consecutive failures
+		if current.ConsecutiveFailures >= c.probeConfig.FailureThreshold {
+			c.setDead()
+		}
 
-		return errors.WrapIf(err, "could not get cluster ID")
+		return err
 	}
 
 	c.setAlive()
-	c.clusterID = string(ns.UID)
+	c.clusterID = clusterID
 
 	return nil
 }
 
+func kubeSystemNamespaceLivenessCheck(ctx context.Context, c *Cluster) (string, error) {
+	clientset, err := kubernetes.NewForConfig(c.clientConfig.ClientsetConfig(c.GetRestConfig()))
+	if err != nil {
+		return "", errors.WrapIf(err, "could not get cluster ID")
+	}
+
+	ns, err := clientset.CoreV1().Namespaces().Get(ctx, metav1.NamespaceSystem, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.WrapIf(err, "could not get cluster ID")
+	}
+
+	return string(ns.UID), nil
+}
+
 func (c *Cluster) StartManager() error {
 	var err error
 
@@ -437,10 +849,36 @@ func (c *Cluster) StartManager() error {
 
 	c.mgrStopped = false
 	c.mgrCtx, c.mgrCtxCancel = context.WithCancel(c.ctx)
-
-	c.mgr, err = ctrl.NewManager(c.k8sConfig, c.ctrlOptions)
+	mgrDone := make(chan struct{})
+	c.mgrDone = mgrDone
+
+	restConfig := c.clientConfig.Apply(c.GetRestConfig())
+	// the informers of the new manager start watching from scratch
+	c.watchMonitor.Reset(time.Now())
+	restConfig.Wrap(c.watchMonitor.WrapTransport)
+	if c.circuitBreaker != nil {
+		restConfig.Wrap(c.circuitBreaker.WrapTransport)
+	}
+	// the direct reads of the traced reconciles from the cluster are traced too
+	restConfig.Wrap(tracing.WrapTransport)
+	restConfig.Wrap(wrapReceivedBytesTransport(c.GetName()))
+	c.restMapper, err = c.startRESTMapper(restConfig)
+	if err == nil {
+		options := c.ctrlOptions
+		options.MapperProvider = func(*rest.Config) (meta.RESTMapper, error) {
+			return c.restMapper, nil
+		}
+		c.mgr, err = ctrl.NewManager(restConfig, options)
+		err = errors.WrapIf(err, "could not create manager")
+	}
 	if err != nil {
-		return errors.WrapIf(err, "could not create manager")
+		// the manager can be started again on the next liveness check
+		c.mgrCtxCancel()
+		c.mgrCtx = nil
+		c.mgrCtxCancel = nil
+		close(mgrDone)
+
+		return err
 	}
 
 	go func() {
@@ -451,12 +889,14 @@ func (c *Cluster) StartManager() error {
 			c.log.V(2).Info("manager stopped")
 		}
 		<-c.mgrCtx.Done()
+		c.setCacheSynced(false)
 		c.mgrCtx = nil
 		c.mgrCtxCancel = nil
 		c.mgr = nil
+		close(mgrDone)
 	}()
 
-	c.mgr.GetCache().WaitForCacheSync(c.mgrCtx)
+	c.setCacheSynced(c.mgr.GetCache().WaitForCacheSync(c.mgrCtx))
 
 	c.log.V(2).Info("manager started")
 
@@ -473,6 +913,20 @@ func (c *Cluster) StartManager() error {
 	return nil
 }
 
+// startRESTMapper starts the REST mapper shared by every client of the manager, it is stopped with the manager
+func (c *Cluster) startRESTMapper(config *rest.Config) (*RESTMapper, error) {
+	mapper, err := NewRESTMapperForConfig(config, WithRESTMapperName(c.name), WithRESTMapperLogger(c.log))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mapper.Start(c.mgrCtx); err != nil {
+		return nil, errors.WrapIf(err, "could not start rest mapper")
+	}
+
+	return mapper, nil
+}
+
 func (c *Cluster) runClusterFunc(f ClusterFunc) {
 	//nolint:gomnd
 	backoff := wait.Backoff{
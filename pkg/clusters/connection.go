@@ -0,0 +1,96 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"bytes"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"os"
+
+	"emperror.dev/errors"
+	"k8s.io/client-go/rest"
+)
+
+var (
+	ErrInvalidCABundle = errors.New("invalid CA bundle")
+	ErrInvalidProxyURL = errors.New("invalid proxy URL")
+)
+
+// ConnectionConfig holds the transport settings of the connections to a cluster which are not part of its kubeconfig
+type ConnectionConfig struct {
+	// CABundle is a PEM encoded bundle of CA certificates trusted on top of the ones of the kubeconfig, or instead of
+	// the system CAs if the kubeconfig has none
+	CABundle []byte
+	// ProxyURL is the URL of the proxy the cluster is connected through, http, https and socks5 proxies are supported
+	ProxyURL string
+}
+
+// Equal returns whether the two configs result in the same connections
+func (c ConnectionConfig) Equal(other ConnectionConfig) bool {
+	return bytes.Equal(c.CABundle, other.CABundle) && c.ProxyURL == other.ProxyURL
+}
+
+// Apply returns a copy of the rest config with the CA bundle merged into its trusted CAs and its proxy set,
+// the transport of the resulting config is built to make sure that it is usable
+func (c ConnectionConfig) Apply(config *rest.Config) (*rest.Config, error) {
+	config = rest.CopyConfig(config)
+
+	if len(c.CABundle) > 0 {
+		if !x509.NewCertPool().AppendCertsFromPEM(c.CABundle) {
+			return nil, errors.WithStack(ErrInvalidCABundle)
+		}
+
+		caData := config.TLSClientConfig.CAData
+		if len(caData) == 0 && config.TLSClientConfig.CAFile != "" {
+			var err error
+			caData, err = os.ReadFile(config.TLSClientConfig.CAFile)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "could not read CA file", "file", config.TLSClientConfig.CAFile)
+			}
+		}
+
+		// the CA file would take precedence over the merged data
+		config.TLSClientConfig.CAFile = ""
+		config.TLSClientConfig.CAData = c.CABundle
+		if len(caData) > 0 {
+			config.TLSClientConfig.CAData = bytes.Join([][]byte{caData, c.CABundle}, []byte("\n"))
+		}
+	}
+
+	if c.ProxyURL != "" {
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return nil, errors.WithDetails(ErrInvalidProxyURL, "url", c.ProxyURL, "error", err.Error())
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, errors.WithDetails(ErrInvalidProxyURL, "scheme", proxyURL.Scheme)
+		}
+		if proxyURL.Host == "" {
+			return nil, errors.WithDetails(ErrInvalidProxyURL, "url", c.ProxyURL)
+		}
+
+		config.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if _, err := rest.TransportFor(config); err != nil {
+		return nil, errors.WrapIf(err, "could not build transport")
+	}
+
+	return config, nil
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+func versionHandler(served *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(served, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major":"1","minor":"21","gitVersion":"v1.21.3"}`))
+	}
+}
+
+func serverVersion(config *rest.Config) error {
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.ServerVersion()
+
+	return err
+}
+
+func serverCA(server *httptest.Server) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+func selfSignedCA(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "other-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})
+}
+
+func TestConnectionConfigCABundle(t *testing.T) {
+	t.Parallel()
+
+	var served int32
+	server := httptest.NewTLSServer(versionHandler(&served))
+	t.Cleanup(server.Close)
+
+	// an unrelated CA the kubeconfig trusts
+	otherCA := selfSignedCA(t)
+	otherCAFile := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(otherCAFile, otherCA, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := serverVersion(&rest.Config{Host: server.URL, TLSClientConfig: rest.TLSClientConfig{CAData: otherCA}}); err == nil {
+		t.Fatal("the server must not be trusted by the unrelated CA")
+	}
+
+	if err := serverVersion(&rest.Config{Host: server.URL}); err == nil {
+		t.Fatal("the server must not be trusted without the CA bundle")
+	}
+
+	tests := []struct {
+		name   string
+		config *rest.Config
+	}{
+		{
+			name:   "without kubeconfig CA",
+			config: &rest.Config{Host: server.URL},
+		},
+		{
+			name:   "merged with kubeconfig CA data",
+			config: &rest.Config{Host: server.URL, TLSClientConfig: rest.TLSClientConfig{CAData: otherCA}},
+		},
+		{
+			name:   "merged with kubeconfig CA file",
+			config: &rest.Config{Host: server.URL, TLSClientConfig: rest.TLSClientConfig{CAFile: otherCAFile}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := clusters.ConnectionConfig{CABundle: serverCA(server)}.Apply(test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := serverVersion(config); err != nil {
+				t.Fatalf("the server must be trusted with the CA bundle: %s", err)
+			}
+		})
+	}
+}
+
+func TestConnectionConfigProxy(t *testing.T) {
+	t.Parallel()
+
+	var served, proxied int32
+	server := httptest.NewServer(versionHandler(&served))
+	t.Cleanup(server.Close)
+
+	proxy := httptest.NewServer(versionHandler(&proxied))
+	t.Cleanup(proxy.Close)
+
+	config, err := clusters.ConnectionConfig{ProxyURL: proxy.URL}.Apply(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := serverVersion(config); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(&proxied) != 1 || atomic.LoadInt32(&served) != 0 {
+		t.Fatalf("the request must be sent through the proxy, proxied: %d, served: %d", proxied, served)
+	}
+}
+
+func TestConnectionConfigInvalid(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name   string
+		config clusters.ConnectionConfig
+		rest   rest.Config
+		err    error
+	}{
+		{
+			name:   "CA bundle without certificates",
+			config: clusters.ConnectionConfig{CABundle: []byte("not a certificate")},
+			err:    clusters.ErrInvalidCABundle,
+		},
+		{
+			name:   "unsupported proxy scheme",
+			config: clusters.ConnectionConfig{ProxyURL: "ftp://proxy.example.com"},
+			err:    clusters.ErrInvalidProxyURL,
+		},
+		{
+			name:   "proxy URL without host",
+			config: clusters.ConnectionConfig{ProxyURL: "http://"},
+			err:    clusters.ErrInvalidProxyURL,
+		},
+		{
+			name:   "unparsable proxy URL",
+			config: clusters.ConnectionConfig{ProxyURL: "http://proxy:port"},
+			err:    clusters.ErrInvalidProxyURL,
+		},
+		{
+			name:   "CA bundle with insecure kubeconfig",
+			config: clusters.ConnectionConfig{CABundle: serverCA(server)},
+			rest:   rest.Config{TLSClientConfig: rest.TLSClientConfig{Insecure: true}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.rest.Host = server.URL
+			_, err := test.config.Apply(&test.rest)
+			if err == nil {
+				t.Fatal("invalid connection config must fail")
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+)
+
+// the encodings of the responses counted by the received bytes metric
+const (
+	encodingProtobuf = "protobuf"
+	encodingJSON     = "json"
+	encodingOther    = "other"
+)
+
+// applyContentType sets the encoding of the requests of the clients of the cluster. The controller-runtime clients and
+// caches use protobuf for the built-in kinds unless the content type is set, the custom resources and the unstructured
+// objects are always sent and received as JSON. Forcing JSON sets the content type, so it applies to every kind.
+func (c ClientConfig) applyContentType(config *rest.Config) {
+	if c.ForceJSON {
+		config.ContentType = runtime.ContentTypeJSON
+		config.AcceptContentTypes = runtime.ContentTypeJSON
+
+		return
+	}
+
+	config.ContentType = ""
+	config.AcceptContentTypes = ""
+}
+
+// ClientsetConfig returns a copy of the rest config for the clientsets of the built-in kinds, which negotiate protobuf
+// with a fallback to JSON unless JSON is forced
+func (c ClientConfig) ClientsetConfig(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	if c.ForceJSON {
+		config.ContentType = runtime.ContentTypeJSON
+		config.AcceptContentTypes = runtime.ContentTypeJSON
+
+		return config
+	}
+
+	config.ContentType = runtime.ContentTypeProtobuf
+	config.AcceptContentTypes = runtime.ContentTypeProtobuf + "," + runtime.ContentTypeJSON
+
+	return config
+}
+
+// wrapReceivedBytesTransport counts the bytes of the response bodies received from the cluster by their encoding
+func wrapReceivedBytesTransport(clusterName string) func(rt http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &receivedBytesRoundTripper{
+			clusterName: clusterName,
+			next:        rt,
+		}
+	}
+}
+
+// deleteReceivedBytesMetrics removes the received bytes metrics of the cluster
+func deleteReceivedBytesMetrics(clusterName string) {
+	for _, encoding := range []string{encodingProtobuf, encodingJSON, encodingOther} {
+		clientReceivedBytes.DeleteLabelValues(clusterName, encoding)
+	}
+}
+
+type receivedBytesRoundTripper struct {
+	clusterName string
+	next        http.RoundTripper
+}
+
+func (rt *receivedBytesRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		return resp, err
+	}
+
+	// the bodies of the watches are streamed, so the bytes are counted as they are read
+	resp.Body = &countingReadCloser{
+		ReadCloser: resp.Body,
+		counter:    clientReceivedBytes.WithLabelValues(rt.clusterName, getEncoding(resp.Header.Get("Content-Type"))),
+	}
+
+	return resp, nil
+}
+
+// getEncoding returns the encoding of the content type of a response
+func getEncoding(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return encodingOther
+	}
+
+	switch mediaType {
+	case runtime.ContentTypeProtobuf:
+		return encodingProtobuf
+	case runtime.ContentTypeJSON:
+		return encodingJSON
+	default:
+		return encodingOther
+	}
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	counter prometheus.Counter
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.counter.Add(float64(n))
+
+	return n, err
+}
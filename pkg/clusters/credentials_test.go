@@ -0,0 +1,102 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"context"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/rest"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+// hostLivenessCheck returns the ID of the cluster reached through the host of the rest config
+func hostLivenessCheck(ids map[string]string) clusters.LivenessCheckFunc {
+	return func(ctx context.Context, c *clusters.Cluster) (string, error) {
+		id, ok := ids[c.GetRestConfig().Host]
+		if !ok {
+			return "", errors.New("unauthorized")
+		}
+
+		return id, nil
+	}
+}
+
+func TestClusterSetCredentials(t *testing.T) {
+	t.Parallel()
+
+	ids := map[string]string{
+		"https://127.0.0.1:1/old":   "cluster-a",
+		"https://127.0.0.1:1/new":   "cluster-a",
+		"https://127.0.0.1:1/other": "cluster-b",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cl, err := clusters.NewCluster(ctx, "test", &rest.Config{Host: "https://127.0.0.1:1/old"}, logr.Discard(),
+		clusters.WithLivenessCheckFunc(hostLivenessCheck(ids)),
+		clusters.WithKubeconfig([]byte("old")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cl.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Stop()
+
+	if cl.GetClusterID() != "cluster-a" {
+		t.Fatalf("unexpected cluster ID %q", cl.GetClusterID())
+	}
+
+	tests := []struct {
+		name       string
+		host       string
+		kubeconfig string
+		valid      bool
+	}{
+		{name: "unauthorized", host: "https://127.0.0.1:1/expired", kubeconfig: "expired", valid: false},
+		{name: "another cluster", host: "https://127.0.0.1:1/other", kubeconfig: "other", valid: false},
+		{name: "rotated", host: "https://127.0.0.1:1/new", kubeconfig: "new", valid: true},
+	}
+
+	for _, test := range tests {
+		err := cl.SetCredentials(&rest.Config{Host: test.host}, clusters.WithKubeconfig([]byte(test.kubeconfig)), clusters.WithSecretID("ns/"+test.kubeconfig))
+		if !test.valid {
+			if !errors.Is(err, clusters.ErrInvalidCredentials) {
+				t.Fatalf("%s: expected invalid credentials error, got %v", test.name, err)
+			}
+			if string(cl.GetKubeconfig()) != "old" {
+				t.Fatalf("%s: credentials replaced although they are invalid", test.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if string(cl.GetKubeconfig()) != test.kubeconfig || *cl.GetSecretID() != "ns/"+test.kubeconfig {
+			t.Fatalf("%s: credentials not replaced", test.name)
+		}
+		if !cl.IsAlive() || !cl.GetProbeStatus().Reachable {
+			t.Fatalf("%s: cluster not alive with the new credentials", test.name)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	DefaultEndpointFailureThreshold    = 3
+	DefaultEndpointHealthCheckInterval = time.Second * 10
+	DefaultEndpointHealthCheckTimeout  = time.Second * 5
+)
+
+var ErrNoEndpoints = errors.New("no API server endpoints")
+
+// EndpointFailover sends the requests of a cluster to one of its API server endpoints. It switches to the next
+// endpoint after a number of consecutive connection errors of the active one, and switches back to a preferred
+// endpoint once it passes the same number of consecutive health checks, so that transient errors do not make it flap.
+type EndpointFailover struct {
+	endpoints           []failoverEndpoint
+	failureThreshold    int
+	healthCheckInterval time.Duration
+	onChangeFuncs       []EndpointChangeFunc
+	log                 logr.Logger
+
+	active   int
+	failures int
+	// recoveries are the consecutive passed health checks of the endpoints preferred over the active one
+	recoveries map[int]int
+	mu         sync.Mutex
+
+	stop context.CancelFunc
+}
+
+type failoverEndpoint struct {
+	url       *url.URL
+	transport http.RoundTripper
+}
+
+// EndpointChangeFunc is called with the previous and the current endpoint when the active endpoint changes
+type EndpointChangeFunc func(previous string, current string, reason string)
+
+type EndpointFailoverOption func(f *EndpointFailover)
+
+// WithEndpointFailureThreshold sets the number of consecutive errors and health checks after which
+// the endpoint is switched
+func WithEndpointFailureThreshold(threshold int) EndpointFailoverOption {
+	return func(f *EndpointFailover) {
+		if threshold > 0 {
+			f.failureThreshold = threshold
+		}
+	}
+}
+
+func WithEndpointHealthCheckInterval(interval time.Duration) EndpointFailoverOption {
+	return func(f *EndpointFailover) {
+		if interval > 0 {
+			f.healthCheckInterval = interval
+		}
+	}
+}
+
+func WithOnEndpointChangeFunc(fn EndpointChangeFunc) EndpointFailoverOption {
+	return func(f *EndpointFailover) {
+		f.onChangeFuncs = append(f.onChangeFuncs, fn)
+	}
+}
+
+func WithEndpointFailoverLogger(log logr.Logger) EndpointFailoverOption {
+	return func(f *EndpointFailover) {
+		f.log = log
+	}
+}
+
+// NewEndpointFailover creates a failover between the rest configs of the endpoints of a cluster, in the order of
+// preference, and returns the rest config sending the requests through it. The configs should only differ in their
+// hosts and TLS settings, the rest of the first config is used for every endpoint. The health checks of the
+// endpoints run until Stop is called.
+func NewEndpointFailover(configs []*rest.Config, opts ...EndpointFailoverOption) (*EndpointFailover, *rest.Config, error) {
+	if len(configs) == 0 {
+		return nil, nil, errors.WithStack(ErrNoEndpoints)
+	}
+
+	f := &EndpointFailover{
+		failureThreshold:    DefaultEndpointFailureThreshold,
+		healthCheckInterval: DefaultEndpointHealthCheckInterval,
+		log:                 logr.Discard(),
+		recoveries:          make(map[int]int),
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	for _, config := range configs {
+		endpoint, err := newFailoverEndpoint(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		f.endpoints = append(f.endpoints, endpoint)
+	}
+
+	// the TLS settings of the endpoints are part of their transports
+	config := rest.CopyConfig(configs[0])
+	config.TLSClientConfig = rest.TLSClientConfig{}
+	config.Proxy = nil
+	config.Dial = nil
+	config.Transport = f
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.stop = cancel
+	if len(f.endpoints) > 1 {
+		go f.runHealthChecks(ctx)
+	}
+
+	return f, config, nil
+}
+
+func newFailoverEndpoint(config *rest.Config) (failoverEndpoint, error) {
+	u, err := url.Parse(config.Host)
+	if err != nil || u.Host == "" {
+		return failoverEndpoint{}, errors.WithDetails(ErrInvalidClusterConfig, "host", config.Host)
+	}
+	if u.Scheme == "" {
+		u.Scheme = "https"
+	}
+
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return failoverEndpoint{}, errors.WrapIfWithDetails(err, "could not create TLS config", "host", config.Host)
+	}
+
+	proxy := config.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+
+	dial := config.Dial
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+
+	return failoverEndpoint{
+		url: &url.URL{Scheme: u.Scheme, Host: u.Host},
+		transport: utilnet.SetTransportDefaults(&http.Transport{
+			Proxy:               proxy,
+			TLSHandshakeTimeout: 10 * time.Second,
+			TLSClientConfig:     tlsConfig,
+			MaxIdleConnsPerHost: 25,
+			DialContext:         dial,
+		}),
+	}, nil
+}
+
+// RoundTrip sends the request to the active endpoint
+func (f *EndpointFailover) RoundTrip(req *http.Request) (*http.Response, error) {
+	index, endpoint := f.getActive()
+
+	r := req.Clone(req.Context())
+	r.URL.Scheme = endpoint.url.Scheme
+	r.URL.Host = endpoint.url.Host
+	r.Host = ""
+
+	resp, err := endpoint.transport.RoundTrip(r)
+	// requests cancelled by the caller say nothing about the endpoint
+	if req.Context().Err() == nil {
+		f.report(index, err)
+	}
+
+	return resp, err
+}
+
+// Active returns the URL of the endpoint the requests are sent to
+func (f *EndpointFailover) Active() string {
+	_, endpoint := f.getActive()
+
+	return endpoint.url.String()
+}
+
+// Endpoints returns the URLs of the endpoints in the order of preference
+func (f *EndpointFailover) Endpoints() []string {
+	endpoints := make([]string, 0, len(f.endpoints))
+	for _, endpoint := range f.endpoints {
+		endpoints = append(endpoints, endpoint.url.String())
+	}
+
+	return endpoints
+}
+
+// Stop stops the health checks of the endpoints
+func (f *EndpointFailover) Stop() {
+	if f == nil {
+		return
+	}
+
+	f.stop()
+}
+
+func (f *EndpointFailover) getActive() (int, failoverEndpoint) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.active, f.endpoints[f.active]
+}
+
+func (f *EndpointFailover) report(index int, err error) {
+	f.mu.Lock()
+
+	if index != f.active || len(f.endpoints) < 2 {
+		f.mu.Unlock()
+
+		return
+	}
+
+	if err == nil {
+		f.failures = 0
+		f.mu.Unlock()
+
+		return
+	}
+
+	f.failures++
+	if f.failures < f.failureThreshold {
+		f.mu.Unlock()
+
+		return
+	}
+
+	f.log.Info("API server endpoint failed", "endpoint", f.endpoints[index].url.String(), "error", err.Error())
+	f.mu.Unlock()
+
+	f.switchTo(index, (index+1)%len(f.endpoints), "EndpointFailed")
+}
+
+// switchTo makes the next endpoint active if the active one is still the expected one
+func (f *EndpointFailover) switchTo(expected int, next int, reason string) {
+	f.mu.Lock()
+	if f.active != expected {
+		f.mu.Unlock()
+
+		return
+	}
+
+	previous := f.endpoints[f.active].url.String()
+	f.active = next
+	f.failures = 0
+	f.recoveries = make(map[int]int)
+	current := f.endpoints[next].url.String()
+	f.mu.Unlock()
+
+	f.log.Info("API server endpoint changed", "previous", previous, "current", current, "reason", reason)
+	for _, fn := range f.onChangeFuncs {
+		fn(previous, current, reason)
+	}
+}
+
+func (f *EndpointFailover) runHealthChecks(ctx context.Context) {
+	ticker := time.NewTicker(f.healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.checkPreferredEndpoints(ctx)
+		}
+	}
+}
+
+// checkPreferredEndpoints fails back to the most preferred endpoint which passed enough consecutive health checks
+func (f *EndpointFailover) checkPreferredEndpoints(ctx context.Context) {
+	active, _ := f.getActive()
+
+	for i := 0; i < active; i++ {
+		healthy := f.isHealthy(ctx, f.endpoints[i])
+
+		f.mu.Lock()
+		if healthy {
+			f.recoveries[i]++
+		} else {
+			f.recoveries[i] = 0
+		}
+		recovered := f.recoveries[i] >= f.failureThreshold
+		f.mu.Unlock()
+
+		if recovered {
+			f.switchTo(active, i, "EndpointRecovered")
+
+			return
+		}
+	}
+}
+
+// isHealthy returns whether the endpoint answers, any response is accepted since the health checks are not
+// authenticated
+func (f *EndpointFailover) isHealthy(ctx context.Context, endpoint failoverEndpoint) bool {
+	ctx, cancel := context.WithTimeout(ctx, DefaultEndpointHealthCheckTimeout)
+	defer cancel()
+
+	u := *endpoint.url
+	u.Path = "/readyz"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := endpoint.transport.RoundTrip(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+
+	return true
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+// endpointServer is an API server endpoint which drops every connection while it is down
+type endpointServer struct {
+	*httptest.Server
+
+	down   int32
+	served int32
+	token  atomic.Value
+}
+
+func newEndpointServer(t *testing.T) *endpointServer {
+	t.Helper()
+
+	s := &endpointServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&s.down) == 1 {
+			if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+				conn.Close()
+			}
+
+			return
+		}
+
+		if r.URL.Path != "/readyz" {
+			atomic.AddInt32(&s.served, 1)
+			s.token.Store(r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *endpointServer) setDown(down bool) {
+	value := int32(0)
+	if down {
+		value = 1
+	}
+	atomic.StoreInt32(&s.down, value)
+}
+
+type endpointChange struct {
+	previous, current, reason string
+}
+
+func newFailoverClient(t *testing.T, opts []clusters.EndpointFailoverOption, servers ...*endpointServer) (*clusters.EndpointFailover, *http.Client) {
+	t.Helper()
+
+	configs := make([]*rest.Config, 0, len(servers))
+	for _, server := range servers {
+		configs = append(configs, &rest.Config{Host: server.URL, BearerToken: "token"})
+	}
+
+	failover, config, err := clusters.NewEndpointFailover(configs, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(failover.Stop)
+
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return failover, &http.Client{Transport: transport, Timeout: time.Second * 5}
+}
+
+func request(client *http.Client, url string) error {
+	resp, err := client.Get(url + "/version")
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func TestEndpointFailover(t *testing.T) {
+	t.Parallel()
+
+	primary := newEndpointServer(t)
+	secondary := newEndpointServer(t)
+
+	var changes []endpointChange
+	var changesMu sync.Mutex
+	failover, client := newFailoverClient(t, []clusters.EndpointFailoverOption{
+		clusters.WithEndpointFailureThreshold(3),
+		clusters.WithEndpointHealthCheckInterval(time.Millisecond * 10),
+		clusters.WithOnEndpointChangeFunc(func(previous, current, reason string) {
+			changesMu.Lock()
+			defer changesMu.Unlock()
+			changes = append(changes, endpointChange{previous, current, reason})
+		}),
+	}, primary, secondary)
+
+	if err := request(client, primary.URL); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&primary.served) != 1 || failover.Active() != primary.URL {
+		t.Fatal("the requests must be sent to the primary endpoint")
+	}
+	if token, _ := primary.token.Load().(string); token != "Bearer token" {
+		t.Fatalf("the requests must be authenticated, got: %q", token)
+	}
+
+	primary.setDown(true)
+	for i := 0; i < 3; i++ {
+		if failover.Active() != primary.URL {
+			t.Fatalf("the endpoint must not be switched after %d failures", i)
+		}
+		if err := request(client, primary.URL); err == nil {
+			t.Fatal("the request to the failed endpoint must fail")
+		}
+	}
+	if failover.Active() != secondary.URL {
+		t.Fatalf("the endpoint must be switched after 3 failures, active: %s", failover.Active())
+	}
+
+	// the requests are sent to the active endpoint regardless of the host of the config
+	if err := request(client, primary.URL); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&secondary.served) != 1 {
+		t.Fatal("the requests must be sent to the secondary endpoint")
+	}
+
+	primary.setDown(false)
+	err := wait.PollImmediate(time.Millisecond*10, time.Second*5, func() (bool, error) {
+		return failover.Active() == primary.URL, nil
+	})
+	if err != nil {
+		t.Fatal("the failover must switch back to the recovered primary endpoint")
+	}
+
+	changesMu.Lock()
+	defer changesMu.Unlock()
+	expected := []endpointChange{
+		{primary.URL, secondary.URL, "EndpointFailed"},
+		{secondary.URL, primary.URL, "EndpointRecovered"},
+	}
+	if len(changes) != len(expected) || changes[0] != expected[0] || changes[1] != expected[1] {
+		t.Fatalf("unexpected endpoint changes: %+v", changes)
+	}
+}
+
+func TestEndpointFailoverTransientErrors(t *testing.T) {
+	t.Parallel()
+
+	primary := newEndpointServer(t)
+	secondary := newEndpointServer(t)
+
+	failover, client := newFailoverClient(t, []clusters.EndpointFailoverOption{
+		clusters.WithEndpointFailureThreshold(3),
+	}, primary, secondary)
+
+	for round := 0; round < 3; round++ {
+		primary.setDown(true)
+		for i := 0; i < 2; i++ {
+			_ = request(client, primary.URL)
+		}
+		primary.setDown(false)
+		if err := request(client, primary.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if failover.Active() != primary.URL {
+		t.Fatal("errors which are not consecutive must not switch the endpoint")
+	}
+	if atomic.LoadInt32(&secondary.served) != 0 {
+		t.Fatal("no request must be sent to the secondary endpoint")
+	}
+}
+
+func TestEndpointFailoverSingleEndpoint(t *testing.T) {
+	t.Parallel()
+
+	server := newEndpointServer(t)
+	failover, client := newFailoverClient(t, nil, server)
+
+	server.setDown(true)
+	for i := 0; i < clusters.DefaultEndpointFailureThreshold*2; i++ {
+		_ = request(client, server.URL)
+	}
+
+	if failover.Active() != server.URL || len(failover.Endpoints()) != 1 {
+		t.Fatal("a single endpoint must stay active")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// gateRequeueDelay is the delay after which a reconcile refused by the gate is retried, unless the controller stops
+const gateRequeueDelay = time.Second
+
+// ReconcileGate admits the reconciles of the controllers, e.g. to let the running reconciles return before the
+// controller shuts down while no new ones are started
+type ReconcileGate interface {
+	// Enter returns false if the reconcile must not run, otherwise the returned function must be called once the
+	// reconcile returned
+	Enter() (func(), bool)
+	// AddOnDrainedFunc registers a function called once no admitted reconcile is running anymore after the gate
+	// closed, e.g. to write the batched status updates
+	AddOnDrainedFunc(f func(ctx context.Context))
+}
+
+type gatedReconciler struct {
+	reconcile.Reconciler
+
+	gate ReconcileGate
+}
+
+// NewGatedReconciler runs the reconciles of the reconciler admitted by the gate, the refused ones are requeued.
+// The reconciler is returned as it is without a gate.
+func NewGatedReconciler(r reconcile.Reconciler, gate ReconcileGate) reconcile.Reconciler {
+	if gate == nil {
+		return r
+	}
+
+	return &gatedReconciler{
+		Reconciler: r,
+		gate:       gate,
+	}
+}
+
+func (r *gatedReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	exit, ok := r.gate.Enter()
+	if !ok {
+		return reconcile.Result{RequeueAfter: gateRequeueDelay}, nil
+	}
+	defer exit()
+
+	return r.Reconciler.Reconcile(ctx, req)
+}
+
+// WithReconcileGate makes every controller of the clusters delivered by the providers run its reconciles through
+// the gate, the controllers of the local cluster can use it through GetReconcileGate
+func WithReconcileGate(gate ReconcileGate) ManagerOption {
+	return func(m *Manager) {
+		m.reconcileGate = gate
+		m.clusterOptions = append(m.clusterOptions, withReconcileGate(gate))
+	}
+}
+
+func withReconcileGate(gate ReconcileGate) Option {
+	return func(c *Cluster) {
+		c.reconcileGate = gate
+	}
+}
+
+// GetReconcileGate returns the gate of the reconciles, it is nil if the reconciles are not gated
+func (m *Manager) GetReconcileGate() ReconcileGate {
+	return m.reconcileGate
+}
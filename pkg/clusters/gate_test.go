@@ -0,0 +1,57 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+	"github.com/cisco-open/cluster-registry-controller/pkg/shutdown"
+)
+
+func TestGatedReconciler(t *testing.T) {
+	t.Parallel()
+
+	drainer := shutdown.NewDrainer()
+
+	var calls int
+	r := clusters.NewGatedReconciler(reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		calls++
+		if drainer.InFlight() != 1 {
+			t.Errorf("expected the reconcile to be counted as running, got %d", drainer.InFlight())
+		}
+
+		return reconcile.Result{}, nil
+	}), drainer)
+
+	result, err := r.Reconcile(context.Background(), reconcile.Request{})
+	if err != nil || result.RequeueAfter != 0 || calls != 1 {
+		t.Fatalf("unexpected result %+v, error %v, calls %d", result, err, calls)
+	}
+	if drainer.InFlight() != 0 {
+		t.Fatalf("reconcile still counted as running")
+	}
+
+	drainer.Drain(time.Second)
+
+	result, err = r.Reconcile(context.Background(), reconcile.Request{})
+	if err != nil || result.RequeueAfter == 0 || calls != 1 {
+		t.Fatalf("refused reconcile should be requeued without running, got %+v, error %v, calls %d", result, err, calls)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// DefaultUnreachableThreshold is the default time since the last contact with a cluster after which it is
+// considered unreachable by the health checks
+const DefaultUnreachableThreshold = time.Minute * 2
+
+var (
+	ErrManagerStopped        = errors.New("cluster manager is stopped")
+	ErrClusterCacheNotSynced = errors.New("cluster cache is not synced")
+	ErrClusterUnreachable    = errors.New("cluster is unreachable")
+)
+
+// HealthConfig configures how the state of the clusters is reflected in the readiness of the controller
+type HealthConfig struct {
+	// UnreachableThreshold is the time since the last successful probe of a cluster after which it is considered
+	// unreachable
+	UnreachableThreshold time.Duration
+	// FailOnUnreachable makes the readiness check fail while any cluster is unreachable, otherwise the unreachable
+	// clusters are left out of the check
+	FailOnUnreachable bool
+}
+
+// WithDefaults returns the config with the unset fields set to their default values
+func (c HealthConfig) WithDefaults() HealthConfig {
+	if c.UnreachableThreshold <= 0 {
+		c.UnreachableThreshold = DefaultUnreachableThreshold
+	}
+
+	return c
+}
+
+// ClusterHealth is the state of a cluster the health checks are based on
+type ClusterHealth struct {
+	Name string
+	// Reachable shows whether the last probe of the cluster succeeded
+	Reachable bool
+	// CacheSynced shows whether the cache of the running manager of the cluster is synced
+	CacheSynced bool
+	// LastContactTime is the time of the last successful probe of the cluster
+	LastContactTime time.Time
+	// Unreachable shows whether the cluster was not contacted for longer than the unreachable threshold
+	Unreachable bool
+}
+
+// GetClusterHealth returns the health of every cluster sorted by their names
+func (m *Manager) GetClusterHealth(threshold time.Duration, now time.Time) []ClusterHealth {
+	all := m.GetAll()
+
+	health := make([]ClusterHealth, 0, len(all))
+	for _, c := range all {
+		health = append(health, c.getHealth(threshold, now))
+	}
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].Name < health[j].Name
+	})
+
+	return health
+}
+
+func (c *Cluster) getHealth(threshold time.Duration, now time.Time) ClusterHealth {
+	status := c.GetProbeStatus()
+
+	// a cluster never contacted is measured from the time it was first probed
+	since := status.LastContactTime
+	if since.IsZero() {
+		since = status.LastTransitionTime
+	}
+
+	return ClusterHealth{
+		Name:            c.GetName(),
+		Reachable:       status.Reachable,
+		CacheSynced:     c.IsCacheSynced(),
+		LastContactTime: status.LastContactTime,
+		Unreachable:     !status.Reachable && !since.IsZero() && now.Sub(since) >= threshold,
+	}
+}
+
+// HealthzCheck fails once the cluster manager is stopped
+func (m *Manager) HealthzCheck(_ *http.Request) error {
+	select {
+	case <-m.Stopped():
+		return errors.WithStack(ErrManagerStopped)
+	default:
+		return nil
+	}
+}
+
+// ReadyzCheck returns a check which fails while the cache of an alive cluster is not synced, and while a cluster is
+// unreachable if the config says so
+func (m *Manager) ReadyzCheck(config HealthConfig) healthz.Checker {
+	config = config.WithDefaults()
+
+	return func(_ *http.Request) error {
+		notSynced := []string{}
+		unreachable := []string{}
+		for _, health := range m.GetClusterHealth(config.UnreachableThreshold, time.Now()) {
+			switch {
+			case health.Unreachable:
+				if config.FailOnUnreachable {
+					unreachable = append(unreachable, fmt.Sprintf("%s (last contact: %s)", health.Name, formatContactTime(health.LastContactTime)))
+				}
+			// the managers of the clusters are only running while they are reachable
+			case health.Reachable && !health.CacheSynced:
+				notSynced = append(notSynced, health.Name)
+			}
+		}
+
+		if len(unreachable) > 0 {
+			return errors.WithStack(fmt.Errorf("%w: %s", ErrClusterUnreachable, strings.Join(unreachable, ", ")))
+		}
+		if len(notSynced) > 0 {
+			return errors.WithStack(fmt.Errorf("%w: %s", ErrClusterCacheNotSynced, strings.Join(notSynced, ", ")))
+		}
+
+		return nil
+	}
+}
+
+func formatContactTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+func TestReadyzCheck(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var failing int32
+	opts := append(fakeClusterOptions(),
+		clusters.WithLivenessCheckFunc(func(ctx context.Context, c *clusters.Cluster) (string, error) {
+			if atomic.LoadInt32(&failing) == 1 {
+				return "", errors.New("connection refused")
+			}
+
+			return "id-" + c.GetName(), nil
+		}),
+		clusters.WithProbeConfig(clusters.ProbeConfig{
+			Interval: time.Millisecond * 10,
+		}),
+	)
+	mgr := clusters.NewManager(ctx, clusters.WithClusterOptions(opts...))
+
+	provider := clusters.NewInMemoryProvider("memory")
+	if err := mgr.AddProvider(provider); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.Set(fakeClusterConfig("test", "https://test.example.com")); err != nil {
+		t.Fatal(err)
+	}
+
+	config := clusters.HealthConfig{UnreachableThreshold: time.Millisecond * 100}
+	check := mgr.ReadyzCheck(config)
+	config.FailOnUnreachable = true
+	strictCheck := mgr.ReadyzCheck(config)
+
+	// eventually returns once the check returns the expected error
+	eventually := func(check func() error, target error) {
+		timeout := time.After(time.Second * 5)
+		for {
+			err := check()
+			if (target == nil && err == nil) || (target != nil && errors.Is(err, target)) {
+				return
+			}
+			select {
+			case <-timeout:
+				t.Fatalf("expected %v, got %v", target, err)
+			case <-time.After(time.Millisecond * 10):
+			}
+		}
+	}
+
+	// the manager of the fake cluster can not be started, so its cache is never synced
+	eventually(func() error { return check(nil) }, clusters.ErrClusterCacheNotSynced)
+	if err := mgr.HealthzCheck(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	atomic.StoreInt32(&failing, 1)
+	eventually(func() error { return strictCheck(nil) }, clusters.ErrClusterUnreachable)
+	if err := check(nil); err != nil {
+		t.Fatalf("unreachable cluster fails the readiness: %v", err)
+	}
+	health := mgr.GetClusterHealth(config.UnreachableThreshold, time.Now())
+	if len(health) != 1 || !health[0].Unreachable || health[0].LastContactTime.IsZero() {
+		t.Fatalf("unexpected cluster health: %+v", health)
+	}
+
+	atomic.StoreInt32(&failing, 0)
+	eventually(func() error { return strictCheck(nil) }, clusters.ErrClusterCacheNotSynced)
+}
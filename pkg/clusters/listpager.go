@@ -0,0 +1,189 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// DefaultListPageSize is the default number of objects the informer caches list in a page
+const DefaultListPageSize = 500
+
+// ListPager makes the informer caches list the objects in pages of a limited size, and counts the listed objects as
+// the pages arrive, so the warm-up of the caches of very large kinds can be followed.
+//
+// The reflectors of this client-go version send their initial list with resource version 0, which is served from the
+// watch cache of the API server in a single response holding every object of the kind, the limit set by them is
+// ignored. The first page of the lists sent through the transport of the pager is requested without the resource
+// version instead, so the API server reads it from etcd and returns the objects page by page. Only the requests which
+// are paginated by the client anyway are changed, e.g. the full lists falling back from an expired continue token are
+// passed through.
+type ListPager struct {
+	pageSize int64
+
+	mu       sync.Mutex
+	progress map[schema.GroupResource]ListProgress
+}
+
+// ListProgress is the state of the last list of a resource sent through the transport of a pager
+type ListProgress struct {
+	// Listed is the number of objects listed so far
+	Listed int64
+	// Done is set once the last page of the list is received
+	Done bool
+}
+
+func NewListPager(pageSize int64) *ListPager {
+	return &ListPager{
+		pageSize: pageSize,
+		progress: make(map[schema.GroupResource]ListProgress),
+	}
+}
+
+// NewCacheFunc returns the function creating the caches with newCache, whose list requests are paged. The progress of
+// the lists of the previous cache is reset when a new one is created.
+func (p *ListPager) NewCacheFunc(newCache cache.NewCacheFunc) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		p.mu.Lock()
+		p.progress = make(map[schema.GroupResource]ListProgress)
+		p.mu.Unlock()
+
+		config = rest.CopyConfig(config)
+		config.Wrap(p.WrapTransport)
+
+		return newCache(config, opts)
+	}
+}
+
+// WrapTransport pages the list requests sent through the round tripper and counts the objects of their responses
+func (p *ListPager) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &listPagerRoundTripper{
+		pager: p,
+		next:  rt,
+	}
+}
+
+// Progress returns the state of the last list of the resource, the zero value if it has not been listed yet
+func (p *ListPager) Progress(resource schema.GroupResource) ListProgress {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.progress[resource]
+}
+
+func (p *ListPager) observePage(resource schema.GroupResource, first bool, items int64, done bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	progress := p.progress[resource]
+	if first {
+		progress = ListProgress{}
+	}
+	progress.Listed += items
+	progress.Done = done
+	p.progress[resource] = progress
+}
+
+type listPagerRoundTripper struct {
+	pager *ListPager
+	next  http.RoundTripper
+}
+
+func (rt *listPagerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	query := req.URL.Query()
+	resource, ok := getListedResource(req.URL.Path)
+	if !ok || req.Method != http.MethodGet || query.Get("watch") == "true" || query.Get("watch") == "1" || query.Get("limit") == "" {
+		return rt.next.RoundTrip(req)
+	}
+
+	first := query.Get("continue") == ""
+	query.Set("limit", strconv.FormatInt(rt.pager.pageSize, 10))
+	if first && query.Get("resourceVersion") == "0" {
+		query.Del("resourceVersion")
+		query.Del("resourceVersionMatch")
+	}
+
+	req = req.Clone(req.Context())
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	// only the objects of JSON responses are counted, e.g. protobuf encoded ones are passed through
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return resp, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not read response")
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	var page struct {
+		Metadata struct {
+			Continue string `json:"continue"`
+		} `json:"metadata"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, errors.WrapIf(err, "could not decode list")
+	}
+
+	rt.pager.observePage(resource, first, int64(len(page.Items)), page.Metadata.Continue == "")
+
+	return resp, nil
+}
+
+// getListedResource returns the resource listed by the path of a request, it returns false if the path is not the
+// path of a list, e.g. it is the path of a single object
+func getListedResource(path string) (schema.GroupResource, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	var group string
+	switch {
+	case len(parts) > 2 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) > 3 && parts[0] == "apis":
+		group = parts[1]
+		parts = parts[3:]
+	default:
+		return schema.GroupResource{}, false
+	}
+
+	switch {
+	case len(parts) == 1:
+		return schema.GroupResource{Group: group, Resource: parts[0]}, true
+	case len(parts) == 3 && parts[0] == "namespaces":
+		return schema.GroupResource{Group: group, Resource: parts[2]}, true
+	default:
+		return schema.GroupResource{}, false
+	}
+}
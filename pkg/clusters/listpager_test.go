@@ -0,0 +1,192 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/pager"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+// newPaginatedListServer returns a fake API server listing the ConfigMaps in pages of the requested size, the
+// continue token is the index of the first object of the next page
+func newPaginatedListServer(t *testing.T, count int, queries *[]url.Values) *httptest.Server {
+	t.Helper()
+
+	var mu sync.Mutex
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		mu.Lock()
+		*queries = append(*queries, query)
+		mu.Unlock()
+
+		start, _ := strconv.Atoi(query.Get("continue"))
+		end := count
+		if limit, _ := strconv.Atoi(query.Get("limit")); limit > 0 && start+limit < count {
+			end = start + limit
+		}
+
+		list := corev1.ConfigMapList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMapList"},
+			ListMeta: metav1.ListMeta{ResourceVersion: "100"},
+		}
+		// the watch cache of the API server ignores the limit of the lists at resource version 0
+		if query.Get("resourceVersion") == "0" {
+			end = count
+		}
+		if end < count {
+			list.Continue = strconv.Itoa(end)
+		}
+		for i := start; i < end; i++ {
+			list.Items = append(list.Items, corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("test-%d", i), Namespace: "default"},
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(list)
+	}))
+}
+
+func TestListPager(t *testing.T) {
+	t.Parallel()
+
+	queries := make([]url.Values, 0)
+	server := newPaginatedListServer(t, 5, &queries)
+	defer server.Close()
+
+	listPager := clusters.NewListPager(2)
+	config := &rest.Config{Host: server.URL}
+	config.Wrap(listPager.WrapTransport)
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the initial list of the reflectors
+	p := pager.New(pager.SimplePageFunc(func(opts metav1.ListOptions) (runtime.Object, error) {
+		return clientset.CoreV1().ConfigMaps("").List(context.Background(), opts)
+	}))
+	list, paginated, err := p.List(context.Background(), metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 5 {
+		t.Fatalf("expected 5 listed objects, got %d", len(items))
+	}
+	if !paginated {
+		t.Fatal("expected the list to be paginated")
+	}
+	if len(queries) != 3 {
+		t.Fatalf("expected 3 pages, got %v", queries)
+	}
+	for i, query := range queries {
+		if query.Get("limit") != "2" {
+			t.Fatalf("expected the page size of the pager, got %v", query)
+		}
+		if query.Get("resourceVersion") == "0" {
+			t.Fatalf("expected the list not to be served from the watch cache, got %v", query)
+		}
+		if (i == 0) != (query.Get("continue") == "") {
+			t.Fatalf("expected the continue token of the previous page, got %v", query)
+		}
+	}
+
+	progress := listPager.Progress(schema.GroupResource{Resource: "configmaps"})
+	if progress.Listed != 5 || !progress.Done {
+		t.Fatalf("expected 5 objects listed in full, got %+v", progress)
+	}
+}
+
+func TestListPagerPassthrough(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		url  string
+	}{
+		{
+			name: "watch",
+			url:  "https://cluster/api/v1/configmaps?limit=500&resourceVersion=0&watch=true",
+		},
+		{
+			name: "full list",
+			url:  "https://cluster/api/v1/configmaps?resourceVersion=0",
+		},
+		{
+			name: "object",
+			url:  "https://cluster/apis/apps/v1/namespaces/default/deployments/test?limit=500&resourceVersion=0",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			expected := req.URL.RawQuery
+
+			transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if req.URL.RawQuery != expected {
+					t.Errorf("expected the query to be kept, got %s", req.URL.RawQuery)
+				}
+
+				rec := httptest.NewRecorder()
+				rec.Header().Set("Content-Type", "application/json")
+				rec.WriteHeader(http.StatusOK)
+				_, _ = rec.WriteString(`{"kind":"ConfigMapList","metadata":{},"items":[{}]}`)
+
+				return rec.Result(), nil
+			})
+
+			listPager := clusters.NewListPager(2)
+			resp, err := listPager.WrapTransport(transport).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			for _, resource := range []schema.GroupResource{{Resource: "configmaps"}, {Group: "apps", Resource: "deployments"}} {
+				if progress := listPager.Progress(resource); progress.Listed != 0 || progress.Done {
+					t.Fatalf("expected the objects not to be counted, got %+v", progress)
+				}
+			}
+		})
+	}
+}
@@ -16,11 +16,13 @@ package clusters
 
 import (
 	"context"
+	"sync"
 
 	"emperror.dev/errors"
 	"github.com/cenkalti/backoff"
 	"github.com/go-logr/logr"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -34,7 +36,12 @@ type ManagedController interface {
 	GetName() string
 	Stop()
 	Stopped() <-chan struct{}
+	// Done is closed once the controller is stopped and cleaned up, so it can be started again
+	Done() <-chan struct{}
 	Start(ctx context.Context, mgr ctrl.Manager) error
+	// StartError returns the error of the last failed attempt to start the controller, it is nil once the
+	// controller is started
+	StartError() error
 	GetRequiredClusterFeatures() []ClusterFeatureRequirement
 	GetClient() client.Client
 }
@@ -49,10 +56,21 @@ type managedController struct {
 	ctrl              controller.Controller
 	ctrlContext       context.Context
 	ctrlContextCancel context.CancelFunc
+	done              chan struct{}
 	client            client.Client
 	cache             cache.Cache
 
 	requiredClusterFeatures []ClusterFeatureRequirement
+	maxConcurrentReconciles int
+	rateLimiter             workqueue.RateLimiter
+	newCache                cache.NewCacheFunc
+	// reconcileGate is set by the cluster the controller is added to
+	reconcileGate ReconcileGate
+	// circuitBreaker is set by the cluster the controller is added to
+	circuitBreaker *CircuitBreaker
+
+	startErr   error
+	startErrMu sync.RWMutex
 }
 
 type ManagedControllerOption func(*managedController)
@@ -66,12 +84,33 @@ func WithRequiredClusterFeatures(features ...ClusterFeatureRequirement) ManagedC
 	}
 }
 
+func WithMaxConcurrentReconciles(count int) ManagedControllerOption {
+	return func(r *managedController) {
+		r.maxConcurrentReconciles = count
+	}
+}
+
+// WithWorkqueueRateLimiter sets the rate limiter of the work queue, which controls the backoff of the failed reconciles
+func WithWorkqueueRateLimiter(rateLimiter workqueue.RateLimiter) ManagedControllerOption {
+	return func(r *managedController) {
+		r.rateLimiter = rateLimiter
+	}
+}
+
+// WithNewCacheFunc sets the function creating the cache of the controller, e.g. to transform the cached objects
+func WithNewCacheFunc(newCache cache.NewCacheFunc) ManagedControllerOption {
+	return func(r *managedController) {
+		r.newCache = newCache
+	}
+}
+
 func NewManagedController(name string, r ManagedReconciler, l logr.Logger, options ...ManagedControllerOption) ManagedController {
 	m := &managedController{
 		name:                    name,
 		reconciler:              r,
 		log:                     l.WithName(name),
 		requiredClusterFeatures: make([]ClusterFeatureRequirement, 0),
+		newCache:                cache.New,
 	}
 
 	for _, o := range options {
@@ -93,6 +132,28 @@ func (c *managedController) GetReconciler() ManagedReconciler {
 	return c.reconciler
 }
 
+func (c *managedController) setReconcileGate(gate ReconcileGate) {
+	c.reconcileGate = gate
+}
+
+func (c *managedController) setCircuitBreaker(breaker *CircuitBreaker) {
+	c.circuitBreaker = breaker
+}
+
+func (c *managedController) StartError() error {
+	c.startErrMu.RLock()
+	defer c.startErrMu.RUnlock()
+
+	return c.startErr
+}
+
+func (c *managedController) setStartError(err error) {
+	c.startErrMu.Lock()
+	defer c.startErrMu.Unlock()
+
+	c.startErr = err
+}
+
 func (c *managedController) SetLogger(l logr.Logger) {
 	c.log = l
 }
@@ -101,6 +162,17 @@ func (c *managedController) Stopped() <-chan struct{} {
 	return c.ctrlContext.Done()
 }
 
+func (c *managedController) Done() <-chan struct{} {
+	if c.done == nil {
+		done := make(chan struct{})
+		close(done)
+
+		return done
+	}
+
+	return c.done
+}
+
 func (c *managedController) Stop() {
 	if c.ctrlContextCancel != nil {
 		c.ctrlContextCancel()
@@ -114,24 +186,35 @@ func (c *managedController) Start(ctx context.Context, mgr ctrl.Manager) error {
 	}
 
 	c.ctrlContext, c.ctrlContextCancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
 	c.mgr = mgr
 
+	ctrlContext, done := c.ctrlContext, c.done
+
 	c.reconciler.SetManager(c.mgr)
 	c.reconciler.SetLogger(c.log)
 
 	var err error
 
 	check := func() error {
-		err = c.reconciler.PreCheck(c.ctrlContext, c.mgr.GetClient())
+		err = c.reconciler.PreCheck(ctrlContext, c.mgr.GetClient())
 		if err != nil {
-			return errors.WrapIf(err, "pre check error")
+			err = errors.WrapIf(err, "pre check error")
+			c.setStartError(err)
+
+			return err
 		}
 
-		err = c.start()
+		err = c.start(ctrlContext, done)
 		if err != nil {
-			return errors.WrapIf(err, "could not start controller")
+			err = errors.WrapIf(err, "could not start controller")
+			c.setStartError(err)
+
+			return err
 		}
 
+		c.setStartError(nil)
+
 		return nil
 	}
 
@@ -142,7 +225,10 @@ func (c *managedController) Start(ctx context.Context, mgr ctrl.Manager) error {
 		} else {
 			return
 		}
-		ticker := backoff.NewTicker(backoff.NewExponentialBackOff())
+		// the checks are retried until they pass, e.g. the missing permissions of the rule are granted
+		b := backoff.NewExponentialBackOff()
+		b.MaxElapsedTime = 0
+		ticker := backoff.NewTicker(b)
 		for {
 			select {
 			case <-ticker.C:
@@ -152,7 +238,10 @@ func (c *managedController) Start(ctx context.Context, mgr ctrl.Manager) error {
 				} else {
 					return
 				}
-			case <-c.ctrlContext.Done():
+			case <-ctrlContext.Done():
+				// the controller was stopped before it could be started
+				c.finish(ctrlContext, done)
+
 				return
 			}
 		}
@@ -183,12 +272,12 @@ func (c *managedController) createClient(config *rest.Config, options client.Opt
 	return cli, nil
 }
 
-func (c *managedController) createAndStartCache() (cache.Cache, error) {
-	if c.ctrlContext == nil {
+func (c *managedController) createAndStartCache(ctrlContext context.Context) (cache.Cache, error) {
+	if ctrlContext == nil {
 		return nil, errors.New("context is nil")
 	}
 
-	cche, err := cache.New(c.mgr.GetConfig(), cache.Options{
+	cche, err := c.newCache(c.mgr.GetConfig(), cache.Options{
 		Scheme: c.mgr.GetScheme(),
 		Mapper: c.mgr.GetRESTMapper(),
 	})
@@ -197,14 +286,14 @@ func (c *managedController) createAndStartCache() (cache.Cache, error) {
 	}
 
 	go func() {
-		err = cche.Start(c.ctrlContext)
+		err = cche.Start(ctrlContext)
 		if err != nil {
 			c.log.Error(err, "could not start cache")
 		}
 		c.log.Info("cache stopped")
 	}()
 
-	if !cche.WaitForCacheSync(c.ctrlContext) {
+	if !cche.WaitForCacheSync(ctrlContext) {
 		return nil, errors.New("could not sync cache")
 	}
 
@@ -241,18 +330,20 @@ func (c *managedController) getInjectFunc() inject.Func {
 	}
 }
 
-func (c *managedController) start() error {
+func (c *managedController) start(ctrlContext context.Context, done chan struct{}) error {
 	var err error
 
 	c.ctrl, err = controller.NewUnmanaged(c.GetName(), c.mgr, controller.Options{
-		Reconciler: c.reconciler,
-		Log:        c.log,
+		Reconciler:              NewGatedReconciler(NewCircuitBreakerReconciler(c.reconciler, c.circuitBreaker), c.reconcileGate),
+		Log:                     c.log,
+		MaxConcurrentReconciles: c.maxConcurrentReconciles,
+		RateLimiter:             c.rateLimiter,
 	})
 	if err != nil {
 		return errors.WithStackIf(err)
 	}
 
-	cache, err := c.createAndStartCache()
+	cache, err := c.createAndStartCache(ctrlContext)
 	if err != nil {
 		return errors.WithStackIf(err)
 	}
@@ -278,18 +369,25 @@ func (c *managedController) start() error {
 
 	c.reconciler.SetClient(c.client)
 
-	err = c.reconciler.SetupWithController(c.ctrlContext, c.ctrl)
+	err = c.reconciler.SetupWithController(ctrlContext, c.ctrl)
 	if err != nil {
 		return errors.WithStackIf(err)
 	}
 
 	// Start our controller in a goroutine so that we do not block.
 	go func() {
+		// the reconciler is cleaned up once the controller is stopped, even if it could not be started
+		defer func() {
+			<-ctrlContext.Done()
+			c.reconciler.DoCleanup()
+			c.finish(ctrlContext, done)
+		}()
+
 		// Block until our controller manager is elected leader. We presume our entire
 		// process will terminate if we lose leadership, so we don't need to handle that.
 		<-c.mgr.Elected()
 
-		started := c.mgr.GetCache().WaitForCacheSync(c.ctrlContext)
+		started := c.mgr.GetCache().WaitForCacheSync(ctrlContext)
 		if !started {
 			c.log.Error(err, "timeout while waiting for cache sync")
 
@@ -300,23 +398,31 @@ func (c *managedController) start() error {
 		// controller returns an error.
 		c.log.Info("starting ctrl")
 
-		err = c.reconciler.Start(c.ctrlContext)
+		err = c.reconciler.Start(ctrlContext)
 		if err != nil {
 			c.log.Error(err, "")
+			c.setStartError(errors.WrapIf(err, "could not start reconciler"))
 
 			return
 		}
 
-		if err := c.ctrl.Start(c.ctrlContext); err != nil {
+		if err := c.ctrl.Start(ctrlContext); err != nil {
 			c.log.Error(err, "cannot run sync controller")
+			c.setStartError(errors.WrapIf(err, "could not run controller"))
 		}
 		c.log.Info("ctrl stopped")
-		<-c.ctrlContext.Done()
-		c.reconciler.DoCleanup()
+	}()
+
+	return nil
+}
+
+// finish releases the controller stopped with the context, unless it was already started again
+func (c *managedController) finish(ctrlContext context.Context, done chan struct{}) {
+	if c.ctrlContext == ctrlContext {
 		c.ctrlContextCancel = nil
 		c.ctrlContext = nil
 		c.ctrl = nil
-	}()
+	}
 
-	return nil
+	close(done)
 }
@@ -19,19 +19,52 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	"github.com/go-logr/logr"
 )
 
 var (
 	ErrClusterNotFound    = errors.New("cluster not found")
 	ErrControllerNotFound = errors.New("controller not found")
+	ErrInvalidCredentials = errors.New("invalid cluster credentials")
+	ErrClusterNotOwned    = errors.New("cluster is assigned to another controller replica")
 )
 
 type ManagerOption func(m *Manager)
 
+// ClusterLister is the read only view of the clusters of every provider, used by the sync reconcilers
+type ClusterLister interface {
+	Get(name string) (*Cluster, error)
+	GetAll() map[string]*Cluster
+	GetAliveClustersByID() map[string]*Cluster
+	GetProbeStatusByID(clusterID string) (ProbeStatus, bool)
+}
+
+// Sharder assigns the clusters to the replicas of the controller, see WithSharder
+type Sharder interface {
+	// Owns returns whether the cluster with the name is assigned to the replica
+	Owns(key string) bool
+	// AddOnChangeFunc registers a function called after the assignment changed
+	AddOnChangeFunc(f func())
+}
+
 type Manager struct {
 	clusters map[string]*Cluster
 	mu       *sync.RWMutex
 	ctx      context.Context
+	log      logr.Logger
+
+	// providers deliver the clusters, clusterProviders contains the name of the provider of each cluster
+	providers        map[string]context.CancelFunc
+	clusterProviders map[string]string
+	clusterOptions   []Option
+
+	// sharder restricts the clusters to the ones assigned to the replica, every cluster is managed without it
+	sharder            Sharder
+	onShardChangeFuncs map[string]func()
+
+	// reconcileGate admits the reconciles of the controllers, see WithReconcileGate
+	reconcileGate ReconcileGate
 
 	onBeforeAddFuncs    map[string]func(c *Cluster)
 	onBeforeDeleteFuncs map[string]func(c *Cluster)
@@ -39,6 +72,13 @@ type Manager struct {
 	onAfterDeleteFuncs  map[string]func()
 }
 
+// WithClusterOptions sets the options applied to every cluster delivered by a provider
+func WithClusterOptions(opts ...Option) ManagerOption {
+	return func(m *Manager) {
+		m.clusterOptions = append(m.clusterOptions, opts...)
+	}
+}
+
 func WithOnBeforeAddFunc(f func(c *Cluster), ids ...string) ManagerOption {
 	return func(m *Manager) {
 		m.AddOnBeforeAddFunc(f, ids...)
@@ -63,17 +103,33 @@ func WithOnAfterDeleteFunc(f func(), ids ...string) ManagerOption {
 	}
 }
 
+// WithSharder makes the manager only run the clusters assigned to the replica, the clusters which are assigned to
+// another replica are stopped and removed once the assignment changes
+func WithSharder(sharder Sharder) ManagerOption {
+	return func(m *Manager) {
+		m.sharder = sharder
+	}
+}
+
 func NewManager(ctx context.Context, options ...ManagerOption) *Manager {
 	mgr := &Manager{
 		clusters: make(map[string]*Cluster),
 		mu:       &sync.RWMutex{},
 		ctx:      ctx,
+		log:      logr.Discard(),
+
+		providers:        make(map[string]context.CancelFunc),
+		clusterProviders: make(map[string]string),
 	}
 
 	for _, opt := range options {
 		opt(mgr)
 	}
 
+	if mgr.sharder != nil {
+		mgr.sharder.AddOnChangeFunc(mgr.reshard)
+	}
+
 	go mgr.waitForStop(ctx)
 
 	return mgr
@@ -164,6 +220,70 @@ func (m *Manager) DeleteOnAfterDeleteFunc(id string) {
 	delete(m.onAfterDeleteFuncs, id)
 }
 
+// AddOnShardChangeFunc registers a function called after the clusters which are not assigned to the replica anymore
+// were removed, e.g. to add the clusters which are newly assigned to it
+func (m *Manager) AddOnShardChangeFunc(f func(), ids ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.onShardChangeFuncs == nil {
+		m.onShardChangeFuncs = make(map[string]func())
+	}
+	id := time.Now().String()
+	if len(ids) > 0 {
+		id = ids[0]
+	}
+	m.onShardChangeFuncs[id] = f
+}
+
+func (m *Manager) DeleteOnShardChangeFunc(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.onShardChangeFuncs, id)
+}
+
+// IsSharded returns whether the clusters are distributed across the replicas of the controller
+func (m *Manager) IsSharded() bool {
+	return m.sharder != nil
+}
+
+// Owns returns whether the cluster is assigned to the replica, every cluster is without a sharder
+func (m *Manager) Owns(name string) bool {
+	if m.sharder == nil {
+		return true
+	}
+
+	return m.sharder.Owns(name)
+}
+
+// reshard stops the clusters assigned to other replicas, the clusters which stay assigned keep running untouched
+func (m *Manager) reshard() {
+	m.mu.RLock()
+	moved := make([]*Cluster, 0)
+	for name, cluster := range m.clusters {
+		if !m.sharder.Owns(name) {
+			moved = append(moved, cluster)
+		}
+	}
+	funcs := make([]func(), 0, len(m.onShardChangeFuncs))
+	for _, f := range m.onShardChangeFuncs {
+		funcs = append(funcs, f)
+	}
+	m.mu.RUnlock()
+
+	for _, cluster := range moved {
+		m.log.Info("cluster is assigned to another replica", "cluster", cluster.GetName())
+		if err := m.Remove(cluster); err != nil {
+			m.log.Error(err, "could not remove cluster", "cluster", cluster.GetName())
+		}
+	}
+
+	for _, f := range funcs {
+		f()
+	}
+}
+
 func (m *Manager) Exists(name string) bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -205,15 +325,110 @@ func (m *Manager) GetAliveClustersByID() map[string]*Cluster {
 	return clusters
 }
 
+// GetProbeStatusByID returns the probe status of the cluster with the ID, regardless of whether it is alive.
+// The ID of a cluster is only known after it was reachable at least once.
+func (m *Manager) GetProbeStatusByID(clusterID string) (ProbeStatus, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, cluster := range m.clusters {
+		if cluster.GetClusterID() == clusterID {
+			return cluster.GetProbeStatus(), true
+		}
+	}
+
+	return ProbeStatus{}, false
+}
+
+// AddProvider starts the provider, its clusters are added to the manager until the provider is removed
+func (m *Manager) AddProvider(provider ClusterProvider) error {
+	name := provider.GetName()
+
+	m.mu.Lock()
+	if _, ok := m.providers[name]; ok {
+		m.mu.Unlock()
+
+		return ErrProviderExists
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.providers[name] = cancel
+	m.mu.Unlock()
+
+	err := provider.Start(ctx, &providerCallbacks{
+		manager:  m,
+		provider: name,
+		ctx:      ctx,
+	})
+	if err != nil {
+		_ = m.RemoveProvider(name)
+
+		return err
+	}
+
+	return nil
+}
+
+// RemoveProvider stops the provider and removes its clusters, so another provider can take over them
+func (m *Manager) RemoveProvider(name string) error {
+	m.mu.Lock()
+	cancel, ok := m.providers[name]
+	if !ok {
+		m.mu.Unlock()
+
+		return ErrProviderNotFound
+	}
+	cancel()
+	delete(m.providers, name)
+
+	clusters := make([]*Cluster, 0)
+	for clusterName, provider := range m.clusterProviders {
+		if provider == name {
+			clusters = append(clusters, m.clusters[clusterName])
+		}
+	}
+	m.mu.Unlock()
+
+	for _, cluster := range clusters {
+		if err := m.Remove(cluster); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetProviderName returns the name of the provider of the cluster, it is empty for clusters added directly
+func (m *Manager) GetProviderName(cluster *Cluster) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.clusterProviders[cluster.GetName()]
+}
+
 func (m *Manager) Add(cluster *Cluster) error {
+	return m.add(cluster, "")
+}
+
+func (m *Manager) add(cluster *Cluster, provider string) error {
+	if !m.Owns(cluster.GetName()) {
+		return ErrClusterNotOwned
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if current := m.clusterProviders[cluster.GetName()]; m.clusters[cluster.GetName()] != nil && current != provider {
+		return ErrClusterOfOtherProvider
+	}
+
 	for _, f := range m.onBeforeAddFuncs {
 		f(cluster)
 	}
 
 	m.clusters[cluster.GetName()] = cluster
+	if provider != "" {
+		m.clusterProviders[cluster.GetName()] = provider
+	}
 
 	for _, f := range m.onAfterAddFuncs {
 		f(cluster)
@@ -241,6 +456,7 @@ func (m *Manager) Remove(cluster *Cluster) error {
 	cluster.Stop()
 
 	delete(m.clusters, cluster.GetName())
+	delete(m.clusterProviders, cluster.GetName())
 
 	for _, f := range m.onAfterDeleteFuncs {
 		f()
@@ -249,6 +465,17 @@ func (m *Manager) Remove(cluster *Cluster) error {
 	return nil
 }
 
+func (m *Manager) get(name string) (*Cluster, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if cluster, ok := m.clusters[name]; ok {
+		return cluster, m.clusterProviders[name], nil
+	}
+
+	return nil, "", ErrClusterNotFound
+}
+
 func (m *Manager) Stopped() <-chan struct{} {
 	return m.ctx.Done()
 }
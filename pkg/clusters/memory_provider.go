@@ -0,0 +1,103 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"context"
+	"sync"
+
+	"emperror.dev/errors"
+)
+
+// InMemoryProvider is an example cluster provider, which delivers the clusters set through its functions.
+// Clusters set before the provider is started are delivered when it starts.
+type InMemoryProvider struct {
+	name      string
+	configs   map[string]ClusterConfig
+	callbacks ClusterProviderCallbacks
+
+	mu sync.Mutex
+}
+
+func NewInMemoryProvider(name string) *InMemoryProvider {
+	return &InMemoryProvider{
+		name:    name,
+		configs: make(map[string]ClusterConfig),
+	}
+}
+
+func (p *InMemoryProvider) GetName() string {
+	return p.name
+}
+
+func (p *InMemoryProvider) Start(ctx context.Context, callbacks ClusterProviderCallbacks) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.callbacks = callbacks
+
+	var combinedErr error
+	for _, config := range p.configs {
+		if _, err := callbacks.Add(config); err != nil {
+			combinedErr = errors.Append(combinedErr, err)
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		p.callbacks = nil
+	}()
+
+	return combinedErr
+}
+
+// Set adds the cluster or updates it if it already exists
+func (p *InMemoryProvider) Set(config ClusterConfig) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, exists := p.configs[config.Name]
+	p.configs[config.Name] = config
+
+	if p.callbacks == nil {
+		return nil
+	}
+
+	var err error
+	if exists {
+		_, err = p.callbacks.Update(config)
+	} else {
+		_, err = p.callbacks.Add(config)
+	}
+
+	return err
+}
+
+func (p *InMemoryProvider) Delete(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.configs, name)
+
+	if p.callbacks == nil {
+		return nil
+	}
+
+	return p.callbacks.Remove(name)
+}
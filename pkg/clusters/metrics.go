@@ -0,0 +1,77 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	restMapperRefreshes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cluster_registry_rest_mapper_refreshes_total",
+			Help: "Number of discovery refreshes of the REST mapper of a cluster",
+		},
+		[]string{"cluster", "trigger", "result"},
+	)
+	restMapperRefreshDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "cluster_registry_rest_mapper_refresh_duration_seconds",
+			Help: "Time the successful discovery refreshes of the REST mapper of a cluster took",
+		},
+		[]string{"cluster"},
+	)
+	watchFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_registry_cluster_watch_consecutive_failures",
+			Help: "Number of consecutive failed list and watch requests of the informers of a resource of a cluster",
+		},
+		[]string{"cluster", "resource"},
+	)
+	watchDegraded = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_registry_cluster_watch_degraded",
+			Help: "Whether the watch of a resource of a cluster is degraded, because its list and watch requests keep failing",
+		},
+		[]string{"cluster", "resource"},
+	)
+	clientReceivedBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cluster_registry_cluster_client_received_bytes_total",
+			Help: "Number of bytes of the response bodies received by the clients of a cluster by their encoding",
+		},
+		[]string{"cluster", "encoding"},
+	)
+	circuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_registry_cluster_circuit_breaker_state",
+			Help: "State of the circuit breaker of a cluster, 1 for the current state",
+		},
+		[]string{"cluster", "state"},
+	)
+	circuitBreakerTrips = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cluster_registry_cluster_circuit_breaker_trips_total",
+			Help: "Number of times the circuit breaker of a cluster tripped because its API server was erroring heavily",
+		},
+		[]string{"cluster"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(restMapperRefreshes, restMapperRefreshDuration, watchFailures, watchDegraded, clientReceivedBytes,
+		circuitBreakerState, circuitBreakerTrips)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"time"
+)
+
+const (
+	DefaultProbeInterval         = time.Second * 5
+	DefaultProbeTimeout          = time.Second * 5
+	DefaultProbeFailureThreshold = 1
+)
+
+// ProbeConfig controls how the connectivity of a cluster is probed
+type ProbeConfig struct {
+	// Interval is the time between two probes
+	Interval time.Duration
+	// Timeout is the time a probe can take before it fails
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failed probes after which the cluster is considered dead
+	FailureThreshold int
+}
+
+// WithDefaults returns the config with the unset fields set to their default values
+func (c ProbeConfig) WithDefaults() ProbeConfig {
+	if c.Interval <= 0 {
+		c.Interval = DefaultProbeInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultProbeTimeout
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = DefaultProbeFailureThreshold
+	}
+
+	return c
+}
+
+// ProbeStatus is the result of the connectivity probes of a cluster
+type ProbeStatus struct {
+	// Reachable shows whether the last probe succeeded
+	Reachable bool
+	// LastProbeTime is the time of the last probe
+	LastProbeTime time.Time
+	// LastContactTime is the time of the last successful probe
+	LastContactTime time.Time
+	// LastTransitionTime is the time the cluster became reachable or unreachable
+	LastTransitionTime time.Time
+	// LastError is the error of the last probe if it failed
+	LastError string
+	// ConsecutiveFailures is the number of failed probes since the last successful one
+	ConsecutiveFailures int
+}
+
+// ProbeFunc is called after every probe of a cluster with the previous and the current probe status
+type ProbeFunc func(c *Cluster, previous ProbeStatus, current ProbeStatus)
+
+// Changed returns whether the reachability or the error of the probe differs from the previous status
+func (s ProbeStatus) Changed(previous ProbeStatus) bool {
+	return s.Reachable != previous.Reachable || s.LastError != previous.LastError || previous.LastProbeTime.IsZero()
+}
+
+func (s ProbeStatus) next(err error, now time.Time) ProbeStatus {
+	next := ProbeStatus{
+		Reachable:          err == nil,
+		LastProbeTime:      now,
+		LastContactTime:    s.LastContactTime,
+		LastTransitionTime: s.LastTransitionTime,
+	}
+
+	if err != nil {
+		next.LastError = err.Error()
+		next.ConsecutiveFailures = s.ConsecutiveFailures + 1
+	} else {
+		next.LastContactTime = now
+	}
+
+	if next.Reachable != s.Reachable || s.LastProbeTime.IsZero() {
+		next.LastTransitionTime = now
+	}
+
+	return next
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/rest"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+func TestProbeFailureThreshold(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var remainingFailures int32
+	probes := make(chan clusters.ProbeStatus, 100)
+	dead := make(chan struct{}, 1)
+
+	opts := append(fakeClusterOptions(),
+		clusters.WithLivenessCheckFunc(func(ctx context.Context, c *clusters.Cluster) (string, error) {
+			if atomic.AddInt32(&remainingFailures, -1) >= 0 {
+				return "", errors.New("connection refused")
+			}
+
+			return "id-" + c.GetName(), nil
+		}),
+		clusters.WithProbeConfig(clusters.ProbeConfig{
+			Interval:         time.Millisecond * 10,
+			FailureThreshold: 3,
+		}),
+		clusters.WithOnProbeFunc(func(c *clusters.Cluster, previous clusters.ProbeStatus, current clusters.ProbeStatus) {
+			probes <- current
+		}),
+		clusters.WithOnDeadFunc(func(c *clusters.Cluster) error {
+			dead <- struct{}{}
+
+			return nil
+		}),
+	)
+
+	cluster, err := clusters.NewCluster(ctx, "test", &rest.Config{Host: "https://test.example.com"}, logr.Discard(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cluster.GetProbeConfig().Timeout != clusters.DefaultProbeTimeout {
+		t.Fatalf("unset probe timeout is not defaulted: %s", cluster.GetProbeConfig().Timeout)
+	}
+	if err := cluster.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// waitFor returns the first probe status matching the function
+	waitFor := func(f func(status clusters.ProbeStatus) bool) clusters.ProbeStatus {
+		timeout := time.After(time.Second * 5)
+		for {
+			select {
+			case status := <-probes:
+				if f(status) {
+					return status
+				}
+			case <-timeout:
+				t.Fatalf("probe status is not reached")
+			}
+		}
+	}
+
+	reachable := waitFor(func(status clusters.ProbeStatus) bool { return status.Reachable })
+	if !cluster.IsAlive() {
+		t.Fatalf("reachable cluster is not alive")
+	}
+
+	// fewer failures than the threshold keep the cluster alive
+	atomic.StoreInt32(&remainingFailures, 2)
+	unreachable := waitFor(func(status clusters.ProbeStatus) bool { return status.ConsecutiveFailures == 2 })
+	if unreachable.Reachable || unreachable.LastError != "connection refused" || !unreachable.LastTransitionTime.After(reachable.LastTransitionTime) {
+		t.Fatalf("unexpected probe status: %+v", unreachable)
+	}
+	waitFor(func(status clusters.ProbeStatus) bool { return status.Reachable })
+	select {
+	case <-dead:
+		t.Fatalf("cluster is dead before the failure threshold is reached")
+	default:
+	}
+
+	// reaching the threshold kills the cluster
+	atomic.StoreInt32(&remainingFailures, 3)
+	select {
+	case <-dead:
+	case <-time.After(time.Second * 5):
+		t.Fatalf("cluster is not dead after the failure threshold is reached")
+	}
+	if status := cluster.GetProbeStatus(); status.Reachable || status.ConsecutiveFailures < 3 {
+		t.Fatalf("unexpected probe status: %+v", status)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+
+	"emperror.dev/errors"
+	"k8s.io/client-go/rest"
+)
+
+var (
+	ErrProviderExists          = errors.New("cluster provider already exists")
+	ErrProviderNotFound        = errors.New("cluster provider not found")
+	ErrClusterOfOtherProvider  = errors.New("cluster is provided by another cluster provider")
+	ErrInvalidClusterConfig    = errors.New("invalid cluster config")
+	ErrClusterProviderStopped  = errors.New("cluster provider is stopped")
+	ErrClusterProviderNotReady = errors.New("cluster provider is not started")
+)
+
+// ClusterProvider is a source of clusters, e.g. the Cluster custom resources and their Secrets on the local cluster.
+// The clusters of every provider are handled the same way by the manager, so the sync reconcilers do not need to
+// know where a cluster comes from.
+type ClusterProvider interface {
+	// GetName returns the unique name of the provider
+	GetName() string
+	// Start is called once the provider is added to the manager, the provider delivers its clusters through the
+	// callbacks until the context is cancelled. Start must not block.
+	Start(ctx context.Context, callbacks ClusterProviderCallbacks) error
+}
+
+// ClusterProviderCallbacks are used by a provider to manage the lifecycle of its clusters
+type ClusterProviderCallbacks interface {
+	// Add creates, starts and registers a new cluster
+	Add(config ClusterConfig) (*Cluster, error)
+	// Update replaces the cluster if its connection changed, otherwise only its metadata is updated
+	Update(config ClusterConfig) (*Cluster, error)
+	// SetCredentials replaces the rest config of a running cluster without recreating it, the options should only
+	// describe the credentials, e.g. WithKubeconfig. Its controllers are restarted once the credentials are validated.
+	SetCredentials(config ClusterConfig) (*Cluster, error)
+	// Remove stops and unregisters the cluster
+	Remove(name string) error
+	// Get returns a cluster of the provider
+	Get(name string) (*Cluster, error)
+}
+
+// ClusterConfig describes a cluster delivered by a provider
+type ClusterConfig struct {
+	// Name is the unique name of the cluster across every provider
+	Name string
+	// RestConfig is a ready to use config to connect to the cluster
+	RestConfig *rest.Config
+	// Metadata is arbitrary information about the cluster, it is available through the GetMetadata function of the
+	// cluster
+	Metadata map[string]string
+	// Options are applied after the cluster options of the manager, e.g. WithLivenessCheckFunc can be used to supply
+	// a custom alive signal
+	Options []Option
+	// Controllers are added to the cluster before it is started
+	Controllers []ManagedController
+}
+
+type providerCallbacks struct {
+	manager  *Manager
+	provider string
+	ctx      context.Context
+}
+
+func (c *providerCallbacks) Add(config ClusterConfig) (*Cluster, error) {
+	if config.Name == "" || config.RestConfig == nil {
+		return nil, errors.WithDetails(ErrInvalidClusterConfig, "provider", c.provider, "cluster", config.Name)
+	}
+	if c.ctx.Err() != nil {
+		return nil, errors.WithDetails(ErrClusterProviderStopped, "provider", c.provider)
+	}
+
+	if _, err := c.Get(config.Name); err == nil {
+		if err := c.Remove(config.Name); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, ErrClusterNotFound) {
+		return nil, err
+	}
+
+	opts := make([]Option, 0, len(c.manager.clusterOptions)+len(config.Options)+1)
+	opts = append(opts, c.manager.clusterOptions...)
+	opts = append(opts, WithMetadata(config.Metadata))
+	opts = append(opts, config.Options...)
+
+	cluster, err := NewCluster(c.ctx, config.Name, config.RestConfig, c.manager.log, opts...)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not create new cluster", "provider", c.provider, "cluster", config.Name)
+	}
+
+	for _, controller := range config.Controllers {
+		if err := cluster.AddController(controller); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not add managed controller", "provider", c.provider, "cluster", config.Name)
+		}
+	}
+
+	if err := cluster.Start(); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not start cluster", "provider", c.provider, "cluster", config.Name)
+	}
+
+	if err := c.manager.add(cluster, c.provider); err != nil {
+		cluster.Stop()
+
+		return nil, err
+	}
+
+	return cluster, nil
+}
+
+func (c *providerCallbacks) Update(config ClusterConfig) (*Cluster, error) {
+	cluster, err := c.Get(config.Name)
+	if errors.Is(err, ErrClusterNotFound) {
+		return c.Add(config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !RestConfigsEqual(cluster.k8sConfig, config.RestConfig) {
+		return c.Add(config)
+	}
+
+	cluster.SetMetadata(config.Metadata)
+
+	return cluster, nil
+}
+
+func (c *providerCallbacks) SetCredentials(config ClusterConfig) (*Cluster, error) {
+	if config.RestConfig == nil {
+		return nil, errors.WithDetails(ErrInvalidClusterConfig, "provider", c.provider, "cluster", config.Name)
+	}
+
+	cluster, err := c.Get(config.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cluster.SetCredentials(config.RestConfig, config.Options...); err != nil {
+		return nil, err
+	}
+
+	if config.Metadata != nil {
+		cluster.SetMetadata(config.Metadata)
+	}
+
+	return cluster, nil
+}
+
+func (c *providerCallbacks) Remove(name string) error {
+	cluster, err := c.Get(name)
+	if errors.Is(err, ErrClusterNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.manager.Remove(cluster)
+}
+
+func (c *providerCallbacks) Get(name string) (*Cluster, error) {
+	cluster, provider, err := c.manager.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if provider != c.provider {
+		return nil, errors.WithDetails(ErrClusterOfOtherProvider, "cluster", name, "provider", provider)
+	}
+
+	return cluster, nil
+}
+
+// RestConfigsEqual returns whether the two configs connect to the same cluster with the same credentials
+func RestConfigsEqual(a, b *rest.Config) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Host == b.Host &&
+		a.APIPath == b.APIPath &&
+		a.Username == b.Username &&
+		a.Password == b.Password &&
+		a.BearerToken == b.BearerToken &&
+		a.BearerTokenFile == b.BearerTokenFile &&
+		reflect.DeepEqual(a.Impersonate, b.Impersonate) &&
+		a.TLSClientConfig.Insecure == b.TLSClientConfig.Insecure &&
+		a.TLSClientConfig.ServerName == b.TLSClientConfig.ServerName &&
+		a.TLSClientConfig.CertFile == b.TLSClientConfig.CertFile &&
+		a.TLSClientConfig.KeyFile == b.TLSClientConfig.KeyFile &&
+		a.TLSClientConfig.CAFile == b.TLSClientConfig.CAFile &&
+		bytes.Equal(a.TLSClientConfig.CertData, b.TLSClientConfig.CertData) &&
+		bytes.Equal(a.TLSClientConfig.KeyData, b.TLSClientConfig.KeyData) &&
+		bytes.Equal(a.TLSClientConfig.CAData, b.TLSClientConfig.CAData)
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+// fakeClusterOptions make clusters alive without an API server
+func fakeClusterOptions() []clusters.Option {
+	return []clusters.Option{
+		clusters.WithLivenessCheckFunc(func(ctx context.Context, c *clusters.Cluster) (string, error) {
+			return "id-" + c.GetName(), nil
+		}),
+		clusters.WithCtrlOption(ctrl.Options{
+			MetricsBindAddress: "0",
+			MapperProvider: func(c *rest.Config) (meta.RESTMapper, error) {
+				return meta.NewDefaultRESTMapper(nil), nil
+			},
+		}),
+	}
+}
+
+func fakeClusterConfig(name string, host string) clusters.ClusterConfig {
+	return clusters.ClusterConfig{
+		Name: name,
+		RestConfig: &rest.Config{
+			Host: host,
+		},
+		Metadata: map[string]string{
+			"host": host,
+		},
+	}
+}
+
+func TestInMemoryProvider(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var added, deleted []string
+	var mu sync.Mutex
+
+	mgr := clusters.NewManager(ctx,
+		clusters.WithClusterOptions(fakeClusterOptions()...),
+		clusters.WithOnAfterAddFunc(func(c *clusters.Cluster) {
+			mu.Lock()
+			defer mu.Unlock()
+			added = append(added, c.GetName())
+		}),
+		clusters.WithOnBeforeDeleteFunc(func(c *clusters.Cluster) {
+			mu.Lock()
+			defer mu.Unlock()
+			deleted = append(deleted, c.GetName())
+		}),
+	)
+
+	provider := clusters.NewInMemoryProvider("memory")
+	if err := provider.Set(fakeClusterConfig("cluster-a", "https://a.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.Set(fakeClusterConfig("cluster-b", "https://b.example.com")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the clusters set before the provider is added are delivered when it starts
+	if err := mgr.AddProvider(provider); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AddProvider(clusters.NewInMemoryProvider("memory")); !errors.Is(err, clusters.ErrProviderExists) {
+		t.Fatalf("provider with the same name is added: %v", err)
+	}
+
+	alive := mgr.GetAliveClustersByID()
+	if len(alive) != 2 || alive["id-cluster-a"] == nil || alive["id-cluster-b"] == nil {
+		t.Fatalf("unexpected alive clusters: %v", alive)
+	}
+
+	clusterA, err := mgr.Get("cluster-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := mgr.GetProviderName(clusterA); name != "memory" {
+		t.Fatalf("unexpected provider name: %s", name)
+	}
+
+	// only the metadata changes, the cluster is kept
+	config := fakeClusterConfig("cluster-a", "https://a.example.com")
+	config.Metadata["region"] = "eu"
+	if err := provider.Set(config); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := mgr.Get("cluster-a"); c != clusterA || c.GetMetadata()["region"] != "eu" {
+		t.Fatalf("cluster is not updated in place")
+	}
+
+	// the connection changes, the cluster is replaced
+	if err := provider.Set(fakeClusterConfig("cluster-a", "https://a2.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := mgr.Get("cluster-a"); c == clusterA || c.GetMetadata()["host"] != "https://a2.example.com" {
+		t.Fatalf("cluster is not replaced")
+	}
+	select {
+	case <-clusterA.Stopped():
+	default:
+		t.Fatalf("replaced cluster is not stopped")
+	}
+
+	// the clusters of a provider can not be taken over by another one
+	other := clusters.NewInMemoryProvider("other")
+	if err := mgr.AddProvider(other); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Set(fakeClusterConfig("cluster-b", "https://b.example.com")); !errors.Is(err, clusters.ErrClusterOfOtherProvider) {
+		t.Fatalf("cluster of another provider is overwritten: %v", err)
+	}
+
+	if err := provider.Delete("cluster-b"); err != nil {
+		t.Fatal(err)
+	}
+	if mgr.Exists("cluster-b") {
+		t.Fatalf("deleted cluster exists")
+	}
+
+	// after the provider is removed, another provider can deliver its clusters
+	if err := mgr.RemoveProvider("memory"); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.GetAll()) != 0 {
+		t.Fatalf("clusters of the removed provider exist: %v", mgr.GetAll())
+	}
+	if err := other.Set(fakeClusterConfig("cluster-a", "https://a.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := mgr.Get("cluster-a"); c == nil || mgr.GetProviderName(c) != "other" {
+		t.Fatalf("cluster is not taken over by the other provider")
+	}
+	if err := mgr.RemoveProvider("memory"); !errors.Is(err, clusters.ErrProviderNotFound) {
+		t.Fatalf("removed provider is found: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(added) != 4 || len(deleted) != 3 {
+		t.Fatalf("unexpected callbacks, added: %v, deleted: %v", added, deleted)
+	}
+}
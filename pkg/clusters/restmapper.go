@@ -0,0 +1,350 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// DefaultRESTMapperRefreshInterval is the fallback refresh interval for changes missed by the CRD watch
+	DefaultRESTMapperRefreshInterval = time.Minute * 10
+	// DefaultRESTMapperMinRefreshInterval is the minimum time between two refreshes requested by lookups or CRD events
+	DefaultRESTMapperMinRefreshInterval = time.Second * 10
+
+	RESTMapperRefreshTriggerStart    = "start"
+	RESTMapperRefreshTriggerPeriodic = "periodic"
+	RESTMapperRefreshTriggerRequest  = "request"
+	RESTMapperRefreshTriggerWarm     = "warm"
+)
+
+// anyVersion matches every version of a kind, the same as the "*" version of the resource sync rules
+const anyVersion = "*"
+
+var customResourceDefinitionsGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// RESTMapper is a REST mapper backed by a cached copy of the discovery information of a cluster. Lookups never call
+// discovery, a lookup of an unknown kind fails right away and requests an asynchronous refresh instead. The cache is
+// refreshed on CRD changes, periodically and when kinds are warmed before they are used.
+type RESTMapper struct {
+	name               string
+	discoveryClient    discovery.DiscoveryInterface
+	metadataClient     metadata.Interface
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	log                logr.Logger
+	refreshRequests    chan struct{}
+	lastRefreshTime    time.Time
+	lastRefreshTimeMu  sync.Mutex
+	refreshMu          sync.Mutex
+	delegate           meta.RESTMapper
+	delegateMu         sync.RWMutex
+}
+
+var _ meta.RESTMapper = &RESTMapper{}
+
+type RESTMapperOption func(m *RESTMapper)
+
+// WithRESTMapperName sets the name the refreshes of the mapper are reported with, usually the name of the cluster
+func WithRESTMapperName(name string) RESTMapperOption {
+	return func(m *RESTMapper) {
+		m.name = name
+	}
+}
+
+// WithRESTMapperRefreshInterval sets the interval of the periodic refreshes
+func WithRESTMapperRefreshInterval(interval time.Duration) RESTMapperOption {
+	return func(m *RESTMapper) {
+		m.refreshInterval = interval
+	}
+}
+
+// WithRESTMapperMinRefreshInterval sets the minimum time between two requested refreshes
+func WithRESTMapperMinRefreshInterval(interval time.Duration) RESTMapperOption {
+	return func(m *RESTMapper) {
+		m.minRefreshInterval = interval
+	}
+}
+
+// WithRESTMapperMetadataClient enables refreshing the mapper on the changes of the CRDs of the cluster
+func WithRESTMapperMetadataClient(client metadata.Interface) RESTMapperOption {
+	return func(m *RESTMapper) {
+		m.metadataClient = client
+	}
+}
+
+func WithRESTMapperLogger(log logr.Logger) RESTMapperOption {
+	return func(m *RESTMapper) {
+		m.log = log
+	}
+}
+
+func NewRESTMapper(discoveryClient discovery.DiscoveryInterface, opts ...RESTMapperOption) *RESTMapper {
+	m := &RESTMapper{
+		discoveryClient:    discoveryClient,
+		refreshInterval:    DefaultRESTMapperRefreshInterval,
+		minRefreshInterval: DefaultRESTMapperMinRefreshInterval,
+		log:                logr.Discard(),
+		refreshRequests:    make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// NewRESTMapperForConfig creates a REST mapper for the cluster of the config which is refreshed on CRD changes
+func NewRESTMapperForConfig(config *rest.Config, opts ...RESTMapperOption) (*RESTMapper, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not create discovery client")
+	}
+
+	metadataClient, err := metadata.NewForConfig(config)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not create metadata client")
+	}
+
+	return NewRESTMapper(discoveryClient, append([]RESTMapperOption{WithRESTMapperMetadataClient(metadataClient)}, opts...)...), nil
+}
+
+// Start refreshes the mapper and keeps it up to date until the context is cancelled
+func (m *RESTMapper) Start(ctx context.Context) error {
+	if err := m.refresh(RESTMapperRefreshTriggerStart); err != nil {
+		return err
+	}
+
+	if m.metadataClient != nil {
+		m.watchCustomResourceDefinitions(ctx)
+	}
+
+	go m.run(ctx)
+
+	return nil
+}
+
+func (m *RESTMapper) run(ctx context.Context) {
+	ticker := time.NewTicker(m.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.refresh(RESTMapperRefreshTriggerPeriodic); err != nil {
+				m.log.Error(err, "could not refresh rest mapper")
+			}
+		case <-m.refreshRequests:
+			if wait := m.minRefreshInterval - time.Since(m.getLastRefreshTime()); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+			}
+			if err := m.refresh(RESTMapperRefreshTriggerRequest); err != nil {
+				m.log.Error(err, "could not refresh rest mapper")
+			}
+		}
+	}
+}
+
+// watchCustomResourceDefinitions requests a refresh whenever a CRD is created, deleted or its spec changes
+func (m *RESTMapper) watchCustomResourceDefinitions(ctx context.Context) {
+	informer := metadatainformer.NewFilteredMetadataInformer(m.metadataClient, customResourceDefinitionsGVR, "", 0, cache.Indexers{}, nil).Informer()
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			// the CRDs listed initially are already known by the mapper
+			if informer.HasSynced() {
+				m.RequestRefresh()
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldMeta, oldOK := oldObj.(metav1.Object)
+			newMeta, newOK := newObj.(metav1.Object)
+			if oldOK && newOK && oldMeta.GetGeneration() == newMeta.GetGeneration() {
+				return
+			}
+			m.RequestRefresh()
+		},
+		DeleteFunc: func(obj interface{}) {
+			m.RequestRefresh()
+		},
+	})
+
+	go informer.Run(ctx.Done())
+}
+
+// RequestRefresh schedules an asynchronous refresh of the mapper, requests are coalesced and rate limited
+func (m *RESTMapper) RequestRefresh() {
+	select {
+	case m.refreshRequests <- struct{}{}:
+	default:
+	}
+}
+
+// Refresh reloads the discovery information of the cluster
+func (m *RESTMapper) Refresh() error {
+	return m.refresh(RESTMapperRefreshTriggerRequest)
+}
+
+func (m *RESTMapper) refresh(trigger string) error {
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
+
+	start := time.Now()
+	groupResources, err := restmapper.GetAPIGroupResources(m.discoveryClient)
+	if err != nil && !(discovery.IsGroupDiscoveryFailedError(err) && len(groupResources) > 0) {
+		restMapperRefreshes.WithLabelValues(m.name, trigger, "error").Inc()
+
+		return errors.WrapIf(err, "could not get api group resources")
+	}
+	if err != nil {
+		// the groups of unavailable aggregated api servers are missing until the next refresh
+		m.log.V(1).Info("partial discovery information", "error", err.Error())
+	}
+
+	m.delegateMu.Lock()
+	m.delegate = restmapper.NewDiscoveryRESTMapper(groupResources)
+	m.delegateMu.Unlock()
+
+	m.lastRefreshTimeMu.Lock()
+	m.lastRefreshTime = time.Now()
+	m.lastRefreshTimeMu.Unlock()
+
+	restMapperRefreshes.WithLabelValues(m.name, trigger, "success").Inc()
+	restMapperRefreshDuration.WithLabelValues(m.name).Observe(time.Since(start).Seconds())
+	m.log.V(2).Info("rest mapper refreshed", "trigger", trigger, "duration", time.Since(start))
+
+	return nil
+}
+
+func (m *RESTMapper) getLastRefreshTime() time.Time {
+	m.lastRefreshTimeMu.Lock()
+	defer m.lastRefreshTimeMu.Unlock()
+
+	return m.lastRefreshTime
+}
+
+// Warm makes sure that the given kinds are known by the mapper, the mapper is refreshed synchronously if any of them
+// is unknown. A "*" version matches any version of the kind. It is meant to be called before the kinds are used,
+// so that their lookups never wait for discovery.
+func (m *RESTMapper) Warm(gvks ...schema.GroupVersionKind) error {
+	if m.knows(gvks...) == nil {
+		return nil
+	}
+
+	if err := m.refresh(RESTMapperRefreshTriggerWarm); err != nil {
+		return err
+	}
+
+	return m.knows(gvks...)
+}
+
+func (m *RESTMapper) knows(gvks ...schema.GroupVersionKind) error {
+	for _, gvk := range gvks {
+		var versions []string
+		if gvk.Version != anyVersion {
+			versions = []string{gvk.Version}
+		}
+		if _, err := m.lookup().RESTMapping(gvk.GroupKind(), versions...); err != nil {
+			return errors.WrapIfWithDetails(err, "unknown kind", "gvk", gvk.String())
+		}
+	}
+
+	return nil
+}
+
+func (m *RESTMapper) lookup() meta.RESTMapper {
+	m.delegateMu.RLock()
+	defer m.delegateMu.RUnlock()
+
+	if m.delegate == nil {
+		return meta.MultiRESTMapper{}
+	}
+
+	return m.delegate
+}
+
+// checkMatch requests a refresh if the lookup failed because of a kind or a resource unknown by the mapper
+func (m *RESTMapper) checkMatch(err error) error {
+	if meta.IsNoMatchError(err) {
+		m.RequestRefresh()
+	}
+
+	return err
+}
+
+func (m *RESTMapper) KindFor(resource schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	gvk, err := m.lookup().KindFor(resource)
+
+	return gvk, m.checkMatch(err)
+}
+
+func (m *RESTMapper) KindsFor(resource schema.GroupVersionResource) ([]schema.GroupVersionKind, error) {
+	gvks, err := m.lookup().KindsFor(resource)
+
+	return gvks, m.checkMatch(err)
+}
+
+func (m *RESTMapper) ResourceFor(input schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	gvr, err := m.lookup().ResourceFor(input)
+
+	return gvr, m.checkMatch(err)
+}
+
+func (m *RESTMapper) ResourcesFor(input schema.GroupVersionResource) ([]schema.GroupVersionResource, error) {
+	gvrs, err := m.lookup().ResourcesFor(input)
+
+	return gvrs, m.checkMatch(err)
+}
+
+func (m *RESTMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	mapping, err := m.lookup().RESTMapping(gk, versions...)
+
+	return mapping, m.checkMatch(err)
+}
+
+func (m *RESTMapper) RESTMappings(gk schema.GroupKind, versions ...string) ([]*meta.RESTMapping, error) {
+	mappings, err := m.lookup().RESTMappings(gk, versions...)
+
+	return mappings, m.checkMatch(err)
+}
+
+func (m *RESTMapper) ResourceSingularizer(resource string) (string, error) {
+	return m.lookup().ResourceSingularizer(resource)
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	"k8s.io/client-go/restmapper"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+var (
+	configMapGVK = schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	widgetGVK    = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+)
+
+// slowDiscovery is a discovery backend which takes delay to answer and counts the discoveries
+type slowDiscovery struct {
+	*fakediscovery.FakeDiscovery
+
+	delay       time.Duration
+	discoveries int
+	mu          sync.Mutex
+}
+
+func newSlowDiscovery(delay time.Duration, resources ...*metav1.APIResourceList) *slowDiscovery {
+	return &slowDiscovery{
+		FakeDiscovery: &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: resources}},
+		delay:         delay,
+	}
+}
+
+func (d *slowDiscovery) ServerGroups() (*metav1.APIGroupList, error) {
+	d.discovered()
+
+	return d.FakeDiscovery.ServerGroups()
+}
+
+func (d *slowDiscovery) ServerGroupsAndResources() ([]*metav1.APIGroup, []*metav1.APIResourceList, error) {
+	d.discovered()
+
+	return d.FakeDiscovery.ServerGroupsAndResources()
+}
+
+func (d *slowDiscovery) discovered() {
+	time.Sleep(d.delay)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.discoveries++
+}
+
+func (d *slowDiscovery) getDiscoveries() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.discoveries
+}
+
+func configMapResources() *metav1.APIResourceList {
+	return &metav1.APIResourceList{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}},
+	}
+}
+
+func widgetResources() *metav1.APIResourceList {
+	return &metav1.APIResourceList{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget", Namespaced: true}},
+	}
+}
+
+func TestRESTMapperWarm(t *testing.T) {
+	t.Parallel()
+
+	backend := newSlowDiscovery(0, configMapResources())
+	mapper := clusters.NewRESTMapper(backend)
+
+	if _, err := mapper.RESTMapping(configMapGVK.GroupKind(), configMapGVK.Version); !meta.IsNoMatchError(err) {
+		t.Fatalf("kinds must be unknown before the first refresh, got: %v", err)
+	}
+	if backend.getDiscoveries() != 0 {
+		t.Fatal("lookups must not call discovery")
+	}
+
+	if err := mapper.Warm(configMapGVK); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mapper.RESTMapping(configMapGVK.GroupKind(), configMapGVK.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	discoveries := backend.getDiscoveries()
+	if err := mapper.Warm(configMapGVK); err != nil {
+		t.Fatal(err)
+	}
+	if backend.getDiscoveries() != discoveries {
+		t.Fatal("known kinds must not be discovered again")
+	}
+
+	// a CRD installed after the last refresh
+	backend.Resources = append(backend.Resources, widgetResources())
+	if _, err := mapper.RESTMapping(widgetGVK.GroupKind(), widgetGVK.Version); !meta.IsNoMatchError(err) {
+		t.Fatalf("new kinds must be unknown until the next refresh, got: %v", err)
+	}
+	if backend.getDiscoveries() != discoveries {
+		t.Fatal("lookups must not call discovery")
+	}
+
+	if err := mapper.Warm(schema.GroupVersionKind{Group: widgetGVK.Group, Version: "*", Kind: widgetGVK.Kind}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mapper.RESTMapping(widgetGVK.GroupKind(), widgetGVK.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mapper.Warm(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Gadget"}); !meta.IsNoMatchError(errors.Cause(err)) {
+		t.Fatalf("unknown kinds must fail to warm, got: %v", err)
+	}
+}
+
+func TestRESTMapperRefreshOnUnknownKind(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backend := newSlowDiscovery(0, configMapResources())
+	mapper := clusters.NewRESTMapper(backend, clusters.WithRESTMapperMinRefreshInterval(0))
+	if err := mapper.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	backend.Resources = append(backend.Resources, widgetResources())
+	if _, err := mapper.RESTMapping(widgetGVK.GroupKind(), widgetGVK.Version); !meta.IsNoMatchError(err) {
+		t.Fatalf("new kinds must be unknown until the next refresh, got: %v", err)
+	}
+
+	err := wait.PollImmediate(time.Millisecond*10, time.Second*5, func() (bool, error) {
+		_, err := mapper.RESTMapping(widgetGVK.GroupKind(), widgetGVK.Version)
+
+		return err == nil, nil
+	})
+	if err != nil {
+		t.Fatal("the lookup of an unknown kind must refresh the mapper in the background")
+	}
+}
+
+func TestRESTMapperRefreshOnCRDCreation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scheme := runtime.NewScheme()
+	metav1.AddMetaToScheme(scheme)
+	metadataClient := metadatafake.NewSimpleMetadataClient(scheme)
+
+	backend := newSlowDiscovery(0, configMapResources())
+	mapper := clusters.NewRESTMapper(backend,
+		clusters.WithRESTMapperMetadataClient(metadataClient),
+		clusters.WithRESTMapperMinRefreshInterval(0),
+	)
+	if err := mapper.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the CRDs listed initially do not trigger a refresh, so the CRD is created once the watch is started
+	err := wait.PollImmediate(time.Millisecond*10, time.Second*5, func() (bool, error) {
+		for _, action := range metadataClient.Actions() {
+			if action.GetVerb() == "watch" {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		t.Fatal("the CRDs must be watched")
+	}
+
+	discoveries := backend.getDiscoveries()
+	backend.Resources = append(backend.Resources, widgetResources())
+	crd := &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apiextensions.k8s.io/v1",
+			Kind:       "CustomResourceDefinition",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "widgets.example.com",
+		},
+	}
+	gvr := schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	if _, err := metadataClient.Resource(gvr).(metadatafake.MetadataClient).CreateFake(crd, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the kind is not looked up until the refresh, since lookups of unknown kinds request a refresh too
+	err = wait.PollImmediate(time.Millisecond*10, time.Second*5, func() (bool, error) {
+		return backend.getDiscoveries() > discoveries, nil
+	})
+	if err != nil {
+		t.Fatal("the creation of a CRD must refresh the mapper")
+	}
+	if _, err := mapper.RESTMapping(widgetGVK.GroupKind(), widgetGVK.Version); err != nil {
+		t.Fatalf("the kind of the new CRD must be known after the refresh, got: %v", err)
+	}
+}
+
+// TestRESTMapperLookupLatency compares the lookup latencies of the cached mapper with a mapper discovering on demand,
+// when every tenth lookup is for a kind the mapper does not know
+func TestRESTMapperLookupLatency(t *testing.T) {
+	t.Parallel()
+
+	const (
+		delay   = time.Millisecond * 20
+		lookups = 100
+	)
+
+	measure := func(mapper meta.RESTMapper, expire func()) time.Duration {
+		latencies := make([]time.Duration, 0, lookups)
+		for i := 0; i < lookups; i++ {
+			gvk := configMapGVK
+			if i%10 == 0 {
+				expire()
+				gvk = widgetGVK
+			}
+
+			start := time.Now()
+			_, _ = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			latencies = append(latencies, time.Since(start))
+		}
+
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		return latencies[len(latencies)*99/100-1]
+	}
+
+	onDemandBackend := newSlowDiscovery(delay, configMapResources())
+	onDemand := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(onDemandBackend))
+	onDemandP99 := measure(onDemand, onDemand.Reset)
+
+	cachedBackend := newSlowDiscovery(delay, configMapResources())
+	cached := clusters.NewRESTMapper(cachedBackend, clusters.WithRESTMapperMinRefreshInterval(time.Hour))
+	if err := cached.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	cachedP99 := measure(cached, func() {})
+
+	t.Logf("p99 lookup latency: on demand %s, cached %s", onDemandP99, cachedP99)
+
+	if onDemandP99 < delay {
+		t.Fatalf("the on demand mapper is expected to wait for discovery, p99: %s", onDemandP99)
+	}
+	if cachedP99 >= delay/2 {
+		t.Fatalf("the cached mapper must not wait for discovery, p99: %s", cachedP99)
+	}
+}
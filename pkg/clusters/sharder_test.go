@@ -0,0 +1,115 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+// fakeSharder owns the keys set on it
+type fakeSharder struct {
+	owned    map[string]bool
+	onChange []func()
+	mu       sync.Mutex
+}
+
+func (s *fakeSharder) Owns(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.owned[key]
+}
+
+func (s *fakeSharder) AddOnChangeFunc(f func()) {
+	s.onChange = append(s.onChange, f)
+}
+
+func (s *fakeSharder) set(owned ...string) {
+	s.mu.Lock()
+	s.owned = make(map[string]bool)
+	for _, key := range owned {
+		s.owned[key] = true
+	}
+	s.mu.Unlock()
+
+	for _, f := range s.onChange {
+		f()
+	}
+}
+
+func TestManagerSharding(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sharder := &fakeSharder{owned: map[string]bool{"cluster-a": true, "cluster-b": true}}
+	mgr := clusters.NewManager(ctx, clusters.WithClusterOptions(fakeClusterOptions()...), clusters.WithSharder(sharder))
+	if !mgr.IsSharded() {
+		t.Fatal("expected a sharded manager")
+	}
+
+	shardChanges := 0
+	mgr.AddOnShardChangeFunc(func() {
+		shardChanges++
+	})
+
+	provider := clusters.NewInMemoryProvider("memory")
+	if err := mgr.AddProvider(provider); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"cluster-a", "cluster-b"} {
+		if err := provider.Set(fakeClusterConfig(name, "https://"+name+".example.com")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := provider.Set(fakeClusterConfig("cluster-c", "https://cluster-c.example.com")); !errors.Is(err, clusters.ErrClusterNotOwned) {
+		t.Fatalf("cluster of another replica is added: %v", err)
+	}
+
+	clusterA, err := mgr.Get("cluster-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the cluster moved to another replica is stopped
+	sharder.set("cluster-a", "cluster-c")
+	if mgr.Exists("cluster-b") {
+		t.Fatal("cluster of another replica is kept")
+	}
+	if c, _ := mgr.Get("cluster-a"); c != clusterA {
+		t.Fatal("cluster staying on the replica is replaced")
+	}
+	select {
+	case <-clusterA.Stopped():
+		t.Fatal("cluster staying on the replica is stopped")
+	default:
+	}
+	if shardChanges != 1 {
+		t.Fatalf("unexpected shard change callbacks: %d", shardChanges)
+	}
+
+	if err := provider.Set(fakeClusterConfig("cluster-c", "https://cluster-c.example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if !mgr.Owns("cluster-c") || !mgr.Exists("cluster-c") {
+		t.Fatal("cluster assigned to the replica is not added")
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DefaultWatchFailureThreshold is the number of consecutive failed list and watch requests of a resource after which
+// its watch is considered degraded
+const DefaultWatchFailureThreshold = 3
+
+// WatchStatus is the health of the list and watch requests the informers of a resource send to a cluster
+type WatchStatus struct {
+	Resource schema.GroupVersionResource
+	// Degraded shows whether the consecutive failures reached the failure threshold
+	Degraded bool
+	// ConsecutiveFailures is the number of failed requests since the last successful one
+	ConsecutiveFailures int
+	// LastError is the error of the last failed request
+	LastError string
+	// LastTransitionTime is the time the watch became degraded or recovered
+	LastTransitionTime time.Time
+}
+
+// WatchStatusFunc is called when the watch of a resource of a cluster becomes degraded or recovers
+type WatchStatusFunc func(c *Cluster, status WatchStatus)
+
+// WatchMonitor counts the consecutive failures of the list and watch requests of the informers per resource. The
+// reflectors of the informers only log their errors and retry, so a watch which keeps failing, e.g. because of expired
+// credentials or throttling, would otherwise stall the controllers silently.
+type WatchMonitor struct {
+	clusterName string
+	threshold   int
+	onChange    func(status WatchStatus)
+
+	statuses map[schema.GroupVersionResource]WatchStatus
+	mu       sync.Mutex
+}
+
+// NewWatchMonitor returns a watch monitor calling the function when the watch of a resource becomes degraded or
+// recovers, a threshold of 0 never reports degraded watches
+func NewWatchMonitor(clusterName string, threshold int, onChange func(status WatchStatus)) *WatchMonitor {
+	return &WatchMonitor{
+		clusterName: clusterName,
+		threshold:   threshold,
+		onChange:    onChange,
+		statuses:    make(map[schema.GroupVersionResource]WatchStatus),
+	}
+}
+
+// WrapTransport observes the list and watch requests sent through the round tripper, it can be used as the
+// WrapTransport of a rest config
+func (m *WatchMonitor) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &watchMonitorRoundTripper{
+		monitor: m,
+		next:    rt,
+	}
+}
+
+// GetDegraded returns the statuses of the degraded watches ordered by their resources
+func (m *WatchMonitor) GetDegraded() []WatchStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]WatchStatus, 0)
+	for _, status := range m.statuses {
+		if status.Degraded {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Resource.String() < statuses[j].Resource.String()
+	})
+
+	return statuses
+}
+
+// Reset forgets the statuses, e.g. because the informers are recreated, the degraded watches are reported recovered
+func (m *WatchMonitor) Reset(now time.Time) {
+	m.mu.Lock()
+	statuses := m.statuses
+	m.statuses = make(map[schema.GroupVersionResource]WatchStatus)
+	m.mu.Unlock()
+
+	for _, status := range statuses {
+		watchFailures.DeleteLabelValues(m.clusterName, status.Resource.GroupResource().String())
+		watchDegraded.DeleteLabelValues(m.clusterName, status.Resource.GroupResource().String())
+		if status.Degraded && m.onChange != nil {
+			m.onChange(WatchStatus{
+				Resource:           status.Resource,
+				LastTransitionTime: now,
+			})
+		}
+	}
+}
+
+// observe records the result of a list or watch request of the resource, err is nil for a successful one
+func (m *WatchMonitor) observe(resource schema.GroupVersionResource, err error, now time.Time) {
+	m.mu.Lock()
+	status, ok := m.statuses[resource]
+	if err == nil && !ok {
+		m.mu.Unlock()
+
+		return
+	}
+
+	previous := status.Degraded
+	status.Resource = resource
+	if err == nil {
+		status.ConsecutiveFailures = 0
+		status.LastError = ""
+		status.Degraded = false
+		delete(m.statuses, resource)
+	} else {
+		status.ConsecutiveFailures++
+		status.LastError = err.Error()
+		status.Degraded = m.threshold > 0 && status.ConsecutiveFailures >= m.threshold
+	}
+	if status.Degraded != previous {
+		status.LastTransitionTime = now
+	}
+	if err != nil {
+		m.statuses[resource] = status
+	}
+	m.mu.Unlock()
+
+	watchFailures.WithLabelValues(m.clusterName, resource.GroupResource().String()).Set(float64(status.ConsecutiveFailures))
+	degraded := 0.0
+	if status.Degraded {
+		degraded = 1
+	}
+	watchDegraded.WithLabelValues(m.clusterName, resource.GroupResource().String()).Set(degraded)
+
+	if status.Degraded != previous && m.onChange != nil {
+		m.onChange(status)
+	}
+}
+
+type watchMonitorRoundTripper struct {
+	monitor *WatchMonitor
+	next    http.RoundTripper
+}
+
+func (rt *watchMonitorRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource, verb, ok := getInformerRequestResource(req)
+
+	resp, err := rt.next.RoundTrip(req)
+	if !ok {
+		return resp, err
+	}
+
+	switch {
+	case err != nil:
+		// the requests of stopped informers are cancelled
+		if req.Context().Err() == nil {
+			rt.monitor.observe(resource, errors.WrapIff(err, "%s %s failed", verb, resource.GroupResource()), time.Now())
+		}
+	case resp.StatusCode >= http.StatusBadRequest:
+		rt.monitor.observe(resource, errors.Errorf("%s %s failed: %s", verb, resource.GroupResource(), resp.Status), time.Now())
+	default:
+		rt.monitor.observe(resource, nil, time.Now())
+	}
+
+	return resp, err
+}
+
+// getInformerRequestResource returns the resource and the verb of the list and watch requests of informers, which are
+// the collection requests with a watch or a limit parameter, e.g. GET /apis/apps/v1/namespaces/default/deployments
+func getInformerRequestResource(req *http.Request) (schema.GroupVersionResource, string, bool) {
+	if req.Method != http.MethodGet {
+		return schema.GroupVersionResource{}, "", false
+	}
+
+	query := req.URL.Query()
+	verb := "list"
+	switch {
+	case query.Get("watch") == "true" || query.Get("watch") == "1":
+		verb = "watch"
+	case query.Has("limit"):
+	default:
+		return schema.GroupVersionResource{}, "", false
+	}
+
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	var resource schema.GroupVersionResource
+	switch {
+	case len(segments) >= 3 && segments[0] == "api":
+		resource.Version = segments[1]
+		segments = segments[2:]
+	case len(segments) >= 4 && segments[0] == "apis":
+		resource.Group = segments[1]
+		resource.Version = segments[2]
+		segments = segments[3:]
+	default:
+		return schema.GroupVersionResource{}, "", false
+	}
+
+	// namespaced collections, e.g. namespaces/default/configmaps
+	if len(segments) == 3 && segments[0] == "namespaces" {
+		segments = segments[2:]
+	}
+	if len(segments) != 1 {
+		return schema.GroupVersionResource{}, "", false
+	}
+	resource.Resource = segments[0]
+
+	return resource, verb, true
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusters_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/clusters"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWatchMonitor(t *testing.T) {
+	t.Parallel()
+
+	status := http.StatusTooManyRequests
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(status)
+
+		return rec.Result(), nil
+	})
+
+	changes := make([]clusters.WatchStatus, 0)
+	monitor := clusters.NewWatchMonitor("watch-monitor-test", 3, func(status clusters.WatchStatus) {
+		changes = append(changes, status)
+	})
+	rt := monitor.WrapTransport(transport)
+
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	send := func(url string) {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	// requests which are not sent by informers are ignored
+	for i := 0; i < 5; i++ {
+		send("https://cluster/api/v1/namespaces/default/configmaps/test")
+		send("https://cluster/api/v1/namespaces/default/configmaps")
+	}
+	if len(monitor.GetDegraded()) != 0 || len(changes) != 0 {
+		t.Fatalf("expected no degraded watches, got %v", monitor.GetDegraded())
+	}
+
+	send("https://cluster/apis/apps/v1/namespaces/default/deployments?limit=500")
+	send("https://cluster/apis/apps/v1/deployments?watch=true")
+	if len(monitor.GetDegraded()) != 0 {
+		t.Fatal("expected the watch not to be degraded before the threshold")
+	}
+
+	send("https://cluster/apis/apps/v1/deployments?watch=true")
+	degraded := monitor.GetDegraded()
+	if len(degraded) != 1 || degraded[0].Resource != deployments || degraded[0].ConsecutiveFailures != 3 {
+		t.Fatalf("expected the deployments watch to be degraded, got %v", degraded)
+	}
+	if len(changes) != 1 || !changes[0].Degraded || changes[0].LastError == "" {
+		t.Fatalf("expected a degraded change, got %v", changes)
+	}
+
+	// further failures are not reported again
+	send("https://cluster/apis/apps/v1/deployments?watch=true")
+	if len(changes) != 1 {
+		t.Fatalf("expected a single change, got %v", changes)
+	}
+
+	status = http.StatusOK
+	send("https://cluster/apis/apps/v1/deployments?limit=500")
+	if len(monitor.GetDegraded()) != 0 {
+		t.Fatalf("expected the watch to recover, got %v", monitor.GetDegraded())
+	}
+	if len(changes) != 2 || changes[1].Degraded || changes[1].Resource != deployments {
+		t.Fatalf("expected a recovered change, got %v", changes)
+	}
+}
+
+func TestWatchMonitorReset(t *testing.T) {
+	t.Parallel()
+
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		rec.WriteHeader(http.StatusUnauthorized)
+
+		return rec.Result(), nil
+	})
+
+	changes := make([]clusters.WatchStatus, 0)
+	monitor := clusters.NewWatchMonitor("watch-monitor-reset-test", 1, func(status clusters.WatchStatus) {
+		changes = append(changes, status)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "https://cluster/api/v1/configmaps?watch=1", nil)
+	resp, err := monitor.WrapTransport(transport).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(monitor.GetDegraded()) != 1 {
+		t.Fatalf("expected a degraded watch, got %v", monitor.GetDegraded())
+	}
+
+	monitor.Reset(time.Now())
+	if len(monitor.GetDegraded()) != 0 {
+		t.Fatalf("expected no degraded watches after reset, got %v", monitor.GetDegraded())
+	}
+	if len(changes) != 2 || changes[1].Degraded {
+		t.Fatalf("expected the reset to report the watch recovered, got %v", changes)
+	}
+}
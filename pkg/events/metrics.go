@@ -0,0 +1,32 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var eventRecordingErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "cluster_registry_event_recording_errors_total",
+		Help: "Number of events which could not be recorded",
+	},
+	[]string{"reason"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(eventRecordingErrors)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"reflect"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+var ErrInvalidObject = errors.New("invalid event object")
+
+// SafeRecorder validates the involved object before recording an event on it and recovers the panics
+// of the underlying recorder. Events which could not be recorded are logged and counted instead.
+type SafeRecorder struct {
+	recorder record.EventRecorder
+	scheme   *runtime.Scheme
+	mapper   meta.RESTMapper
+	log      logr.Logger
+}
+
+var _ record.EventRecorder = &SafeRecorder{}
+
+func NewSafeRecorder(recorder record.EventRecorder, scheme *runtime.Scheme, mapper meta.RESTMapper, log logr.Logger) *SafeRecorder {
+	return &SafeRecorder{
+		recorder: recorder,
+		scheme:   scheme,
+		mapper:   mapper,
+		log:      log,
+	}
+}
+
+func (r *SafeRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.record(object, eventtype, reason, message, func() {
+		r.recorder.Event(object, eventtype, reason, message)
+	})
+}
+
+func (r *SafeRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.record(object, eventtype, reason, fmt.Sprintf(messageFmt, args...), func() {
+		r.recorder.Eventf(object, eventtype, reason, messageFmt, args...)
+	})
+}
+
+func (r *SafeRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.record(object, eventtype, reason, fmt.Sprintf(messageFmt, args...), func() {
+		r.recorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+	})
+}
+
+func (r *SafeRecorder) record(object runtime.Object, eventtype, reason, message string, record func()) {
+	if err := r.validate(object); err != nil {
+		eventRecordingErrors.WithLabelValues("invalid").Inc()
+		r.log.Error(err, "could not record event", "type", eventtype, "reason", reason, "message", message)
+
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			eventRecordingErrors.WithLabelValues("panic").Inc()
+			r.log.Error(errors.Errorf("event recorder panic: %v", p), "could not record event", "type", eventtype, "reason", reason, "message", message)
+		}
+	}()
+
+	record()
+}
+
+// validate checks whether the object has the UID, GVK and namespace an event can refer to
+func (r *SafeRecorder) validate(object runtime.Object) error {
+	if object == nil || reflect.ValueOf(object).Kind() == reflect.Ptr && reflect.ValueOf(object).IsNil() {
+		return errors.WithDetails(ErrInvalidObject, "reason", "object is nil")
+	}
+
+	objMeta, err := meta.Accessor(object)
+	if err != nil {
+		return errors.WrapIfWithDetails(ErrInvalidObject, "could not access object metadata", "error", err.Error())
+	}
+
+	if objMeta.GetUID() == "" {
+		return errors.WithDetails(ErrInvalidObject, "reason", "uid is empty", "name", objMeta.GetName())
+	}
+
+	gvk, err := apiutil.GVKForObject(object, r.scheme)
+	if err != nil {
+		return errors.WithDetails(ErrInvalidObject, "reason", "unknown gvk", "name", objMeta.GetName())
+	}
+
+	if r.mapper == nil {
+		return nil
+	}
+
+	mapping, err := r.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return errors.WithDetails(ErrInvalidObject, "reason", "no rest mapping", "gvk", gvk.String())
+	}
+
+	namespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace
+	if namespaced != (objMeta.GetNamespace() != "") {
+		return errors.WithDetails(ErrInvalidObject, "reason", "namespace does not match the scope", "gvk", gvk.String(), "namespace", objMeta.GetNamespace())
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events_test
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/events"
+)
+
+type panickingRecorder struct {
+	record.EventRecorder
+}
+
+func (r panickingRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	panic("recorder failure")
+}
+
+func newRecorder(t *testing.T, recorder record.EventRecorder) *events.SafeRecorder {
+	t.Helper()
+
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := clusterregistryv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(clusterregistryv1alpha1.GroupVersion.WithKind("ResourceSyncRule"), meta.RESTScopeRoot)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	return events.NewSafeRecorder(recorder, s, mapper, logr.Discard())
+}
+
+func newRule(uid string) *clusterregistryv1alpha1.ResourceSyncRule {
+	return &clusterregistryv1alpha1.ResourceSyncRule{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "test-rule",
+			UID:  types.UID(uid),
+		},
+	}
+}
+
+func TestSafeRecorder(t *testing.T) {
+	t.Parallel()
+
+	var nilRule *clusterregistryv1alpha1.ResourceSyncRule
+
+	namespacedRule := newRule("test-uid")
+	namespacedRule.Namespace = "default"
+
+	tests := map[string]struct {
+		object   runtime.Object
+		recorded bool
+	}{
+		"valid rule": {
+			object:   newRule("test-uid"),
+			recorded: true,
+		},
+		"valid namespaced object": {
+			object: &corev1.ConfigMap{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "test",
+					Namespace: "default",
+					UID:       "test-uid",
+				},
+			},
+			recorded: true,
+		},
+		"empty uid": {
+			object: newRule(""),
+		},
+		"nil rule": {
+			object: nilRule,
+		},
+		"nil object": {
+			object: nil,
+		},
+		"namespace on cluster scoped object": {
+			object: namespacedRule,
+		},
+		"no rest mapping": {
+			object: &clusterregistryv1alpha1.SyncAnchor{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "test",
+					UID:  "test-uid",
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		fake := record.NewFakeRecorder(2)
+		recorder := newRecorder(t, fake)
+
+		recorder.Event(test.object, corev1.EventTypeNormal, "Test", "test")
+		recorder.Eventf(test.object, corev1.EventTypeNormal, "Test", "test %s", name)
+
+		if recorded := len(fake.Events) > 0; recorded != test.recorded {
+			t.Fatalf("%s: event recorded: %t, expected: %t", name, recorded, test.recorded)
+		}
+	}
+}
+
+func TestSafeRecorderRecoversPanics(t *testing.T) {
+	t.Parallel()
+
+	fake := record.NewFakeRecorder(1)
+	recorder := newRecorder(t, panickingRecorder{EventRecorder: fake})
+
+	// the panic of the recorder must not reach the caller
+	recorder.Event(newRule("test-uid"), corev1.EventTypeWarning, "Test", "test")
+
+	// events are still recorded by the methods which do not panic
+	recorder.Eventf(newRule("test-uid"), corev1.EventTypeWarning, "Test", "test")
+	if len(fake.Events) != 1 {
+		t.Fatal("event was not recorded after a recorder panic")
+	}
+}
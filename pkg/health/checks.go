@@ -0,0 +1,62 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"net/http"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// Checks is a set of named health checks served like the checks of the controller manager, except that checks can
+// be added and removed while they are served, e.g. one for every resource sync rule
+type Checks struct {
+	mu     sync.RWMutex
+	checks map[string]healthz.Checker
+}
+
+func NewChecks() *Checks {
+	return &Checks{
+		checks: make(map[string]healthz.Checker),
+	}
+}
+
+// Add adds the check with the name, a check added with the same name is replaced
+func (c *Checks) Add(name string, check healthz.Checker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks[name] = check
+}
+
+func (c *Checks) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.checks, name)
+}
+
+// ServeHTTP runs every check on the root path and a single check on the subpath of its name
+func (c *Checks) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	c.mu.RLock()
+	checks := make(map[string]healthz.Checker, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	(&healthz.Handler{Checks: checks}).ServeHTTP(w, req)
+}
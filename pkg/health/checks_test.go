@@ -0,0 +1,66 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/health"
+)
+
+func TestChecks(t *testing.T) {
+	t.Parallel()
+
+	checks := health.NewChecks()
+	checks.Add("ping", healthz.Ping)
+
+	get := func(path string) (int, string) {
+		rec := httptest.NewRecorder()
+		checks.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return rec.Code, rec.Body.String()
+	}
+
+	if code, _ := get("/"); code != http.StatusOK {
+		t.Fatalf("unexpected status code %d", code)
+	}
+
+	// checks added while served are run
+	checks.Add("rule/invalid", func(_ *http.Request) error {
+		return errors.New("no matches for kind")
+	})
+	code, body := get("/")
+	if code != http.StatusInternalServerError || !strings.Contains(body, "[-]rule/invalid failed") || !strings.Contains(body, "[+]ping ok") {
+		t.Fatalf("unexpected response %d: %s", code, body)
+	}
+	code, body = get("/rule/invalid")
+	if code != http.StatusInternalServerError || !strings.Contains(body, "no matches for kind") {
+		t.Fatalf("unexpected response %d: %s", code, body)
+	}
+
+	checks.Remove("rule/invalid")
+	if code, body := get("/"); code != http.StatusOK {
+		t.Fatalf("unexpected response %d: %s", code, body)
+	}
+	if code, _ := get("/rule/invalid"); code != http.StatusNotFound {
+		t.Fatalf("removed check is served: %d", code)
+	}
+}
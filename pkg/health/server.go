@@ -0,0 +1,115 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+)
+
+const (
+	LivenessEndpoint  = "/healthz"
+	ReadinessEndpoint = "/readyz"
+
+	shutdownTimeout = time.Second * 5
+)
+
+// Server serves the liveness and readiness checks in place of the health probe server of the controller manager,
+// whose checks can not change once it is started
+type Server struct {
+	addr    string
+	log     logr.Logger
+	healthz *Checks
+	readyz  *Checks
+}
+
+type ServerOption func(s *Server)
+
+func WithLogger(log logr.Logger) ServerOption {
+	return func(s *Server) {
+		s.log = log
+	}
+}
+
+func NewServer(addr string, opts ...ServerOption) *Server {
+	s := &Server{
+		addr:    addr,
+		log:     logr.Discard(),
+		healthz: NewChecks(),
+		readyz:  NewChecks(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Healthz returns the liveness checks
+func (s *Server) Healthz() *Checks {
+	return s.healthz
+}
+
+// Readyz returns the readiness checks
+func (s *Server) Readyz() *Checks {
+	return s.readyz
+}
+
+// NeedLeaderElection makes the server run on every replica
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the checks until the context is cancelled
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(LivenessEndpoint, http.StripPrefix(LivenessEndpoint, s.healthz))
+	mux.Handle(LivenessEndpoint+"/", http.StripPrefix(LivenessEndpoint, s.healthz))
+	mux.Handle(ReadinessEndpoint, http.StripPrefix(ReadinessEndpoint, s.readyz))
+	mux.Handle(ReadinessEndpoint+"/", http.StripPrefix(ReadinessEndpoint, s.readyz))
+
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not listen for health probes", "address", s.addr)
+	}
+
+	server := &http.Server{
+		Handler: mux,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.log.Error(err, "could not stop health probe server")
+		}
+	}()
+
+	s.log.Info("starting health probe server", "address", listener.Addr().String())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.WrapIf(err, "could not serve health probes")
+	}
+
+	return nil
+}
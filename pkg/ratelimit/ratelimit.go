@@ -17,27 +17,40 @@ package ratelimit
 import (
 	"emperror.dev/errors"
 	"github.com/throttled/throttled"
-	"github.com/throttled/throttled/store/memstore"
 )
 
 var defaultRateQuota = throttled.RateQuota{MaxRate: throttled.PerSec(1), MaxBurst: 0}
 
-func NewRateLimiter(maxKeys int, quota *throttled.RateQuota) (throttled.RateLimiter, error) {
-	var rateLimiter *throttled.GCRARateLimiter
+// RateLimiter is a GCRA rate limiter which reports the number of keys it tracks
+type RateLimiter struct {
+	*throttled.GCRARateLimiter
 
-	store, err := memstore.New(maxKeys)
+	store *Store
+}
+
+// NewRateLimiter creates a rate limiter tracking at most maxKeys keys, DefaultMaxKeys is used if it is not positive
+func NewRateLimiter(maxKeys int, quota *throttled.RateQuota) (*RateLimiter, error) {
+	store, err := NewStore(maxKeys)
 	if err != nil {
-		return nil, errors.WrapIf(err, "could not create memstore for rate limit")
+		return nil, errors.WrapIf(err, "could not create store for rate limit")
 	}
 
 	if quota == nil {
 		quota = &defaultRateQuota
 	}
 
-	rateLimiter, err = throttled.NewGCRARateLimiter(store, *quota)
+	rateLimiter, err := throttled.NewGCRARateLimiter(store, *quota)
 	if err != nil {
 		return nil, errors.WrapIf(err, "could not create rate limiter")
 	}
 
-	return rateLimiter, nil
+	return &RateLimiter{
+		GCRARateLimiter: rateLimiter,
+		store:           store,
+	}, nil
+}
+
+// KeyCount returns the number of keys which are not expired yet
+func (l *RateLimiter) KeyCount() int {
+	return l.store.Len()
 }
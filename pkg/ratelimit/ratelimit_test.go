@@ -0,0 +1,116 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/throttled/throttled"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/ratelimit"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	store, err := ratelimit.NewStore(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := store.SetIfNotExistsWithTTL("a", 1, 0); err != nil || !ok {
+		t.Fatalf("key is not set: %v", err)
+	}
+	if ok, _ := store.SetIfNotExistsWithTTL("a", 2, 0); ok {
+		t.Fatalf("existing key is overwritten")
+	}
+	if ok, _ := store.CompareAndSwapWithTTL("a", 2, 3, 0); ok {
+		t.Fatalf("key is swapped with a different old value")
+	}
+	if ok, _ := store.CompareAndSwapWithTTL("a", 1, 3, 0); !ok {
+		t.Fatalf("key is not swapped")
+	}
+	if value, _, _ := store.GetWithTime("a"); value != 3 {
+		t.Fatalf("unexpected value: %d", value)
+	}
+	if value, _, _ := store.GetWithTime("missing"); value != -1 {
+		t.Fatalf("unexpected value of missing key: %d", value)
+	}
+
+	// the least recently used key is evicted
+	_, _ = store.SetIfNotExistsWithTTL("b", 1, 0)
+	_, _, _ = store.GetWithTime("a")
+	_, _ = store.SetIfNotExistsWithTTL("c", 1, 0)
+	if value, _, _ := store.GetWithTime("b"); value != -1 {
+		t.Fatalf("least recently used key is kept")
+	}
+	if store.Len() != 2 {
+		t.Fatalf("unexpected number of keys: %d", store.Len())
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	t.Parallel()
+
+	store, err := ratelimit.NewStore(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = store.SetIfNotExistsWithTTL("expiring", 1, time.Millisecond*10)
+	_, _ = store.SetIfNotExistsWithTTL("kept", 1, time.Hour)
+	if store.Len() != 2 {
+		t.Fatalf("unexpected number of keys: %d", store.Len())
+	}
+
+	time.Sleep(time.Millisecond * 20)
+
+	if value, _, _ := store.GetWithTime("expiring"); value != -1 {
+		t.Fatalf("expired key is kept")
+	}
+	if ok, _ := store.SetIfNotExistsWithTTL("expiring", 2, time.Hour); !ok {
+		t.Fatalf("expired key is not set again")
+	}
+
+	_, _ = store.SetIfNotExistsWithTTL("expiring-again", 1, time.Millisecond*10)
+	time.Sleep(time.Millisecond * 20)
+	if store.Len() != 2 {
+		t.Fatalf("expired keys are counted: %d", store.Len())
+	}
+}
+
+func TestRateLimiterKeyCount(t *testing.T) {
+	t.Parallel()
+
+	rl, err := ratelimit.NewRateLimiter(10, &throttled.RateQuota{
+		MaxRate:  throttled.PerSec(5),
+		MaxBurst: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if _, _, err := rl.RateLimit(fmt.Sprintf("default/object-%d", i), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if count := rl.KeyCount(); count != 10 {
+		t.Fatalf("unexpected number of keys: %d", count)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// DefaultMaxKeys is the number of keys kept by a store created without a positive limit
+const DefaultMaxKeys = 1024
+
+// Store is an in-memory GCRA store, which keeps at most a fixed number of keys by evicting the least recently used
+// ones, and forgets the keys whose TTL expired. Unlike the memstore of throttled it is never unbounded and honors the
+// TTL, so the keys of objects which are not reconciled anymore do not pile up.
+type Store struct {
+	keys *lru.Cache
+
+	mu sync.Mutex
+}
+
+type storeEntry struct {
+	value     int64
+	expiresAt time.Time
+}
+
+func NewStore(maxKeys int) (*Store, error) {
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+
+	keys, err := lru.New(maxKeys)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not create lru cache")
+	}
+
+	return &Store{
+		keys: keys,
+	}, nil
+}
+
+// GetWithTime returns the value of the key or -1 if it does not exist or is expired
+func (s *Store) GetWithTime(key string) (int64, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	entry, ok := s.get(key, now)
+	if !ok {
+		return -1, now, nil
+	}
+
+	return entry.value, now, nil
+}
+
+// SetIfNotExistsWithTTL sets the value of the key only if it does not exist or is expired
+func (s *Store) SetIfNotExistsWithTTL(key string, value int64, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if _, ok := s.get(key, now); ok {
+		return false, nil
+	}
+
+	s.set(key, value, ttl, now)
+
+	return true, nil
+}
+
+// CompareAndSwapWithTTL sets the value of the key to the new value if its current value is the old one
+func (s *Store) CompareAndSwapWithTTL(key string, old, new int64, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	entry, ok := s.get(key, now)
+	if !ok || entry.value != old {
+		return false, nil
+	}
+
+	s.set(key, new, ttl, now)
+
+	return true, nil
+}
+
+// Len removes the expired keys and returns the number of the remaining ones
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range s.keys.Keys() {
+		if value, ok := s.keys.Peek(key); ok && value.(storeEntry).expired(now) {
+			s.keys.Remove(key)
+		}
+	}
+
+	return s.keys.Len()
+}
+
+func (s *Store) get(key string, now time.Time) (storeEntry, bool) {
+	value, ok := s.keys.Get(key)
+	if !ok {
+		return storeEntry{}, false
+	}
+
+	entry := value.(storeEntry)
+	if entry.expired(now) {
+		s.keys.Remove(key)
+
+		return storeEntry{}, false
+	}
+
+	return entry, true
+}
+
+func (s *Store) set(key string, value int64, ttl time.Duration, now time.Time) {
+	entry := storeEntry{
+		value: value,
+	}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+
+	s.keys.Add(key, entry)
+}
+
+func (e storeEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
@@ -0,0 +1,294 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rulebuilder
+
+import (
+	"emperror.dev/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// Builder builds a ResourceSyncRule. The match and mutation methods apply to the current sync rule of the spec, which
+// is the first one until NextRule is called, and the match methods to its current match, which is the first one until
+// OrMatch is called. The errors of the methods and of the validation of the rule are returned by Build.
+type Builder struct {
+	rule *clusterregistryv1alpha1.ResourceSyncRule
+	opts []ValidateOption
+	errs []error
+}
+
+// New returns a builder of the named rule, the options are used to validate the built rule
+func New(name string, opts ...ValidateOption) *Builder {
+	return &Builder{
+		rule: &clusterregistryv1alpha1.ResourceSyncRule{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: clusterregistryv1alpha1.GroupVersion.String(),
+				Kind:       "ResourceSyncRule",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				Rules: []clusterregistryv1alpha1.SyncRule{{}},
+			},
+		},
+		opts: opts,
+	}
+}
+
+// WithGVK sets the kind synced by the rule, the version can be * to sync any version of the kind
+func (b *Builder) WithGVK(apiVersion, kind string) *Builder {
+	gvk, err := parseGVK(apiVersion, kind)
+	if err != nil {
+		b.errs = append(b.errs, errors.WrapIf(err, "groupVersionKind"))
+	}
+	b.rule.Spec.GVK = gvk
+
+	return b
+}
+
+// WithClusterFeature only syncs from the clusters having the feature with the labels
+func (b *Builder) WithClusterFeature(name string, matchLabels map[string]string) *Builder {
+	b.rule.Spec.ClusterFeatureMatches = append(b.rule.Spec.ClusterFeatureMatches, clusterregistryv1alpha1.ClusterFeatureMatch{
+		FeatureName: name,
+		MatchLabels: matchLabels,
+	})
+
+	return b
+}
+
+// WithSpec changes any other field of the spec
+func (b *Builder) WithSpec(f func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec)) *Builder {
+	f(&b.rule.Spec)
+
+	return b
+}
+
+// NextRule starts a new sync rule, the objects matched by any of the sync rules are synced
+func (b *Builder) NextRule() *Builder {
+	b.rule.Spec.Rules = append(b.rule.Spec.Rules, clusterregistryv1alpha1.SyncRule{})
+
+	return b
+}
+
+// OrMatch starts a new match of the current sync rule, the objects matched by any of the matches are synced
+func (b *Builder) OrMatch() *Builder {
+	syncRule := b.currentRule()
+	syncRule.Matches = append(syncRule.Matches, clusterregistryv1alpha1.SyncRuleMatch{})
+
+	return b
+}
+
+// MatchNamespace matches the objects in any of the namespaces
+func (b *Builder) MatchNamespace(namespaces ...string) *Builder {
+	match := b.currentMatch()
+	match.Namespaces = append(match.Namespaces, namespaces...)
+
+	return b
+}
+
+// MatchObjectKey matches the object with the name in the namespace
+func (b *Builder) MatchObjectKey(namespace, name string) *Builder {
+	match := b.currentMatch()
+	match.ObjectKey.Namespace = namespace
+	match.ObjectKey.Name = name
+
+	return b
+}
+
+// MatchLabels matches the objects having every label
+func (b *Builder) MatchLabels(labels map[string]string) *Builder {
+	match := b.currentMatch()
+	match.Labels = append(match.Labels, metav1.LabelSelector{
+		MatchLabels: labels,
+	})
+
+	return b
+}
+
+// MatchLabelSelector matches the objects selected by the label selector, e.g. "app in (a, b), !canary"
+func (b *Builder) MatchLabelSelector(selector string) *Builder {
+	labelSelector, err := metav1.ParseToLabelSelector(selector)
+	if err != nil {
+		b.errs = append(b.errs, errors.WrapIff(err, "could not parse label selector %q", selector))
+
+		return b
+	}
+
+	match := b.currentMatch()
+	match.Labels = append(match.Labels, *labelSelector)
+
+	return b
+}
+
+// MatchAnnotations matches the objects having every annotation
+func (b *Builder) MatchAnnotations(annotations map[string]string) *Builder {
+	match := b.currentMatch()
+	match.Annotations = append(match.Annotations, clusterregistryv1alpha1.AnnotationSelector{
+		MatchAnnotations: annotations,
+	})
+
+	return b
+}
+
+// MutateAnnotations adds and removes annotations of the synced objects
+func (b *Builder) MutateAnnotations(add map[string]string, remove ...string) *Builder {
+	mutations := &b.currentRule().Mutations
+	if mutations.Annotations == nil {
+		mutations.Annotations = &clusterregistryv1alpha1.AnnotationMutations{}
+	}
+	mutations.Annotations.Add = mergeMaps(mutations.Annotations.Add, add)
+	mutations.Annotations.Remove = append(mutations.Annotations.Remove, remove...)
+
+	return b
+}
+
+// MutateLabels adds and removes labels of the synced objects
+func (b *Builder) MutateLabels(add map[string]string, remove ...string) *Builder {
+	mutations := &b.currentRule().Mutations
+	if mutations.Labels == nil {
+		mutations.Labels = &clusterregistryv1alpha1.LabelMutations{}
+	}
+	mutations.Labels.Add = mergeMaps(mutations.Labels.Add, add)
+	mutations.Labels.Remove = append(mutations.Labels.Remove, remove...)
+
+	return b
+}
+
+// RemoveAnnotationsMatching removes the annotations of the synced objects with any of the key prefixes or keys matching
+// any of the RE2 regular expressions
+func (b *Builder) RemoveAnnotationsMatching(prefixes []string, regexes []string) *Builder {
+	mutations := &b.currentRule().Mutations
+	if mutations.Annotations == nil {
+		mutations.Annotations = &clusterregistryv1alpha1.AnnotationMutations{}
+	}
+	mutations.Annotations.RemovePrefixes = append(mutations.Annotations.RemovePrefixes, prefixes...)
+	mutations.Annotations.RemoveRegexes = append(mutations.Annotations.RemoveRegexes, regexes...)
+
+	return b
+}
+
+// RemoveLabelsMatching removes the labels of the synced objects with any of the key prefixes or keys matching any of
+// the RE2 regular expressions
+func (b *Builder) RemoveLabelsMatching(prefixes []string, regexes []string) *Builder {
+	mutations := &b.currentRule().Mutations
+	if mutations.Labels == nil {
+		mutations.Labels = &clusterregistryv1alpha1.LabelMutations{}
+	}
+	mutations.Labels.RemovePrefixes = append(mutations.Labels.RemovePrefixes, prefixes...)
+	mutations.Labels.RemoveRegexes = append(mutations.Labels.RemoveRegexes, regexes...)
+
+	return b
+}
+
+// MutateGVK syncs the objects as another kind, the empty parts of the GVK are not changed
+func (b *Builder) MutateGVK(apiVersion, kind string) *Builder {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		b.errs = append(b.errs, errors.WrapIff(err, "could not parse apiVersion %q", apiVersion))
+
+		return b
+	}
+
+	b.currentRule().Mutations.GVK = &resources.GroupVersionKind{
+		Group:   gv.Group,
+		Version: gv.Version,
+		Kind:    kind,
+	}
+
+	return b
+}
+
+// Overlay adds an overlay patch to the synced objects, the value is not used by the delete patches
+func (b *Builder) Overlay(patchType resources.OverlayPatchType, path string, value string) *Builder {
+	patch := resources.K8SResourceOverlayPatch{
+		Type: patchType,
+		Path: pointer.StringPtr(path),
+	}
+	if patchType != resources.DeleteOverlayPatchType {
+		patch.Value = pointer.StringPtr(value)
+	}
+
+	mutations := &b.currentRule().Mutations
+	mutations.Overrides = append(mutations.Overrides, patch)
+
+	return b
+}
+
+// SyncStatus syncs the status of the objects too
+func (b *Builder) SyncStatus() *Builder {
+	b.currentRule().Mutations.SyncStatus = true
+
+	return b
+}
+
+// Build returns the rule or the errors of the builder and of its validation combined
+func (b *Builder) Build() (*clusterregistryv1alpha1.ResourceSyncRule, error) {
+	if err := errors.Combine(append(b.errs, Validate(b.rule, b.opts...))...); err != nil {
+		return nil, err
+	}
+
+	return b.rule.DeepCopy(), nil
+}
+
+func (b *Builder) currentRule() *clusterregistryv1alpha1.SyncRule {
+	return &b.rule.Spec.Rules[len(b.rule.Spec.Rules)-1]
+}
+
+func (b *Builder) currentMatch() *clusterregistryv1alpha1.SyncRuleMatch {
+	syncRule := b.currentRule()
+	if len(syncRule.Matches) == 0 {
+		syncRule.Matches = append(syncRule.Matches, clusterregistryv1alpha1.SyncRuleMatch{})
+	}
+
+	return &syncRule.Matches[len(syncRule.Matches)-1]
+}
+
+func parseGVK(apiVersion, kind string) (resources.GroupVersionKind, error) {
+	// the any version is not a valid version for the parser
+	if apiVersion == clusterregistryv1alpha1.AnyVersion {
+		return resources.GroupVersionKind{Version: apiVersion, Kind: kind}, nil
+	}
+
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return resources.GroupVersionKind{}, errors.WrapIff(err, "could not parse apiVersion %q", apiVersion)
+	}
+
+	return resources.GroupVersionKind{
+		Group:   gv.Group,
+		Version: gv.Version,
+		Kind:    kind,
+	}, nil
+}
+
+func mergeMaps(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+
+	return dst
+}
@@ -0,0 +1,268 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rulebuilder_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/rulebuilder"
+)
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	rule, err := rulebuilder.New("istio-secrets").
+		WithGVK("v1", "Secret").
+		MatchNamespace("istio-system").
+		MatchLabelSelector("istio/multiCluster=true").
+		MutateAnnotations(map[string]string{"synced": "true"}, "kubectl.kubernetes.io/last-applied-configuration").
+		Overlay(resources.ReplaceOverlayPatchType, "/metadata/labels/origin", "remote").
+		NextRule().
+		MatchObjectKey("default", "shared").
+		SyncStatus().
+		Build()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	if rule.Kind != "ResourceSyncRule" || rule.APIVersion != clusterregistryv1alpha1.GroupVersion.String() {
+		t.Fatalf("unexpected type %s", rule.GroupVersionKind())
+	}
+	if rule.Spec.GVK != (resources.GroupVersionKind{Version: "v1", Kind: "Secret"}) {
+		t.Fatalf("unexpected kind %+v", rule.Spec.GVK)
+	}
+	if len(rule.Spec.Rules) != 2 {
+		t.Fatalf("expected 2 sync rules, got %d", len(rule.Spec.Rules))
+	}
+
+	match := rule.Spec.Rules[0].Matches[0]
+	if len(match.Namespaces) != 1 || len(match.Labels) != 1 || match.Labels[0].MatchLabels["istio/multiCluster"] != "true" {
+		t.Fatalf("unexpected match %+v", match)
+	}
+	if mutations := rule.Spec.Rules[0].Mutations; mutations.Annotations.Add["synced"] != "true" || len(mutations.Overrides) != 1 {
+		t.Fatalf("unexpected mutations %+v", mutations)
+	}
+	if syncRule := rule.Spec.Rules[1]; syncRule.Matches[0].ObjectKey.Name != "shared" || !syncRule.Mutations.SyncStatus {
+		t.Fatalf("unexpected sync rule %+v", syncRule)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	tests := map[string]struct {
+		builder *rulebuilder.Builder
+		// errors contains a part of each expected error
+		errors []string
+	}{
+		"valid rule of any version": {
+			builder: rulebuilder.New("valid").WithGVK(clusterregistryv1alpha1.AnyVersion, "Secret"),
+		},
+		"invalid name": {
+			builder: rulebuilder.New("Invalid_Name").WithGVK("v1", "Secret"),
+			errors:  []string{"invalid rule name"},
+		},
+		"missing kind": {
+			builder: rulebuilder.New("missing-kind"),
+			errors:  []string{"version and kind are required"},
+		},
+		"invalid apiVersion": {
+			builder: rulebuilder.New("invalid-api-version").WithGVK("a/b/c", "Secret"),
+			errors:  []string{"could not parse apiVersion", "version and kind are required"},
+		},
+		"every error": {
+			builder: rulebuilder.New("every-error", rulebuilder.WithScheme(scheme)).
+				WithGVK("v1", "Secret").
+				MatchLabelSelector("a in (").
+				Overlay(resources.ReplaceOverlayPatchType, "", "value").
+				MutateGVK("v1", "NotRegistered"),
+			errors: []string{
+				"could not parse label selector",
+				"rules[0].mutations.overrides[0]: path is required",
+				"rules[0].mutations.groupVersionKind: kind /v1, Kind=NotRegistered is not registered",
+			},
+		},
+		"invalid removed key regex": {
+			builder: rulebuilder.New("invalid-removed-key-regex").
+				WithGVK("v1", "Secret").
+				RemoveAnnotationsMatching([]string{"kubectl.kubernetes.io/"}, []string{`^operator\.io/(.*$`}),
+			errors: []string{"rules[0].mutations.annotations.removeRegexes[0]: invalid regular expression"},
+		},
+		"empty removed key prefix": {
+			builder: rulebuilder.New("empty-removed-key-prefix").
+				WithGVK("v1", "Secret").
+				RemoveLabelsMatching([]string{""}, nil),
+			errors: []string{"rules[0].mutations.labels.removePrefixes[0]: can not be empty"},
+		},
+		"invalid overlay template": {
+			builder: rulebuilder.New("invalid-template").
+				WithGVK("v1", "Secret").
+				Overlay(resources.ReplaceOverlayPatchType, "/data/key", "{{ .Missing"),
+			errors: []string{"could not parse value template"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rule, err := test.builder.Build()
+			if len(test.errors) == 0 {
+				if err != nil {
+					t.Fatalf("%+v", err)
+				}
+
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors, got rule %+v", rule)
+			}
+
+			if errs := errors.GetErrors(err); len(errs) != len(test.errors) {
+				t.Fatalf("expected %d errors, got %d: %s", len(test.errors), len(errs), err)
+			}
+			for _, msg := range test.errors {
+				if !strings.Contains(err.Error(), msg) {
+					t.Fatalf("expected error %q in %q", msg, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	if err := rulebuilder.Validate(nil); err == nil {
+		t.Fatal("expected error for nil rule")
+	}
+
+	rule, err := rulebuilder.New("rule").WithGVK("v1", "ConfigMap").Build()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := rulebuilder.Validate(rule); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	rule.Spec.Versions = []string{"v1"}
+	if err := rulebuilder.Validate(rule); err == nil || !strings.Contains(err.Error(), "versions:") {
+		t.Fatalf("expected versions error, got %v", err)
+	}
+
+	rule.Spec.Versions = nil
+	rule.Spec.SyncWindow = &clusterregistryv1alpha1.SyncWindow{
+		Schedule: "0 25 * * *",
+		Duration: metav1.Duration{Duration: time.Hour},
+	}
+	if err := rulebuilder.Validate(rule); err == nil || !strings.Contains(err.Error(), "syncWindow.schedule:") {
+		t.Fatalf("expected sync window error, got %v", err)
+	}
+}
+
+func TestValidateNamespacedSpec(t *testing.T) {
+	t.Parallel()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+
+	tests := map[string]struct {
+		spec  clusterregistryv1alpha1.ResourceSyncRuleSpec
+		error string
+	}{
+		"namespaced kind": {
+			spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				GVK: resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+			},
+		},
+		"unknown kind": {
+			spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				GVK: resources.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"},
+			},
+		},
+		"cluster scoped kind": {
+			spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				GVK: resources.GroupVersionKind{Version: "v1", Kind: "Namespace"},
+			},
+			error: "groupVersionKind: cluster scoped kind Namespace can not be synced by namespaced rules",
+		},
+		"cluster scoped mutated kind": {
+			spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				GVK: resources.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"},
+				Rules: []clusterregistryv1alpha1.SyncRule{
+					{
+						Mutations: clusterregistryv1alpha1.Mutations{
+							GVK: &resources.GroupVersionKind{Kind: "ClusterRole"},
+						},
+					},
+				},
+			},
+			error: "rules[0].mutations.groupVersionKind: cluster scoped kind ClusterRole.rbac.authorization.k8s.io",
+		},
+		"anchor ownership": {
+			spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				GVK:             resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				AnchorOwnership: true,
+			},
+			error: "anchorOwnership: can not be used by namespaced rules",
+		},
+		"service account": {
+			spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				GVK:                resources.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				ServiceAccountName: "syncer",
+			},
+			error: "serviceAccountName: can not be used by namespaced rules",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := rulebuilder.ValidateNamespacedSpec(test.spec, rulebuilder.WithRESTMapper(mapper))
+			if test.error == "" {
+				if err != nil {
+					t.Fatalf("%+v", err)
+				}
+
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.error) {
+				t.Fatalf("expected error %q, got %v", test.error, err)
+			}
+		})
+	}
+}
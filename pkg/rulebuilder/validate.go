@@ -0,0 +1,277 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rulebuilder
+
+import (
+	"strings"
+	"text/template"
+
+	"emperror.dev/errors"
+	"github.com/Masterminds/sprig"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+type validator struct {
+	scheme *runtime.Scheme
+	mapper meta.RESTMapper
+}
+
+// ValidateOption configures optional checks of the validation
+type ValidateOption func(v *validator)
+
+// WithScheme checks that the kinds the objects are synced as by GVK mutations are registered in the scheme, the
+// controller can only write those kinds
+func WithScheme(scheme *runtime.Scheme) ValidateOption {
+	return func(v *validator) {
+		v.scheme = scheme
+	}
+}
+
+// WithRESTMapper checks the scope of the kinds of namespaced rules, the kinds unknown to the mapper are accepted, since
+// their CRDs may be installed later
+func WithRESTMapper(mapper meta.RESTMapper) ValidateOption {
+	return func(v *validator) {
+		v.mapper = mapper
+	}
+}
+
+// Validate returns the errors of the rule the controller would reject it with, it does the same checks as the
+// admission webhook of the controller except for the ones depending on the other rules and the configuration of the
+// controller
+func Validate(rule *clusterregistryv1alpha1.ResourceSyncRule, opts ...ValidateOption) error {
+	if rule == nil {
+		return errors.New("rule is required")
+	}
+
+	var errs []error
+	if rule.GetName() == "" {
+		errs = append(errs, errors.New("name: is required"))
+	} else if msgs := validation.IsDNS1123Subdomain(rule.GetName()); len(msgs) > 0 {
+		errs = append(errs, errors.Errorf("name: invalid rule name %q: %s", rule.GetName(), strings.Join(msgs, ", ")))
+	}
+
+	return errors.Combine(append(errs, ValidateSpec(rule.Spec, opts...))...)
+}
+
+// ValidateSpec returns the errors of the spec the admission webhook of the controller would reject it with, each
+// invalid field is reported
+func ValidateSpec(spec clusterregistryv1alpha1.ResourceSyncRuleSpec, opts ...ValidateOption) error {
+	v := &validator{}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	var errs []error
+	if err := validateGVK(spec.GVK, true); err != nil {
+		errs = append(errs, errors.WrapIf(err, "groupVersionKind"))
+	}
+
+	if err := spec.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// the rest of the sync window is checked by the spec, the schedule is parsed the same way the controller does
+	if spec.SyncWindow != nil && spec.SyncWindow.Schedule != "" {
+		if _, err := cron.ParseStandard(spec.SyncWindow.Schedule); err != nil {
+			errs = append(errs, errors.WrapIf(err, "syncWindow.schedule"))
+		}
+	}
+
+	for i, match := range spec.ClusterFeatureMatches {
+		if _, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+			MatchLabels:      match.MatchLabels,
+			MatchExpressions: match.MatchExpressions,
+		}); err != nil {
+			errs = append(errs, errors.WrapIff(err, "clusterFeatureMatch[%d]", i))
+		}
+	}
+
+	for i, rule := range spec.Rules {
+		for j, match := range rule.Matches {
+			for k := range match.Labels {
+				if _, err := metav1.LabelSelectorAsSelector(&match.Labels[k]); err != nil {
+					errs = append(errs, errors.WrapIff(err, "rules[%d].match[%d].labels[%d]", i, j, k))
+				}
+			}
+		}
+
+		if rule.Mutations.GVK != nil {
+			if err := v.validateMutationGVK(spec.GVK, rule); err != nil {
+				errs = append(errs, errors.WrapIff(err, "rules[%d].mutations.groupVersionKind", i))
+			}
+		}
+
+		for j, patch := range rule.Mutations.Overrides {
+			if err := validateOverlayPatch(spec.GVK, patch); err != nil {
+				errs = append(errs, errors.WrapIff(err, "rules[%d].mutations.overrides[%d]", i, j))
+			}
+		}
+	}
+
+	return errors.Combine(errs...)
+}
+
+// ValidateNamespacedSpec returns the errors of the spec of a namespaced rule the admission webhook of the controller
+// would reject it with, the namespaced rules can not use the fields reaching out of their namespace or sync cluster
+// scoped kinds
+func ValidateNamespacedSpec(spec clusterregistryv1alpha1.ResourceSyncRuleSpec, opts ...ValidateOption) error {
+	v := &validator{}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	errs := []error{ValidateSpec(spec, opts...)}
+	if err := spec.ValidateNamespaced(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := v.validateNamespacedScope(schema.GroupVersionKind(spec.GVK)); err != nil {
+		errs = append(errs, errors.WrapIf(err, "groupVersionKind"))
+	}
+
+	for i, rule := range spec.Rules {
+		if rule.Mutations.GVK == nil {
+			continue
+		}
+
+		_, gvk := clusterregistryv1alpha1.MatchedRules{rule}.GetMutatedGVK(schema.GroupVersionKind(spec.GVK))
+		if err := v.validateNamespacedScope(gvk); err != nil {
+			errs = append(errs, errors.WrapIff(err, "rules[%d].mutations.groupVersionKind", i))
+		}
+	}
+
+	return errors.Combine(errs...)
+}
+
+// validateNamespacedScope returns an error if the kind is cluster scoped according to the mapper
+func (v *validator) validateNamespacedScope(gvk schema.GroupVersionKind) error {
+	if v.mapper == nil {
+		return nil
+	}
+
+	var versions []string
+	if gvk.Version != clusterregistryv1alpha1.AnyVersion {
+		versions = append(versions, gvk.Version)
+	}
+
+	mapping, err := v.mapper.RESTMapping(gvk.GroupKind(), versions...)
+	if meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not get rest mapping", "gvk", gvk.String())
+	}
+
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		return errors.Errorf("cluster scoped kind %s can not be synced by namespaced rules", gvk.GroupKind())
+	}
+
+	return nil
+}
+
+func (v *validator) validateMutationGVK(ruleGVK resources.GroupVersionKind, rule clusterregistryv1alpha1.SyncRule) error {
+	if err := validateGVK(*rule.Mutations.GVK, false); err != nil {
+		return err
+	}
+
+	if v.scheme == nil {
+		return nil
+	}
+
+	_, gvk := clusterregistryv1alpha1.MatchedRules{rule}.GetMutatedGVK(schema.GroupVersionKind(ruleGVK))
+	if gvk.Version != clusterregistryv1alpha1.AnyVersion {
+		if !v.scheme.Recognizes(gvk) {
+			return errors.Errorf("kind %s is not registered", gvk)
+		}
+
+		return nil
+	}
+
+	for _, gv := range v.scheme.VersionsForGroupKind(gvk.GroupKind()) {
+		if v.scheme.Recognizes(gv.WithKind(gvk.Kind)) {
+			return nil
+		}
+	}
+
+	return errors.Errorf("kind %s is not registered", gvk.GroupKind())
+}
+
+// validateGVK returns an error if the specified GVK can not be parsed, the
+// version and kind are only required when the GVK is not partial.
+func validateGVK(gvk resources.GroupVersionKind, full bool) error {
+	if gvk.Group != "" {
+		if errs := validation.IsDNS1123Subdomain(gvk.Group); len(errs) > 0 {
+			return errors.Errorf("invalid group %q: %s", gvk.Group, strings.Join(errs, ", "))
+		}
+	}
+
+	if gvk.Version != "" && gvk.Version != clusterregistryv1alpha1.AnyVersion {
+		if errs := validation.IsDNS1035Label(gvk.Version); len(errs) > 0 {
+			return errors.Errorf("invalid version %q: %s", gvk.Version, strings.Join(errs, ", "))
+		}
+	}
+
+	if gvk.Kind != "" {
+		if errs := validation.IsDNS1035Label(strings.ToLower(gvk.Kind)); len(errs) > 0 || strings.Contains(gvk.Kind, "-") {
+			return errors.Errorf("invalid kind %q", gvk.Kind)
+		}
+	}
+
+	if full && (gvk.Version == "" || gvk.Kind == "") {
+		return errors.New("version and kind are required")
+	}
+
+	if !full && gvk.Group == "" && gvk.Version == "" && gvk.Kind == "" {
+		return errors.New("at least one of group, version and kind is required")
+	}
+
+	return nil
+}
+
+// validateOverlayPatch returns an error if the specified patch could not be
+// applied at runtime. Templated values are only rendered during syncing, so
+// only their templates are checked.
+func validateOverlayPatch(ruleGVK resources.GroupVersionKind, patch resources.K8SResourceOverlayPatch) error {
+	if patch.Path == nil || *patch.Path == "" {
+		return errors.New("path is required")
+	}
+
+	if patch.Value != nil && strings.Contains(*patch.Value, "{{") {
+		if _, err := template.New("").Funcs(sprig.TxtFuncMap()).Parse(*patch.Value); err != nil {
+			return errors.WrapIf(err, "could not parse value template")
+		}
+
+		return nil
+	}
+
+	gvk := ruleGVK
+	if _, err := resources.PatchYAMLModifier(resources.K8SResourceOverlay{
+		GVK:     &gvk,
+		Patches: []resources.K8SResourceOverlayPatch{patch},
+	}, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
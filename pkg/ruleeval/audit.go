@@ -0,0 +1,86 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruleeval
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// AuditVerification is the outcome of the verification of a synced object against the source object it was synced
+// from
+type AuditVerification struct {
+	// Verified is set if the synced object was written with exactly the desired state the rule syncs from the source
+	// object
+	Verified bool
+	// Reason tells why the object is not verified
+	Reason string
+	// ExpectedHash is the audit hash of the object the rule syncs from the source object
+	ExpectedHash string
+	// ActualHash is the audit hash recorded on the synced object
+	ActualHash string
+	// SourceResourceVersion is the resource version of the source object the synced object was written from
+	SourceResourceVersion string
+	// SyncedAt is the time the desired state of the synced object was written, zero if it is not recorded
+	SyncedAt time.Time
+}
+
+// VerifyAuditHash recomputes the audit hash of the object the rule syncs from the source object and compares it with
+// the audit hash recorded on the synced object, for audit jobs proving that what runs on a cluster is exactly what was
+// approved on the source cluster. The options must describe the clusters like the controller sees them, the
+// mutations can depend on them.
+func VerifyAuditHash(rule *clusterregistryv1alpha1.ResourceSyncRule, source *unstructured.Unstructured, synced client.Object, opts ...Option) (*AuditVerification, error) {
+	result, err := Evaluate(rule, source, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &AuditVerification{
+		ActualHash:            util.GetAuditHash(synced),
+		SourceResourceVersion: util.GetSourceResourceVersion(synced),
+	}
+	verification.SyncedAt, _ = util.GetSyncedAt(synced)
+
+	if !result.Matched {
+		verification.Reason = result.Reason
+
+		return verification, nil
+	}
+
+	verification.ExpectedHash, err = util.AuditHash(result.Object)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not compute audit hash")
+	}
+
+	switch {
+	case verification.ActualHash == "":
+		verification.Reason = "synced object has no audit hash, it was written without the audit trail"
+	case verification.ActualHash != verification.ExpectedHash:
+		verification.Reason = "synced object differs from the object the rule syncs from the source object"
+		if verification.SourceResourceVersion != source.GetResourceVersion() {
+			verification.Reason += ", it was written from another version of the source object"
+		}
+	default:
+		verification.Verified = true
+	}
+
+	return verification, nil
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruleeval_test
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/rulebuilder"
+	"github.com/cisco-open/cluster-registry-controller/pkg/ruleeval"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestVerifyAuditHash(t *testing.T) {
+	t.Parallel()
+
+	rule, err := rulebuilder.New("demo").
+		WithGVK("v1", "ConfigMap").
+		MatchNamespace("default").
+		MutateLabels(map[string]string{"copy": "true"}).
+		Build()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	cluster := &clusterregistryv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "remote"},
+		Spec:       clusterregistryv1alpha1.ClusterSpec{ClusterID: "remote-id"},
+	}
+
+	// synced returns the object the controller writes from the source object, with its audit annotations
+	synced := func(source *unstructured.Unstructured, mutate func(obj client.Object)) client.Object {
+		result, err := ruleeval.Evaluate(rule, source, ruleeval.WithClusters(cluster, nil))
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		hash, err := util.AuditHash(result.Object)
+		if err != nil {
+			t.Fatal(err)
+		}
+		util.SetAuditHash(result.Object, hash)
+		if mutate != nil {
+			mutate(result.Object)
+		}
+
+		return result.Object
+	}
+
+	changedSource := newConfigMap("default", nil)
+	changedSource.SetResourceVersion("43")
+	_ = unstructured.SetNestedStringMap(changedSource.Object, map[string]string{"key": "changed"}, "data")
+
+	tests := map[string]struct {
+		source   *unstructured.Unstructured
+		synced   client.Object
+		verified bool
+	}{
+		"verified": {
+			source:   newConfigMap("default", nil),
+			synced:   synced(newConfigMap("default", nil), nil),
+			verified: true,
+		},
+		"changed locally": {
+			source: newConfigMap("default", nil),
+			synced: synced(newConfigMap("default", nil), func(obj client.Object) {
+				obj.SetLabels(map[string]string{"copy": "false"})
+				hash, _ := util.AuditHash(obj)
+				util.SetAuditHash(obj, hash)
+			}),
+		},
+		"changed at the source": {
+			source: changedSource,
+			synced: synced(newConfigMap("default", nil), nil),
+		},
+		"written without audit trail": {
+			source: newConfigMap("default", nil),
+			synced: synced(newConfigMap("default", nil), func(obj client.Object) {
+				obj.SetAnnotations(nil)
+			}),
+		},
+		"not matched": {
+			source: newConfigMap("other", nil),
+			synced: synced(newConfigMap("default", nil), nil),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			verification, err := ruleeval.VerifyAuditHash(rule, test.source, test.synced, ruleeval.WithClusters(cluster, nil))
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if verification.Verified != test.verified {
+				t.Fatalf("expected verified %t, got %+v", test.verified, verification)
+			}
+			if !test.verified && verification.Reason == "" {
+				t.Fatalf("expected reason, got %+v", verification)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruleeval
+
+import (
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	objectsync "github.com/cisco-open/cluster-registry-controller/pkg/sync"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// DefaultClusterID is the ID and the name of the source cluster the objects are evaluated as synced from without
+// WithClusters
+const DefaultClusterID = "source-cluster"
+
+type evaluator struct {
+	scheme             *runtime.Scheme
+	clusterID          string
+	clusterName        string
+	cluster            *clusterregistryv1alpha1.Cluster
+	localCluster       *clusterregistryv1alpha1.Cluster
+	writeFormatVersion util.FormatVersion
+}
+
+// Option configures the evaluation
+type Option func(e *evaluator)
+
+// WithScheme sets the scheme of the local cluster, the scheme of client-go is used by default
+func WithScheme(scheme *runtime.Scheme) Option {
+	return func(e *evaluator) {
+		e.scheme = scheme
+	}
+}
+
+// WithClusters sets the source and the local clusters, the ID of the source cluster is recorded on the object and
+// both clusters are available to the templates of the mutations
+func WithClusters(cluster, localCluster *clusterregistryv1alpha1.Cluster) Option {
+	return func(e *evaluator) {
+		e.cluster = cluster
+		e.localCluster = localCluster
+		if cluster != nil {
+			e.clusterID = string(cluster.Spec.ClusterID)
+			e.clusterName = cluster.GetName()
+		}
+	}
+}
+
+// WithWriteFormatVersion sets the format version of the annotations, the previous version is used by default like
+// the controller does
+func WithWriteFormatVersion(version util.FormatVersion) Option {
+	return func(e *evaluator) {
+		e.writeFormatVersion = version
+	}
+}
+
+// MatchedRule is a sync rule of the spec matching the object
+type MatchedRule struct {
+	// Index of the sync rule in the spec
+	Index int
+	// Matches are the indexes of the matches of the sync rule matching the object, empty if the sync rule matches
+	// every object
+	Matches []int
+}
+
+// Result is the outcome of the evaluation of a rule on an object
+type Result struct {
+	Matched bool
+	// Reason tells why the object is not matched
+	Reason       string
+	MatchedRules []MatchedRule
+	// Object is the object as the sync reconciler would write it to the local cluster, only set if the object is
+	// matched
+	Object client.Object
+}
+
+// Evaluate returns whether the rule matches the object, which of its sync rules and matches do, and the object
+// mutated exactly as the sync reconcilers mutate it. The object is the source object as read from the source cluster.
+func Evaluate(rule *clusterregistryv1alpha1.ResourceSyncRule, obj *unstructured.Unstructured, opts ...Option) (*Result, error) {
+	e := &evaluator{
+		scheme:             clientgoscheme.Scheme,
+		clusterID:          DefaultClusterID,
+		clusterName:        DefaultClusterID,
+		writeFormatVersion: util.PreviousFormatVersion,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	source, err := e.toSourceObject(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	if !rule.Spec.MatchGVK(source.GetObjectKind().GroupVersionKind()) {
+		result.Reason = "kind is not synced by the rule"
+
+		return result, nil
+	}
+
+	ok, matchedRules, err := rule.Match(source)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not match object")
+	}
+	if !ok {
+		result.Reason = "no sync rule matches the object"
+		if !rule.Spec.IncludeSystemSecrets && clusterregistryv1alpha1.IsSystemSecret(source) {
+			result.Reason = "system secrets are not synced by the rule"
+		}
+
+		return result, nil
+	}
+
+	result.Matched = true
+	for i, syncRule := range rule.Spec.Rules {
+		matched, ok, err := getMatchedRule(i, syncRule, source)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result.MatchedRules = append(result.MatchedRules, matched)
+		}
+	}
+
+	mutated, err := objectsync.NewMutator(rule, e.scheme,
+		objectsync.WithClusterName(e.clusterName),
+		objectsync.WithWriteFormatVersion(e.writeFormatVersion),
+		objectsync.WithTemplateData(func(current client.Object, obj client.Object) (map[string]interface{}, error) {
+			return objectsync.NewTemplateData(obj, e.cluster, e.localCluster), nil
+		}),
+	).Mutate(source, matchedRules, e.clusterID)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not mutate object")
+	}
+
+	contentHash, err := util.ContentHash(mutated)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not compute content hash")
+	}
+	util.SetContentHash(mutated, contentHash)
+	result.Object = mutated
+
+	return result, nil
+}
+
+// toSourceObject returns the object typed like the sync reconciler reads it if the scheme knows its kind
+func (e *evaluator) toSourceObject(obj *unstructured.Unstructured) (client.Object, error) {
+	gvk := obj.GroupVersionKind()
+	if gvk.Empty() {
+		return nil, errors.New("apiVersion and kind of the object are required")
+	}
+
+	source := objectsync.NewObject(e.scheme, gvk)
+	if _, ok := source.(*unstructured.Unstructured); ok {
+		return obj.DeepCopy(), nil
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), source); err != nil {
+		return nil, errors.WrapIff(err, "could not convert object to %s", gvk)
+	}
+	source.GetObjectKind().SetGroupVersionKind(gvk)
+
+	return source, nil
+}
+
+func getMatchedRule(index int, syncRule clusterregistryv1alpha1.SyncRule, obj client.Object) (MatchedRule, bool, error) {
+	matched := MatchedRule{Index: index}
+	if len(syncRule.Matches) == 0 {
+		return matched, true, nil
+	}
+
+	for i, match := range syncRule.Matches {
+		ok, err := match.Match(obj)
+		if err != nil {
+			return matched, false, errors.WrapIff(err, "could not match rules[%d].match[%d]", index, i)
+		}
+		if ok {
+			matched.Matches = append(matched.Matches, i)
+		}
+	}
+
+	return matched, len(matched.Matches) > 0, nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ruleeval_test
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/rulebuilder"
+	"github.com/cisco-open/cluster-registry-controller/pkg/ruleeval"
+)
+
+func newConfigMap(namespace string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName("demo")
+	obj.SetNamespace(namespace)
+	obj.SetLabels(labels)
+	obj.SetUID("uid")
+	obj.SetResourceVersion("42")
+	obj.SetFinalizers([]string{"finalizer"})
+	obj.SetAnnotations(map[string]string{corev1.LastAppliedConfigAnnotation: "{}"})
+	_ = unstructured.SetNestedStringMap(obj.Object, map[string]string{"key": "value"}, "data")
+
+	return obj
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	rule, err := rulebuilder.New("demo").
+		WithGVK("v1", "ConfigMap").
+		MatchNamespace("other").
+		OrMatch().
+		MatchLabels(map[string]string{"app": "demo"}).
+		MutateAnnotations(map[string]string{"synced": "true"}).
+		Overlay(resources.ReplaceOverlayPatchType, "/data/key", "{{ .Object.GetName }}-{{ .Cluster.Spec.ClusterID }}").
+		NextRule().
+		MatchNamespace("default").
+		MutateLabels(map[string]string{"copy": "true"}).
+		Build()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	tests := map[string]struct {
+		obj          *unstructured.Unstructured
+		matched      bool
+		matchedRules []ruleeval.MatchedRule
+	}{
+		"matched by labels": {
+			obj:          newConfigMap("default", map[string]string{"app": "demo"}),
+			matched:      true,
+			matchedRules: []ruleeval.MatchedRule{{Index: 0, Matches: []int{1}}, {Index: 1, Matches: []int{0}}},
+		},
+		"matched by namespace": {
+			obj:          newConfigMap("other", nil),
+			matched:      true,
+			matchedRules: []ruleeval.MatchedRule{{Index: 0, Matches: []int{0}}},
+		},
+		"not matched": {
+			obj: newConfigMap("unknown", nil),
+		},
+	}
+
+	cluster := &clusterregistryv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "remote"},
+		Spec:       clusterregistryv1alpha1.ClusterSpec{ClusterID: "remote-id"},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := ruleeval.Evaluate(rule, test.obj, ruleeval.WithClusters(cluster, nil))
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if result.Matched != test.matched {
+				t.Fatalf("expected matched %t, got %t: %s", test.matched, result.Matched, result.Reason)
+			}
+			if !test.matched {
+				if result.Reason == "" || result.Object != nil {
+					t.Fatalf("unexpected result %+v", result)
+				}
+
+				return
+			}
+			if !reflect.DeepEqual(result.MatchedRules, test.matchedRules) {
+				t.Fatalf("expected matched rules %+v, got %+v", test.matchedRules, result.MatchedRules)
+			}
+
+			cm, ok := result.Object.(*corev1.ConfigMap)
+			if !ok {
+				t.Fatalf("expected typed ConfigMap, got %T", result.Object)
+			}
+			if cm.Data["key"] != "demo-remote-id" {
+				t.Fatalf("overlay patch is not applied: %+v", cm.Data)
+			}
+			if cm.Annotations["synced"] != "true" || cm.Annotations[clusterregistryv1alpha1.OwnershipAnnotation] != "remote-id" {
+				t.Fatalf("unexpected annotations %+v", cm.Annotations)
+			}
+			if _, ok := cm.Annotations[corev1.LastAppliedConfigAnnotation]; ok {
+				t.Fatal("last applied configuration is not stripped")
+			}
+			if cm.UID != "" || cm.ResourceVersion != "" || len(cm.Finalizers) > 0 {
+				t.Fatalf("server set metadata is not stripped: %+v", cm.ObjectMeta)
+			}
+		})
+	}
+}
+
+func TestEvaluateOtherKind(t *testing.T) {
+	t.Parallel()
+
+	rule, err := rulebuilder.New("secrets").WithGVK("v1", "Secret").Build()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	result, err := ruleeval.Evaluate(rule, newConfigMap("default", nil))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if result.Matched || result.Reason != "kind is not synced by the rule" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
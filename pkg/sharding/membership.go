@@ -0,0 +1,288 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// GroupLabel is set on the Leases of the members to the name of their group
+	GroupLabel = "cluster-registry.k8s.cisco.com/shard-group"
+
+	DefaultLeaseDuration = time.Second * 15
+)
+
+var ErrInvalidMembership = errors.New("invalid shard membership")
+
+// Membership keeps a Lease of the replica renewed and assigns the keys to the replicas whose Leases are not expired.
+// A replica which can not renew its Lease for a lease duration owns no keys, since the other replicas take over its
+// keys by then.
+type Membership struct {
+	client    client.Client
+	reader    client.Reader
+	namespace string
+	group     string
+	identity  string
+
+	leaseDuration time.Duration
+	log           logr.Logger
+
+	mu            sync.RWMutex
+	ring          *Ring
+	lastRenew     time.Time
+	onChangeFuncs []func()
+}
+
+type MembershipOption func(m *Membership)
+
+func WithLeaseDuration(duration time.Duration) MembershipOption {
+	return func(m *Membership) {
+		if duration > 0 {
+			m.leaseDuration = duration
+		}
+	}
+}
+
+func WithLogger(log logr.Logger) MembershipOption {
+	return func(m *Membership) {
+		m.log = log
+	}
+}
+
+// NewMembership creates the membership of the replica in the group, the Leases are written with the client and
+// listed with the reader, which should not be a cached one.
+func NewMembership(c client.Client, reader client.Reader, namespace, group, identity string, opts ...MembershipOption) (*Membership, error) {
+	if namespace == "" || group == "" || identity == "" {
+		return nil, errors.WithDetails(ErrInvalidMembership, "namespace", namespace, "group", group, "identity", identity)
+	}
+
+	m := &Membership{
+		client:    c,
+		reader:    reader,
+		namespace: namespace,
+		group:     group,
+		identity:  identity,
+
+		leaseDuration: DefaultLeaseDuration,
+		log:           logr.Discard(),
+		ring:          NewRing(),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m, nil
+}
+
+// Identity returns the name of the replica in the group
+func (m *Membership) Identity() string {
+	return m.identity
+}
+
+// Members returns the replicas the keys are currently assigned to
+func (m *Membership) Members() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.ring.Members()
+}
+
+// Owns returns whether the key is assigned to the replica
+func (m *Membership) Owns(key string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.ring.Owner(key) == m.identity
+}
+
+// AddOnChangeFunc registers a function called after the members changed
+func (m *Membership) AddOnChangeFunc(f func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.onChangeFuncs = append(m.onChangeFuncs, f)
+}
+
+// NeedLeaderElection makes the membership run on every replica
+func (m *Membership) NeedLeaderElection() bool {
+	return false
+}
+
+// Start renews the Lease of the replica and refreshes the members until the context is cancelled, then releases
+// the Lease so the keys of the replica move without waiting for it to expire
+func (m *Membership) Start(ctx context.Context) error {
+	// renewing three times per lease duration tolerates two failed renewals
+	ticker := time.NewTicker(m.leaseDuration / 3) // nolint:gomnd
+	defer ticker.Stop()
+
+	for {
+		m.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			m.release()
+
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Membership) refresh(ctx context.Context) {
+	now := time.Now()
+
+	if err := m.renew(ctx, now); err != nil {
+		m.log.Error(err, "could not renew shard lease")
+	} else {
+		m.mu.Lock()
+		m.lastRenew = now
+		m.mu.Unlock()
+	}
+
+	m.mu.RLock()
+	expired := now.Sub(m.lastRenew) >= m.leaseDuration
+	m.mu.RUnlock()
+	if expired {
+		// the other replicas consider the replica gone, so it must not own any key either, even if it can not list
+		// the members
+		m.setRing(NewRing())
+
+		return
+	}
+
+	members, err := m.listMembers(ctx, now)
+	if err != nil {
+		m.log.Error(err, "could not list shard members")
+
+		return
+	}
+
+	m.setRing(NewRing(members...))
+}
+
+func (m *Membership) setRing(ring *Ring) {
+	m.mu.Lock()
+	if m.ring.Equal(ring) {
+		m.mu.Unlock()
+
+		return
+	}
+	m.ring = ring
+	funcs := append([]func(){}, m.onChangeFuncs...)
+	m.mu.Unlock()
+
+	m.log.Info("shard members changed", "members", ring.Members(), "identity", m.identity)
+	for _, f := range funcs {
+		f()
+	}
+}
+
+func (m *Membership) renew(ctx context.Context, now time.Time) error {
+	lease := &coordinationv1.Lease{}
+	err := m.reader.Get(ctx, client.ObjectKey{Namespace: m.namespace, Name: m.leaseName()}, lease)
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      m.leaseName(),
+				Namespace: m.namespace,
+				Labels: map[string]string{
+					GroupLabel: m.group,
+				},
+			},
+		}
+		m.setLeaseSpec(lease, now)
+
+		return errors.WrapIfWithDetails(m.client.Create(ctx, lease), "could not create lease", "name", lease.GetName())
+	}
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "could not get lease", "name", m.leaseName())
+	}
+
+	m.setLeaseSpec(lease, now)
+
+	return errors.WrapIfWithDetails(m.client.Update(ctx, lease), "could not update lease", "name", lease.GetName())
+}
+
+func (m *Membership) setLeaseSpec(lease *coordinationv1.Lease, now time.Time) {
+	renewTime := metav1.NewMicroTime(now)
+	lease.Spec.HolderIdentity = pointer.StringPtr(m.identity)
+	lease.Spec.LeaseDurationSeconds = pointer.Int32Ptr(int32(m.leaseDuration.Seconds()))
+	lease.Spec.RenewTime = &renewTime
+	if lease.Spec.AcquireTime == nil {
+		lease.Spec.AcquireTime = &renewTime
+	}
+}
+
+// listMembers returns the holders of the Leases of the group which are not expired
+func (m *Membership) listMembers(ctx context.Context, now time.Time) ([]string, error) {
+	leases := &coordinationv1.LeaseList{}
+	err := m.reader.List(ctx, leases, client.InNamespace(m.namespace), client.MatchingLabels{GroupLabel: m.group})
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not list leases")
+	}
+
+	members := make([]string, 0, len(leases.Items))
+	for _, lease := range leases.Items {
+		if isLeaseExpired(lease, now) {
+			continue
+		}
+		members = append(members, *lease.Spec.HolderIdentity)
+	}
+
+	return members, nil
+}
+
+// release deletes the Lease of the replica, the context of the membership is already cancelled by then
+func (m *Membership) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.leaseDuration)
+	defer cancel()
+
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.leaseName(),
+			Namespace: m.namespace,
+		},
+	}
+	if err := m.client.Delete(ctx, lease); err != nil && !apierrors.IsNotFound(err) {
+		m.log.Error(err, "could not release shard lease")
+	}
+
+	m.setRing(NewRing())
+}
+
+func (m *Membership) leaseName() string {
+	return fmt.Sprintf("%s-%s", m.group, m.identity)
+}
+
+func isLeaseExpired(lease coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.HolderIdentity == nil || lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+
+	return !now.Before(lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second))
+}
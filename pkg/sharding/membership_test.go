@@ -0,0 +1,176 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharding_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/sharding"
+)
+
+const testLeaseDuration = time.Millisecond * 1500
+
+func newTestMembership(t *testing.T, c client.Client, identity string) *sharding.Membership {
+	t.Helper()
+
+	m, err := sharding.NewMembership(c, c, "cluster-registry", "cluster-registry-leader-election", identity, sharding.WithLeaseDuration(testLeaseDuration))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+func waitForMembers(t *testing.T, m *sharding.Membership, count int) {
+	t.Helper()
+
+	err := wait.PollImmediate(time.Millisecond*50, testLeaseDuration*4, func() (bool, error) {
+		return len(m.Members()) == count, nil
+	})
+	if err != nil {
+		t.Fatalf("%s has members %v, expected %d", m.Identity(), m.Members(), count)
+	}
+}
+
+func TestMembershipAssignsEveryKeyOnce(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	first := newTestMembership(t, c, "replica-0")
+	second := newTestMembership(t, c, "replica-1")
+
+	changes := make(chan struct{}, 10)
+	second.AddOnChangeFunc(func() {
+		changes <- struct{}{}
+	})
+
+	if first.Owns("cluster") || second.Owns("cluster") {
+		t.Fatal("expected no key to be owned before joining")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	firstCtx, stopFirst := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		_ = first.Start(firstCtx)
+		close(stopped)
+	}()
+	go func() {
+		_ = second.Start(ctx)
+	}()
+
+	waitForMembers(t, first, 2)
+	waitForMembers(t, second, 2)
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("cluster-%d", i)
+		if first.Owns(key) == second.Owns(key) {
+			t.Fatalf("key %s is owned by %d replicas", key, map[bool]int{true: 2, false: 0}[first.Owns(key)])
+		}
+	}
+
+	// the released lease moves the keys without waiting for it to expire
+	stopFirst()
+	<-stopped
+	waitForMembers(t, second, 1)
+
+	for i := 0; i < 100; i++ {
+		if key := fmt.Sprintf("cluster-%d", i); !second.Owns(key) || first.Owns(key) {
+			t.Fatalf("key %s is not moved to the remaining replica", key)
+		}
+	}
+
+	if len(changes) == 0 {
+		t.Fatal("expected the change functions to be called")
+	}
+}
+
+func TestNewMembershipValidates(t *testing.T) {
+	t.Parallel()
+
+	if _, err := sharding.NewMembership(nil, nil, "cluster-registry", "group", ""); err == nil {
+		t.Fatal("expected an error for a missing identity")
+	}
+}
+
+// unreachableClient fails every request while the API server is unreachable
+type unreachableClient struct {
+	client.Client
+
+	unreachable int32
+}
+
+var errUnreachable = errors.New("api server is unreachable")
+
+func (c *unreachableClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if atomic.LoadInt32(&c.unreachable) == 1 {
+		return errUnreachable
+	}
+
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *unreachableClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if atomic.LoadInt32(&c.unreachable) == 1 {
+		return errUnreachable
+	}
+
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestMembershipOwnsNoKeyAfterLeaseExpires(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	c := &unreachableClient{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+	}
+
+	m := newTestMembership(t, c, "replica-0")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = m.Start(ctx)
+	}()
+
+	waitForMembers(t, m, 1)
+	if !m.Owns("cluster") {
+		t.Fatal("expected the only replica to own the key")
+	}
+
+	// neither the lease can be renewed nor the members listed, the stale ring must not be kept
+	atomic.StoreInt32(&c.unreachable, 1)
+	waitForMembers(t, m, 0)
+	if m.Owns("cluster") {
+		t.Fatal("expected no key to be owned after the lease expired")
+	}
+}
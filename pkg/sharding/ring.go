@@ -0,0 +1,98 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharding
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// Ring assigns keys to members by rendezvous hashing: every key belongs to the member with the highest hash of the
+// key and the member name. When a member joins or leaves only the keys it gains or loses move, the assignment of
+// every other key stays the same.
+type Ring struct {
+	members []string
+}
+
+// NewRing returns the ring of the members, duplicate and empty names are dropped
+func NewRing(members ...string) *Ring {
+	unique := make(map[string]struct{}, len(members))
+	r := &Ring{
+		members: make([]string, 0, len(members)),
+	}
+	for _, member := range members {
+		if _, ok := unique[member]; ok || member == "" {
+			continue
+		}
+		unique[member] = struct{}{}
+		r.members = append(r.members, member)
+	}
+	sort.Strings(r.members)
+
+	return r
+}
+
+// Members returns the sorted names of the members
+func (r *Ring) Members() []string {
+	return append([]string(nil), r.members...)
+}
+
+// Owner returns the member the key belongs to, it is empty if the ring has no members
+func (r *Ring) Owner(key string) string {
+	var owner string
+	var max uint64
+	for _, member := range r.members {
+		if score := hash(member, key); owner == "" || score > max {
+			owner = member
+			max = score
+		}
+	}
+
+	return owner
+}
+
+// Equal returns whether the rings have the same members
+func (r *Ring) Equal(other *Ring) bool {
+	if r == nil || other == nil {
+		return r == other
+	}
+	if len(r.members) != len(other.members) {
+		return false
+	}
+	for i := range r.members {
+		if r.members[i] != other.members[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hash(member, key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(member))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+
+	// FNV alone spreads similar inputs poorly, the finalizer of splitmix64 mixes the bits of the sum
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharding_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/sharding"
+)
+
+func TestRingOwner(t *testing.T) {
+	t.Parallel()
+
+	if owner := sharding.NewRing().Owner("cluster"); owner != "" {
+		t.Fatalf("expected no owner without members, got %q", owner)
+	}
+
+	ring := sharding.NewRing("b", "a", "b", "")
+	if members := ring.Members(); len(members) != 2 || members[0] != "a" || members[1] != "b" {
+		t.Fatalf("unexpected members %v", members)
+	}
+
+	// the assignment does not depend on the order of the members
+	other := sharding.NewRing("a", "b")
+	if !ring.Equal(other) {
+		t.Fatal("expected equal rings")
+	}
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("cluster-%d", i)
+		if ring.Owner(key) != other.Owner(key) {
+			t.Fatalf("different owners of %s", key)
+		}
+	}
+}
+
+func TestRingSpreadsKeys(t *testing.T) {
+	t.Parallel()
+
+	ring := sharding.NewRing("replica-0", "replica-1", "replica-2")
+
+	counts := make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		counts[ring.Owner(fmt.Sprintf("cluster-%d", i))]++
+	}
+
+	for _, member := range ring.Members() {
+		if counts[member] < 800 || counts[member] > 1200 {
+			t.Fatalf("uneven assignment %v", counts)
+		}
+	}
+}
+
+func TestRingMovesOnlyTheKeysOfChangedMembers(t *testing.T) {
+	t.Parallel()
+
+	before := sharding.NewRing("replica-0", "replica-1", "replica-2")
+	after := sharding.NewRing("replica-0", "replica-1")
+
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("cluster-%d", i)
+		if owner := before.Owner(key); owner != "replica-2" && after.Owner(key) != owner {
+			t.Fatalf("key %s moved from %s to %s", key, owner, after.Owner(key))
+		}
+	}
+
+	joined := sharding.NewRing("replica-0", "replica-1", "replica-2", "replica-3")
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("cluster-%d", i)
+		if owner := joined.Owner(key); owner != "replica-3" && before.Owner(key) != owner {
+			t.Fatalf("key %s moved from %s to %s", key, before.Owner(key), owner)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+)
+
+const (
+	// DefaultDrainTimeout leaves 5 seconds of the default 30 second termination grace period of the pods for
+	// stopping the controllers
+	DefaultDrainTimeout = time.Second * 25
+
+	// flushTimeout is the time the functions called after draining get, on top of the drain timeout
+	flushTimeout = time.Second * 5
+)
+
+var ErrDraining = errors.New("controller is shutting down")
+
+// Drainer lets the running reconciles return before the controller shuts down. Once draining starts, no new
+// reconcile is admitted and the readiness check fails, the running ones are waited for until the drain timeout, then
+// the drained functions are called, e.g. to write the batched status updates.
+type Drainer struct {
+	log logr.Logger
+
+	mu             sync.Mutex
+	draining       bool
+	inFlight       int
+	idle           chan struct{}
+	onDrainedFuncs []func(ctx context.Context)
+}
+
+type Option func(d *Drainer)
+
+func WithLogger(log logr.Logger) Option {
+	return func(d *Drainer) {
+		d.log = log
+	}
+}
+
+func NewDrainer(opts ...Option) *Drainer {
+	d := &Drainer{
+		log:  logr.Discard(),
+		idle: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Enter admits a reconcile unless draining started, the returned function must be called once it returned
+func (d *Drainer) Enter() (func(), bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.draining {
+		return nil, false
+	}
+	d.inFlight++
+
+	var once sync.Once
+
+	return func() {
+		once.Do(d.exit)
+	}, true
+}
+
+func (d *Drainer) exit() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.inFlight--
+	if d.draining && d.inFlight == 0 {
+		close(d.idle)
+	}
+}
+
+// AddOnDrainedFunc registers a function called once the running reconciles returned or the drain timed out
+func (d *Drainer) AddOnDrainedFunc(f func(ctx context.Context)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.onDrainedFuncs = append(d.onDrainedFuncs, f)
+}
+
+// InFlight returns the number of running reconciles
+func (d *Drainer) InFlight() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.inFlight
+}
+
+// IsDraining returns whether draining started
+func (d *Drainer) IsDraining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.draining
+}
+
+// Check fails once draining started, it is meant to be added as a readiness check
+func (d *Drainer) Check(_ *http.Request) error {
+	if d.IsDraining() {
+		return errors.WithStack(ErrDraining)
+	}
+
+	return nil
+}
+
+// Drain stops admitting reconciles and waits at most the timeout for the running ones to return, then calls the
+// drained functions. It returns the number of reconciles still running.
+func (d *Drainer) Drain(timeout time.Duration) int {
+	d.mu.Lock()
+	if d.draining {
+		d.mu.Unlock()
+
+		return d.InFlight()
+	}
+	d.draining = true
+	if d.inFlight == 0 {
+		close(d.idle)
+	}
+	inFlight := d.inFlight
+	funcs := append([]func(ctx context.Context){}, d.onDrainedFuncs...)
+	d.mu.Unlock()
+
+	d.log.Info("draining running reconciles", "inFlight", inFlight, "timeout", timeout.String())
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-d.idle:
+		d.log.Info("running reconciles returned")
+	case <-timer.C:
+		d.log.Info("running reconciles did not return in time", "inFlight", d.InFlight())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	for _, f := range funcs {
+		f(ctx)
+	}
+
+	return d.InFlight()
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/shutdown"
+)
+
+func TestDrainWaitsForRunningReconciles(t *testing.T) {
+	t.Parallel()
+
+	d := shutdown.NewDrainer()
+
+	var flushed bool
+	d.AddOnDrainedFunc(func(ctx context.Context) {
+		if d.InFlight() != 0 {
+			t.Errorf("drained functions called with %d running reconciles", d.InFlight())
+		}
+		flushed = true
+	})
+
+	exit, ok := d.Enter()
+	if !ok {
+		t.Fatal("reconcile refused before draining")
+	}
+
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		exit()
+		// a second call must not be counted again
+		exit()
+	}()
+
+	if inFlight := d.Drain(time.Second * 5); inFlight != 0 {
+		t.Fatalf("expected no running reconciles, got %d", inFlight)
+	}
+	if !flushed {
+		t.Fatal("drained functions were not called")
+	}
+
+	if _, ok := d.Enter(); ok {
+		t.Fatal("reconcile admitted while draining")
+	}
+	if err := d.Check(nil); err == nil {
+		t.Fatal("readiness check should fail while draining")
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	t.Parallel()
+
+	d := shutdown.NewDrainer()
+
+	if err := d.Check(nil); err != nil {
+		t.Fatalf("unexpected readiness error: %s", err)
+	}
+
+	if _, ok := d.Enter(); !ok {
+		t.Fatal("reconcile refused before draining")
+	}
+
+	var flushed bool
+	d.AddOnDrainedFunc(func(ctx context.Context) {
+		flushed = true
+	})
+
+	if inFlight := d.Drain(time.Millisecond * 50); inFlight != 1 {
+		t.Fatalf("expected one running reconcile, got %d", inFlight)
+	}
+	if !flushed {
+		t.Fatal("drained functions were not called after the timeout")
+	}
+}
@@ -0,0 +1,734 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync
+
+import (
+	"encoding/json"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+	operatortoolstypes "github.com/banzaicloud/operator-tools/pkg/types"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// strippedAnnotations are removed from the synced objects, they belong to the tools managing the source objects
+// TODO: make these annotations as parameters, which can be specified
+// by users, that way other annotations can be used as well and we can
+// get rid of banzai specific annotations from the code
+var strippedAnnotations = []string{
+	operatortoolstypes.BanzaiCloudManagedComponent,
+	operatortoolstypes.BanzaiCloudRelatedTo,
+	patch.LastAppliedConfig,
+	corev1.LastAppliedConfigAnnotation,
+	clusterregistryv1alpha1.VersionFallbackAnnotation,
+}
+
+// TemplateDataFunc returns the data the templates of the overrides and the JSON patches are executed with
+type TemplateDataFunc func(current client.Object, obj client.Object) (map[string]interface{}, error)
+
+// OwnerReferenceResolver returns the reference to the local copy of the owner the source object references, or nil if
+// the owner is not synced into the local cluster. The object is the mutated one, local owners in another namespace can
+// not own it.
+type OwnerReferenceResolver func(ref metav1.OwnerReference, sourceNamespace string, obj client.Object) (*metav1.OwnerReference, error)
+
+// ServiceResolver returns the local copy of the source Service of the key, or nil if the Service is not synced into the
+// local cluster. The object is the mutated EndpointSlice or Endpoints the Service is resolved for.
+type ServiceResolver func(key types.NamespacedName, obj client.Object) (*corev1.Service, error)
+
+// CABundleResolver returns the CA bundle the client configs of the webhook configurations are set to from the local
+// Secret or ConfigMap key
+type CABundleResolver func(source clusterregistryv1alpha1.CABundleSource) ([]byte, error)
+
+// AutoscalerChecker returns whether a local HorizontalPodAutoscaler targets the object
+type AutoscalerChecker func(obj client.Object) (bool, error)
+
+// StorageClassChecker returns whether the storage class exists in the local cluster
+type StorageClassChecker func(name string) (bool, error)
+
+// Mutator turns the objects read from the source clusters of a rule into the objects the sync reconcilers write into
+// the local cluster. It is safe for concurrent use.
+type Mutator struct {
+	rule               *clusterregistryv1alpha1.ResourceSyncRule
+	scheme             *runtime.Scheme
+	clusterName        string
+	writeFormatVersion util.FormatVersion
+	localClusterScoped bool
+	templateData       TemplateDataFunc
+	ownerReferences    OwnerReferenceResolver
+	services           ServiceResolver
+	sourceNodes        util.NodeGetter
+	caBundles          CABundleResolver
+	storageClasses     StorageClassChecker
+	autoscalers        AutoscalerChecker
+	keyRemovals        *util.KeyRemovals
+	fallbackVersion    string
+	fallbackSchema     map[string]interface{}
+	log                logr.Logger
+}
+
+// MutatorOption configures a Mutator
+type MutatorOption func(m *Mutator)
+
+// WithClusterName sets the name of the source cluster recorded in the source annotations
+func WithClusterName(name string) MutatorOption {
+	return func(m *Mutator) {
+		m.clusterName = name
+	}
+}
+
+// WithWriteFormatVersion sets the format version of the annotations written on the objects, the previous version is
+// written by default
+func WithWriteFormatVersion(version util.FormatVersion) MutatorOption {
+	return func(m *Mutator) {
+		m.writeFormatVersion = version
+	}
+}
+
+// WithLocalClusterScoped clears the namespace of the objects, the local kind is cluster scoped
+func WithLocalClusterScoped(clusterScoped bool) MutatorOption {
+	return func(m *Mutator) {
+		m.localClusterScoped = clusterScoped
+	}
+}
+
+// WithTemplateData sets the data of the templates of the mutations, without it the templates only get the object
+func WithTemplateData(f TemplateDataFunc) MutatorOption {
+	return func(m *Mutator) {
+		m.templateData = f
+	}
+}
+
+// WithOwnerReferenceResolver sets the resolver of the owner references remapped by the rule, without it the references
+// are dropped
+func WithOwnerReferenceResolver(f OwnerReferenceResolver) MutatorOption {
+	return func(m *Mutator) {
+		m.ownerReferences = f
+	}
+}
+
+// WithServiceResolver sets the resolver of the local Services the synced EndpointSlices and Endpoints are attached to,
+// without it they are synced as they are
+func WithServiceResolver(f ServiceResolver) MutatorOption {
+	return func(m *Mutator) {
+		m.services = f
+	}
+}
+
+// WithSourceNodeGetter sets the getter of the nodes of the source cluster, the endpoints mutations dropping the
+// endpoints of the unreachable nodes keep every endpoint without it
+func WithSourceNodeGetter(f util.NodeGetter) MutatorOption {
+	return func(m *Mutator) {
+		m.sourceNodes = f
+	}
+}
+
+// WithCABundleResolver sets the resolver of the CA bundles of the webhook configurations, without it the CA bundles
+// are kept
+func WithCABundleResolver(f CABundleResolver) MutatorOption {
+	return func(m *Mutator) {
+		m.caBundles = f
+	}
+}
+
+// WithStorageClassChecker sets the checker of the local storage classes of the PersistentVolumeClaims, without it the
+// claims are synced whatever their storage class is
+func WithStorageClassChecker(f StorageClassChecker) MutatorOption {
+	return func(m *Mutator) {
+		m.storageClasses = f
+	}
+}
+
+// WithAutoscalerChecker sets the checker of the local autoscalers, the replicas of the objects they target are not
+// overridden if the rule leaves them to the autoscalers
+func WithAutoscalerChecker(f AutoscalerChecker) MutatorOption {
+	return func(m *Mutator) {
+		m.autoscalers = f
+	}
+}
+
+// WithKeyRemovals sets the compiled key removals of the annotation and label mutations of the rule, without it they are
+// compiled for every mutated object
+func WithKeyRemovals(keyRemovals *util.KeyRemovals) MutatorOption {
+	return func(m *Mutator) {
+		m.keyRemovals = keyRemovals
+	}
+}
+
+// WithVersionFallback converts the objects to the version the local cluster serves instead of the one the rule syncs
+// them as, the OpenAPI schema of the version is needed for the kinds unknown by the scheme. An empty version converts
+// nothing.
+func WithVersionFallback(version string, openAPISchema map[string]interface{}) MutatorOption {
+	return func(m *Mutator) {
+		m.fallbackVersion = version
+		m.fallbackSchema = openAPISchema
+	}
+}
+
+// WithLogger sets the logger of the optional JSON patch operations which are skipped
+func WithLogger(log logr.Logger) MutatorOption {
+	return func(m *Mutator) {
+		m.log = log
+	}
+}
+
+// NewMutator returns the mutator of the objects synced by the rule, the scheme is the one of the local cluster, the
+// objects of the kinds it knows are typed, the others are unstructured
+func NewMutator(rule *clusterregistryv1alpha1.ResourceSyncRule, scheme *runtime.Scheme, opts ...MutatorOption) *Mutator {
+	m := &Mutator{
+		rule:               rule,
+		scheme:             scheme,
+		writeFormatVersion: util.PreviousFormatVersion,
+		log:                logr.Discard(),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// mutationStep is a step of the mutation of the object, the source object is only read
+type mutationStep func(m *Mutator, source client.Object, obj client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, sourceClusterID string) (client.Object, error)
+
+// mutationSteps are the steps of the mutation in the order they are applied: the metadata of the object is mutated
+// first, so the overrides and the JSON patches see it, and the source key is recorded last, after any of the steps
+// could have changed the name or the namespace of the object.
+var mutationSteps = []mutationStep{
+	(*Mutator).normalizeVersion,
+	(*Mutator).mutateMetadata,
+	(*Mutator).clearServerFields,
+	(*Mutator).sanitize,
+	(*Mutator).pruneStatus,
+	(*Mutator).pruneSecretData,
+	(*Mutator).mutatePersistentVolumeClaim,
+	(*Mutator).applyOverrides,
+	(*Mutator).applyJSONPatches,
+	(*Mutator).routeNamespace,
+	(*Mutator).clearNamespace,
+	(*Mutator).remapOwnerReferences,
+	(*Mutator).rewriteEndpoints,
+	(*Mutator).rewriteWebhooks,
+	(*Mutator).mutateReplicas,
+	(*Mutator).checkStorageClass,
+	(*Mutator).convertToFallbackVersion,
+	(*Mutator).setSourceReference,
+}
+
+// Mutate returns the object the source object read from the source cluster is written to the local cluster as,
+// mutated by the matched rules. The source object is not changed.
+func (m *Mutator) Mutate(source client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, sourceClusterID string) (client.Object, error) {
+	obj, ok := source.DeepCopyObject().(client.Object)
+	if !ok {
+		return nil, errors.New("invalid object")
+	}
+
+	for _, step := range mutationSteps {
+		var err error
+		if obj, err = step(m, source, obj, matchedRules, sourceClusterID); err != nil {
+			return nil, err
+		}
+	}
+
+	return obj, nil
+}
+
+func (m *Mutator) normalizeVersion(_ client.Object, obj client.Object, _ clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	version := m.rule.Spec.NormalizeToVersion
+	if version == "" {
+		return obj, nil
+	}
+
+	obj, err := util.ConvertObjectVersion(m.scheme, obj, version, m.rule.Spec.VersionConversions)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not normalize object version")
+	}
+
+	return obj, nil
+}
+
+// convertToFallbackVersion converts the fully mutated object to the version the local cluster serves, and records the GVK it
+// was meant to be synced as. Objects which can not be converted without losing fields fail.
+func (m *Mutator) convertToFallbackVersion(source client.Object, obj client.Object, _ clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if m.fallbackVersion == "" || gvk.Version == m.fallbackVersion {
+		return obj, nil
+	}
+
+	converted, err := util.ConvertObjectVersion(m.scheme, obj, m.fallbackVersion, m.rule.Spec.VersionConversions)
+	if errors.Is(err, util.ErrNoConversion) {
+		converted, err = util.RelabelObjectVersion(m.scheme, obj, m.fallbackVersion, m.fallbackSchema)
+	}
+	if err != nil {
+		return nil, errors.WithDetails(errors.WrapIf(util.ErrVersionFallbackFailed, err.Error()), errors.GetDetails(err)...)
+	}
+
+	annotations := converted.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[clusterregistryv1alpha1.VersionFallbackAnnotation] = util.GVKToString(gvk)
+	util.SetSourceGVK(annotations, source.GetObjectKind().GroupVersionKind(), converted.GetObjectKind().GroupVersionKind())
+	converted.SetAnnotations(annotations)
+
+	return converted, nil
+}
+
+// mutateMetadata applies the annotation, label and GVK mutations and records the ownership of the object. The
+// ownership annotation and label set by the mutations or by a previous hop are kept.
+func (m *Mutator) mutateMetadata(source client.Object, obj client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, sourceClusterID string) (client.Object, error) {
+	keyRemovals := m.keyRemovals
+	if keyRemovals == nil {
+		var err error
+		if keyRemovals, err = util.NewKeyRemovals(m.rule.Spec.Rules); err != nil {
+			return nil, err
+		}
+	}
+
+	objAnnotations := obj.GetAnnotations()
+	if objAnnotations == nil {
+		objAnnotations = make(map[string]string)
+	}
+
+	annotationMutations := matchedRules.GetMutationAnnotations()
+	for k, v := range annotationMutations.Add {
+		objAnnotations[k] = v
+	}
+
+	for _, k := range annotationMutations.Remove {
+		delete(objAnnotations, k)
+	}
+	if err := keyRemovals.Remove(objAnnotations, annotationMutations.RemovePrefixes, annotationMutations.RemoveRegexes); err != nil {
+		return nil, errors.WrapIf(err, "could not remove annotations")
+	}
+
+	objLabels := obj.GetLabels()
+	if objLabels == nil {
+		objLabels = make(map[string]string)
+	}
+
+	labelMutations := matchedRules.GetMutationLabels()
+	for k, v := range labelMutations.Add {
+		objLabels[k] = v
+	}
+
+	for _, k := range labelMutations.Remove {
+		delete(objLabels, k)
+	}
+	if err := keyRemovals.Remove(objLabels, labelMutations.RemovePrefixes, labelMutations.RemoveRegexes); err != nil {
+		return nil, errors.WrapIf(err, "could not remove labels")
+	}
+
+	if objAnnotations[clusterregistryv1alpha1.OwnershipAnnotation] == "" {
+		objAnnotations[clusterregistryv1alpha1.OwnershipAnnotation] = sourceClusterID
+	}
+
+	if objLabels[clusterregistryv1alpha1.OwnershipAnnotation] == "" {
+		objLabels[clusterregistryv1alpha1.OwnershipAnnotation] = sourceClusterID
+	}
+
+	util.SetOwnerRule(objAnnotations, m.rule.GetName())
+	util.SetSyncOrigin(objAnnotations, source, sourceClusterID)
+
+	_, gvk := matchedRules.GetMutatedGVK(obj.GetObjectKind().GroupVersionKind())
+	util.SetSourceGVK(objAnnotations, source.GetObjectKind().GroupVersionKind(), gvk)
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	for _, k := range strippedAnnotations {
+		delete(objAnnotations, k)
+	}
+	util.SetFormatVersion(objAnnotations, m.writeFormatVersion)
+	obj.SetAnnotations(objAnnotations)
+	obj.SetLabels(objLabels)
+
+	return obj, nil
+}
+
+// clearServerFields clears the metadata set by the API server and the controllers of the source cluster, except for the
+// finalizers preserved by the rule
+func (m *Mutator) clearServerFields(_ client.Object, obj client.Object, _ clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	obj.SetGeneration(0)
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	obj.SetSelfLink("")
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetFinalizers(m.rule.Spec.GetPreservedFinalizers(obj.GetFinalizers()))
+	obj.SetOwnerReferences(nil)
+	obj.SetManagedFields(nil)
+
+	return obj, nil
+}
+
+func (m *Mutator) sanitize(_ client.Object, obj client.Object, _ clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	if m.rule.Spec.DisableFieldSanitization {
+		return obj, nil
+	}
+
+	if err := util.SanitizeObject(obj); err != nil {
+		return nil, errors.WrapIf(err, "could not sanitize object")
+	}
+
+	return obj, nil
+}
+
+// pruneStatus removes the status of the source object, it must not show up on the created or updated object if it is
+// not synced
+func (m *Mutator) pruneStatus(_ client.Object, obj client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	if matchedRules.GetMutationSyncStatus() {
+		return obj, nil
+	}
+
+	if err := util.PruneStatus(obj); err != nil {
+		return nil, errors.WrapIf(err, "could not prune object status")
+	}
+
+	return obj, nil
+}
+
+func (m *Mutator) pruneSecretData(_ client.Object, obj client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	if mutations := matchedRules.GetMutationSecretData(); mutations != nil {
+		if err := util.PruneSecretData(obj, *mutations); err != nil {
+			return nil, err
+		}
+	}
+
+	return obj, nil
+}
+
+func (m *Mutator) mutatePersistentVolumeClaim(_ client.Object, obj client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	if mutations := matchedRules.GetMutationPersistentVolumeClaims(); mutations != nil {
+		if err := util.MutatePersistentVolumeClaim(obj, *mutations); err != nil {
+			return nil, err
+		}
+	}
+
+	return obj, nil
+}
+
+func (m *Mutator) applyOverrides(source client.Object, obj client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	patches := matchedRules.GetMutationOverrides()
+	if len(patches) == 0 {
+		return obj, nil
+	}
+
+	templateData, err := m.getTemplateData(source, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	patches, err = util.K8SResourceOverlayPatchExecuteTemplates(patches, templateData)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not execute templates on patches")
+	}
+
+	gvk := resources.ConvertGVK(obj.GetObjectKind().GroupVersionKind())
+	patchFunc, err := resources.PatchYAMLModifier(resources.K8SResourceOverlay{
+		GVK:     &gvk,
+		Patches: patches,
+	}, resources.NewObjectParser(m.scheme))
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not get patch func for object")
+	}
+
+	patchedObject, err := patchFunc(obj)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not patch object")
+	}
+
+	patched, ok := patchedObject.(client.Object)
+	if !ok {
+		return nil, errors.New("invalid object")
+	}
+
+	return patched, nil
+}
+
+// applyJSONPatches applies the JSON patches after the overrides, so they see the already overridden object
+func (m *Mutator) applyJSONPatches(source client.Object, obj client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	operations := matchedRules.GetMutationJSONPatches()
+	if len(operations) == 0 {
+		return obj, nil
+	}
+
+	templateData, err := m.getTemplateData(source, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	operations, err = util.JSONPatchOperationExecuteTemplates(operations, templateData)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not execute templates on json patches")
+	}
+
+	doc, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not marshal object")
+	}
+
+	doc, skipped, err := util.ApplyJSONPatchOperations(doc, operations)
+	for _, skippedErr := range skipped {
+		m.log.V(1).Info("optional json patch operation skipped", append([]interface{}{"reason", skippedErr.Error()}, errors.GetDetails(skippedErr)...)...)
+	}
+	if err != nil {
+		return nil, errors.WithDetails(err, "resource", client.ObjectKeyFromObject(source))
+	}
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	patchedObject := NewObject(m.scheme, gvk)
+	if err := json.Unmarshal(doc, patchedObject); err != nil {
+		return nil, errors.WrapIf(err, "could not unmarshal patched object")
+	}
+	patchedObject.GetObjectKind().SetGroupVersionKind(gvk)
+
+	return patchedObject, nil
+}
+
+func (m *Mutator) routeNamespace(source client.Object, obj client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	if routing := matchedRules.GetMutationNamespaceRouting(); routing != nil {
+		if err := util.RouteNamespace(obj, source, *routing); err != nil {
+			return nil, err
+		}
+	}
+
+	return obj, nil
+}
+
+// clearNamespace clears the namespace of the objects of cluster scoped kinds, the overrides and patches could set a
+// namespace, which would make the key of the local object ambiguous
+func (m *Mutator) clearNamespace(_ client.Object, obj client.Object, _ clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	if m.localClusterScoped {
+		obj.SetNamespace("")
+	}
+
+	return obj, nil
+}
+
+// remapOwnerReferences points the owner references of the source object to the local copies of the owners if the
+// rule remaps them, the references to owners which are not synced are dropped. The references are cleared with the
+// other server set fields otherwise, since the local garbage collector deletes the objects of missing owners.
+func (m *Mutator) remapOwnerReferences(source client.Object, obj client.Object, _ clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	if m.rule.Spec.OwnerReferenceMode != clusterregistryv1alpha1.OwnerReferenceModeRemap || m.ownerReferences == nil {
+		return obj, nil
+	}
+
+	var ownerReferences []metav1.OwnerReference
+	for _, ref := range source.GetOwnerReferences() {
+		resolved, err := m.ownerReferences(ref, source.GetNamespace(), obj)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not resolve owner reference", "kind", ref.Kind, "name", ref.Name)
+		}
+		if resolved != nil {
+			ownerReferences = append(ownerReferences, *resolved)
+		}
+	}
+	obj.SetOwnerReferences(ownerReferences)
+
+	return obj, nil
+}
+
+// rewriteEndpoints rewrites the addresses of the EndpointSlices and the Endpoints, and attaches them to the local copy
+// of their Service. The endpoints of the Services whose endpoints are managed by the local controllers are not synced,
+// the controllers would overwrite them.
+func (m *Mutator) rewriteEndpoints(source client.Object, obj client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	if !util.IsEndpointsKind(obj.GetObjectKind().GroupVersionKind().GroupKind()) {
+		return obj, nil
+	}
+
+	if mutations := matchedRules.GetMutationEndpoints(); mutations != nil {
+		rewriter, err := util.NewEndpointsRewriter(*mutations, m.sourceNodes)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := rewriter.Rewrite(obj); err != nil {
+			return nil, errors.WrapIf(err, "could not rewrite endpoints")
+		}
+	}
+
+	name := util.GetEndpointsServiceName(source)
+	if m.services == nil || name == "" {
+		return obj, nil
+	}
+
+	key := types.NamespacedName{Namespace: source.GetNamespace(), Name: name}
+	service, err := m.services(key, obj)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not resolve service of endpoints", "service", key.String())
+	}
+	if service == nil {
+		return nil, errors.WithDetails(util.ErrEndpointsServiceNotSynced, "service", key.String())
+	}
+
+	if err := util.AttachEndpoints(obj, service); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// rewriteWebhooks points the client configs of the webhook configurations to the URLs of the rule instead of the
+// services of the source cluster, and sets the CA bundle of the local webhook servers
+func (m *Mutator) rewriteWebhooks(source client.Object, obj client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	mutations := matchedRules.GetMutationWebhooks()
+	if mutations == nil || !util.IsWebhookConfigurationKind(obj.GetObjectKind().GroupVersionKind().GroupKind()) {
+		return obj, nil
+	}
+
+	var serviceURL func(util.WebhookServiceReference) (string, error)
+	if mutations.ServiceURLTemplate != "" {
+		templateData, err := m.getTemplateData(source, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		serviceURL = func(service util.WebhookServiceReference) (string, error) {
+			return util.ExecuteWebhookURLTemplate(mutations.ServiceURLTemplate, templateData, service)
+		}
+	}
+
+	var caBundle []byte
+	if mutations.CABundleFrom != nil && m.caBundles != nil {
+		var err error
+		if caBundle, err = m.caBundles(*mutations.CABundleFrom); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := util.RewriteWebhookClientConfigs(obj, serviceURL, caBundle); err != nil {
+		return nil, errors.WrapIf(err, "could not rewrite webhook client configs")
+	}
+
+	return obj, nil
+}
+
+// mutateReplicas overrides the replicas of the object, it runs after the namespace routing, so the autoscalers are
+// looked up by the local key of the object
+func (m *Mutator) mutateReplicas(_ client.Object, obj client.Object, matchedRules clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	mutations := matchedRules.GetMutationReplicas()
+	if mutations == nil {
+		return obj, nil
+	}
+
+	if mutations.IgnoreIfHPAManaged && m.autoscalers != nil {
+		managed, err := m.autoscalers(obj)
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not check autoscalers")
+		}
+		if managed {
+			if err := util.RemoveReplicas(obj, *mutations); err != nil {
+				return nil, err
+			}
+
+			return obj, nil
+		}
+	}
+
+	if err := util.SetReplicas(obj, *mutations); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// checkStorageClass holds the PersistentVolumeClaims whose storage class does not exist locally, after the overrides
+// and the JSON patches could have changed it, they would be pending forever
+func (m *Mutator) checkStorageClass(_ client.Object, obj client.Object, _ clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	if m.storageClasses == nil || !util.IsPersistentVolumeClaim(obj) {
+		return obj, nil
+	}
+
+	name, err := util.GetStorageClassName(obj)
+	if err != nil || name == "" {
+		return obj, err
+	}
+
+	exists, err := m.storageClasses(name)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not check storage class", "storageClass", name)
+	}
+	if !exists {
+		return nil, errors.WithDetails(util.ErrStorageClassNotFound, "storageClass", name)
+	}
+
+	return obj, nil
+}
+
+// setSourceReference records the key of the source object if it differs from the key of the object, the source
+// annotations unless they are disabled by the rule, and the UID of the source object
+func (m *Mutator) setSourceReference(source client.Object, obj client.Object, _ clusterregistryv1alpha1.MatchedRules, _ string) (client.Object, error) {
+	util.SetSourceObjectKey(obj, client.ObjectKeyFromObject(source))
+
+	var sourceReference *util.SourceReference
+	if !m.rule.Spec.DisableSourceAnnotations {
+		sourceReference = &util.SourceReference{
+			Cluster:         m.clusterName,
+			Object:          client.ObjectKeyFromObject(source),
+			Rule:            m.rule.GetName(),
+			ResourceVersion: source.GetResourceVersion(),
+		}
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	util.SetSourceReference(annotations, sourceReference)
+	util.SetSourceUID(annotations, source.GetUID())
+	obj.SetAnnotations(annotations)
+
+	return obj, nil
+}
+
+func (m *Mutator) getTemplateData(source client.Object, obj client.Object) (map[string]interface{}, error) {
+	if m.templateData == nil {
+		return NewTemplateData(obj, nil, nil), nil
+	}
+
+	return m.templateData(source, obj)
+}
+
+// NewTemplateData returns the data the templates of the mutations are executed with
+func NewTemplateData(obj client.Object, cluster *clusterregistryv1alpha1.Cluster, localCluster *clusterregistryv1alpha1.Cluster) map[string]interface{} {
+	return map[string]interface{}{
+		"Object":       obj,
+		"Cluster":      cluster,
+		"LocalCluster": localCluster,
+	}
+}
+
+// NewObject returns an empty object of the kind, typed if the scheme knows the kind and unstructured otherwise
+func NewObject(scheme *runtime.Scheme, gvk schema.GroupVersionKind) client.Object {
+	var object client.Object
+	obj, err := scheme.New(gvk)
+	if err != nil {
+		object = &unstructured.Unstructured{}
+		object.GetObjectKind().SetGroupVersionKind(gvk)
+	} else {
+		object = obj.(client.Object) // nolint:forcetypeassert
+	}
+
+	return object
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	objectsync "github.com/cisco-open/cluster-registry-controller/pkg/sync"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the mutator")
+
+const goldenDir = "testdata/golden"
+
+// TestMutatorGolden mutates the source object of each directory of the golden dir with its rule and compares the
+// result with the desired object. The desired objects were generated with the mutation pipeline inlined in the sync
+// reconciler, so any difference is a change of the objects written to the local clusters.
+func TestMutatorGolden(t *testing.T) {
+	t.Parallel()
+
+	dirs, err := os.ReadDir(goldenDir)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	for _, dir := range dirs {
+		name := dir.Name()
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			rule := &clusterregistryv1alpha1.ResourceSyncRule{}
+			readYAML(t, filepath.Join(goldenDir, name, "rule.yaml"), rule)
+			source := readSourceObject(t, filepath.Join(goldenDir, name, "source.yaml"))
+
+			ok, matchedRules, err := rule.Match(source)
+			if err != nil || !ok {
+				t.Fatalf("source object is not matched: %v", err)
+			}
+
+			desired, err := objectsync.NewMutator(rule, clientgoscheme.Scheme, objectsync.WithClusterName("source")).Mutate(source, matchedRules, "source-cluster-id")
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+
+			actual, err := yaml.Marshal(desired)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+
+			goldenFile := filepath.Join(goldenDir, name, "desired.yaml")
+			if *update {
+				if err := os.WriteFile(goldenFile, actual, 0o600); err != nil {
+					t.Fatalf("%+v", err)
+				}
+			}
+
+			expected, err := os.ReadFile(goldenFile)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if string(actual) != string(expected) {
+				t.Fatalf("mutated object differs from %s:\n%s", goldenFile, actual)
+			}
+		})
+	}
+}
+
+func readYAML(t *testing.T, filename string, obj interface{}) {
+	t.Helper()
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := yaml.Unmarshal(content, obj); err != nil {
+		t.Fatalf("%+v", err)
+	}
+}
+
+// readSourceObject returns the object typed if its kind is known, like the sync reconcilers read the source objects
+func readSourceObject(t *testing.T, filename string) client.Object {
+	t.Helper()
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	content, err = yaml.YAMLToJSON(content)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(content); err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	obj := objectsync.NewObject(clientgoscheme.Scheme, u.GroupVersionKind())
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		return u
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(u.GroupVersionKind())
+
+	return obj
+}
@@ -0,0 +1,695 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"emperror.dev/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/rulebuilder"
+	objectsync "github.com/cisco-open/cluster-registry-controller/pkg/sync"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+const testClusterID = "source-cluster-id"
+
+func newSourceConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "demo",
+			Namespace:         "default",
+			UID:               "uid",
+			ResourceVersion:   "42",
+			Generation:        3,
+			SelfLink:          "/api/v1/namespaces/default/configmaps/demo",
+			CreationTimestamp: metav1.Now(),
+			Finalizers:        []string{"example.com/finalizer"},
+			OwnerReferences:   []metav1.OwnerReference{{APIVersion: "v1", Kind: "Namespace", Name: "default", UID: "owner"}},
+			ManagedFields:     []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			Labels:            map[string]string{"app": "demo", "team": "payments"},
+			Annotations:       map[string]string{corev1.LastAppliedConfigAnnotation: "{}"},
+		},
+		Data: map[string]string{"key": "value"},
+	}
+}
+
+func TestMutate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		builder *rulebuilder.Builder
+		opts    []objectsync.MutatorOption
+		source  func() client.Object
+		check   func(t *testing.T, obj client.Object)
+	}{
+		"server set metadata is cleared": {
+			builder: rulebuilder.New("rule"),
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if obj.GetUID() != "" || obj.GetResourceVersion() != "" || obj.GetGeneration() != 0 || obj.GetSelfLink() != "" ||
+					!obj.GetCreationTimestamp().Time.IsZero() || obj.GetFinalizers() != nil || obj.GetOwnerReferences() != nil || obj.GetManagedFields() != nil {
+					t.Fatalf("server set metadata is kept: %+v", obj)
+				}
+			},
+		},
+		"preserved finalizers are kept": {
+			builder: rulebuilder.New("rule").WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.PreserveFinalizers = []string{"example.com/finalizer", "example.com/missing"}
+			}),
+			source: func() client.Object {
+				cm := newSourceConfigMap()
+				cm.Finalizers = append(cm.Finalizers, "example.com/other")
+
+				return cm
+			},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if finalizers := obj.GetFinalizers(); len(finalizers) != 1 || finalizers[0] != "example.com/finalizer" {
+					t.Fatalf("unexpected finalizers %+v", finalizers)
+				}
+			},
+		},
+		"every finalizer is kept": {
+			builder: rulebuilder.New("rule").WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.PreserveFinalizers = []string{clusterregistryv1alpha1.AnyFinalizer}
+			}),
+			source: func() client.Object {
+				cm := newSourceConfigMap()
+				cm.Finalizers = append(cm.Finalizers, "example.com/other")
+
+				return cm
+			},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if finalizers := obj.GetFinalizers(); len(finalizers) != 2 {
+					t.Fatalf("unexpected finalizers %+v", finalizers)
+				}
+			},
+		},
+		"ownership and source are recorded": {
+			builder: rulebuilder.New("rule"),
+			opts:    []objectsync.MutatorOption{objectsync.WithClusterName("source")},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				annotations := obj.GetAnnotations()
+				if annotations[clusterregistryv1alpha1.OwnershipAnnotation] != testClusterID || obj.GetLabels()[clusterregistryv1alpha1.OwnershipAnnotation] != testClusterID {
+					t.Fatalf("ownership is not recorded: %+v", annotations)
+				}
+				if util.GetOwnerRule(obj) != "rule" {
+					t.Fatalf("owner rule is not recorded: %+v", annotations)
+				}
+				if annotations[clusterregistryv1alpha1.SourceClusterAnnotation] != "source" || annotations[clusterregistryv1alpha1.SourceObjectAnnotation] != "default/demo" {
+					t.Fatalf("source is not recorded: %+v", annotations)
+				}
+			},
+		},
+		"source annotations are disabled": {
+			builder: rulebuilder.New("rule").WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.DisableSourceAnnotations = true
+			}),
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if _, ok := obj.GetAnnotations()[clusterregistryv1alpha1.SourceObjectAnnotation]; ok {
+					t.Fatalf("source annotations are written: %+v", obj.GetAnnotations())
+				}
+			},
+		},
+		"ownership of a previous hop is kept": {
+			builder: rulebuilder.New("rule"),
+			source: func() client.Object {
+				cm := newSourceConfigMap()
+				cm.Annotations[clusterregistryv1alpha1.OwnershipAnnotation] = "origin"
+
+				return cm
+			},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if owner := obj.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation]; owner != "origin" {
+					t.Fatalf("ownership is overwritten with %s", owner)
+				}
+			},
+		},
+		"annotations and labels are removed by prefix and regex": {
+			builder: rulebuilder.New("rule").
+				RemoveAnnotationsMatching([]string{"kubectl.kubernetes.io/"}, []string{`^cluster-registry\.k8s\.cisco\.com/`}).
+				RemoveLabelsMatching(nil, []string{"^te"}),
+			source: func() client.Object {
+				cm := newSourceConfigMap()
+				cm.Annotations["kubectl.kubernetes.io/restartedAt"] = "now"
+				cm.Annotations[clusterregistryv1alpha1.OwnershipAnnotation] = "origin"
+
+				return cm
+			},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if _, ok := obj.GetAnnotations()["kubectl.kubernetes.io/restartedAt"]; ok {
+					t.Fatalf("annotation is not removed: %+v", obj.GetAnnotations())
+				}
+				if owner := obj.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation]; owner != "origin" {
+					t.Fatalf("protected ownership annotation is removed, got %s", owner)
+				}
+				if labels := obj.GetLabels(); labels["app"] != "demo" || labels["team"] != "" {
+					t.Fatalf("unexpected labels %+v", labels)
+				}
+			},
+		},
+		"owner references are remapped to the local owners": {
+			builder: rulebuilder.New("rule").WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.OwnerReferenceMode = clusterregistryv1alpha1.OwnerReferenceModeRemap
+			}),
+			opts: []objectsync.MutatorOption{objectsync.WithOwnerReferenceResolver(func(ref metav1.OwnerReference, sourceNamespace string, obj client.Object) (*metav1.OwnerReference, error) {
+				if ref.Name != "default" || sourceNamespace != "default" || obj.GetNamespace() != "default" {
+					return nil, nil
+				}
+				ref.UID = "local-owner"
+
+				return &ref, nil
+			})},
+			source: func() client.Object {
+				cm := newSourceConfigMap()
+				cm.OwnerReferences = append(cm.OwnerReferences, metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "unsynced", UID: "other"})
+
+				return cm
+			},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if refs := obj.GetOwnerReferences(); len(refs) != 1 || refs[0].Name != "default" || refs[0].UID != "local-owner" {
+					t.Fatalf("unexpected owner references %+v", refs)
+				}
+			},
+		},
+		"owner references are cleared without a resolver": {
+			builder: rulebuilder.New("rule").WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.OwnerReferenceMode = clusterregistryv1alpha1.OwnerReferenceModeRemap
+			}),
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if refs := obj.GetOwnerReferences(); refs != nil {
+					t.Fatalf("owner references are kept %+v", refs)
+				}
+			},
+		},
+		"removals are applied after additions": {
+			builder: rulebuilder.New("rule").
+				MutateAnnotations(map[string]string{"a": "1", "b": "2"}, "b").
+				MutateLabels(map[string]string{"c": "3"}, "c", "team"),
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if _, ok := obj.GetAnnotations()["b"]; ok || obj.GetAnnotations()["a"] != "1" {
+					t.Fatalf("unexpected annotations %+v", obj.GetAnnotations())
+				}
+				if _, ok := obj.GetLabels()["c"]; ok || obj.GetLabels()["team"] != "" || obj.GetLabels()["app"] != "demo" {
+					t.Fatalf("unexpected labels %+v", obj.GetLabels())
+				}
+			},
+		},
+		"stripped annotations are removed after the mutations": {
+			builder: rulebuilder.New("rule").MutateAnnotations(map[string]string{corev1.LastAppliedConfigAnnotation: "{}"}),
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if _, ok := obj.GetAnnotations()[corev1.LastAppliedConfigAnnotation]; ok {
+					t.Fatal("last applied configuration is kept")
+				}
+			},
+		},
+		"format version is written": {
+			builder: rulebuilder.New("rule"),
+			opts:    []objectsync.MutatorOption{objectsync.WithWriteFormatVersion(util.FormatVersionV2)},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if version, err := util.GetFormatVersion(obj.GetAnnotations()); err != nil || version != util.FormatVersionV2 {
+					t.Fatalf("unexpected format version %s: %v", version, err)
+				}
+			},
+		},
+		"kind is mutated": {
+			builder: rulebuilder.New("rule").MutateGVK("example.com/v1", "Mirror"),
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if gvk := obj.GetObjectKind().GroupVersionKind(); gvk.Kind != "Mirror" || gvk.Group != "example.com" {
+					t.Fatalf("unexpected kind %s", gvk)
+				}
+				if _, ok := obj.GetAnnotations()[clusterregistryv1alpha1.OriginalGVKAnnotation]; !ok {
+					t.Fatalf("original kind is not recorded: %+v", obj.GetAnnotations())
+				}
+			},
+		},
+		"status is pruned": {
+			builder: rulebuilder.New("rule"),
+			source:  newSourceDeployment,
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if deployment := obj.(*appsv1.Deployment); deployment.Status.Replicas != 0 { // nolint:forcetypeassert
+					t.Fatalf("status is kept: %+v", deployment.Status)
+				}
+			},
+		},
+		"status is synced": {
+			builder: rulebuilder.New("rule").SyncStatus(),
+			source:  newSourceDeployment,
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if deployment := obj.(*appsv1.Deployment); deployment.Status.Replicas != 2 { // nolint:forcetypeassert
+					t.Fatal("status is pruned")
+				}
+			},
+		},
+		"fields are sanitized": {
+			builder: rulebuilder.New("rule").WithGVK("v1", "Service"),
+			source:  newSourceService,
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if svc := obj.(*corev1.Service); svc.Spec.ClusterIP != "" { // nolint:forcetypeassert
+					t.Fatalf("cluster IP is kept: %s", svc.Spec.ClusterIP)
+				}
+			},
+		},
+		"sanitization is disabled": {
+			builder: rulebuilder.New("rule").WithGVK("v1", "Service").WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.DisableFieldSanitization = true
+			}),
+			source: newSourceService,
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if svc := obj.(*corev1.Service); svc.Spec.ClusterIP == "" { // nolint:forcetypeassert
+					t.Fatal("cluster IP is cleared")
+				}
+			},
+		},
+		"secret data is pruned": {
+			builder: rulebuilder.New("rule").WithGVK("v1", "Secret").NextRule().WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.Rules[0].Mutations.SecretData = &clusterregistryv1alpha1.SecretDataMutations{ExcludeKeys: []string{"private"}}
+			}),
+			source: func() client.Object {
+				return &corev1.Secret{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+					ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+					Data:       map[string][]byte{"public": []byte("a"), "private": []byte("b")},
+				}
+			},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if data := obj.(*corev1.Secret).Data; len(data) != 1 || data["public"] == nil { // nolint:forcetypeassert
+					t.Fatalf("unexpected data %+v", data)
+				}
+			},
+		},
+		"endpoint slices are rewritten and attached to the local service": {
+			builder: rulebuilder.New("rule").NextRule().WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.Rules[0].Mutations.Endpoints = &clusterregistryv1alpha1.EndpointsMutations{
+					CIDRTranslations: []clusterregistryv1alpha1.CIDRTranslation{{From: "10.0.0.0/16", To: "172.20.0.0/16"}},
+				}
+			}),
+			opts: []objectsync.MutatorOption{
+				objectsync.WithServiceResolver(func(key types.NamespacedName, obj client.Object) (*corev1.Service, error) {
+					if key != (types.NamespacedName{Namespace: "default", Name: "demo"}) {
+						return nil, nil
+					}
+
+					return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "demo-synced", Namespace: obj.GetNamespace()}}, nil
+				}),
+			},
+			source: func() client.Object {
+				return &discoveryv1.EndpointSlice{
+					TypeMeta: metav1.TypeMeta{APIVersion: "discovery.k8s.io/v1", Kind: "EndpointSlice"},
+					ObjectMeta: metav1.ObjectMeta{Name: "demo-abcde", Namespace: "default", Labels: map[string]string{
+						discoveryv1.LabelServiceName: "demo",
+						discoveryv1.LabelManagedBy:   "endpointslice-controller.k8s.io",
+					}},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.1.5"}}},
+				}
+			},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				slice := obj.(*discoveryv1.EndpointSlice) // nolint:forcetypeassert
+				if slice.Labels[discoveryv1.LabelServiceName] != "demo-synced" || slice.Labels[discoveryv1.LabelManagedBy] != util.EndpointSliceManager {
+					t.Fatalf("slice is not attached to the local service: %+v", slice.Labels)
+				}
+				if address := slice.Endpoints[0].Addresses[0]; address != "172.20.1.5" {
+					t.Fatalf("unexpected address %s", address)
+				}
+			},
+		},
+		"webhook client configs are rewritten": {
+			builder: rulebuilder.New("rule").NextRule().WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.Rules[0].Mutations.Webhooks = &clusterregistryv1alpha1.WebhookMutations{
+					ServiceURLTemplate: "https://{{ .Service.Name }}.{{ .Object.GetName }}.example.com{{ .Service.Path }}",
+					CABundleFrom:       &clusterregistryv1alpha1.CABundleSource{Kind: clusterregistryv1alpha1.CABundleSourceKindSecret, Name: "ca", Namespace: "system"},
+				}
+			}),
+			opts: []objectsync.MutatorOption{
+				objectsync.WithCABundleResolver(func(source clusterregistryv1alpha1.CABundleSource) ([]byte, error) {
+					return []byte(source.Namespace + "/" + source.Name + "/" + source.GetKey()), nil
+				}),
+			},
+			source: func() client.Object {
+				return &admissionregistrationv1.MutatingWebhookConfiguration{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "admissionregistration.k8s.io/v1", Kind: "MutatingWebhookConfiguration"},
+					ObjectMeta: metav1.ObjectMeta{Name: "demo"},
+					Webhooks: []admissionregistrationv1.MutatingWebhook{{
+						Name: "demo.example.com",
+						ClientConfig: admissionregistrationv1.WebhookClientConfig{
+							Service: &admissionregistrationv1.ServiceReference{Namespace: "system", Name: "webhook", Path: pointer.String("/mutate")},
+						},
+					}},
+				}
+			},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				clientConfig := obj.(*admissionregistrationv1.MutatingWebhookConfiguration).Webhooks[0].ClientConfig // nolint:forcetypeassert
+				if clientConfig.Service != nil || pointer.StringDeref(clientConfig.URL, "") != "https://webhook.demo.example.com/mutate" {
+					t.Fatalf("unexpected client config %+v", clientConfig)
+				}
+				if string(clientConfig.CABundle) != "system/ca/ca.crt" {
+					t.Fatalf("unexpected CA bundle %q", clientConfig.CABundle)
+				}
+			},
+		},
+		"persistent volume claim storage class is mapped": {
+			builder: rulebuilder.New("rule").NextRule().WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.Rules[0].Mutations.PersistentVolumeClaims = &clusterregistryv1alpha1.PersistentVolumeClaimMutations{
+					StorageClassMap: map[string]string{"fast-ssd": "gp3"},
+					ClearDataSource: true,
+				}
+			}),
+			opts: []objectsync.MutatorOption{
+				objectsync.WithStorageClassChecker(func(name string) (bool, error) {
+					return name == "gp3", nil
+				}),
+			},
+			source: newSourcePersistentVolumeClaim,
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				pvc := obj.(*corev1.PersistentVolumeClaim) // nolint:forcetypeassert
+				if pointer.StringDeref(pvc.Spec.StorageClassName, "") != "gp3" || pvc.Spec.VolumeName != "" || pvc.Spec.DataSource != nil {
+					t.Fatalf("unexpected claim %+v", pvc.Spec)
+				}
+			},
+		},
+		"replicas are scaled down": {
+			builder: rulebuilder.New("rule").NextRule().WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.Rules[0].Mutations.Replicas = &clusterregistryv1alpha1.ReplicaMutations{
+					Value:              intstr.FromString("50%"),
+					IgnoreIfHPAManaged: true,
+				}
+			}),
+			opts: []objectsync.MutatorOption{
+				objectsync.WithAutoscalerChecker(func(obj client.Object) (bool, error) {
+					return false, nil
+				}),
+			},
+			source: newSourceDeployment,
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if replicas := obj.(*appsv1.Deployment).Spec.Replicas; replicas == nil || *replicas != 2 { // nolint:forcetypeassert
+					t.Fatalf("unexpected replicas %v", replicas)
+				}
+			},
+		},
+		"replicas are left to the local autoscaler": {
+			builder: rulebuilder.New("rule").NextRule().WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.Rules[0].Mutations.Replicas = &clusterregistryv1alpha1.ReplicaMutations{
+					Value:              intstr.FromInt(0),
+					IgnoreIfHPAManaged: true,
+				}
+			}),
+			opts: []objectsync.MutatorOption{
+				objectsync.WithAutoscalerChecker(func(obj client.Object) (bool, error) {
+					return obj.GetName() == "demo", nil
+				}),
+			},
+			source: newSourceDeployment,
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if replicas := obj.(*appsv1.Deployment).Spec.Replicas; replicas != nil { // nolint:forcetypeassert
+					t.Fatalf("replicas are not left out: %d", *replicas)
+				}
+			},
+		},
+		"overrides see the mutated metadata": {
+			builder: rulebuilder.New("rule").
+				MutateLabels(map[string]string{"copy": "yes"}).
+				Overlay(resources.ReplaceOverlayPatchType, "/data/key", `{{ index .Object.GetLabels "copy" }}`),
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if value := obj.(*corev1.ConfigMap).Data["key"]; value != "yes" { // nolint:forcetypeassert
+					t.Fatalf("unexpected value %s", value)
+				}
+			},
+		},
+		"JSON patches see the overrides": {
+			builder: rulebuilder.New("rule").
+				Overlay(resources.ReplaceOverlayPatchType, "/data/key", "overridden").
+				WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+					spec.Rules[0].Mutations.JSONPatches = []clusterregistryv1alpha1.JSONPatchOperation{
+						{Op: "copy", From: "/data/key", Path: "/data/copy"},
+					}
+				}),
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if value := obj.(*corev1.ConfigMap).Data["copy"]; value != "overridden" { // nolint:forcetypeassert
+					t.Fatalf("unexpected value %s", value)
+				}
+			},
+		},
+		"namespace is routed": {
+			builder: rulebuilder.New("rule").WithSpec(func(spec *clusterregistryv1alpha1.ResourceSyncRuleSpec) {
+				spec.Rules[0].Mutations.NamespaceRouting = &clusterregistryv1alpha1.NamespaceRouting{
+					FromLabel: "team",
+					Map:       map[string]string{"payments": "payments-mirror"},
+				}
+			}),
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if obj.GetNamespace() != "payments-mirror" {
+					t.Fatalf("namespace is not routed: %s", obj.GetNamespace())
+				}
+				if obj.GetLabels()[clusterregistryv1alpha1.OriginalNamespaceLabel] != "default" {
+					t.Fatalf("source namespace is not recorded: %+v", obj.GetLabels())
+				}
+			},
+		},
+		"renamed object records its source key": {
+			builder: rulebuilder.New("rule").Overlay(resources.ReplaceOverlayPatchType, "/metadata/name", "renamed"),
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if obj.GetName() != "renamed" || obj.GetLabels()[clusterregistryv1alpha1.OriginalNameLabel] != "demo" {
+					t.Fatalf("source name is not recorded: %s %+v", obj.GetName(), obj.GetLabels())
+				}
+			},
+		},
+		"namespace of cluster scoped kinds is cleared after the overrides": {
+			builder: rulebuilder.New("rule").Overlay(resources.ReplaceOverlayPatchType, "/metadata/namespace", "other"),
+			opts:    []objectsync.MutatorOption{objectsync.WithLocalClusterScoped(true)},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if obj.GetNamespace() != "" {
+					t.Fatalf("namespace is kept: %s", obj.GetNamespace())
+				}
+			},
+		},
+		"templates get the template data": {
+			builder: rulebuilder.New("rule").Overlay(resources.ReplaceOverlayPatchType, "/data/key", "{{ .Cluster.Spec.ClusterID }}"),
+			opts: []objectsync.MutatorOption{objectsync.WithTemplateData(func(current client.Object, obj client.Object) (map[string]interface{}, error) {
+				return objectsync.NewTemplateData(obj, &clusterregistryv1alpha1.Cluster{
+					Spec: clusterregistryv1alpha1.ClusterSpec{ClusterID: testClusterID},
+				}, nil), nil
+			})},
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				if value := obj.(*corev1.ConfigMap).Data["key"]; value != testClusterID { // nolint:forcetypeassert
+					t.Fatalf("unexpected value %s", value)
+				}
+			},
+		},
+		"objects fall back to the version served locally": {
+			builder: rulebuilder.New("rule"),
+			opts:    []objectsync.MutatorOption{objectsync.WithVersionFallback("v1beta1", nil)},
+			source:  newSourceCronJob,
+			check: func(t *testing.T, obj client.Object) {
+				t.Helper()
+				cronJob, ok := obj.(*batchv1beta1.CronJob)
+				if !ok || cronJob.Spec.Schedule != "*/5 * * * *" || cronJob.APIVersion != "batch/v1beta1" {
+					t.Fatalf("object is not converted: %+v", obj)
+				}
+				annotations := obj.GetAnnotations()
+				if annotations[clusterregistryv1alpha1.VersionFallbackAnnotation] != "CronJob.batch/v1" {
+					t.Fatalf("fallback is not recorded: %+v", annotations)
+				}
+				if gvk, err := util.GetSourceGVK(obj); err != nil || gvk != batchv1.SchemeGroupVersion.WithKind("CronJob") {
+					t.Fatalf("unexpected source gvk %s: %v", gvk, err)
+				}
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			source := client.Object(newSourceConfigMap())
+			if test.source != nil {
+				source = test.source()
+			}
+
+			rule, err := test.builder.WithGVK(source.GetObjectKind().GroupVersionKind().GroupVersion().String(), source.GetObjectKind().GroupVersionKind().Kind).Build()
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			ok, matchedRules, err := rule.Match(source)
+			if err != nil || !ok {
+				t.Fatalf("source object is not matched: %v", err)
+			}
+
+			original := source.DeepCopyObject()
+			obj, err := objectsync.NewMutator(rule, clientgoscheme.Scheme, test.opts...).Mutate(source, matchedRules, testClusterID)
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if !reflect.DeepEqual(source, original) {
+				t.Fatal("source object is changed")
+			}
+
+			test.check(t, obj)
+		})
+	}
+}
+
+func TestMutateErrors(t *testing.T) {
+	t.Parallel()
+
+	rule, err := rulebuilder.New("rule").WithGVK("v1", "ConfigMap").
+		Overlay(resources.ReplaceOverlayPatchType, "/data/key", "{{ .Cluster.Spec.ClusterID }}").
+		Build()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	_, matchedRules, _ := rule.Match(newSourceConfigMap())
+
+	// the templates can not refer to the clusters without template data
+	if _, err := objectsync.NewMutator(rule, clientgoscheme.Scheme).Mutate(newSourceConfigMap(), matchedRules, testClusterID); err == nil {
+		t.Fatal("expected template error")
+	}
+
+	rule.Spec.Rules[0].Mutations.Overrides = nil
+	rule.Spec.Rules[0].Mutations.JSONPatches = []clusterregistryv1alpha1.JSONPatchOperation{
+		{Op: "remove", Path: "/data/missing"},
+	}
+	_, matchedRules, _ = rule.Match(newSourceConfigMap())
+	if _, err := objectsync.NewMutator(rule, clientgoscheme.Scheme).Mutate(newSourceConfigMap(), matchedRules, testClusterID); err == nil {
+		t.Fatal("expected error of the json patch of a missing path")
+	}
+
+	// the claims of the storage classes missing locally are held
+	rule, err = rulebuilder.New("rule").WithGVK("v1", "PersistentVolumeClaim").Build()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	_, matchedRules, _ = rule.Match(newSourcePersistentVolumeClaim())
+	_, err = objectsync.NewMutator(rule, clientgoscheme.Scheme, objectsync.WithStorageClassChecker(func(name string) (bool, error) {
+		return false, nil
+	})).Mutate(newSourcePersistentVolumeClaim(), matchedRules, testClusterID)
+	if !errors.Is(err, util.ErrStorageClassNotFound) {
+		t.Fatalf("expected missing storage class error, got %v", err)
+	}
+
+	// the fields missing from the version served locally fail the fallback
+	rule, err = rulebuilder.New("rule").WithGVK("networking.k8s.io/v1", "Ingress").Build()
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	_, matchedRules, _ = rule.Match(newSourceIngress())
+	_, err = objectsync.NewMutator(rule, clientgoscheme.Scheme, objectsync.WithVersionFallback("v1beta1", nil)).Mutate(newSourceIngress(), matchedRules, testClusterID)
+	if !errors.Is(err, util.ErrVersionFallbackFailed) || !strings.Contains(err.Error(), "spec.defaultBackend.service.name") {
+		t.Fatalf("expected version fallback error, got %v", err)
+	}
+}
+
+func newSourceCronJob() client.Object {
+	return &batchv1.CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "CronJob"},
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+		Spec:       batchv1.CronJobSpec{Schedule: "*/5 * * * *"},
+	}
+}
+
+func newSourceIngress() client.Object {
+	return &networkingv1.Ingress{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+		Spec: networkingv1.IngressSpec{
+			DefaultBackend: &networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{Name: "demo", Port: networkingv1.ServiceBackendPort{Number: 80}},
+			},
+		},
+	}
+}
+
+func newSourceService() client.Object {
+	return &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "10.96.0.15",
+			Ports:     []corev1.ServicePort{{Port: 80}},
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "192.0.2.10"}}},
+		},
+	}
+}
+
+func newSourcePersistentVolumeClaim() client.Object {
+	return &corev1.PersistentVolumeClaim{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: pointer.String("fast-ssd"),
+			VolumeName:       "pvc-0123",
+			DataSource:       &corev1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: "origin"},
+		},
+	}
+}
+
+func newSourceDeployment() client.Object {
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(3)},
+		Status:     appsv1.DeploymentStatus{Replicas: 2},
+	}
+}
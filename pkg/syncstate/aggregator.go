@@ -0,0 +1,281 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncstate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+const DefaultInterval = time.Second * 10
+
+// WriteFunc writes the sync state of the cluster onto its Cluster resource
+type WriteFunc func(ctx context.Context, cluster string, state clusterregistryv1alpha1.ClusterSyncState) error
+
+type clusterState struct {
+	// rules are the objects synced by each rule
+	rules map[string]map[types.NamespacedName]struct{}
+
+	lastSyncTime  time.Time
+	lastError     string
+	lastErrorTime time.Time
+
+	dirty bool
+}
+
+// Aggregator collects what the sync reconcilers report about the objects synced from each cluster and writes the
+// summary onto the Cluster resources once per interval, so busy clusters cause a single write per interval.
+type Aggregator struct {
+	write    WriteFunc
+	interval time.Duration
+	log      logr.Logger
+
+	mu       sync.Mutex
+	clusters map[string]*clusterState
+}
+
+type Option func(a *Aggregator)
+
+func WithInterval(interval time.Duration) Option {
+	return func(a *Aggregator) {
+		a.interval = interval
+	}
+}
+
+func WithLogger(log logr.Logger) Option {
+	return func(a *Aggregator) {
+		a.log = log
+	}
+}
+
+func NewAggregator(write WriteFunc, opts ...Option) *Aggregator {
+	a := &Aggregator{
+		write:    write,
+		interval: DefaultInterval,
+		log:      logr.Discard(),
+
+		clusters: make(map[string]*clusterState),
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if a.interval <= 0 {
+		a.interval = DefaultInterval
+	}
+
+	return a
+}
+
+func (a *Aggregator) getCluster(cluster string) *clusterState {
+	state, ok := a.clusters[cluster]
+	if !ok {
+		state = &clusterState{
+			rules: make(map[string]map[types.NamespacedName]struct{}),
+		}
+		a.clusters[cluster] = state
+	}
+
+	return state
+}
+
+// AddRule starts counting the objects the rule syncs from the cluster, from zero if it was already counted
+func (a *Aggregator) AddRule(cluster, rule string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state := a.getCluster(cluster)
+	state.rules[rule] = make(map[types.NamespacedName]struct{})
+	state.dirty = true
+}
+
+// RemoveRule stops counting the objects of the rule
+func (a *Aggregator) RemoveRule(cluster, rule string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, ok := a.clusters[cluster]
+	if !ok {
+		return
+	}
+	if _, ok := state.rules[rule]; !ok {
+		return
+	}
+
+	delete(state.rules, rule)
+	state.dirty = true
+}
+
+// ObjectSynced records that the object was synced from the cluster by the rule
+func (a *Aggregator) ObjectSynced(cluster, rule string, key types.NamespacedName) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	objects, ok := a.getRule(cluster, rule)
+	if !ok {
+		return
+	}
+
+	objects[key] = struct{}{}
+	state := a.clusters[cluster]
+	state.lastSyncTime = time.Now()
+	state.dirty = true
+}
+
+// ObjectRemoved records that the object is no longer synced from the cluster by the rule
+func (a *Aggregator) ObjectRemoved(cluster, rule string, key types.NamespacedName) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	objects, ok := a.getRule(cluster, rule)
+	if !ok {
+		return
+	}
+	if _, ok := objects[key]; !ok {
+		return
+	}
+
+	delete(objects, key)
+	a.clusters[cluster].dirty = true
+}
+
+// SyncFailed records the error of syncing the object from the cluster by the rule
+func (a *Aggregator) SyncFailed(cluster, rule string, key types.NamespacedName, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.getRule(cluster, rule); !ok {
+		return
+	}
+
+	state := a.clusters[cluster]
+	state.lastError = fmt.Sprintf("could not sync %s by rule %s: %s", key, rule, err)
+	state.lastErrorTime = time.Now()
+	state.dirty = true
+}
+
+func (a *Aggregator) getRule(cluster, rule string) (map[types.NamespacedName]struct{}, bool) {
+	state, ok := a.clusters[cluster]
+	if !ok {
+		return nil, false
+	}
+
+	objects, ok := state.rules[rule]
+
+	return objects, ok
+}
+
+// State returns the current sync state of the cluster
+func (a *Aggregator) State(cluster string) (clusterregistryv1alpha1.ClusterSyncState, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	state, ok := a.clusters[cluster]
+	if !ok {
+		return clusterregistryv1alpha1.ClusterSyncState{}, false
+	}
+
+	return state.summarize(), true
+}
+
+func (s *clusterState) summarize() clusterregistryv1alpha1.ClusterSyncState {
+	summary := clusterregistryv1alpha1.ClusterSyncState{
+		Rules:     len(s.rules),
+		LastError: s.lastError,
+	}
+	for _, objects := range s.rules {
+		summary.Objects += len(objects)
+	}
+	if !s.lastSyncTime.IsZero() {
+		t := metav1.NewTime(s.lastSyncTime)
+		summary.LastSyncTime = &t
+	}
+	if !s.lastErrorTime.IsZero() {
+		t := metav1.NewTime(s.lastErrorTime)
+		summary.LastErrorTime = &t
+	}
+
+	return summary
+}
+
+// Start writes the changed sync states once per interval until the context is done
+func (a *Aggregator) Start(ctx context.Context) error {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			a.Flush(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection makes only the leader write the sync states
+func (a *Aggregator) NeedLeaderElection() bool {
+	return true
+}
+
+// Flush writes the sync state of every cluster changed since the last write and returns the number of writes.
+// The states which could not be written are written again on the next flush.
+func (a *Aggregator) Flush(ctx context.Context) int {
+	a.mu.Lock()
+	names := make([]string, 0, len(a.clusters))
+	states := make(map[string]clusterregistryv1alpha1.ClusterSyncState)
+	for name, state := range a.clusters {
+		if !state.dirty {
+			continue
+		}
+		names = append(names, name)
+		states[name] = state.summarize()
+		state.dirty = false
+	}
+	a.mu.Unlock()
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		err := a.write(ctx, name, states[name])
+		if err != nil {
+			a.log.Error(err, "could not write cluster sync state", "cluster", name)
+		}
+
+		a.mu.Lock()
+		if state, ok := a.clusters[name]; ok {
+			switch {
+			case err != nil:
+				state.dirty = true
+			case len(state.rules) == 0 && !state.dirty:
+				// the clusters without rules are forgotten once their empty state is written
+				delete(a.clusters, name)
+			}
+		}
+		a.mu.Unlock()
+	}
+
+	return len(names)
+}
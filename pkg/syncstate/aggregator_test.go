@@ -0,0 +1,165 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncstate_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/syncstate"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	writes map[string]int
+	states map[string]clusterregistryv1alpha1.ClusterSyncState
+	fail   bool
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		writes: make(map[string]int),
+		states: make(map[string]clusterregistryv1alpha1.ClusterSyncState),
+	}
+}
+
+func (r *recorder) write(ctx context.Context, cluster string, state clusterregistryv1alpha1.ClusterSyncState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.fail {
+		return errors.New("write failed")
+	}
+
+	r.writes[cluster]++
+	r.states[cluster] = state
+
+	return nil
+}
+
+func key(name string) types.NamespacedName {
+	return types.NamespacedName{Namespace: "default", Name: name}
+}
+
+func TestAggregatorCounts(t *testing.T) {
+	t.Parallel()
+
+	rec := newRecorder()
+	a := syncstate.NewAggregator(rec.write)
+
+	a.AddRule("cluster-1", "rule-1")
+	a.AddRule("cluster-1", "rule-2")
+	a.AddRule("cluster-2", "rule-1")
+
+	a.ObjectSynced("cluster-1", "rule-1", key("a"))
+	a.ObjectSynced("cluster-1", "rule-1", key("a"))
+	a.ObjectSynced("cluster-1", "rule-1", key("b"))
+	a.ObjectSynced("cluster-1", "rule-2", key("a"))
+	a.ObjectRemoved("cluster-1", "rule-1", key("b"))
+	a.ObjectRemoved("cluster-1", "rule-1", key("unknown"))
+	// objects of rules which are not counted are ignored
+	a.ObjectSynced("cluster-1", "rule-3", key("c"))
+
+	if n := a.Flush(context.Background()); n != 2 {
+		t.Fatalf("wanted 2 writes, got %d", n)
+	}
+
+	state := rec.states["cluster-1"]
+	if state.Rules != 2 || state.Objects != 2 || state.LastSyncTime == nil || state.LastError != "" {
+		t.Fatalf("unexpected state of cluster-1: %+v", state)
+	}
+	state = rec.states["cluster-2"]
+	if state.Rules != 1 || state.Objects != 0 || state.LastSyncTime != nil {
+		t.Fatalf("unexpected state of cluster-2: %+v", state)
+	}
+
+	a.SyncFailed("cluster-2", "rule-1", key("d"), errors.New("forbidden"))
+	a.Flush(context.Background())
+
+	state = rec.states["cluster-2"]
+	if !strings.Contains(state.LastError, "default/d") || !strings.Contains(state.LastError, "forbidden") || state.LastErrorTime == nil {
+		t.Fatalf("the last error must be reported, got: %+v", state)
+	}
+}
+
+func TestAggregatorDebounce(t *testing.T) {
+	t.Parallel()
+
+	rec := newRecorder()
+	a := syncstate.NewAggregator(rec.write)
+
+	a.AddRule("cluster-1", "rule-1")
+	for _, name := range []string{"a", "b", "c", "d"} {
+		a.ObjectSynced("cluster-1", "rule-1", key(name))
+	}
+
+	a.Flush(context.Background())
+	if n := a.Flush(context.Background()); n != 0 {
+		t.Fatalf("unchanged states must not be written, got %d writes", n)
+	}
+	if rec.writes["cluster-1"] != 1 || rec.states["cluster-1"].Objects != 4 {
+		t.Fatalf("the changes must be written at once, got %d writes of %+v", rec.writes["cluster-1"], rec.states["cluster-1"])
+	}
+}
+
+func TestAggregatorFailedWrites(t *testing.T) {
+	t.Parallel()
+
+	rec := newRecorder()
+	a := syncstate.NewAggregator(rec.write)
+
+	a.AddRule("cluster-1", "rule-1")
+	a.ObjectSynced("cluster-1", "rule-1", key("a"))
+
+	rec.fail = true
+	a.Flush(context.Background())
+	rec.fail = false
+
+	if n := a.Flush(context.Background()); n != 1 || rec.states["cluster-1"].Objects != 1 {
+		t.Fatal("a failed write must be retried on the next flush")
+	}
+}
+
+func TestAggregatorRemoveRule(t *testing.T) {
+	t.Parallel()
+
+	rec := newRecorder()
+	a := syncstate.NewAggregator(rec.write)
+
+	a.AddRule("cluster-1", "rule-1")
+	a.ObjectSynced("cluster-1", "rule-1", key("a"))
+	a.Flush(context.Background())
+
+	// a restarted controller counts its objects again
+	a.AddRule("cluster-1", "rule-1")
+	if state, _ := a.State("cluster-1"); state.Objects != 0 || state.LastSyncTime == nil {
+		t.Fatalf("the objects must be counted again, got: %+v", state)
+	}
+
+	a.RemoveRule("cluster-1", "rule-1")
+	a.Flush(context.Background())
+	if state := rec.states["cluster-1"]; state.Rules != 0 || state.Objects != 0 {
+		t.Fatalf("the empty state must be written, got: %+v", state)
+	}
+	if _, ok := a.State("cluster-1"); ok {
+		t.Fatal("the cluster without rules must be forgotten")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+const DefaultSampleRatio = 0.1
+
+// Config configures the export of the spans through OTLP
+type Config struct {
+	// Enabled turns tracing on, it is off by default
+	Enabled bool
+	// Endpoint is the host:port of the OTLP gRPC receiver the spans are exported to
+	Endpoint string
+	// Insecure exports the spans without TLS
+	Insecure bool
+	// SampleRatio is the ratio of the reconciles traced, unless their parents are sampled already
+	SampleRatio float64
+	// ServiceName is the name of the service the spans are exported with
+	ServiceName string
+}
+
+// Setup turns tracing on if the config says so, the returned function flushes the pending spans and stops the
+// export. It does nothing while tracing is off.
+func Setup(ctx context.Context, config Config) (func(ctx context.Context) error, error) {
+	if !config.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(config.Endpoint),
+	}
+	if config.Insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "could not create trace exporter", "endpoint", config.Endpoint)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(config.ServiceName)))
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not create trace resource")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		SetTracerProvider(nil)
+
+		return errors.WrapIf(provider.Shutdown(ctx), "could not stop trace exporter")
+	}, nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the tracer of the spans of the controller
+const TracerName = "github.com/cisco-open/cluster-registry-controller"
+
+// the attributes of the spans of the sync pipeline
+const (
+	RuleKey    = attribute.Key("cluster_registry.rule")
+	ClusterKey = attribute.Key("cluster_registry.cluster")
+	GVKKey     = attribute.Key("cluster_registry.gvk")
+	ObjectKey  = attribute.Key("cluster_registry.object")
+)
+
+// noopSpan is returned while tracing is off, it is a zero sized value, so returning it does not allocate
+var noopSpan = trace.SpanFromContext(context.Background())
+
+// tracer holds a tracerHolder, the tracer is nil while tracing is off
+var tracer atomic.Value
+
+type tracerHolder struct {
+	tracer trace.Tracer
+}
+
+// SetTracerProvider turns tracing on with the spans created by the provider, a nil provider turns it off
+func SetTracerProvider(provider trace.TracerProvider) {
+	if provider == nil {
+		tracer.Store(tracerHolder{})
+
+		return
+	}
+
+	tracer.Store(tracerHolder{
+		tracer: provider.Tracer(TracerName),
+	})
+}
+
+// Enabled returns whether tracing is on
+func Enabled() bool {
+	return getTracer() != nil
+}
+
+func getTracer() trace.Tracer {
+	holder, _ := tracer.Load().(tracerHolder)
+
+	return holder.tracer
+}
+
+// Start starts a span as the child of the span of the context. While tracing is off the context is returned as it
+// is with a noop span, without allocating, so the callers should only add attributes to recording spans.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t := getTracer()
+	if t == nil {
+		return ctx, noopSpan
+	}
+
+	return t.Start(ctx, name, opts...)
+}
+
+// End records the error on the span and ends it
+func End(span trace.Span, err error) {
+	if err != nil && span.IsRecording() {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
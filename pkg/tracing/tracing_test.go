@@ -0,0 +1,105 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/tracing"
+)
+
+// the tests change the global tracer, so they do not run in parallel
+
+func TestStartWhileOff(t *testing.T) {
+	tracing.SetTracerProvider(nil)
+
+	if tracing.Enabled() {
+		t.Fatal("tracing is on")
+	}
+
+	rt := http.DefaultTransport
+	if tracing.WrapTransport(rt) != rt {
+		t.Fatal("transport is wrapped while tracing is off")
+	}
+
+	ctx := context.Background()
+	err := errors.New("failed")
+	allocs := testing.AllocsPerRun(100, func() {
+		spanCtx, span := tracing.Start(ctx, "test")
+		if spanCtx != ctx || span.IsRecording() {
+			t.Fatal("span is recorded while tracing is off")
+		}
+		tracing.End(span, err)
+	})
+	if allocs != 0 {
+		t.Fatalf("spans allocate while tracing is off: %f", allocs)
+	}
+}
+
+func TestSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracing.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer tracing.SetTracerProvider(nil)
+
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagator)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/namespaces", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := tracing.WrapTransport(http.DefaultTransport).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	tracing.End(parent, errors.New("failed"))
+
+	if traceparent == "" {
+		t.Fatal("span context is not propagated")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("unexpected spans: %d", len(spans))
+	}
+	request, ended := spans[0], spans[1]
+	if request.Name() != "HTTP GET" || request.Parent().SpanID() != ended.SpanContext().SpanID() {
+		t.Fatalf("request span is not the child of the parent span: %s", request.Name())
+	}
+	if ended.Status().Code != codes.Error || ended.Status().Description != "failed" {
+		t.Fatalf("error is not recorded: %+v", ended.Status())
+	}
+}
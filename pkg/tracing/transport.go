@@ -0,0 +1,62 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracingRoundTripper struct {
+	delegate http.RoundTripper
+}
+
+// WrapTransport traces the requests sent through the round tripper as children of the spans of their contexts, and
+// propagates the span contexts to the API servers. The round tripper is returned as it is while tracing is off.
+func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	if !Enabled() {
+		return rt
+	}
+
+	return &tracingRoundTripper{
+		delegate: rt,
+	}
+}
+
+func (t *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// only the requests of the traced reconciles are traced, e.g. not the list and watch requests of the informers
+	if !trace.SpanFromContext(req.Context()).IsRecording() {
+		return t.delegate.RoundTrip(req)
+	}
+
+	ctx, span := Start(req.Context(), "HTTP "+req.Method, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(semconv.HTTPMethodKey.String(req.Method), semconv.HTTPTargetKey.String(req.URL.Path), semconv.NetPeerNameKey.String(req.URL.Hostname()))
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.delegate.RoundTrip(req)
+	if err == nil {
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
+	}
+	End(span, err)
+
+	return resp, err
+}
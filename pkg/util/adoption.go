@@ -0,0 +1,115 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"emperror.dev/errors"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// syncAnnotations and syncLabels are only written by the sync controller, so objects which already existed
+// before being synced lack them even if they are identical otherwise
+var (
+	syncAnnotations = []string{
+		clusterregistryv1alpha1.OwnershipAnnotation,
+		clusterregistryv1alpha1.OwnerRuleAnnotation,
+		clusterregistryv1alpha1.OriginalGVKAnnotation,
+		clusterregistryv1alpha1.FormatVersionAnnotation,
+		clusterregistryv1alpha1.SourceClusterAnnotation,
+		clusterregistryv1alpha1.SourceObjectAnnotation,
+		clusterregistryv1alpha1.SourceRuleAnnotation,
+		clusterregistryv1alpha1.SourceResourceVersionAnnotation,
+		clusterregistryv1alpha1.SourceUIDAnnotation,
+		clusterregistryv1alpha1.SyncOriginAnnotation,
+		clusterregistryv1alpha1.ContentHashAnnotation,
+		clusterregistryv1alpha1.AuditHashAnnotation,
+		clusterregistryv1alpha1.SyncedAtAnnotation,
+		patch.LastAppliedConfig,
+	}
+	syncLabels = []string{
+		clusterregistryv1alpha1.OwnershipAnnotation,
+		clusterregistryv1alpha1.OriginalNameLabel,
+		clusterregistryv1alpha1.OriginalNamespaceLabel,
+	}
+)
+
+// AdoptionDriftedPaths returns the paths of the desired object which differ on an existing object not written by the
+// sync controller. The metadata written only by the sync controller and the fields populated by the API server are
+// not compared, so an existing object identical to the desired one has no drifted paths.
+func AdoptionDriftedPaths(desired client.Object, existing client.Object) ([]string, error) {
+	desired, ok := desired.DeepCopyObject().(client.Object)
+	if !ok {
+		return nil, errors.New("invalid object")
+	}
+
+	desired.SetAnnotations(withoutKeys(desired.GetAnnotations(), syncAnnotations))
+	desired.SetLabels(withoutKeys(desired.GetLabels(), syncLabels))
+
+	return DriftedPaths(desired, existing)
+}
+
+// AdoptObject copies the metadata written by the sync controller from the desired object onto the existing one,
+// so the existing object is treated as synced from then on
+func AdoptObject(existing client.Object, desired client.Object) {
+	annotations := existing.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	for _, key := range syncAnnotations {
+		if value, ok := desired.GetAnnotations()[key]; ok && key != patch.LastAppliedConfig {
+			annotations[key] = value
+		}
+	}
+	existing.SetAnnotations(annotations)
+
+	labels := existing.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	for _, key := range syncLabels {
+		if value, ok := desired.GetLabels()[key]; ok {
+			labels[key] = value
+		}
+	}
+	existing.SetLabels(labels)
+
+	// e.g. the sync anchor of the rule
+	if len(desired.GetOwnerReferences()) > 0 {
+		existing.SetOwnerReferences(desired.GetOwnerReferences())
+	}
+}
+
+func withoutKeys(m map[string]string, keys []string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	for _, key := range keys {
+		delete(result, key)
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// synced adds the metadata written by the sync controller to the object
+func synced(obj client.Object) client.Object {
+	annotations := map[string]string{
+		clusterregistryv1alpha1.OwnershipAnnotation:     "source-cluster",
+		clusterregistryv1alpha1.FormatVersionAnnotation: "2",
+	}
+	for k, v := range obj.GetAnnotations() {
+		annotations[k] = v
+	}
+	obj.SetAnnotations(annotations)
+
+	labels := map[string]string{
+		clusterregistryv1alpha1.OwnershipAnnotation: "source-cluster",
+	}
+	for k, v := range obj.GetLabels() {
+		labels[k] = v
+	}
+	obj.SetLabels(labels)
+
+	return obj
+}
+
+func TestAdoptionDriftedPaths(t *testing.T) {
+	t.Parallel()
+
+	configMap := func(mutate func(cm *corev1.ConfigMap)) client.Object {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "settings",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "app"},
+			},
+			Data: map[string]string{"key": "value"},
+		}
+		if mutate != nil {
+			mutate(cm)
+		}
+
+		return cm
+	}
+	clusterRole := func(mutate func(cr *rbacv1.ClusterRole)) client.Object {
+		cr := &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "reader",
+			},
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"configmaps"},
+				Verbs:     []string{"get", "list"},
+			}},
+		}
+		if mutate != nil {
+			mutate(cr)
+		}
+
+		return cr
+	}
+
+	tests := map[string]struct {
+		desired  client.Object
+		existing client.Object
+		expected []string
+	}{
+		"identical namespaced object": {
+			desired: synced(configMap(nil)),
+			existing: configMap(func(cm *corev1.ConfigMap) {
+				cm.ResourceVersion = "42"
+				cm.UID = "uid"
+				cm.CreationTimestamp = metav1.Now()
+			}),
+			expected: []string{},
+		},
+		"different namespaced object": {
+			desired: synced(configMap(nil)),
+			existing: configMap(func(cm *corev1.ConfigMap) {
+				cm.Data["key"] = "other"
+				cm.Labels["app"] = "other"
+			}),
+			expected: []string{"data.key", "metadata.labels.app"},
+		},
+		"identical cluster scoped object": {
+			desired: synced(clusterRole(nil)),
+			existing: clusterRole(func(cr *rbacv1.ClusterRole) {
+				cr.ResourceVersion = "7"
+				cr.Annotations = map[string]string{"rbac.authorization.kubernetes.io/autoupdate": "true"}
+			}),
+			expected: []string{},
+		},
+		"different cluster scoped object": {
+			desired: synced(clusterRole(nil)),
+			existing: clusterRole(func(cr *rbacv1.ClusterRole) {
+				cr.Rules[0].Verbs = []string{"*"}
+			}),
+			expected: []string{"rules"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			paths, err := util.AdoptionDriftedPaths(test.desired, test.existing)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(paths, test.expected) {
+				t.Fatalf("expected drifted paths %v, got %v", test.expected, paths)
+			}
+			if _, ok := test.desired.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation]; !ok {
+				t.Fatal("the desired object must not be changed")
+			}
+		})
+	}
+}
+
+func TestAdoptObject(t *testing.T) {
+	t.Parallel()
+
+	for name, obj := range map[string]func() client.Object{
+		"namespaced": func() client.Object {
+			return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default", Labels: map[string]string{"app": "app"}}}
+		},
+		"cluster scoped": func() client.Object {
+			return &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "reader", Labels: map[string]string{"app": "app"}}}
+		},
+	} {
+		obj := obj
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			desired := synced(obj())
+			desired.SetOwnerReferences([]metav1.OwnerReference{{Kind: "ConfigMap", Name: "anchor"}})
+			existing := obj()
+
+			util.AdoptObject(existing, desired)
+
+			if existing.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation] != "source-cluster" ||
+				existing.GetAnnotations()[clusterregistryv1alpha1.FormatVersionAnnotation] != "2" ||
+				existing.GetLabels()[clusterregistryv1alpha1.OwnershipAnnotation] != "source-cluster" {
+				t.Fatalf("the sync metadata must be set, got annotations %v, labels %v", existing.GetAnnotations(), existing.GetLabels())
+			}
+			if existing.GetLabels()["app"] != "app" {
+				t.Fatal("the existing metadata must be kept")
+			}
+			if len(existing.GetOwnerReferences()) != 1 {
+				t.Fatal("the owner references of the desired object must be set")
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// GetAuditHash returns the audit hash the object was written with
+func GetAuditHash(obj client.Object) string {
+	return obj.GetAnnotations()[clusterregistryv1alpha1.AuditHashAnnotation]
+}
+
+// SetAuditHash sets the audit hash annotation of the object
+func SetAuditHash(obj client.Object, hash string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[clusterregistryv1alpha1.AuditHashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+}
+
+// GetSyncedAt returns the time the desired state of the audit hash of the object was written
+func GetSyncedAt(obj client.Object) (time.Time, bool) {
+	value, ok := obj.GetAnnotations()[clusterregistryv1alpha1.SyncedAtAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	syncedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return syncedAt, true
+}
+
+// SetSyncedAt stamps the desired object with the time it is written at, if it has an audit hash. The time the current
+// object was written at is kept while its audit hash is the same, so rewriting an unchanged desired state, e.g. to
+// revert a local change, does not change it, and the timestamp alone never makes the objects differ. The current
+// object is nil if the desired one is created.
+func SetSyncedAt(current client.Object, desired client.Object, now time.Time) {
+	hash := GetAuditHash(desired)
+	if hash == "" {
+		return
+	}
+
+	annotations := desired.GetAnnotations()
+	if current != nil && GetAuditHash(current) == hash {
+		if value, ok := current.GetAnnotations()[clusterregistryv1alpha1.SyncedAtAnnotation]; ok {
+			annotations[clusterregistryv1alpha1.SyncedAtAnnotation] = value
+			desired.SetAnnotations(annotations)
+
+			return
+		}
+	}
+
+	annotations[clusterregistryv1alpha1.SyncedAtAnnotation] = now.UTC().Format(time.RFC3339)
+	desired.SetAnnotations(annotations)
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestSetSyncedAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	written := "2022-02-01T08:00:00Z"
+
+	object := func(hash string, syncedAt string) client.Object {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "config",
+				Namespace:   "default",
+				Annotations: map[string]string{},
+			},
+		}
+		if hash != "" {
+			util.SetAuditHash(cm, hash)
+		}
+		if syncedAt != "" {
+			cm.Annotations[clusterregistryv1alpha1.SyncedAtAnnotation] = syncedAt
+		}
+
+		return cm
+	}
+
+	tests := map[string]struct {
+		current  client.Object
+		desired  client.Object
+		expected string
+	}{
+		"audit disabled": {
+			current:  object("", written),
+			desired:  object("", ""),
+			expected: "",
+		},
+		"created": {
+			desired:  object("hash", ""),
+			expected: "2022-03-01T12:00:00Z",
+		},
+		"unchanged": {
+			current:  object("hash", written),
+			desired:  object("hash", ""),
+			expected: written,
+		},
+		"changed": {
+			current:  object("previous", written),
+			desired:  object("hash", ""),
+			expected: "2022-03-01T12:00:00Z",
+		},
+		"written before the audit trail": {
+			current:  object("hash", ""),
+			desired:  object("hash", ""),
+			expected: "2022-03-01T12:00:00Z",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			util.SetSyncedAt(test.current, test.desired, now)
+
+			if actual := test.desired.GetAnnotations()[clusterregistryv1alpha1.SyncedAtAnnotation]; actual != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, actual)
+			}
+			if test.expected == "" {
+				return
+			}
+
+			syncedAt, ok := util.GetSyncedAt(test.desired)
+			if !ok || syncedAt.Format(time.RFC3339) != test.expected {
+				t.Fatalf("expected %s, got %s", test.expected, syncedAt)
+			}
+		})
+	}
+}
+
+func TestAuditHash(t *testing.T) {
+	t.Parallel()
+
+	cm := newHashedConfigMap()
+	hash, err := util.AuditHash(cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hash) != 64 {
+		t.Fatalf("expected sha256 hex digest, got %s", hash)
+	}
+
+	contentHash, err := util.ContentHash(cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash[:32] != contentHash {
+		t.Fatalf("expected content hash %s to be the prefix of the audit hash %s", contentHash, hash)
+	}
+
+	// the audit annotations written on the object do not change its hash
+	util.SetAuditHash(cm, hash)
+	util.SetSyncedAt(nil, cm, time.Now())
+	if rehashed, err := util.AuditHash(cm); err != nil || rehashed != hash {
+		t.Fatalf("audit annotations changed the hash: %s, %v", rehashed, err)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// contentHashIgnoredFields are set by the API server or by the writes of the object, they are not part of its
+// desired state. The source resource version changes with every change of the source, even if the desired state does
+// not, e.g. when only the status of the source changes while the status is not synced.
+var contentHashIgnoredFields = [][]string{
+	{"metadata", "annotations", clusterregistryv1alpha1.SourceResourceVersionAnnotation},
+	{"metadata", "resourceVersion"},
+	{"metadata", "uid"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "managedFields"},
+	{"metadata", "selfLink"},
+	{"metadata", "annotations", clusterregistryv1alpha1.ContentHashAnnotation},
+	{"metadata", "annotations", clusterregistryv1alpha1.AuditHashAnnotation},
+	{"metadata", "annotations", clusterregistryv1alpha1.SyncedAtAnnotation},
+	{"metadata", "annotations", patch.LastAppliedConfig},
+}
+
+// ContentHash returns a stable hash of the desired state of the object, the fields set by the API server and the
+// content hash annotation itself are ignored
+func ContentHash(obj client.Object) (string, error) {
+	sum, err := contentSum(obj)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// AuditHash returns the full sha256 of the desired state of the object, which is hashed like by ContentHash
+func AuditHash(obj client.Object) (string, error) {
+	sum, err := contentSum(obj)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func contentSum(obj client.Object) ([sha256.Size]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return [sha256.Size]byte{}, errors.WrapIf(err, "could not convert object to unstructured")
+	}
+	content = runtime.DeepCopyJSON(content)
+
+	for _, field := range contentHashIgnoredFields {
+		unstructured.RemoveNestedField(content, field...)
+	}
+
+	// the keys of the maps are sorted by the encoder, so the same content always has the same hash
+	data, err := json.Marshal(content)
+	if err != nil {
+		return [sha256.Size]byte{}, errors.WrapIf(err, "could not marshal object")
+	}
+
+	return sha256.Sum256(data), nil
+}
+
+// MetadataHash returns a hash of the labels, annotations and ownerReferences of the object. Unlike the spec, they can
+// be changed locally without changing the generation of the object, so the changes of a synced object are detected by
+// comparing it to the hash the object was written with.
+func MetadataHash(obj client.Object) (string, error) {
+	data, err := json.Marshal(struct {
+		Labels          map[string]string       `json:"labels,omitempty"`
+		Annotations     map[string]string       `json:"annotations,omitempty"`
+		OwnerReferences []metav1.OwnerReference `json:"ownerReferences,omitempty"`
+	}{
+		Labels:          obj.GetLabels(),
+		Annotations:     obj.GetAnnotations(),
+		OwnerReferences: obj.GetOwnerReferences(),
+	})
+	if err != nil {
+		return "", errors.WrapIf(err, "could not marshal object metadata")
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// ObjectSize returns the size of the object serialized to JSON, without the last applied annotation, so the size of a
+// synced object does not depend on whether it was written with one
+func ObjectSize(obj client.Object) (int64, error) {
+	if _, ok := obj.GetAnnotations()[patch.LastAppliedConfig]; ok {
+		var copied bool
+		if obj, copied = obj.DeepCopyObject().(client.Object); !copied {
+			return 0, errors.New("invalid object")
+		}
+		annotations := obj.GetAnnotations()
+		delete(annotations, patch.LastAppliedConfig)
+		obj.SetAnnotations(annotations)
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return 0, errors.WrapIf(err, "could not marshal object")
+	}
+
+	return int64(len(data)), nil
+}
+
+// GetContentHash returns the content hash the object was written with
+func GetContentHash(obj client.Object) string {
+	return obj.GetAnnotations()[clusterregistryv1alpha1.ContentHashAnnotation]
+}
+
+// SetContentHash sets the content hash annotation of the object
+func SetContentHash(obj client.Object, hash string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[clusterregistryv1alpha1.ContentHashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,281 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func newHashedConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "default",
+			Labels:      map[string]string{"a": "1", "b": "2"},
+			Annotations: map[string]string{"c": "3"},
+		},
+		Data: map[string]string{"x": "1", "y": "2"},
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		mutate  func(cm *corev1.ConfigMap)
+		changed bool
+	}{
+		"identical": {
+			mutate: func(cm *corev1.ConfigMap) {},
+		},
+		"server fields": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.SetResourceVersion("123")
+				cm.SetUID("uid")
+				cm.SetGeneration(4)
+				cm.SetCreationTimestamp(metav1.Now())
+				cm.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "test"}})
+			},
+		},
+		"source resource version": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Annotations[clusterregistryv1alpha1.SourceResourceVersionAnnotation] = "456"
+			},
+		},
+		"content hash annotation": {
+			mutate: func(cm *corev1.ConfigMap) {
+				util.SetContentHash(cm, "previous")
+			},
+		},
+		"audit annotations": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Annotations[clusterregistryv1alpha1.AuditHashAnnotation] = "previous"
+				cm.Annotations[clusterregistryv1alpha1.SyncedAtAnnotation] = "2022-01-01T00:00:00Z"
+			},
+		},
+		"data": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Data["x"] = "changed"
+			},
+			changed: true,
+		},
+		"label": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Labels["a"] = "changed"
+			},
+			changed: true,
+		},
+		"annotation": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Annotations[clusterregistryv1alpha1.OwnerRuleAnnotation] = "rule"
+			},
+			changed: true,
+		},
+		"name": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Name = "renamed"
+			},
+			changed: true,
+		},
+	}
+
+	expected, err := util.ContentHash(newHashedConfigMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cm := newHashedConfigMap()
+			test.mutate(cm)
+
+			hash, err := util.ContentHash(cm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (hash != expected) != test.changed {
+				t.Fatalf("expected changed %t, got %s and %s", test.changed, expected, hash)
+			}
+		})
+	}
+}
+
+func TestContentHashOfUnstructured(t *testing.T) {
+	t.Parallel()
+
+	cm := newHashedConfigMap()
+	expected, err := util.ContentHash(cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same content built in a different order has the same hash as the typed object
+	u := &unstructured.Unstructured{}
+	u.Object = map[string]interface{}{
+		"data": map[string]interface{}{"y": "2", "x": "1"},
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{"c": "3"},
+			"labels":      map[string]interface{}{"b": "2", "a": "1"},
+			"namespace":   "default",
+			"name":        "test",
+		},
+		"kind":       "ConfigMap",
+		"apiVersion": "v1",
+	}
+
+	hash, err := util.ContentHash(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != expected {
+		t.Fatalf("expected %s, got %s", expected, hash)
+	}
+
+	util.SetContentHash(u, hash)
+	if util.GetContentHash(u) != hash {
+		t.Fatalf("content hash annotation is not set")
+	}
+	if _, ok := cm.GetAnnotations()[clusterregistryv1alpha1.ContentHashAnnotation]; ok {
+		t.Fatalf("content hash annotation is set on the original object")
+	}
+	if rehashed, err := util.ContentHash(u); err != nil || rehashed != hash {
+		t.Fatalf("content hash annotation changed the hash: %s, %v", rehashed, err)
+	}
+}
+
+func TestMetadataHash(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		mutate  func(cm *corev1.ConfigMap)
+		changed bool
+	}{
+		"identical": {
+			mutate: func(cm *corev1.ConfigMap) {},
+		},
+		"generation and resource version": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.SetResourceVersion("123")
+				cm.SetGeneration(4)
+			},
+		},
+		"data": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Data["x"] = "changed"
+			},
+		},
+		"label": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Labels["a"] = "changed"
+			},
+			changed: true,
+		},
+		"annotation": {
+			mutate: func(cm *corev1.ConfigMap) {
+				delete(cm.Annotations, "c")
+			},
+			changed: true,
+		},
+		"owner reference": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "uid"}})
+			},
+			changed: true,
+		},
+	}
+
+	expected, err := util.MetadataHash(newHashedConfigMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cm := newHashedConfigMap()
+			test.mutate(cm)
+
+			hash, err := util.MetadataHash(cm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (hash != expected) != test.changed {
+				t.Fatalf("expected changed %t, got %s and %s", test.changed, expected, hash)
+			}
+		})
+	}
+}
+
+func TestObjectSize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		mutate  func(cm *corev1.ConfigMap)
+		changed bool
+	}{
+		"identical": {
+			mutate: func(cm *corev1.ConfigMap) {},
+		},
+		"last applied annotation": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Annotations[patch.LastAppliedConfig] = strings.Repeat("x", 1024)
+			},
+		},
+		"data": {
+			mutate: func(cm *corev1.ConfigMap) {
+				cm.Data["z"] = strings.Repeat("x", 1024)
+			},
+			changed: true,
+		},
+	}
+
+	expected, err := util.ObjectSize(newHashedConfigMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cm := newHashedConfigMap()
+			test.mutate(cm)
+
+			size, err := util.ObjectSize(cm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (size != expected) != test.changed {
+				t.Fatalf("expected changed %t, got %d and %d", test.changed, expected, size)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"strings"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// DenyList contains the namespaces and kinds which can never be written by syncing
+type DenyList struct {
+	namespaces map[string]struct{}
+	gvks       []schema.GroupVersionKind
+}
+
+// NewDenyList parses the protected namespaces and the denied GVKs. GVKs are given as [group/]version/kind,
+// e.g. v1/Secret or admissionregistration.k8s.io/*/ValidatingWebhookConfiguration, where "*" matches any version.
+func NewDenyList(namespaces []string, gvks []string) (*DenyList, error) {
+	d := &DenyList{
+		namespaces: make(map[string]struct{}, len(namespaces)),
+		gvks:       make([]schema.GroupVersionKind, 0, len(gvks)),
+	}
+
+	for _, namespace := range namespaces {
+		d.namespaces[namespace] = struct{}{}
+	}
+
+	for _, s := range gvks {
+		parts := strings.Split(s, "/")
+
+		var gvk schema.GroupVersionKind
+		switch len(parts) {
+		case 2: // nolint:gomnd
+			gvk = schema.GroupVersionKind{Version: parts[0], Kind: parts[1]}
+		case 3: // nolint:gomnd
+			gvk = schema.GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]}
+		default:
+			return nil, errors.NewWithDetails("invalid denied gvk, expected [group/]version/kind", "gvk", s)
+		}
+		if gvk.Version == "" || gvk.Kind == "" {
+			return nil, errors.NewWithDetails("invalid denied gvk, expected [group/]version/kind", "gvk", s)
+		}
+
+		d.gvks = append(d.gvks, gvk)
+	}
+
+	return d, nil
+}
+
+// IsNamespaceProtected returns whether objects can not be written into the namespace
+func (d *DenyList) IsNamespaceProtected(namespace string) bool {
+	if d == nil || namespace == "" {
+		return false
+	}
+
+	_, ok := d.namespaces[namespace]
+
+	return ok
+}
+
+// IsGVKDenied returns whether objects of the GVK can not be written. A GVK with the "*" version is only denied
+// if every version of its kind is denied.
+func (d *DenyList) IsGVKDenied(gvk schema.GroupVersionKind) bool {
+	if d == nil {
+		return false
+	}
+
+	for _, denied := range d.gvks {
+		if denied.Group != gvk.Group || denied.Kind != gvk.Kind {
+			continue
+		}
+		if denied.Version == clusterregistryv1alpha1.AnyVersion || denied.Version == gvk.Version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsDenied returns whether the object can not be written, because of its kind or namespace
+func (d *DenyList) IsDenied(obj client.Object) bool {
+	return d.IsGVKDenied(obj.GetObjectKind().GroupVersionKind()) || d.IsNamespaceProtected(obj.GetNamespace())
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestNewDenyList(t *testing.T) {
+	t.Parallel()
+
+	for _, gvk := range []string{"Secret", "a/b/c/d", "v1/", "/Secret"} {
+		if _, err := util.NewDenyList(nil, []string{gvk}); err == nil {
+			t.Fatalf("%s: expected error", gvk)
+		}
+	}
+}
+
+func TestDenyList(t *testing.T) {
+	t.Parallel()
+
+	denyList, err := util.NewDenyList(
+		[]string{"kube-system"},
+		[]string{"v1/Secret", "admissionregistration.k8s.io/*/ValidatingWebhookConfiguration"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		gvk       schema.GroupVersionKind
+		namespace string
+		denied    bool
+	}{
+		"allowed": {
+			gvk:       corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+			namespace: "default",
+		},
+		"protected namespace": {
+			gvk:       corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+			namespace: "kube-system",
+			denied:    true,
+		},
+		"denied kind": {
+			gvk:       corev1.SchemeGroupVersion.WithKind("Secret"),
+			namespace: "default",
+			denied:    true,
+		},
+		"any version of denied kind": {
+			gvk:    schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1beta1", Kind: "ValidatingWebhookConfiguration"},
+			denied: true,
+		},
+		"other version of denied kind": {
+			gvk:       schema.GroupVersionKind{Version: "v2", Kind: "Secret"},
+			namespace: "default",
+		},
+	}
+
+	for name, test := range tests {
+		obj := &corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "test",
+				Namespace: test.namespace,
+			},
+		}
+		obj.SetGroupVersionKind(test.gvk)
+
+		if denied := denyList.IsDenied(obj); denied != test.denied {
+			t.Fatalf("%s: denied: %t, expected: %t", name, denied, test.denied)
+		}
+	}
+
+	if (*util.DenyList)(nil).IsDenied(&corev1.Secret{}) {
+		t.Fatal("nil deny list denied an object")
+	}
+}
+
+// A benign source kind is blocked once it is mutated into a denied kind
+func TestDenyListMutatedGVK(t *testing.T) {
+	t.Parallel()
+
+	denyList, err := util.NewDenyList(nil, []string{"admissionregistration.k8s.io/v1/ValidatingWebhookConfiguration"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj := &corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "webhook",
+		},
+	}
+	obj.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+
+	if denyList.IsDenied(obj) {
+		t.Fatal("source object is denied")
+	}
+
+	mutated, gvk := clusterregistryv1alpha1.MatchedRules{
+		{
+			Mutations: clusterregistryv1alpha1.Mutations{
+				GVK: &resources.GroupVersionKind{
+					Group: "admissionregistration.k8s.io",
+					Kind:  "ValidatingWebhookConfiguration",
+				},
+			},
+		},
+	}.GetMutatedGVK(obj.GroupVersionKind())
+	if !mutated {
+		t.Fatal("gvk is not mutated")
+	}
+	obj.SetGroupVersionKind(gvk)
+
+	if !denyList.IsDenied(obj) {
+		t.Fatalf("mutated object of %s is not denied", gvk)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// managedMetadataFields are the only metadata fields of the desired state compared with the live object,
+// the rest is either set by the API server or by the sync controller on write
+var managedMetadataFields = []string{"labels", "annotations"}
+
+// DriftedPaths returns the dot separated paths of the desired object which differ on the live object.
+// Only the paths present on the desired object are compared, so defaulted fields and fields managed by
+// others on the live object are not reported.
+func DriftedPaths(desired client.Object, live client.Object) ([]string, error) {
+	desiredContent, err := toUnstructuredContent(desired)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not convert desired object")
+	}
+
+	liveContent, err := toUnstructuredContent(live)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not convert live object")
+	}
+
+	paths := make([]string, 0)
+
+	desiredMetadata, _, _ := unstructured.NestedMap(desiredContent, "metadata")
+	liveMetadata, _, _ := unstructured.NestedMap(liveContent, "metadata")
+	for _, field := range managedMetadataFields {
+		if value, ok := desiredMetadata[field]; ok {
+			paths = append(paths, driftedPaths("metadata."+field, value, liveMetadata[field])...)
+		}
+	}
+
+	for key, value := range desiredContent {
+		switch key {
+		case "apiVersion", "kind", "metadata":
+			continue
+		}
+
+		paths = append(paths, driftedPaths(key, value, liveContent[key])...)
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// ChangedFieldManagers returns the sorted field managers of the entries of the managed fields which are new or
+// changed on the new object, the managers which changed it since the old object. It is empty if the API server does
+// not track the managed fields.
+func ChangedFieldManagers(oldObj, newObj metav1.Object) []string {
+	type entryKey struct {
+		manager   string
+		operation metav1.ManagedFieldsOperationType
+	}
+
+	previous := make(map[entryKey]metav1.ManagedFieldsEntry, len(oldObj.GetManagedFields()))
+	for _, entry := range oldObj.GetManagedFields() {
+		previous[entryKey{entry.Manager, entry.Operation}] = entry
+	}
+
+	managers := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, entry := range newObj.GetManagedFields() {
+		if _, ok := seen[entry.Manager]; ok {
+			continue
+		}
+
+		old, ok := previous[entryKey{entry.Manager, entry.Operation}]
+		if ok && reflect.DeepEqual(old.Time, entry.Time) && reflect.DeepEqual(old.FieldsV1, entry.FieldsV1) {
+			continue
+		}
+
+		seen[entry.Manager] = struct{}{}
+		managers = append(managers, entry.Manager)
+	}
+
+	sort.Strings(managers)
+
+	return managers
+}
+
+func driftedPaths(path string, desired interface{}, live interface{}) []string {
+	desiredMap, ok := desired.(map[string]interface{})
+	if !ok {
+		if valuesEqual(desired, live) {
+			return nil
+		}
+
+		return []string{path}
+	}
+
+	liveMap, ok := live.(map[string]interface{})
+	if !ok {
+		if len(desiredMap) == 0 && live == nil {
+			return nil
+		}
+
+		return []string{path}
+	}
+
+	paths := make([]string, 0)
+	for key, value := range desiredMap {
+		paths = append(paths, driftedPaths(joinPath(path, key), value, liveMap[key])...)
+	}
+
+	return paths
+}
+
+func valuesEqual(desired interface{}, live interface{}) bool {
+	if reflect.DeepEqual(desired, live) {
+		return true
+	}
+
+	// numbers may be decoded as integers on one side and as floats on the other
+	desiredNumber, desiredOK := toFloat(desired)
+	liveNumber, liveOK := toFloat(live)
+	if desiredOK && liveOK {
+		return desiredNumber == liveNumber
+	}
+
+	// empty slices and missing fields are the same after a round trip through the API server
+	if live == nil {
+		if s, ok := desired.([]interface{}); ok && len(s) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func joinPath(path string, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return fmt.Sprintf("%s[%s]", path, key)
+	}
+
+	return path + "." + key
+}
+
+func toUnstructuredContent(obj client.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+
+	return content, errors.WithStackIf(err)
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestDriftedPaths(t *testing.T) {
+	t.Parallel()
+
+	deployment := func(mutate func(d *appsv1.Deployment)) client.Object {
+		d := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "app",
+				Namespace:   "default",
+				Labels:      map[string]string{"app": "app"},
+				Annotations: map[string]string{"team": "payments"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: pointer.Int32Ptr(2),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "app", Image: "app:1"}},
+					},
+				},
+			},
+		}
+		if mutate != nil {
+			mutate(d)
+		}
+
+		return d
+	}
+
+	tests := map[string]struct {
+		desired  client.Object
+		live     client.Object
+		expected []string
+	}{
+		"in sync": {
+			desired:  deployment(nil),
+			live:     deployment(nil),
+			expected: []string{},
+		},
+		"server side fields are ignored": {
+			desired: deployment(nil),
+			live: deployment(func(d *appsv1.Deployment) {
+				d.ResourceVersion = "42"
+				d.Generation = 3
+				d.Annotations["deployment.kubernetes.io/revision"] = "1"
+				d.Spec.RevisionHistoryLimit = pointer.Int32Ptr(10)
+				d.Status.Replicas = 2
+			}),
+			expected: []string{},
+		},
+		"drifted fields": {
+			desired: deployment(nil),
+			live: deployment(func(d *appsv1.Deployment) {
+				d.Labels["app"] = "other"
+				d.Spec.Replicas = pointer.Int32Ptr(5)
+				d.Spec.Template.Spec.Containers[0].Image = "app:2"
+			}),
+			expected: []string{"metadata.labels.app", "spec.replicas", "spec.template.spec.containers"},
+		},
+		"removed annotation": {
+			desired: deployment(nil),
+			live: deployment(func(d *appsv1.Deployment) {
+				d.Annotations = nil
+			}),
+			expected: []string{"metadata.annotations"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			paths, err := util.DriftedPaths(test.desired, test.live)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(paths) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, paths)
+			}
+			for i := range paths {
+				if paths[i] != test.expected[i] {
+					t.Fatalf("expected %v, got %v", test.expected, paths)
+				}
+			}
+		})
+	}
+}
+
+func TestDriftedPathsUnstructured(t *testing.T) {
+	t.Parallel()
+
+	desired := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "config"},
+		"data":       map[string]interface{}{"key": "value", "dotted.key": "value"},
+	}}
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "config", "uid": "1234"},
+		"data":       map[string]interface{}{"key": "value", "dotted.key": "changed", "extra": "value"},
+	}}
+
+	paths, err := util.DriftedPaths(desired, live)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) != 1 || paths[0] != "data[dotted.key]" {
+		t.Fatalf("expected [data[dotted.key]], got %v", paths)
+	}
+}
+
+func TestChangedFieldManagers(t *testing.T) {
+	t.Parallel()
+
+	earlier := metav1.NewTime(metav1.Now().Add(-time.Minute).Truncate(time.Second))
+	later := metav1.NewTime(earlier.Add(time.Minute))
+	entry := func(manager string, operation metav1.ManagedFieldsOperationType, at metav1.Time, fields string) metav1.ManagedFieldsEntry {
+		at = *at.DeepCopy()
+
+		return metav1.ManagedFieldsEntry{
+			Manager:   manager,
+			Operation: operation,
+			Time:      &at,
+			FieldsV1:  &metav1.FieldsV1{Raw: []byte(fields)},
+		}
+	}
+	synced := entry("cluster-registry-controller rule", metav1.ManagedFieldsOperationUpdate, earlier, `{"f:data":{}}`)
+
+	tests := map[string]struct {
+		old      []metav1.ManagedFieldsEntry
+		new      []metav1.ManagedFieldsEntry
+		expected []string
+	}{
+		"unchanged": {
+			old: []metav1.ManagedFieldsEntry{synced},
+			new: []metav1.ManagedFieldsEntry{synced},
+		},
+		"managed fields not tracked": {},
+		"new manager": {
+			old:      []metav1.ManagedFieldsEntry{synced},
+			new:      []metav1.ManagedFieldsEntry{synced, entry("kubectl-edit", metav1.ManagedFieldsOperationUpdate, later, `{"f:data":{"f:key":{}}}`)},
+			expected: []string{"kubectl-edit"},
+		},
+		"changed entry": {
+			old: []metav1.ManagedFieldsEntry{synced, entry("kubectl-edit", metav1.ManagedFieldsOperationUpdate, earlier, `{"f:data":{}}`)},
+			new: []metav1.ManagedFieldsEntry{
+				synced,
+				entry("kubectl-edit", metav1.ManagedFieldsOperationUpdate, later, `{"f:data":{"f:key":{}}}`),
+			},
+			expected: []string{"kubectl-edit"},
+		},
+		"several managers": {
+			old: []metav1.ManagedFieldsEntry{synced},
+			new: []metav1.ManagedFieldsEntry{
+				entry("kubectl-edit", metav1.ManagedFieldsOperationUpdate, later, `{"f:data":{"f:key":{}}}`),
+				entry("argocd", metav1.ManagedFieldsOperationApply, later, `{"f:data":{"f:other":{}}}`),
+				entry("argocd", metav1.ManagedFieldsOperationUpdate, later, `{"f:metadata":{}}`),
+			},
+			expected: []string{"argocd", "kubectl-edit"},
+		},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			oldObj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ManagedFields: test.old}}
+			newObj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ManagedFields: test.new}}
+
+			managers := util.ChangedFieldManagers(oldObj, newObj)
+			if len(managers) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, managers)
+			}
+			for i := range test.expected {
+				if managers[i] != test.expected[i] {
+					t.Fatalf("expected %v, got %v", test.expected, managers)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"net"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// EndpointSliceManager is the manager label value of the synced EndpointSlices, the endpoint slice controllers of the
+// local cluster only manage the slices labeled as theirs
+const EndpointSliceManager = "cluster-registry.k8s.cisco.com"
+
+var (
+	ErrEndpointsServiceNotSynced = errors.New("service of the endpoints is not synced locally")
+	ErrEndpointsServiceSelected  = errors.New("endpoints of the local service are managed by the local endpoint controllers")
+)
+
+var (
+	endpointsGroupKind     = corev1.SchemeGroupVersion.WithKind("Endpoints").GroupKind()
+	endpointSliceGroupKind = discoveryv1.SchemeGroupVersion.WithKind("EndpointSlice").GroupKind()
+)
+
+// IsEndpointsKind returns whether the objects of the kind are the EndpointSlices or the Endpoints of Services
+func IsEndpointsKind(gk schema.GroupKind) bool {
+	return gk == endpointsGroupKind || gk == endpointSliceGroupKind
+}
+
+// GetEndpointsServiceName returns the name of the Service the EndpointSlice or the Endpoints belong to
+func GetEndpointsServiceName(obj client.Object) string {
+	if obj.GetObjectKind().GroupVersionKind().GroupKind() == endpointSliceGroupKind {
+		return obj.GetLabels()[discoveryv1.LabelServiceName]
+	}
+
+	return obj.GetName()
+}
+
+// AttachEndpoints makes the EndpointSlice or the Endpoints belong to the local Service, and marks the slices not to be
+// managed by the local endpoint slice controllers. ErrEndpointsServiceSelected is returned if the local controllers
+// manage the same endpoints: the EndpointSlices of the Services with a selector, unless they are headless, and the
+// Endpoints of every Service with a selector.
+func AttachEndpoints(obj client.Object, service *corev1.Service) error {
+	selected := len(service.Spec.Selector) > 0
+
+	switch obj.GetObjectKind().GroupVersionKind().GroupKind() {
+	case endpointSliceGroupKind:
+		if selected && service.Spec.ClusterIP != corev1.ClusterIPNone {
+			return errors.WithDetails(ErrEndpointsServiceSelected, "service", client.ObjectKeyFromObject(service).String())
+		}
+
+		objLabels := obj.GetLabels()
+		if objLabels == nil {
+			objLabels = make(map[string]string)
+		}
+		objLabels[discoveryv1.LabelServiceName] = service.GetName()
+		objLabels[discoveryv1.LabelManagedBy] = EndpointSliceManager
+		obj.SetLabels(objLabels)
+	case endpointsGroupKind:
+		if selected {
+			return errors.WithDetails(ErrEndpointsServiceSelected, "service", client.ObjectKeyFromObject(service).String())
+		}
+
+		obj.SetName(service.GetName())
+	default:
+		return errors.Errorf("endpoints can not be attached to services on %T", obj)
+	}
+
+	return nil
+}
+
+// NodeGetter returns the node of the source cluster, or nil if it does not exist
+type NodeGetter func(name string) (*corev1.Node, error)
+
+// EndpointsRewriter rewrites the addresses of the EndpointSlices and the Endpoints synced from another cluster, whose
+// pod network is not routable from the local cluster. The node names and the target references of the endpoints are
+// removed, they refer to the nodes and the pods of the source cluster.
+type EndpointsRewriter struct {
+	addressMap   map[string]string
+	translations []cidrTranslation
+	dropNodes    bool
+	nodeSelector labels.Selector
+	getNode      NodeGetter
+}
+
+type cidrTranslation struct {
+	from *net.IPNet
+	to   *net.IPNet
+}
+
+// NewEndpointsRewriter returns the rewriter of the mutations, the nodes of the endpoints are read with getNode if the
+// unreachable ones are dropped
+func NewEndpointsRewriter(mutations clusterregistryv1alpha1.EndpointsMutations, getNode NodeGetter) (*EndpointsRewriter, error) {
+	if err := mutations.Validate(); err != nil {
+		return nil, errors.WrapIf(err, "invalid endpoints mutations")
+	}
+
+	r := &EndpointsRewriter{
+		addressMap: mutations.AddressMap,
+		dropNodes:  mutations.DropUnreachableNodes,
+		getNode:    getNode,
+	}
+
+	for _, translation := range mutations.CIDRTranslations {
+		_, from, _ := net.ParseCIDR(translation.From)
+		_, to, _ := net.ParseCIDR(translation.To)
+		r.translations = append(r.translations, cidrTranslation{from: from, to: to})
+	}
+
+	if mutations.ReachableNodeSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(mutations.ReachableNodeSelector)
+		if err != nil {
+			return nil, errors.WrapIf(err, "invalid reachable node selector")
+		}
+		r.nodeSelector = selector
+	}
+
+	return r, nil
+}
+
+// RewriteAddress returns the mapped address, or the address translated by the first translation whose source CIDR
+// contains it, or the address itself
+func (r *EndpointsRewriter) RewriteAddress(address string) string {
+	if mapped, ok := r.addressMap[address]; ok {
+		return mapped
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return address
+	}
+
+	for _, translation := range r.translations {
+		if !translation.from.Contains(ip) {
+			continue
+		}
+
+		// the addresses of the same family have the length of their masks in the parsed CIDRs
+		if v4 := ip.To4(); v4 != nil && len(translation.from.Mask) == net.IPv4len {
+			ip = v4
+		}
+		translated := make(net.IP, len(ip))
+		for i := range ip {
+			translated[i] = translation.to.IP[i] | ip[i]&^translation.from.Mask[i]
+		}
+
+		return translated.String()
+	}
+
+	return address
+}
+
+// Rewrite rewrites the addresses of the EndpointSlice or the Endpoints, and removes the endpoints on the unreachable
+// nodes if the mutations drop them
+func (r *EndpointsRewriter) Rewrite(obj client.Object) error {
+	gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	if !IsEndpointsKind(gk) {
+		return errors.Errorf("endpoints can not be rewritten on %s", gk)
+	}
+
+	if gk == endpointSliceGroupKind {
+		return modifyObjectContent(obj, r.rewriteEndpointSlice)
+	}
+
+	return modifyObjectContent(obj, r.rewriteEndpoints)
+}
+
+func (r *EndpointsRewriter) rewriteEndpointSlice(content map[string]interface{}) error {
+	endpoints, _ := content["endpoints"].([]interface{})
+	kept := make([]interface{}, 0, len(endpoints))
+	for _, item := range endpoints {
+		endpoint, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		// the node name of the v1beta1 endpoints can be in their topology only
+		nodeName, _ := endpoint["nodeName"].(string)
+		topology, _ := endpoint["topology"].(map[string]interface{})
+		if hostname, ok := topology[corev1.LabelHostname].(string); ok && nodeName == "" {
+			nodeName = hostname
+		}
+		reachable, err := r.isNodeReachable(nodeName)
+		if err != nil {
+			return err
+		}
+		if !reachable {
+			continue
+		}
+
+		addresses, _ := endpoint["addresses"].([]interface{})
+		for i, address := range addresses {
+			if address, ok := address.(string); ok {
+				addresses[i] = r.RewriteAddress(address)
+			}
+		}
+		delete(endpoint, "nodeName")
+		delete(endpoint, "targetRef")
+		delete(topology, corev1.LabelHostname)
+
+		kept = append(kept, endpoint)
+	}
+	content["endpoints"] = kept
+
+	return nil
+}
+
+func (r *EndpointsRewriter) rewriteEndpoints(content map[string]interface{}) error {
+	subsets, _ := content["subsets"].([]interface{})
+	keptSubsets := make([]interface{}, 0, len(subsets))
+	for _, item := range subsets {
+		subset, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		count := 0
+		for _, field := range []string{"addresses", "notReadyAddresses"} {
+			addresses, _ := subset[field].([]interface{})
+			kept := make([]interface{}, 0, len(addresses))
+			for _, item := range addresses {
+				address, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				nodeName, _ := address["nodeName"].(string)
+				reachable, err := r.isNodeReachable(nodeName)
+				if err != nil {
+					return err
+				}
+				if !reachable {
+					continue
+				}
+
+				if ip, ok := address["ip"].(string); ok {
+					address["ip"] = r.RewriteAddress(ip)
+				}
+				delete(address, "nodeName")
+				delete(address, "targetRef")
+
+				kept = append(kept, address)
+			}
+			if len(kept) > 0 {
+				subset[field] = kept
+			} else {
+				delete(subset, field)
+			}
+			count += len(kept)
+		}
+
+		// the API server rejects the subsets without addresses
+		if count > 0 {
+			keptSubsets = append(keptSubsets, subset)
+		}
+	}
+	content["subsets"] = keptSubsets
+
+	return nil
+}
+
+// isNodeReachable returns whether the node of the source cluster is ready and selected by the reachable node
+// selector, the endpoints without a node are kept
+func (r *EndpointsRewriter) isNodeReachable(name string) (bool, error) {
+	if !r.dropNodes || name == "" || r.getNode == nil {
+		return true, nil
+	}
+
+	node, err := r.getNode(name)
+	if err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not get node", "node", name)
+	}
+	if node == nil {
+		return false, nil
+	}
+
+	if r.nodeSelector != nil && !r.nodeSelector.Matches(labels.Set(node.GetLabels())) {
+		return false, nil
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue, nil
+		}
+	}
+
+	return false, nil
+}
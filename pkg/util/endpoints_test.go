@@ -0,0 +1,276 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"reflect"
+	"testing"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func newTestNode(name string, ready bool, labels map[string]string) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+
+	return &corev1.Node{
+		ObjectMeta: v1.ObjectMeta{Name: name, Labels: labels},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func TestRewriteEndpoints(t *testing.T) {
+	t.Parallel()
+
+	nodes := map[string]*corev1.Node{
+		"node-a": newTestNode("node-a", true, map[string]string{"gateway": "true"}),
+		"node-b": newTestNode("node-b", false, map[string]string{"gateway": "true"}),
+		"node-c": newTestNode("node-c", true, nil),
+	}
+	getNode := func(name string) (*corev1.Node, error) {
+		return nodes[name], nil
+	}
+
+	tests := map[string]struct {
+		mutations clusterregistryv1alpha1.EndpointsMutations
+		wanted    []string
+	}{
+		"addresses are kept": {
+			wanted: []string{"10.0.1.5", "10.0.2.6", "10.0.3.7", "10.0.4.8"},
+		},
+		"address map is applied before the translations": {
+			mutations: clusterregistryv1alpha1.EndpointsMutations{
+				AddressMap: map[string]string{"10.0.1.5": "192.168.0.1"},
+				CIDRTranslations: []clusterregistryv1alpha1.CIDRTranslation{
+					{From: "10.0.0.0/16", To: "172.20.0.0/16"},
+				},
+			},
+			wanted: []string{"192.168.0.1", "172.20.2.6", "172.20.3.7", "172.20.4.8"},
+		},
+		"unreachable nodes are dropped": {
+			mutations: clusterregistryv1alpha1.EndpointsMutations{
+				DropUnreachableNodes: true,
+			},
+			wanted: []string{"10.0.1.5", "10.0.3.7"},
+		},
+		"nodes not selected are dropped": {
+			mutations: clusterregistryv1alpha1.EndpointsMutations{
+				DropUnreachableNodes:  true,
+				ReachableNodeSelector: &v1.LabelSelector{MatchLabels: map[string]string{"gateway": "true"}},
+			},
+			wanted: []string{"10.0.1.5"},
+		},
+	}
+
+	for name, test := range tests {
+		slice := &discoveryv1.EndpointSlice{
+			TypeMeta:    v1.TypeMeta{APIVersion: "discovery.k8s.io/v1", Kind: "EndpointSlice"},
+			ObjectMeta:  v1.ObjectMeta{Name: "demo-abcde", Namespace: "default"},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.0.1.5"}, NodeName: pointer.String("node-a"), TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "demo-1"}},
+				{Addresses: []string{"10.0.2.6"}, NodeName: pointer.String("node-b")},
+				{Addresses: []string{"10.0.3.7"}, NodeName: pointer.String("node-c")},
+				{Addresses: []string{"10.0.4.8"}, NodeName: pointer.String("node-d")},
+			},
+		}
+		endpoints := &corev1.Endpoints{
+			TypeMeta:   v1.TypeMeta{APIVersion: "v1", Kind: "Endpoints"},
+			ObjectMeta: v1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Subsets: []corev1.EndpointSubset{
+				{
+					Addresses: []corev1.EndpointAddress{
+						{IP: "10.0.1.5", NodeName: pointer.String("node-a"), TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "demo-1"}},
+						{IP: "10.0.2.6", NodeName: pointer.String("node-b")},
+					},
+					NotReadyAddresses: []corev1.EndpointAddress{
+						{IP: "10.0.3.7", NodeName: pointer.String("node-c")},
+					},
+				},
+				{
+					Addresses: []corev1.EndpointAddress{{IP: "10.0.4.8", NodeName: pointer.String("node-d")}},
+				},
+			},
+		}
+
+		objects := map[string]client.Object{
+			"typed slice":     slice,
+			"typed endpoints": endpoints,
+		}
+		for kind, obj := range map[string]client.Object{"slice": slice, "endpoints": endpoints} {
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			objects["unstructured "+kind] = &unstructured.Unstructured{Object: content}
+		}
+
+		for kind, obj := range objects {
+			rewriter, err := util.NewEndpointsRewriter(test.mutations, getNode)
+			if err != nil {
+				t.Fatalf("%s (%s): %+v", name, kind, err)
+			}
+
+			obj = obj.DeepCopyObject().(client.Object) // nolint:forcetypeassert
+			if err := rewriter.Rewrite(obj); err != nil {
+				t.Fatalf("%s (%s): %+v", name, kind, err)
+			}
+
+			addresses, refs := getTestEndpointAddresses(t, obj)
+			if !reflect.DeepEqual(addresses, test.wanted) {
+				t.Fatalf("%s (%s): %v != %v", name, kind, addresses, test.wanted)
+			}
+			if refs > 0 {
+				t.Fatalf("%s (%s): node names and target references are kept", name, kind)
+			}
+		}
+	}
+}
+
+// getTestEndpointAddresses returns the addresses of the endpoints and the number of their node names and target
+// references
+func getTestEndpointAddresses(t *testing.T, obj client.Object) ([]string, int) {
+	t.Helper()
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var addresses []string
+	var refs int
+	if obj.GetObjectKind().GroupVersionKind().Kind == "EndpointSlice" {
+		slice := &discoveryv1.EndpointSlice{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, slice); err != nil {
+			t.Fatal(err)
+		}
+		for _, endpoint := range slice.Endpoints {
+			addresses = append(addresses, endpoint.Addresses...)
+			if endpoint.NodeName != nil || endpoint.TargetRef != nil {
+				refs++
+			}
+		}
+
+		return addresses, refs
+	}
+
+	endpoints := &corev1.Endpoints{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, endpoints); err != nil {
+		t.Fatal(err)
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) == 0 && len(subset.NotReadyAddresses) == 0 {
+			t.Fatalf("empty subset is kept: %+v", endpoints.Subsets)
+		}
+		for _, address := range append(subset.Addresses, subset.NotReadyAddresses...) {
+			addresses = append(addresses, address.IP)
+			if address.NodeName != nil || address.TargetRef != nil {
+				refs++
+			}
+		}
+	}
+
+	return addresses, refs
+}
+
+func TestAttachEndpoints(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		kind         string
+		selector     map[string]string
+		clusterIP    string
+		wantSelected bool
+	}{
+		"slice of selectorless service": {
+			kind:      "EndpointSlice",
+			clusterIP: "10.96.0.10",
+		},
+		"slice of headless service": {
+			kind:      "EndpointSlice",
+			selector:  map[string]string{"app": "demo"},
+			clusterIP: corev1.ClusterIPNone,
+		},
+		"slice of selected service": {
+			kind:         "EndpointSlice",
+			selector:     map[string]string{"app": "demo"},
+			clusterIP:    "10.96.0.10",
+			wantSelected: true,
+		},
+		"endpoints of selectorless service": {
+			kind:      "Endpoints",
+			clusterIP: corev1.ClusterIPNone,
+		},
+		"endpoints of headless service": {
+			kind:         "Endpoints",
+			selector:     map[string]string{"app": "demo"},
+			clusterIP:    corev1.ClusterIPNone,
+			wantSelected: true,
+		},
+	}
+
+	for name, test := range tests {
+		service := &corev1.Service{
+			ObjectMeta: v1.ObjectMeta{Name: "demo-local", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Selector:  test.selector,
+				ClusterIP: test.clusterIP,
+			},
+		}
+
+		obj := &unstructured.Unstructured{}
+		if test.kind == "EndpointSlice" {
+			obj.SetAPIVersion("discovery.k8s.io/v1")
+			obj.SetLabels(map[string]string{discoveryv1.LabelServiceName: "demo", discoveryv1.LabelManagedBy: "endpointslice-controller.k8s.io"})
+		} else {
+			obj.SetAPIVersion("v1")
+		}
+		obj.SetKind(test.kind)
+		obj.SetName("demo")
+
+		err := util.AttachEndpoints(obj, service)
+		if test.wantSelected {
+			if !errors.Is(err, util.ErrEndpointsServiceSelected) {
+				t.Fatalf("%s: expected selected service error, got %v", name, err)
+			}
+
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %+v", name, err)
+		}
+
+		if got := util.GetEndpointsServiceName(obj); got != service.GetName() {
+			t.Fatalf("%s: endpoints are attached to %q", name, got)
+		}
+		if test.kind == "EndpointSlice" && obj.GetLabels()[discoveryv1.LabelManagedBy] != util.EndpointSliceManager {
+			t.Fatalf("%s: slice is managed by %q", name, obj.GetLabels()[discoveryv1.LabelManagedBy])
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultFailureThreshold is the number of consecutive failures after which an object is not retried anymore
+const DefaultFailureThreshold = 20
+
+// FailedObject is an object which failed to sync too many times in a row, it is not retried until its source changes
+type FailedObject struct {
+	Key                   types.NamespacedName
+	SourceResourceVersion string
+	Failures              int
+	LastError             string
+	Since                 time.Time
+}
+
+// FailureTracker counts the consecutive sync failures of the objects and parks the ones reaching the threshold
+type FailureTracker struct {
+	threshold int
+
+	failures  map[types.NamespacedName]int
+	lastError map[types.NamespacedName]string
+	parked    map[types.NamespacedName]FailedObject
+	mu        sync.Mutex
+}
+
+// NewFailureTracker returns a tracker parking the objects after threshold consecutive failures, 0 never parks them
+func NewFailureTracker(threshold int) *FailureTracker {
+	return &FailureTracker{
+		threshold: threshold,
+
+		failures:  make(map[types.NamespacedName]int),
+		lastError: make(map[types.NamespacedName]string),
+		parked:    make(map[types.NamespacedName]FailedObject),
+	}
+}
+
+// Failed records a failed sync of the object and returns whether it failed too many times in a row
+func (t *FailureTracker) Failed(key types.NamespacedName, err error) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.failures[key]++
+	t.lastError[key] = err.Error()
+
+	return t.threshold > 0 && t.failures[key] >= t.threshold
+}
+
+// Succeeded forgets the failures of the object
+func (t *FailureTracker) Succeeded(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.failures, key)
+	delete(t.lastError, key)
+}
+
+// Park stops retrying the object until its source changes from the given resource version
+func (t *FailureTracker) Park(key types.NamespacedName, sourceResourceVersion string, now time.Time) FailedObject {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	failed := FailedObject{
+		Key:                   key,
+		SourceResourceVersion: sourceResourceVersion,
+		Failures:              t.failures[key],
+		LastError:             t.lastError[key],
+		Since:                 now,
+	}
+	t.parked[key] = failed
+	delete(t.failures, key)
+	delete(t.lastError, key)
+
+	return failed
+}
+
+// IsParked returns whether the object is parked and its source is unchanged
+func (t *FailureTracker) IsParked(key types.NamespacedName, sourceResourceVersion string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	failed, ok := t.parked[key]
+
+	return ok && failed.SourceResourceVersion == sourceResourceVersion
+}
+
+// Unpark retries the object and returns whether it was parked
+func (t *FailureTracker) Unpark(key types.NamespacedName) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, ok := t.parked[key]
+	delete(t.parked, key)
+
+	return ok
+}
+
+// List returns the parked objects ordered by their keys
+func (t *FailureTracker) List() []FailedObject {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	objects := make([]FailedObject, 0, len(t.parked))
+	for _, failed := range t.parked {
+		objects = append(objects, failed)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key.String() < objects[j].Key.String()
+	})
+
+	return objects
+}
+
+// Reset forgets every failure and parked object, and returns whether any object was parked
+func (t *FailureTracker) Reset() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parked := len(t.parked) > 0
+	t.failures = make(map[types.NamespacedName]int)
+	t.lastError = make(map[types.NamespacedName]string)
+	t.parked = make(map[types.NamespacedName]FailedObject)
+
+	return parked
+}
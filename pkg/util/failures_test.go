@@ -0,0 +1,89 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestFailureTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker := util.NewFailureTracker(3)
+	now := time.Now()
+	key := types.NamespacedName{Namespace: "default", Name: "rejected"}
+	other := types.NamespacedName{Namespace: "default", Name: "flaky"}
+
+	for i := 0; i < 2; i++ {
+		if tracker.Failed(key, errors.New("denied by webhook")) {
+			t.Fatalf("object reached the threshold after %d failures", i+1)
+		}
+	}
+
+	// failures which are not consecutive do not reach the threshold
+	tracker.Failed(other, errors.New("conflict"))
+	tracker.Failed(other, errors.New("conflict"))
+	tracker.Succeeded(other)
+	if tracker.Failed(other, errors.New("conflict")) {
+		t.Fatal("a success must reset the failures")
+	}
+
+	if !tracker.Failed(key, errors.New("denied by webhook: invalid value")) {
+		t.Fatal("object did not reach the threshold after 3 failures")
+	}
+
+	failed := tracker.Park(key, "42", now)
+	if failed.Failures != 3 || failed.LastError != "denied by webhook: invalid value" || failed.SourceResourceVersion != "42" {
+		t.Fatalf("unexpected failed object: %+v", failed)
+	}
+
+	if !tracker.IsParked(key, "42") {
+		t.Fatal("parked object with unchanged source must stay parked")
+	}
+	if tracker.IsParked(key, "43") {
+		t.Fatal("parked object with changed source must be retried")
+	}
+	if list := tracker.List(); len(list) != 1 || list[0].Key != key {
+		t.Fatalf("unexpected parked objects: %+v", list)
+	}
+
+	if !tracker.Unpark(key) || tracker.Unpark(key) || tracker.IsParked(key, "42") {
+		t.Fatal("unparked object must be retried")
+	}
+
+	tracker.Park(key, "42", now)
+	if !tracker.Reset() || len(tracker.List()) != 0 || tracker.Reset() {
+		t.Fatal("reset must retry every parked object")
+	}
+}
+
+func TestFailureTrackerDisabled(t *testing.T) {
+	t.Parallel()
+
+	tracker := util.NewFailureTracker(0)
+	key := types.NamespacedName{Namespace: "default", Name: "rejected"}
+
+	for i := 0; i < 100; i++ {
+		if tracker.Failed(key, errors.New("denied by webhook")) {
+			t.Fatal("objects must be retried forever with a threshold of 0")
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"strconv"
+
+	"emperror.dev/errors"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// FormatVersion is the version of every format the controller persists on
+// the objects it writes (controller annotations, labels and their encodings).
+//
+// Compatibility contract for rolling upgrades: readers accept the current and
+// the previous version, writers emit the previous version until they are
+// explicitly switched forward after every replica runs the new code.
+type FormatVersion int
+
+const (
+	// FormatVersionV1 is the format written before format versioning was
+	// introduced, objects in this format carry no format version annotation.
+	FormatVersionV1 FormatVersion = 1
+	// FormatVersionV2 marks the written objects with the format version annotation.
+	FormatVersionV2 FormatVersion = 2
+
+	CurrentFormatVersion  = FormatVersionV2
+	PreviousFormatVersion = FormatVersionV1
+)
+
+var ErrUnsupportedFormatVersion = errors.New("unsupported format version")
+
+func (v FormatVersion) String() string {
+	return strconv.Itoa(int(v))
+}
+
+// IsReadable returns whether the controller understands objects written in this format version.
+func (v FormatVersion) IsReadable() bool {
+	return v == CurrentFormatVersion || v == PreviousFormatVersion
+}
+
+// IsWritable returns whether the controller is able to emit this format version.
+func (v FormatVersion) IsWritable() bool {
+	return v.IsReadable()
+}
+
+// ParseFormatVersion parses a format version, the zero value means the previous version.
+func ParseFormatVersion(version int) (FormatVersion, error) {
+	if version == 0 {
+		return PreviousFormatVersion, nil
+	}
+
+	v := FormatVersion(version)
+	if !v.IsWritable() {
+		return 0, errors.WithDetails(ErrUnsupportedFormatVersion, "version", version)
+	}
+
+	return v, nil
+}
+
+// GetFormatVersion returns the format version the annotations were written in.
+func GetFormatVersion(annotations map[string]string) (FormatVersion, error) {
+	value, ok := annotations[clusterregistryv1alpha1.FormatVersionAnnotation]
+	if !ok {
+		return FormatVersionV1, nil
+	}
+
+	version, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.WrapIfWithDetails(ErrUnsupportedFormatVersion, "could not parse format version", "version", value)
+	}
+
+	v := FormatVersion(version)
+	if !v.IsReadable() {
+		return v, errors.WithDetails(ErrUnsupportedFormatVersion, "version", value)
+	}
+
+	return v, nil
+}
+
+// SetFormatVersion marks the annotations with the given format version.
+func SetFormatVersion(annotations map[string]string, version FormatVersion) {
+	if version == FormatVersionV1 {
+		delete(annotations, clusterregistryv1alpha1.FormatVersionAnnotation)
+
+		return
+	}
+
+	annotations[clusterregistryv1alpha1.FormatVersionAnnotation] = version.String()
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestGetFormatVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		annotations map[string]string
+		version     util.FormatVersion
+		wantErr     bool
+	}{
+		"missing annotation": {
+			annotations: map[string]string{},
+			version:     util.FormatVersionV1,
+		},
+		"current version": {
+			annotations: map[string]string{clusterregistryv1alpha1.FormatVersionAnnotation: util.CurrentFormatVersion.String()},
+			version:     util.CurrentFormatVersion,
+		},
+		"newer version": {
+			annotations: map[string]string{clusterregistryv1alpha1.FormatVersionAnnotation: "99"},
+			wantErr:     true,
+		},
+		"invalid version": {
+			annotations: map[string]string{clusterregistryv1alpha1.FormatVersionAnnotation: "invalid"},
+			wantErr:     true,
+		},
+	}
+
+	for name, test := range tests {
+		version, err := util.GetFormatVersion(test.annotations)
+		if test.wantErr {
+			if err == nil {
+				t.Fatalf("%s: expected error", name)
+			}
+
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %+v", name, err)
+		}
+		if version != test.version {
+			t.Fatalf("%s: %s != %s", name, version, test.version)
+		}
+	}
+}
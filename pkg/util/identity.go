@@ -0,0 +1,64 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/rest"
+)
+
+// ServiceAccountUsername returns the username a service account is authenticated and impersonated as
+func ServiceAccountUsername(namespace, name string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
+
+// RuleUserAgent returns the user agent of the requests of a rule, the name of the rule is appended to the user agent
+// of the controller, so the writes of each rule can be told apart in the audit logs of the API server
+func RuleUserAgent(userAgent string, rule string) string {
+	if userAgent == "" {
+		userAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	return fmt.Sprintf("%s rule/%s", userAgent, rule)
+}
+
+// fieldManagerMaxLength is the longest field manager name the API server records
+const fieldManagerMaxLength = 128
+
+// FieldManagerFromUserAgent returns the field manager the API server records the writes of a client with the user
+// agent as when the writes do not set one, the user agent up to its first slash
+func FieldManagerFromUserAgent(userAgent string) string {
+	manager := strings.Split(userAgent, "/")[0]
+	if len(manager) > fieldManagerMaxLength {
+		manager = manager[:fieldManagerMaxLength]
+	}
+
+	return manager
+}
+
+// RuleRESTConfig returns a copy of the config with the user agent of the rule, impersonating the user if set
+func RuleRESTConfig(config *rest.Config, rule string, user string) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.UserAgent = RuleUserAgent(config.UserAgent, rule)
+	if user != "" {
+		config.Impersonate = rest.ImpersonationConfig{
+			UserName: user,
+		}
+	}
+
+	return config
+}
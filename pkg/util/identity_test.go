@@ -0,0 +1,115 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestRuleRESTConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		userAgent string
+		user      string
+		expected  string
+	}{
+		"controller identity": {
+			userAgent: "cluster-registry-controller",
+			expected:  "cluster-registry-controller rule/test",
+		},
+		"service account": {
+			userAgent: "cluster-registry-controller",
+			user:      util.ServiceAccountUsername("cluster-registry", "syncer"),
+			expected:  "cluster-registry-controller rule/test",
+		},
+		"default user agent": {},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			base := &rest.Config{
+				Host:      "https://example.com",
+				UserAgent: test.userAgent,
+			}
+			config := util.RuleRESTConfig(base, "test", test.user)
+
+			if base.UserAgent != test.userAgent || base.Impersonate.UserName != "" {
+				t.Fatalf("base config was modified: %+v", base)
+			}
+			if config.Host != base.Host {
+				t.Fatalf("expected host %q, got %q", base.Host, config.Host)
+			}
+			if test.expected != "" && config.UserAgent != test.expected {
+				t.Fatalf("expected user agent %q, got %q", test.expected, config.UserAgent)
+			}
+			if !strings.HasSuffix(config.UserAgent, " rule/test") {
+				t.Fatalf("user agent %q does not name the rule", config.UserAgent)
+			}
+			if config.Impersonate.UserName != test.user {
+				t.Fatalf("expected to impersonate %q, got %q", test.user, config.Impersonate.UserName)
+			}
+		})
+	}
+}
+
+func TestServiceAccountUsername(t *testing.T) {
+	t.Parallel()
+
+	if username := util.ServiceAccountUsername("cluster-registry", "syncer"); username != "system:serviceaccount:cluster-registry:syncer" {
+		t.Fatalf("unexpected username %q", username)
+	}
+}
+
+func TestFieldManagerFromUserAgent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		userAgent string
+		expected  string
+	}{
+		"default user agent": {
+			userAgent: "manager/v0.0.0 (linux/amd64) kubernetes/$Format rule/test",
+			expected:  "manager",
+		},
+		"controller identity": {
+			userAgent: "cluster-registry-controller rule/test",
+			expected:  "cluster-registry-controller rule",
+		},
+		"too long": {
+			userAgent: strings.Repeat("a", 200),
+			expected:  strings.Repeat("a", 128),
+		},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if manager := util.FieldManagerFromUserAgent(test.userAgent); manager != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, manager)
+			}
+		})
+	}
+}
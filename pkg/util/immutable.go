@@ -0,0 +1,138 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// immutableObjectMessage is in the errors of the updates of the ConfigMaps and Secrets marked immutable
+const immutableObjectMessage = "field is immutable when `immutable` is set"
+
+// ImmutableFieldCauses returns the sorted paths of the fields which made the API server reject an update
+// because they can not be changed, e.g. spec.selector of a Deployment. Nil is returned for every other error.
+func ImmutableFieldCauses(err error) []string {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) {
+		return nil
+	}
+
+	status := statusErr.Status()
+	if status.Reason != metav1.StatusReasonInvalid || status.Details == nil {
+		return nil
+	}
+
+	fields := make(map[string]struct{})
+	for _, cause := range status.Details.Causes {
+		if cause.Field == "" {
+			continue
+		}
+		if cause.Type == metav1.CauseType(field.ErrorTypeForbidden) || strings.Contains(cause.Message, "immutable") {
+			fields[cause.Field] = struct{}{}
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	result := make([]string, 0, len(fields))
+	for path := range fields {
+		result = append(result, path)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// IsImmutableObjectError returns whether the API server rejected the update of a ConfigMap or a Secret, because the
+// existing object is marked immutable, so its content can only be changed by deleting and creating it again
+func IsImmutableObjectError(err error) bool {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+
+	status := statusErr.Status()
+	if (status.Reason != metav1.StatusReasonInvalid && status.Reason != metav1.StatusReasonForbidden) || status.Details == nil {
+		return false
+	}
+	if status.Details.Group != "" || (status.Details.Kind != "ConfigMap" && status.Details.Kind != "Secret") {
+		return false
+	}
+
+	if strings.Contains(status.Message, immutableObjectMessage) {
+		return true
+	}
+	for _, cause := range status.Details.Causes {
+		if strings.Contains(cause.Message, immutableObjectMessage) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetFieldValues returns the values of the fields of the object, using the field paths of API server errors,
+// e.g. spec.template.spec.containers[0].image. Missing fields have nil values.
+func GetFieldValues(obj client.Object, fields []string) (map[string]interface{}, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not convert object to unstructured")
+	}
+
+	values := make(map[string]interface{}, len(fields))
+	for _, path := range fields {
+		values[path] = getFieldValue(content, path)
+	}
+
+	return values, nil
+}
+
+func getFieldValue(content interface{}, path string) interface{} {
+	value := content
+	for _, segment := range strings.Split(path, ".") {
+		keys := []string{segment}
+		if i := strings.Index(segment, "["); i > 0 && strings.HasSuffix(segment, "]") {
+			keys = []string{segment[:i], segment[i+1 : len(segment)-1]}
+		}
+
+		for _, key := range keys {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				value = v[key]
+			case []interface{}:
+				index, err := strconv.Atoi(key)
+				if err != nil || index < 0 || index >= len(v) {
+					return nil
+				}
+				value = v[index]
+			default:
+				return nil
+			}
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"emperror.dev/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func readStatusError(t *testing.T, filename string) error {
+	t.Helper()
+
+	content, err := os.ReadFile("testdata/immutable/" + filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := v1.Status{}
+	if err := json.Unmarshal(content, &status); err != nil {
+		t.Fatal(err)
+	}
+
+	return &apierrors.StatusError{ErrStatus: status}
+}
+
+func TestImmutableFieldCauses(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		filename string
+		fields   []string
+	}{
+		"deployment selector": {
+			filename: "deployment-selector.json",
+			fields:   []string{"spec.selector"},
+		},
+		"job template": {
+			filename: "job-template.json",
+			fields:   []string{"spec.template"},
+		},
+		"pvc storage request": {
+			filename: "pvc-storage.json",
+			fields:   []string{"spec.resources.requests.storage"},
+		},
+		"invalid but mutable field": {
+			filename: "deployment-replicas.json",
+		},
+	}
+
+	for name, test := range tests {
+		// the error is wrapped the same way as the errors returned by the resource reconciler
+		err := errors.WrapIfWithDetails(readStatusError(t, test.filename), "updating resource failed", "name", name)
+
+		if fields := util.ImmutableFieldCauses(err); !reflect.DeepEqual(fields, test.fields) {
+			t.Fatalf("%s: %v != %v", name, fields, test.fields)
+		}
+	}
+
+	if fields := util.ImmutableFieldCauses(apierrors.NewConflict(corev1.Resource("configmaps"), "test", errors.New("conflict"))); fields != nil {
+		t.Fatalf("unexpected fields for a conflict error: %v", fields)
+	}
+}
+
+func TestIsImmutableObjectError(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		err       error
+		immutable bool
+	}{
+		"immutable configmap": {
+			err:       readStatusError(t, "configmap-immutable.json"),
+			immutable: true,
+		},
+		"immutable secret": {
+			err:       readStatusError(t, "secret-immutable.json"),
+			immutable: true,
+		},
+		"immutable field of another kind": {
+			err: readStatusError(t, "deployment-selector.json"),
+		},
+		"conflict": {
+			err: apierrors.NewConflict(corev1.Resource("configmaps"), "test", errors.New("conflict")),
+		},
+		"not an API error": {
+			err: errors.New("field is immutable when `immutable` is set"),
+		},
+	}
+
+	for name, test := range tests {
+		// the error is wrapped the same way as the errors returned by the resource reconciler
+		err := errors.WrapIfWithDetails(test.err, "updating resource failed", "name", name)
+
+		if immutable := util.IsImmutableObjectError(err); immutable != test.immutable {
+			t.Fatalf("%s: %t != %t", name, immutable, test.immutable)
+		}
+	}
+}
+
+func TestGetFieldValues(t *testing.T) {
+	t.Parallel()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:   "web",
+			Labels: map[string]string{"app": "web"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &v1.LabelSelector{
+				MatchLabels: map[string]string{"app": "web"},
+			},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "web",
+							Image: "web:v1",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	values, err := util.GetFieldValues(deployment, []string{
+		"spec.selector",
+		"spec.template.spec.containers[0].image",
+		"spec.template.spec.containers[1].image",
+		"metadata.labels[app]",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wanted := map[string]interface{}{
+		"spec.selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{"app": "web"},
+		},
+		"spec.template.spec.containers[0].image": "web:v1",
+		"spec.template.spec.containers[1].image": nil,
+		"metadata.labels[app]":                   "web",
+	}
+	if !reflect.DeepEqual(values, wanted) {
+		t.Fatalf("%v != %v", values, wanted)
+	}
+}
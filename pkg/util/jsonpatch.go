@@ -0,0 +1,114 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"text/template"
+
+	"emperror.dev/errors"
+	"github.com/Masterminds/sprig"
+	jsonpatch "github.com/evanphx/json-patch"
+
+	"github.com/banzaicloud/operator-tools/pkg/utils"
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+var ErrJSONPatchFailed = errors.New("could not apply json patch")
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+func JSONPatchOperationExecuteTemplates(operations []clusterregistryv1alpha1.JSONPatchOperation, data interface{}) ([]clusterregistryv1alpha1.JSONPatchOperation, error) {
+	result := make([]clusterregistryv1alpha1.JSONPatchOperation, 0)
+	for _, o := range operations {
+		if o.Value == nil {
+			result = append(result, o)
+
+			continue
+		}
+
+		t, err := template.New("").Funcs(sprig.TxtFuncMap()).Parse(*o.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		var tpl bytes.Buffer
+		err = t.Execute(&tpl, data)
+		if err != nil {
+			return nil, err
+		}
+
+		o.Value = utils.StringPointer(tpl.String())
+		result = append(result, o)
+	}
+
+	return result, nil
+}
+
+// ApplyJSONPatchOperations applies the operations one by one on the JSON document.
+// Optional operations which could not be applied are skipped and returned as the second return value.
+func ApplyJSONPatchOperations(doc []byte, operations []clusterregistryv1alpha1.JSONPatchOperation) ([]byte, []error, error) {
+	skipped := make([]error, 0)
+
+	for i, o := range operations {
+		patched, err := applyJSONPatchOperation(doc, o)
+		if err != nil {
+			err = errors.WrapWithDetails(ErrJSONPatchFailed, err.Error(), "index", i, "op", o.Op, "path", o.Path)
+			if o.Optional {
+				skipped = append(skipped, err)
+
+				continue
+			}
+
+			return nil, skipped, err
+		}
+
+		doc = patched
+	}
+
+	return doc, skipped, nil
+}
+
+func applyJSONPatchOperation(doc []byte, o clusterregistryv1alpha1.JSONPatchOperation) ([]byte, error) {
+	op := jsonPatchOperation{
+		Op:   string(o.Op),
+		Path: o.Path,
+		From: o.From,
+	}
+	if o.Value != nil {
+		if !json.Valid([]byte(*o.Value)) {
+			return nil, errors.New("value is not valid json")
+		}
+		op.Value = json.RawMessage(*o.Value)
+	}
+
+	raw, err := json.Marshal([]jsonPatchOperation{op})
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := jsonpatch.DecodePatch(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return patch.Apply(doc)
+}
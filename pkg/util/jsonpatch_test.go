@@ -0,0 +1,172 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+	"github.com/banzaicloud/operator-tools/pkg/utils"
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestApplyJSONPatchOperations(t *testing.T) {
+	t.Parallel()
+
+	doc := []byte(`{"metadata":{"name":"test","labels":{"a":"b"}},"spec":{"ports":[{"port":80},{"port":443}]}}`)
+
+	tests := map[string]struct {
+		operations []clusterregistryv1alpha1.JSONPatchOperation
+		wanted     string
+		skipped    int
+		wantErr    bool
+	}{
+		"remove list entry by index": {
+			operations: []clusterregistryv1alpha1.JSONPatchOperation{
+				{Op: clusterregistryv1alpha1.JSONPatchOperationTypeRemove, Path: "/spec/ports/0"},
+			},
+			wanted: `{"metadata":{"name":"test","labels":{"a":"b"}},"spec":{"ports":[{"port":443}]}}`,
+		},
+		"add value": {
+			operations: []clusterregistryv1alpha1.JSONPatchOperation{
+				{Op: clusterregistryv1alpha1.JSONPatchOperationTypeAdd, Path: "/metadata/labels/c", Value: utils.StringPointer(`"d"`)},
+			},
+			wanted: `{"metadata":{"name":"test","labels":{"a":"b","c":"d"}},"spec":{"ports":[{"port":80},{"port":443}]}}`,
+		},
+		"remove missing path": {
+			operations: []clusterregistryv1alpha1.JSONPatchOperation{
+				{Op: clusterregistryv1alpha1.JSONPatchOperationTypeRemove, Path: "/metadata/annotations"},
+			},
+			wantErr: true,
+		},
+		"optional remove missing path": {
+			operations: []clusterregistryv1alpha1.JSONPatchOperation{
+				{Op: clusterregistryv1alpha1.JSONPatchOperationTypeRemove, Path: "/metadata/annotations", Optional: true},
+				{Op: clusterregistryv1alpha1.JSONPatchOperationTypeRemove, Path: "/metadata/labels/a"},
+			},
+			wanted:  `{"metadata":{"name":"test","labels":{}},"spec":{"ports":[{"port":80},{"port":443}]}}`,
+			skipped: 1,
+		},
+		"invalid value": {
+			operations: []clusterregistryv1alpha1.JSONPatchOperation{
+				{Op: clusterregistryv1alpha1.JSONPatchOperationTypeAdd, Path: "/metadata/labels/c", Value: utils.StringPointer(`d`)},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		result, skipped, err := util.ApplyJSONPatchOperations(doc, test.operations)
+		if test.wantErr {
+			if !errors.Is(err, util.ErrJSONPatchFailed) {
+				t.Fatalf("%s: expected json patch error, got %v", name, err)
+			}
+
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %+v", name, err)
+		}
+		if len(skipped) != test.skipped {
+			t.Fatalf("%s: %d operations skipped instead of %d", name, len(skipped), test.skipped)
+		}
+		assertJSONEqual(t, name, result, test.wanted)
+	}
+}
+
+// JSON patches are applied after the overlay patches, so they must see the result of the overrides.
+func TestJSONPatchAfterOverlayPatch(t *testing.T) {
+	t.Parallel()
+
+	svc := &corev1.Service{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: "test-service",
+			Labels: map[string]string{
+				"original": "true",
+			},
+		},
+	}
+
+	gvk := resources.ConvertGVK(svc.GroupVersionKind())
+	patchFunc, err := resources.PatchYAMLModifier(resources.K8SResourceOverlay{
+		GVK: &gvk,
+		Patches: []resources.K8SResourceOverlayPatch{
+			{
+				Type:       resources.ReplaceOverlayPatchType,
+				Path:       utils.StringPointer("/metadata/labels"),
+				Value:      utils.StringPointer(`{"overridden": "true"}`),
+				ParseValue: true,
+			},
+		},
+	}, resources.NewObjectParser(scheme.Scheme))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patched, err := patchFunc(svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := json.Marshal(patched)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, _, err := util.ApplyJSONPatchOperations(doc, []clusterregistryv1alpha1.JSONPatchOperation{
+		{Op: clusterregistryv1alpha1.JSONPatchOperationTypeTest, Path: "/metadata/labels/overridden", Value: utils.StringPointer(`"true"`)},
+		{Op: clusterregistryv1alpha1.JSONPatchOperationTypeMove, From: "/metadata/labels/overridden", Path: "/metadata/labels/moved"},
+	})
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	var object corev1.Service
+	if err := json.Unmarshal(result, &object); err != nil {
+		t.Fatal(err)
+	}
+	if object.GetLabels()["moved"] != "true" || len(object.GetLabels()) != 1 {
+		t.Fatalf("unexpected labels: %v", object.GetLabels())
+	}
+}
+
+func assertJSONEqual(t *testing.T, name string, actual []byte, wanted string) {
+	t.Helper()
+
+	var a, w interface{}
+	if err := json.Unmarshal(actual, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(wanted), &w); err != nil {
+		t.Fatal(err)
+	}
+
+	aj, _ := json.Marshal(a)
+	wj, _ := json.Marshal(w)
+	if string(aj) != string(wj) {
+		t.Fatalf("%s: %s != %s", name, aj, wj)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"regexp"
+	"strings"
+
+	"emperror.dev/errors"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// protectedMetadataKeys are the annotations and labels the controller tracks the synced objects with, the prefixes and
+// regular expressions of the mutations never remove them
+var protectedMetadataKeys = map[string]struct{}{
+	clusterregistryv1alpha1.OwnershipAnnotation:     {},
+	clusterregistryv1alpha1.OwnerRuleAnnotation:     {},
+	clusterregistryv1alpha1.OriginalGVKAnnotation:   {},
+	clusterregistryv1alpha1.OriginalNameLabel:       {},
+	clusterregistryv1alpha1.OriginalNamespaceLabel:  {},
+	clusterregistryv1alpha1.SyncOriginAnnotation:    {},
+	clusterregistryv1alpha1.FormatVersionAnnotation: {},
+	clusterregistryv1alpha1.SyncAnchorRuleLabel:     {},
+}
+
+// KeyRemovals removes the annotations and labels by the key prefixes and regular expressions of the mutations of a
+// rule. The regular expressions are compiled once, when the rule is loaded.
+type KeyRemovals struct {
+	regexes map[string]*regexp.Regexp
+}
+
+// NewKeyRemovals compiles the regular expressions of the removed annotation and label keys of the sync rules
+func NewKeyRemovals(rules []clusterregistryv1alpha1.SyncRule) (*KeyRemovals, error) {
+	r := &KeyRemovals{
+		regexes: make(map[string]*regexp.Regexp),
+	}
+
+	for _, rule := range rules {
+		exprs := append(append([]string{}, rule.Mutations.GetAnnotations().RemoveRegexes...), rule.Mutations.GetLabels().RemoveRegexes...)
+		for _, expr := range exprs {
+			if _, ok := r.regexes[expr]; ok {
+				continue
+			}
+
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "invalid regular expression of removed keys", "regex", expr)
+			}
+			r.regexes[expr] = re
+		}
+	}
+
+	return r, nil
+}
+
+// Remove deletes the keys with any of the prefixes or matching any of the regular expressions from the annotations or
+// labels, except for the protected keys of the controller. The regular expressions must be compiled by NewKeyRemovals.
+func (r *KeyRemovals) Remove(keys map[string]string, prefixes []string, regexes []string) error {
+	if len(prefixes) == 0 && len(regexes) == 0 {
+		return nil
+	}
+
+	compiled := make([]*regexp.Regexp, 0, len(regexes))
+	for _, expr := range regexes {
+		re, ok := r.regexes[expr]
+		if !ok {
+			return errors.NewWithDetails("regular expression of removed keys is not compiled", "regex", expr)
+		}
+		compiled = append(compiled, re)
+	}
+
+	for key := range keys {
+		if _, ok := protectedMetadataKeys[key]; ok {
+			continue
+		}
+
+		if matchesAnyPrefix(key, prefixes) || matchesAnyRegex(key, compiled) {
+			delete(keys, key)
+		}
+	}
+
+	return nil
+}
+
+func matchesAnyPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesAnyRegex(key string, regexes []*regexp.Regexp) bool {
+	for _, re := range regexes {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"reflect"
+	"testing"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestKeyRemovals(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		prefixes []string
+		regexes  []string
+		keys     map[string]string
+		expected map[string]string
+	}{
+		"prefix": {
+			prefixes: []string{"kubectl.kubernetes.io/"},
+			keys: map[string]string{
+				"kubectl.kubernetes.io/restartedAt": "now",
+				"app":                               "demo",
+			},
+			expected: map[string]string{
+				"app": "demo",
+			},
+		},
+		"regex": {
+			regexes: []string{`^deployment\.kubernetes\.io/`, `^operator\.example\.com/.*-hash$`},
+			keys: map[string]string{
+				"deployment.kubernetes.io/revision":    "3",
+				"operator.example.com/spec-hash":       "abc",
+				"operator.example.com/managed-by":      "operator",
+				"example.com/deployment.kubernetes.io": "kept",
+			},
+			expected: map[string]string{
+				"operator.example.com/managed-by":      "operator",
+				"example.com/deployment.kubernetes.io": "kept",
+			},
+		},
+		"protected keys": {
+			prefixes: []string{"cluster-registry.k8s.cisco.com/", "k8s.cisco.com/"},
+			regexes:  []string{".*"},
+			keys: map[string]string{
+				clusterregistryv1alpha1.OwnershipAnnotation:     "cluster-id",
+				clusterregistryv1alpha1.OriginalGVKAnnotation:   "v1/ConfigMap",
+				clusterregistryv1alpha1.OwnerRuleAnnotation:     "rule",
+				clusterregistryv1alpha1.SourceClusterAnnotation: "source",
+				"app": "demo",
+			},
+			expected: map[string]string{
+				clusterregistryv1alpha1.OwnershipAnnotation:   "cluster-id",
+				clusterregistryv1alpha1.OriginalGVKAnnotation: "v1/ConfigMap",
+				clusterregistryv1alpha1.OwnerRuleAnnotation:   "rule",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			removals, err := util.NewKeyRemovals([]clusterregistryv1alpha1.SyncRule{
+				{
+					Mutations: clusterregistryv1alpha1.Mutations{
+						Annotations: &clusterregistryv1alpha1.AnnotationMutations{
+							RemovePrefixes: test.prefixes,
+							RemoveRegexes:  test.regexes,
+						},
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+
+			if err := removals.Remove(test.keys, test.prefixes, test.regexes); err != nil {
+				t.Fatalf("%+v", err)
+			}
+			if !reflect.DeepEqual(test.keys, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, test.keys)
+			}
+		})
+	}
+}
+
+func TestKeyRemovalsErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := util.NewKeyRemovals([]clusterregistryv1alpha1.SyncRule{
+		{
+			Mutations: clusterregistryv1alpha1.Mutations{
+				Labels: &clusterregistryv1alpha1.LabelMutations{
+					RemoveRegexes: []string{"(unclosed"},
+				},
+			},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected error for invalid regex")
+	}
+
+	removals, err := util.NewKeyRemovals(nil)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if err := removals.Remove(map[string]string{"key": "value"}, nil, []string{"^key$"}); err == nil {
+		t.Fatal("expected error for regex not compiled with the rule")
+	}
+}
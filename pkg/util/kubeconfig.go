@@ -90,14 +90,46 @@ func GetEndpointForClusterByNetwork(cluster *clusterregistryv1alpha1.Cluster, ne
 	return endpoint
 }
 
-func GetKubeconfigOverridesForClusterByNetwork(cluster *clusterregistryv1alpha1.Cluster, networkName string) (*clientcmd.ConfigOverrides, error) {
-	overrides := &clientcmd.ConfigOverrides{}
+// GetEndpointsForClusterByNetwork returns the endpoints of the cluster usable from the network in the order of
+// preference, the endpoints of the network come first, then the ones which are not network specific
+func GetEndpointsForClusterByNetwork(cluster *clusterregistryv1alpha1.Cluster, networkName string) []clusterregistryv1alpha1.KubernetesAPIEndpoint {
+	endpoints := make([]clusterregistryv1alpha1.KubernetesAPIEndpoint, 0)
+	for _, apiEndpoint := range cluster.Spec.KubernetesAPIEndpoints {
+		if apiEndpoint.ClientNetwork == networkName && networkName != "" {
+			endpoints = append(endpoints, apiEndpoint)
+		}
+	}
+	for _, apiEndpoint := range cluster.Spec.KubernetesAPIEndpoints {
+		if apiEndpoint.ClientNetwork == "" {
+			endpoints = append(endpoints, apiEndpoint)
+		}
+	}
+
+	return endpoints
+}
+
+// GetKubeconfigOverridesForClusterByNetwork returns the kubeconfig overrides of every endpoint of the cluster usable
+// from the network in the order of preference, or a single empty override if the cluster has no such endpoints
+func GetKubeconfigOverridesForClusterByNetwork(cluster *clusterregistryv1alpha1.Cluster, networkName string) ([]*clientcmd.ConfigOverrides, error) {
+	endpoints := GetEndpointsForClusterByNetwork(cluster, networkName)
+	if len(endpoints) == 0 {
+		return []*clientcmd.ConfigOverrides{{}}, nil
+	}
 
-	if len(cluster.Spec.KubernetesAPIEndpoints) == 0 {
-		return overrides, nil
+	overrides := make([]*clientcmd.ConfigOverrides, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		o, err := getKubeconfigOverridesForEndpoint(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
 	}
 
-	endpoint := GetEndpointForClusterByNetwork(cluster, networkName)
+	return overrides, nil
+}
+
+func getKubeconfigOverridesForEndpoint(endpoint clusterregistryv1alpha1.KubernetesAPIEndpoint) (*clientcmd.ConfigOverrides, error) {
+	overrides := &clientcmd.ConfigOverrides{}
 
 	if endpoint.ServerAddress != "" {
 		address, err := getURLWithHTTPSScheme(endpoint.ServerAddress)
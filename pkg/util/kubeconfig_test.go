@@ -0,0 +1,89 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestGetKubeconfigOverridesForClusterByNetwork(t *testing.T) {
+	t.Parallel()
+
+	cluster := &clusterregistryv1alpha1.Cluster{
+		Spec: clusterregistryv1alpha1.ClusterSpec{
+			KubernetesAPIEndpoints: []clusterregistryv1alpha1.KubernetesAPIEndpoint{
+				{ServerAddress: "generic-1:6443"},
+				{ServerAddress: "network-a-1:6443", ClientNetwork: "network-a"},
+				{ServerAddress: "network-b:6443", ClientNetwork: "network-b"},
+				{ServerAddress: "https://generic-2:6443"},
+				{ServerAddress: "network-a-2:6443", ClientNetwork: "network-a"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		cluster *clusterregistryv1alpha1.Cluster
+		network string
+		wanted  []string
+	}{
+		"network specific endpoints first": {
+			cluster: cluster,
+			network: "network-a",
+			wanted:  []string{"https://network-a-1:6443", "https://network-a-2:6443", "https://generic-1:6443", "https://generic-2:6443"},
+		},
+		"unknown network": {
+			cluster: cluster,
+			network: "network-c",
+			wanted:  []string{"https://generic-1:6443", "https://generic-2:6443"},
+		},
+		"no network": {
+			cluster: cluster,
+			wanted:  []string{"https://generic-1:6443", "https://generic-2:6443"},
+		},
+		"no endpoints": {
+			cluster: &clusterregistryv1alpha1.Cluster{},
+			network: "network-a",
+			wanted:  []string{""},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			overrides, err := util.GetKubeconfigOverridesForClusterByNetwork(test.cluster, test.network)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			servers := make([]string, 0, len(overrides))
+			for _, o := range overrides {
+				servers = append(servers, o.ClusterInfo.Server)
+			}
+			if len(servers) != len(test.wanted) {
+				t.Fatalf("wanted %v, got %v", test.wanted, servers)
+			}
+			for i := range servers {
+				if servers[i] != test.wanted[i] {
+					t.Fatalf("wanted %v, got %v", test.wanted, servers)
+				}
+			}
+		})
+	}
+}
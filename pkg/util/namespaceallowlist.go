@@ -0,0 +1,76 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"path"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NamespaceAllowList contains the glob patterns of the namespaces objects can be written into, regardless of the
+// rules. An empty allow list allows every namespace.
+type NamespaceAllowList struct {
+	patterns []string
+}
+
+// NewNamespaceAllowList parses the glob patterns of the allowed namespaces, e.g. team-a-*
+func NewNamespaceAllowList(patterns []string) (*NamespaceAllowList, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "invalid allowed namespace pattern", "pattern", pattern)
+		}
+	}
+
+	return &NamespaceAllowList{
+		patterns: patterns,
+	}, nil
+}
+
+// IsNamespaceAllowed returns whether objects can be written into the namespace
+func (a *NamespaceAllowList) IsNamespaceAllowed(namespace string) bool {
+	if a == nil || len(a.patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range a.patterns {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsAllowed returns whether the object can be written. Once the allow list is not empty, the only cluster scoped
+// objects which can be written are the allowed namespaces themselves.
+func (a *NamespaceAllowList) IsAllowed(obj client.Object) bool {
+	if a == nil || len(a.patterns) == 0 {
+		return true
+	}
+
+	if obj.GetNamespace() != "" {
+		return a.IsNamespaceAllowed(obj.GetNamespace())
+	}
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.GroupKind() == corev1.SchemeGroupVersion.WithKind("Namespace").GroupKind() {
+		return a.IsNamespaceAllowed(obj.GetName())
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestNewNamespaceAllowList(t *testing.T) {
+	t.Parallel()
+
+	if _, err := util.NewNamespaceAllowList([]string{"team-a-[*"}); err == nil {
+		t.Fatal("expected error of invalid pattern")
+	}
+}
+
+func TestNamespaceAllowList(t *testing.T) {
+	t.Parallel()
+
+	allowList, err := util.NewNamespaceAllowList([]string{"team-a-*", "shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		obj     client.Object
+		allowed bool
+	}{
+		"matching namespace": {
+			obj: &corev1.ConfigMap{
+				ObjectMeta: v1.ObjectMeta{Name: "demo", Namespace: "team-a-frontend"},
+			},
+			allowed: true,
+		},
+		"exact namespace": {
+			obj: &corev1.ConfigMap{
+				ObjectMeta: v1.ObjectMeta{Name: "demo", Namespace: "shared"},
+			},
+			allowed: true,
+		},
+		"other namespace": {
+			obj: &corev1.ConfigMap{
+				ObjectMeta: v1.ObjectMeta{Name: "demo", Namespace: "team-b-frontend"},
+			},
+		},
+		"allowed namespace object": {
+			obj: &corev1.Namespace{
+				TypeMeta:   v1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+				ObjectMeta: v1.ObjectMeta{Name: "team-a-backend"},
+			},
+			allowed: true,
+		},
+		"other namespace object": {
+			obj: &corev1.Namespace{
+				TypeMeta:   v1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+				ObjectMeta: v1.ObjectMeta{Name: "kube-system"},
+			},
+		},
+		"cluster scoped object": {
+			obj: &rbacv1.ClusterRole{
+				TypeMeta:   v1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole"},
+				ObjectMeta: v1.ObjectMeta{Name: "team-a-admin"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		if allowed := allowList.IsAllowed(test.obj); allowed != test.allowed {
+			t.Fatalf("%s: object is expected to be allowed: %t", name, test.allowed)
+		}
+	}
+
+	var empty *util.NamespaceAllowList
+	if !empty.IsAllowed(&rbacv1.ClusterRole{}) {
+		t.Fatal("empty allow list is expected to allow every object")
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+var ErrNamespaceNotRouted = errors.New("no target namespace is mapped to the object")
+
+// RouteNamespace sets the namespace of the synced object from the metadata of its source object.
+// Cluster scoped objects are left untouched.
+func RouteNamespace(obj client.Object, source client.Object, routing clusterregistryv1alpha1.NamespaceRouting) error {
+	if source.GetNamespace() == "" {
+		return nil
+	}
+
+	namespace, routed := routing.Route(source)
+	if !routed {
+		return errors.WithDetails(ErrNamespaceNotRouted, "resource", client.ObjectKeyFromObject(source).String(), "policy", string(routing.GetUnmappedPolicy()))
+	}
+
+	obj.SetNamespace(namespace)
+
+	return nil
+}
+
+// IsClusterScoped returns whether the kind is cluster scoped according to the mapper
+func IsClusterScoped(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (bool, error) {
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not get rest mapping", "gvk", gvk.String())
+	}
+
+	return mapping.Scope.Name() == meta.RESTScopeNameRoot, nil
+}
+
+// GetStaleSyncedObjects returns the objects synced from the source object other than the current one,
+// e.g. the ones left in the previous namespace after the routing of the object changed
+func GetStaleSyncedObjects(objects []client.Object, source types.NamespacedName, current types.NamespacedName) []client.Object {
+	stale := make([]client.Object, 0)
+	for _, obj := range objects {
+		if GetSourceObjectKey(obj) != source || client.ObjectKeyFromObject(obj) == current {
+			continue
+		}
+
+		stale = append(stale, obj)
+	}
+
+	return stale
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestRouteNamespace(t *testing.T) {
+	t.Parallel()
+
+	teamRouting := clusterregistryv1alpha1.NamespaceRouting{
+		FromAnnotation: "owner-team",
+		Map: map[string]string{
+			"payments": "team-payments",
+			"search":   "team-search",
+		},
+	}
+
+	tests := map[string]struct {
+		routing     clusterregistryv1alpha1.NamespaceRouting
+		namespace   string
+		annotations map[string]string
+		labels      map[string]string
+		expected    string
+		err         error
+	}{
+		"mapped annotation": {
+			routing:     teamRouting,
+			namespace:   "default",
+			annotations: map[string]string{"owner-team": "search"},
+			expected:    "team-search",
+		},
+		"mapped label": {
+			routing: clusterregistryv1alpha1.NamespaceRouting{
+				FromLabel: "owner-team",
+				Map:       teamRouting.Map,
+			},
+			namespace: "default",
+			labels:    map[string]string{"owner-team": "payments"},
+			expected:  "team-payments",
+		},
+		"label is not read for annotation routing": {
+			routing:   teamRouting,
+			namespace: "default",
+			labels:    map[string]string{"owner-team": "payments"},
+			err:       util.ErrNamespaceNotRouted,
+		},
+		"unmapped value is skipped by default": {
+			routing:     teamRouting,
+			namespace:   "default",
+			annotations: map[string]string{"owner-team": "billing"},
+			err:         util.ErrNamespaceNotRouted,
+		},
+		"unmapped value fails": {
+			routing: clusterregistryv1alpha1.NamespaceRouting{
+				FromAnnotation: "owner-team",
+				Map:            teamRouting.Map,
+				UnmappedPolicy: clusterregistryv1alpha1.UnmappedNamespacePolicyFail,
+			},
+			namespace:   "default",
+			annotations: map[string]string{"owner-team": "billing"},
+			err:         util.ErrNamespaceNotRouted,
+		},
+		"unmapped value is routed to the default namespace": {
+			routing: clusterregistryv1alpha1.NamespaceRouting{
+				FromAnnotation:   "owner-team",
+				Map:              teamRouting.Map,
+				DefaultNamespace: "team-shared",
+				UnmappedPolicy:   clusterregistryv1alpha1.UnmappedNamespacePolicyDefault,
+			},
+			namespace:   "default",
+			annotations: map[string]string{"owner-team": "billing"},
+			expected:    "team-shared",
+		},
+		"missing key is routed to the default namespace": {
+			routing: clusterregistryv1alpha1.NamespaceRouting{
+				FromAnnotation:   "owner-team",
+				Map:              teamRouting.Map,
+				DefaultNamespace: "team-shared",
+				UnmappedPolicy:   clusterregistryv1alpha1.UnmappedNamespacePolicyDefault,
+			},
+			namespace: "default",
+			expected:  "team-shared",
+		},
+		"cluster scoped object": {
+			routing:     teamRouting,
+			annotations: map[string]string{"owner-team": "search"},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			source := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					Namespace:   test.namespace,
+					Annotations: test.annotations,
+					Labels:      test.labels,
+				},
+			}
+			obj := source.DeepCopy()
+
+			err := util.RouteNamespace(obj, source, test.routing)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("unexpected error: %v, expected: %v", err, test.err)
+			}
+			if test.err != nil {
+				return
+			}
+
+			if obj.GetNamespace() != test.expected {
+				t.Fatalf("routed to %q, expected %q", obj.GetNamespace(), test.expected)
+			}
+		})
+	}
+}
+
+func TestGetStaleSyncedObjectsAfterRoutingChange(t *testing.T) {
+	t.Parallel()
+
+	source := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "default",
+			Annotations: map[string]string{"owner-team": "search"},
+		},
+	}
+	sourceKey := client.ObjectKeyFromObject(source)
+
+	sync := func(routing clusterregistryv1alpha1.NamespaceRouting) client.Object {
+		obj := source.DeepCopy()
+		if err := util.RouteNamespace(obj, source, routing); err != nil {
+			t.Fatal(err)
+		}
+		util.SetSourceObjectKey(obj, sourceKey)
+
+		return obj
+	}
+
+	routing := clusterregistryv1alpha1.NamespaceRouting{
+		FromAnnotation: "owner-team",
+		Map:            map[string]string{"search": "team-search"},
+	}
+	previous := sync(routing)
+
+	// the map is edited, the object is routed to another namespace
+	routing.Map = map[string]string{"search": "team-discovery"}
+	current := sync(routing)
+
+	unrelated := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "team-search",
+			Labels: map[string]string{
+				clusterregistryv1alpha1.OriginalNamespaceLabel: "other",
+			},
+		},
+	}
+
+	stale := util.GetStaleSyncedObjects([]client.Object{previous, current, unrelated}, sourceKey, client.ObjectKeyFromObject(current))
+	if len(stale) != 1 || client.ObjectKeyFromObject(stale[0]) != (types.NamespacedName{Name: "test", Namespace: "team-search"}) {
+		t.Fatalf("unexpected stale objects: %v", stale)
+	}
+
+	// nothing is stale once the object is in its namespace only
+	if stale := util.GetStaleSyncedObjects([]client.Object{current}, sourceKey, client.ObjectKeyFromObject(current)); len(stale) != 0 {
+		t.Fatalf("unexpected stale objects: %v", stale)
+	}
+}
+
+func TestIsClusterScoped(t *testing.T) {
+	t.Parallel()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	tests := map[string]struct {
+		gvk      schema.GroupVersionKind
+		expected bool
+		err      bool
+	}{
+		"cluster scoped": {
+			gvk:      rbacv1.SchemeGroupVersion.WithKind("ClusterRole"),
+			expected: true,
+		},
+		"namespaced": {
+			gvk: corev1.SchemeGroupVersion.WithKind("ConfigMap"),
+		},
+		"unknown kind": {
+			gvk: corev1.SchemeGroupVersion.WithKind("Unknown"),
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			clusterScoped, err := util.IsClusterScoped(mapper, test.gvk)
+			if test.err != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if clusterScoped != test.expected {
+				t.Fatalf("expected cluster scoped to be %t, got %t", test.expected, clusterScoped)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ObjectQuota counts the distinct local objects synced by each rule from any of the clusters, so the rules can be
+// kept from creating more objects than their quota. The objects already synced are always counted, only the new
+// ones are refused beyond the quota.
+type ObjectQuota struct {
+	rules map[string]*ruleObjects
+	mu    sync.Mutex
+}
+
+type ruleObjects struct {
+	// objects are the local objects of the rule with the clusters they are synced from
+	objects map[types.NamespacedName]map[string]struct{}
+	// restored is the count of the objects the rule synced before a restart, it is counted until the objects are
+	// tracked again
+	restored int
+	exceeded bool
+	// onRelease are called with the clusters they are registered for when the quota of the rule is not exceeded
+	// anymore
+	onRelease map[string]func()
+}
+
+// ObjectQuotaResult is the result of an admission or release of an object
+type ObjectQuotaResult struct {
+	// Count is the number of objects counted for the rule
+	Count int
+	// Admitted is whether the object can be synced
+	Admitted bool
+	// Exceeded is whether the rule refused objects, because of its quota, and it is still full
+	Exceeded bool
+	// ExceededChanged is whether Exceeded changed by the call
+	ExceededChanged bool
+}
+
+func NewObjectQuota() *ObjectQuota {
+	return &ObjectQuota{
+		rules: make(map[string]*ruleObjects),
+	}
+}
+
+// Admit counts the object synced by the rule from the cluster if it exists already, it is counted already, or the
+// rule syncs fewer objects than the limit. A limit of zero is unlimited.
+func (q *ObjectQuota) Admit(rule string, cluster string, key types.NamespacedName, exists bool, limit int) ObjectQuotaResult {
+	q.mu.Lock()
+	objects := q.getRule(rule)
+	_, counted := objects.objects[key]
+	admitted := exists || counted || limit <= 0 || objects.count() < limit
+	if admitted {
+		objects.add(key, cluster)
+	}
+	result := objects.update(admitted, limit)
+	notify := objects.getReleaseFuncs(result)
+	q.mu.Unlock()
+
+	for _, f := range notify {
+		f()
+	}
+
+	return result
+}
+
+// Release stops counting the object deleted by the rule from the cluster
+func (q *ObjectQuota) Release(rule string, cluster string, key types.NamespacedName, limit int) ObjectQuotaResult {
+	q.mu.Lock()
+	objects := q.getRule(rule)
+	if clusters, ok := objects.objects[key]; ok {
+		delete(clusters, cluster)
+		if len(clusters) == 0 {
+			delete(objects.objects, key)
+		}
+	}
+	result := objects.update(true, limit)
+	notify := objects.getReleaseFuncs(result)
+	q.mu.Unlock()
+
+	for _, f := range notify {
+		f()
+	}
+
+	return result
+}
+
+// Restore counts the objects of the rule persisted before a restart, until as many of them are counted again or the
+// restored count is cleared
+func (q *ObjectQuota) Restore(rule string, count int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	objects := q.getRule(rule)
+	if len(objects.objects) == 0 {
+		objects.restored = count
+	}
+}
+
+// ClearRestored stops counting the objects persisted before a restart, e.g. because every object of the rule is
+// counted again
+func (q *ObjectQuota) ClearRestored(rule string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.getRule(rule).restored = 0
+}
+
+// Count returns the number of objects counted for the rule
+func (q *ObjectQuota) Count(rule string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.getRule(rule).count()
+}
+
+// IsExceeded returns whether the rule refused objects, because of its quota, and it is still full
+func (q *ObjectQuota) IsExceeded(rule string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	objects, ok := q.rules[rule]
+
+	return ok && objects.exceeded
+}
+
+// OnRelease registers the function called for the cluster when the quota of the rule is not exceeded anymore, so the
+// refused objects can be synced again. A nil function unregisters it.
+func (q *ObjectQuota) OnRelease(rule string, cluster string, f func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	objects := q.getRule(rule)
+	if f == nil {
+		delete(objects.onRelease, cluster)
+
+		return
+	}
+	objects.onRelease[cluster] = f
+}
+
+// ForgetCluster stops counting the objects synced by the rule from the cluster, e.g. because the rule does not sync
+// from it anymore. The rule is forgotten once it counts no objects.
+func (q *ObjectQuota) ForgetCluster(rule string, cluster string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	objects, ok := q.rules[rule]
+	if !ok {
+		return
+	}
+
+	for key, clusters := range objects.objects {
+		delete(clusters, cluster)
+		if len(clusters) == 0 {
+			delete(objects.objects, key)
+		}
+	}
+	delete(objects.onRelease, cluster)
+
+	if len(objects.objects) == 0 && len(objects.onRelease) == 0 {
+		delete(q.rules, rule)
+	}
+}
+
+// getRule returns the objects of the rule, the lock must be held
+func (q *ObjectQuota) getRule(rule string) *ruleObjects {
+	objects, ok := q.rules[rule]
+	if !ok {
+		objects = &ruleObjects{
+			objects:   make(map[types.NamespacedName]map[string]struct{}),
+			onRelease: make(map[string]func()),
+		}
+		q.rules[rule] = objects
+	}
+
+	return objects
+}
+
+func (o *ruleObjects) count() int {
+	if o.restored > len(o.objects) {
+		return o.restored
+	}
+
+	return len(o.objects)
+}
+
+func (o *ruleObjects) add(key types.NamespacedName, cluster string) {
+	clusters, ok := o.objects[key]
+	if !ok {
+		clusters = make(map[string]struct{})
+		o.objects[key] = clusters
+	}
+	clusters[cluster] = struct{}{}
+}
+
+// update marks the quota exceeded when an object is refused, and not exceeded anymore once there is room for
+// another object
+func (o *ruleObjects) update(admitted bool, limit int) ObjectQuotaResult {
+	exceeded := o.exceeded
+	switch {
+	case !admitted:
+		o.exceeded = true
+	case limit <= 0 || o.count() < limit:
+		o.exceeded = false
+	}
+
+	return ObjectQuotaResult{
+		Count:           o.count(),
+		Admitted:        admitted,
+		Exceeded:        o.exceeded,
+		ExceededChanged: exceeded != o.exceeded,
+	}
+}
+
+func (o *ruleObjects) getReleaseFuncs(result ObjectQuotaResult) []func() {
+	if !result.ExceededChanged || result.Exceeded {
+		return nil
+	}
+
+	funcs := make([]func(), 0, len(o.onRelease))
+	for _, f := range o.onRelease {
+		funcs = append(funcs, f)
+	}
+
+	return funcs
+}
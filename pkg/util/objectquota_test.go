@@ -0,0 +1,122 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestObjectQuota(t *testing.T) {
+	t.Parallel()
+
+	quota := util.NewObjectQuota()
+	first := types.NamespacedName{Namespace: "default", Name: "first"}
+	second := types.NamespacedName{Namespace: "default", Name: "second"}
+	third := types.NamespacedName{Namespace: "default", Name: "third"}
+
+	released := 0
+	quota.OnRelease("rule", "cluster-a", func() {
+		released++
+	})
+
+	if result := quota.Admit("rule", "cluster-a", first, false, 2); !result.Admitted || result.Count != 1 {
+		t.Fatalf("first object must be admitted: %+v", result)
+	}
+	// the same object synced from another cluster is counted once
+	if result := quota.Admit("rule", "cluster-b", first, false, 2); !result.Admitted || result.Count != 1 {
+		t.Fatalf("object synced from another cluster must be counted once: %+v", result)
+	}
+	if result := quota.Admit("rule", "cluster-a", second, false, 2); !result.Admitted || result.Count != 2 || result.Exceeded {
+		t.Fatalf("second object must be admitted: %+v", result)
+	}
+
+	result := quota.Admit("rule", "cluster-a", third, false, 2)
+	if result.Admitted || !result.Exceeded || !result.ExceededChanged || !quota.IsExceeded("rule") {
+		t.Fatalf("new object beyond the quota must be refused: %+v", result)
+	}
+	if result := quota.Admit("rule", "cluster-a", third, false, 2); result.Admitted || result.ExceededChanged {
+		t.Fatalf("refusing another object must not change the exceeded state: %+v", result)
+	}
+	// the objects existing already and the ones counted already are updated beyond the quota
+	if result := quota.Admit("rule", "cluster-a", first, true, 2); !result.Admitted {
+		t.Fatalf("counted object must be admitted: %+v", result)
+	}
+	if result := quota.Admit("rule", "cluster-a", third, true, 2); !result.Admitted || result.Count != 3 {
+		t.Fatalf("existing object must be admitted: %+v", result)
+	}
+
+	quota.Release("rule", "cluster-a", third, 2)
+	if released != 0 {
+		t.Fatal("release funcs must not be called while the quota is full")
+	}
+
+	// the object is still synced from the other cluster
+	if result := quota.Release("rule", "cluster-a", first, 2); result.Count != 2 || !result.Exceeded {
+		t.Fatalf("object synced from another cluster must stay counted: %+v", result)
+	}
+	result = quota.Release("rule", "cluster-b", first, 2)
+	if result.Count != 1 || result.Exceeded || !result.ExceededChanged || released != 1 {
+		t.Fatalf("release must call the release funcs once the quota has room: %+v, released %d", result, released)
+	}
+
+	// raising the quota resumes creation
+	quota.Admit("rule", "cluster-a", first, false, 2)
+	quota.Admit("rule", "cluster-a", third, false, 2)
+	if result := quota.Admit("rule", "cluster-a", third, false, 4); !result.Admitted || result.Exceeded || !result.ExceededChanged || released != 2 {
+		t.Fatalf("raised quota must admit new objects: %+v, released %d", result, released)
+	}
+}
+
+func TestObjectQuotaRestore(t *testing.T) {
+	t.Parallel()
+
+	quota := util.NewObjectQuota()
+	key := types.NamespacedName{Namespace: "default", Name: "new"}
+
+	quota.Restore("rule", 5)
+	if count := quota.Count("rule"); count != 5 {
+		t.Fatalf("restored count must be counted, got %d", count)
+	}
+	if result := quota.Admit("rule", "cluster", key, false, 5); result.Admitted {
+		t.Fatalf("new object must be refused until the restored objects are counted again: %+v", result)
+	}
+
+	quota.ClearRestored("rule")
+	if result := quota.Admit("rule", "cluster", key, false, 5); !result.Admitted || result.Count != 1 {
+		t.Fatalf("new object must be admitted once the restored count is cleared: %+v", result)
+	}
+
+	quota.ForgetCluster("rule", "cluster")
+	if count := quota.Count("rule"); count != 0 {
+		t.Fatalf("forgotten cluster must not be counted, got %d", count)
+	}
+}
+
+func TestObjectQuotaUnlimited(t *testing.T) {
+	t.Parallel()
+
+	quota := util.NewObjectQuota()
+
+	for i, name := range []string{"a", "b", "c"} {
+		result := quota.Admit("rule", "cluster", types.NamespacedName{Name: name}, false, 0)
+		if !result.Admitted || result.Exceeded || result.Count != i+1 {
+			t.Fatalf("objects must be admitted without a quota: %+v", result)
+		}
+	}
+}
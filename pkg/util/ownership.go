@@ -0,0 +1,45 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// SetOwnerRule records the rule writing the synced object in its annotations, a value inherited from an object synced
+// over multiple hops is overwritten.
+func SetOwnerRule(annotations map[string]string, rule string) {
+	annotations[clusterregistryv1alpha1.OwnerRuleAnnotation] = rule
+}
+
+// GetOwnerRule returns the rule which wrote the synced object, objects written before the owner rule was recorded
+// have none.
+func GetOwnerRule(obj metav1.Object) string {
+	return obj.GetAnnotations()[clusterregistryv1alpha1.OwnerRuleAnnotation]
+}
+
+// IsOwnedByRule returns whether the object was synced from the cluster by the rule. Objects without an owner rule are
+// owned by every rule syncing from their owner cluster, they are upgraded in place when synced next.
+func IsOwnedByRule(obj metav1.Object, clusterID string, rule string) bool {
+	if obj.GetAnnotations()[clusterregistryv1alpha1.OwnershipAnnotation] != clusterID {
+		return false
+	}
+
+	owner := GetOwnerRule(obj)
+
+	return owner == "" || owner == rule
+}
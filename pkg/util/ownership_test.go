@@ -0,0 +1,100 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestIsOwnedByRule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		owned       bool
+	}{
+		{
+			name:        "not synced",
+			annotations: nil,
+			owned:       false,
+		},
+		{
+			name: "written before the owner rule was recorded",
+			annotations: map[string]string{
+				clusterregistryv1alpha1.OwnershipAnnotation: "cluster-a",
+			},
+			owned: true,
+		},
+		{
+			name: "written by the rule",
+			annotations: map[string]string{
+				clusterregistryv1alpha1.OwnershipAnnotation: "cluster-a",
+				clusterregistryv1alpha1.OwnerRuleAnnotation: "rule-a",
+			},
+			owned: true,
+		},
+		{
+			name: "written by another rule",
+			annotations: map[string]string{
+				clusterregistryv1alpha1.OwnershipAnnotation: "cluster-a",
+				clusterregistryv1alpha1.OwnerRuleAnnotation: "rule-b",
+			},
+			owned: false,
+		},
+		{
+			name: "written from another cluster",
+			annotations: map[string]string{
+				clusterregistryv1alpha1.OwnershipAnnotation: "cluster-b",
+				clusterregistryv1alpha1.OwnerRuleAnnotation: "rule-a",
+			},
+			owned: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := &metav1.ObjectMeta{Annotations: tt.annotations}
+			if owned := util.IsOwnedByRule(obj, "cluster-a", "rule-a"); owned != tt.owned {
+				t.Fatalf("expected owned to be %t, got %t", tt.owned, owned)
+			}
+		})
+	}
+}
+
+func TestSetOwnerRuleUpgradesLegacyObjects(t *testing.T) {
+	t.Parallel()
+
+	annotations := map[string]string{
+		clusterregistryv1alpha1.OwnershipAnnotation: "cluster-a",
+	}
+	util.SetOwnerRule(annotations, "rule-a")
+
+	obj := &metav1.ObjectMeta{Annotations: annotations}
+	if owner := util.GetOwnerRule(obj); owner != "rule-a" {
+		t.Fatalf("expected owner rule rule-a, got %q", owner)
+	}
+	if util.IsOwnedByRule(obj, "cluster-a", "rule-b") {
+		t.Fatal("expected the upgraded object not to be owned by another rule")
+	}
+}
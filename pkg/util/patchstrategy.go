@@ -0,0 +1,113 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"emperror.dev/errors"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// HasPatchDiff returns whether the desired object differs from the live object with the patch strategy of a rule
+func HasPatchDiff(strategy clusterregistryv1alpha1.PatchStrategy, desired client.Object, live client.Object) (bool, error) {
+	switch strategy {
+	case clusterregistryv1alpha1.PatchStrategyMerge:
+		desiredContent, err := toUnstructuredContent(desired)
+		if err != nil {
+			return false, errors.WrapIf(err, "could not convert desired object")
+		}
+		liveContent, err := toUnstructuredContent(live)
+		if err != nil {
+			return false, errors.WrapIf(err, "could not convert live object")
+		}
+
+		// the object matcher compares unstructured objects with a JSON merge patch
+		return hasThreeWayPatchDiff(&unstructured.Unstructured{Object: desiredContent}, &unstructured.Unstructured{Object: liveContent})
+	case clusterregistryv1alpha1.PatchStrategyJSON:
+		paths, err := DriftedPaths(desired, live)
+
+		return len(paths) > 0, err
+	case clusterregistryv1alpha1.PatchStrategyReplace:
+		return hasReplaceDiff(desired, live)
+	default:
+		return hasThreeWayPatchDiff(desired, live)
+	}
+}
+
+func hasThreeWayPatchDiff(desired client.Object, live client.Object) (bool, error) {
+	result, err := patch.DefaultPatchMaker.Calculate(live, desired, patch.IgnoreStatusFields())
+	if err != nil {
+		return false, errors.WrapIf(err, "could not calculate patch")
+	}
+
+	return !result.IsEmpty(), nil
+}
+
+// hasReplaceDiff returns whether the fields of the desired object differ on the live object, or the live object has
+// fields the desired object does not have, the metadata and the status written by others are not compared
+func hasReplaceDiff(desired client.Object, live client.Object) (bool, error) {
+	paths, err := DriftedPaths(desired, live)
+	if err != nil || len(paths) > 0 {
+		return len(paths) > 0, err
+	}
+
+	desiredContent, err := toUnstructuredContent(desired)
+	if err != nil {
+		return false, errors.WrapIf(err, "could not convert desired object")
+	}
+	liveContent, err := toUnstructuredContent(live)
+	if err != nil {
+		return false, errors.WrapIf(err, "could not convert live object")
+	}
+
+	desiredFields, err := withoutReplacedFields(desiredContent)
+	if err != nil {
+		return false, err
+	}
+	liveFields, err := withoutReplacedFields(liveContent)
+	if err != nil {
+		return false, err
+	}
+
+	return !reflect.DeepEqual(desiredFields, liveFields), nil
+}
+
+// withoutReplacedFields returns the content of the object without the fields not written by its replacement, it is
+// normalized through JSON, so the numbers of typed and unstructured objects are compared as the same type
+func withoutReplacedFields(content map[string]interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{}, len(content))
+	for key, value := range content {
+		switch key {
+		case "apiVersion", "kind", "metadata", "status":
+			continue
+		}
+		fields[key] = value
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not marshal object")
+	}
+
+	normalized := make(map[string]interface{})
+
+	return normalized, errors.WrapIf(json.Unmarshal(data, &normalized), "could not unmarshal object")
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// routeTable is a custom resource declaring a merge key for its routes, which its routes do not always have, so the
+// strategic merge patch can not be calculated for it
+type routeTable struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec routeTableSpec `json:"spec,omitempty"`
+}
+
+type routeTableSpec struct {
+	Routes []route `json:"routes,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+}
+
+type route struct {
+	Name   string `json:"name,omitempty"`
+	Weight int    `json:"weight"`
+}
+
+func (in *routeTable) DeepCopyObject() runtime.Object {
+	out := *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec.Routes = append([]route(nil), in.Spec.Routes...)
+
+	return &out
+}
+
+func TestHasPatchDiff(t *testing.T) {
+	t.Parallel()
+
+	table := func(mutate func(rt *routeTable)) *routeTable {
+		rt := &routeTable{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "routing.example.com/v1", Kind: "RouteTable"},
+			ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+			Spec: routeTableSpec{
+				Routes: []route{{Name: "primary", Weight: 90}, {Weight: 10}},
+			},
+		}
+		if mutate != nil {
+			mutate(rt)
+		}
+
+		return rt
+	}
+
+	configMap := func(mutate func(c *corev1.ConfigMap)) *corev1.ConfigMap {
+		c := &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+			Data:       map[string]string{"key": "value"},
+		}
+		if mutate != nil {
+			mutate(c)
+		}
+
+		return c
+	}
+
+	// applied returns the live object written from the desired one, with the last applied annotation of the writes
+	applied := func(desired client.Object, mutate func(obj client.Object)) client.Object {
+		live, ok := desired.DeepCopyObject().(client.Object)
+		if !ok {
+			t.Fatal("invalid object")
+		}
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(live); err != nil {
+			t.Fatal(err)
+		}
+		live.SetUID("1234")
+		live.SetResourceVersion("42")
+		if mutate != nil {
+			mutate(live)
+		}
+
+		return live
+	}
+
+	tests := map[string]struct {
+		strategy clusterregistryv1alpha1.PatchStrategy
+		desired  client.Object
+		live     client.Object
+		expected bool
+		err      bool
+	}{
+		"strategic merge in sync": {
+			desired:  configMap(nil),
+			live:     applied(configMap(nil), nil),
+			expected: false,
+		},
+		"strategic merge changed": {
+			desired: configMap(nil),
+			live: applied(configMap(nil), func(obj client.Object) {
+				obj.(*corev1.ConfigMap).Data["key"] = "changed"
+			}),
+			expected: true,
+		},
+		"strategic merge can not compare routes without merge key": {
+			strategy: clusterregistryv1alpha1.PatchStrategyStrategicMerge,
+			desired:  table(nil),
+			live:     applied(table(nil), nil),
+			err:      true,
+		},
+		"merge in sync": {
+			strategy: clusterregistryv1alpha1.PatchStrategyMerge,
+			desired:  table(nil),
+			live:     applied(table(nil), nil),
+			expected: false,
+		},
+		"merge changed route": {
+			strategy: clusterregistryv1alpha1.PatchStrategyMerge,
+			desired:  table(nil),
+			live: applied(table(nil), func(obj client.Object) {
+				obj.(*routeTable).Spec.Routes[1].Weight = 20
+			}),
+			expected: true,
+		},
+		"merge removed route": {
+			strategy: clusterregistryv1alpha1.PatchStrategyMerge,
+			desired: table(func(rt *routeTable) {
+				rt.Spec.Routes = rt.Spec.Routes[:1]
+			}),
+			live:     applied(table(nil), nil),
+			expected: true,
+		},
+		"json in sync": {
+			strategy: clusterregistryv1alpha1.PatchStrategyJSON,
+			desired:  table(nil),
+			live:     applied(table(nil), nil),
+			expected: false,
+		},
+		"json ignores fields added locally": {
+			strategy: clusterregistryv1alpha1.PatchStrategyJSON,
+			desired:  configMap(nil),
+			live: applied(configMap(nil), func(obj client.Object) {
+				obj.(*corev1.ConfigMap).Data["local"] = "value"
+			}),
+			expected: false,
+		},
+		"json changed route": {
+			strategy: clusterregistryv1alpha1.PatchStrategyJSON,
+			desired:  table(nil),
+			live: applied(table(nil), func(obj client.Object) {
+				obj.(*routeTable).Spec.Routes[0].Name = "secondary"
+			}),
+			expected: true,
+		},
+		"replace in sync": {
+			strategy: clusterregistryv1alpha1.PatchStrategyReplace,
+			desired:  table(nil),
+			live:     applied(table(nil), nil),
+			expected: false,
+		},
+		"replace ignores metadata": {
+			strategy: clusterregistryv1alpha1.PatchStrategyReplace,
+			desired:  configMap(nil),
+			live: applied(configMap(nil), func(obj client.Object) {
+				obj.SetLabels(map[string]string{"local": "value"})
+			}),
+			expected: false,
+		},
+		"replace removes fields added locally": {
+			strategy: clusterregistryv1alpha1.PatchStrategyReplace,
+			desired:  configMap(nil),
+			live: applied(configMap(nil), func(obj client.Object) {
+				obj.(*corev1.ConfigMap).Data["local"] = "value"
+			}),
+			expected: true,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			changed, err := util.HasPatchDiff(test.strategy, test.desired, test.live)
+			if test.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if changed != test.expected {
+				t.Fatalf("expected %t, got %t", test.expected, changed)
+			}
+		})
+	}
+}
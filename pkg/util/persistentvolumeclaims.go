@@ -0,0 +1,88 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+var ErrStorageClassNotFound = errors.New("storage class of the claim does not exist locally")
+
+var persistentVolumeClaimGroupKind = corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim").GroupKind()
+
+// IsPersistentVolumeClaim returns whether the object is a PersistentVolumeClaim
+func IsPersistentVolumeClaim(obj client.Object) bool {
+	return obj.GetObjectKind().GroupVersionKind().GroupKind() == persistentVolumeClaimGroupKind
+}
+
+// MutatePersistentVolumeClaim maps the storage class of the claim, and removes its data sources if the mutations clear
+// them
+func MutatePersistentVolumeClaim(obj client.Object, mutations clusterregistryv1alpha1.PersistentVolumeClaimMutations) error {
+	if !IsPersistentVolumeClaim(obj) {
+		return errors.Errorf("persistent volume claim mutations can not be applied on %s", obj.GetObjectKind().GroupVersionKind())
+	}
+
+	return modifyObjectContent(obj, func(content map[string]interface{}) error {
+		if mutations.ClearDataSource {
+			unstructured.RemoveNestedField(content, "spec", "dataSource")
+			unstructured.RemoveNestedField(content, "spec", "dataSourceRef")
+		}
+
+		name, ok, _ := unstructured.NestedString(content, "spec", "storageClassName")
+		if mapped, found := mutations.StorageClassMap[name]; ok && found {
+			return errors.WrapIf(unstructured.SetNestedField(content, mapped, "spec", "storageClassName"), "could not set storage class")
+		}
+
+		// the beta annotation is still honored over the field by the volume controllers
+		name, ok, _ = unstructured.NestedString(content, "metadata", "annotations", corev1.BetaStorageClassAnnotation)
+		if mapped, found := mutations.StorageClassMap[name]; ok && found {
+			return errors.WrapIf(unstructured.SetNestedField(content, mapped, "metadata", "annotations", corev1.BetaStorageClassAnnotation), "could not set storage class")
+		}
+
+		return nil
+	})
+}
+
+// GetStorageClassName returns the name of the storage class the claim requests, the claims without a storage class
+// and the ones requesting the default class return an empty name
+func GetStorageClassName(obj client.Object) (string, error) {
+	if !IsPersistentVolumeClaim(obj) {
+		return "", nil
+	}
+
+	if name := obj.GetAnnotations()[corev1.BetaStorageClassAnnotation]; name != "" {
+		return name, nil
+	}
+
+	switch o := obj.(type) {
+	case *corev1.PersistentVolumeClaim:
+		if o.Spec.StorageClassName != nil {
+			return *o.Spec.StorageClassName, nil
+		}
+
+		return "", nil
+	case *unstructured.Unstructured:
+		name, _, err := unstructured.NestedString(o.Object, "spec", "storageClassName")
+
+		return name, errors.WrapIf(err, "could not get storage class")
+	default:
+		return "", errors.Errorf("invalid persistent volume claim %T", obj)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func newPersistentVolumeClaim() *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "PersistentVolumeClaim",
+			APIVersion: "v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "data",
+			Namespace: "default",
+			Annotations: map[string]string{
+				"pv.kubernetes.io/bind-completed":               "yes",
+				"volume.kubernetes.io/storage-provisioner":      "ebs.csi.aws.com",
+				"volume.beta.kubernetes.io/storage-provisioner": "ebs.csi.aws.com",
+				"example.com/owner":                             "team-a",
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: pointer.String("fast-ssd"),
+			VolumeName:       "pvc-0123",
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: pointer.String("snapshot.storage.k8s.io"),
+				Kind:     "VolumeSnapshot",
+				Name:     "data-snapshot",
+			},
+		},
+	}
+}
+
+func toObjects(t *testing.T, pvc *corev1.PersistentVolumeClaim) map[string]client.Object {
+	t.Helper()
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pvc.DeepCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]client.Object{
+		"typed":        pvc.DeepCopy(),
+		"unstructured": &unstructured.Unstructured{Object: content},
+	}
+}
+
+func fromObject(t *testing.T, obj client.Object) *corev1.PersistentVolumeClaim {
+	t.Helper()
+
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		var pvc corev1.PersistentVolumeClaim
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &pvc); err != nil {
+			t.Fatal(err)
+		}
+
+		return &pvc
+	}
+
+	return obj.(*corev1.PersistentVolumeClaim) // nolint:forcetypeassert
+}
+
+func TestSanitizePersistentVolumeClaim(t *testing.T) {
+	t.Parallel()
+
+	for kind, obj := range toObjects(t, newPersistentVolumeClaim()) {
+		if err := util.SanitizeObject(obj); err != nil {
+			t.Fatalf("%s: %+v", kind, err)
+		}
+
+		pvc := fromObject(t, obj)
+		if pvc.Spec.VolumeName != "" {
+			t.Fatalf("%s: volume name was not cleared", kind)
+		}
+		if len(pvc.GetAnnotations()) != 1 || pvc.GetAnnotations()["example.com/owner"] != "team-a" {
+			t.Fatalf("%s: unexpected annotations %v", kind, pvc.GetAnnotations())
+		}
+		if pvc.Spec.DataSource == nil || pointer.StringDeref(pvc.Spec.StorageClassName, "") != "fast-ssd" {
+			t.Fatalf("%s: unrelated fields were modified: %+v", kind, pvc.Spec)
+		}
+	}
+}
+
+func TestMutatePersistentVolumeClaim(t *testing.T) {
+	t.Parallel()
+
+	annotated := newPersistentVolumeClaim()
+	annotated.Spec.StorageClassName = nil
+	annotated.Annotations[corev1.BetaStorageClassAnnotation] = "fast-ssd"
+
+	tests := map[string]struct {
+		pvc                *corev1.PersistentVolumeClaim
+		mutations          clusterregistryv1alpha1.PersistentVolumeClaimMutations
+		wantedStorageClass string
+		wantedDataSource   bool
+	}{
+		"storage class is mapped": {
+			pvc: newPersistentVolumeClaim(),
+			mutations: clusterregistryv1alpha1.PersistentVolumeClaimMutations{
+				StorageClassMap: map[string]string{"fast-ssd": "gp3"},
+			},
+			wantedStorageClass: "gp3",
+			wantedDataSource:   true,
+		},
+		"unmapped storage class is kept": {
+			pvc: newPersistentVolumeClaim(),
+			mutations: clusterregistryv1alpha1.PersistentVolumeClaimMutations{
+				StorageClassMap: map[string]string{"standard": "gp2"},
+			},
+			wantedStorageClass: "fast-ssd",
+			wantedDataSource:   true,
+		},
+		"storage class annotation is mapped": {
+			pvc: annotated,
+			mutations: clusterregistryv1alpha1.PersistentVolumeClaimMutations{
+				StorageClassMap: map[string]string{"fast-ssd": "gp3"},
+			},
+			wantedStorageClass: "gp3",
+			wantedDataSource:   true,
+		},
+		"data source is cleared": {
+			pvc: newPersistentVolumeClaim(),
+			mutations: clusterregistryv1alpha1.PersistentVolumeClaimMutations{
+				ClearDataSource: true,
+			},
+			wantedStorageClass: "fast-ssd",
+		},
+	}
+
+	for name, test := range tests {
+		for kind, obj := range toObjects(t, test.pvc) {
+			if err := util.MutatePersistentVolumeClaim(obj, test.mutations); err != nil {
+				t.Fatalf("%s (%s): %+v", name, kind, err)
+			}
+
+			storageClass, err := util.GetStorageClassName(obj)
+			if err != nil {
+				t.Fatalf("%s (%s): %+v", name, kind, err)
+			}
+			if storageClass != test.wantedStorageClass {
+				t.Fatalf("%s (%s): unexpected storage class %q", name, kind, storageClass)
+			}
+
+			pvc := fromObject(t, obj)
+			if (pvc.Spec.DataSource != nil) != test.wantedDataSource {
+				t.Fatalf("%s (%s): unexpected data source %v", name, kind, pvc.Spec.DataSource)
+			}
+		}
+	}
+}
+
+func TestMutatePersistentVolumeClaimInvalidKind(t *testing.T) {
+	t.Parallel()
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+	}
+
+	if err := util.MutatePersistentVolumeClaim(cm, clusterregistryv1alpha1.PersistentVolumeClaimMutations{}); err == nil {
+		t.Fatal("expected error")
+	}
+	if name, err := util.GetStorageClassName(cm); err != nil || name != "" {
+		t.Fatalf("unexpected storage class %q: %v", name, err)
+	}
+}
@@ -65,6 +65,9 @@ func ProvisionLocalClusterObject(c client.Client, log logr.Logger, configuration
 	if err != nil {
 		return errors.WrapIf(err, "cannot create new local cluster object")
 	}
+	if configuration.LocalClusterID != "" {
+		newClusterSpec.Spec.ClusterID = types.UID(configuration.LocalClusterID)
+	}
 
 	err = c.Create(context.Background(), newClusterSpec)
 	if err != nil {
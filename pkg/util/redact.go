@@ -0,0 +1,343 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// minScrubbedValueLength is the length of the shortest sensitive value scrubbed from texts, shorter values would
+// replace unrelated parts of the messages
+const minScrubbedValueLength = 4
+
+// defaultRedactedFields are always redacted
+var defaultRedactedFields = map[schema.GroupKind][][]string{
+	corev1.SchemeGroupVersion.WithKind("Secret").GroupKind(): {{"data"}, {"stringData"}},
+}
+
+// RedactedValue returns the placeholder of a sensitive value, which only reveals its length and hash, so changes of the
+// value can still be followed in the logs
+func RedactedValue(value []byte) string {
+	sum := sha256.Sum256(value)
+
+	return fmt.Sprintf("[redacted, %d bytes, sha256:%s]", len(value), hex.EncodeToString(sum[:])[:12])
+}
+
+// Redactor knows the sensitive fields of the kinds, whose values must not appear in logs, events and errors. A nil
+// redactor only knows the data of Secrets.
+type Redactor struct {
+	fields map[schema.GroupKind][][]string
+}
+
+// NewRedactor returns a redactor of the data of Secrets and the additional fields in Kind[.group]:.path format, e.g.
+// ConfigMap:.data or Certificate.cert-manager.io:.spec.password. The values of a map field are redacted one by one.
+func NewRedactor(fields []string) (*Redactor, error) {
+	r := &Redactor{
+		fields: make(map[schema.GroupKind][][]string, len(defaultRedactedFields)+len(fields)),
+	}
+	for gk, paths := range defaultRedactedFields {
+		r.fields[gk] = append(r.fields[gk], paths...)
+	}
+
+	for _, field := range fields {
+		kind, path, ok := strings.Cut(field, ":")
+		if !ok || kind == "" || !strings.HasPrefix(path, ".") || len(path) < 2 || strings.Contains(path, "..") || strings.HasSuffix(path, ".") {
+			return nil, errors.NewWithDetails("invalid redacted field, expected Kind[.group]:.path format", "field", field)
+		}
+
+		gk := schema.ParseGroupKind(kind)
+		r.fields[gk] = append(r.fields[gk], strings.Split(path[1:], "."))
+	}
+
+	return r, nil
+}
+
+func (r *Redactor) getFields() map[schema.GroupKind][][]string {
+	if r == nil {
+		return defaultRedactedFields
+	}
+
+	return r.fields
+}
+
+// IsSensitive returns whether the kind has redacted fields
+func (r *Redactor) IsSensitive(gk schema.GroupKind) bool {
+	_, ok := r.getFields()[gk]
+
+	return ok
+}
+
+// NewScrubber returns a scrubber of the values of the sensitive fields of the object
+func (r *Redactor) NewScrubber(obj client.Object) *Scrubber {
+	s := &Scrubber{}
+	if obj == nil {
+		return s
+	}
+
+	paths, ok := r.getFields()[obj.GetObjectKind().GroupVersionKind().GroupKind()]
+	if !ok {
+		return s
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return s
+	}
+
+	for _, path := range paths {
+		value, ok := getNestedValue(content, path)
+		if !ok {
+			continue
+		}
+
+		if m, ok := value.(map[string]interface{}); ok {
+			for _, v := range m {
+				s.add(v)
+			}
+
+			continue
+		}
+		s.add(value)
+	}
+	s.sort()
+
+	return s
+}
+
+func getNestedValue(content map[string]interface{}, path []string) (interface{}, bool) {
+	var value interface{} = content
+	for _, field := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[field]; !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// Scrubber replaces the sensitive values of an object in texts, in both their plain and base64 encoded forms
+type Scrubber struct {
+	values   []string
+	replacer *strings.Replacer
+}
+
+func (s *Scrubber) add(value interface{}) {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case nil:
+		return
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		raw = string(data)
+	}
+
+	// the data of Secrets is base64 encoded in the unstructured content and in the patches, while the plain form
+	// appears in the messages of the API server, the stringData is plain in the object and encoded in the patches
+	s.addString(raw)
+	s.addString(base64.StdEncoding.EncodeToString([]byte(raw)))
+	if decoded, err := base64.StdEncoding.DecodeString(raw); err == nil {
+		s.addString(string(decoded))
+	}
+}
+
+func (s *Scrubber) addString(value string) {
+	if len(value) < minScrubbedValueLength {
+		return
+	}
+	s.values = append(s.values, value)
+}
+
+// sort makes the longest values replaced first, so a value containing another one is not replaced partially
+func (s *Scrubber) sort() {
+	if len(s.values) == 0 {
+		return
+	}
+
+	sort.SliceStable(s.values, func(i, j int) bool {
+		return len(s.values[i]) > len(s.values[j])
+	})
+
+	pairs := make([]string, 0, 2*len(s.values))
+	for _, value := range s.values {
+		pairs = append(pairs, value, RedactedValue([]byte(value)))
+	}
+	s.replacer = strings.NewReplacer(pairs...)
+}
+
+// IsEmpty returns whether the scrubber has no values to replace
+func (s *Scrubber) IsEmpty() bool {
+	return s == nil || s.replacer == nil
+}
+
+// ScrubString replaces the sensitive values in the text
+func (s *Scrubber) ScrubString(text string) string {
+	if s.IsEmpty() {
+		return text
+	}
+
+	return s.replacer.Replace(text)
+}
+
+// ScrubValue replaces the sensitive values in a log or error detail value, values which are neither texts nor errors
+// are scrubbed in their JSON form
+func (s *Scrubber) ScrubValue(value interface{}) interface{} {
+	if s.IsEmpty() {
+		return value
+	}
+
+	switch v := value.(type) {
+	case nil, bool, int, int32, int64, uint, uint32, uint64, float32, float64:
+		return v
+	case string:
+		return s.ScrubString(v)
+	case []byte:
+		return s.ScrubString(string(v))
+	case error:
+		return s.ScrubError(v)
+	case fmt.Stringer:
+		return s.ScrubString(v.String())
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%T", v)
+		}
+
+		return s.ScrubString(string(data))
+	}
+}
+
+// ScrubError returns the error with the sensitive values replaced in its message and details. The returned error
+// matches the same errors with errors.Is and errors.As as the original one, but it does not unwrap to it, so the
+// original message and details can not be reached by the loggers.
+func (s *Scrubber) ScrubError(err error) error {
+	if err == nil || s.IsEmpty() {
+		return err
+	}
+
+	details := errors.GetDetails(err)
+	for i := range details {
+		details[i] = s.ScrubValue(details[i])
+	}
+
+	return &scrubbedError{
+		err:     err,
+		msg:     s.ScrubString(err.Error()),
+		details: details,
+	}
+}
+
+type scrubbedError struct {
+	err     error
+	msg     string
+	details []interface{}
+}
+
+func (e *scrubbedError) Error() string {
+	return e.msg
+}
+
+func (e *scrubbedError) Details() []interface{} {
+	return e.details
+}
+
+func (e *scrubbedError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+func (e *scrubbedError) As(target interface{}) bool {
+	return errors.As(e.err, target)
+}
+
+// WrapLogger returns a logger replacing the sensitive values in the logged values and errors
+func (s *Scrubber) WrapLogger(log logr.Logger) logr.Logger {
+	if s.IsEmpty() {
+		return log
+	}
+
+	return &scrubbingLogger{
+		Logger:   log,
+		scrubber: s,
+	}
+}
+
+type scrubbingLogger struct {
+	logr.Logger
+	scrubber *Scrubber
+}
+
+func (l *scrubbingLogger) scrubKeysAndValues(keysAndValues []interface{}) []interface{} {
+	scrubbed := make([]interface{}, len(keysAndValues))
+	for i, v := range keysAndValues {
+		if i%2 == 0 {
+			scrubbed[i] = v
+
+			continue
+		}
+		scrubbed[i] = l.scrubber.ScrubValue(v)
+	}
+
+	return scrubbed
+}
+
+func (l *scrubbingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.Logger.Info(l.scrubber.ScrubString(msg), l.scrubKeysAndValues(keysAndValues)...)
+}
+
+func (l *scrubbingLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.Logger.Error(l.scrubber.ScrubError(err), l.scrubber.ScrubString(msg), l.scrubKeysAndValues(keysAndValues)...)
+}
+
+func (l *scrubbingLogger) V(level int) logr.Logger {
+	return &scrubbingLogger{
+		Logger:   l.Logger.V(level),
+		scrubber: l.scrubber,
+	}
+}
+
+func (l *scrubbingLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return &scrubbingLogger{
+		Logger:   l.Logger.WithValues(l.scrubKeysAndValues(keysAndValues)...),
+		scrubber: l.scrubber,
+	}
+}
+
+func (l *scrubbingLogger) WithName(name string) logr.Logger {
+	return &scrubbingLogger{
+		Logger:   l.Logger.WithName(name),
+		scrubber: l.scrubber,
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+const (
+	secretPassword = "s3cr3t-p4ssw0rd"
+	secretToken    = "tok-0123456789"
+)
+
+func newSensitiveSecret() *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "credentials",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"password": []byte(secretPassword),
+		},
+		StringData: map[string]string{
+			"token": secretToken,
+		},
+	}
+}
+
+// assertNoSecretValues fails if any form of the values of the sensitive secret appears in the text
+func assertNoSecretValues(t *testing.T, text string) {
+	t.Helper()
+
+	for _, value := range []string{secretPassword, secretToken} {
+		for _, form := range []string{value, base64.StdEncoding.EncodeToString([]byte(value))} {
+			if strings.Contains(text, form) {
+				t.Fatalf("sensitive value %q found in %q", form, text)
+			}
+		}
+	}
+}
+
+func TestNewRedactor(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		fields    []string
+		sensitive []schema.GroupKind
+		err       bool
+	}{
+		"defaults": {
+			sensitive: []schema.GroupKind{{Kind: "Secret"}},
+		},
+		"core kind": {
+			fields:    []string{"ConfigMap:.data"},
+			sensitive: []schema.GroupKind{{Kind: "Secret"}, {Kind: "ConfigMap"}},
+		},
+		"grouped kind": {
+			fields:    []string{"Certificate.cert-manager.io:.spec.password"},
+			sensitive: []schema.GroupKind{{Group: "cert-manager.io", Kind: "Certificate"}},
+		},
+		"missing path":  {fields: []string{"ConfigMap"}, err: true},
+		"missing kind":  {fields: []string{":.data"}, err: true},
+		"relative path": {fields: []string{"ConfigMap:data"}, err: true},
+		"empty segment": {fields: []string{"ConfigMap:.data..key"}, err: true},
+		"trailing dot":  {fields: []string{"ConfigMap:.data."}, err: true},
+		"only a dot":    {fields: []string{"ConfigMap:."}, err: true},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			redactor, err := util.NewRedactor(test.fields)
+			if (err != nil) != test.err {
+				t.Fatalf("expected error %t, got %v", test.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			for _, gk := range test.sensitive {
+				if !redactor.IsSensitive(gk) {
+					t.Fatalf("expected %s to be sensitive", gk)
+				}
+			}
+			if redactor.IsSensitive(schema.GroupKind{Kind: "Service"}) {
+				t.Fatalf("expected Service not to be sensitive")
+			}
+		})
+	}
+}
+
+func TestRedactedValue(t *testing.T) {
+	t.Parallel()
+
+	value := util.RedactedValue([]byte(secretPassword))
+	if !strings.HasPrefix(value, fmt.Sprintf("[redacted, %d bytes, sha256:", len(secretPassword))) {
+		t.Fatalf("unexpected placeholder %q", value)
+	}
+	if value != util.RedactedValue([]byte(secretPassword)) {
+		t.Fatalf("placeholder is not stable")
+	}
+	if value == util.RedactedValue([]byte(secretToken)) {
+		t.Fatalf("placeholders of different values are equal")
+	}
+}
+
+func TestScrubber(t *testing.T) {
+	t.Parallel()
+
+	redactor, err := util.NewRedactor([]string{"ConfigMap:.data.key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := newSensitiveSecret()
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("Secret")
+	u.Object["data"] = map[string]interface{}{"password": base64.StdEncoding.EncodeToString([]byte(secretPassword))}
+	u.Object["stringData"] = map[string]interface{}{"token": secretToken}
+
+	configMap := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		Data:     map[string]string{"key": secretPassword, "other": "public-value"},
+	}
+
+	tests := map[string]struct {
+		scrubber *util.Scrubber
+		text     string
+		kept     string
+	}{
+		"typed secret": {
+			scrubber: redactor.NewScrubber(secret),
+			text:     fmt.Sprintf("plain %s encoded %s token %s", secretPassword, base64.StdEncoding.EncodeToString([]byte(secretPassword)), secretToken),
+			kept:     "plain [redacted, 15 bytes, sha256:",
+		},
+		"unstructured secret": {
+			scrubber: redactor.NewScrubber(u),
+			text:     fmt.Sprintf(`{"data":{"password":"%s"},"stringData":{"token":"%s"}} %s`, base64.StdEncoding.EncodeToString([]byte(secretPassword)), base64.StdEncoding.EncodeToString([]byte(secretToken)), secretPassword),
+			kept:     `{"data":{"password":"[redacted`,
+		},
+		"configured field": {
+			scrubber: redactor.NewScrubber(configMap),
+			text:     fmt.Sprintf("key %s other public-value", secretPassword),
+			kept:     "other public-value",
+		},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			scrubbed := test.scrubber.ScrubString(test.text)
+			assertNoSecretValues(t, scrubbed)
+			if !strings.Contains(scrubbed, test.kept) {
+				t.Fatalf("expected %q to contain %q", scrubbed, test.kept)
+			}
+		})
+	}
+
+	var defaultRedactor *util.Redactor
+	if scrubber := defaultRedactor.NewScrubber(secret); scrubber.ScrubString(secretPassword) == secretPassword {
+		t.Fatalf("expected the nil redactor to redact the data of Secrets")
+	}
+
+	if scrubber := redactor.NewScrubber(&corev1.Service{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}}); !scrubber.IsEmpty() {
+		t.Fatalf("expected empty scrubber for a kind without sensitive fields")
+	}
+}
+
+func TestScrubError(t *testing.T) {
+	t.Parallel()
+
+	redactor, err := util.NewRedactor(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scrubber := redactor.NewScrubber(newSensitiveSecret())
+
+	invalid := apierrors.NewInvalid(schema.GroupKind{Kind: "Secret"}, "credentials", field.ErrorList{
+		field.Invalid(field.NewPath("data", "password"), secretPassword, "is invalid"),
+	})
+	original := errors.WrapIfWithDetails(invalid, "updating resource failed", "value", secretToken, "name", "credentials")
+
+	scrubbed := scrubber.ScrubError(original)
+	assertNoSecretValues(t, scrubbed.Error())
+	assertNoSecretValues(t, fmt.Sprintf("%+v", scrubbed))
+	assertNoSecretValues(t, fmt.Sprint(errors.GetDetails(scrubbed)...))
+
+	if !apierrors.IsInvalid(scrubbed) {
+		t.Fatalf("scrubbed error does not match the API error")
+	}
+	if !errors.Is(scrubbed, invalid) {
+		t.Fatalf("scrubbed error does not match the wrapped error")
+	}
+	if details := errors.GetDetails(scrubbed); len(details) != 4 || details[3] != "credentials" {
+		t.Fatalf("unexpected details %v", details)
+	}
+
+	if scrubber.ScrubError(nil) != nil {
+		t.Fatalf("expected nil error")
+	}
+}
+
+// captureLogger writes every message, value and error to a buffer, errors are written with their stack traces like
+// the errorVerbose field of zap
+type captureLogger struct {
+	mu     *sync.Mutex
+	buffer *bytes.Buffer
+	values []interface{}
+}
+
+func newCaptureLogger() *captureLogger {
+	return &captureLogger{
+		mu:     &sync.Mutex{},
+		buffer: &bytes.Buffer{},
+	}
+}
+
+func (l *captureLogger) write(msg string, keysAndValues []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fmt.Fprintln(l.buffer, append([]interface{}{msg}, append(l.values, keysAndValues...)...)...)
+}
+
+func (l *captureLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.buffer.String()
+}
+
+func (l *captureLogger) Enabled() bool {
+	return true
+}
+
+func (l *captureLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.write(msg, keysAndValues)
+}
+
+func (l *captureLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	l.write(msg, append([]interface{}{"error", fmt.Sprintf("%+v", err), "details", errors.GetDetails(err)}, keysAndValues...))
+}
+
+func (l *captureLogger) V(level int) logr.Logger {
+	return l
+}
+
+func (l *captureLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return &captureLogger{
+		mu:     l.mu,
+		buffer: l.buffer,
+		values: append(append([]interface{}{}, l.values...), keysAndValues...),
+	}
+}
+
+func (l *captureLogger) WithName(name string) logr.Logger {
+	return l
+}
+
+func TestSecretSyncFailureLogs(t *testing.T) {
+	t.Parallel()
+
+	redactor, err := util.NewRedactor(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := newSensitiveSecret()
+	scrubber := redactor.NewScrubber(secret)
+	capture := newCaptureLogger()
+	log := scrubber.WrapLogger(capture).WithValues("resource", "default/credentials")
+
+	// the logs of a failed sync of a Secret: the reconciler of the synced objects logs the patch and the desired
+	// object, and the failure is returned with the message of the API server echoing the rejected value
+	patch := fmt.Sprintf(`{"data":{"password":"%s"},"stringData":{"token":"%s"}}`, base64.StdEncoding.EncodeToString([]byte(secretPassword)), secretToken)
+	log.V(1).Info("resource diff", "patch", patch, "patchBytes", []byte(patch))
+	log.Error(errors.New("could not set annotation"), "Failed to set last applied annotation", "desired", secret)
+
+	syncErr := errors.WrapIfWithDetails(apierrors.NewInvalid(schema.GroupKind{Kind: "Secret"}, "credentials", field.ErrorList{
+		field.Invalid(field.NewPath("data", "password"), secretPassword, "is invalid"),
+	}), "updating resource failed", "desired", secret)
+	log.Error(scrubber.ScrubError(syncErr), "could not reconcile")
+	log.Error(syncErr, "could not reconcile unscrubbed error")
+
+	output := capture.String()
+	assertNoSecretValues(t, output)
+	for _, expected := range []string{"resource diff", "updating resource failed", "[redacted, 15 bytes, sha256:", "default/credentials"} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("expected %q in the logs:\n%s", expected, output)
+		}
+	}
+}
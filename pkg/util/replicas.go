@@ -0,0 +1,110 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"strings"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// defaultReplicas is the number of replicas of the objects without a replica field, the workload kinds default to it
+const defaultReplicas = 1
+
+// GetReplicaField returns the path of the replica field of the object, which is set by the mutations or known for
+// its kind, or nil if the kind has no known replica field
+func GetReplicaField(obj client.Object, mutations clusterregistryv1alpha1.ReplicaMutations) []string {
+	if mutations.Field != "" {
+		return strings.Split(strings.TrimPrefix(mutations.Field, "."), ".")
+	}
+
+	if clusterregistryv1alpha1.IsWorkloadKind(obj.GetObjectKind().GroupVersionKind().GroupKind()) {
+		return []string{"spec", "replicas"}
+	}
+
+	return nil
+}
+
+// SetReplicas sets the replica field of the object to the value of the mutations, the percentages are of the current
+// value of the field rounded up
+func SetReplicas(obj client.Object, mutations clusterregistryv1alpha1.ReplicaMutations) error {
+	field := GetReplicaField(obj, mutations)
+	if field == nil {
+		return errors.Errorf("replica field of %s is unknown", obj.GetObjectKind().GroupVersionKind())
+	}
+
+	return modifyObjectContent(obj, func(content map[string]interface{}) error {
+		current, ok, err := unstructured.NestedInt64(content, field...)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "invalid replica field", "field", mutations.Field)
+		}
+		if !ok {
+			current = defaultReplicas
+		}
+
+		value := mutations.Value
+		replicas, err := intstr.GetScaledValueFromIntOrPercent(&value, int(current), true)
+		if err != nil {
+			return errors.WrapIf(err, "invalid replicas")
+		}
+
+		return errors.WrapIf(unstructured.SetNestedField(content, int64(replicas), field...), "could not set replicas")
+	})
+}
+
+// RemoveReplicas removes the replica field of the object, so it is left to the local autoscaler, see KeepReplicas
+func RemoveReplicas(obj client.Object, mutations clusterregistryv1alpha1.ReplicaMutations) error {
+	field := GetReplicaField(obj, mutations)
+	if field == nil {
+		return errors.Errorf("replica field of %s is unknown", obj.GetObjectKind().GroupVersionKind())
+	}
+
+	return modifyObjectContent(obj, func(content map[string]interface{}) error {
+		unstructured.RemoveNestedField(content, field...)
+
+		return nil
+	})
+}
+
+// KeepReplicas copies the replica field of the current object to the desired one which does not set it, so the update
+// of the object does not reset the replicas set by the local autoscaler to the default
+func KeepReplicas(current client.Object, desired client.Object, mutations clusterregistryv1alpha1.ReplicaMutations) error {
+	field := GetReplicaField(desired, mutations)
+	if field == nil {
+		return nil
+	}
+
+	currentContent, err := toUnstructuredContent(current)
+	if err != nil {
+		return errors.WrapIf(err, "could not convert current object")
+	}
+	replicas, ok, _ := unstructured.NestedFieldCopy(currentContent, field...)
+	if !ok {
+		return nil
+	}
+
+	return modifyObjectContent(desired, func(content map[string]interface{}) error {
+		if _, ok, _ := unstructured.NestedFieldNoCopy(content, field...); ok {
+			return nil
+		}
+
+		return errors.WrapIf(unstructured.SetNestedField(content, replicas, field...), "could not set replicas")
+	})
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func newReplicatedObjects(replicas int32) map[string]func() client.Object {
+	return map[string]func() client.Object{
+		"deployment": func() client.Object {
+			return &appsv1.Deployment{
+				TypeMeta: v1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				Spec:     appsv1.DeploymentSpec{Replicas: pointer.Int32(replicas)},
+			}
+		},
+		"statefulset": func() client.Object {
+			return &appsv1.StatefulSet{
+				TypeMeta: v1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+				Spec:     appsv1.StatefulSetSpec{Replicas: pointer.Int32(replicas)},
+			}
+		},
+		"custom resource": func() client.Object {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Cache",
+				"spec": map[string]interface{}{
+					"cluster": map[string]interface{}{"size": int64(replicas)},
+				},
+			}}
+		},
+	}
+}
+
+func getReplicas(t *testing.T, obj client.Object) (int64, bool) {
+	t.Helper()
+
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		if o.Spec.Replicas == nil {
+			return 0, false
+		}
+
+		return int64(*o.Spec.Replicas), true
+	case *appsv1.StatefulSet:
+		if o.Spec.Replicas == nil {
+			return 0, false
+		}
+
+		return int64(*o.Spec.Replicas), true
+	case *unstructured.Unstructured:
+		replicas, ok, err := unstructured.NestedInt64(o.Object, "spec", "cluster", "size")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return replicas, ok
+	default:
+		t.Fatalf("unexpected object %T", obj)
+
+		return 0, false
+	}
+}
+
+func replicaMutations(obj client.Object, value intstr.IntOrString) clusterregistryv1alpha1.ReplicaMutations {
+	mutations := clusterregistryv1alpha1.ReplicaMutations{
+		Value:              value,
+		IgnoreIfHPAManaged: true,
+	}
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		mutations.Field = ".spec.cluster.size"
+	}
+
+	return mutations
+}
+
+func TestSetReplicas(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value  intstr.IntOrString
+		wanted int64
+	}{
+		"scaled to zero": {
+			value:  intstr.FromInt(0),
+			wanted: 0,
+		},
+		"fixed number": {
+			value:  intstr.FromInt(2),
+			wanted: 2,
+		},
+		"percentage rounded up": {
+			value:  intstr.FromString("50%"),
+			wanted: 3,
+		},
+	}
+
+	for name, test := range tests {
+		for kind, newObject := range newReplicatedObjects(5) {
+			obj := newObject()
+			if err := util.SetReplicas(obj, replicaMutations(obj, test.value)); err != nil {
+				t.Fatalf("%s (%s): %+v", name, kind, err)
+			}
+
+			if replicas, ok := getReplicas(t, obj); !ok || replicas != test.wanted {
+				t.Fatalf("%s (%s): unexpected replicas %d", name, kind, replicas)
+			}
+		}
+	}
+}
+
+func TestSetReplicasUnknownField(t *testing.T) {
+	t.Parallel()
+
+	obj := newReplicatedObjects(1)["custom resource"]()
+	if err := util.SetReplicas(obj, clusterregistryv1alpha1.ReplicaMutations{Value: intstr.FromInt(0)}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestKeepReplicas(t *testing.T) {
+	t.Parallel()
+
+	for kind, newObject := range newReplicatedObjects(5) {
+		current := newObject()
+		desired := newObject()
+		mutations := replicaMutations(desired, intstr.FromInt(0))
+
+		// the desired state keeps its own replicas
+		if err := util.KeepReplicas(current, desired, mutations); err != nil {
+			t.Fatalf("%s: %+v", kind, err)
+		}
+		if replicas, ok := getReplicas(t, desired); !ok || replicas != 5 {
+			t.Fatalf("%s: unexpected replicas %d", kind, replicas)
+		}
+
+		if err := util.RemoveReplicas(desired, mutations); err != nil {
+			t.Fatalf("%s: %+v", kind, err)
+		}
+		if _, ok := getReplicas(t, desired); ok {
+			t.Fatalf("%s: replicas were not removed", kind)
+		}
+
+		// the replicas of the local autoscaler are kept
+		current = newReplicatedObjects(7)[kind]()
+		if err := util.KeepReplicas(current, desired, mutations); err != nil {
+			t.Fatalf("%s: %+v", kind, err)
+		}
+		if replicas, ok := getReplicas(t, desired); !ok || replicas != 7 {
+			t.Fatalf("%s: unexpected replicas %d", kind, replicas)
+		}
+	}
+}
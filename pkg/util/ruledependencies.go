@@ -0,0 +1,78 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"sort"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// GetRuleDependencies returns the rules each of the rules depends on by their names
+func GetRuleDependencies(rules []clusterregistryv1alpha1.ResourceSyncRule) map[string][]string {
+	dependencies := make(map[string][]string, len(rules))
+	for _, rule := range rules {
+		dependencies[rule.GetName()] = rule.Spec.DependsOn
+	}
+
+	return dependencies
+}
+
+// FindDependencyCycle returns the rules of a dependency cycle going through the rule, starting and ending with it, or
+// nil if the rule is not part of a cycle
+func FindDependencyCycle(dependencies map[string][]string, rule string) []string {
+	visited := make(map[string]struct{})
+
+	var find func(name string, path []string) []string
+	find = func(name string, path []string) []string {
+		path = append(path, name)
+		if len(path) > 1 && name == rule {
+			return path
+		}
+		if _, ok := visited[name]; ok {
+			return nil
+		}
+		visited[name] = struct{}{}
+
+		// the dependencies are followed in order of their names, so the same cycle is reported every time
+		next := append([]string{}, dependencies[name]...)
+		sort.Strings(next)
+		for _, dependency := range next {
+			if cycle := find(dependency, path); cycle != nil {
+				return cycle
+			}
+		}
+
+		return nil
+	}
+
+	return find(rule, make([]string, 0))
+}
+
+// GetDependentRules returns the names of the rules depending on the rule
+func GetDependentRules(rules []clusterregistryv1alpha1.ResourceSyncRule, rule string) []string {
+	dependents := make([]string, 0)
+	for _, r := range rules {
+		for _, dependency := range r.Spec.DependsOn {
+			if dependency == rule {
+				dependents = append(dependents, r.GetName())
+
+				break
+			}
+		}
+	}
+
+	return dependents
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestFindDependencyCycle(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		dependencies map[string][]string
+		rule         string
+		cycle        []string
+	}{
+		"no dependencies": {
+			dependencies: map[string][]string{"a": nil},
+			rule:         "a",
+		},
+		"chain": {
+			dependencies: map[string][]string{"a": {"b"}, "b": {"c"}, "c": nil},
+			rule:         "a",
+		},
+		"missing dependency": {
+			dependencies: map[string][]string{"a": {"b"}},
+			rule:         "a",
+		},
+		"self dependency": {
+			dependencies: map[string][]string{"a": {"a"}},
+			rule:         "a",
+			cycle:        []string{"a", "a"},
+		},
+		"cycle": {
+			dependencies: map[string][]string{"a": {"c", "b"}, "b": {"c"}, "c": {"a"}},
+			rule:         "a",
+			cycle:        []string{"a", "b", "c", "a"},
+		},
+		"cycle not through the rule": {
+			dependencies: map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"b"}},
+			rule:         "a",
+		},
+		"diamond": {
+			dependencies: map[string][]string{"a": {"b", "c"}, "b": {"d"}, "c": {"d"}, "d": nil},
+			rule:         "a",
+		},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cycle := util.FindDependencyCycle(test.dependencies, test.rule)
+			if !reflect.DeepEqual(cycle, test.cycle) {
+				t.Fatalf("expected cycle %v, got %v", test.cycle, cycle)
+			}
+		})
+	}
+}
+
+func TestGetDependentRules(t *testing.T) {
+	t.Parallel()
+
+	rules := []clusterregistryv1alpha1.ResourceSyncRule{
+		{ObjectMeta: metav1.ObjectMeta{Name: "crds"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "widgets"}, Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{DependsOn: []string{"crds"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "gadgets"}, Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{DependsOn: []string{"widgets", "crds"}}},
+	}
+
+	if dependents := util.GetDependentRules(rules, "crds"); !reflect.DeepEqual(dependents, []string{"widgets", "gadgets"}) {
+		t.Fatalf("unexpected dependents of crds: %v", dependents)
+	}
+	if dependents := util.GetDependentRules(rules, "gadgets"); len(dependents) != 0 {
+		t.Fatalf("unexpected dependents of gadgets: %v", dependents)
+	}
+
+	dependencies := util.GetRuleDependencies(rules)
+	if !reflect.DeepEqual(dependencies["gadgets"], []string{"widgets", "crds"}) || len(dependencies["crds"]) != 0 {
+		t.Fatalf("unexpected dependencies: %v", dependencies)
+	}
+}
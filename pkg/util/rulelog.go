@@ -0,0 +1,247 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+)
+
+// RuleLogLevel is the log level of the sync controllers of a rule, the default level logs like the rest of the
+// controller
+type RuleLogLevel string
+
+const (
+	RuleLogLevelDefault RuleLogLevel = ""
+	// RuleLogLevelError logs the errors only
+	RuleLogLevelError RuleLogLevel = "error"
+	// RuleLogLevelInfo logs the errors and the info lines, without the verbose ones
+	RuleLogLevelInfo RuleLogLevel = "info"
+	// RuleLogLevelDebug logs the verbose lines of V(1) as well, whatever the verbosity of the controller is
+	RuleLogLevelDebug RuleLogLevel = "debug"
+	// RuleLogLevelTrace logs every verbose line
+	RuleLogLevelTrace RuleLogLevel = "trace"
+)
+
+// ParseRuleLogLevel parses the log level set on a rule, the level is case insensitive
+func ParseRuleLogLevel(value string) (RuleLogLevel, error) {
+	level := RuleLogLevel(strings.ToLower(strings.TrimSpace(value)))
+	switch level {
+	case RuleLogLevelDefault, RuleLogLevelError, RuleLogLevelInfo, RuleLogLevelDebug, RuleLogLevelTrace:
+		return level, nil
+	default:
+		return RuleLogLevelDefault, errors.NewWithDetails("invalid log level", "level", value)
+	}
+}
+
+// IsVerbose returns whether the level logs verbose lines
+func (l RuleLogLevel) IsVerbose() bool {
+	return l == RuleLogLevelDebug || l == RuleLogLevelTrace
+}
+
+// verbosity returns the highest V level of the lines logged at the level
+func (l RuleLogLevel) verbosity() int {
+	switch l {
+	case RuleLogLevelError:
+		return -1
+	case RuleLogLevelInfo:
+		return 0
+	case RuleLogLevelDebug:
+		return 1
+	default:
+		return math.MaxInt
+	}
+}
+
+// WithRuleLogLevel returns a logger logging the lines enabled by the level, the lines of the V levels enabled by it are
+// logged as info lines of the logger with their V level, so they are logged whatever the verbosity of the logger is.
+// The logger is returned as is with the default level.
+func WithRuleLogLevel(log logr.Logger, level RuleLogLevel) logr.Logger {
+	if level == RuleLogLevelDefault {
+		return log
+	}
+
+	return &levelLogger{
+		Logger:    log,
+		verbosity: level.verbosity(),
+	}
+}
+
+type levelLogger struct {
+	logr.Logger
+
+	verbosity int
+	level     int
+}
+
+func (l *levelLogger) Enabled() bool {
+	return l.level <= l.verbosity
+}
+
+func (l *levelLogger) Info(msg string, keysAndValues ...interface{}) {
+	if !l.Enabled() {
+		return
+	}
+
+	if l.level > 0 {
+		keysAndValues = append([]interface{}{"v", l.level}, keysAndValues...)
+	}
+	l.Logger.Info(msg, keysAndValues...)
+}
+
+func (l *levelLogger) V(level int) logr.Logger {
+	return &levelLogger{
+		Logger:    l.Logger,
+		verbosity: l.verbosity,
+		level:     l.level + level,
+	}
+}
+
+func (l *levelLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return &levelLogger{
+		Logger:    l.Logger.WithValues(keysAndValues...),
+		verbosity: l.verbosity,
+		level:     l.level,
+	}
+}
+
+func (l *levelLogger) WithName(name string) logr.Logger {
+	return &levelLogger{
+		Logger:    l.Logger.WithName(name),
+		verbosity: l.verbosity,
+		level:     l.level,
+	}
+}
+
+// LogSampler limits the lines of a message logged for a key, e.g. a rule, to a number of lines per second. The number
+// of the lines suppressed since the last logged one is added to the next logged line of the message.
+type LogSampler struct {
+	linesPerSecond int
+	now            func() time.Time
+
+	windows map[logSampleKey]*logSampleWindow
+	mu      sync.Mutex
+}
+
+type logSampleKey struct {
+	key string
+	msg string
+}
+
+type logSampleWindow struct {
+	start      time.Time
+	logged     int
+	suppressed int
+}
+
+type LogSamplerOption func(s *LogSampler)
+
+// WithLogSamplerClock sets the function returning the current time, e.g. for testing
+func WithLogSamplerClock(now func() time.Time) LogSamplerOption {
+	return func(s *LogSampler) {
+		s.now = now
+	}
+}
+
+// NewLogSampler returns a sampler logging the lines per second of every message for every key, 0 disables sampling
+func NewLogSampler(linesPerSecond int, opts ...LogSamplerOption) *LogSampler {
+	s := &LogSampler{
+		linesPerSecond: linesPerSecond,
+		now:            time.Now,
+		windows:        make(map[logSampleKey]*logSampleWindow),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Allow returns whether a line of the message is logged for the key, and the number of lines of the message suppressed
+// for the key since the last logged one if it is
+func (s *LogSampler) Allow(key, msg string) (bool, int) {
+	if s == nil || s.linesPerSecond <= 0 {
+		return true, 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	w, ok := s.windows[logSampleKey{key: key, msg: msg}]
+	if !ok {
+		w = &logSampleWindow{}
+		s.windows[logSampleKey{key: key, msg: msg}] = w
+	}
+	if now.Sub(w.start) >= time.Second {
+		w.start = now
+		w.logged = 0
+	}
+
+	if w.logged >= s.linesPerSecond {
+		w.suppressed++
+
+		return false, 0
+	}
+
+	w.logged++
+	suppressed := w.suppressed
+	w.suppressed = 0
+
+	return true, suppressed
+}
+
+// Info logs the message with the logger unless the lines of the message logged for the key in the last second reached
+// the limit of the sampler, and returns whether it was logged. The logged line gets the number of the lines suppressed
+// before it as the "suppressed" value.
+func (s *LogSampler) Info(log logr.Logger, key, msg string, keysAndValues ...interface{}) bool {
+	if !log.Enabled() {
+		return true
+	}
+
+	allowed, suppressed := s.Allow(key, msg)
+	if !allowed {
+		return false
+	}
+
+	if suppressed > 0 {
+		keysAndValues = append(keysAndValues, "suppressed", suppressed)
+	}
+	log.Info(msg, keysAndValues...)
+
+	return true
+}
+
+// Forget drops the state of the messages of the key, e.g. once a rule is deleted
+func (s *LogSampler) Forget(key string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k := range s.windows {
+		if k.key == key {
+			delete(s.windows, k)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestParseRuleLogLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		level   util.RuleLogLevel
+		invalid bool
+	}{
+		{value: "", level: util.RuleLogLevelDefault},
+		{value: "error", level: util.RuleLogLevelError},
+		{value: "Info", level: util.RuleLogLevelInfo},
+		{value: " DEBUG ", level: util.RuleLogLevelDebug},
+		{value: "trace", level: util.RuleLogLevelTrace},
+		{value: "verbose", invalid: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.value, func(t *testing.T) {
+			t.Parallel()
+
+			level, err := util.ParseRuleLogLevel(test.value)
+			if test.invalid != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if level != test.level {
+				t.Fatalf("unexpected level: %q", level)
+			}
+		})
+	}
+}
+
+func TestWithRuleLogLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		level    util.RuleLogLevel
+		expected []string
+		dropped  []string
+	}{
+		{level: util.RuleLogLevelDefault, expected: []string{"error line", "info line", "debug line", "trace line"}},
+		{level: util.RuleLogLevelError, expected: []string{"error line"}, dropped: []string{"info line", "debug line", "trace line"}},
+		{level: util.RuleLogLevelInfo, expected: []string{"error line", "info line"}, dropped: []string{"debug line", "trace line"}},
+		{level: util.RuleLogLevelDebug, expected: []string{"error line", "info line", "debug line rule test v 1"}, dropped: []string{"trace line"}},
+		{level: util.RuleLogLevelTrace, expected: []string{"error line", "info line", "debug line rule test v 1", "trace line rule test key value v 2"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(string(test.level), func(t *testing.T) {
+			t.Parallel()
+
+			capture := newCaptureLogger()
+			log := util.WithRuleLogLevel(capture, test.level).WithValues("rule", "test")
+
+			log.Error(errors.New("failed"), "error line")
+			log.Info("info line")
+			log.V(1).Info("debug line")
+			log.V(1).WithValues("key", "value").V(1).Info("trace line")
+
+			logs := capture.String()
+			for _, line := range test.expected {
+				if !strings.Contains(logs, line) {
+					t.Fatalf("%q is not logged:\n%s", line, logs)
+				}
+			}
+			for _, line := range test.dropped {
+				if strings.Contains(logs, line) {
+					t.Fatalf("%q is logged:\n%s", line, logs)
+				}
+			}
+			if !strings.Contains(logs, "rule test") {
+				t.Fatalf("values of the logger are not logged:\n%s", logs)
+			}
+		})
+	}
+}
+
+func TestLogSampler(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	sampler := util.NewLogSampler(2, util.WithLogSamplerClock(func() time.Time {
+		return now
+	}))
+	capture := newCaptureLogger()
+
+	for i := 0; i < 5; i++ {
+		logged := sampler.Info(capture, "rule-a", "reconciling")
+		if logged != (i < 2) {
+			t.Fatalf("line %d logged: %t", i, logged)
+		}
+	}
+	// the messages and the keys are sampled separately
+	if !sampler.Info(capture, "rule-a", "object reconciled") || !sampler.Info(capture, "rule-b", "reconciling") {
+		t.Fatal("other messages and keys must not be suppressed")
+	}
+
+	now = now.Add(time.Second)
+	if !sampler.Info(capture, "rule-a", "reconciling") {
+		t.Fatal("line must be logged in the next second")
+	}
+	if logs := capture.String(); strings.Count(logs, "reconciling") != 4 || !strings.Contains(logs, "reconciling suppressed 3") {
+		t.Fatalf("unexpected logs:\n%s", logs)
+	}
+
+	// the suppressed lines are only reported once
+	now = now.Add(time.Second)
+	sampler.Info(capture, "rule-a", "reconciling")
+	if logs := capture.String(); strings.Count(logs, "suppressed") != 1 {
+		t.Fatalf("unexpected logs:\n%s", logs)
+	}
+}
+
+func TestLogSamplerDisabled(t *testing.T) {
+	t.Parallel()
+
+	for _, sampler := range []*util.LogSampler{nil, util.NewLogSampler(0)} {
+		for i := 0; i < 100; i++ {
+			if allowed, _ := sampler.Allow("rule", "reconciling"); !allowed {
+				t.Fatal("lines must not be suppressed without a limit")
+			}
+		}
+	}
+}
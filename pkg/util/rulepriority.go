@@ -0,0 +1,133 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// RuleRegistry keeps the rules syncing from each cluster, so the rules matching the same object can agree on which
+// one of them syncs it
+type RuleRegistry struct {
+	rules map[ruleRegistryKey]registeredRule
+	mu    sync.RWMutex
+}
+
+type ruleRegistryKey struct {
+	cluster string
+	rule    string
+}
+
+type registeredRule struct {
+	spec     clusterregistryv1alpha1.ResourceSyncRuleSpec
+	onChange func()
+}
+
+func NewRuleRegistry() *RuleRegistry {
+	return &RuleRegistry{
+		rules: make(map[ruleRegistryKey]registeredRule),
+	}
+}
+
+// Register adds or replaces the rule syncing from the cluster. The onChange func is called whenever another rule of the
+// same kind is registered or unregistered for the cluster, since the resolution of its objects could change.
+func (r *RuleRegistry) Register(cluster string, rule *clusterregistryv1alpha1.ResourceSyncRule, onChange func()) {
+	r.mu.Lock()
+	r.rules[ruleRegistryKey{cluster: cluster, rule: rule.GetName()}] = registeredRule{
+		spec:     *rule.Spec.DeepCopy(),
+		onChange: onChange,
+	}
+	notify := r.getAffected(cluster, rule.GetName(), rule.Spec)
+	r.mu.Unlock()
+
+	for _, f := range notify {
+		f()
+	}
+}
+
+// Unregister removes the rule syncing from the cluster
+func (r *RuleRegistry) Unregister(cluster string, name string) {
+	r.mu.Lock()
+	key := ruleRegistryKey{cluster: cluster, rule: name}
+	current, ok := r.rules[key]
+	if !ok {
+		r.mu.Unlock()
+
+		return
+	}
+	delete(r.rules, key)
+	notify := r.getAffected(cluster, name, current.spec)
+	r.mu.Unlock()
+
+	for _, f := range notify {
+		f()
+	}
+}
+
+// Resolve returns the name of the rule syncing the object from the cluster: the matching rule with the highest
+// priority, or the one with the lowest name among the matching rules with the same priority, in which case tied is
+// true. The winner is empty if no registered rule matches the object.
+func (r *RuleRegistry) Resolve(cluster string, obj runtime.Object) (winner string, tied bool, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var priority int
+	for key, rule := range r.rules {
+		if key.cluster != cluster {
+			continue
+		}
+
+		ok, _, err := rule.spec.Match(obj)
+		if err != nil {
+			return "", false, err
+		}
+		if !ok {
+			continue
+		}
+
+		switch {
+		case winner == "" || rule.spec.Priority > priority:
+			winner, priority, tied = key.rule, rule.spec.Priority, false
+		case rule.spec.Priority == priority:
+			tied = true
+			if key.rule < winner {
+				winner = key.rule
+			}
+		}
+	}
+
+	return winner, tied, nil
+}
+
+// getAffected returns the change funcs of the other rules of the same kind, it must be called with mu held
+func (r *RuleRegistry) getAffected(cluster string, name string, spec clusterregistryv1alpha1.ResourceSyncRuleSpec) []func() {
+	funcs := make([]func(), 0)
+	for key, rule := range r.rules {
+		if key.cluster != cluster || key.rule == name || rule.onChange == nil {
+			continue
+		}
+		if rule.spec.GVK.Group != spec.GVK.Group || rule.spec.GVK.Kind != spec.GVK.Kind {
+			continue
+		}
+
+		funcs = append(funcs, rule.onChange)
+	}
+
+	return funcs
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func newPriorityRule(name string, priority int, labels map[string]string) *clusterregistryv1alpha1.ResourceSyncRule {
+	rule := &clusterregistryv1alpha1.ResourceSyncRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+			GVK: resources.GroupVersionKind{
+				Version: "v1",
+				Kind:    "ConfigMap",
+			},
+			Priority: priority,
+		},
+	}
+	if labels != nil {
+		rule.Spec.Rules = []clusterregistryv1alpha1.SyncRule{
+			{
+				Matches: []clusterregistryv1alpha1.SyncRuleMatch{
+					{
+						Labels: []metav1.LabelSelector{
+							{
+								MatchLabels: labels,
+							},
+						},
+					},
+				},
+			},
+		}
+	} else {
+		rule.Spec.Rules = []clusterregistryv1alpha1.SyncRule{{}}
+	}
+
+	return rule
+}
+
+func TestRuleRegistryResolve(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		rules  []*clusterregistryv1alpha1.ResourceSyncRule
+		winner string
+		tied   bool
+	}{
+		"single rule": {
+			rules:  []*clusterregistryv1alpha1.ResourceSyncRule{newPriorityRule("a", 0, nil)},
+			winner: "a",
+		},
+		"higher priority wins": {
+			rules: []*clusterregistryv1alpha1.ResourceSyncRule{
+				newPriorityRule("a", 0, nil),
+				newPriorityRule("b", 10, nil),
+			},
+			winner: "b",
+		},
+		"tie is broken by name": {
+			rules: []*clusterregistryv1alpha1.ResourceSyncRule{
+				newPriorityRule("b", 5, nil),
+				newPriorityRule("a", 5, nil),
+				newPriorityRule("c", 1, nil),
+			},
+			winner: "a",
+			tied:   true,
+		},
+		"not matching rules are ignored": {
+			rules: []*clusterregistryv1alpha1.ResourceSyncRule{
+				newPriorityRule("a", 0, nil),
+				newPriorityRule("b", 10, map[string]string{"team": "search"}),
+			},
+			winner: "a",
+		},
+		"no matching rule": {
+			rules: []*clusterregistryv1alpha1.ResourceSyncRule{
+				newPriorityRule("a", 0, map[string]string{"team": "search"}),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			registry := util.NewRuleRegistry()
+			for _, rule := range test.rules {
+				registry.Register("cluster-1", rule, nil)
+			}
+			// rules of other clusters are not considered
+			registry.Register("cluster-2", newPriorityRule("0-other", 100, nil), nil)
+
+			obj := &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "default",
+					Labels:    map[string]string{"team": "payments"},
+				},
+			}
+
+			winner, tied, err := registry.Resolve("cluster-1", obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if winner != test.winner || tied != test.tied {
+				t.Fatalf("expected winner %q (tied: %t), got %q (tied: %t)", test.winner, test.tied, winner, tied)
+			}
+		})
+	}
+}
+
+func TestRuleRegistryNotifiesRulesOfSameKind(t *testing.T) {
+	t.Parallel()
+
+	registry := util.NewRuleRegistry()
+
+	var notified []string
+	registry.Register("cluster-1", newPriorityRule("a", 0, nil), func() { notified = append(notified, "a") })
+
+	secrets := newPriorityRule("secrets", 0, nil)
+	secrets.Spec.GVK.Kind = "Secret"
+	registry.Register("cluster-1", secrets, func() { notified = append(notified, "secrets") })
+	registry.Register("cluster-2", newPriorityRule("other", 0, nil), func() { notified = append(notified, "other") })
+	if len(notified) != 0 {
+		t.Fatalf("expected no notification for rules of other kinds and clusters, got %v", notified)
+	}
+
+	registry.Register("cluster-1", newPriorityRule("b", 10, nil), nil)
+	if len(notified) != 1 || notified[0] != "a" {
+		t.Fatalf("expected rule a to be notified on register, got %v", notified)
+	}
+
+	registry.Unregister("cluster-1", "b")
+	if len(notified) != 2 || notified[1] != "a" {
+		t.Fatalf("expected rule a to be notified on unregister, got %v", notified)
+	}
+
+	registry.Unregister("cluster-1", "b")
+	if len(notified) != 2 {
+		t.Fatalf("expected no notification for unknown rule, got %v", notified)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"sync"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type fieldSanitizer func(obj map[string]interface{}) error
+
+// fieldSanitizers clear fields which are owned by allocators of the source cluster
+// and are either invalid or immutable on the target cluster
+var fieldSanitizers = map[schema.GroupVersionKind]fieldSanitizer{
+	corev1.SchemeGroupVersion.WithKind("Service"):               sanitizeService,
+	corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"): sanitizePersistentVolumeClaim,
+}
+
+// persistentVolumeClaimBindingAnnotations are set on the claims by the volume controllers of the source cluster when
+// they are bound and provisioned, the local controllers would skip the provisioning of the claims having them
+var persistentVolumeClaimBindingAnnotations = []string{
+	"pv.kubernetes.io/bind-completed",
+	"pv.kubernetes.io/bound-by-controller",
+	"volume.beta.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/selected-node",
+}
+
+// statusPreservingKinds have a spec-like status, which is kept even if the status is not synced,
+// e.g. the kinds of some aggregated APIs
+var (
+	statusPreservingKinds   = map[schema.GroupKind]struct{}{}
+	statusPreservingKindsMu sync.RWMutex
+)
+
+// RegisterStatusPreservingKind makes PruneStatus keep the status of the objects of the kind
+func RegisterStatusPreservingKind(gk schema.GroupKind) {
+	statusPreservingKindsMu.Lock()
+	defer statusPreservingKindsMu.Unlock()
+
+	statusPreservingKinds[gk] = struct{}{}
+}
+
+// IsStatusPreservingKind returns whether the status of the kind is kept even if the status is not synced
+func IsStatusPreservingKind(gk schema.GroupKind) bool {
+	statusPreservingKindsMu.RLock()
+	defer statusPreservingKindsMu.RUnlock()
+
+	_, ok := statusPreservingKinds[gk]
+
+	return ok
+}
+
+// SanitizeObject clears the cluster specific fields of the object based on its GVK.
+// Objects without a registered sanitizer are returned unchanged.
+func SanitizeObject(obj client.Object) error {
+	sanitize, ok := fieldSanitizers[obj.GetObjectKind().GroupVersionKind()]
+	if !ok {
+		return nil
+	}
+
+	return modifyObjectContent(obj, sanitize)
+}
+
+// PruneStatus removes the status of the object, so the status of the source object is not written when the status
+// is not synced. The status of the registered status preserving kinds is kept.
+func PruneStatus(obj client.Object) error {
+	if IsStatusPreservingKind(obj.GetObjectKind().GroupVersionKind().GroupKind()) {
+		return nil
+	}
+
+	return modifyObjectContent(obj, func(content map[string]interface{}) error {
+		unstructured.RemoveNestedField(content, "status")
+
+		return nil
+	})
+}
+
+func modifyObjectContent(obj client.Object, modify func(content map[string]interface{}) error) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return modify(u.Object)
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return errors.WrapIf(err, "could not convert object to unstructured")
+	}
+
+	if err := modify(content); err != nil {
+		return err
+	}
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj); err != nil {
+		return errors.WrapIf(err, "could not convert object from unstructured")
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	return nil
+}
+
+func sanitizeService(obj map[string]interface{}) error {
+	// headless services must keep their explicitly requested cluster IP
+	if clusterIP, _, _ := unstructured.NestedString(obj, "spec", "clusterIP"); clusterIP != corev1.ClusterIPNone {
+		unstructured.RemoveNestedField(obj, "spec", "clusterIP")
+		unstructured.RemoveNestedField(obj, "spec", "clusterIPs")
+	}
+
+	unstructured.RemoveNestedField(obj, "spec", "healthCheckNodePort")
+	unstructured.RemoveNestedField(obj, "status", "loadBalancer")
+
+	ports, ok, err := unstructured.NestedSlice(obj, "spec", "ports")
+	if err != nil {
+		return errors.WrapIf(err, "could not get service ports")
+	}
+	if !ok {
+		return nil
+	}
+
+	for _, port := range ports {
+		if p, ok := port.(map[string]interface{}); ok {
+			delete(p, "nodePort")
+		}
+	}
+
+	return errors.WrapIf(unstructured.SetNestedSlice(obj, ports, "spec", "ports"), "could not set service ports")
+}
+
+// sanitizePersistentVolumeClaim unbinds the claim from the volume of the source cluster, so the local controllers
+// provision or bind a local volume
+func sanitizePersistentVolumeClaim(obj map[string]interface{}) error {
+	unstructured.RemoveNestedField(obj, "spec", "volumeName")
+
+	for _, annotation := range persistentVolumeClaimBindingAnnotations {
+		unstructured.RemoveNestedField(obj, "metadata", "annotations", annotation)
+	}
+
+	return nil
+}
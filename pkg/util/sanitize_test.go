@@ -0,0 +1,247 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func newService(serviceType corev1.ServiceType) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test-service",
+			Namespace: "default",
+		},
+		Spec: corev1.ServiceSpec{
+			Type:       serviceType,
+			ClusterIP:  "10.0.0.10",
+			ClusterIPs: []string{"10.0.0.10"},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+				},
+			},
+			Selector: map[string]string{
+				"app": "test",
+			},
+		},
+	}
+}
+
+func TestSanitizeService(t *testing.T) {
+	t.Parallel()
+
+	nodePort := newService(corev1.ServiceTypeNodePort)
+	nodePort.Spec.Ports[0].NodePort = 30080
+
+	loadBalancer := newService(corev1.ServiceTypeLoadBalancer)
+	loadBalancer.Spec.Ports[0].NodePort = 30080
+	loadBalancer.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+	loadBalancer.Spec.HealthCheckNodePort = 30081
+	loadBalancer.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{
+		{
+			IP: "1.2.3.4",
+		},
+	}
+
+	headless := newService(corev1.ServiceTypeClusterIP)
+	headless.Spec.ClusterIP = corev1.ClusterIPNone
+	headless.Spec.ClusterIPs = []string{corev1.ClusterIPNone}
+
+	tests := map[string]struct {
+		service   *corev1.Service
+		clusterIP string
+	}{
+		"cluster ip": {
+			service: newService(corev1.ServiceTypeClusterIP),
+		},
+		"node port": {
+			service: nodePort,
+		},
+		"load balancer": {
+			service: loadBalancer,
+		},
+		"headless": {
+			service:   headless,
+			clusterIP: corev1.ClusterIPNone,
+		},
+	}
+
+	for name, test := range tests {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(test.service.DeepCopy())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for kind, obj := range map[string]client.Object{
+			"typed":        test.service.DeepCopy(),
+			"unstructured": &unstructured.Unstructured{Object: content},
+		} {
+			if err := util.SanitizeObject(obj); err != nil {
+				t.Fatalf("%s (%s): %+v", name, kind, err)
+			}
+
+			var service corev1.Service
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &service); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				service = *obj.(*corev1.Service) // nolint:forcetypeassert
+			}
+
+			if service.GroupVersionKind() != test.service.GroupVersionKind() {
+				t.Fatalf("%s (%s): gvk changed to %s", name, kind, service.GroupVersionKind())
+			}
+			if service.Spec.ClusterIP != test.clusterIP {
+				t.Fatalf("%s (%s): unexpected cluster ip %q", name, kind, service.Spec.ClusterIP)
+			}
+			if test.clusterIP == "" && len(service.Spec.ClusterIPs) != 0 {
+				t.Fatalf("%s (%s): unexpected cluster ips %v", name, kind, service.Spec.ClusterIPs)
+			}
+			if service.Spec.Ports[0].NodePort != 0 || service.Spec.HealthCheckNodePort != 0 {
+				t.Fatalf("%s (%s): node ports were not cleared", name, kind)
+			}
+			if len(service.Status.LoadBalancer.Ingress) != 0 {
+				t.Fatalf("%s (%s): load balancer status was not cleared", name, kind)
+			}
+			if service.Spec.Type != test.service.Spec.Type || service.Spec.Ports[0].Port != 80 || service.Spec.Selector["app"] != "test" {
+				t.Fatalf("%s (%s): unrelated fields were modified: %+v", name, kind, service.Spec)
+			}
+		}
+	}
+}
+
+func TestSanitizeUnknownGVK(t *testing.T) {
+	t.Parallel()
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		Data: map[string]string{
+			"clusterIP": "10.0.0.10",
+		},
+	}
+
+	if err := util.SanitizeObject(cm); err != nil {
+		t.Fatal(err)
+	}
+	if cm.Data["clusterIP"] != "10.0.0.10" {
+		t.Fatalf("unexpected modification: %v", cm.Data)
+	}
+}
+
+func TestPruneStatus(t *testing.T) {
+	t.Parallel()
+
+	deployment := &appsv1.Deployment{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 3,
+			ReadyReplicas:      2,
+			Conditions: []appsv1.DeploymentCondition{
+				{
+					Type:   appsv1.DeploymentAvailable,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		},
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment.DeepCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for kind, obj := range map[string]client.Object{
+		"typed":        deployment.DeepCopy(),
+		"unstructured": &unstructured.Unstructured{Object: content},
+	} {
+		if err := util.PruneStatus(obj); err != nil {
+			t.Fatalf("%s: %+v", kind, err)
+		}
+
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			if _, ok := u.Object["status"]; ok {
+				t.Fatalf("%s: status was not pruned: %v", kind, u.Object["status"])
+			}
+
+			continue
+		}
+
+		pruned := obj.(*appsv1.Deployment) // nolint:forcetypeassert
+		if pruned.GroupVersionKind() != deployment.GroupVersionKind() {
+			t.Fatalf("%s: gvk changed to %s", kind, pruned.GroupVersionKind())
+		}
+		if pruned.Status.ObservedGeneration != 0 || pruned.Status.ReadyReplicas != 0 || len(pruned.Status.Conditions) != 0 {
+			t.Fatalf("%s: status was not pruned: %+v", kind, pruned.Status)
+		}
+		if pruned.GetName() != deployment.GetName() || pruned.GetNamespace() != deployment.GetNamespace() {
+			t.Fatalf("%s: unrelated fields were modified: %+v", kind, pruned.ObjectMeta)
+		}
+	}
+}
+
+func TestPruneStatusPreservingKind(t *testing.T) {
+	t.Parallel()
+
+	util.RegisterStatusPreservingKind(schema.GroupKind{Group: "aggregated.example.com", Kind: "Report"})
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "aggregated.example.com/v1",
+			"kind":       "Report",
+			"metadata": map[string]interface{}{
+				"name": "test",
+			},
+			"status": map[string]interface{}{
+				"summary": "spec-like",
+			},
+		},
+	}
+
+	if err := util.PruneStatus(obj); err != nil {
+		t.Fatal(err)
+	}
+	if summary, _, _ := unstructured.NestedString(obj.Object, "status", "summary"); summary != "spec-like" {
+		t.Fatalf("status of a status preserving kind was pruned: %v", obj.Object)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+var ErrEmptySecretData = errors.New("secret data is empty after pruning")
+
+// PruneSecretData keeps only the included keys, if any is specified, and removes the excluded keys
+// from the data and stringData of the Secret. ErrEmptySecretData is returned if no key is left.
+func PruneSecretData(obj client.Object, mutations clusterregistryv1alpha1.SecretDataMutations) error {
+	switch o := obj.(type) {
+	case *corev1.Secret:
+		for k := range o.Data {
+			if !keepSecretDataKey(k, mutations) {
+				delete(o.Data, k)
+			}
+		}
+		for k := range o.StringData {
+			if !keepSecretDataKey(k, mutations) {
+				delete(o.StringData, k)
+			}
+		}
+		if len(o.Data) == 0 && len(o.StringData) == 0 {
+			return errors.WithDetails(ErrEmptySecretData, "name", o.GetName(), "namespace", o.GetNamespace())
+		}
+	case *unstructured.Unstructured:
+		keys := 0
+		for _, field := range []string{"data", "stringData"} {
+			data, ok := o.Object[field].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for k := range data {
+				if !keepSecretDataKey(k, mutations) {
+					delete(data, k)
+				}
+			}
+			keys += len(data)
+		}
+		if keys == 0 {
+			return errors.WithDetails(ErrEmptySecretData, "name", o.GetName(), "namespace", o.GetNamespace())
+		}
+	default:
+		return errors.Errorf("secret data can not be pruned on %T", obj)
+	}
+
+	return nil
+}
+
+func keepSecretDataKey(key string, mutations clusterregistryv1alpha1.SecretDataMutations) bool {
+	if len(mutations.IncludeKeys) > 0 && !containsString(mutations.IncludeKeys, key) {
+		return false
+	}
+
+	return !containsString(mutations.ExcludeKeys, key)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestPruneSecretData(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		mutations clusterregistryv1alpha1.SecretDataMutations
+		wanted    []string
+		wantEmpty bool
+	}{
+		"include keys": {
+			mutations: clusterregistryv1alpha1.SecretDataMutations{
+				IncludeKeys: []string{"ca.crt", "extra"},
+			},
+			wanted: []string{"ca.crt", "extra"},
+		},
+		"exclude keys": {
+			mutations: clusterregistryv1alpha1.SecretDataMutations{
+				ExcludeKeys: []string{"tls.key"},
+			},
+			wanted: []string{"ca.crt", "extra", "tls.crt"},
+		},
+		"include and exclude keys": {
+			mutations: clusterregistryv1alpha1.SecretDataMutations{
+				IncludeKeys: []string{"ca.crt", "tls.key"},
+				ExcludeKeys: []string{"tls.key"},
+			},
+			wanted: []string{"ca.crt"},
+		},
+		"empty result": {
+			mutations: clusterregistryv1alpha1.SecretDataMutations{
+				IncludeKeys: []string{"missing"},
+			},
+			wantEmpty: true,
+		},
+	}
+
+	for name, test := range tests {
+		secret := &corev1.Secret{
+			TypeMeta: v1.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: "v1",
+			},
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "tls",
+				Namespace: "default",
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: map[string][]byte{
+				"ca.crt":  []byte("ca"),
+				"tls.crt": []byte("crt"),
+				"tls.key": []byte("key"),
+			},
+			StringData: map[string]string{
+				"extra": "value",
+			},
+		}
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(secret.DeepCopy())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for kind, obj := range map[string]client.Object{
+			"typed":        secret.DeepCopy(),
+			"unstructured": &unstructured.Unstructured{Object: content},
+		} {
+			err := util.PruneSecretData(obj, test.mutations)
+
+			keys := make([]string, 0)
+			switch o := obj.(type) {
+			case *corev1.Secret:
+				for k := range o.Data {
+					keys = append(keys, k)
+				}
+				for k := range o.StringData {
+					keys = append(keys, k)
+				}
+			case *unstructured.Unstructured:
+				for _, field := range []string{"data", "stringData"} {
+					data, _, _ := unstructured.NestedMap(o.Object, field)
+					for k := range data {
+						keys = append(keys, k)
+					}
+				}
+			}
+
+			if test.wantEmpty {
+				if !errors.Is(err, util.ErrEmptySecretData) {
+					t.Fatalf("%s (%s): expected empty secret data error, got %v", name, kind, err)
+				}
+
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s (%s): %+v", name, kind, err)
+			}
+
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, test.wanted) {
+				t.Fatalf("%s (%s): %v != %v", name, kind, keys, test.wanted)
+			}
+		}
+	}
+}
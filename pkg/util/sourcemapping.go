@@ -0,0 +1,177 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+var ErrSourceMappingViolation = errors.New("synced object does not map back to its source object")
+
+var sourceReferenceAnnotations = []string{
+	clusterregistryv1alpha1.SourceClusterAnnotation,
+	clusterregistryv1alpha1.SourceObjectAnnotation,
+	clusterregistryv1alpha1.SourceRuleAnnotation,
+	clusterregistryv1alpha1.SourceResourceVersionAnnotation,
+}
+
+// SourceReference identifies the source object a synced object was written from
+type SourceReference struct {
+	Cluster         string
+	Object          types.NamespacedName
+	Rule            string
+	ResourceVersion string
+}
+
+// SetSourceReference records the source object in the annotations of the synced object. A nil reference removes the
+// values inherited from an object synced over multiple hops.
+func SetSourceReference(annotations map[string]string, ref *SourceReference) {
+	if ref == nil {
+		for _, key := range sourceReferenceAnnotations {
+			delete(annotations, key)
+		}
+
+		return
+	}
+
+	// cluster scoped objects are referred by their name only
+	object := ref.Object.Name
+	if ref.Object.Namespace != "" {
+		object = ref.Object.String()
+	}
+
+	annotations[clusterregistryv1alpha1.SourceClusterAnnotation] = ref.Cluster
+	annotations[clusterregistryv1alpha1.SourceObjectAnnotation] = object
+	annotations[clusterregistryv1alpha1.SourceRuleAnnotation] = ref.Rule
+	annotations[clusterregistryv1alpha1.SourceResourceVersionAnnotation] = ref.ResourceVersion
+}
+
+// GetSourceResourceVersion returns the resource version of the source object at the time the synced object was written
+func GetSourceResourceVersion(obj client.Object) string {
+	return obj.GetAnnotations()[clusterregistryv1alpha1.SourceResourceVersionAnnotation]
+}
+
+// GetSourceModifiedAt returns the time of the last modification of the source object set by the side pushing it
+func GetSourceModifiedAt(obj client.Object) (time.Time, bool) {
+	value, ok := obj.GetAnnotations()[clusterregistryv1alpha1.SourceModifiedAtAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	modifiedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return modifiedAt, true
+}
+
+// SetSourceGVK records the source GVK in the annotations if it differs from the GVK of the synced object.
+// A value inherited from an object synced over multiple hops is removed otherwise.
+func SetSourceGVK(annotations map[string]string, sourceGVK schema.GroupVersionKind, gvk schema.GroupVersionKind) {
+	if sourceGVK == gvk {
+		delete(annotations, clusterregistryv1alpha1.OriginalGVKAnnotation)
+
+		return
+	}
+
+	annotations[clusterregistryv1alpha1.OriginalGVKAnnotation] = GVKToString(sourceGVK)
+}
+
+// SetSourceObjectKey records the source name and namespace in the labels of the synced object
+// if they differ from the name and namespace of the synced object. Labels inherited from an object
+// synced over multiple hops are removed otherwise.
+func SetSourceObjectKey(obj client.Object, source types.NamespacedName) (nameMutated bool, namespaceMutated bool) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	if source.Name != obj.GetName() {
+		labels[clusterregistryv1alpha1.OriginalNameLabel] = source.Name
+		nameMutated = true
+	} else {
+		delete(labels, clusterregistryv1alpha1.OriginalNameLabel)
+	}
+
+	if source.Namespace != obj.GetNamespace() {
+		labels[clusterregistryv1alpha1.OriginalNamespaceLabel] = source.Namespace
+		namespaceMutated = true
+	} else {
+		delete(labels, clusterregistryv1alpha1.OriginalNamespaceLabel)
+	}
+
+	obj.SetLabels(labels)
+
+	return nameMutated, namespaceMutated
+}
+
+// GetSourceObjectKey returns the name and namespace of the source object the synced object was created from.
+func GetSourceObjectKey(obj client.Object) types.NamespacedName {
+	key := types.NamespacedName{
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+	}
+
+	if name, ok := obj.GetLabels()[clusterregistryv1alpha1.OriginalNameLabel]; ok {
+		key.Name = name
+	}
+
+	if namespace, ok := obj.GetLabels()[clusterregistryv1alpha1.OriginalNamespaceLabel]; ok {
+		key.Namespace = namespace
+	}
+
+	return key
+}
+
+// GetSourceGVK returns the GVK of the source object the synced object was created from.
+func GetSourceGVK(obj client.Object) (schema.GroupVersionKind, error) {
+	value, ok := obj.GetAnnotations()[clusterregistryv1alpha1.OriginalGVKAnnotation]
+	if !ok {
+		return obj.GetObjectKind().GroupVersionKind(), nil
+	}
+
+	gvk := ParseGVKFromString(value)
+	if gvk == nil {
+		return schema.GroupVersionKind{}, errors.NewWithDetails("invalid original gvk annotation", "value", value)
+	}
+
+	return *gvk, nil
+}
+
+// ValidateSourceMapping checks whether the synced object maps back to the given source object.
+func ValidateSourceMapping(obj client.Object, sourceGVK schema.GroupVersionKind, source types.NamespacedName) error {
+	gvk, err := GetSourceGVK(obj)
+	if err != nil {
+		return errors.WrapIfWithDetails(ErrSourceMappingViolation, err.Error(), errors.GetDetails(err)...)
+	}
+
+	if gvk != sourceGVK {
+		return errors.WithDetails(ErrSourceMappingViolation, "expectedGVK", sourceGVK.String(), "actualGVK", gvk.String())
+	}
+
+	if key := GetSourceObjectKey(obj); key != source {
+		return errors.WithDetails(ErrSourceMappingViolation, "expected", source.String(), "actual", key.String())
+	}
+
+	return nil
+}
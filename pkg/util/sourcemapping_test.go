@@ -0,0 +1,243 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+const sourceMappingIterations = 5000
+
+func randomString(rnd *rand.Rand, alphabet string, min, max int) string {
+	var b strings.Builder
+	for i := 0; i < min+rnd.Intn(max-min+1); i++ {
+		b.WriteByte(alphabet[rnd.Intn(len(alphabet))])
+	}
+
+	return b.String()
+}
+
+func randomGVK(rnd *rand.Rand) schema.GroupVersionKind {
+	gvk := schema.GroupVersionKind{
+		Version: randomString(rnd, "v12beta", 1, 8),
+		Kind:    randomString(rnd, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", 1, 16),
+	}
+	// core group, single label group or dotted group
+	switch rnd.Intn(3) {
+	case 1:
+		gvk.Group = randomString(rnd, "abcdefghijklmnopqrstuvwxyz", 1, 10)
+	case 2:
+		gvk.Group = randomString(rnd, "abcdefghijklmnopqrstuvwxyz-.", 1, 30)
+	}
+
+	return gvk
+}
+
+func randomName(rnd *rand.Rand) string {
+	return randomString(rnd, "abcdefghijklmnopqrstuvwxyz0123456789-.", 1, 63)
+}
+
+// mutate mimics the mutation pipeline of the sync reconciler on the metadata level
+func mutate(rnd *rand.Rand, source *unstructured.Unstructured) *unstructured.Unstructured {
+	obj := source.DeepCopy()
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	gvk := source.GroupVersionKind()
+	if rnd.Intn(2) == 0 {
+		gvk = randomGVK(rnd)
+	}
+	util.SetSourceGVK(annotations, source.GroupVersionKind(), gvk)
+	obj.SetGroupVersionKind(gvk)
+	obj.SetAnnotations(annotations)
+
+	if rnd.Intn(2) == 0 {
+		obj.SetName(randomName(rnd))
+	}
+	if source.GetNamespace() != "" && rnd.Intn(2) == 0 {
+		obj.SetNamespace(randomName(rnd))
+	}
+
+	util.SetSourceObjectKey(obj, types.NamespacedName{
+		Name:      source.GetName(),
+		Namespace: source.GetNamespace(),
+	})
+
+	return obj
+}
+
+func TestSourceMappingRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1)) // nolint:gosec
+
+	for i := 0; i < sourceMappingIterations; i++ {
+		source := &unstructured.Unstructured{}
+		source.SetGroupVersionKind(randomGVK(rnd))
+		source.SetName(randomName(rnd))
+		if rnd.Intn(2) == 0 {
+			source.SetNamespace(randomName(rnd))
+		}
+		// objects synced over multiple hops may already carry mapping metadata
+		if rnd.Intn(4) == 0 {
+			source.SetLabels(map[string]string{
+				clusterregistryv1alpha1.OriginalNameLabel:      randomName(rnd),
+				clusterregistryv1alpha1.OriginalNamespaceLabel: randomName(rnd),
+			})
+			source.SetAnnotations(map[string]string{
+				clusterregistryv1alpha1.OriginalGVKAnnotation: util.GVKToString(randomGVK(rnd)),
+			})
+		}
+
+		key := types.NamespacedName{Name: source.GetName(), Namespace: source.GetNamespace()}
+		obj := mutate(rnd, source)
+
+		if err := util.ValidateSourceMapping(obj, source.GroupVersionKind(), key); err != nil {
+			t.Fatalf("iteration %d: %s: %v, source: %v, object: %v", i, err, errors.GetDetails(err), source, obj)
+		}
+	}
+}
+
+func TestSourceMappingViolation(t *testing.T) {
+	t.Parallel()
+
+	gvk := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	key := types.NamespacedName{Name: "test", Namespace: "default"}
+
+	tests := map[string]func(obj *unstructured.Unstructured){
+		"missing name label": func(obj *unstructured.Unstructured) {
+			obj.SetName("renamed")
+		},
+		"missing namespace label": func(obj *unstructured.Unstructured) {
+			obj.SetNamespace("other")
+		},
+		"missing gvk annotation": func(obj *unstructured.Unstructured) {
+			obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"})
+		},
+		"invalid gvk annotation": func(obj *unstructured.Unstructured) {
+			obj.SetAnnotations(map[string]string{
+				clusterregistryv1alpha1.OriginalGVKAnnotation: "invalid",
+			})
+		},
+	}
+
+	for name, modify := range tests {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		obj.SetName(key.Name)
+		obj.SetNamespace(key.Namespace)
+		modify(obj)
+
+		if err := util.ValidateSourceMapping(obj, gvk, key); !errors.Is(err, util.ErrSourceMappingViolation) {
+			t.Fatalf("%s: expected source mapping violation, got %v", name, err)
+		}
+	}
+}
+
+func TestSourceReference(t *testing.T) {
+	t.Parallel()
+
+	annotations := map[string]string{"team": "payments"}
+	util.SetSourceReference(annotations, &util.SourceReference{
+		Cluster:         "source-cluster",
+		Object:          types.NamespacedName{Namespace: "source-ns", Name: "source-name"},
+		Rule:            "sync-configmaps",
+		ResourceVersion: "42",
+	})
+
+	expected := map[string]string{
+		"team": "payments",
+		clusterregistryv1alpha1.SourceClusterAnnotation:         "source-cluster",
+		clusterregistryv1alpha1.SourceObjectAnnotation:          "source-ns/source-name",
+		clusterregistryv1alpha1.SourceRuleAnnotation:            "sync-configmaps",
+		clusterregistryv1alpha1.SourceResourceVersionAnnotation: "42",
+	}
+	if len(annotations) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, annotations)
+	}
+	for k, v := range expected {
+		if annotations[k] != v {
+			t.Fatalf("expected %v, got %v", expected, annotations)
+		}
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAnnotations(annotations)
+	if rv := util.GetSourceResourceVersion(obj); rv != "42" {
+		t.Fatalf("expected source resource version 42, got %q", rv)
+	}
+
+	// cluster scoped objects are referred by their name only
+	util.SetSourceReference(annotations, &util.SourceReference{
+		Cluster: "source-cluster",
+		Object:  types.NamespacedName{Name: "source-name"},
+		Rule:    "sync-clusterroles",
+	})
+	if object := annotations[clusterregistryv1alpha1.SourceObjectAnnotation]; object != "source-name" {
+		t.Fatalf("expected source object source-name, got %q", object)
+	}
+
+	// the references inherited over multiple hops are removed when they are disabled
+	util.SetSourceReference(annotations, nil)
+	if len(annotations) != 1 || annotations["team"] != "payments" {
+		t.Fatalf("expected only the own annotations to be kept, got %v", annotations)
+	}
+}
+
+func TestGetSourceModifiedAt(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value      *string
+		modifiedAt time.Time
+		ok         bool
+	}{
+		"set": {
+			value:      pointer.String("2022-03-01T10:00:00Z"),
+			modifiedAt: time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+			ok:         true,
+		},
+		"missing": {},
+		"invalid": {
+			value: pointer.String("yesterday"),
+		},
+	}
+
+	for name, test := range tests {
+		obj := &unstructured.Unstructured{}
+		if test.value != nil {
+			obj.SetAnnotations(map[string]string{clusterregistryv1alpha1.SourceModifiedAtAnnotation: *test.value})
+		}
+
+		modifiedAt, ok := util.GetSourceModifiedAt(obj)
+		if ok != test.ok || !modifiedAt.Equal(test.modifiedAt) {
+			t.Fatalf("%s: expected %s, %t, got %s, %t", name, test.modifiedAt, test.ok, modifiedAt, ok)
+		}
+	}
+}
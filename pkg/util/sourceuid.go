@@ -0,0 +1,62 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// SetSourceUID records the UID of the source object in the annotations of the synced object, an empty UID removes the
+// value inherited from an object synced over multiple hops
+func SetSourceUID(annotations map[string]string, uid types.UID) {
+	if uid == "" {
+		delete(annotations, clusterregistryv1alpha1.SourceUIDAnnotation)
+
+		return
+	}
+
+	annotations[clusterregistryv1alpha1.SourceUIDAnnotation] = string(uid)
+}
+
+// GetSourceUID returns the UID of the source object the synced object was written from
+func GetSourceUID(obj client.Object) types.UID {
+	return types.UID(obj.GetAnnotations()[clusterregistryv1alpha1.SourceUIDAnnotation])
+}
+
+// IsSyncedFrom returns whether the synced object was written from the instance of the source object with the UID. The
+// objects written before the UID was recorded and the unknown UIDs match every instance.
+func IsSyncedFrom(obj client.Object, uid types.UID) bool {
+	recorded := GetSourceUID(obj)
+
+	return uid == "" || recorded == "" || recorded == uid
+}
+
+// GetSourceObject reads the source object from the cache, and directly from the API server if it is not in the cache,
+// so a source object deleted and recreated right away is not taken as deleted while the cache is behind. The direct
+// reader is optional.
+func GetSourceObject(ctx context.Context, cached client.Reader, direct client.Reader, key client.ObjectKey, obj client.Object) error {
+	err := cached.Get(ctx, key, obj)
+	if !apierrors.IsNotFound(err) || direct == nil {
+		return err
+	}
+
+	return direct.Get(ctx, key, obj)
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func newSourceInstance(uid types.UID) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "config",
+			Namespace: "default",
+			UID:       uid,
+		},
+	}
+}
+
+func newSyncedFrom(uid types.UID) *corev1.ConfigMap {
+	cm := newSourceInstance("local")
+	if uid != "" {
+		cm.SetAnnotations(map[string]string{
+			clusterregistryv1alpha1.SourceUIDAnnotation: string(uid),
+		})
+	}
+
+	return cm
+}
+
+// applyEvents applies the events to a fake client, the cache of the source cluster is given the events which reached
+// it so far, so it can lag behind the API server
+func applyEvents(t *testing.T, events []func(c client.Client) error) client.Client {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	for _, event := range events {
+		if err := event(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return c
+}
+
+func TestGetSourceObjectRecreated(t *testing.T) {
+	t.Parallel()
+
+	create := func(uid types.UID) func(c client.Client) error {
+		return func(c client.Client) error {
+			return c.Create(context.Background(), newSourceInstance(uid))
+		}
+	}
+	deleteSource := func(c client.Client) error {
+		return c.Delete(context.Background(), newSourceInstance(""))
+	}
+
+	// the source object is deleted and recreated right away, the cache has seen the deletion, but not the creation yet
+	events := []func(c client.Client) error{create("old"), deleteSource, create("new")}
+	cached := applyEvents(t, events[:2])
+	direct := applyEvents(t, events)
+
+	key := client.ObjectKeyFromObject(newSourceInstance(""))
+
+	obj := &corev1.ConfigMap{}
+	if err := util.GetSourceObject(context.Background(), cached, nil, key, obj); !apierrors.IsNotFound(err) {
+		t.Fatalf("source object is expected to be missing from the cache: %v", err)
+	}
+
+	obj = &corev1.ConfigMap{}
+	if err := util.GetSourceObject(context.Background(), cached, direct, key, obj); err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if obj.GetUID() != "new" {
+		t.Fatalf("unexpected source object %s", obj.GetUID())
+	}
+
+	// the object synced from the recreated source is not deleted when the deletion of the previous one is handled late
+	if util.IsSyncedFrom(newSyncedFrom("new"), "old") {
+		t.Fatal("object synced from the recreated source object matches the deleted one")
+	}
+
+	// the source object is gone for good
+	direct = applyEvents(t, events[:2])
+	if err := util.GetSourceObject(context.Background(), cached, direct, key, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("source object is expected to be gone: %v", err)
+	}
+}
+
+func TestIsSyncedFrom(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		obj    client.Object
+		uid    types.UID
+		wanted bool
+	}{
+		"same instance": {
+			obj:    newSyncedFrom("old"),
+			uid:    "old",
+			wanted: true,
+		},
+		"recreated instance": {
+			obj:    newSyncedFrom("new"),
+			uid:    "old",
+			wanted: false,
+		},
+		"uid not recorded": {
+			obj:    newSyncedFrom(""),
+			uid:    "old",
+			wanted: true,
+		},
+		"uid of deleted instance unknown": {
+			obj:    newSyncedFrom("new"),
+			wanted: true,
+		},
+	}
+
+	for name, test := range tests {
+		if synced := util.IsSyncedFrom(test.obj, test.uid); synced != test.wanted {
+			t.Fatalf("%s: unexpected result %t", name, synced)
+		}
+	}
+}
+
+func TestSetSourceUID(t *testing.T) {
+	t.Parallel()
+
+	annotations := map[string]string{}
+	util.SetSourceUID(annotations, "uid")
+
+	obj := newSourceInstance("local")
+	obj.SetAnnotations(annotations)
+	if uid := util.GetSourceUID(obj); uid != "uid" {
+		t.Fatalf("unexpected source uid %s", uid)
+	}
+
+	util.SetSourceUID(annotations, "")
+	if _, ok := annotations[clusterregistryv1alpha1.SourceUIDAnnotation]; ok {
+		t.Fatal("source uid was not removed")
+	}
+}
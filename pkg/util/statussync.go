@@ -0,0 +1,177 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// SetSyncedStatus replaces the status of the desired object, which is the status of the source object, with the
+// status to be written onto the current local object according to the status sync. Objects of any kind are handled
+// through their unstructured content, so the conditions are merged by their type field.
+func SetSyncedStatus(desired client.Object, current client.Object, statusSync *clusterregistryv1alpha1.StatusSync) error {
+	if statusSync == nil || statusSync.GetStrategy() == clusterregistryv1alpha1.StatusSyncStrategyReplace {
+		return nil
+	}
+
+	desiredContent, err := toUnstructuredContent(desired)
+	if err != nil {
+		return errors.WrapIf(err, "could not convert desired object")
+	}
+
+	currentContent, err := toUnstructuredContent(current)
+	if err != nil {
+		return errors.WrapIf(err, "could not convert current object")
+	}
+
+	sourceStatus, _, err := unstructured.NestedMap(desiredContent, "status")
+	if err != nil {
+		return errors.WrapIf(err, "could not get source status")
+	}
+	localStatus, _, err := unstructured.NestedMap(currentContent, "status")
+	if err != nil {
+		return errors.WrapIf(err, "could not get local status")
+	}
+
+	var status map[string]interface{}
+	switch statusSync.GetStrategy() {
+	case clusterregistryv1alpha1.StatusSyncStrategyMergeConditions:
+		status = sourceStatus
+		if status == nil {
+			status = make(map[string]interface{})
+		}
+		if conditions := mergeConditions(localStatus["conditions"], sourceStatus["conditions"]); len(conditions) > 0 {
+			status["conditions"] = conditions
+		}
+	case clusterregistryv1alpha1.StatusSyncStrategyFields:
+		status = localStatus
+		if status == nil {
+			status = make(map[string]interface{})
+		}
+		for _, field := range statusSync.Fields {
+			path := strings.Split(strings.TrimPrefix(field, ".status."), ".")
+			value, found, err := unstructured.NestedFieldCopy(sourceStatus, path...)
+			if err != nil {
+				return errors.WrapIfWithDetails(err, "could not get status field", "field", field)
+			}
+			if !found {
+				unstructured.RemoveNestedField(status, path...)
+
+				continue
+			}
+			if err := unstructured.SetNestedField(status, value, path...); err != nil {
+				return errors.WrapIfWithDetails(err, "could not set status field", "field", field)
+			}
+		}
+	default:
+		return errors.Errorf("unknown status sync strategy %q", statusSync.Strategy)
+	}
+
+	desiredContent["status"] = status
+
+	return fromUnstructuredContent(desiredContent, desired)
+}
+
+// mergeConditions merges the condition lists by the type of the conditions, the condition with the newest
+// lastTransitionTime wins and the source one on a tie. Conditions only present locally are kept in their order, the
+// ones only present on the source are appended.
+func mergeConditions(local interface{}, source interface{}) []interface{} {
+	localConditions, _ := local.([]interface{})
+	sourceConditions, _ := source.([]interface{})
+
+	sourceByType := make(map[string]interface{}, len(sourceConditions))
+	for _, condition := range sourceConditions {
+		if conditionType := getConditionType(condition); conditionType != "" {
+			sourceByType[conditionType] = condition
+		}
+	}
+
+	merged := make([]interface{}, 0, len(localConditions)+len(sourceConditions))
+	localTypes := make(map[string]struct{}, len(localConditions))
+	for _, condition := range localConditions {
+		conditionType := getConditionType(condition)
+		localTypes[conditionType] = struct{}{}
+
+		if sourceCondition, ok := sourceByType[conditionType]; ok && !getTransitionTime(condition).After(getTransitionTime(sourceCondition)) {
+			condition = sourceCondition
+		}
+		merged = append(merged, condition)
+	}
+
+	for _, condition := range sourceConditions {
+		conditionType := getConditionType(condition)
+		if _, ok := localTypes[conditionType]; ok && conditionType != "" {
+			continue
+		}
+		merged = append(merged, condition)
+	}
+
+	return merged
+}
+
+func getConditionType(condition interface{}) string {
+	if c, ok := condition.(map[string]interface{}); ok {
+		if conditionType, ok := c["type"].(string); ok {
+			return conditionType
+		}
+	}
+
+	return ""
+}
+
+// getTransitionTime returns the lastTransitionTime of the condition, or the zero time if it is not set or invalid
+func getTransitionTime(condition interface{}) time.Time {
+	if c, ok := condition.(map[string]interface{}); ok {
+		if value, ok := c["lastTransitionTime"].(string); ok {
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				return t
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+// fromUnstructuredContent writes the content onto the object, typed objects are decoded into a new value, so the
+// fields missing from the content are cleared
+func fromUnstructuredContent(content map[string]interface{}, obj client.Object) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.SetUnstructuredContent(content)
+
+		return nil
+	}
+
+	value := reflect.ValueOf(obj)
+	if value.Kind() != reflect.Ptr {
+		return errors.Errorf("invalid object %T", obj)
+	}
+
+	decoded := reflect.New(value.Elem().Type())
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, decoded.Interface()); err != nil {
+		return errors.WrapIf(err, "could not convert object")
+	}
+	value.Elem().Set(decoded.Elem())
+
+	return nil
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func newStatusWidget(status map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata": map[string]interface{}{
+			"name":      "widget",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"size": int64(1),
+		},
+		"status": status,
+	}}
+}
+
+func newCondition(conditionType string, status string, lastTransitionTime string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":               conditionType,
+		"status":             status,
+		"lastTransitionTime": lastTransitionTime,
+	}
+}
+
+func TestSetSyncedStatus(t *testing.T) {
+	t.Parallel()
+
+	// the status of the source object, as it is on the desired object
+	sourceStatus := func() map[string]interface{} {
+		return map[string]interface{}{
+			"phase":    "Ready",
+			"replicas": int64(3),
+			"conditions": []interface{}{
+				newCondition("Ready", "True", "2022-01-02T00:00:00Z"),
+				newCondition("Synced", "True", "2022-01-02T00:00:00Z"),
+			},
+		}
+	}
+	// the status of the local object, which has a condition maintained by a local controller
+	localStatus := func() map[string]interface{} {
+		return map[string]interface{}{
+			"phase":         "Pending",
+			"observedLocal": true,
+			"conditions": []interface{}{
+				newCondition("Ready", "False", "2022-01-01T00:00:00Z"),
+				newCondition("LocalReady", "True", "2022-01-01T00:00:00Z"),
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		statusSync *clusterregistryv1alpha1.StatusSync
+		local      map[string]interface{}
+		expected   map[string]interface{}
+	}{
+		{
+			name:       "no status sync replaces the status",
+			statusSync: nil,
+			local:      localStatus(),
+			expected:   sourceStatus(),
+		},
+		{
+			name: "replace",
+			statusSync: &clusterregistryv1alpha1.StatusSync{
+				Strategy: clusterregistryv1alpha1.StatusSyncStrategyReplace,
+			},
+			local:    localStatus(),
+			expected: sourceStatus(),
+		},
+		{
+			name: "merge conditions keeps the local conditions",
+			statusSync: &clusterregistryv1alpha1.StatusSync{
+				Strategy: clusterregistryv1alpha1.StatusSyncStrategyMergeConditions,
+			},
+			local: localStatus(),
+			expected: map[string]interface{}{
+				"phase":    "Ready",
+				"replicas": int64(3),
+				"conditions": []interface{}{
+					newCondition("Ready", "True", "2022-01-02T00:00:00Z"),
+					newCondition("LocalReady", "True", "2022-01-01T00:00:00Z"),
+					newCondition("Synced", "True", "2022-01-02T00:00:00Z"),
+				},
+			},
+		},
+		{
+			name: "merge conditions keeps the newer local condition",
+			statusSync: &clusterregistryv1alpha1.StatusSync{
+				Strategy: clusterregistryv1alpha1.StatusSyncStrategyMergeConditions,
+			},
+			local: map[string]interface{}{
+				"conditions": []interface{}{
+					newCondition("Ready", "False", "2022-01-03T00:00:00Z"),
+				},
+			},
+			expected: map[string]interface{}{
+				"phase":    "Ready",
+				"replicas": int64(3),
+				"conditions": []interface{}{
+					newCondition("Ready", "False", "2022-01-03T00:00:00Z"),
+					newCondition("Synced", "True", "2022-01-02T00:00:00Z"),
+				},
+			},
+		},
+		{
+			name: "merge conditions without local status",
+			statusSync: &clusterregistryv1alpha1.StatusSync{
+				Strategy: clusterregistryv1alpha1.StatusSyncStrategyMergeConditions,
+			},
+			local:    nil,
+			expected: sourceStatus(),
+		},
+		{
+			name: "fields copies only the listed fields",
+			statusSync: &clusterregistryv1alpha1.StatusSync{
+				Strategy: clusterregistryv1alpha1.StatusSyncStrategyFields,
+				Fields:   []string{".status.phase", ".status.replicas", ".status.observedLocal"},
+			},
+			local: localStatus(),
+			expected: map[string]interface{}{
+				"phase":    "Ready",
+				"replicas": int64(3),
+				"conditions": []interface{}{
+					newCondition("Ready", "False", "2022-01-01T00:00:00Z"),
+					newCondition("LocalReady", "True", "2022-01-01T00:00:00Z"),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			desired := newStatusWidget(sourceStatus())
+			current := newStatusWidget(tt.local)
+			if tt.local == nil {
+				unstructured.RemoveNestedField(current.Object, "status")
+			}
+
+			if err := util.SetSyncedStatus(desired, current, tt.statusSync); err != nil {
+				t.Fatal(err)
+			}
+
+			status, _, _ := unstructured.NestedMap(desired.Object, "status")
+			if !reflect.DeepEqual(status, tt.expected) {
+				t.Fatalf("expected status %v, got %v", tt.expected, status)
+			}
+			if size, _, _ := unstructured.NestedInt64(desired.Object, "spec", "size"); size != 1 {
+				t.Fatalf("expected the spec to be kept, got size %d", size)
+			}
+		})
+	}
+}
+
+func TestSetSyncedStatusTypedObject(t *testing.T) {
+	t.Parallel()
+
+	desired := &corev1.Service{
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeLoadBalancer,
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}},
+			},
+			Conditions: []metav1.Condition{
+				{Type: "Source", Status: metav1.ConditionTrue},
+			},
+		},
+	}
+	local := []metav1.Condition{
+		{Type: "Local", Status: metav1.ConditionTrue},
+	}
+	current := &corev1.Service{
+		Status: corev1.ServiceStatus{
+			Conditions: local,
+		},
+	}
+
+	err := util.SetSyncedStatus(desired, current, &clusterregistryv1alpha1.StatusSync{
+		Strategy: clusterregistryv1alpha1.StatusSyncStrategyFields,
+		Fields:   []string{".status.loadBalancer"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if desired.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		t.Fatalf("expected the spec to be kept, got type %s", desired.Spec.Type)
+	}
+	if len(desired.Status.LoadBalancer.Ingress) != 1 || desired.Status.LoadBalancer.Ingress[0].IP != "10.0.0.1" {
+		t.Fatalf("expected the load balancer status to be synced, got %v", desired.Status.LoadBalancer)
+	}
+	if !reflect.DeepEqual(desired.Status.Conditions, local) {
+		t.Fatalf("expected the local conditions %v, got %v", local, desired.Status.Conditions)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// DefaultMaxSyncHops is the number of clusters an object can be synced through from the cluster it originates from
+const DefaultMaxSyncHops = 5
+
+var ErrSyncLoop = errors.New("sync loop detected")
+
+// SyncOrigin is the cluster a synced object originates from and the number of syncs it took to reach its cluster
+type SyncOrigin struct {
+	ClusterID string
+	Hops      int
+}
+
+func (o SyncOrigin) String() string {
+	return fmt.Sprintf("%s/%d", o.ClusterID, o.Hops)
+}
+
+// GetSyncOrigin returns the origin of a synced object, false is returned for objects which were not synced or whose
+// origin can not be parsed
+func GetSyncOrigin(obj client.Object) (SyncOrigin, bool) {
+	value, ok := obj.GetAnnotations()[clusterregistryv1alpha1.SyncOriginAnnotation]
+	if !ok {
+		return SyncOrigin{}, false
+	}
+
+	i := strings.LastIndex(value, "/")
+	if i <= 0 {
+		return SyncOrigin{}, false
+	}
+
+	hops, err := strconv.Atoi(value[i+1:])
+	if err != nil || hops < 1 {
+		return SyncOrigin{}, false
+	}
+
+	return SyncOrigin{
+		ClusterID: value[:i],
+		Hops:      hops,
+	}, true
+}
+
+// SetSyncOrigin sets the origin of the object synced from the source object of the source cluster. An object which was
+// not synced originates from the source cluster, while the origin of a synced one is kept with one more hop.
+func SetSyncOrigin(annotations map[string]string, source client.Object, sourceClusterID string) {
+	origin, ok := GetSyncOrigin(source)
+	if !ok {
+		origin = SyncOrigin{
+			ClusterID: sourceClusterID,
+		}
+	}
+	origin.Hops++
+
+	annotations[clusterregistryv1alpha1.SyncOriginAnnotation] = origin.String()
+}
+
+// CheckSyncLoop returns an error if syncing the source object into the local cluster would close a sync loop, i.e. the
+// object originates from the local cluster, or it was already synced through the maximum number of clusters. A
+// maximum of 0 disables the limit of the hops.
+func CheckSyncLoop(source client.Object, localClusterID string, maxHops int) error {
+	origin, ok := GetSyncOrigin(source)
+	if !ok {
+		return nil
+	}
+
+	if origin.ClusterID == localClusterID {
+		return errors.WithDetails(errors.WrapIf(ErrSyncLoop, "object originates from the local cluster"), "origin", origin.String())
+	}
+
+	if maxHops > 0 && origin.Hops >= maxHops {
+		return errors.WithDetails(errors.WrapIff(ErrSyncLoop, "object was synced through %d clusters already", origin.Hops), "origin", origin.String())
+	}
+
+	return nil
+}
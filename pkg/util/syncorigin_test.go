@@ -0,0 +1,175 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func newOriginObject(name string, origin string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetName(name)
+	if origin != "" {
+		obj.SetAnnotations(map[string]string{clusterregistryv1alpha1.SyncOriginAnnotation: origin})
+	}
+
+	return obj
+}
+
+func TestGetSyncOrigin(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value  string
+		origin util.SyncOrigin
+		ok     bool
+	}{
+		"not synced":      {},
+		"valid":           {value: "cluster-a/2", origin: util.SyncOrigin{ClusterID: "cluster-a", Hops: 2}, ok: true},
+		"slash in the id": {value: "a/b/1", origin: util.SyncOrigin{ClusterID: "a/b", Hops: 1}, ok: true},
+		"missing hops":    {value: "cluster-a"},
+		"missing cluster": {value: "/1"},
+		"invalid hops":    {value: "cluster-a/x"},
+		"zero hops":       {value: "cluster-a/0"},
+		"negative hops":   {value: "cluster-a/-1"},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			origin, ok := util.GetSyncOrigin(newOriginObject("test", test.value))
+			if ok != test.ok || origin != test.origin {
+				t.Fatalf("expected %v (%t), got %v (%t)", test.origin, test.ok, origin, ok)
+			}
+		})
+	}
+}
+
+func TestSetSyncOrigin(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		source   string
+		expected string
+	}{
+		"not synced":     {expected: "cluster-a/1"},
+		"synced":         {source: "cluster-c/2", expected: "cluster-c/3"},
+		"invalid origin": {source: "cluster-c", expected: "cluster-a/1"},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			annotations := map[string]string{}
+			util.SetSyncOrigin(annotations, newOriginObject("test", test.source), "cluster-a")
+			if v := annotations[clusterregistryv1alpha1.SyncOriginAnnotation]; v != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, v)
+			}
+		})
+	}
+}
+
+func TestCheckSyncLoop(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		origin  string
+		maxHops int
+		loop    bool
+	}{
+		"not synced":             {maxHops: 5},
+		"other origin":           {origin: "cluster-b/1", maxHops: 5},
+		"local origin":           {origin: "cluster-a/1", maxHops: 5, loop: true},
+		"local origin, no limit": {origin: "cluster-a/3", loop: true},
+		"below the hop limit":    {origin: "cluster-b/4", maxHops: 5},
+		"at the hop limit":       {origin: "cluster-b/5", maxHops: 5, loop: true},
+		"hop limit disabled":     {origin: "cluster-b/50"},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := util.CheckSyncLoop(newOriginObject("test", test.origin), "cluster-a", test.maxHops)
+			if loop := errors.Is(err, util.ErrSyncLoop); loop != test.loop {
+				t.Fatalf("expected loop %t, got %v", test.loop, err)
+			}
+		})
+	}
+}
+
+// TestSyncLoopTerminates simulates rules syncing objects between clusters in a ring, renaming them on every sync, so
+// the synced objects never match the objects they were synced from, and checks that the syncing stops
+func TestSyncLoopTerminates(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		clusters []string
+		maxHops  int
+		syncs    int
+	}{
+		"two clusters":                 {clusters: []string{"cluster-a", "cluster-b"}, maxHops: 5, syncs: 1},
+		"three clusters":               {clusters: []string{"cluster-a", "cluster-b", "cluster-c"}, maxHops: 5, syncs: 2},
+		"three clusters, no hop limit": {clusters: []string{"cluster-a", "cluster-b", "cluster-c"}, syncs: 2},
+		"ring above the hop limit":     {clusters: []string{"a", "b", "c", "d", "e", "f", "g"}, maxHops: 3, syncs: 3},
+	}
+
+	for name, test := range tests {
+		name, test := name, test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// the objects created by the last syncs, which are synced further by the next rule of the ring
+			created := []*unstructured.Unstructured{newOriginObject("test", "")}
+
+			syncs := 0
+			for i := 0; len(created) > 0 && i < 100; i++ {
+				source := test.clusters[i%len(test.clusters)]
+				target := test.clusters[(i+1)%len(test.clusters)]
+
+				next := []*unstructured.Unstructured{}
+				for _, obj := range created {
+					if util.CheckSyncLoop(obj, target, test.maxHops) != nil {
+						continue
+					}
+
+					annotations := map[string]string{}
+					util.SetSyncOrigin(annotations, obj, source)
+					synced := newOriginObject(obj.GetName()+"-"+target, "")
+					synced.SetAnnotations(annotations)
+
+					next = append(next, synced)
+					syncs++
+				}
+				created = next
+			}
+
+			if syncs != test.syncs {
+				t.Fatalf("expected %d syncs, got %d", test.syncs, syncs)
+			}
+		})
+	}
+}
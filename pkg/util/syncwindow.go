@@ -0,0 +1,84 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/robfig/cron/v3"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+// SyncWindow evaluates the schedule of the sync windows of a rule. The windows start at the wall clock times of the
+// schedule in its time zone and last for the duration in elapsed time, so a window spanning a DST transition is an hour
+// shorter or longer on the wall clock.
+type SyncWindow struct {
+	schedule cron.Schedule
+	duration time.Duration
+	location *time.Location
+
+	deferDeletions bool
+}
+
+// NewSyncWindow parses the schedule of the sync window
+func NewSyncWindow(window clusterregistryv1alpha1.SyncWindow) (*SyncWindow, error) {
+	if err := window.Validate(); err != nil {
+		return nil, errors.WithStackIf(err)
+	}
+
+	schedule, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "invalid schedule", "schedule", window.Schedule)
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, errors.NewWithDetails("schedule never opens a window", "schedule", window.Schedule)
+	}
+
+	location, err := time.LoadLocation(window.TimeZone)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "invalid time zone", "timeZone", window.TimeZone)
+	}
+
+	return &SyncWindow{
+		schedule: schedule,
+		duration: window.Duration.Duration,
+		location: location,
+
+		deferDeletions: window.Deletions != clusterregistryv1alpha1.SyncWindowDeletionPolicyImmediate,
+	}, nil
+}
+
+// IsOpen returns whether the time is within a window, and the start of the next window if it is not
+func (w *SyncWindow) IsOpen(t time.Time) (bool, time.Time) {
+	// the first start after the beginning of the window which would still be open at the time
+	start := w.schedule.Next(t.Add(-w.duration).In(w.location))
+	if start.IsZero() {
+		// the schedule never starts a window
+		return false, start
+	}
+
+	if !start.After(t) {
+		return true, time.Time{}
+	}
+
+	return false, start
+}
+
+// DefersDeletions returns whether the deletions of the synced objects wait for the windows too
+func (w *SyncWindow) DefersDeletions() bool {
+	return w.deferDeletions
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestSyncWindow(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(year int, month time.Month, day, hour, min int, loc *time.Location) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, loc)
+	}
+
+	type check struct {
+		at         time.Time
+		open       bool
+		nextWindow time.Time
+	}
+
+	tests := map[string]struct {
+		window clusterregistryv1alpha1.SyncWindow
+		checks []check
+	}{
+		"window in utc": {
+			window: clusterregistryv1alpha1.SyncWindow{
+				Schedule: "0 22 * * 6",
+				Duration: v1.Duration{Duration: 2 * time.Hour},
+			},
+			checks: []check{
+				// Friday
+				{at: at(2021, time.June, 4, 23, 0, time.UTC), nextWindow: at(2021, time.June, 5, 22, 0, time.UTC)},
+				// Saturday
+				{at: at(2021, time.June, 5, 22, 0, time.UTC), open: true},
+				{at: at(2021, time.June, 5, 23, 59, time.UTC), open: true},
+				{at: at(2021, time.June, 6, 0, 0, time.UTC), nextWindow: at(2021, time.June, 12, 22, 0, time.UTC)},
+			},
+		},
+		"window spanning midnight": {
+			window: clusterregistryv1alpha1.SyncWindow{
+				Schedule: "0 22 * * *",
+				Duration: v1.Duration{Duration: 4 * time.Hour},
+				TimeZone: "Europe/Berlin",
+			},
+			checks: []check{
+				{at: at(2021, time.June, 4, 21, 59, berlin), nextWindow: at(2021, time.June, 4, 22, 0, berlin)},
+				{at: at(2021, time.June, 4, 23, 30, berlin), open: true},
+				{at: at(2021, time.June, 5, 1, 59, berlin), open: true},
+				{at: at(2021, time.June, 5, 2, 0, berlin), nextWindow: at(2021, time.June, 5, 22, 0, berlin)},
+			},
+		},
+		"time zone of the schedule": {
+			window: clusterregistryv1alpha1.SyncWindow{
+				Schedule: "0 22 * * *",
+				Duration: v1.Duration{Duration: time.Hour},
+				TimeZone: "Europe/Berlin",
+			},
+			checks: []check{
+				// 22:00 in UTC is midnight in Berlin in summer
+				{at: at(2021, time.June, 4, 22, 0, time.UTC), nextWindow: at(2021, time.June, 5, 20, 0, time.UTC)},
+				{at: at(2021, time.June, 4, 20, 30, time.UTC), open: true},
+			},
+		},
+		"window spanning the end of dst": {
+			// the clocks are turned back from 03:00 CEST to 02:00 CET on 31 October 2021
+			window: clusterregistryv1alpha1.SyncWindow{
+				Schedule: "0 1 * * *",
+				Duration: v1.Duration{Duration: 3 * time.Hour},
+				TimeZone: "Europe/Berlin",
+			},
+			checks: []check{
+				{at: at(2021, time.October, 31, 0, 59, berlin), nextWindow: at(2021, time.October, 31, 1, 0, berlin)},
+				// the window lasts 3 hours of elapsed time, which ends at 03:00 CET on the wall clock
+				{at: at(2021, time.October, 31, 2, 30, berlin), open: true},
+				{at: at(2021, time.October, 31, 2, 59, berlin), open: true},
+				{at: at(2021, time.October, 31, 3, 0, berlin), nextWindow: at(2021, time.November, 1, 1, 0, berlin)},
+			},
+		},
+		"window spanning the start of dst": {
+			// the clocks are turned forward from 02:00 CET to 03:00 CEST on 28 March 2021
+			window: clusterregistryv1alpha1.SyncWindow{
+				Schedule: "0 1 * * *",
+				Duration: v1.Duration{Duration: 3 * time.Hour},
+				TimeZone: "Europe/Berlin",
+			},
+			checks: []check{
+				// the window lasts 3 hours of elapsed time, which ends at 05:00 CEST on the wall clock
+				{at: at(2021, time.March, 28, 4, 30, berlin), open: true},
+				{at: at(2021, time.March, 28, 5, 0, berlin), nextWindow: at(2021, time.March, 29, 1, 0, berlin)},
+			},
+		},
+		"window starting in the hour skipped by dst": {
+			// there is no 02:30 on 28 March 2021 in Berlin, the window of the day is skipped
+			window: clusterregistryv1alpha1.SyncWindow{
+				Schedule: "30 2 * * *",
+				Duration: v1.Duration{Duration: time.Hour},
+				TimeZone: "Europe/Berlin",
+			},
+			checks: []check{
+				{at: at(2021, time.March, 28, 1, 0, berlin), nextWindow: at(2021, time.March, 29, 2, 30, berlin)},
+				{at: at(2021, time.March, 28, 3, 30, berlin), nextWindow: at(2021, time.March, 29, 2, 30, berlin)},
+			},
+		},
+		"window starting in the hour repeated by dst": {
+			// 02:30 is passed twice on 31 October 2021 in Berlin, a window opens both times
+			window: clusterregistryv1alpha1.SyncWindow{
+				Schedule: "30 2 * * *",
+				Duration: v1.Duration{Duration: 30 * time.Minute},
+				TimeZone: "Europe/Berlin",
+			},
+			checks: []check{
+				{at: at(2021, time.October, 31, 0, 45, time.UTC), open: true},
+				{at: at(2021, time.October, 31, 1, 15, time.UTC), nextWindow: at(2021, time.October, 31, 1, 30, time.UTC)},
+				{at: at(2021, time.October, 31, 1, 45, time.UTC), open: true},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		window, err := util.NewSyncWindow(test.window)
+		if err != nil {
+			t.Fatalf("%s: %+v", name, err)
+		}
+
+		for _, check := range test.checks {
+			open, next := window.IsOpen(check.at)
+			if open != check.open {
+				t.Fatalf("%s: window at %s is expected to be open: %t", name, check.at, check.open)
+			}
+			if !next.Equal(check.nextWindow) {
+				t.Fatalf("%s: unexpected next window at %s: %s instead of %s", name, check.at, next, check.nextWindow)
+			}
+		}
+	}
+}
+
+func TestSyncWindowDeletions(t *testing.T) {
+	t.Parallel()
+
+	tests := map[clusterregistryv1alpha1.SyncWindowDeletionPolicy]bool{
+		"": true,
+		clusterregistryv1alpha1.SyncWindowDeletionPolicyDefer:     true,
+		clusterregistryv1alpha1.SyncWindowDeletionPolicyImmediate: false,
+	}
+
+	for policy, deferred := range tests {
+		window, err := util.NewSyncWindow(clusterregistryv1alpha1.SyncWindow{
+			Schedule:  "@daily",
+			Duration:  v1.Duration{Duration: time.Hour},
+			Deletions: policy,
+		})
+		if err != nil {
+			t.Fatalf("%q: %+v", policy, err)
+		}
+		if window.DefersDeletions() != deferred {
+			t.Fatalf("%q: deletions are expected to be deferred: %t", policy, deferred)
+		}
+	}
+}
+
+func TestInvalidSyncWindow(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]clusterregistryv1alpha1.SyncWindow{
+		"invalid schedule": {
+			Schedule: "0 25 * * *",
+			Duration: v1.Duration{Duration: time.Hour},
+		},
+		"schedule never opening a window": {
+			Schedule: "0 0 30 2 *",
+			Duration: v1.Duration{Duration: time.Hour},
+		},
+		"missing duration": {
+			Schedule: "@daily",
+		},
+		"unknown time zone": {
+			Schedule: "@daily",
+			Duration: v1.Duration{Duration: time.Hour},
+			TimeZone: "Mars/Olympus_Mons",
+		},
+	}
+
+	for name, window := range tests {
+		if _, err := util.NewSyncWindow(window); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Takeover is an object updated by a cluster while its owner cluster is not alive
+type Takeover struct {
+	Key            types.NamespacedName
+	OwnerClusterID string
+	Since          time.Time
+}
+
+// TakeoverTracker tracks the objects taken over from their owner clusters, so the hand-back can be detected
+type TakeoverTracker struct {
+	objects map[types.NamespacedName]Takeover
+	mu      sync.Mutex
+}
+
+func NewTakeoverTracker() *TakeoverTracker {
+	return &TakeoverTracker{
+		objects: make(map[types.NamespacedName]Takeover),
+	}
+}
+
+// TakeOver records the takeover of the object and returns whether it was not taken over from the same owner before
+func (t *TakeoverTracker) TakeOver(key types.NamespacedName, ownerClusterID string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if current, ok := t.objects[key]; ok && current.OwnerClusterID == ownerClusterID {
+		return false
+	}
+
+	t.objects[key] = Takeover{
+		Key:            key,
+		OwnerClusterID: ownerClusterID,
+		Since:          now,
+	}
+
+	return true
+}
+
+// HandBack forgets the takeover of the object and returns it, if the object was taken over
+func (t *TakeoverTracker) HandBack(key types.NamespacedName) (Takeover, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	takeover, ok := t.objects[key]
+	delete(t.objects, key)
+
+	return takeover, ok
+}
+
+// List returns the taken over objects ordered by their keys
+func (t *TakeoverTracker) List() []Takeover {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	takeovers := make([]Takeover, 0, len(t.objects))
+	for _, takeover := range t.objects {
+		takeovers = append(takeovers, takeover)
+	}
+	sort.Slice(takeovers, func(i, j int) bool {
+		return takeovers[i].Key.String() < takeovers[j].Key.String()
+	})
+
+	return takeovers
+}
+
+// Reset forgets every takeover
+func (t *TakeoverTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.objects = make(map[types.NamespacedName]Takeover)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestTakeoverTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker := util.NewTakeoverTracker()
+	now := time.Now()
+
+	first := types.NamespacedName{Namespace: "default", Name: "b"}
+	second := types.NamespacedName{Namespace: "default", Name: "a"}
+
+	if !tracker.TakeOver(first, "dead-cluster", now) {
+		t.Fatalf("first takeover is not reported")
+	}
+	if tracker.TakeOver(first, "dead-cluster", now.Add(time.Minute)) {
+		t.Fatalf("repeated takeover is reported")
+	}
+	if !tracker.TakeOver(second, "dead-cluster", now) {
+		t.Fatalf("takeover of another object is not reported")
+	}
+
+	takeovers := tracker.List()
+	if len(takeovers) != 2 || takeovers[0].Key != second || takeovers[1].Key != first {
+		t.Fatalf("unexpected takeovers: %v", takeovers)
+	}
+	if !takeovers[1].Since.Equal(now) {
+		t.Fatalf("repeated takeover changed the takeover time: %v", takeovers[1].Since)
+	}
+
+	if !tracker.TakeOver(first, "other-dead-cluster", now) {
+		t.Fatalf("takeover from another owner is not reported")
+	}
+
+	takeover, ok := tracker.HandBack(first)
+	if !ok || takeover.OwnerClusterID != "other-dead-cluster" {
+		t.Fatalf("unexpected hand-back: %v, %t", takeover, ok)
+	}
+	if _, ok := tracker.HandBack(first); ok {
+		t.Fatalf("object is handed back twice")
+	}
+
+	tracker.Reset()
+	if len(tracker.List()) != 0 {
+		t.Fatalf("takeovers exist after reset: %v", tracker.List())
+	}
+}
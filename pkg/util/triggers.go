@@ -0,0 +1,135 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Trigger is a request to reconcile an object of a kind, or every object if All is set
+type Trigger struct {
+	GVK schema.GroupVersionKind
+	Key types.NamespacedName
+	// All requests a full resync, every object is reconciled
+	All bool
+	// ListFromCache is whether the objects of a full resync are listed from the informer caches instead of the API
+	// servers
+	ListFromCache bool
+}
+
+// TriggerBuffer buffers the triggers until they are consumed without ever blocking the callers adding them. The
+// triggers beyond the size of the buffer are dropped and degraded to a full resync, so no change is missed. The full
+// resyncs requested while one is pending are coalesced into it.
+type TriggerBuffer struct {
+	triggers chan Trigger
+	// wake is signalled when a full resync is requested, so a consumer waiting for the next trigger gets it
+	wake chan struct{}
+
+	fullResync          bool
+	fullResyncFromCache bool
+	mu                  sync.Mutex
+}
+
+func NewTriggerBuffer(size int) *TriggerBuffer {
+	if size < 0 {
+		size = 0
+	}
+
+	return &TriggerBuffer{
+		triggers: make(chan Trigger, size),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Add buffers the trigger, and returns false if the buffer is full and the trigger is degraded to a full resync
+func (b *TriggerBuffer) Add(trigger Trigger) bool {
+	if trigger.All {
+		b.requestFullResync(trigger.ListFromCache)
+
+		return true
+	}
+
+	select {
+	case b.triggers <- trigger:
+		return true
+	default:
+		b.requestFullResync(true)
+
+		return false
+	}
+}
+
+// Next returns the next trigger, a pending full resync first, and waits for one until the context is done
+func (b *TriggerBuffer) Next(ctx context.Context) (Trigger, bool) {
+	for {
+		if trigger, ok := b.takeFullResync(); ok {
+			return trigger, true
+		}
+
+		select {
+		case trigger := <-b.triggers:
+			return trigger, true
+		case <-b.wake:
+		case <-ctx.Done():
+			return Trigger{}, false
+		}
+	}
+}
+
+// Len returns the number of the buffered triggers, not counting a pending full resync
+func (b *TriggerBuffer) Len() int {
+	return len(b.triggers)
+}
+
+// IsFullResyncPending returns whether a full resync is requested and not consumed yet
+func (b *TriggerBuffer) IsFullResyncPending() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.fullResync
+}
+
+// requestFullResync marks a full resync pending, it lists from the API servers if any of the coalesced requests did
+func (b *TriggerBuffer) requestFullResync(listFromCache bool) {
+	b.mu.Lock()
+	if b.fullResync {
+		b.fullResyncFromCache = b.fullResyncFromCache && listFromCache
+	} else {
+		b.fullResync = true
+		b.fullResyncFromCache = listFromCache
+	}
+	b.mu.Unlock()
+
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (b *TriggerBuffer) takeFullResync() (Trigger, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.fullResync {
+		return Trigger{}, false
+	}
+	b.fullResync = false
+
+	return Trigger{All: true, ListFromCache: b.fullResyncFromCache}, true
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+var triggerTestGVK = schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+func newTestTrigger(i int) util.Trigger {
+	return util.Trigger{
+		GVK: triggerTestGVK,
+		Key: types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("object-%d", i)},
+	}
+}
+
+func TestTriggerBufferBelowSize(t *testing.T) {
+	t.Parallel()
+
+	const size = 100
+	buffer := util.NewTriggerBuffer(size)
+
+	for i := 0; i < size; i++ {
+		if !buffer.Add(newTestTrigger(i)) {
+			t.Fatalf("trigger %d was dropped below the buffer size", i)
+		}
+	}
+	if buffer.IsFullResyncPending() {
+		t.Fatal("full resync must not be requested below the buffer size")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	for i := 0; i < size; i++ {
+		trigger, ok := buffer.Next(ctx)
+		if !ok {
+			t.Fatalf("trigger %d was lost", i)
+		}
+		if trigger != newTestTrigger(i) {
+			t.Fatalf("unexpected trigger %d: %+v", i, trigger)
+		}
+	}
+	if buffer.Len() != 0 {
+		t.Fatalf("unexpected buffered triggers: %d", buffer.Len())
+	}
+}
+
+func TestTriggerBufferOverflow(t *testing.T) {
+	t.Parallel()
+
+	const size = 10
+	buffer := util.NewTriggerBuffer(size)
+
+	// adding beyond the size of the buffer must never block the caller, even without a consumer
+	done := make(chan int)
+	go func() {
+		dropped := 0
+		for i := 0; i < size*10; i++ {
+			if !buffer.Add(newTestTrigger(i)) {
+				dropped++
+			}
+		}
+		done <- dropped
+	}()
+
+	select {
+	case dropped := <-done:
+		if dropped != size*9 {
+			t.Fatalf("unexpected dropped triggers: %d", dropped)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("adding triggers to a full buffer blocked")
+	}
+
+	if !buffer.IsFullResyncPending() {
+		t.Fatal("overflow must request a full resync")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	trigger, ok := buffer.Next(ctx)
+	if !ok || !trigger.All || !trigger.ListFromCache {
+		t.Fatalf("full resync must be consumed first: %+v", trigger)
+	}
+	if buffer.IsFullResyncPending() {
+		t.Fatal("consumed full resync must not be pending")
+	}
+	for i := 0; i < size; i++ {
+		if trigger, ok := buffer.Next(ctx); !ok || trigger != newTestTrigger(i) {
+			t.Fatalf("buffered trigger %d was lost: %+v", i, trigger)
+		}
+	}
+}
+
+func TestTriggerBufferFullResync(t *testing.T) {
+	t.Parallel()
+
+	buffer := util.NewTriggerBuffer(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	// a consumer waiting for the next trigger is woken up by a full resync
+	next := make(chan util.Trigger)
+	go func() {
+		trigger, _ := buffer.Next(ctx)
+		next <- trigger
+	}()
+
+	buffer.Add(util.Trigger{All: true, ListFromCache: true})
+	if trigger := <-next; !trigger.All || !trigger.ListFromCache {
+		t.Fatalf("unexpected trigger: %+v", trigger)
+	}
+
+	// the full resyncs are coalesced, listing from the API servers if any of them requested it
+	buffer.Add(util.Trigger{All: true, ListFromCache: true})
+	buffer.Add(util.Trigger{All: true, ListFromCache: false})
+	buffer.Add(util.Trigger{All: true, ListFromCache: true})
+
+	if trigger, ok := buffer.Next(ctx); !ok || !trigger.All || trigger.ListFromCache {
+		t.Fatalf("unexpected trigger: %+v", trigger)
+	}
+	if buffer.IsFullResyncPending() {
+		t.Fatal("coalesced full resyncs must be consumed at once")
+	}
+
+	cancel()
+	if _, ok := buffer.Next(ctx); ok {
+		t.Fatal("no trigger must be returned after the context is done")
+	}
+}
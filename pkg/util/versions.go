@@ -0,0 +1,282 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+)
+
+var (
+	ErrNoServedVersion = errors.New("no matching version is served")
+	ErrNoConversion    = errors.New("no conversion between versions")
+	// ErrVersionFieldsDiffer is returned when an object can not be moved to another version as it is, because the
+	// version lacks some of its fields
+	ErrVersionFieldsDiffer = errors.New("fields differ between versions")
+	// ErrVersionFallbackFailed is returned when an object can not be converted to the version the local cluster serves
+	ErrVersionFallbackFailed = errors.New("could not convert object to the version served by the local cluster")
+)
+
+// ResolveSourceGVK resolves the "*" version of the GVK to the first of the given versions served by the cluster,
+// or to its preferred version if no versions are given. Other GVKs are returned as is.
+func ResolveSourceGVK(mapper meta.RESTMapper, gvk schema.GroupVersionKind, versions []string) (schema.GroupVersionKind, error) {
+	if gvk.Version != clusterregistryv1alpha1.AnyVersion {
+		return gvk, nil
+	}
+
+	mappings, err := mapper.RESTMappings(gvk.GroupKind())
+	if err != nil && !meta.IsNoMatchError(err) {
+		return gvk, errors.WrapIfWithDetails(err, "could not get rest mappings", "groupKind", gvk.GroupKind().String())
+	}
+
+	served := make(map[string]bool)
+	for _, mapping := range mappings {
+		served[mapping.GroupVersionKind.Version] = true
+	}
+
+	if len(versions) == 0 && len(mappings) > 0 {
+		return mappings[0].GroupVersionKind, nil
+	}
+
+	for _, version := range versions {
+		if served[version] {
+			return gvk.GroupKind().WithVersion(version), nil
+		}
+	}
+
+	return gvk, errors.WithDetails(ErrNoServedVersion, "groupKind", gvk.GroupKind().String(), "versions", versions)
+}
+
+// ConvertObjectVersion converts the object to the given version of its group using the conversions registered in
+// the scheme, or if the scheme can not convert it, using the field maps of the matching version conversion.
+func ConvertObjectVersion(scheme *runtime.Scheme, obj client.Object, version string, conversions []clusterregistryv1alpha1.VersionConversion) (client.Object, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Version == version {
+		return obj, nil
+	}
+
+	targetGVK := gvk.GroupKind().WithVersion(version)
+
+	if scheme.Recognizes(gvk) && scheme.Recognizes(targetGVK) {
+		if converted, err := scheme.ConvertToVersion(obj, targetGVK.GroupVersion()); err == nil {
+			if o, ok := converted.(client.Object); ok {
+				o.GetObjectKind().SetGroupVersionKind(targetGVK)
+
+				return o, nil
+			}
+		}
+	}
+
+	var conversion *clusterregistryv1alpha1.VersionConversion
+	for i := range conversions {
+		if conversions[i].From == gvk.Version && conversions[i].To == version {
+			conversion = &conversions[i]
+
+			break
+		}
+	}
+	if conversion == nil {
+		return nil, errors.WithDetails(ErrNoConversion, "from", gvk.String(), "to", version)
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not convert object to unstructured")
+	}
+
+	for _, fieldMap := range conversion.FieldMaps {
+		from := strings.Split(fieldMap.From, ".")
+		value, ok, err := unstructured.NestedFieldNoCopy(content, from...)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not get field", "path", fieldMap.From)
+		}
+		if !ok {
+			continue
+		}
+
+		unstructured.RemoveNestedField(content, from...)
+		if err := unstructured.SetNestedField(content, value, strings.Split(fieldMap.To, ".")...); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "could not set field", "path", fieldMap.To)
+		}
+	}
+
+	var converted client.Object = &unstructured.Unstructured{Object: content}
+	if o, err := scheme.New(targetGVK); err == nil {
+		if typed, ok := o.(client.Object); ok {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, typed); err != nil {
+				return nil, errors.WrapIf(err, "could not convert object from unstructured")
+			}
+			converted = typed
+		}
+	}
+	converted.GetObjectKind().SetGroupVersionKind(targetGVK)
+
+	return converted, nil
+}
+
+// RelabelObjectVersion moves the object to the given version of its kind as it is, if the version has every field of
+// the object. The fields of the kinds known by the scheme are checked by their types, the fields of any other kind by
+// the OpenAPI schema of the version, e.g. taken from its CRD.
+func RelabelObjectVersion(scheme *runtime.Scheme, obj client.Object, version string, openAPISchema map[string]interface{}) (client.Object, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	targetGVK := gvk.GroupKind().WithVersion(version)
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, errors.WrapIf(err, "could not convert object to unstructured")
+	}
+	content = runtime.DeepCopyJSON(content)
+	content["apiVersion"] = targetGVK.GroupVersion().String()
+
+	var converted client.Object
+	var missing []string
+	if o, err := scheme.New(targetGVK); err == nil {
+		typed, ok := o.(client.Object)
+		if !ok {
+			return nil, errors.NewWithDetails("invalid object", "gvk", targetGVK.String())
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, typed); err != nil {
+			return nil, errors.WrapIf(err, "could not convert object from unstructured")
+		}
+		roundTrip, err := runtime.DefaultUnstructuredConverter.ToUnstructured(typed)
+		if err != nil {
+			return nil, errors.WrapIf(err, "could not convert object to unstructured")
+		}
+		missing = getMissingFields(content, roundTrip, "")
+		converted = typed
+	} else {
+		if openAPISchema == nil {
+			return nil, errors.WithDetails(ErrNoConversion, "from", gvk.String(), "to", version)
+		}
+		missing = getUnknownSchemaFields(openAPISchema, content, "")
+		converted = &unstructured.Unstructured{Object: content}
+	}
+
+	if len(missing) > 0 {
+		return nil, errors.WithDetails(errors.WrapIff(ErrVersionFieldsDiffer, "%s has no %s", targetGVK, strings.Join(missing, ", ")),
+			"from", gvk.String(), "to", version)
+	}
+
+	converted.GetObjectKind().SetGroupVersionKind(targetGVK)
+
+	return converted, nil
+}
+
+// getMissingFields returns the paths of the fields of the content with a value, which are not in the converted content
+func getMissingFields(content interface{}, converted interface{}, path string) []string {
+	missing := make([]string, 0)
+
+	switch value := content.(type) {
+	case map[string]interface{}:
+		convertedMap, _ := converted.(map[string]interface{})
+		for _, key := range getSortedKeys(value) {
+			missing = append(missing, getMissingFields(value[key], convertedMap[key], joinFieldPath(path, key))...)
+		}
+	case []interface{}:
+		convertedList, _ := converted.([]interface{})
+		for i, item := range value {
+			var convertedItem interface{}
+			if i < len(convertedList) {
+				convertedItem = convertedList[i]
+			}
+			missing = append(missing, getMissingFields(item, convertedItem, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	default:
+		// the zero values are omitted by the types, losing them changes nothing
+		if value != nil && !reflect.ValueOf(value).IsZero() && converted == nil {
+			missing = append(missing, path)
+		}
+	}
+
+	return missing
+}
+
+// getUnknownSchemaFields returns the paths of the fields of the content which would be pruned by the OpenAPI schema
+func getUnknownSchemaFields(openAPISchema map[string]interface{}, content interface{}, path string) []string {
+	unknown := make([]string, 0)
+
+	if preserve, _ := openAPISchema["x-kubernetes-preserve-unknown-fields"].(bool); preserve {
+		return unknown
+	}
+
+	switch value := content.(type) {
+	case map[string]interface{}:
+		properties, _ := openAPISchema["properties"].(map[string]interface{})
+		embedded, _ := openAPISchema["x-kubernetes-embedded-resource"].(bool)
+		for _, key := range getSortedKeys(value) {
+			// the type and the metadata of the object are not part of its schema
+			if (path == "" || embedded) && (key == "apiVersion" || key == "kind" || key == "metadata") {
+				continue
+			}
+
+			fieldPath := joinFieldPath(path, key)
+			if property, ok := properties[key].(map[string]interface{}); ok {
+				unknown = append(unknown, getUnknownSchemaFields(property, value[key], fieldPath)...)
+
+				continue
+			}
+
+			switch additional := openAPISchema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				unknown = append(unknown, getUnknownSchemaFields(additional, value[key], fieldPath)...)
+			case bool:
+				if !additional {
+					unknown = append(unknown, fieldPath)
+				}
+			default:
+				unknown = append(unknown, fieldPath)
+			}
+		}
+	case []interface{}:
+		items, ok := openAPISchema["items"].(map[string]interface{})
+		if !ok {
+			return unknown
+		}
+		for i, item := range value {
+			unknown = append(unknown, getUnknownSchemaFields(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return unknown
+}
+
+func getSortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func joinFieldPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
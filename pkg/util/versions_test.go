@@ -0,0 +1,289 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+var widgetGVK = schema.GroupVersionKind{
+	Group:   "example.com",
+	Version: clusterregistryv1alpha1.AnyVersion,
+	Kind:    "Widget",
+}
+
+func newSourceMapper(versions ...string) meta.RESTMapper {
+	groupVersions := make([]schema.GroupVersion, 0, len(versions))
+	for _, version := range versions {
+		groupVersions = append(groupVersions, schema.GroupVersion{Group: widgetGVK.Group, Version: version})
+	}
+
+	mapper := meta.NewDefaultRESTMapper(groupVersions)
+	for _, gv := range groupVersions {
+		mapper.Add(gv.WithKind(widgetGVK.Kind), meta.RESTScopeNamespace)
+	}
+
+	return mapper
+}
+
+func newWidget(version string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	obj.SetGroupVersionKind(widgetGVK.GroupKind().WithVersion(version))
+	obj.SetName("widget")
+	obj.SetNamespace("default")
+
+	return obj
+}
+
+func TestResolveSourceGVK(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		gvk      schema.GroupVersionKind
+		mapper   meta.RESTMapper
+		versions []string
+		wanted   string
+		wantErr  bool
+	}{
+		"fixed version": {
+			gvk:    widgetGVK.GroupKind().WithVersion("v1"),
+			mapper: newSourceMapper("v1alpha1"),
+			wanted: "v1",
+		},
+		"preferred version": {
+			gvk:    widgetGVK,
+			mapper: newSourceMapper("v1beta1", "v1alpha1"),
+			wanted: "v1beta1",
+		},
+		"version list": {
+			gvk:      widgetGVK,
+			mapper:   newSourceMapper("v1beta1", "v1alpha1"),
+			versions: []string{"v1", "v1alpha1"},
+			wanted:   "v1alpha1",
+		},
+		"version not served": {
+			gvk:      widgetGVK,
+			mapper:   newSourceMapper("v1beta1"),
+			versions: []string{"v1alpha1"},
+			wantErr:  true,
+		},
+		"kind not served": {
+			gvk:     widgetGVK,
+			mapper:  meta.NewDefaultRESTMapper(nil),
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		gvk, err := util.ResolveSourceGVK(test.mapper, test.gvk, test.versions)
+		if test.wantErr {
+			if !errors.Is(err, util.ErrNoServedVersion) {
+				t.Fatalf("%s: expected no served version error, got %v", name, err)
+			}
+
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %+v", name, err)
+		}
+		if gvk != test.gvk.GroupKind().WithVersion(test.wanted) {
+			t.Fatalf("%s: resolved %s instead of %s", name, gvk, test.wanted)
+		}
+	}
+}
+
+// Two source clusters serving the Widget kind at different versions converge onto one normalized version
+func TestNormalizeSourceVersions(t *testing.T) {
+	t.Parallel()
+
+	conversions := []clusterregistryv1alpha1.VersionConversion{
+		{
+			From: "v1alpha1",
+			To:   "v1beta1",
+			FieldMaps: []clusterregistryv1alpha1.FieldMap{
+				{From: "spec.size", To: "spec.replicas"},
+				{From: "spec.missing", To: "spec.unused"},
+			},
+		},
+	}
+
+	sources := map[string]struct {
+		mapper meta.RESTMapper
+		spec   map[string]interface{}
+	}{
+		"cluster-a": {
+			mapper: newSourceMapper("v1alpha1"),
+			spec: map[string]interface{}{
+				"size":  int64(3),
+				"color": "blue",
+			},
+		},
+		"cluster-b": {
+			mapper: newSourceMapper("v1beta1"),
+			spec: map[string]interface{}{
+				"replicas": int64(3),
+				"color":    "blue",
+			},
+		},
+	}
+
+	var normalized *unstructured.Unstructured
+	for cluster, source := range sources {
+		gvk, err := util.ResolveSourceGVK(source.mapper, widgetGVK, nil)
+		if err != nil {
+			t.Fatalf("%s: %+v", cluster, err)
+		}
+
+		obj, err := util.ConvertObjectVersion(runtime.NewScheme(), newWidget(gvk.Version, source.spec), "v1beta1", conversions)
+		if err != nil {
+			t.Fatalf("%s: %+v", cluster, err)
+		}
+
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			t.Fatalf("%s: unexpected type %T", cluster, obj)
+		}
+		if normalized == nil {
+			normalized = u
+
+			continue
+		}
+		if !reflect.DeepEqual(normalized.Object, u.Object) {
+			t.Fatalf("%s: %v != %v", cluster, u.Object, normalized.Object)
+		}
+	}
+
+	if normalized.GroupVersionKind().Version != "v1beta1" {
+		t.Fatalf("unexpected version %s", normalized.GroupVersionKind().Version)
+	}
+	if replicas, _, _ := unstructured.NestedInt64(normalized.Object, "spec", "replicas"); replicas != 3 {
+		t.Fatalf("unexpected replicas %d", replicas)
+	}
+}
+
+func TestConvertObjectVersionWithoutConversion(t *testing.T) {
+	t.Parallel()
+
+	_, err := util.ConvertObjectVersion(runtime.NewScheme(), newWidget("v1alpha1", map[string]interface{}{}), "v1", nil)
+	if !errors.Is(err, util.ErrNoConversion) {
+		t.Fatalf("expected no conversion error, got %v", err)
+	}
+}
+
+func TestRelabelObjectVersion(t *testing.T) {
+	t.Parallel()
+
+	openAPISchema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"spec": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"size": map[string]interface{}{"type": "integer"},
+					"ports": map[string]interface{}{
+						"type": "array",
+						"items": map[string]interface{}{
+							"type":       "object",
+							"properties": map[string]interface{}{"port": map[string]interface{}{"type": "integer"}},
+						},
+					},
+					"labels": map[string]interface{}{
+						"type":                 "object",
+						"additionalProperties": map[string]interface{}{"type": "string"},
+					},
+					"config": map[string]interface{}{
+						"type":                                 "object",
+						"x-kubernetes-preserve-unknown-fields": true,
+					},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		spec    map[string]interface{}
+		schema  map[string]interface{}
+		err     error
+		missing string
+	}{
+		"fields of the schema": {
+			spec: map[string]interface{}{
+				"size":   int64(3),
+				"ports":  []interface{}{map[string]interface{}{"port": int64(80)}},
+				"labels": map[string]interface{}{"app": "widget"},
+				"config": map[string]interface{}{"anything": true},
+			},
+			schema: openAPISchema,
+		},
+		"field missing from the schema": {
+			spec:    map[string]interface{}{"replicas": int64(3)},
+			schema:  openAPISchema,
+			err:     util.ErrVersionFieldsDiffer,
+			missing: "spec.replicas",
+		},
+		"field of a list item missing from the schema": {
+			spec:    map[string]interface{}{"ports": []interface{}{map[string]interface{}{"port": int64(80), "protocol": "TCP"}}},
+			schema:  openAPISchema,
+			err:     util.ErrVersionFieldsDiffer,
+			missing: "spec.ports[0].protocol",
+		},
+		"no schema": {
+			spec: map[string]interface{}{"size": int64(3)},
+			err:  util.ErrNoConversion,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := util.RelabelObjectVersion(runtime.NewScheme(), newWidget("v1", test.spec), "v1beta1", test.schema)
+			if test.err != nil {
+				if !errors.Is(err, test.err) || !strings.Contains(err.Error(), test.missing) {
+					t.Fatalf("expected %v of %q, got %v", test.err, test.missing, err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("%+v", err)
+			}
+
+			if gvk := obj.GetObjectKind().GroupVersionKind(); gvk.Version != "v1beta1" {
+				t.Fatalf("unexpected gvk %s", gvk)
+			}
+			spec, _, _ := unstructured.NestedMap(obj.(*unstructured.Unstructured).Object, "spec") // nolint:forcetypeassert
+			if !reflect.DeepEqual(spec, test.spec) {
+				t.Fatalf("unexpected spec %+v", spec)
+			}
+		})
+	}
+}
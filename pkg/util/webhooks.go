@@ -0,0 +1,120 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"encoding/base64"
+	"text/template"
+
+	"emperror.dev/errors"
+	"github.com/Masterminds/sprig"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var ErrCABundleNotFound = errors.New("CA bundle of the webhooks is not found")
+
+// defaultWebhookServicePort is the port of the webhook services the client configs do not set a port for
+const defaultWebhookServicePort = 443
+
+// WebhookServiceReference is the service reference of a webhook client config, the URL templates get it as .Service
+type WebhookServiceReference struct {
+	Namespace string
+	Name      string
+	Path      string
+	Port      int64
+}
+
+// IsWebhookConfigurationKind returns whether the objects of the kind are admission webhook configurations
+func IsWebhookConfigurationKind(gk schema.GroupKind) bool {
+	return gk.Group == admissionregistrationv1.GroupName && (gk.Kind == "ValidatingWebhookConfiguration" || gk.Kind == "MutatingWebhookConfiguration")
+}
+
+// ExecuteWebhookURLTemplate returns the URL the service reference of a webhook is replaced with, the template gets the
+// data and the reference as .Service
+func ExecuteWebhookURLTemplate(text string, data map[string]interface{}, service WebhookServiceReference) (string, error) {
+	t, err := template.New("").Funcs(sprig.TxtFuncMap()).Parse(text)
+	if err != nil {
+		return "", errors.WrapIf(err, "could not parse service URL template")
+	}
+
+	templateData := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		templateData[k] = v
+	}
+	templateData["Service"] = service
+
+	var url bytes.Buffer
+	if err := t.Execute(&url, templateData); err != nil {
+		return "", errors.WrapIf(err, "could not execute service URL template")
+	}
+
+	return url.String(), nil
+}
+
+// RewriteWebhookClientConfigs replaces the service references of the client configs of the webhook configuration with
+// the URLs returned by serviceURL, unless it is nil, and sets their CA bundle, unless it is empty
+func RewriteWebhookClientConfigs(obj client.Object, serviceURL func(WebhookServiceReference) (string, error), caBundle []byte) error {
+	gk := obj.GetObjectKind().GroupVersionKind().GroupKind()
+	if !IsWebhookConfigurationKind(gk) {
+		return errors.Errorf("webhook client configs can not be rewritten on %s", gk)
+	}
+
+	return modifyObjectContent(obj, func(content map[string]interface{}) error {
+		return rewriteWebhookClientConfigs(content, serviceURL, caBundle)
+	})
+}
+
+func rewriteWebhookClientConfigs(content map[string]interface{}, serviceURL func(WebhookServiceReference) (string, error), caBundle []byte) error {
+	webhooks, _ := content["webhooks"].([]interface{})
+	for _, item := range webhooks {
+		webhook, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		clientConfig, ok := webhook["clientConfig"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if service, ok := clientConfig["service"].(map[string]interface{}); ok && serviceURL != nil {
+			ref := WebhookServiceReference{
+				Port: defaultWebhookServicePort,
+			}
+			ref.Namespace, _, _ = unstructured.NestedString(service, "namespace")
+			ref.Name, _, _ = unstructured.NestedString(service, "name")
+			ref.Path, _, _ = unstructured.NestedString(service, "path")
+			if port, ok, _ := unstructured.NestedInt64(service, "port"); ok {
+				ref.Port = port
+			}
+
+			url, err := serviceURL(ref)
+			if err != nil {
+				return errors.WithDetails(err, "webhook", webhook["name"])
+			}
+			delete(clientConfig, "service")
+			clientConfig["url"] = url
+		}
+
+		if len(caBundle) > 0 {
+			clientConfig["caBundle"] = base64.StdEncoding.EncodeToString(caBundle)
+		}
+	}
+
+	return nil
+}
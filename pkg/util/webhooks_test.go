@@ -0,0 +1,120 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"bytes"
+	"testing"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestRewriteWebhookClientConfigs(t *testing.T) {
+	t.Parallel()
+
+	serviceURL := func(service util.WebhookServiceReference) (string, error) {
+		return util.ExecuteWebhookURLTemplate("https://{{ .Service.Name }}.{{ .Service.Namespace }}.{{ .Cluster }}:{{ .Service.Port }}{{ .Service.Path }}", map[string]interface{}{
+			"Cluster": "remote.example.com",
+		}, service)
+	}
+
+	tests := map[string]struct {
+		serviceURL func(util.WebhookServiceReference) (string, error)
+		caBundle   []byte
+		wantedURLs []string
+		wantedCA   []byte
+	}{
+		"client configs are kept": {
+			wantedURLs: []string{"", "https://external.example.com/validate"},
+			wantedCA:   []byte("source-ca"),
+		},
+		"service references are replaced": {
+			serviceURL: serviceURL,
+			wantedURLs: []string{"https://webhook.system.remote.example.com:443/validate", "https://external.example.com/validate"},
+			wantedCA:   []byte("source-ca"),
+		},
+		"CA bundle is set": {
+			serviceURL: serviceURL,
+			caBundle:   []byte("local-ca"),
+			wantedURLs: []string{"https://webhook.system.remote.example.com:443/validate", "https://external.example.com/validate"},
+			wantedCA:   []byte("local-ca"),
+		},
+	}
+
+	for name, test := range tests {
+		configuration := &admissionregistrationv1.ValidatingWebhookConfiguration{
+			TypeMeta:   v1.TypeMeta{APIVersion: "admissionregistration.k8s.io/v1", Kind: "ValidatingWebhookConfiguration"},
+			ObjectMeta: v1.ObjectMeta{Name: "demo"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				{
+					Name: "service.example.com",
+					ClientConfig: admissionregistrationv1.WebhookClientConfig{
+						Service:  &admissionregistrationv1.ServiceReference{Namespace: "system", Name: "webhook", Path: pointer.String("/validate")},
+						CABundle: []byte("source-ca"),
+					},
+				},
+				{
+					Name: "url.example.com",
+					ClientConfig: admissionregistrationv1.WebhookClientConfig{
+						URL:      pointer.String("https://external.example.com/validate"),
+						CABundle: []byte("source-ca"),
+					},
+				},
+			},
+		}
+
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(configuration.DeepCopy())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for kind, obj := range map[string]client.Object{
+			"typed":        configuration.DeepCopy(),
+			"unstructured": &unstructured.Unstructured{Object: content},
+		} {
+			if err := util.RewriteWebhookClientConfigs(obj, test.serviceURL, test.caBundle); err != nil {
+				t.Fatalf("%s (%s): %+v", name, kind, err)
+			}
+
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rewritten := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, rewritten); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, webhook := range rewritten.Webhooks {
+				if url := pointer.StringDeref(webhook.ClientConfig.URL, ""); url != test.wantedURLs[i] {
+					t.Fatalf("%s (%s): webhooks[%d]: %q != %q", name, kind, i, url, test.wantedURLs[i])
+				}
+				if url := pointer.StringDeref(webhook.ClientConfig.URL, ""); url != "" && webhook.ClientConfig.Service != nil {
+					t.Fatalf("%s (%s): webhooks[%d]: service reference is kept", name, kind, i)
+				}
+				if !bytes.Equal(webhook.ClientConfig.CABundle, test.wantedCA) {
+					t.Fatalf("%s (%s): webhooks[%d]: %q != %q", name, kind, i, webhook.ClientConfig.CABundle, test.wantedCA)
+				}
+			}
+		}
+	}
+}
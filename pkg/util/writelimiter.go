@@ -0,0 +1,61 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+)
+
+// WriteLimiter limits the number of writes running at the same time, it is shared by the sync controllers of every
+// rule, so their workers together can not flood the local API server. A nil limiter does not limit the writes.
+type WriteLimiter struct {
+	tokens chan struct{}
+}
+
+// NewWriteLimiter returns a limiter allowing size writes at the same time, or nil if size is not positive
+func NewWriteLimiter(size int) *WriteLimiter {
+	if size <= 0 {
+		return nil
+	}
+
+	return &WriteLimiter{
+		tokens: make(chan struct{}, size),
+	}
+}
+
+// Acquire waits for a free slot, the slot has to be released once the write is done
+func (l *WriteLimiter) Acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	select {
+	case l.tokens <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return errors.WrapIf(ctx.Err(), "could not wait for a free write slot")
+	}
+}
+
+// Release frees the slot taken by Acquire
+func (l *WriteLimiter) Release() {
+	if l == nil {
+		return
+	}
+
+	<-l.tokens
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+func TestWriteLimiter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		size     int
+		acquired int
+		blocked  bool
+	}{
+		{
+			name:     "free slot",
+			size:     2,
+			acquired: 1,
+		},
+		{
+			name:     "no free slot",
+			size:     2,
+			acquired: 2,
+			blocked:  true,
+		},
+		{
+			name:     "disabled",
+			size:     0,
+			acquired: 10,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			limiter := util.NewWriteLimiter(tc.size)
+			for i := 0; i < tc.acquired; i++ {
+				if err := limiter.Acquire(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+			defer cancel()
+			err := limiter.Acquire(ctx)
+			if blocked := err != nil; blocked != tc.blocked {
+				t.Fatalf("expected the write to be blocked: %t, got %v", tc.blocked, err)
+			}
+
+			limiter.Release()
+			if err := limiter.Acquire(context.Background()); err != nil {
+				t.Fatalf("expected a released slot to be acquired, got %v", err)
+			}
+		})
+	}
+}
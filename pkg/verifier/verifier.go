@@ -0,0 +1,312 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+const defaultObjectsPerMinute = 10
+
+// ListFunc returns the synced objects to verify
+type ListFunc func(ctx context.Context) ([]client.Object, error)
+
+// RenderFunc renders the desired state of the synced object from its current source object,
+// ok is false if there is nothing to compare with, e.g. the source object is already gone
+type RenderFunc func(ctx context.Context, obj client.Object) (desired client.Object, ok bool, err error)
+
+// ReportFunc is called with the finding of every verified object, changed is true if the object drifted,
+// its drifted paths changed or it is in sync again since its previous verification
+type ReportFunc func(ctx context.Context, finding Finding, changed bool)
+
+// ChangeFunc is called with every drifted object when the set of drifted objects changes
+type ChangeFunc func(ctx context.Context, findings []Finding)
+
+// RepairFunc syncs the drifted object again
+type RepairFunc func(ctx context.Context, obj client.Object)
+
+// GateFunc reports whether verification is currently allowed, e.g. it returns false under back-pressure
+type GateFunc func() bool
+
+// Finding is the result of verifying a synced object
+type Finding struct {
+	Key types.NamespacedName
+	// Paths are the dot separated paths differing from the desired state
+	Paths []string
+	// Since is the time the drift was first found
+	Since time.Time
+}
+
+func (f Finding) Drifted() bool {
+	return len(f.Paths) > 0
+}
+
+// Verifier walks the synced objects round-robin at a low rate and compares each of them with its desired state.
+// Drifted objects are only reported unless a repair func is set.
+type Verifier struct {
+	list             ListFunc
+	render           RenderFunc
+	report           ReportFunc
+	change           ChangeFunc
+	repair           RepairFunc
+	gate             GateFunc
+	objectsPerMinute int
+	log              logr.Logger
+
+	mu       sync.Mutex
+	batch    []client.Object
+	cursor   int
+	findings map[types.NamespacedName]Finding
+}
+
+type Option func(v *Verifier)
+
+func WithObjectsPerMinute(objectsPerMinute int) Option {
+	return func(v *Verifier) {
+		v.objectsPerMinute = objectsPerMinute
+	}
+}
+
+func WithReportFunc(report ReportFunc) Option {
+	return func(v *Verifier) {
+		v.report = report
+	}
+}
+
+func WithChangeFunc(change ChangeFunc) Option {
+	return func(v *Verifier) {
+		v.change = change
+	}
+}
+
+func WithRepairFunc(repair RepairFunc) Option {
+	return func(v *Verifier) {
+		v.repair = repair
+	}
+}
+
+func WithGate(gate GateFunc) Option {
+	return func(v *Verifier) {
+		v.gate = gate
+	}
+}
+
+func WithLogger(log logr.Logger) Option {
+	return func(v *Verifier) {
+		v.log = log
+	}
+}
+
+func New(list ListFunc, render RenderFunc, opts ...Option) *Verifier {
+	v := &Verifier{
+		list:             list,
+		render:           render,
+		objectsPerMinute: defaultObjectsPerMinute,
+		log:              logr.Discard(),
+
+		findings: make(map[types.NamespacedName]Finding),
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if v.objectsPerMinute <= 0 {
+		v.objectsPerMinute = defaultObjectsPerMinute
+	}
+
+	return v
+}
+
+// Start verifies objects at the configured rate until the context is done
+func (v *Verifier) Start(ctx context.Context) error {
+	limiter := rate.NewLimiter(rate.Every(time.Minute/time.Duration(v.objectsPerMinute)), 1)
+
+	for {
+		if err := limiter.Wait(ctx); err != nil {
+			// the context is done
+			return nil
+		}
+
+		if _, err := v.VerifyNext(ctx, time.Now()); err != nil {
+			v.log.Error(err, "could not verify object")
+		}
+	}
+}
+
+// VerifyNext verifies the next object of the round, it returns false if no object was verified
+func (v *Verifier) VerifyNext(ctx context.Context, now time.Time) (bool, error) {
+	if v.gate != nil && !v.gate() {
+		v.log.V(1).Info("verification is paused")
+
+		return false, nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.cursor >= len(v.batch) {
+		if err := v.refresh(ctx); err != nil {
+			return false, err
+		}
+		if len(v.batch) == 0 {
+			return false, nil
+		}
+	}
+
+	obj := v.batch[v.cursor]
+	v.cursor++
+
+	key := client.ObjectKeyFromObject(obj)
+
+	desired, ok, err := v.render(ctx, obj)
+	if err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not render desired state", "resource", key.String())
+	}
+	if !ok {
+		v.setFinding(ctx, Finding{Key: key})
+
+		return false, nil
+	}
+
+	paths, err := util.DriftedPaths(desired, obj)
+	if err != nil {
+		return false, errors.WrapIfWithDetails(err, "could not compare with desired state", "resource", key.String())
+	}
+
+	finding := Finding{
+		Key:   key,
+		Paths: paths,
+	}
+	if finding.Drifted() {
+		finding.Since = now
+		if previous, ok := v.findings[key]; ok {
+			finding.Since = previous.Since
+		}
+	}
+
+	changed := v.setFinding(ctx, finding)
+
+	if v.report != nil {
+		v.report(ctx, finding, changed)
+	}
+
+	if finding.Drifted() && v.repair != nil {
+		v.repair(ctx, obj)
+	}
+
+	return true, nil
+}
+
+// Findings returns the drifted objects ordered by key
+func (v *Verifier) Findings() []Finding {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.getFindings()
+}
+
+func (v *Verifier) refresh(ctx context.Context) error {
+	objects, err := v.list(ctx)
+	if err != nil {
+		return errors.WrapIf(err, "could not list objects to verify")
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return client.ObjectKeyFromObject(objects[i]).String() < client.ObjectKeyFromObject(objects[j]).String()
+	})
+
+	v.batch = objects
+	v.cursor = 0
+
+	// objects no longer synced are not drifted anymore
+	keys := make(map[types.NamespacedName]struct{}, len(objects))
+	for _, obj := range objects {
+		keys[client.ObjectKeyFromObject(obj)] = struct{}{}
+	}
+
+	changed := false
+	for key := range v.findings {
+		if _, ok := keys[key]; !ok {
+			delete(v.findings, key)
+			changed = true
+		}
+	}
+
+	if changed && v.change != nil {
+		v.change(ctx, v.getFindings())
+	}
+
+	return nil
+}
+
+func (v *Verifier) setFinding(ctx context.Context, finding Finding) bool {
+	previous, existed := v.findings[finding.Key]
+	switch {
+	case finding.Drifted():
+		v.findings[finding.Key] = finding
+		if existed && equalPaths(previous.Paths, finding.Paths) {
+			return false
+		}
+	case existed:
+		delete(v.findings, finding.Key)
+	default:
+		return false
+	}
+
+	if v.change != nil {
+		v.change(ctx, v.getFindings())
+	}
+
+	return true
+}
+
+func (v *Verifier) getFindings() []Finding {
+	findings := make([]Finding, 0, len(v.findings))
+	for _, finding := range v.findings {
+		findings = append(findings, finding)
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Key.String() < findings[j].Key.String()
+	})
+
+	return findings
+}
+
+func equalPaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
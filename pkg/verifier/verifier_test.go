@@ -0,0 +1,258 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/cisco-open/cluster-registry-controller/pkg/verifier"
+)
+
+type inventory struct {
+	mu       sync.Mutex
+	live     map[string]string
+	desired  map[string]string
+	repaired []string
+	changes  int
+	reports  int
+}
+
+func newInventory() *inventory {
+	return &inventory{
+		live: map[string]string{
+			"a": "1",
+			"b": "1",
+			"c": "1",
+		},
+		desired: map[string]string{
+			"a": "1",
+			"b": "1",
+			"c": "1",
+		},
+	}
+}
+
+func newConfigMap(name string, value string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Data: map[string]string{
+			"value": value,
+		},
+	}
+}
+
+func (i *inventory) list(ctx context.Context) ([]client.Object, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	objects := make([]client.Object, 0, len(i.live))
+	for name, value := range i.live {
+		objects = append(objects, newConfigMap(name, value))
+	}
+
+	return objects, nil
+}
+
+func (i *inventory) render(ctx context.Context, obj client.Object) (client.Object, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	value, ok := i.desired[obj.GetName()]
+	if !ok {
+		return nil, false, nil
+	}
+
+	return newConfigMap(obj.GetName(), value), true, nil
+}
+
+func (i *inventory) repair(ctx context.Context, obj client.Object) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.repaired = append(i.repaired, obj.GetName())
+	i.live[obj.GetName()] = i.desired[obj.GetName()]
+}
+
+func (i *inventory) change(ctx context.Context, findings []verifier.Finding) {
+	i.changes++
+}
+
+func (i *inventory) report(ctx context.Context, finding verifier.Finding, changed bool) {
+	if changed {
+		i.reports++
+	}
+}
+
+func verifyRound(t *testing.T, v *verifier.Verifier, count int, now time.Time) {
+	t.Helper()
+
+	for n := 0; n < count; n++ {
+		if _, err := v.VerifyNext(context.Background(), now); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestVerifierDetectsDrift(t *testing.T) {
+	t.Parallel()
+
+	inv := newInventory()
+	inv.live["b"] = "2"
+
+	v := verifier.New(inv.list, inv.render, verifier.WithChangeFunc(inv.change), verifier.WithReportFunc(inv.report))
+
+	now := time.Now()
+	verifyRound(t, v, 3, now)
+
+	findings := v.Findings()
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 drifted object, got %v", findings)
+	}
+	if findings[0].Key != (types.NamespacedName{Namespace: "default", Name: "b"}) {
+		t.Fatalf("unexpected drifted object %s", findings[0].Key)
+	}
+	if len(findings[0].Paths) != 1 || findings[0].Paths[0] != "data.value" {
+		t.Fatalf("unexpected drifted paths %v", findings[0].Paths)
+	}
+	if inv.changes != 1 {
+		t.Fatalf("expected 1 change, got %d", inv.changes)
+	}
+
+	// the drift is reported from the time it was first found
+	verifyRound(t, v, 3, now.Add(time.Minute))
+	if findings := v.Findings(); len(findings) != 1 || !findings[0].Since.Equal(now) {
+		t.Fatalf("unexpected findings after the second round %v", findings)
+	}
+	if inv.changes != 1 {
+		t.Fatalf("expected 1 change, got %d", inv.changes)
+	}
+
+	// the drift is cleared once the object is in sync again
+	inv.live["b"] = "1"
+	verifyRound(t, v, 3, now.Add(2*time.Minute))
+	if findings := v.Findings(); len(findings) != 0 {
+		t.Fatalf("expected no drifted objects, got %v", findings)
+	}
+	if inv.changes != 2 || inv.reports != 2 {
+		t.Fatalf("expected 2 changes and reports, got %d and %d", inv.changes, inv.reports)
+	}
+}
+
+func TestVerifierDoesNotRepairByDefault(t *testing.T) {
+	t.Parallel()
+
+	inv := newInventory()
+	inv.live["a"] = "2"
+
+	v := verifier.New(inv.list, inv.render)
+
+	verifyRound(t, v, 6, time.Now())
+
+	if len(inv.repaired) != 0 {
+		t.Fatalf("expected no repairs, got %v", inv.repaired)
+	}
+	if inv.live["a"] != "2" {
+		t.Fatal("drifted object was modified")
+	}
+	if findings := v.Findings(); len(findings) != 1 {
+		t.Fatalf("expected 1 drifted object, got %v", findings)
+	}
+}
+
+func TestVerifierRepairsDrift(t *testing.T) {
+	t.Parallel()
+
+	inv := newInventory()
+	inv.live["a"] = "2"
+	inv.live["c"] = "2"
+
+	v := verifier.New(inv.list, inv.render, verifier.WithRepairFunc(inv.repair))
+
+	verifyRound(t, v, 3, time.Now())
+	if len(inv.repaired) != 2 || inv.repaired[0] != "a" || inv.repaired[1] != "c" {
+		t.Fatalf("expected a and c to be repaired, got %v", inv.repaired)
+	}
+
+	verifyRound(t, v, 3, time.Now())
+	if findings := v.Findings(); len(findings) != 0 {
+		t.Fatalf("expected no drifted objects after repair, got %v", findings)
+	}
+	if len(inv.repaired) != 2 {
+		t.Fatalf("expected no further repairs, got %v", inv.repaired)
+	}
+}
+
+func TestVerifierPausesWhenGated(t *testing.T) {
+	t.Parallel()
+
+	inv := newInventory()
+	inv.live["a"] = "2"
+
+	open := false
+	v := verifier.New(inv.list, inv.render, verifier.WithGate(func() bool {
+		return open
+	}))
+
+	verified, err := v.VerifyNext(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified || len(v.Findings()) != 0 {
+		t.Fatal("object verified while paused")
+	}
+
+	open = true
+	verified, err = v.VerifyNext(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified || len(v.Findings()) != 1 {
+		t.Fatal("object not verified after resume")
+	}
+}
+
+func TestVerifierForgetsRemovedObjects(t *testing.T) {
+	t.Parallel()
+
+	inv := newInventory()
+	inv.live["a"] = "2"
+
+	v := verifier.New(inv.list, inv.render, verifier.WithChangeFunc(inv.change))
+
+	verifyRound(t, v, 3, time.Now())
+	if findings := v.Findings(); len(findings) != 1 {
+		t.Fatalf("expected 1 drifted object, got %v", findings)
+	}
+
+	delete(inv.live, "a")
+	verifyRound(t, v, 1, time.Now())
+	if findings := v.Findings(); len(findings) != 0 {
+		t.Fatalf("expected removed object to be forgotten, got %v", findings)
+	}
+	if inv.changes != 2 {
+		t.Fatalf("expected 2 changes, got %d", inv.changes)
+	}
+}
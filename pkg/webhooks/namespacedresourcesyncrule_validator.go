@@ -0,0 +1,123 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"net/http"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	clusterregistrycontrollerapiv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/rulebuilder"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// +kubebuilder:webhook:path=/validate-namespacedresourcesyncrule,mutating=false,failurePolicy=ignore,sideEffects=None,groups=clusterregistry.k8s.cisco.com,resources=namespacedresourcesyncrules,verbs=create;update,versions=v1alpha1,name=namespacedresourcesyncrule-validator.clusterregistry.k8s.cisco.com,admissionReviewVersions=v1
+
+// NamespacedResourceSyncRuleValidator validates namespaced resource sync rule
+// CRs of the cluster registry. The rules are confined to their namespaces, so
+// they are not checked against the other rules or the authorization policies.
+type NamespacedResourceSyncRuleValidator struct {
+	// logger is the log interface to use inside the validator.
+	logger logr.Logger
+
+	// client is used to look up the scope of the synced kinds.
+	client client.Client
+
+	// scheme is used to check whether the kinds targeted by GVK mutations are
+	// known to the controller.
+	scheme *runtime.Scheme
+
+	// denyList contains the namespaces and kinds the controller never writes.
+	denyList *util.DenyList
+
+	// decoder is responsible for decoding the webhook request into structured
+	// data.
+	decoder *admission.Decoder
+}
+
+// NewNamespacedResourceSyncRuleValidator instantiates a namespaced resource
+// sync rule CR validator which uses the specified client, scheme and deny
+// list.
+func NewNamespacedResourceSyncRuleValidator(logger logr.Logger, client client.Client, scheme *runtime.Scheme, denyList *util.DenyList) *NamespacedResourceSyncRuleValidator {
+	return &NamespacedResourceSyncRuleValidator{
+		logger:   logger,
+		client:   client,
+		scheme:   scheme,
+		denyList: denyList,
+		decoder:  nil,
+	}
+}
+
+// Handle handles the validator's admission requests and determines whether the
+// specified request can be allowed.
+func (validator *NamespacedResourceSyncRuleValidator) Handle(ctx context.Context, request admission.Request) admission.Response {
+	validator.logger.V(1).Info("validating namespaced resource sync rule CR", "request", request)
+
+	rule := &clusterregistrycontrollerapiv1alpha1.NamespacedResourceSyncRule{}
+
+	err := validator.decoder.Decode(request, rule)
+	if err != nil {
+		err = errors.Wrap(err, "decoding admission request as namespaced resource sync rule CR failed")
+
+		validator.logger.Error(err, "validating namespaced resource sync rule CR failed", "request", request)
+
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// the namespace of the rule is set by the API server if the request does not contain it
+	namespace := rule.GetNamespace()
+	if namespace == "" {
+		namespace = request.Namespace
+	}
+
+	err = validator.validateSpec(namespace, rule.Spec)
+	if err != nil {
+		validator.logger.Info("namespaced resource sync rule CR rejected", "namespace", namespace, "name", rule.Name, "reason", err.Error())
+
+		return admission.Denied(err.Error())
+	}
+
+	validator.logger.V(1).Info("validating namespaced resource sync rule CR succeeded", "namespace", namespace, "name", rule.Name)
+
+	return admission.Allowed("")
+}
+
+// validateSpec returns an error if the specified spec of a rule in the
+// specified namespace could not be synced at runtime.
+func (validator *NamespacedResourceSyncRuleValidator) validateSpec(namespace string, spec clusterregistrycontrollerapiv1alpha1.ResourceSyncRuleSpec) error {
+	err := rulebuilder.ValidateNamespacedSpec(spec, rulebuilder.WithScheme(validator.scheme), rulebuilder.WithRESTMapper(validator.client.RESTMapper()))
+	if err != nil {
+		return err
+	}
+
+	if validator.denyList.IsNamespaceProtected(namespace) {
+		return errors.Errorf("namespace %s is protected by the controller, objects are never synced into it", namespace)
+	}
+
+	return nil
+}
+
+// InjectDecoder sets the namespaced resource sync rule CR decoder object.
+func (validator *NamespacedResourceSyncRuleValidator) InjectDecoder(decoder *admission.Decoder) error {
+	validator.decoder = decoder
+
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+	"github.com/cisco-open/cluster-registry-controller/pkg/webhooks"
+)
+
+func TestNamespacedResourceSyncRuleValidator(t *testing.T) {
+	t.Parallel()
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := clusterregistryv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	denyList, err := util.NewDenyList([]string{"kube-system"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+
+	tests := map[string]struct {
+		mutate  func(rule *clusterregistryv1alpha1.NamespacedResourceSyncRule)
+		allowed bool
+	}{
+		"valid rule": {
+			allowed: true,
+		},
+		"cluster scoped kind": {
+			mutate: func(rule *clusterregistryv1alpha1.NamespacedResourceSyncRule) {
+				rule.Spec.GVK.Kind = "Namespace"
+			},
+		},
+		"dependencies": {
+			mutate: func(rule *clusterregistryv1alpha1.NamespacedResourceSyncRule) {
+				rule.Spec.DependsOn = []string{"other"}
+			},
+		},
+		"namespace routing": {
+			mutate: func(rule *clusterregistryv1alpha1.NamespacedResourceSyncRule) {
+				rule.Spec.Rules = []clusterregistryv1alpha1.SyncRule{
+					{
+						Mutations: clusterregistryv1alpha1.Mutations{
+							NamespaceRouting: &clusterregistryv1alpha1.NamespaceRouting{
+								FromLabel: "team",
+								Map:       map[string]string{"a": "team-a"},
+							},
+						},
+					},
+				}
+			},
+		},
+		"protected namespace": {
+			mutate: func(rule *clusterregistryv1alpha1.NamespacedResourceSyncRule) {
+				rule.Namespace = "kube-system"
+			},
+		},
+	}
+
+	for name, test := range tests {
+		validator := webhooks.NewNamespacedResourceSyncRuleValidator(
+			logr.Discard(),
+			restMapperClient{
+				Client: fake.NewClientBuilder().WithScheme(s).Build(),
+				mapper: mapper,
+			},
+			s,
+			denyList,
+		)
+
+		decoder, err := admission.NewDecoder(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := validator.InjectDecoder(decoder); err != nil {
+			t.Fatal(err)
+		}
+
+		rule := &clusterregistryv1alpha1.NamespacedResourceSyncRule{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: clusterregistryv1alpha1.GroupVersion.String(),
+				Kind:       "NamespacedResourceSyncRule",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "team-a",
+			},
+			Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+				GVK: resources.GroupVersionKind{
+					Version: "v1",
+					Kind:    "ConfigMap",
+				},
+			},
+		}
+		if test.mutate != nil {
+			test.mutate(rule)
+		}
+
+		raw, err := json.Marshal(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response := validator.Handle(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Namespace: rule.Namespace,
+				Object: runtime.RawExtension{
+					Raw: raw,
+				},
+			},
+		})
+
+		if response.Allowed != test.allowed {
+			t.Fatalf("%s: allowed: %t, expected: %t (%v)", name, response.Allowed, test.allowed, response.Result)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+
+	clusterregistrycontrollerapiv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/authorization"
+	"github.com/cisco-open/cluster-registry-controller/pkg/rulebuilder"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+)
+
+// +kubebuilder:webhook:path=/validate-resourcesyncrule,mutating=false,failurePolicy=fail,sideEffects=None,groups=clusterregistry.k8s.cisco.com,resources=resourcesyncrules,verbs=create;update,versions=v1alpha1,name=resourcesyncrule-validator.clusterregistry.k8s.cisco.com,admissionReviewVersions=v1
+
+// ResourceSyncRuleValidator validates resource sync rule CRs of the cluster
+// registry.
+type ResourceSyncRuleValidator struct {
+	// logger is the log interface to use inside the validator.
+	logger logr.Logger
+
+	// client is used to list the existing resource sync rules when looking for
+	// overlapping rules.
+	client client.Client
+
+	// scheme is used to check whether the kinds targeted by GVK mutations are
+	// known to the controller.
+	scheme *runtime.Scheme
+
+	// denyList contains the namespaces and kinds the controller never writes.
+	denyList *util.DenyList
+
+	// authorize enables checking the rules created or updated by a user
+	// against the sync rule authorization policies applying to the user.
+	authorize bool
+
+	// authorizationExemptUsers are not checked against the policies, e.g.
+	// the controller itself writing the core and synced rules.
+	authorizationExemptUsers map[string]struct{}
+
+	// decoder is responsible for decoding the webhook request into structured
+	// data.
+	decoder *admission.Decoder
+}
+
+// ResourceSyncRuleValidatorOption configures optional checks of the validator.
+type ResourceSyncRuleValidatorOption func(validator *ResourceSyncRuleValidator)
+
+// WithAuthorization denies the rules which are not allowed to the requesting
+// user by any sync rule authorization policy, except for the exempt users.
+func WithAuthorization(exemptUsers ...string) ResourceSyncRuleValidatorOption {
+	return func(validator *ResourceSyncRuleValidator) {
+		validator.authorize = true
+		for _, user := range exemptUsers {
+			validator.authorizationExemptUsers[user] = struct{}{}
+		}
+	}
+}
+
+// NewResourceSyncRuleValidator instantiates a resource sync rule CR validator
+// which uses the specified client, scheme and deny list.
+func NewResourceSyncRuleValidator(logger logr.Logger, client client.Client, scheme *runtime.Scheme, denyList *util.DenyList, opts ...ResourceSyncRuleValidatorOption) *ResourceSyncRuleValidator {
+	validator := &ResourceSyncRuleValidator{
+		logger:   logger,
+		client:   client,
+		scheme:   scheme,
+		denyList: denyList,
+		decoder:  nil,
+
+		authorizationExemptUsers: make(map[string]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(validator)
+	}
+
+	return validator
+}
+
+// Handle handles the validator's admission requests and determines whether the
+// specified request can be allowed.
+func (validator *ResourceSyncRuleValidator) Handle(ctx context.Context, request admission.Request) admission.Response {
+	validator.logger.V(1).Info("validating resource sync rule CR", "request", request)
+
+	rule := &clusterregistrycontrollerapiv1alpha1.ResourceSyncRule{}
+
+	err := validator.decoder.Decode(request, rule)
+	if err != nil {
+		err = errors.Wrap(err, "decoding admission request as resource sync rule CR failed")
+
+		validator.logger.Error(err, "validating resource sync rule CR failed", "request", request)
+
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	err = validator.validateSpec(rule.Spec)
+	if err != nil {
+		validator.logger.Info("resource sync rule CR rejected", "name", rule.Name, "reason", err.Error())
+
+		return admission.Denied(err.Error())
+	}
+
+	err = validator.authorizeRule(ctx, request, rule)
+	if errors.Is(err, authorization.ErrNotAuthorized) {
+		validator.logger.Info("resource sync rule CR rejected", "name", rule.Name, "user", request.UserInfo.Username, "reason", err.Error())
+
+		return admission.Denied(err.Error())
+	}
+	if err != nil {
+		validator.logger.Error(err, "validating resource sync rule CR failed")
+
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	existingRules := &clusterregistrycontrollerapiv1alpha1.ResourceSyncRuleList{}
+	err = validator.client.List(ctx, existingRules)
+	if err != nil {
+		err = errors.Wrap(err, "listing existing resource sync rule CRs failed")
+
+		validator.logger.Error(err, "validating resource sync rule CR failed")
+
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	// the existing rules have no cycles, so a new cycle goes through the validated rule
+	dependencies := util.GetRuleDependencies(existingRules.Items)
+	dependencies[rule.Name] = rule.Spec.DependsOn
+	if cycle := util.FindDependencyCycle(dependencies, rule.Name); cycle != nil {
+		err = errors.Errorf("dependsOn: dependency cycle %s", strings.Join(cycle, " -> "))
+		validator.logger.Info("resource sync rule CR rejected", "name", rule.Name, "reason", err.Error())
+
+		return admission.Denied(err.Error())
+	}
+
+	warnings := make([]string, 0)
+	for _, existingRule := range existingRules.Items {
+		// Note: in case a rule is updated in place it should not overlap with the older version of itself.
+		if existingRule.Name == rule.Name {
+			continue
+		}
+
+		if rulesOverlap(rule.Spec, existingRule.Spec) {
+			warnings = append(warnings, fmt.Sprintf(
+				"objects matched by this rule may also be matched by resource sync rule %s, the rules would fight over their ownership",
+				existingRule.Name,
+			))
+		}
+	}
+
+	warnings = append(warnings, validator.getNamespaceRoutingWarnings(rule.Spec)...)
+
+	validator.logger.V(1).Info("validating resource sync rule CR succeeded", "name", rule.Name, "warnings", warnings)
+
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// authorizeRule returns an error if the requesting user is not allowed to
+// author the specified rule. Only spec changes are authorized, so existing
+// rules are not broken by policy changes, e.g. when their finalizers are
+// updated by the controller.
+func (validator *ResourceSyncRuleValidator) authorizeRule(ctx context.Context, request admission.Request, rule *clusterregistrycontrollerapiv1alpha1.ResourceSyncRule) error {
+	if !validator.authorize {
+		return nil
+	}
+
+	if _, ok := validator.authorizationExemptUsers[request.UserInfo.Username]; ok {
+		return nil
+	}
+
+	if request.Operation == admissionv1.Update {
+		oldRule := &clusterregistrycontrollerapiv1alpha1.ResourceSyncRule{}
+		if err := validator.decoder.DecodeRaw(request.OldObject, oldRule); err != nil {
+			return errors.Wrap(err, "decoding admission request as resource sync rule CR failed")
+		}
+
+		if equality.Semantic.DeepEqual(oldRule.Spec, rule.Spec) {
+			return nil
+		}
+	}
+
+	policies := &clusterregistrycontrollerapiv1alpha1.SyncRuleAuthorizationPolicyList{}
+	if err := validator.client.List(ctx, policies); err != nil {
+		return errors.Wrap(err, "listing sync rule authorization policies failed")
+	}
+
+	return authorization.Authorize(request.UserInfo, policies.Items, authorization.RequestsFromRuleSpec(rule.Spec))
+}
+
+// validateSpec returns an error if the specified spec could not be synced
+// at runtime.
+func (validator *ResourceSyncRuleValidator) validateSpec(spec clusterregistrycontrollerapiv1alpha1.ResourceSyncRuleSpec) error {
+	// the checks of the spec itself are shared with the rulebuilder package, so they can not drift from the webhook
+	if err := rulebuilder.ValidateSpec(spec, rulebuilder.WithScheme(validator.scheme)); err != nil {
+		return err
+	}
+
+	if validator.isBlocked(spec) {
+		return errors.New("every object matched by the rule would be written as a kind or into a namespace denied by the controller")
+	}
+
+	return nil
+}
+
+// isBlocked returns whether every write of the specified spec would be
+// blocked by the deny list of the controller.
+func (validator *ResourceSyncRuleValidator) isBlocked(spec clusterregistrycontrollerapiv1alpha1.ResourceSyncRuleSpec) bool {
+	if validator.denyList == nil || len(spec.Rules) == 0 {
+		return false
+	}
+
+	for _, rule := range spec.Rules {
+		gvk := mutateGVK(spec.GVK, rule.Mutations.GVK)
+		if validator.denyList.IsGVKDenied(gvk) {
+			continue
+		}
+
+		if len(rule.Matches) == 0 || !validator.isNamespaced(gvk) {
+			return false
+		}
+
+		for _, match := range rule.Matches {
+			if !validator.matchesProtectedNamespacesOnly(match) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isNamespaced returns whether the specified kind is namespaced, kinds with an
+// unknown scope are considered cluster scoped.
+func (validator *ResourceSyncRuleValidator) isNamespaced(gvk schema.GroupVersionKind) bool {
+	versions := []string{gvk.Version}
+	if gvk.Version == clusterregistrycontrollerapiv1alpha1.AnyVersion {
+		versions = nil
+	}
+
+	mapper := validator.client.RESTMapper()
+	if mapper == nil {
+		return false
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), versions...)
+	if err != nil {
+		return false
+	}
+
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace
+}
+
+// getNamespaceRoutingWarnings returns a warning for every routed namespace
+// protected by the controller, the objects routed there are never synced.
+func (validator *ResourceSyncRuleValidator) getNamespaceRoutingWarnings(spec clusterregistrycontrollerapiv1alpha1.ResourceSyncRuleSpec) []string {
+	warnings := make([]string, 0)
+	for i, rule := range spec.Rules {
+		routing := rule.Mutations.NamespaceRouting
+		if routing == nil {
+			continue
+		}
+
+		namespaces := make([]string, 0, len(routing.Map)+1)
+		for _, namespace := range routing.Map {
+			namespaces = append(namespaces, namespace)
+		}
+		if routing.GetUnmappedPolicy() == clusterregistrycontrollerapiv1alpha1.UnmappedNamespacePolicyDefault {
+			namespaces = append(namespaces, routing.DefaultNamespace)
+		}
+		sort.Strings(namespaces)
+
+		for _, namespace := range namespaces {
+			if validator.denyList.IsNamespaceProtected(namespace) {
+				warnings = append(warnings, fmt.Sprintf(
+					"rules[%d].mutations.namespaceRouting routes objects into the protected namespace %s, they are not synced",
+					i, namespace,
+				))
+			}
+		}
+	}
+
+	return warnings
+}
+
+func (validator *ResourceSyncRuleValidator) matchesProtectedNamespacesOnly(match clusterregistrycontrollerapiv1alpha1.SyncRuleMatch) bool {
+	if match.ObjectKey.Namespace != "" {
+		return validator.denyList.IsNamespaceProtected(match.ObjectKey.Namespace)
+	}
+
+	if len(match.Namespaces) == 0 {
+		return false
+	}
+
+	for _, namespace := range match.Namespaces {
+		if !validator.denyList.IsNamespaceProtected(namespace) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mutateGVK returns the GVK objects of the rule GVK are synced as.
+func mutateGVK(ruleGVK resources.GroupVersionKind, mutation *resources.GroupVersionKind) schema.GroupVersionKind {
+	gvk := schema.GroupVersionKind(ruleGVK)
+	if mutation == nil {
+		return gvk
+	}
+
+	if mutation.Group != "" {
+		gvk.Group = mutation.Group
+	}
+	if mutation.Kind != "" {
+		gvk.Kind = mutation.Kind
+	}
+	if mutation.Version != "" {
+		gvk.Version = mutation.Version
+	}
+
+	return gvk
+}
+
+// rulesOverlap returns whether any object could be matched by both specified
+// rules. Label, annotation and content selectors are not compared, the rules
+// are considered overlapping whenever their GVKs, namespaces and object keys
+// could select the same object.
+func rulesOverlap(a, b clusterregistrycontrollerapiv1alpha1.ResourceSyncRuleSpec) bool {
+	if !gvksOverlap(a, b) {
+		return false
+	}
+
+	for _, ruleA := range a.Rules {
+		for _, ruleB := range b.Rules {
+			if syncRulesOverlap(ruleA, ruleB) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func gvksOverlap(a, b clusterregistrycontrollerapiv1alpha1.ResourceSyncRuleSpec) bool {
+	if a.GVK.Group != b.GVK.Group || a.GVK.Kind != b.GVK.Kind {
+		return false
+	}
+
+	switch {
+	case a.GVK.Version != clusterregistrycontrollerapiv1alpha1.AnyVersion:
+		return b.MatchGVK(schema.GroupVersionKind(a.GVK))
+	case b.GVK.Version != clusterregistrycontrollerapiv1alpha1.AnyVersion:
+		return a.MatchGVK(schema.GroupVersionKind(b.GVK))
+	case len(a.Versions) == 0 || len(b.Versions) == 0:
+		return true
+	}
+
+	for _, version := range a.Versions {
+		if b.MatchGVK(schema.GroupVersionKind(a.GVK).GroupKind().WithVersion(version)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func syncRulesOverlap(a, b clusterregistrycontrollerapiv1alpha1.SyncRule) bool {
+	if len(a.Matches) == 0 || len(b.Matches) == 0 {
+		return true
+	}
+
+	for _, matchA := range a.Matches {
+		for _, matchB := range b.Matches {
+			if matchesOverlap(matchA, matchB) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func matchesOverlap(a, b clusterregistrycontrollerapiv1alpha1.SyncRuleMatch) bool {
+	if !stringsOverlap(a.ObjectKey.Name, b.ObjectKey.Name) || !stringsOverlap(a.ObjectKey.Namespace, b.ObjectKey.Namespace) {
+		return false
+	}
+
+	if len(a.Namespaces) == 0 || len(b.Namespaces) == 0 {
+		return true
+	}
+
+	for _, namespace := range a.Namespaces {
+		for _, other := range b.Namespaces {
+			if namespace == other {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// stringsOverlap returns whether two optional, exactly matched values could
+// select the same value.
+func stringsOverlap(a, b string) bool {
+	return a == "" || b == "" || a == b
+}
+
+// InjectDecoder sets the resource sync rule CR decoder object.
+func (validator *ResourceSyncRuleValidator) InjectDecoder(decoder *admission.Decoder) error {
+	validator.decoder = decoder
+
+	return nil
+}
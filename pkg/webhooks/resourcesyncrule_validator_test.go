@@ -0,0 +1,519 @@
+// Copyright (c) 2021, and 2022 Cisco and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/banzaicloud/operator-tools/pkg/resources"
+	"github.com/banzaicloud/operator-tools/pkg/utils"
+
+	clusterregistryv1alpha1 "github.com/cisco-open/cluster-registry-controller/api/v1alpha1"
+	"github.com/cisco-open/cluster-registry-controller/pkg/util"
+	"github.com/cisco-open/cluster-registry-controller/pkg/webhooks"
+)
+
+// restMapperClient sets the REST mapper of the fake client, which has none
+type restMapperClient struct {
+	client.Client
+
+	mapper meta.RESTMapper
+}
+
+func (c restMapperClient) RESTMapper() meta.RESTMapper {
+	return c.mapper
+}
+
+func newResourceSyncRule(name string, namespaces ...string) *clusterregistryv1alpha1.ResourceSyncRule {
+	return &clusterregistryv1alpha1.ResourceSyncRule{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterregistryv1alpha1.GroupVersion.String(),
+			Kind:       "ResourceSyncRule",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: clusterregistryv1alpha1.ResourceSyncRuleSpec{
+			GVK: resources.GroupVersionKind{
+				Version: "v1",
+				Kind:    "ConfigMap",
+			},
+			Rules: []clusterregistryv1alpha1.SyncRule{
+				{
+					Matches: []clusterregistryv1alpha1.SyncRuleMatch{
+						{
+							Namespaces: namespaces,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestResourceSyncRuleValidator(t *testing.T) {
+	t.Parallel()
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := clusterregistryv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	denyList, err := util.NewDenyList([]string{"kube-system"}, []string{"admissionregistration.k8s.io/*/ValidatingWebhookConfiguration"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	tests := map[string]struct {
+		mutate   func(rule *clusterregistryv1alpha1.ResourceSyncRule)
+		allowed  bool
+		warnings int
+	}{
+		"valid rule": {
+			allowed: true,
+		},
+		"wildcard version": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.GVK.Version = clusterregistryv1alpha1.AnyVersion
+			},
+			allowed: true,
+		},
+		"overlapping rule": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Matches[0].Namespaces = []string{"default", "other"}
+			},
+			allowed:  true,
+			warnings: 1,
+		},
+		"dependency": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.DependsOn = []string{"existing"}
+			},
+			allowed: true,
+		},
+		"dependency cycle": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.DependsOn = []string{"unrelated"}
+			},
+		},
+		"self dependency": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.DependsOn = []string{"test"}
+			},
+		},
+		"service account": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.ServiceAccountName = "syncer"
+			},
+			allowed: true,
+		},
+		"invalid service account": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.ServiceAccountName = "Syncer"
+			},
+		},
+		"missing kind": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.GVK.Kind = ""
+			},
+		},
+		"invalid group": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.GVK.Group = "example.com/v1"
+			},
+		},
+		"invalid version": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.GVK.Version = "V1"
+			},
+		},
+		"invalid label selector": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Matches[0].Labels = []metav1.LabelSelector{
+					{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{
+								Key:      "app",
+								Operator: metav1.LabelSelectorOpExists,
+								Values:   []string{"test"},
+							},
+						},
+					},
+				}
+			},
+		},
+		"invalid cluster feature match": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.ClusterFeatureMatches = []clusterregistryv1alpha1.ClusterFeatureMatch{
+					{
+						MatchLabels: map[string]string{"invalid key": "value"},
+					},
+				}
+			},
+		},
+		"registered mutation kind": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.GVK = &resources.GroupVersionKind{Kind: "Secret"}
+			},
+			allowed: true,
+		},
+		"unregistered mutation kind": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.GVK = &resources.GroupVersionKind{Group: "example.com", Kind: "Widget"}
+			},
+		},
+		"valid override": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.Overrides = []resources.K8SResourceOverlayPatch{
+					{
+						Type:       resources.ReplaceOverlayPatchType,
+						Path:       utils.StringPointer("/metadata/labels"),
+						Value:      utils.StringPointer(`{"synced": "true"}`),
+						ParseValue: true,
+					},
+				}
+			},
+			allowed: true,
+		},
+		"templated override": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.Overrides = []resources.K8SResourceOverlayPatch{
+					{
+						Type:  resources.ReplaceOverlayPatchType,
+						Path:  utils.StringPointer("/data/cluster"),
+						Value: utils.StringPointer(`{{ .Cluster.Name }}`),
+					},
+				}
+			},
+			allowed: true,
+		},
+		"unparsable override value": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.Overrides = []resources.K8SResourceOverlayPatch{
+					{
+						Type:       resources.ReplaceOverlayPatchType,
+						Path:       utils.StringPointer("/metadata/labels"),
+						Value:      utils.StringPointer(`{"synced": "true"`),
+						ParseValue: true,
+					},
+				}
+			},
+		},
+		"protected namespace": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Matches[0].Namespaces = []string{"kube-system"}
+			},
+		},
+		"protected and unprotected namespace": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Matches[0].Namespaces = []string{"kube-system", "default"}
+			},
+			allowed:  true,
+			warnings: 1,
+		},
+		"denied kind": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.GVK = resources.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"}
+				rule.Spec.Rules[0].Matches = nil
+			},
+		},
+		"mutated into denied kind": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.GVK = &resources.GroupVersionKind{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}
+			},
+		},
+		"namespace routing": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.NamespaceRouting = &clusterregistryv1alpha1.NamespaceRouting{
+					FromAnnotation: "owner-team",
+					Map:            map[string]string{"search": "team-search"},
+				}
+			},
+			allowed: true,
+		},
+		"namespace routing into protected namespace": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.NamespaceRouting = &clusterregistryv1alpha1.NamespaceRouting{
+					FromLabel:        "owner-team",
+					Map:              map[string]string{"search": "team-search"},
+					DefaultNamespace: "kube-system",
+					UnmappedPolicy:   clusterregistryv1alpha1.UnmappedNamespacePolicyDefault,
+				}
+			},
+			allowed:  true,
+			warnings: 1,
+		},
+		"namespace routing from annotation and label": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.NamespaceRouting = &clusterregistryv1alpha1.NamespaceRouting{
+					FromAnnotation: "owner-team",
+					FromLabel:      "owner-team",
+				}
+			},
+		},
+		"namespace routing without default namespace": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.NamespaceRouting = &clusterregistryv1alpha1.NamespaceRouting{
+					FromAnnotation: "owner-team",
+					UnmappedPolicy: clusterregistryv1alpha1.UnmappedNamespacePolicyDefault,
+				}
+			},
+		},
+		"namespace routing into invalid namespace": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.NamespaceRouting = &clusterregistryv1alpha1.NamespaceRouting{
+					FromAnnotation: "owner-team",
+					Map:            map[string]string{"search": "Team_Search"},
+				}
+			},
+		},
+		"unparsable override template": {
+			mutate: func(rule *clusterregistryv1alpha1.ResourceSyncRule) {
+				rule.Spec.Rules[0].Mutations.Overrides = []resources.K8SResourceOverlayPatch{
+					{
+						Type:  resources.ReplaceOverlayPatchType,
+						Path:  utils.StringPointer("/data/cluster"),
+						Value: utils.StringPointer(`{{ .Cluster.Name `),
+					},
+				}
+			},
+		},
+	}
+
+	for name, test := range tests {
+		unrelated := newResourceSyncRule("unrelated", "kube-system")
+		unrelated.Spec.DependsOn = []string{"test"}
+		existingRules := []runtime.Object{
+			newResourceSyncRule("existing", "other"),
+			unrelated,
+		}
+
+		validator := webhooks.NewResourceSyncRuleValidator(
+			logr.Discard(),
+			restMapperClient{
+				Client: fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(existingRules...).Build(),
+				mapper: mapper,
+			},
+			s,
+			denyList,
+		)
+
+		decoder, err := admission.NewDecoder(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := validator.InjectDecoder(decoder); err != nil {
+			t.Fatal(err)
+		}
+
+		rule := newResourceSyncRule("test", "default")
+		if test.mutate != nil {
+			test.mutate(rule)
+		}
+
+		raw, err := json.Marshal(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response := validator.Handle(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object: runtime.RawExtension{
+					Raw: raw,
+				},
+			},
+		})
+
+		if response.Allowed != test.allowed {
+			t.Fatalf("%s: allowed: %t, expected: %t (%v)", name, response.Allowed, test.allowed, response.Result)
+		}
+		if len(response.Warnings) != test.warnings {
+			t.Fatalf("%s: warnings: %v, expected %d", name, response.Warnings, test.warnings)
+		}
+	}
+}
+
+func TestResourceSyncRuleValidatorAuthorization(t *testing.T) {
+	t.Parallel()
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := clusterregistryv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	policy := &clusterregistryv1alpha1.SyncRuleAuthorizationPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "team-a",
+		},
+		Spec: clusterregistryv1alpha1.SyncRuleAuthorizationPolicySpec{
+			Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "team-a"}},
+			Allow: []clusterregistryv1alpha1.SyncRuleAllowance{
+				{
+					SourceKinds: []metav1.GroupKind{{Kind: "ConfigMap"}},
+					Namespaces:  []string{"team-a"},
+				},
+			},
+		},
+	}
+
+	teamA := authenticationv1.UserInfo{Username: "alice", Groups: []string{"team-a"}}
+	controller := authenticationv1.UserInfo{Username: "system:serviceaccount:cluster-registry:controller"}
+
+	tests := map[string]struct {
+		operation admissionv1.Operation
+		user      authenticationv1.UserInfo
+		old       *clusterregistryv1alpha1.ResourceSyncRule
+		rule      *clusterregistryv1alpha1.ResourceSyncRule
+		disabled  bool
+		allowed   bool
+	}{
+		"allowed create": {
+			operation: admissionv1.Create,
+			user:      teamA,
+			rule:      newResourceSyncRule("test", "team-a"),
+			allowed:   true,
+		},
+		"namespace not allowed": {
+			operation: admissionv1.Create,
+			user:      teamA,
+			rule:      newResourceSyncRule("test", "team-b"),
+			allowed:   false,
+		},
+		"no applicable policy": {
+			operation: admissionv1.Create,
+			user:      authenticationv1.UserInfo{Username: "bob", Groups: []string{"team-b"}},
+			rule:      newResourceSyncRule("test", "team-a"),
+			allowed:   false,
+		},
+		"exempt user": {
+			operation: admissionv1.Create,
+			user:      controller,
+			rule:      newResourceSyncRule("test", "team-b"),
+			allowed:   true,
+		},
+		"update without spec change": {
+			operation: admissionv1.Update,
+			user:      teamA,
+			old:       newResourceSyncRule("test", "team-b"),
+			rule: func() *clusterregistryv1alpha1.ResourceSyncRule {
+				rule := newResourceSyncRule("test", "team-b")
+				rule.Finalizers = []string{"example.com/finalizer"}
+
+				return rule
+			}(),
+			allowed: true,
+		},
+		"update with spec change": {
+			operation: admissionv1.Update,
+			user:      teamA,
+			old:       newResourceSyncRule("test", "team-a"),
+			rule:      newResourceSyncRule("test", "team-b"),
+			allowed:   false,
+		},
+		"authorization disabled": {
+			operation: admissionv1.Create,
+			user:      authenticationv1.UserInfo{Username: "bob"},
+			rule:      newResourceSyncRule("test", "team-b"),
+			disabled:  true,
+			allowed:   true,
+		},
+	}
+
+	for name, test := range tests {
+		opts := []webhooks.ResourceSyncRuleValidatorOption{
+			webhooks.WithAuthorization(controller.Username),
+		}
+		if test.disabled {
+			opts = nil
+		}
+
+		validator := webhooks.NewResourceSyncRuleValidator(
+			logr.Discard(),
+			restMapperClient{
+				Client: fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(policy).Build(),
+				mapper: mapper,
+			},
+			s,
+			nil,
+			opts...,
+		)
+
+		decoder, err := admission.NewDecoder(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := validator.InjectDecoder(decoder); err != nil {
+			t.Fatal(err)
+		}
+
+		raw, err := json.Marshal(test.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		request := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: test.operation,
+				UserInfo:  test.user,
+				Object: runtime.RawExtension{
+					Raw: raw,
+				},
+			},
+		}
+		if test.old != nil {
+			oldRaw, err := json.Marshal(test.old)
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.OldObject = runtime.RawExtension{
+				Raw: oldRaw,
+			}
+		}
+
+		response := validator.Handle(context.Background(), request)
+
+		if response.Allowed != test.allowed {
+			t.Fatalf("%s: allowed: %t, expected: %t (%v)", name, response.Allowed, test.allowed, response.Result)
+		}
+	}
+}